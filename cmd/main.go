@@ -2,52 +2,112 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/diagnostics"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/event_handler"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/logging"
+	"github.com/keptn-contrib/dynatrace-service/internal/nats"
 	"github.com/keptn-contrib/dynatrace-service/internal/onboard"
+	"github.com/keptn-contrib/dynatrace-service/internal/problem"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	"github.com/keptn-contrib/dynatrace-service/internal/startup"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
 
 	log "github.com/sirupsen/logrus"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
-	"github.com/kelseyhightower/envconfig"
 )
 
-type envConfig struct {
-	// Port on which to listen for cloudevents
-	Port int    `envconfig:"RCV_PORT" default:"8080"`
-	Path string `envconfig:"RCV_PATH" default:"/"`
-}
+// serviceConfig is the configuration the event handlers are created with
+var serviceConfig *env.Config
+
+// shutdownTimeout is the time given to send pending data, e.g. spans, before the process exits
+const shutdownTimeout = 10 * time.Second
+
+// timeouts of the server receiving Dynatrace problem notification webhooks, so that slow or idle clients cannot keep connections open
+const (
+	problemWebhookReadTimeout  = 10 * time.Second
+	problemWebhookWriteTimeout = 30 * time.Second
+	problemWebhookIdleTimeout  = 60 * time.Second
+)
 
 func main() {
-	log.SetLevel(env.GetLogLevel())
+	cfg := env.ReadConfig()
+	log.SetLevel(cfg.LogLevel)
+	logging.Init(cfg)
 
-	var env envConfig
-	if err := envconfig.Process("", &env); err != nil {
-		log.WithError(err).Fatal("Failed to process env var")
+	if err := cfg.Validate(); err != nil {
+		log.WithError(err).Fatal("Invalid configuration")
 	}
+	log.WithFields(cfg.LogFields()).Info("Loaded configuration")
 
-	os.Exit(_main(os.Args[1:], env))
+	os.Exit(_main(os.Args[1:], cfg))
 }
 
-func _main(args []string, envCfg envConfig) int {
+func _main(args []string, cfg *env.Config) int {
+	flags := flag.NewFlagSet("dynatrace-service", flag.ContinueOnError)
+	diagnose := flags.Bool("diagnose", false, "check the connections to Dynatrace and Keptn, print a report and exit")
+	project := flags.String("project", "", "project whose dynatrace.conf.yaml is used for diagnosing")
+	stage := flags.String("stage", "", "stage whose dynatrace.conf.yaml is used for diagnosing")
+	service := flags.String("service", "", "service whose dynatrace.conf.yaml is used for diagnosing")
+	replay := flags.String("replay", "", "file containing a captured cloud event or dead letter to process once with debug logging and exit, - reads it from stdin")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	defer shutdown(cfg)
 
-	if env.IsServiceSyncEnabled() {
-		cm, err := credentials.NewCredentialManager(nil)
-		if err != nil {
-			log.WithError(err).Fatal("Failed to initialize CredentialManager")
-		}
-		onboard.ActivateServiceSynchronizer(
-			credentials.NewCredentialManagerDefaultFallbackDecorator(cm))
+	if *diagnose {
+		return runDiagnostics(*project, *stage, *service, cfg)
+	}
+
+	if *replay != "" {
+		return runReplay(*replay, cfg)
+	}
+
+	go checkDynatraceAPIToken(cfg)
+
+	if cfg.EventTransport == env.NATSTransport {
+		go startHealthEndpoint()
+	}
+
+	serviceConfig = cfg
+	tracing.Init(cfg)
+	if cfg.SelfMonitoringEnabled {
+		go startSelfMonitoring(time.Duration(cfg.SelfMonitoringInterval)*time.Second, cfg)
+	}
+
+	// events are received right away, but only handled once the dependencies are ready, so that none are refused while Keptn is starting
+	go enableSubsystems(cfg)
+
+	if cfg.ProblemWebhookEnabled {
+		go startProblemWebhook(cfg.ProblemWebhookSecret, cfg.ProblemWebhookPort)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.EventTransport == env.NATSTransport {
+		nats.NewDefaultReceiver(cfg, gotEvent).Run(ctx)
+		return 0
 	}
 
-	ctx := context.Background()
 	ctx = cloudevents.WithEncodingStructured(ctx)
 
-	log.WithFields(log.Fields{"port": envCfg.Port, "path": envCfg.Path}).Debug("Initializing cloudevents client")
-	p, err := cloudevents.NewHTTP(cloudevents.WithPath(envCfg.Path), cloudevents.WithPort(envCfg.Port))
+	log.WithFields(log.Fields{"port": cfg.ReceiverPort, "path": cfg.ReceiverPath}).Debug("Initializing cloudevents client")
+	p, err := cloudevents.NewHTTP(cloudevents.WithPath(cfg.ReceiverPath), cloudevents.WithPort(cfg.ReceiverPort))
 	if err != nil {
 		log.WithError(err).Fatal("Failed to create client")
 	}
@@ -55,22 +115,282 @@ func _main(args []string, envCfg envConfig) int {
 	if err != nil {
 		log.WithError(err).Fatal("Failed to create client")
 	}
-	log.Fatal(c.StartReceiver(ctx, gotEvent))
+	if err := c.StartReceiver(ctx, gotEvent); err != nil {
+		log.WithError(err).Error("Failed to receive events")
+		return 1
+	}
 
 	return 0
 }
 
-func gotEvent(ctx context.Context, event cloudevents.Event) error {
-	dynatraceEventHandler, err := event_handler.NewEventHandler(event)
+// shutdown stops redelivering outgoing events, which stay buffered for the next start, and sends the spans that have not been exported yet
+func shutdown(cfg *env.Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := dynatrace.GetDefaultAsyncEventSender(cfg).Flush(ctx); err != nil {
+		log.WithError(err).Warn("Could not send all queued Dynatrace events before exiting")
+	}
+
+	keptn.GetDefaultOutgoingEventQueue(cfg).Stop()
+
+	if err := tracing.Shutdown(ctx); err != nil {
+		log.WithError(err).Warn("Could not export all spans before exiting")
+	}
+}
+
+// dependenciesReady is closed by enableSubsystems once the dependencies of the dynatrace-service are ready or the startup timeout was exceeded
+var dependenciesReady = make(chan struct{})
 
+// enableSubsystems waits for Keptn unless running locally, then enables the subsystems depending on it and the handling of received events
+func enableSubsystems(cfg *env.Config) {
+	// when running locally, resources are read from the local disk and events are written to files, so Keptn is not required
+	if cfg.RunLocalEnabled {
+		log.WithField("eventsDir", cfg.RunLocalEventsDir).Warn("Running locally, events are written to files and Dynatrace API requests changing data are not sent")
+	} else {
+		waitForKeptn(time.Duration(cfg.StartupReadinessTimeout)*time.Second, cfg)
+	}
+
+	if cfg.ServiceSyncEnabled {
+		cm, err := credentials.NewCredentialManager(nil, cfg)
+		if err != nil {
+			log.WithError(err).Fatal("Failed to initialize CredentialManager")
+		}
+		onboard.ActivateServiceSynchronizer(
+			credentials.NewCredentialManagerDefaultFallbackDecorator(cm, cfg),
+			time.Duration(cfg.ServiceSyncInterval)*time.Second,
+			cfg)
+	}
+
+	keptn.GetDefaultOutgoingEventQueue(cfg).SetDeadLetterFunc(event_handler.GetDefaultDeadLetterQueue(cfg).DeadLetter)
+	keptn.GetDefaultOutgoingEventQueue(cfg).RedeliverBufferedEvents()
+
+	close(dependenciesReady)
+}
+
+// waitForKeptn waits for the Keptn API and the resource-service to become available, e.g. while the Keptn control plane is starting as well,
+// so that the first run of the service synchronization and the redelivery of buffered events do not fail
+func waitForKeptn(timeout time.Duration, cfg *env.Config) {
+	checks := []startup.ReadinessCheck{{Name: "resource-service", Check: func() error {
+		return keptn.CheckResourceServiceConnection(cfg)
+	}}}
+
+	keptnCredentials, err := getKeptnAPICredentials(cfg)
 	if err != nil {
-		log.WithError(err).Error("NewEventHandler() returned an error")
-		return err
+		log.WithError(err).Info("No Keptn API credentials found, not waiting for the Keptn API")
+	} else {
+		checks = append([]startup.ReadinessCheck{{Name: "Keptn API", Check: func() error {
+			return credentials.CheckKeptnConnection(cfg, keptnCredentials)
+		}}}, checks...)
+	}
+
+	startup.NewReadinessGate(timeout, checks...).Wait()
+}
+
+func getKeptnAPICredentials(cfg *env.Config) (*credentials.KeptnAPICredentials, error) {
+	cm, err := credentials.NewCredentialManager(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return cm.GetKeptnAPICredentials()
+}
+
+// startHealthEndpoint serves the health endpoint usually provided by the distributor, which is not deployed when using the nats transport
+func startHealthEndpoint() {
+	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	log.Fatal(http.ListenAndServe(":10999", nil))
+}
+
+// startProblemWebhook serves the endpoint receiving Dynatrace problem notification webhooks directly instead of via the Keptn API
+func startProblemWebhook(secret string, port int) {
+	if secret == "" {
+		log.Error("No PROBLEM_WEBHOOK_SECRET set, not receiving Dynatrace problem notification webhooks")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(problem.ProblemWebhookPath, problem.NewProblemWebhookHandler(secret, gotEvent))
+
+	log.WithFields(log.Fields{"port": port, "path": problem.ProblemWebhookPath}).Info("Receiving Dynatrace problem notification webhooks")
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(port),
+		Handler:           mux,
+		ReadHeaderTimeout: problemWebhookReadTimeout,
+		ReadTimeout:       problemWebhookReadTimeout,
+		WriteTimeout:      problemWebhookWriteTimeout,
+		IdleTimeout:       problemWebhookIdleTimeout,
+	}
+	log.Fatal(server.ListenAndServe())
+}
+
+// runDiagnostics prints the diagnostics report as JSON and returns a non-zero exit code if any check failed
+func runDiagnostics(project string, stage string, service string, cfg *env.Config) int {
+	d, err := diagnostics.NewDefaultDiagnostics(cfg)
+	if err != nil {
+		log.WithError(err).Error("Could not initialize diagnostics")
+		return 1
 	}
 
-	err = dynatraceEventHandler.HandleEvent()
+	report := d.Run(project, stage, service)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.WithError(err).Error("Could not print diagnostics report")
+		return 1
+	}
+
+	if report.HasFailures() {
+		return 1
+	}
+	return 0
+}
+
+// runReplay processes the captured event stored in the file once and returns a non-zero exit code if processing failed
+func runReplay(path string, cfg *env.Config) int {
+	if !log.IsLevelEnabled(log.DebugLevel) {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		log.WithError(err).Error("Could not read captured event")
+		return 1
+	}
+
+	event, err := event_handler.ParseCapturedEvent(data)
+	if err != nil {
+		log.WithError(err).Error("Could not replay event")
+		return 1
+	}
+
+	err = event_handler.ReplayEvent(event, cfg)
+
+	// the events sent while replaying may still be queued, so wait for them instead of only stopping the queues on shutdown
+	flushed := flushReplayedEvents(cfg)
+
+	if err != nil {
+		log.WithError(err).Error("Replaying event returned an error")
+		return 1
+	}
+	if !flushed {
+		return 1
+	}
+	return 0
+}
+
+// flushReplayedEvents waits until the queued Dynatrace and Keptn events were sent and returns whether all of them were sent in time
+func flushReplayedEvents(cfg *env.Config) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	if err := dynatrace.GetDefaultAsyncEventSender(cfg).Flush(ctx); err != nil {
+		log.WithError(err).Error("Could not send all queued Dynatrace events of the replayed event")
+		return false
+	}
+
+	if err := keptn.GetDefaultOutgoingEventQueue(cfg).Flush(ctx); err != nil {
+		log.WithError(err).Error("Could not deliver all queued Keptn events of the replayed event")
+		return false
+	}
+	return true
+}
+
+// startSelfMonitoring ingests the operational metrics of the dynatrace-service into the tenant of the default Dynatrace secret
+func startSelfMonitoring(interval time.Duration, cfg *env.Config) {
+	cm, err := credentials.NewCredentialManager(nil, cfg)
+	if err != nil {
+		log.WithError(err).Error("Could not start self-monitoring")
+		return
+	}
+
+	dtCredentials, err := credentials.NewCredentialManagerDefaultFallbackDecorator(cm, cfg).GetDynatraceCredentials("")
+	if err != nil {
+		log.WithError(err).Error("No default Dynatrace secret found, could not start self-monitoring")
+		return
+	}
+
+	log.WithField("interval", interval).Info("Ingesting self-monitoring metrics into Dynatrace")
+	dtClient := dynatrace.NewClient(dtCredentials, cfg)
+	dtClient.DisableInstrumentation()
+	selfmonitoring.Start(interval, dynatrace.NewSelfMonitoringClient(dtClient).IngestMeasurements)
+}
+
+// checkDynatraceAPIToken logs a warning if the API token of the default Dynatrace secret misses scopes required for the enabled features
+func checkDynatraceAPIToken(cfg *env.Config) {
+	cm, err := credentials.NewCredentialManager(nil, cfg)
+	if err != nil {
+		log.WithError(err).Warn("Could not verify scopes of Dynatrace API token")
+		return
+	}
+
+	dtCredentials, err := credentials.NewCredentialManagerDefaultFallbackDecorator(cm, cfg).GetDynatraceCredentials("")
+	if err != nil {
+		log.WithError(err).Info("No default Dynatrace secret found, skipping verification of Dynatrace API token scopes")
+		return
+	}
+
+	metadata, err := dynatrace.CheckConnectivity(dynatrace.NewClient(dtCredentials, cfg))
 	if err != nil {
-		log.WithError(err).Error("HandleEvent() returned an error")
+		log.WithError(err).Warn("Could not verify scopes of Dynatrace API token")
+		return
+	}
+
+	missingScopes := dynatrace.FindMissingScopes(dynatrace.GetRequiredScopes(cfg), metadata.Scopes)
+
+	if len(missingScopes) > 0 {
+		log.WithField("missingScopes", missingScopes).Warn("Dynatrace API token is missing scopes required for the enabled features")
+		return
 	}
-	return err
+
+	log.Info("Dynatrace API token has all scopes required for the enabled features")
+}
+
+// gotEvent handles a received event. Events received before the dependencies are ready are acknowledged and handled in the background once they are
+func gotEvent(ctx context.Context, event cloudevents.Event) error {
+	select {
+	case <-dependenciesReady:
+		return handleEvent(event)
+	default:
+	}
+
+	log.WithField("eventType", event.Type()).Info("Dependencies not ready yet, handling event once they are")
+	go func() {
+		<-dependenciesReady
+		handleEvent(event)
+	}()
+	return nil
+}
+
+// handleEvent handles the event, processing it again if it failed and dead-lettering it if it failed too often
+func handleEvent(event cloudevents.Event) error {
+	selfmonitoring.RecordEventHandled(event.Type())
+	return event_handler.GetDefaultDeadLetterQueue(serviceConfig).Handle(event, func() error {
+		span, tracedEvent := tracing.StartEventSpan(event)
+		dynatraceEventHandler, err := event_handler.NewEventHandler(tracedEvent, serviceConfig)
+
+		if err != nil {
+			tracing.EndSpan(span, err)
+			selfmonitoring.RecordHandlerError(event.Type(), err)
+			log.WithError(err).Error("NewEventHandler() returned an error")
+			return err
+		}
+
+		err = dynatraceEventHandler.HandleEvent()
+		tracing.EndSpan(span, err)
+		if err != nil {
+			selfmonitoring.RecordHandlerError(event.Type(), err)
+			log.WithError(err).Error("HandleEvent() returned an error")
+		}
+		return err
+	})
 }
@@ -5,9 +5,12 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/cloudevents/sdk-go/v2/types"
 	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 const shKeptnContext = "shkeptncontext"
+const triggeredID = "triggeredid"
+const gitCommitID = "gitcommitid"
 
 type TriggeredCloudEventContentAdapter interface {
 	CloudEventContentAdapter
@@ -43,6 +46,24 @@ func (a CloudEventAdapter) ShKeptnContext() string {
 	return context
 }
 
+// TriggeredID returns the ID of the triggered event this event refers to, or an empty string if there is none
+func (a CloudEventAdapter) TriggeredID() string {
+	id, err := types.ToString(a.ce.Context.GetExtensions()[triggeredID])
+	if err != nil {
+		log.WithError(err).Debug("Event does not contain " + triggeredID)
+	}
+	return id
+}
+
+// GitCommitID returns the ID of the git commit of the configuration repository the event refers to, or an empty string if there is none
+func (a CloudEventAdapter) GitCommitID() string {
+	id, err := types.ToString(a.ce.Context.GetExtensions()[gitCommitID])
+	if err != nil {
+		log.WithError(err).Debug("Event does not contain " + gitCommitID)
+	}
+	return id
+}
+
 func (a CloudEventAdapter) Source() string {
 	return a.ce.Source()
 }
@@ -55,6 +76,11 @@ func (a CloudEventAdapter) Type() string {
 	return a.ce.Type()
 }
 
+// Time returns the time the event was created, or the zero time if it is not specified
+func (a CloudEventAdapter) Time() time.Time {
+	return a.ce.Time()
+}
+
 // PayloadAs attempts to populate the provided content object with the event payload. Will return an error otherwise.
 // content should be a pointer type.
 func (a CloudEventAdapter) PayloadAs(content interface{}) error {
@@ -3,9 +3,11 @@ package adapter
 import (
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
-	"github.com/keptn-contrib/dynatrace-service/internal/event"
 )
 
+// defaultEventSource is the source of created events, the client sending them may replace it and add extensions as configured, see event.GetEventSource
+const defaultEventSource = "dynatrace-service"
+
 type CloudEventFactoryInterface interface {
 	CreateCloudEvent() (*cloudevents.Event, error)
 }
@@ -31,7 +33,7 @@ func (f *CloudEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
 	}
 
 	if f.event.GetEventID() != "" {
-		ce.SetExtension("triggeredid", f.event.GetEventID())
+		ce.SetExtension(triggeredID, f.event.GetEventID())
 	}
 
 	return ce, nil
@@ -53,7 +55,7 @@ func NewCloudEventFactoryBase(event CloudEventContentAdapter, eventType string,
 
 func (f *CloudEventFactoryBase) CreateCloudEvent() (*cloudevents.Event, error) {
 	ev := cloudevents.NewEvent()
-	ev.SetSource(event.GetEventSource())
+	ev.SetSource(defaultEventSource)
 	ev.SetDataContentType(cloudevents.ApplicationJSON)
 	ev.SetType(f.eventType)
 	ev.SetExtension("shkeptncontext", f.event.GetShKeptnContext())
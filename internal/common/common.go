@@ -3,6 +3,8 @@ package common
 import (
 	"net/url"
 	"os"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -16,15 +18,25 @@ import (
 	"github.com/keptn/go-utils/pkg/lib/keptn"
 )
 
+const labelPlaceholderPrefix = "$LABEL."
+
+var labelPlaceholderRegex = regexp.MustCompile(`\$LABEL\.[A-Za-z0-9_\-]+`)
+
 // This is the label name for the Problem URL label
 const PROBLEMURL_LABEL = "Problem URL"
+
+// This is the label name for the link to the root cause entity of a problem
+const ROOTCAUSEURL_LABEL = "Root cause URL"
 const KEPTNSBRIDGE_LABEL = "Keptns Bridge"
+const KEPTNSBRIDGE_EVALUATION_LABEL = "Keptns Bridge Evaluation"
 
 const shipyardController = "SHIPYARD_CONTROLLER"
 const configurationService = "CONFIGURATION_SERVICE"
 const datastore = "DATASTORE"
+const secretService = "SECRET_SERVICE"
 
 const defaultShipyardControllerURL = "http://shipyard-controller:8080"
+const defaultSecretServiceURL = "http://secret-service:8080"
 
 // GetConfigurationServiceURL Returns the endpoint to the configuration-service
 func GetConfigurationServiceURL() string {
@@ -41,6 +53,11 @@ func GetShipyardControllerURL() string {
 	return getKeptnServiceURL(shipyardController, defaultShipyardControllerURL)
 }
 
+// GetSecretServiceURL Returns the endpoint to the secret-service
+func GetSecretServiceURL() string {
+	return getKeptnServiceURL(secretService, defaultSecretServiceURL)
+}
+
 func getKeptnServiceURL(servicename, defaultURL string) string {
 	url, err := keptn.GetServiceEndpoint(servicename)
 	if err != nil {
@@ -86,6 +103,7 @@ func ReplaceQueryParameters(query string, customFilters []*keptnv2.SLIFilter, ke
 // $CONTEXT, $EVENT, $SOURCE
 // $PROJECT, $STAGE, $SERVICE, $DEPLOYMENT
 // $TESTSTRATEGY
+// $TEST.SI, $TEST.LTN -> x-dynatrace-test header values set by the dynatrace-service
 // $LABEL.XXXX  -> will replace that with a label called XXXX
 // $ENV.XXXX    -> will replace that with an env variable called XXXX
 // $SECRET.YYYY -> will replace that with the k8s secret called YYYY
@@ -105,10 +123,12 @@ func ReplaceKeptnPlaceholders(input string, keptnEvent adapter.EventContentAdapt
 	result = strings.Replace(result, "$DEPLOYMENT", url.QueryEscape(keptnEvent.GetDeployment()), -1)
 	result = strings.Replace(result, "$TESTSTRATEGY", url.QueryEscape(keptnEvent.GetTestStrategy()), -1)
 
+	// x-dynatrace-test header values that are known for the whole sequence
+	result = strings.Replace(result, "$TEST.SI", url.QueryEscape(DynatraceTestHeaderSource), -1)
+	result = strings.Replace(result, "$TEST.LTN", url.QueryEscape(keptnEvent.GetShKeptnContext()), -1)
+
 	// now we do the labels
-	for key, value := range keptnEvent.GetLabels() {
-		result = strings.Replace(result, "$LABEL."+key, url.QueryEscape(value), -1)
-	}
+	result = ReplaceLabelPlaceholders(result, keptnEvent.GetLabels(), url.QueryEscape)
 
 	// now we do all environment variables
 	for _, env := range os.Environ() {
@@ -121,6 +141,31 @@ func ReplaceKeptnPlaceholders(input string, keptnEvent adapter.EventContentAdapt
 	return result
 }
 
+// ReplaceLabelPlaceholders replaces $LABEL.XXXX placeholders with the escaped value of the label called XXXX.
+// Longer label names are replaced first so that e.g. $LABEL.environment is not resolved using the label env.
+// Placeholders of labels that are not set are left unchanged and logged
+func ReplaceLabelPlaceholders(input string, labels map[string]string, escape func(string) string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+
+	result := input
+	for _, key := range keys {
+		result = strings.Replace(result, labelPlaceholderPrefix+key, escape(labels[key]), -1)
+	}
+
+	unresolved := labelPlaceholderRegex.FindAllString(result, -1)
+	if len(unresolved) > 0 {
+		log.WithField("placeholders", unresolved).Warn("No labels found for placeholders, leaving them unchanged")
+	}
+
+	return result
+}
+
 // ParseUnixTimestamp parses a time stamp into Unix foramt
 func ParseUnixTimestamp(timestamp string) (time.Time, error) {
 	parsedTime, err := time.Parse(time.RFC3339, timestamp)
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
 	keptnapi "github.com/keptn/go-utils/pkg/lib"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -89,3 +90,43 @@ func TestParsePassAndWarningFromString(t *testing.T) {
 		})
 	}
 }
+
+func TestReplaceKeptnPlaceholdersWithDeploymentAndLabels(t *testing.T) {
+	tests := []struct {
+		name  string
+		event *test.EventData
+		query string
+		want  string
+	}{
+		{
+			name: "deployment and labels are replaced",
+			event: &test.EventData{
+				Deployment: "canary",
+				Labels:     map[string]string{"dttag": "app:carts"},
+			},
+			query: "entitySelector=type(SERVICE),tag($LABEL.dttag),tag(keptn_deployment:$DEPLOYMENT)",
+			want:  "entitySelector=type(SERVICE),tag(app%3Acarts),tag(keptn_deployment:canary)",
+		},
+		{
+			name: "longer label names take precedence over their prefixes",
+			event: &test.EventData{
+				Labels: map[string]string{"env": "short", "environment": "production", "environment_zone": "eu"},
+			},
+			query: "$LABEL.env-$LABEL.environment-$LABEL.environment_zone",
+			want:  "short-production-eu",
+		},
+		{
+			name: "placeholders of missing labels are left unchanged",
+			event: &test.EventData{
+				Labels: map[string]string{"env": "production"},
+			},
+			query: "tag($LABEL.owner),tag(env:$LABEL.env)",
+			want:  "tag($LABEL.owner),tag(env:production)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ReplaceKeptnPlaceholders(tt.query, tt.event))
+		})
+	}
+}
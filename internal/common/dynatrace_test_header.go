@@ -0,0 +1,49 @@
+package common
+
+import (
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+)
+
+// DynatraceTestHeaderName is the name of the HTTP header load testing tools use to tag requests for Dynatrace
+const DynatraceTestHeaderName = "x-dynatrace-test"
+
+// DynatraceTestHeaderSource is the source (SI) used in the x-dynatrace-test header
+const DynatraceTestHeaderSource = "keptn"
+
+// DynatraceTestHeader contains the values of the x-dynatrace-test header, see
+// https://www.dynatrace.com/support/help/setup-and-configuration/integrations/third-party-integrations/test-automation-frameworks/dynatrace-and-load-testing-tools-integration
+type DynatraceTestHeader struct {
+	// VirtualUser (VU) is the ID of the virtual user sending the request, load testing tools should overwrite it
+	VirtualUser string `json:"VU"`
+
+	// Source (SI) identifies the tool sending the request
+	Source string `json:"SI"`
+
+	// TestStepName (TSN) is the name of the test step, load testing tools should overwrite it per request
+	TestStepName string `json:"TSN"`
+
+	// LoadScriptName (LSN) is the name of the load script, i.e. the Keptn test strategy
+	LoadScriptName string `json:"LSN"`
+
+	// LoadTestName (LTN) is the name of the load test run, i.e. the Keptn context
+	LoadTestName string `json:"LTN"`
+
+	// Header is the complete header value
+	Header string `json:"header"`
+}
+
+// NewDynatraceTestHeader creates the x-dynatrace-test header values for a test of a Keptn sequence
+func NewDynatraceTestHeader(event adapter.EventContentAdapter) DynatraceTestHeader {
+	header := DynatraceTestHeader{
+		VirtualUser:    "1",
+		Source:         DynatraceTestHeaderSource,
+		TestStepName:   event.GetService(),
+		LoadScriptName: event.GetTestStrategy(),
+		LoadTestName:   event.GetShKeptnContext(),
+	}
+	header.Header = fmt.Sprintf("VU=%s;SI=%s;TSN=%s;LSN=%s;LTN=%s", header.VirtualUser, header.Source, header.TestStepName, header.LoadScriptName, header.LoadTestName)
+
+	return header
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDynatraceTestHeader(t *testing.T) {
+	event := &test.EventData{
+		Context:      "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+		Project:      "sockshop",
+		Stage:        "staging",
+		Service:      "carts",
+		TestStrategy: "performance",
+	}
+
+	header := NewDynatraceTestHeader(event)
+
+	assert.EqualValues(t, "1", header.VirtualUser)
+	assert.EqualValues(t, "keptn", header.Source)
+	assert.EqualValues(t, "carts", header.TestStepName)
+	assert.EqualValues(t, "performance", header.LoadScriptName)
+	assert.EqualValues(t, "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", header.LoadTestName)
+	assert.EqualValues(t, "VU=1;SI=keptn;TSN=carts;LSN=performance;LTN=7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", header.Header)
+}
+
+func TestReplaceKeptnPlaceholdersWithDynatraceTestHeaderValues(t *testing.T) {
+	event := &test.EventData{
+		Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+	}
+
+	query := "metricSelector=builtin:service.response.time:filter(and(eq(\"SI\",\"$TEST.SI\"),eq(\"LTN\",\"$TEST.LTN\")))"
+
+	assert.EqualValues(t,
+		"metricSelector=builtin:service.response.time:filter(and(eq(\"SI\",\"keptn\"),eq(\"LTN\",\"7c2c890f-b3ac-4caa-8922-f44d2aa54ec9\")))",
+		ReplaceKeptnPlaceholders(query, event))
+}
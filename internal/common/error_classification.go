@@ -0,0 +1,113 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// ErrorType classifies an error by the subsystem that caused it
+type ErrorType string
+
+const (
+	// UnknownErrorType is used for errors that could not be classified
+	UnknownErrorType ErrorType = "unknown"
+
+	// UserConfigurationErrorType is used for errors caused by missing or invalid configuration provided by the user, e.g. sli.yaml or dynatrace.conf.yaml
+	UserConfigurationErrorType ErrorType = "user configuration"
+
+	// DynatraceAPIErrorType is used for errors returned by or while connecting to the Dynatrace API
+	DynatraceAPIErrorType ErrorType = "Dynatrace API"
+
+	// KeptnAPIErrorType is used for errors returned by or while connecting to the Keptn API
+	KeptnAPIErrorType ErrorType = "Keptn API"
+)
+
+var errorTypeMessagePrefixes = map[ErrorType]string{
+	UserConfigurationErrorType: "invalid user configuration",
+	DynatraceAPIErrorType:      "Dynatrace API request failed",
+	KeptnAPIErrorType:          "Keptn API request failed",
+}
+
+// ClassifiableError is implemented by errors that know which subsystem caused them
+type ClassifiableError interface {
+	error
+	ErrorType() ErrorType
+}
+
+// ClassifiedError wraps an error and adds an ErrorType
+type ClassifiedError struct {
+	errorType ErrorType
+	cause     error
+}
+
+// NewUserConfigurationError wraps an error caused by missing or invalid user configuration
+func NewUserConfigurationError(cause error) *ClassifiedError {
+	return &ClassifiedError{
+		errorType: UserConfigurationErrorType,
+		cause:     cause,
+	}
+}
+
+// NewDynatraceAPIError wraps an error caused by the Dynatrace API
+func NewDynatraceAPIError(cause error) *ClassifiedError {
+	return &ClassifiedError{
+		errorType: DynatraceAPIErrorType,
+		cause:     cause,
+	}
+}
+
+// NewKeptnAPIError wraps an error caused by the Keptn API
+func NewKeptnAPIError(cause error) *ClassifiedError {
+	return &ClassifiedError{
+		errorType: KeptnAPIErrorType,
+		cause:     cause,
+	}
+}
+
+// Error returns the message of the wrapped error
+func (e *ClassifiedError) Error() string {
+	return e.cause.Error()
+}
+
+// Unwrap returns the wrapped error
+func (e *ClassifiedError) Unwrap() error {
+	return e.cause
+}
+
+// ErrorType returns the type of the error
+func (e *ClassifiedError) ErrorType() ErrorType {
+	return e.errorType
+}
+
+// GetErrorType returns the ErrorType of the first classifiable error in the chain of err or UnknownErrorType if there is none
+func GetErrorType(err error) ErrorType {
+	var classifiableErr ClassifiableError
+	if errors.As(err, &classifiableErr) {
+		return classifiableErr.ErrorType()
+	}
+
+	return UnknownErrorType
+}
+
+// GetFinishedEventStatusAndResult returns the status and result of a finished event for a task that failed with err.
+// Invalid user configuration means the task was executed but cannot pass, so the status is succeeded and the result is fail.
+// All other errors mean the task could not be executed, so the status is errored
+func GetFinishedEventStatusAndResult(err error) (keptnv2.StatusType, keptnv2.ResultType) {
+	if GetErrorType(err) == UserConfigurationErrorType {
+		return keptnv2.StatusSucceeded, keptnv2.ResultFailed
+	}
+
+	return keptnv2.StatusErrored, keptnv2.ResultFailed
+}
+
+// GetFinishedEventMessage returns the message of a finished event for a task that failed with err naming the failing subsystem
+func GetFinishedEventMessage(err error) string {
+	prefix, ok := errorTypeMessagePrefixes[GetErrorType(err)]
+	if !ok {
+		return err.Error()
+	}
+
+	return fmt.Sprintf("%s: %s", prefix, err.Error())
+}
@@ -0,0 +1,60 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorClassification(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantType    ErrorType
+		wantStatus  keptnv2.StatusType
+		wantMessage string
+	}{
+		{
+			name:        "unclassified error",
+			err:         errors.New("something went wrong"),
+			wantType:    UnknownErrorType,
+			wantStatus:  keptnv2.StatusErrored,
+			wantMessage: "something went wrong",
+		},
+		{
+			name:        "user configuration error",
+			err:         NewUserConfigurationError(errors.New("invalid SLO file format")),
+			wantType:    UserConfigurationErrorType,
+			wantStatus:  keptnv2.StatusSucceeded,
+			wantMessage: "invalid user configuration: invalid SLO file format",
+		},
+		{
+			name:        "Dynatrace API error",
+			err:         NewDynatraceAPIError(errors.New("connection refused")),
+			wantType:    DynatraceAPIErrorType,
+			wantStatus:  keptnv2.StatusErrored,
+			wantMessage: "Dynatrace API request failed: connection refused",
+		},
+		{
+			name:        "wrapped Keptn API error",
+			err:         fmt.Errorf("could not retrieve shipyard: %w", NewKeptnAPIError(errors.New("404 not found"))),
+			wantType:    KeptnAPIErrorType,
+			wantStatus:  keptnv2.StatusErrored,
+			wantMessage: "Keptn API request failed: could not retrieve shipyard: 404 not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.wantType, GetErrorType(tt.err))
+
+			status, result := GetFinishedEventStatusAndResult(tt.err)
+			assert.Equal(t, tt.wantStatus, status)
+			assert.Equal(t, keptnv2.ResultFailed, result)
+
+			assert.Equal(t, tt.wantMessage, GetFinishedEventMessage(tt.err))
+		})
+	}
+}
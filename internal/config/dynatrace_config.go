@@ -2,10 +2,146 @@ package config
 
 import "github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 
+// Aggregations of SLI values queried from several Dynatrace environments
+const (
+	SumSLIAggregation     = "sum"
+	AverageSLIAggregation = "avg"
+	MaximumSLIAggregation = "max"
+	MinimumSLIAggregation = "min"
+)
+
+// SupportedSLIAggregations contains the values supported for sliAggregation
+var SupportedSLIAggregations = []string{SumSLIAggregation, AverageSLIAggregation, MaximumSLIAggregation, MinimumSLIAggregation}
+
+// Sources taking precedence if SLIs of a dashboard are merged with those defined in the sli.yaml
+const (
+	DashboardSLIMerge = "dashboard"
+	FileSLIMerge      = "file"
+)
+
+// SupportedSLIMerges contains the values supported for sliMerge
+var SupportedSLIMerges = []string{DashboardSLIMerge, FileSLIMerge}
+
 // DynatraceConfigFile defines the Dynatrace configuration structure
 type DynatraceConfigFile struct {
-	SpecVersion string                 `json:"spec_version" yaml:"spec_version"`
+	SpecVersion string `json:"spec_version" yaml:"spec_version"`
+	DtCreds     string `json:"dtCreds,omitempty" yaml:"dtCreds,omitempty"`
+	// StageDtCreds maps stage names to the credentials to be used for this stage instead of DtCreds. It is a shorthand for overrides of the stages setting only DtCreds
+	StageDtCreds map[string]string      `json:"stageDtCreds,omitempty" yaml:"stageDtCreds,omitempty"`
+	Dashboard    string                 `json:"dashboard,omitempty" yaml:"dashboard,omitempty"`
+	AttachRules  *dynatrace.AttachRules `json:"attachRules,omitempty" yaml:"attachRules,omitempty"`
+	TLS          *dynatrace.TLSOptions  `json:"tls,omitempty" yaml:"tls,omitempty"`
+
+	// EventTypes maps Keptn event types to the Dynatrace event types sent for them, or NONE to disable sending events for them
+	EventTypes dynatrace.EventTypeMapping `json:"eventTypes,omitempty" yaml:"eventTypes,omitempty"`
+
+	// AdditionalDtCreds references the credentials of further Dynatrace environments SLIs defined in the sli.yaml are queried from
+	AdditionalDtCreds []string `json:"additionalDtCreds,omitempty" yaml:"additionalDtCreds,omitempty"`
+	// SLIAggregation defines how the values of an SLI queried from several Dynatrace environments are combined, defaults to sum
+	SLIAggregation string `json:"sliAggregation,omitempty" yaml:"sliAggregation,omitempty"`
+
+	// StrictKeySLIs makes the get-sli task error if a key SLI of the slo.yaml cannot be retrieved
+	StrictKeySLIs bool `json:"strictKeySLIs,omitempty" yaml:"strictKeySLIs,omitempty"`
+	// SLIDataCoverage enables checking how much of the evaluation timeframe is covered by the data points of metrics SLIs defined in the sli.yaml
+	SLIDataCoverage *SLIDataCoverage `json:"sliDataCoverage,omitempty" yaml:"sliDataCoverage,omitempty"`
+	// SLIMerge enables merging the SLIs of the dashboard with those defined in the sli.yaml and defines whether dashboard or file takes precedence
+	SLIMerge string `json:"sliMerge,omitempty" yaml:"sliMerge,omitempty"`
+
+	// Managed configures monitoring in further environments of the Dynatrace Managed cluster of DtCreds
+	Managed *ManagedConfig `json:"managed,omitempty" yaml:"managed,omitempty"`
+
+	// Overrides maps a stage (<stage>), a service in a stage (<stage>/<service>) or a service in all stages (*/<service>) to the settings used for it instead
+	Overrides map[string]ConfigOverride `json:"overrides,omitempty" yaml:"overrides,omitempty"`
+}
+
+// Settings of the dynatrace.conf.yaml an override can unset
+const (
+	DtCredsSetting     = "dtCreds"
+	DashboardSetting   = "dashboard"
+	AttachRulesSetting = "attachRules"
+)
+
+// SupportedUnsetSettings contains the values supported for the unset list of an override
+var SupportedUnsetSettings = []string{DtCredsSetting, DashboardSetting, AttachRulesSetting}
+
+// ConfigOverride contains the settings that can be overridden per stage or service. Only the settings that are set are overridden,
+// those listed in Unset are reset to their defaults, e.g. the default secret for dtCreds
+type ConfigOverride struct {
 	DtCreds     string                 `json:"dtCreds,omitempty" yaml:"dtCreds,omitempty"`
 	Dashboard   string                 `json:"dashboard,omitempty" yaml:"dashboard,omitempty"`
 	AttachRules *dynatrace.AttachRules `json:"attachRules,omitempty" yaml:"attachRules,omitempty"`
+	Unset       []string               `json:"unset,omitempty" yaml:"unset,omitempty"`
+}
+
+// SLIDataCoverage defines the share of the evaluation timeframe the data points of a metrics SLI are expected to cover
+type SLIDataCoverage struct {
+	// MinPercent is the minimum percentage of the evaluation timeframe covered by data points, SLIs with less coverage get a warning
+	MinPercent float64 `json:"minPercent" yaml:"minPercent"`
+	// FailBelowMinPercent makes SLIs with less coverage fail instead of only getting a warning
+	FailBelowMinPercent bool `json:"failBelowMinPercent,omitempty" yaml:"failBelowMinPercent,omitempty"`
+}
+
+// ManagedConfig defines the environments of a Dynatrace Managed cluster monitoring is configured in
+type ManagedConfig struct {
+	// ClusterCreds references the credentials containing the cluster URL and a cluster API token. It is required to discover the environments
+	ClusterCreds string `json:"clusterCreds,omitempty" yaml:"clusterCreds,omitempty"`
+	// Environments contains the IDs or names of the environments, all enabled environments are used if it is empty
+	Environments []string `json:"environments,omitempty" yaml:"environments,omitempty"`
+	// EnvironmentCreds maps the ID or name of an environment to the credentials containing its API token. Other environments are accessed using the API token of DtCreds
+	EnvironmentCreds map[string]string `json:"environmentCreds,omitempty" yaml:"environmentCreds,omitempty"`
+}
+
+// applyOverrides applies the overrides for the stage and service in the order returned by getOverrides
+func (f *DynatraceConfigFile) applyOverrides(stage string, service string) {
+	for _, override := range f.getOverrides(stage, service) {
+		override.applyTo(f)
+	}
+}
+
+// getOverrides returns the overrides for the stage and service, so that the most specific one comes last: the credentials of the stage
+// in stageDtCreds, the override of the stage, the one of the service in all stages and finally the one of the service in the stage
+func (f *DynatraceConfigFile) getOverrides(stage string, service string) []ConfigOverride {
+	var overrides []ConfigOverride
+	if stageDtCreds := f.StageDtCreds[stage]; stageDtCreds != "" {
+		overrides = append(overrides, ConfigOverride{DtCreds: stageDtCreds})
+	}
+
+	for _, key := range []string{stage, "*/" + service, stage + "/" + service} {
+		if override, ok := f.Overrides[key]; ok {
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides
+}
+
+// applyTo unsets the settings listed in Unset and then replaces those set in the override
+func (o ConfigOverride) applyTo(f *DynatraceConfigFile) {
+	for _, setting := range o.Unset {
+		switch setting {
+		case DtCredsSetting:
+			f.DtCreds = ""
+		case DashboardSetting:
+			f.Dashboard = ""
+		case AttachRulesSetting:
+			f.AttachRules = nil
+		}
+	}
+
+	if o.DtCreds != "" {
+		f.DtCreds = o.DtCreds
+	}
+	if o.Dashboard != "" {
+		f.Dashboard = o.Dashboard
+	}
+	if o.AttachRules != nil {
+		f.AttachRules = o.AttachRules
+	}
+}
+
+// GetSLIAggregation returns the configured aggregation of SLI values queried from several Dynatrace environments or sum if none is configured
+func (f *DynatraceConfigFile) GetSLIAggregation() string {
+	if f.SLIAggregation == "" {
+		return SumSLIAggregation
+	}
+	return f.SLIAggregation
 }
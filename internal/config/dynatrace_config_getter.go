@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"os"
 	"strings"
@@ -45,9 +46,11 @@ func (d *DynatraceConfigGetter) GetDynatraceConfig(event adapter.EventContentAda
 	// unmarshal the file
 	dynatraceConfFile, err := parseDynatraceConfigFile([]byte(fileContent))
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse dynatrace config file found for service %s in stage %s in project %s: %s", event.GetService(), event.GetStage(), event.GetProject(), err.Error())
+		return nil, fmt.Errorf("failed to parse dynatrace config file found for service %s in stage %s in project %s: %w", event.GetService(), event.GetStage(), event.GetProject(), err)
 	}
 
+	dynatraceConfFile.applyOverrides(event.GetStage(), event.GetService())
+
 	return dynatraceConfFile, nil
 }
 
@@ -74,9 +77,7 @@ func replaceKeptnPlaceholders(input string, event adapter.EventContentAdapter) s
 	result = strings.Replace(result, "$TESTSTRATEGY", event.GetTestStrategy(), -1)
 
 	// now we do the labels
-	for key, value := range event.GetLabels() {
-		result = strings.Replace(result, "$LABEL."+key, value, -1)
-	}
+	result = common.ReplaceLabelPlaceholders(result, event.GetLabels(), func(value string) string { return value })
 
 	// now we do all environment variables
 	for _, env := range os.Environ() {
@@ -90,8 +91,13 @@ func replaceKeptnPlaceholders(input string, event adapter.EventContentAdapter) s
 }
 
 func parseDynatraceConfigFile(input []byte) (*DynatraceConfigFile, error) {
+	err := validateDynatraceConfigFile(input)
+	if err != nil {
+		return nil, err
+	}
+
 	dynatraceConfFile := &DynatraceConfigFile{}
-	err := yaml.Unmarshal(input, dynatraceConfFile)
+	err = yaml.Unmarshal(input, dynatraceConfFile)
 
 	if err != nil {
 		return nil, err
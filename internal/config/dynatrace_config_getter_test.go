@@ -3,6 +3,10 @@ package config
 import (
 	"reflect"
 	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_parseDynatraceConfigFile(t *testing.T) {
@@ -78,3 +82,209 @@ dashboard: '****'`,
 		})
 	}
 }
+
+type dynatraceConfigResourceClientMock struct {
+	content string
+}
+
+func (m *dynatraceConfigResourceClientMock) GetDynatraceConfig(project string, stage string, service string) (string, error) {
+	return m.content, nil
+}
+
+func TestDynatraceConfigGetter_GetDynatraceConfigResolvesDtCredsPerStage(t *testing.T) {
+	const content = `
+spec_version: '0.1.0'
+dtCreds: dynatrace-preprod
+stageDtCreds:
+  production: dynatrace-prod
+  hardening: ""`
+
+	tests := []struct {
+		name        string
+		stage       string
+		wantDtCreds string
+	}{
+		{
+			name:        "stage with own credentials",
+			stage:       "production",
+			wantDtCreds: "dynatrace-prod",
+		},
+		{
+			name:        "stage without own credentials",
+			stage:       "dev",
+			wantDtCreds: "dynatrace-preprod",
+		},
+		{
+			name:        "stage with empty credentials",
+			stage:       "hardening",
+			wantDtCreds: "dynatrace-preprod",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := NewDynatraceConfigGetter(&dynatraceConfigResourceClientMock{content: content})
+
+			got, err := getter.GetDynatraceConfig(&test.EventData{Project: "sockshop", Stage: tt.stage, Service: "carts"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDtCreds, got.DtCreds)
+		})
+	}
+}
+
+func TestDynatraceConfigGetter_GetDynatraceConfigAppliesOverrides(t *testing.T) {
+	const content = `
+spec_version: '0.1.0'
+dtCreds: dynatrace-preprod
+dashboard: query
+attachRules:
+  tagRule:
+  - meTypes:
+    - SERVICE
+    tags:
+    - context: CONTEXTLESS
+      key: keptn_service
+      value: $SERVICE
+overrides:
+  production:
+    dtCreds: dynatrace-prod
+  production/carts:
+    dashboard: 12345678-1111-4444-8888-123456789012
+  '*/carts':
+    unset:
+    - dashboard
+    attachRules:
+      tagRule:
+      - meTypes:
+        - SERVICE
+        tags:
+        - context: CONTEXTLESS
+          key: app
+          value: $SERVICE`
+
+	serviceAttachRules := &dynatrace.AttachRules{TagRule: []dynatrace.TagRule{{MeTypes: []string{"SERVICE"}, Tags: []dynatrace.TagEntry{{Context: "CONTEXTLESS", Key: "keptn_service", Value: "orders"}}}}}
+	appAttachRules := &dynatrace.AttachRules{TagRule: []dynatrace.TagRule{{MeTypes: []string{"SERVICE"}, Tags: []dynatrace.TagEntry{{Context: "CONTEXTLESS", Key: "app", Value: "carts"}}}}}
+
+	tests := []struct {
+		name            string
+		stage           string
+		service         string
+		wantDtCreds     string
+		wantDashboard   string
+		wantAttachRules *dynatrace.AttachRules
+	}{
+		{
+			name:            "no override",
+			stage:           "dev",
+			service:         "orders",
+			wantDtCreds:     "dynatrace-preprod",
+			wantDashboard:   "query",
+			wantAttachRules: serviceAttachRules,
+		},
+		{
+			name:            "stage override",
+			stage:           "production",
+			service:         "orders",
+			wantDtCreds:     "dynatrace-prod",
+			wantDashboard:   "query",
+			wantAttachRules: serviceAttachRules,
+		},
+		{
+			name:            "service override in all stages",
+			stage:           "dev",
+			service:         "carts",
+			wantDtCreds:     "dynatrace-preprod",
+			wantDashboard:   "",
+			wantAttachRules: appAttachRules,
+		},
+		{
+			name:            "stage, service and service in stage overrides combined",
+			stage:           "production",
+			service:         "carts",
+			wantDtCreds:     "dynatrace-prod",
+			wantDashboard:   "12345678-1111-4444-8888-123456789012",
+			wantAttachRules: appAttachRules,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := NewDynatraceConfigGetter(&dynatraceConfigResourceClientMock{content: content})
+
+			got, err := getter.GetDynatraceConfig(&test.EventData{Project: "sockshop", Stage: tt.stage, Service: tt.service})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDtCreds, got.DtCreds)
+			assert.Equal(t, tt.wantDashboard, got.Dashboard)
+			assert.Equal(t, tt.wantAttachRules, got.AttachRules)
+		})
+	}
+}
+
+func TestDynatraceConfigGetter_GetDynatraceConfigAppliesStageDtCredsAsOverride(t *testing.T) {
+	const content = `
+spec_version: '0.1.0'
+dtCreds: dynatrace-preprod
+dashboard: query
+attachRules:
+  tagRule:
+  - meTypes:
+    - SERVICE
+    tags:
+    - context: CONTEXTLESS
+      key: keptn_service
+      value: $SERVICE
+stageDtCreds:
+  production: dynatrace-prod
+  hardening: dynatrace-hardening
+overrides:
+  production/carts:
+    unset: [dtCreds, dashboard, attachRules]
+  hardening:
+    dtCreds: dynatrace-hardening-2`
+
+	serviceAttachRules := &dynatrace.AttachRules{TagRule: []dynatrace.TagRule{{MeTypes: []string{"SERVICE"}, Tags: []dynatrace.TagEntry{{Context: "CONTEXTLESS", Key: "keptn_service", Value: "orders"}}}}}
+
+	tests := []struct {
+		name            string
+		stage           string
+		service         string
+		wantDtCreds     string
+		wantDashboard   string
+		wantAttachRules *dynatrace.AttachRules
+	}{
+		{
+			name:            "stage credentials",
+			stage:           "production",
+			service:         "orders",
+			wantDtCreds:     "dynatrace-prod",
+			wantDashboard:   "query",
+			wantAttachRules: serviceAttachRules,
+		},
+		{
+			name:        "settings unset for service in stage",
+			stage:       "production",
+			service:     "carts",
+			wantDtCreds: "",
+		},
+		{
+			name:            "override of the stage takes precedence over stage credentials",
+			stage:           "hardening",
+			service:         "orders",
+			wantDtCreds:     "dynatrace-hardening-2",
+			wantDashboard:   "query",
+			wantAttachRules: serviceAttachRules,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getter := NewDynatraceConfigGetter(&dynatraceConfigResourceClientMock{content: content})
+
+			got, err := getter.GetDynatraceConfig(&test.EventData{Project: "sockshop", Stage: tt.stage, Service: tt.service})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDtCreds, got.DtCreds)
+			assert.Equal(t, tt.wantDashboard, got.Dashboard)
+			assert.Equal(t, tt.wantAttachRules, got.AttachRules)
+		})
+	}
+}
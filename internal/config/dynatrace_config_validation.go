@@ -0,0 +1,484 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"gopkg.in/yaml.v3"
+)
+
+// supportedSpecVersions contains the spec versions of dynatrace.conf.yaml that are supported
+var supportedSpecVersions = []string{"0.1.0"}
+
+// DynatraceConfigValidationError describes an invalid entry in a dynatrace.conf.yaml file
+type DynatraceConfigValidationError struct {
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+// Error returns a string representation of this error
+func (e *DynatraceConfigValidationError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("invalid dynatrace.conf.yaml at line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("invalid dynatrace.conf.yaml at line %d, column %d: key '%s': %s", e.Line, e.Column, e.Key, e.Message)
+}
+
+// ErrorType returns the type of the error
+func (e *DynatraceConfigValidationError) ErrorType() common.ErrorType {
+	return common.UserConfigurationErrorType
+}
+
+// configSchema describes the expected structure of a YAML node.
+// Mappings either have a fixed set of fields or arbitrary keys with values described by items, sequences have items described by items
+type configSchema struct {
+	kind   yaml.Kind
+	fields map[string]*configSchema
+	items  *configSchema
+}
+
+var stringSchema = &configSchema{kind: yaml.ScalarNode}
+
+var attachRulesSchema = &configSchema{
+	kind: yaml.MappingNode,
+	fields: map[string]*configSchema{
+		"tagRule": {
+			kind: yaml.SequenceNode,
+			items: &configSchema{
+				kind: yaml.MappingNode,
+				fields: map[string]*configSchema{
+					"meTypes": {kind: yaml.SequenceNode, items: stringSchema},
+					"tags": {
+						kind: yaml.SequenceNode,
+						items: &configSchema{
+							kind: yaml.MappingNode,
+							fields: map[string]*configSchema{
+								"context": stringSchema,
+								"key":     stringSchema,
+								"value":   stringSchema,
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+var dynatraceConfigFileSchema = &configSchema{
+	kind: yaml.MappingNode,
+	fields: map[string]*configSchema{
+		"spec_version":      stringSchema,
+		"dtCreds":           stringSchema,
+		"stageDtCreds":      {kind: yaml.MappingNode, items: stringSchema},
+		"dashboard":         stringSchema,
+		"eventTypes":        {kind: yaml.MappingNode, items: stringSchema},
+		"additionalDtCreds": {kind: yaml.SequenceNode, items: stringSchema},
+		"sliAggregation":    stringSchema,
+		"strictKeySLIs":     stringSchema,
+		"sliMerge":          stringSchema,
+		"sliDataCoverage": {
+			kind: yaml.MappingNode,
+			fields: map[string]*configSchema{
+				"minPercent":          stringSchema,
+				"failBelowMinPercent": stringSchema,
+			},
+		},
+		"tls": {
+			kind: yaml.MappingNode,
+			fields: map[string]*configSchema{
+				"sslVerify":  stringSchema,
+				"caBundle":   stringSchema,
+				"minVersion": stringSchema,
+			},
+		},
+		"managed": {
+			kind: yaml.MappingNode,
+			fields: map[string]*configSchema{
+				"clusterCreds":     stringSchema,
+				"environments":     {kind: yaml.SequenceNode, items: stringSchema},
+				"environmentCreds": {kind: yaml.MappingNode, items: stringSchema},
+			},
+		},
+		"attachRules": attachRulesSchema,
+		"overrides": {
+			kind: yaml.MappingNode,
+			items: &configSchema{
+				kind: yaml.MappingNode,
+				fields: map[string]*configSchema{
+					"dtCreds":     stringSchema,
+					"dashboard":   stringSchema,
+					"attachRules": attachRulesSchema,
+					"unset":       {kind: yaml.SequenceNode, items: stringSchema},
+				},
+			},
+		},
+	},
+}
+
+// validateDynatraceConfigFile strictly validates the content of a dynatrace.conf.yaml file and reports the position of the first invalid entry
+func validateDynatraceConfigFile(input []byte) error {
+	var document yaml.Node
+	err := yaml.Unmarshal(input, &document)
+	if err != nil {
+		return common.NewUserConfigurationError(fmt.Errorf("invalid dynatrace.conf.yaml: %w", err))
+	}
+
+	// an empty file is valid
+	if len(document.Content) == 0 {
+		return nil
+	}
+
+	root := document.Content[0]
+	err = validateNode(root, dynatraceConfigFileSchema, "")
+	if err != nil {
+		return err
+	}
+
+	err = validateSpecVersion(root)
+	if err != nil {
+		return err
+	}
+
+	err = validateEventTypes(root)
+	if err != nil {
+		return err
+	}
+
+	err = validateOverrides(root)
+	if err != nil {
+		return err
+	}
+
+	err = validateDataCoverage(root)
+	if err != nil {
+		return err
+	}
+
+	err = validateSupportedValue(root, "sliAggregation", "aggregation", SupportedSLIAggregations)
+	if err != nil {
+		return err
+	}
+
+	return validateSupportedValue(root, "sliMerge", "precedence", SupportedSLIMerges)
+}
+
+func validateNode(node *yaml.Node, schema *configSchema, path string) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+
+	// null values are treated as if the key was not specified
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return nil
+	}
+
+	if node.Kind != schema.kind {
+		return &DynatraceConfigValidationError{
+			Line:    node.Line,
+			Column:  node.Column,
+			Key:     path,
+			Message: fmt.Sprintf("expected %s but found %s", getKindName(schema.kind), getKindName(node.Kind)),
+		}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		return validateMappingNode(node, schema, path)
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := validateNode(item, schema.items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateMappingNode(node *yaml.Node, schema *configSchema, path string) error {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		valueNode := node.Content[i+1]
+		keyPath := joinPath(path, keyNode.Value)
+
+		valueSchema := schema.items
+		if schema.fields != nil {
+			var ok bool
+			valueSchema, ok = schema.fields[keyNode.Value]
+			if !ok {
+				return &DynatraceConfigValidationError{
+					Line:    keyNode.Line,
+					Column:  keyNode.Column,
+					Key:     keyPath,
+					Message: fmt.Sprintf("unknown field, expected one of: %s", strings.Join(getFieldNames(schema), ", ")),
+				}
+			}
+		}
+
+		if err := validateNode(valueNode, valueSchema, keyPath); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateSpecVersion(root *yaml.Node) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "spec_version" {
+			continue
+		}
+
+		valueNode := root.Content[i+1]
+		for _, version := range supportedSpecVersions {
+			if valueNode.Value == version {
+				return nil
+			}
+		}
+
+		return &DynatraceConfigValidationError{
+			Line:    valueNode.Line,
+			Column:  valueNode.Column,
+			Key:     "spec_version",
+			Message: fmt.Sprintf("unsupported version '%s', expected one of: %s", valueNode.Value, strings.Join(supportedSpecVersions, ", ")),
+		}
+	}
+
+	return nil
+}
+
+func validateEventTypes(root *yaml.Node) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "eventTypes" {
+			continue
+		}
+
+		eventTypesNode := root.Content[i+1]
+		if eventTypesNode.Kind == yaml.AliasNode {
+			eventTypesNode = eventTypesNode.Alias
+		}
+
+		for j := 0; j+1 < len(eventTypesNode.Content); j += 2 {
+			valueNode := eventTypesNode.Content[j+1]
+			if valueNode.Tag == "!!null" || dynatrace.IsSupportedEventType(valueNode.Value) {
+				continue
+			}
+
+			return &DynatraceConfigValidationError{
+				Line:    valueNode.Line,
+				Column:  valueNode.Column,
+				Key:     joinPath("eventTypes", eventTypesNode.Content[j].Value),
+				Message: fmt.Sprintf("unsupported Dynatrace event type '%s', expected one of: %s", valueNode.Value, strings.Join(dynatrace.SupportedEventTypes, ", ")),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOverrides validates that the keys of overrides reference a stage, a service in a stage or a service in all stages
+// and that the settings they unset are supported and not set by the same override
+func validateOverrides(root *yaml.Node) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "overrides" {
+			continue
+		}
+
+		overridesNode := root.Content[i+1]
+		if overridesNode.Kind == yaml.AliasNode {
+			overridesNode = overridesNode.Alias
+		}
+
+		for j := 0; j+1 < len(overridesNode.Content); j += 2 {
+			keyNode := overridesNode.Content[j]
+			if !isValidOverrideKey(keyNode.Value) {
+				return &DynatraceConfigValidationError{
+					Line:    keyNode.Line,
+					Column:  keyNode.Column,
+					Key:     joinPath("overrides", keyNode.Value),
+					Message: "expected <stage>, <stage>/<service> or */<service>",
+				}
+			}
+
+			err := validateOverrideUnset(overridesNode.Content[j+1], joinPath("overrides", keyNode.Value))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateOverrideUnset validates the settings listed in the unset list of the override
+func validateOverrideUnset(overrideNode *yaml.Node, path string) error {
+	if overrideNode.Kind == yaml.AliasNode {
+		overrideNode = overrideNode.Alias
+	}
+
+	setSettings := map[string]bool{}
+	var unsetNode *yaml.Node
+	for i := 0; i+1 < len(overrideNode.Content); i += 2 {
+		if overrideNode.Content[i].Value == "unset" {
+			unsetNode = overrideNode.Content[i+1]
+		} else if overrideNode.Content[i+1].Tag != "!!null" {
+			setSettings[overrideNode.Content[i].Value] = true
+		}
+	}
+
+	if unsetNode == nil {
+		return nil
+	}
+	if unsetNode.Kind == yaml.AliasNode {
+		unsetNode = unsetNode.Alias
+	}
+
+	for i, settingNode := range unsetNode.Content {
+		message := ""
+		if !isSupportedValue(settingNode.Value, SupportedUnsetSettings) {
+			message = fmt.Sprintf("unsupported setting '%s', expected one of: %s", settingNode.Value, strings.Join(SupportedUnsetSettings, ", "))
+		} else if setSettings[settingNode.Value] {
+			message = fmt.Sprintf("setting '%s' cannot be set and unset by the same override", settingNode.Value)
+		} else {
+			continue
+		}
+
+		return &DynatraceConfigValidationError{
+			Line:    settingNode.Line,
+			Column:  settingNode.Column,
+			Key:     fmt.Sprintf("%s[%d]", joinPath(path, "unset"), i),
+			Message: message,
+		}
+	}
+
+	return nil
+}
+
+func isValidOverrideKey(key string) bool {
+	parts := strings.Split(key, "/")
+	switch len(parts) {
+	case 1:
+		return parts[0] != "" && parts[0] != "*"
+	case 2:
+		return parts[0] != "" && parts[1] != ""
+	default:
+		return false
+	}
+}
+
+// validateDataCoverage validates that the minimum data coverage of SLIs is a percentage
+func validateDataCoverage(root *yaml.Node) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "sliDataCoverage" {
+			continue
+		}
+
+		dataCoverageNode := root.Content[i+1]
+		if dataCoverageNode.Kind == yaml.AliasNode {
+			dataCoverageNode = dataCoverageNode.Alias
+		}
+
+		for j := 0; j+1 < len(dataCoverageNode.Content); j += 2 {
+			if dataCoverageNode.Content[j].Value != "minPercent" {
+				continue
+			}
+
+			valueNode := dataCoverageNode.Content[j+1]
+			if valueNode.Kind == yaml.AliasNode {
+				valueNode = valueNode.Alias
+			}
+			if valueNode.Tag == "!!null" {
+				return nil
+			}
+
+			minPercent, err := strconv.ParseFloat(valueNode.Value, 64)
+			if err == nil && minPercent >= 0 && minPercent <= 100 {
+				return nil
+			}
+
+			return &DynatraceConfigValidationError{
+				Line:    valueNode.Line,
+				Column:  valueNode.Column,
+				Key:     "sliDataCoverage.minPercent",
+				Message: fmt.Sprintf("invalid percentage '%s', expected a number between 0 and 100", valueNode.Value),
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSupportedValue validates that the value of a top-level key is one of the supported values
+func validateSupportedValue(root *yaml.Node, key string, description string, supportedValues []string) error {
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != key {
+			continue
+		}
+
+		valueNode := root.Content[i+1]
+		if valueNode.Kind == yaml.AliasNode {
+			valueNode = valueNode.Alias
+		}
+		if valueNode.Tag == "!!null" {
+			return nil
+		}
+
+		if isSupportedValue(valueNode.Value, supportedValues) {
+			return nil
+		}
+
+		return &DynatraceConfigValidationError{
+			Line:    valueNode.Line,
+			Column:  valueNode.Column,
+			Key:     key,
+			Message: fmt.Sprintf("unsupported %s '%s', expected one of: %s", description, valueNode.Value, strings.Join(supportedValues, ", ")),
+		}
+	}
+
+	return nil
+}
+
+func isSupportedValue(value string, supportedValues []string) bool {
+	for _, supportedValue := range supportedValues {
+		if value == supportedValue {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func getFieldNames(schema *configSchema) []string {
+	names := make([]string, 0, len(schema.fields))
+	for name := range schema.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getKindName(kind yaml.Kind) string {
+	switch kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.ScalarNode:
+		return "a value"
+	default:
+		return "an unsupported element"
+	}
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_validateDynatraceConfigFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		yamlString string
+		wantErr    string
+	}{
+		{
+			name:       "empty file",
+			yamlString: "",
+		},
+		{
+			name: "valid file with all fields",
+			yamlString: `
+spec_version: '0.1.0'
+dtCreds: dynatrace
+stageDtCreds:
+  production: dynatrace-prod
+dashboard: query
+attachRules:
+  tagRule:
+  - meTypes:
+    - SERVICE
+    tags:
+    - context: CONTEXTLESS
+      key: keptn_service
+      value: carts
+tls:
+  sslVerify: true
+  caBundle: /etc/dynatrace/ca.pem
+  minVersion: '1.2'
+eventTypes:
+  deployment.finished: CUSTOM_INFO
+  sh.keptn.event.test.triggered: NONE
+strictKeySLIs: true
+sliDataCoverage:
+  minPercent: 90
+  failBelowMinPercent: true
+additionalDtCreds:
+- dynatrace-saas
+sliAggregation: avg
+sliMerge: dashboard
+managed:
+  clusterCreds: dynatrace-cluster
+  environments:
+  - production
+  environmentCreds:
+    production: dynatrace-production
+overrides:
+  production:
+    dtCreds: dynatrace-prod
+  production/carts:
+    dashboard: 12345678-1111-4444-8888-123456789012
+  '*/carts':
+    attachRules:
+      tagRule:
+      - meTypes:
+        - SERVICE
+        tags:
+        - context: CONTEXTLESS
+          key: app
+          value: carts`,
+		},
+		{
+			name: "unknown field",
+			yamlString: `
+spec_version: '0.1.0'
+dtCred: dynatrace`,
+			wantErr: "invalid dynatrace.conf.yaml at line 3, column 1: key 'dtCred': unknown field, expected one of: additionalDtCreds, attachRules, dashboard, dtCreds, eventTypes, managed, overrides, sliAggregation, sliDataCoverage, sliMerge, spec_version, stageDtCreds, strictKeySLIs, tls",
+		},
+		{
+			name: "unknown nested field",
+			yamlString: `
+spec_version: '0.1.0'
+attachRules:
+  tagRule:
+  - meTypes:
+    - SERVICE
+    tag:
+    - key: keptn_service`,
+			wantErr: "invalid dynatrace.conf.yaml at line 7, column 5: key 'attachRules.tagRule[0].tag': unknown field, expected one of: meTypes, tags",
+		},
+		{
+			name: "wrong type",
+			yamlString: `
+spec_version: '0.1.0'
+dashboard:
+  id: 12345`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 3: key 'dashboard': expected a value but found a mapping",
+		},
+		{
+			name: "unsupported spec_version",
+			yamlString: `
+spec_version: '0.2.0'
+dtCreds: dynatrace`,
+			wantErr: "invalid dynatrace.conf.yaml at line 2, column 15: key 'spec_version': unsupported version '0.2.0', expected one of: 0.1.0",
+		},
+		{
+			name: "unsupported Dynatrace event type",
+			yamlString: `
+spec_version: '0.1.0'
+eventTypes:
+  deployment.finished: CUSTOM_EVENT`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 24: key 'eventTypes.deployment.finished': unsupported Dynatrace event type 'CUSTOM_EVENT', expected one of: CUSTOM_INFO, CUSTOM_ANNOTATION, CUSTOM_CONFIGURATION, CUSTOM_DEPLOYMENT, MARKED_FOR_TERMINATION, ERROR_EVENT, AVAILABILITY_EVENT, NONE",
+		},
+		{
+			name: "unsupported SLI aggregation",
+			yamlString: `
+spec_version: '0.1.0'
+additionalDtCreds:
+- dynatrace-saas
+sliAggregation: median`,
+			wantErr: "invalid dynatrace.conf.yaml at line 5, column 17: key 'sliAggregation': unsupported aggregation 'median', expected one of: sum, avg, max, min",
+		},
+		{
+			name: "unsupported SLI merge",
+			yamlString: `
+spec_version: '0.1.0'
+dashboard: query
+sliMerge: sli.yaml`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 11: key 'sliMerge': unsupported precedence 'sli.yaml', expected one of: dashboard, file",
+		},
+		{
+			name: "SLI data coverage above 100 percent",
+			yamlString: `
+spec_version: '0.1.0'
+sliDataCoverage:
+  minPercent: 150`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 15: key 'sliDataCoverage.minPercent': invalid percentage '150', expected a number between 0 and 100",
+		},
+		{
+			name: "SLI data coverage not a number",
+			yamlString: `
+spec_version: '0.1.0'
+sliDataCoverage:
+  minPercent: most`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 15: key 'sliDataCoverage.minPercent': invalid percentage 'most', expected a number between 0 and 100",
+		},
+		{
+			name: "additional credentials not a list",
+			yamlString: `
+spec_version: '0.1.0'
+additionalDtCreds: dynatrace-saas`,
+			wantErr: "invalid dynatrace.conf.yaml at line 3, column 20: key 'additionalDtCreds': expected a list but found a value",
+		},
+		{
+			name: "unknown field in override",
+			yamlString: `
+spec_version: '0.1.0'
+overrides:
+  production:
+    sliMerge: file`,
+			wantErr: "invalid dynatrace.conf.yaml at line 5, column 5: key 'overrides.production.sliMerge': unknown field, expected one of: attachRules, dashboard, dtCreds, unset",
+		},
+		{
+			name: "invalid override key",
+			yamlString: `
+spec_version: '0.1.0'
+overrides:
+  production/carts/v2:
+    dashboard: query`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 3: key 'overrides.production/carts/v2': expected <stage>, <stage>/<service> or */<service>",
+		},
+		{
+			name: "unsupported setting unset by override",
+			yamlString: `
+spec_version: '0.1.0'
+overrides:
+  production:
+    unset: [dashboard, tls]`,
+			wantErr: "invalid dynatrace.conf.yaml at line 5, column 24: key 'overrides.production.unset[1]': unsupported setting 'tls', expected one of: dtCreds, dashboard, attachRules",
+		},
+		{
+			name: "setting set and unset by the same override",
+			yamlString: `
+spec_version: '0.1.0'
+overrides:
+  production:
+    dashboard: query
+    unset:
+    - dashboard`,
+			wantErr: "invalid dynatrace.conf.yaml at line 7, column 7: key 'overrides.production.unset[0]': setting 'dashboard' cannot be set and unset by the same override",
+		},
+		{
+			name: "managed environments not a list",
+			yamlString: `
+spec_version: '0.1.0'
+managed:
+  environments: production`,
+			wantErr: "invalid dynatrace.conf.yaml at line 4, column 17: key 'managed.environments': expected a list but found a value",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDynatraceConfigFile([]byte(tt.yamlString))
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			if assert.Error(t, err) {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, common.UserConfigurationErrorType, common.GetErrorType(err))
+			}
+		})
+	}
+}
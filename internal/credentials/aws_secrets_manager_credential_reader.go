@@ -0,0 +1,103 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awscredentials "github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const awsWebIdentitySessionName = "dynatrace-service"
+
+// AWSSecretsManagerOptions defines how secrets are read from AWS Secrets Manager
+type AWSSecretsManagerOptions struct {
+	// Region of the Secrets Manager
+	Region string
+	// Endpoint of the Secrets Manager. The public endpoint of the region is used by default
+	Endpoint string
+	// STSEndpoint is the endpoint of the AWS Security Token Service. The public endpoint of the region is used by default
+	STSEndpoint string
+	// SecretNamePrefix is added to the names of the secrets
+	SecretNamePrefix string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are static AWS credentials
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// RoleARN and WebIdentityTokenFile are used to assume a role with a web identity, e.g. of IAM roles for service accounts, if no static credentials are set
+	RoleARN              string
+	WebIdentityTokenFile string
+}
+
+// AWSSecretsManagerCredentialReader reads secrets from AWS Secrets Manager.
+// The secret <SecretNamePrefix><secretName> is read and its value must be a JSON object containing the keys, e.g. {"DT_TENANT": "...", "DT_API_TOKEN": "..."}
+type AWSSecretsManagerCredentialReader struct {
+	client           *secretsmanager.Client
+	secretNamePrefix string
+}
+
+// NewAWSSecretsManagerCredentialReader creates a new AWSSecretsManagerCredentialReader
+func NewAWSSecretsManagerCredentialReader(httpClient *http.Client, options AWSSecretsManagerOptions) (*AWSSecretsManagerCredentialReader, error) {
+	if options.Region == "" {
+		return nil, errors.New("could not initialize AWSSecretsManagerCredentialReader: no AWS region specified")
+	}
+
+	var credentialsProvider aws.CredentialsProvider
+	switch {
+	case options.AccessKeyID != "" && options.SecretAccessKey != "":
+		credentialsProvider = awscredentials.NewStaticCredentialsProvider(options.AccessKeyID, options.SecretAccessKey, options.SessionToken)
+	case options.RoleARN != "" && options.WebIdentityTokenFile != "":
+		stsOptions := sts.Options{
+			Region:     options.Region,
+			HTTPClient: httpClient,
+		}
+		if options.STSEndpoint != "" {
+			stsOptions.EndpointResolver = sts.EndpointResolverFromURL(options.STSEndpoint)
+		}
+
+		// the cache assumes the role again shortly before the credentials expire
+		credentialsProvider = aws.NewCredentialsCache(stscreds.NewWebIdentityRoleProvider(sts.New(stsOptions), options.RoleARN, stscreds.IdentityTokenFile(options.WebIdentityTokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			o.RoleSessionName = awsWebIdentitySessionName
+		}))
+	default:
+		return nil, errors.New("could not initialize AWSSecretsManagerCredentialReader: neither static AWS credentials nor a role with a web identity token file specified")
+	}
+
+	secretsManagerOptions := secretsmanager.Options{
+		Region:      options.Region,
+		Credentials: credentialsProvider,
+		HTTPClient:  httpClient,
+	}
+	if options.Endpoint != "" {
+		secretsManagerOptions.EndpointResolver = secretsmanager.EndpointResolverFromURL(options.Endpoint)
+	}
+
+	return &AWSSecretsManagerCredentialReader{
+		client:           secretsmanager.New(secretsManagerOptions),
+		secretNamePrefix: options.SecretNamePrefix,
+	}, nil
+}
+
+// ReadSecret reads the key of the secret from AWS Secrets Manager. The namespace is ignored
+func (r *AWSSecretsManagerCredentialReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	secretID := r.secretNamePrefix + secretName
+
+	output, err := r.client.GetSecretValue(context.Background(), &secretsmanager.GetSecretValueInput{SecretId: aws.String(secretID)})
+	if err != nil {
+		var notFoundErr *types.ResourceNotFoundException
+		if errors.As(err, &notFoundErr) {
+			return "", fmt.Errorf("secret %s was not found in AWS Secrets Manager: %w", secretID, ErrSecretNotFound)
+		}
+		return "", fmt.Errorf("could not read secret %s from AWS Secrets Manager: %w", secretID, err)
+	}
+
+	return readJSONSecretKey(aws.ToString(output.SecretString), secretID, secretKey)
+}
@@ -0,0 +1,93 @@
+package credentials
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSSecretsManagerCredentialReader_ReadSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/eu-central-1/secretsmanager/aws4_request")
+
+		body, _ := ioutil.ReadAll(r.Body)
+		switch string(body) {
+		case `{"SecretId":"keptn/dynatrace"}`:
+			w.Write([]byte(`{"Name": "keptn/dynatrace", "SecretString": "{\"DT_TENANT\": \"https://mySampleEnv.live.dynatrace.com\", \"DT_API_TOKEN\": \"abc123\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"__type": "ResourceNotFoundException", "message": "Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer server.Close()
+
+	reader, err := NewAWSSecretsManagerCredentialReader(server.Client(), AWSSecretsManagerOptions{
+		Region:           "eu-central-1",
+		Endpoint:         server.URL,
+		SecretNamePrefix: "keptn/",
+		AccessKeyID:      "AKIDEXAMPLE",
+		SecretAccessKey:  "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	})
+	assert.NoError(t, err)
+
+	apiToken, err := reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", apiToken)
+
+	_, err = reader.ReadSecret("dynatrace-prod", "keptn", "DT_API_TOKEN")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestAWSSecretsManagerCredentialReader_AssumesRoleWithWebIdentity(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("web-identity-token"), 0600))
+
+	expiration := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	stsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "AssumeRoleWithWebIdentity", r.Form.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/dynatrace-service", r.Form.Get("RoleArn"))
+		assert.Equal(t, "web-identity-token", r.Form.Get("WebIdentityToken"))
+
+		w.Write([]byte(`<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session-token</SessionToken>
+      <Expiration>` + expiration + `</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`))
+	}))
+	defer stsServer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "session-token", r.Header.Get("X-Amz-Security-Token"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=ASIAEXAMPLE/"))
+		w.Write([]byte(`{"SecretString": "{\"DT_TENANT\": \"https://mySampleEnv.live.dynatrace.com\"}"}`))
+	}))
+	defer server.Close()
+
+	reader, err := NewAWSSecretsManagerCredentialReader(http.DefaultClient, AWSSecretsManagerOptions{
+		Region:               "eu-central-1",
+		Endpoint:             server.URL,
+		STSEndpoint:          stsServer.URL,
+		RoleARN:              "arn:aws:iam::123456789012:role/dynatrace-service",
+		WebIdentityTokenFile: tokenFile,
+	})
+	assert.NoError(t, err)
+
+	tenant, err := reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mySampleEnv.live.dynatrace.com", tenant)
+}
@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/security/keyvault/azsecrets"
+)
+
+// AzureKeyVaultOptions defines how secrets are read from Azure Key Vault
+type AzureKeyVaultOptions struct {
+	// VaultURL is the URL of the Key Vault, e.g. https://my-vault.vault.azure.net
+	VaultURL string
+	// AuthorityHost of Microsoft Entra ID. https://login.microsoftonline.com is used by default
+	AuthorityHost string
+	// TenantID and ClientID identify the application used to authenticate against the Key Vault
+	TenantID string
+	ClientID string
+	// ClientSecret of the application
+	ClientSecret string
+	// FederatedTokenFile contains a token of Azure workload identity, used if no ClientSecret is set
+	FederatedTokenFile string
+}
+
+// AzureKeyVaultCredentialReader reads secrets from Azure Key Vault.
+// The value of the secret <secretName> must be a JSON object containing the keys, e.g. {"DT_TENANT": "...", "DT_API_TOKEN": "..."}
+type AzureKeyVaultCredentialReader struct {
+	client *azsecrets.Client
+}
+
+// NewAzureKeyVaultCredentialReader creates a new AzureKeyVaultCredentialReader
+func NewAzureKeyVaultCredentialReader(httpClient *http.Client, options AzureKeyVaultOptions) (*AzureKeyVaultCredentialReader, error) {
+	if options.VaultURL == "" {
+		return nil, errors.New("could not initialize AzureKeyVaultCredentialReader: no Key Vault URL specified")
+	}
+
+	if options.TenantID == "" || options.ClientID == "" {
+		return nil, errors.New("could not initialize AzureKeyVaultCredentialReader: no tenant or client ID specified")
+	}
+
+	clientOptions := azcore.ClientOptions{Transport: httpClient}
+	if options.AuthorityHost != "" {
+		clientOptions.Cloud = cloud.Configuration{ActiveDirectoryAuthorityHost: options.AuthorityHost}
+	}
+
+	var credential azcore.TokenCredential
+	var err error
+	switch {
+	case options.ClientSecret != "":
+		credential, err = azidentity.NewClientSecretCredential(options.TenantID, options.ClientID, options.ClientSecret, &azidentity.ClientSecretCredentialOptions{ClientOptions: clientOptions})
+	case options.FederatedTokenFile != "":
+		credential, err = azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      options.TenantID,
+			ClientID:      options.ClientID,
+			TokenFilePath: options.FederatedTokenFile,
+		})
+	default:
+		return nil, errors.New("could not initialize AzureKeyVaultCredentialReader: neither a client secret nor a federated token file specified")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize AzureKeyVaultCredentialReader: %w", err)
+	}
+
+	client, err := azsecrets.NewClient(options.VaultURL, credential, &azsecrets.ClientOptions{ClientOptions: clientOptions})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize AzureKeyVaultCredentialReader: %w", err)
+	}
+
+	return &AzureKeyVaultCredentialReader{client: client}, nil
+}
+
+// ReadSecret reads the key of the latest version of the secret from Azure Key Vault. The namespace is ignored
+func (r *AzureKeyVaultCredentialReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	response, err := r.client.GetSecret(context.Background(), secretName, "", nil)
+	if err != nil {
+		var responseErr *azcore.ResponseError
+		if errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusNotFound {
+			return "", fmt.Errorf("secret %s was not found in Azure Key Vault: %w", secretName, ErrSecretNotFound)
+		}
+		return "", fmt.Errorf("could not read secret %s from Azure Key Vault: %w", secretName, err)
+	}
+
+	if response.Value == nil {
+		return "", fmt.Errorf("secret %s read from Azure Key Vault has no value", secretName)
+	}
+
+	return readJSONSecretKey(*response.Value, secretName, secretKey)
+}
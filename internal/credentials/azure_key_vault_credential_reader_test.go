@@ -0,0 +1,144 @@
+package credentials
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAzureKeyVaultURL = "https://my-vault.vault.azure.net"
+
+// newAzureTestServer returns a server acting as Microsoft Entra ID of the tenant my-tenant and as Key Vault containing the secret dynatrace,
+// and a client sending all requests to it. Tokens are requested from the tokenHandler
+func newAzureTestServer(t *testing.T, tokenHandler http.HandlerFunc) (*httptest.Server, *http.Client) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/common/discovery/instance":
+			w.Write([]byte(`{"tenant_discovery_endpoint": "https://login.microsoftonline.com/my-tenant/v2.0/.well-known/openid-configuration", "api-version": "1.1", "metadata": []}`))
+		case "/my-tenant/v2.0/.well-known/openid-configuration":
+			w.Write([]byte(`{"token_endpoint": "https://login.microsoftonline.com/my-tenant/oauth2/v2.0/token", "authorization_endpoint": "https://login.microsoftonline.com/my-tenant/oauth2/v2.0/authorize", "issuer": "https://login.microsoftonline.com/my-tenant/v2.0"}`))
+		case "/my-tenant/oauth2/v2.0/token":
+			tokenHandler(w, r)
+		case "/secrets/dynatrace/":
+			// the Key Vault challenges unauthenticated requests to discover the tenant and scope of the access token
+			if r.Header.Get("Authorization") == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer authorization="https://login.microsoftonline.com/my-tenant", resource="https://vault.azure.net"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			assert.Equal(t, "Bearer access-token", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"id": "https://my-vault.vault.azure.net/secrets/dynatrace/1", "value": "{\"DT_TENANT\": \"https://mySampleEnv.live.dynatrace.com\", \"DT_API_TOKEN\": \"abc123\"}"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": {"code": "SecretNotFound", "message": "A secret with the name was not found in this key vault."}}`))
+		}
+	}))
+
+	client := server.Client()
+	transport := client.Transport
+	client.Transport = roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.URL.Host = server.Listener.Addr().String()
+		return transport.RoundTrip(req)
+	})
+	return server, client
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestAzureKeyVaultCredentialReader_ReadSecret(t *testing.T) {
+	tokenRequests := 0
+	server, client := newAzureTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "my-client", r.Form.Get("client_id"))
+		assert.Equal(t, "my-secret", r.Form.Get("client_secret"))
+		assert.Contains(t, r.Form.Get("scope"), "https://vault.azure.net/.default")
+
+		tokenRequests++
+		w.Write([]byte(`{"token_type": "Bearer", "expires_in": 3599, "access_token": "access-token"}`))
+	})
+	defer server.Close()
+
+	reader, err := NewAzureKeyVaultCredentialReader(client, AzureKeyVaultOptions{
+		VaultURL:     testAzureKeyVaultURL,
+		TenantID:     "my-tenant",
+		ClientID:     "my-client",
+		ClientSecret: "my-secret",
+	})
+	assert.NoError(t, err)
+
+	tenant, err := reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mySampleEnv.live.dynatrace.com", tenant)
+
+	apiToken, err := reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", apiToken)
+
+	_, err = reader.ReadSecret("dynatrace-prod", "keptn", "DT_API_TOKEN")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	// the access token is reused
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestAzureKeyVaultCredentialReader_UsesFederatedToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("federated-token"), 0600))
+
+	server, client := newAzureTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		assert.Equal(t, "my-client", r.Form.Get("client_id"))
+		assert.Equal(t, "urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.Form.Get("client_assertion_type"))
+		assert.Equal(t, "federated-token", r.Form.Get("client_assertion"))
+		assert.Empty(t, r.Form.Get("client_secret"))
+
+		w.Write([]byte(`{"token_type": "Bearer", "expires_in": 3599, "access_token": "access-token"}`))
+	})
+	defer server.Close()
+
+	reader, err := NewAzureKeyVaultCredentialReader(client, AzureKeyVaultOptions{
+		VaultURL:           testAzureKeyVaultURL,
+		TenantID:           "my-tenant",
+		ClientID:           "my-client",
+		FederatedTokenFile: tokenFile,
+	})
+	assert.NoError(t, err)
+
+	apiToken, err := reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", apiToken)
+}
+
+func TestAzureKeyVaultCredentialReader_ReturnsTokenError(t *testing.T) {
+	server, client := newAzureTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "invalid_client", "error_description": "Invalid client secret provided."}`))
+	})
+	defer server.Close()
+
+	reader, err := NewAzureKeyVaultCredentialReader(client, AzureKeyVaultOptions{
+		VaultURL:     testAzureKeyVaultURL,
+		TenantID:     "my-tenant",
+		ClientID:     "my-client",
+		ClientSecret: "wrong-secret",
+	})
+	assert.NoError(t, err)
+
+	_, err = reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid_client")
+		assert.False(t, errors.Is(err, ErrSecretNotFound))
+	}
+}
@@ -2,7 +2,6 @@ package credentials
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -11,7 +10,10 @@ import (
 
 	"k8s.io/client-go/kubernetes"
 
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
 	keptnkubeutils "github.com/keptn/kubernetes-utils/pkg"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -20,6 +22,10 @@ type DTCredentials struct {
 	// Base URL of Dynatrace tenant. This is always prefixed with "https://" or "http://"
 	Tenant   string `json:"DT_TENANT" yaml:"DT_TENANT"`
 	ApiToken string `json:"DT_API_TOKEN" yaml:"DT_API_TOKEN"`
+	// SecondaryApiToken is optional and used if the Dynatrace API rejects ApiToken, e.g. while the token is being rotated
+	SecondaryApiToken string `json:"DT_API_TOKEN_SECONDARY,omitempty" yaml:"DT_API_TOKEN_SECONDARY,omitempty"`
+	// ConfigAPIURL is optional and used instead of Tenant as the base URL of the configuration API, e.g. if only Tenant points at an Environment ActiveGate
+	ConfigAPIURL string `json:"DT_CONFIG_API_URL,omitempty" yaml:"DT_CONFIG_API_URL,omitempty"`
 }
 
 type KeptnAPICredentials struct {
@@ -27,22 +33,10 @@ type KeptnAPICredentials struct {
 	APIToken string `json:"KEPTN_API_TOKEN" yaml:"KEPTN_API_TOKEN"`
 }
 
-var namespace = getPodNamespace()
-
 var ErrSecretNotFound = errors.New("secret not found")
 
-func getKubernetesClient() (*kubernetes.Clientset, error) {
-	useInClusterConfig := os.Getenv("KUBERNETES_SERVICE_HOST") != ""
-	return keptnkubeutils.GetClientset(useInClusterConfig)
-}
-
-func getPodNamespace() string {
-	ns := os.Getenv("POD_NAMESPACE")
-	if ns == "" {
-		return "keptn"
-	}
-
-	return ns
+func getKubernetesClient(cfg *env.Config) (*kubernetes.Clientset, error) {
+	return keptnkubeutils.GetClientset(cfg.InCluster)
 }
 
 type SecretReader interface {
@@ -53,12 +47,13 @@ type K8sCredentialReader struct {
 	K8sClient kubernetes.Interface
 }
 
-func NewK8sCredentialReader(k8sClient kubernetes.Interface) (*K8sCredentialReader, error) {
+// NewK8sCredentialReader creates a new K8sCredentialReader using the given client or, if it is nil, a client created using the configuration
+func NewK8sCredentialReader(k8sClient kubernetes.Interface, cfg *env.Config) (*K8sCredentialReader, error) {
 	k8sCredentialReader := &K8sCredentialReader{}
 	if k8sClient != nil {
 		k8sCredentialReader.K8sClient = k8sClient
 	} else {
-		client, err := getKubernetesClient()
+		client, err := getKubernetesClient(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("could not initialize K8sCredentialReader: %s", err.Error())
 		}
@@ -69,6 +64,9 @@ func NewK8sCredentialReader(k8sClient kubernetes.Interface) (*K8sCredentialReade
 
 func (kcr *K8sCredentialReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
 	secret, err := kcr.K8sClient.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return "", fmt.Errorf("secret %s was not found in namespace %s: %w", secretName, namespace, ErrSecretNotFound)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -96,14 +94,19 @@ type CredentialManagerInterface interface {
 
 type CredentialManager struct {
 	SecretReader SecretReader
+	// namespace is the namespace of the secrets, i.e. the one of the pod
+	namespace string
+	// cfg provides the Keptn API credentials and bridge URL used if the secret does not contain them
+	cfg *env.Config
 }
 
-func NewCredentialManager(sr SecretReader) (*CredentialManager, error) {
-	cm := &CredentialManager{}
+// NewCredentialManager creates a new CredentialManager using the given SecretReader or, if it is nil, the one of the secret backend of the configuration
+func NewCredentialManager(sr SecretReader, cfg *env.Config) (*CredentialManager, error) {
+	cm := &CredentialManager{namespace: cfg.PodNamespace, cfg: cfg}
 	if sr != nil {
 		cm.SecretReader = sr
 	} else {
-		sr, err := NewK8sCredentialReader(nil)
+		sr, err := getDefaultSecretReader(cfg)
 		if err != nil {
 			return nil, fmt.Errorf("could not initialize CredentialManager: %s", err.Error())
 		}
@@ -113,33 +116,73 @@ func NewCredentialManager(sr SecretReader) (*CredentialManager, error) {
 }
 
 func (cm *CredentialManager) GetDynatraceCredentials(secretName string) (*DTCredentials, error) {
-	dtTenant, err := cm.SecretReader.ReadSecret(secretName, namespace, "DT_TENANT")
+	dtTenant, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "DT_TENANT")
 	if err != nil {
-		return nil, fmt.Errorf("key DT_TENANT was not found in secret \"%s\"", secretName)
+		return nil, newRequiredKeyError("DT_TENANT", secretName, err)
 	}
 
-	dtAPIToken, err := cm.SecretReader.ReadSecret(secretName, namespace, "DT_API_TOKEN")
+	tenantURL, err := NormalizeTenantURL(dtTenant)
 	if err != nil {
-		return nil, fmt.Errorf("key DT_API_TOKEN was not found in secret \"%s\"", secretName)
+		return nil, fmt.Errorf("key DT_TENANT of secret \"%s\" is invalid: %w", secretName, err)
+	}
+
+	dtAPIToken, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "DT_API_TOKEN")
+	if err != nil {
+		return nil, newRequiredKeyError("DT_API_TOKEN", secretName, err)
+	}
+
+	// the secondary token is optional, so it is only read if it exists
+	dtSecondaryAPIToken, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "DT_API_TOKEN_SECONDARY")
+	if err != nil {
+		if !errors.Is(err, ErrSecretNotFound) {
+			return nil, fmt.Errorf("could not read key DT_API_TOKEN_SECONDARY of secret \"%s\": %w", secretName, err)
+		}
+		dtSecondaryAPIToken = ""
 	}
 
-	return &DTCredentials{Tenant: getCleanURL(dtTenant), ApiToken: getCleanToken(dtAPIToken)}, nil
+	// the base URL of the configuration API is optional, so it is only read if it exists
+	configAPIURL, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "DT_CONFIG_API_URL")
+	if err == nil {
+		configAPIURL, err = NormalizeTenantURL(configAPIURL)
+		if err != nil {
+			return nil, fmt.Errorf("key DT_CONFIG_API_URL of secret \"%s\" is invalid: %w", secretName, err)
+		}
+	} else if errors.Is(err, ErrSecretNotFound) {
+		configAPIURL = ""
+	} else {
+		return nil, fmt.Errorf("could not read key DT_CONFIG_API_URL of secret \"%s\": %w", secretName, err)
+	}
+
+	return &DTCredentials{
+		Tenant:            tenantURL,
+		ApiToken:          getCleanToken(dtAPIToken),
+		SecondaryApiToken: getCleanToken(dtSecondaryAPIToken),
+		ConfigAPIURL:      configAPIURL,
+	}, nil
+}
+
+// newRequiredKeyError returns the error for a required key of the secret that could not be read, wrapping ErrSecretNotFound only if the key or secret does not exist
+func newRequiredKeyError(key string, secretName string, err error) error {
+	if errors.Is(err, ErrSecretNotFound) {
+		return fmt.Errorf("key %s was not found in secret \"%s\": %w", key, secretName, err)
+	}
+	return fmt.Errorf("could not read key %s of secret \"%s\": %w", key, secretName, err)
 }
 
 func (cm *CredentialManager) GetKeptnAPICredentials() (*KeptnAPICredentials, error) {
 	secretName := "dynatrace"
 
-	apiURL, err := cm.SecretReader.ReadSecret(secretName, namespace, "KEPTN_API_URL")
+	apiURL, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "KEPTN_API_URL")
 	if err != nil {
-		apiURL = os.Getenv("KEPTN_API_URL")
+		apiURL = cm.cfg.KeptnAPIURL
 		if apiURL == "" {
 			return nil, fmt.Errorf("key KEPTN_API_URL was not found in secret \"%s\" or environment variables", secretName)
 		}
 	}
 
-	apiToken, err := cm.SecretReader.ReadSecret(secretName, namespace, "KEPTN_API_TOKEN")
+	apiToken, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "KEPTN_API_TOKEN")
 	if err != nil {
-		apiToken = os.Getenv("KEPTN_API_TOKEN")
+		apiToken = cm.cfg.KeptnAPIToken
 		if apiToken == "" {
 			return nil, fmt.Errorf("key KEPTN_API_TOKEN was not found in secret \"%s\" or environment variables", secretName)
 		}
@@ -151,10 +194,10 @@ func (cm *CredentialManager) GetKeptnAPICredentials() (*KeptnAPICredentials, err
 func (cm *CredentialManager) GetKeptnBridgeURL() (string, error) {
 	secretName := "dynatrace"
 
-	bridgeURL, err := cm.SecretReader.ReadSecret(secretName, namespace, "KEPTN_BRIDGE_URL")
+	bridgeURL, err := cm.SecretReader.ReadSecret(secretName, cm.namespace, "KEPTN_BRIDGE_URL")
 
 	if err != nil {
-		bridgeURL = os.Getenv("KEPTN_BRIDGE_URL")
+		bridgeURL = cm.cfg.KeptnBridgeURL
 		if bridgeURL == "" {
 			return "", fmt.Errorf("key KEPTN_BRIDGE_URL was not found in secret \"%s\" or environment variables", secretName)
 		}
@@ -181,8 +224,8 @@ func getCleanToken(token string) string {
 }
 
 // GetKeptnCredentials retrieves the Keptn Credentials from the "dynatrace" secret
-func GetKeptnCredentials() (*KeptnAPICredentials, error) {
-	cm, err := NewCredentialManager(nil)
+func GetKeptnCredentials(cfg *env.Config) (*KeptnAPICredentials, error) {
+	cm, err := NewCredentialManager(nil, cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -190,21 +233,23 @@ func GetKeptnCredentials() (*KeptnAPICredentials, error) {
 }
 
 // CheckKeptnConnection verifies wether a connection to the Keptn API can be established
-func CheckKeptnConnection(keptnCredentials *KeptnAPICredentials) error {
-	client := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		},
-	}
+func CheckKeptnConnection(cfg *env.Config, keptnCredentials *KeptnAPICredentials) error {
+	return checkKeptnConnection(keptnhttp.NewDefaultAuthenticatedHTTPClient(cfg, keptnCredentials.APIToken), keptnCredentials)
+}
+
+func checkKeptnConnection(client *http.Client, keptnCredentials *KeptnAPICredentials) error {
 	req, err := http.NewRequest(http.MethodGet, keptnCredentials.APIURL+"/v1/auth", nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-token", keptnCredentials.APIToken)
 
 	resp, err := client.Do(req)
 	if err != nil {
 		return errors.New("could not authenticate at Keptn API: " + err.Error())
 	}
+	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
 		return errors.New("invalid Keptn API Token: received 401 - Unauthorized from " + keptnCredentials.APIURL + "/v1/auth")
@@ -215,8 +260,8 @@ func CheckKeptnConnection(keptnCredentials *KeptnAPICredentials) error {
 }
 
 // GetKeptnBridgeURL returns the bridge URL
-func GetKeptnBridgeURL() (string, error) {
-	cm, err := NewCredentialManager(nil)
+func GetKeptnBridgeURL(cfg *env.Config) (string, error) {
+	cm, err := NewCredentialManager(nil, cfg)
 	if err != nil {
 		return "", err
 	}
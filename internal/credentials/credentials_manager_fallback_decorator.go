@@ -1,7 +1,9 @@
 package credentials
 
 import (
+	"errors"
 	"fmt"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	log "github.com/sirupsen/logrus"
 	"strings"
 )
@@ -19,12 +21,12 @@ func NewCredentialManagerFallbackDecorator(cm CredentialManagerInterface, secret
 	}
 }
 
-func NewCredentialManagerDefaultFallbackDecorator(cm CredentialManagerInterface) *CredentialManagerFallbackDecorator {
-	return NewCredentialManagerFallbackDecorator(cm, []string{"dynatrace"})
+func NewCredentialManagerDefaultFallbackDecorator(cm CredentialManagerInterface, cfg *env.Config) *CredentialManagerFallbackDecorator {
+	return NewCredentialManagerFallbackDecorator(cm, []string{cfg.DefaultDynatraceSecretName})
 }
 
-func NewCredentialManagerSLIServiceFallbackDecorator(cm CredentialManagerInterface, project string) *CredentialManagerFallbackDecorator {
-	return NewCredentialManagerFallbackDecorator(cm, []string{fmt.Sprintf("dynatrace-credentials-%s", project), "dynatrace-credentials", "dynatrace"})
+func NewCredentialManagerSLIServiceFallbackDecorator(cm CredentialManagerInterface, project string, cfg *env.Config) *CredentialManagerFallbackDecorator {
+	return NewCredentialManagerFallbackDecorator(cm, []string{fmt.Sprintf("dynatrace-credentials-%s", project), "dynatrace-credentials", cfg.DefaultDynatraceSecretName})
 }
 
 func (cm *CredentialManagerFallbackDecorator) GetDynatraceCredentials(secretName string) (*DTCredentials, error) {
@@ -47,6 +49,11 @@ func (cm *CredentialManagerFallbackDecorator) GetDynatraceCredentials(secretName
 			cm.secretName = secret
 			return dtCredentials, nil
 		}
+
+		// the secret exists, but is misconfigured, or could not be read, so report it rather than silently using a fallback secret
+		if err != nil && !errors.Is(err, ErrSecretNotFound) {
+			return nil, err
+		}
 	}
 
 	return nil, fmt.Errorf("could not find any Dynatrace specific secrets with the following names: %s", strings.Join(secrets, ","))
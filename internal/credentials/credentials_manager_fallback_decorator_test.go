@@ -0,0 +1,80 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// secretsCredentialManager returns credentials only for the given secrets and records all requested secrets
+type secretsCredentialManager struct {
+	secrets          map[string]*DTCredentials
+	requestedSecrets []string
+}
+
+func (m *secretsCredentialManager) GetDynatraceCredentials(secretName string) (*DTCredentials, error) {
+	m.requestedSecrets = append(m.requestedSecrets, secretName)
+	credentials, ok := m.secrets[secretName]
+	if !ok {
+		return nil, ErrSecretNotFound
+	}
+	return credentials, nil
+}
+
+func (m *secretsCredentialManager) GetKeptnAPICredentials() (*KeptnAPICredentials, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestCredentialManagerFallbackDecorator_UsesConfiguredDefaultSecretName(t *testing.T) {
+	expectedCredentials := &DTCredentials{Tenant: "https://mySampleEnv.live.dynatrace.com", ApiToken: "abc123"}
+	cm := &secretsCredentialManager{secrets: map[string]*DTCredentials{"acme-dynatrace": expectedCredentials}}
+
+	decorator := NewCredentialManagerSLIServiceFallbackDecorator(cm, "sockshop", &env.Config{DefaultDynatraceSecretName: "acme-dynatrace"})
+	dtCredentials, err := decorator.GetDynatraceCredentials("dynatrace-prod")
+
+	assert.NoError(t, err)
+	assert.Equal(t, expectedCredentials, dtCredentials)
+	assert.Equal(t, "acme-dynatrace", decorator.GetSecretName())
+	assert.Equal(t, []string{"dynatrace-prod", "dynatrace-credentials-sockshop", "dynatrace-credentials", "acme-dynatrace"}, cm.requestedSecrets)
+}
+
+func TestCredentialManagerFallbackDecorator_DefaultSecretName(t *testing.T) {
+	cm := &secretsCredentialManager{}
+
+	_, err := NewCredentialManagerDefaultFallbackDecorator(cm, env.ReadConfig()).GetDynatraceCredentials("")
+
+	assert.Error(t, err)
+	assert.Equal(t, []string{"dynatrace"}, cm.requestedSecrets)
+}
+
+// Tests that an error reading a secret, e.g. because the secret backend is unavailable, is returned rather than treated as a missing secret
+func TestCredentialManagerFallbackDecorator_ReportsReadErrors(t *testing.T) {
+	cm, err := NewCredentialManager(failingKeySecretReader{
+		secretReader: mapSecretReader{"dynatrace": {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"}},
+		failingKey:   "DT_TENANT",
+	}, env.ReadConfig())
+	assert.NoError(t, err)
+
+	_, err = NewCredentialManagerDefaultFallbackDecorator(cm, env.ReadConfig()).GetDynatraceCredentials("dynatrace-prod")
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "could not read key DT_TENANT of secret \"dynatrace-prod\"")
+		assert.False(t, errors.Is(err, ErrSecretNotFound))
+	}
+}
+
+func TestCredentialManagerFallbackDecorator_ReportsInvalidTenantURL(t *testing.T) {
+	secretReader := mapSecretReader{
+		"dynatrace-prod": {"DT_TENANT": "ftp://managed.example.com/e/prod", "DT_API_TOKEN": "abc123"},
+		"dynatrace":      {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"},
+	}
+	cm, err := NewCredentialManager(secretReader, env.ReadConfig())
+	assert.NoError(t, err)
+
+	_, err = NewCredentialManagerDefaultFallbackDecorator(cm, env.ReadConfig()).GetDynatraceCredentials("dynatrace-prod")
+
+	var invalidTenantURLErr *InvalidTenantURLError
+	assert.ErrorAs(t, err, &invalidTenantURLErr)
+}
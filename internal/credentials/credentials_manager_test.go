@@ -1,12 +1,14 @@
 package credentials
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
@@ -59,7 +61,7 @@ func TestCheckKeptnConnection(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			returnedResponse = tt.returnedResponse
-			if err := CheckKeptnConnection(tt.args.KeptnAPICredentials); (err != nil) != tt.wantErr {
+			if err := CheckKeptnConnection(env.ReadConfig(), tt.args.KeptnAPICredentials); (err != nil) != tt.wantErr {
 				t.Errorf("CheckKeptnConnection() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -126,9 +128,9 @@ func TestGetKeptnAPICredentials(t *testing.T) {
 				},
 			})
 
-			k8sSecretReader, _ := NewK8sCredentialReader(fakeClient)
+			k8sSecretReader, _ := NewK8sCredentialReader(fakeClient, env.ReadConfig())
 
-			cm, err := NewCredentialManager(k8sSecretReader)
+			cm, err := NewCredentialManager(k8sSecretReader, env.ReadConfig())
 			if err != nil {
 				t.Errorf("could not initialize CredentialManager: %s", err.Error())
 			}
@@ -189,9 +191,9 @@ func TestGetKeptnBridgeURL(t *testing.T) {
 				},
 			})
 
-			k8sSecretReader, _ := NewK8sCredentialReader(fakeClient)
+			k8sSecretReader, _ := NewK8sCredentialReader(fakeClient, env.ReadConfig())
 
-			cm, err := NewCredentialManager(k8sSecretReader)
+			cm, err := NewCredentialManager(k8sSecretReader, env.ReadConfig())
 			if err != nil {
 				t.Errorf("could not initialize CredentialManager: %s", err.Error())
 			}
@@ -214,6 +216,12 @@ func TestCredentialManager_GetDynatraceCredentials(t *testing.T) {
 	dynatraceSecret := createDynatraceDTSecret("dynatrace", "keptn", "https://mySampleEnv.live.dynatrace.com", "abc123")
 	dynatraceOtherSecret := createDynatraceDTSecret("dynatrace_other", "keptn", "https://mySampleEnv.live.dynatrace.com", "abc123")
 
+	activeGateSecret := createDynatraceDTSecret("dynatrace", "keptn", "https://activegate.example.com:9999/e/abc12345/", "abc123")
+	activeGateSecret.Data["DT_CONFIG_API_URL"] = []byte("https://abc12345.live.dynatrace.com/api")
+
+	invalidConfigAPIURLSecret := createDynatraceDTSecret("dynatrace", "keptn", "https://activegate.example.com:9999/e/abc12345", "abc123")
+	invalidConfigAPIURLSecret.Data["DT_CONFIG_API_URL"] = []byte("ftp://abc12345.live.dynatrace.com")
+
 	type args struct {
 		secretName string
 	}
@@ -256,6 +264,27 @@ func TestCredentialManager_GetDynatraceCredentials(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "with Environment ActiveGate and configuration API URL",
+			secret: activeGateSecret,
+			args: args{
+				secretName: "",
+			},
+			want: &DTCredentials{
+				Tenant:       "https://activegate.example.com:9999/e/abc12345",
+				ApiToken:     "abc123",
+				ConfigAPIURL: "https://abc12345.live.dynatrace.com",
+			},
+			wantErr: false,
+		},
+		{
+			name:   "with invalid configuration API URL",
+			secret: invalidConfigAPIURLSecret,
+			args: args{
+				secretName: "",
+			},
+			wantErr: true,
+		},
 		{
 			name:   "with dynatrace_other secret, with bad config",
 			secret: dynatraceOtherSecret,
@@ -276,15 +305,15 @@ func TestCredentialManager_GetDynatraceCredentials(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 
-			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret))
+			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret), env.ReadConfig())
 			if err != nil {
 				t.Fatalf("NewK8sCredentialReader() error = %v", err)
 			}
-			cm, err := NewCredentialManager(secretReader)
+			cm, err := NewCredentialManager(secretReader, env.ReadConfig())
 			if err != nil {
 				t.Fatalf("NewCredentialManager() error = %v", err)
 			}
-			decorator := NewCredentialManagerDefaultFallbackDecorator(cm)
+			decorator := NewCredentialManagerDefaultFallbackDecorator(cm, env.ReadConfig())
 
 			got, err := decorator.GetDynatraceCredentials(tt.args.secretName)
 			if (err != nil) && tt.wantErr {
@@ -300,6 +329,54 @@ func TestCredentialManager_GetDynatraceCredentials(t *testing.T) {
 	}
 }
 
+// failingKeySecretReader fails to read one key, e.g. because the secret backend is temporarily unavailable, and reads all other keys using the secretReader
+type failingKeySecretReader struct {
+	secretReader SecretReader
+	failingKey   string
+}
+
+func (r failingKeySecretReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	if secretKey == r.failingKey {
+		return "", errors.New("connection refused")
+	}
+	return r.secretReader.ReadSecret(secretName, namespace, secretKey)
+}
+
+func TestCredentialManager_GetDynatraceCredentials_ReturnsErrorsOfOptionalKeys(t *testing.T) {
+	secretReader := mapSecretReader{"dynatrace": {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"}}
+
+	for _, key := range []string{"DT_API_TOKEN_SECONDARY", "DT_CONFIG_API_URL"} {
+		t.Run(key, func(t *testing.T) {
+			cm, err := NewCredentialManager(failingKeySecretReader{secretReader: secretReader, failingKey: key}, env.ReadConfig())
+			if err != nil {
+				t.Fatalf("NewCredentialManager() error = %v", err)
+			}
+
+			_, err = cm.GetDynatraceCredentials("dynatrace")
+			if err == nil || !strings.Contains(err.Error(), key) {
+				t.Errorf("CredentialManager.GetDynatraceCredentials() error = %v, want error reading %s", err, key)
+			}
+		})
+	}
+}
+
+func TestK8sCredentialReader_ReadSecret_ReturnsErrSecretNotFoundForMissingSecrets(t *testing.T) {
+	secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(createDynatraceDTSecret("dynatrace", "keptn", "https://mySampleEnv.live.dynatrace.com", "abc123")), env.ReadConfig())
+	if err != nil {
+		t.Fatalf("NewK8sCredentialReader() error = %v", err)
+	}
+
+	_, err = secretReader.ReadSecret("dynatrace-prod", "keptn", "DT_TENANT")
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("K8sCredentialReader.ReadSecret() error = %v, want ErrSecretNotFound for a missing secret", err)
+	}
+
+	_, err = secretReader.ReadSecret("dynatrace", "keptn", "DT_CONFIG_API_URL")
+	if !errors.Is(err, ErrSecretNotFound) {
+		t.Errorf("K8sCredentialReader.ReadSecret() error = %v, want ErrSecretNotFound for a missing key", err)
+	}
+}
+
 func createDynatraceDTSecret(name string, namespace string, dtTenant string, dtAPIToken string) *v1.Secret {
 	return &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -372,19 +449,16 @@ func TestCredentialManager_GetKeptnAPICredentials(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret))
+			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret), env.ReadConfig())
 			if err != nil {
 				t.Fatalf("NewK8sCredentialReader() error = %v", err)
 			}
 
-			os.Setenv("KEPTN_API_URL", tt.envVars.keptnAPIURL)
-			os.Setenv("KEPTN_API_TOKEN", tt.envVars.keptnAPIToken)
-			defer func() {
-				os.Unsetenv("KEPTN_API_URL")
-				os.Unsetenv("KEPTN_API_TOKEN")
-			}()
+			cfg := env.ReadConfig()
+			cfg.KeptnAPIURL = tt.envVars.keptnAPIURL
+			cfg.KeptnAPIToken = tt.envVars.keptnAPIToken
 
-			cm, err := NewCredentialManager(secretReader)
+			cm, err := NewCredentialManager(secretReader, cfg)
 			if err != nil {
 				t.Fatalf("NewCredentialManager() error = %v", err)
 			}
@@ -462,17 +536,15 @@ func TestCredentialManager_GetKeptnBridgeURL(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret))
+			secretReader, err := NewK8sCredentialReader(fake.NewSimpleClientset(tt.secret), env.ReadConfig())
 			if err != nil {
 				t.Fatalf("NewK8sCredentialReader() error = %v", err)
 			}
 
-			os.Setenv("KEPTN_BRIDGE_URL", tt.envVars.keptnBridgeURL)
-			defer func() {
-				os.Unsetenv("KEPTN_BRIDGE_URL")
-			}()
+			cfg := env.ReadConfig()
+			cfg.KeptnBridgeURL = tt.envVars.keptnBridgeURL
 
-			cm, err := NewCredentialManager(secretReader)
+			cm, err := NewCredentialManager(secretReader, cfg)
 			if err != nil {
 				t.Fatalf("NewCredentialManager() error = %v", err)
 			}
@@ -0,0 +1,102 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// secretListCacheDuration is how long the list of secrets retrieved from the secret-service is reused, so that reading all keys of
+// the credentials, e.g. DT_TENANT, DT_API_TOKEN, DT_API_TOKEN_SECONDARY and DT_CONFIG_API_URL, only retrieves it once
+const secretListCacheDuration = 10 * time.Second
+
+// KeptnSecretServiceCredentialReader reads secrets managed by the Keptn secret-service, e.g. created in the Keptn Bridge.
+// Only secrets of its scope are read. As the secret-service does not return the values of secrets, these are read by the valueReader,
+// i.e. from the Kubernetes secret created by the secret-service
+type KeptnSecretServiceCredentialReader struct {
+	secretHandler keptnapi.SecretHandlerInterface
+	valueReader   SecretReader
+	scope         string
+	now           func() time.Time
+
+	mutex          sync.Mutex
+	secrets        *models.GetSecretsResponse
+	secretsExpires time.Time
+}
+
+// NewKeptnSecretServiceCredentialReader creates a new KeptnSecretServiceCredentialReader
+func NewKeptnSecretServiceCredentialReader(secretHandler keptnapi.SecretHandlerInterface, valueReader SecretReader, scope string) (*KeptnSecretServiceCredentialReader, error) {
+	if scope == "" {
+		return nil, errors.New("could not initialize KeptnSecretServiceCredentialReader: no scope specified")
+	}
+
+	return &KeptnSecretServiceCredentialReader{
+		secretHandler: secretHandler,
+		valueReader:   valueReader,
+		scope:         scope,
+		now:           time.Now,
+	}, nil
+}
+
+// NewDefaultKeptnSecretServiceCredentialReader creates a new KeptnSecretServiceCredentialReader using the secret-service of the Keptn control plane
+// and the Kubernetes secrets it creates. The scope of the secrets is the one of the configuration
+func NewDefaultKeptnSecretServiceCredentialReader(cfg *env.Config) (*KeptnSecretServiceCredentialReader, error) {
+	valueReader, err := NewK8sCredentialReader(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	secretHandler := keptnapi.NewSecretHandler(common.GetSecretServiceURL())
+	secretHandler.HTTPClient = keptnhttp.GetDefaultHTTPClient(cfg)
+
+	return NewKeptnSecretServiceCredentialReader(secretHandler, valueReader, cfg.KeptnSecretScope)
+}
+
+// ReadSecret reads the key of the secret if the secret-service manages the secret in the scope of the reader and the secret contains the key
+func (r *KeptnSecretServiceCredentialReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	secrets, err := r.getSecrets()
+	if err != nil {
+		return "", err
+	}
+
+	for _, secret := range secrets.Secrets {
+		if secret.Name == nil || *secret.Name != secretName || secret.Scope == nil || *secret.Scope != r.scope {
+			continue
+		}
+
+		for _, key := range secret.Keys {
+			if key == secretKey {
+				return r.valueReader.ReadSecret(secretName, namespace, secretKey)
+			}
+		}
+		return "", ErrSecretNotFound
+	}
+
+	return "", fmt.Errorf("secret %s was not found in scope %s of the Keptn secret-service: %w", secretName, r.scope, ErrSecretNotFound)
+}
+
+// getSecrets returns the secrets managed by the secret-service, reusing the list retrieved within the last secretListCacheDuration. Errors are not cached
+func (r *KeptnSecretServiceCredentialReader) getSecrets() (*models.GetSecretsResponse, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.secrets != nil && r.now().Before(r.secretsExpires) {
+		return r.secrets, nil
+	}
+
+	secrets, err := r.secretHandler.GetSecrets()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve secrets from the Keptn secret-service: %w", err)
+	}
+
+	r.secrets = secrets
+	r.secretsExpires = r.now().Add(secretListCacheDuration)
+	return secrets, nil
+}
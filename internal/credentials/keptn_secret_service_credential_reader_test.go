@@ -0,0 +1,116 @@
+package credentials
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn/go-utils/pkg/api/models"
+	utils_mock "github.com/keptn/go-utils/pkg/api/utils/fake"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapSecretReader reads the values of secrets from a map of secret names to keys and values
+type mapSecretReader map[string]map[string]string
+
+func (r mapSecretReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	value := r[secretName][secretKey]
+	if value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func newSecretResponseItem(name string, scope string, keys ...string) models.GetSecretResponseItem {
+	return models.GetSecretResponseItem{
+		SecretMetadata: models.SecretMetadata{Name: &name, Scope: &scope},
+		Keys:           keys,
+	}
+}
+
+func TestKeptnSecretServiceCredentialReader_ReadSecret(t *testing.T) {
+	secretHandler := &utils_mock.SecretHandlerInterfaceMock{
+		GetSecretsFunc: func() (*models.GetSecretsResponse, error) {
+			return &models.GetSecretsResponse{
+				Secrets: []models.GetSecretResponseItem{
+					newSecretResponseItem("dynatrace", "dynatrace-service", "DT_TENANT", "DT_API_TOKEN"),
+					newSecretResponseItem("dynatrace-prod", "keptn-webhook-service", "DT_TENANT", "DT_API_TOKEN"),
+				},
+			}, nil
+		},
+	}
+	valueReader := mapSecretReader{
+		"dynatrace":      {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"},
+		"dynatrace-prod": {"DT_TENANT": "https://myProdEnv.live.dynatrace.com", "DT_API_TOKEN": "def456"},
+	}
+
+	reader, err := NewKeptnSecretServiceCredentialReader(secretHandler, valueReader, "dynatrace-service")
+	assert.NoError(t, err)
+
+	tenant, err := reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mySampleEnv.live.dynatrace.com", tenant)
+
+	// keys not listed by the secret-service are not read
+	_, err = reader.ReadSecret("dynatrace", "keptn", "KEPTN_API_URL")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	// secrets of other scopes are not read
+	_, err = reader.ReadSecret("dynatrace-prod", "keptn", "DT_TENANT")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestKeptnSecretServiceCredentialReader_SecretServiceUnavailable(t *testing.T) {
+	secretHandler := &utils_mock.SecretHandlerInterfaceMock{
+		GetSecretsFunc: func() (*models.GetSecretsResponse, error) {
+			return nil, errors.New("connection refused")
+		},
+	}
+
+	reader, err := NewKeptnSecretServiceCredentialReader(secretHandler, mapSecretReader{}, "dynatrace-service")
+	assert.NoError(t, err)
+
+	_, err = reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestKeptnSecretServiceCredentialReader_ReusesSecretList(t *testing.T) {
+	getSecretsCalls := 0
+	secretHandler := &utils_mock.SecretHandlerInterfaceMock{
+		GetSecretsFunc: func() (*models.GetSecretsResponse, error) {
+			getSecretsCalls++
+			if getSecretsCalls == 2 {
+				return nil, errors.New("connection refused")
+			}
+			return &models.GetSecretsResponse{
+				Secrets: []models.GetSecretResponseItem{newSecretResponseItem("dynatrace", "dynatrace-service", "DT_TENANT", "DT_API_TOKEN")},
+			}, nil
+		},
+	}
+	valueReader := mapSecretReader{"dynatrace": {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"}}
+
+	reader, err := NewKeptnSecretServiceCredentialReader(secretHandler, valueReader, "dynatrace-service")
+	assert.NoError(t, err)
+
+	now := time.Date(2022, 1, 1, 12, 0, 0, 0, time.UTC)
+	reader.now = func() time.Time { return now }
+
+	credentialManager, err := NewCredentialManager(reader, env.ReadConfig())
+	assert.NoError(t, err)
+
+	dtCredentials, err := credentialManager.GetDynatraceCredentials("dynatrace")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", dtCredentials.ApiToken)
+	assert.Equal(t, 1, getSecretsCalls)
+
+	// the list expired, errors are not cached
+	now = now.Add(secretListCacheDuration)
+	_, err = reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.Error(t, err)
+
+	_, err = reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, getSecretsCalls)
+}
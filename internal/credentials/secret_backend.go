@@ -0,0 +1,109 @@
+package credentials
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+// secretBackendTimeout is the timeout of requests to external secret backends
+const secretBackendTimeout = 30 * time.Second
+
+var defaultSecretReader SecretReader
+var defaultSecretReaderMutex sync.Mutex
+
+// getDefaultSecretReader returns the SecretReader of the secret backend of the configuration.
+// The reader is shared, so that access tokens of external secret backends are reused across events. If it cannot be created, e.g. because
+// the Kubernetes API is temporarily unavailable, creating it is retried on the next call
+func getDefaultSecretReader(cfg *env.Config) (SecretReader, error) {
+	defaultSecretReaderMutex.Lock()
+	defer defaultSecretReaderMutex.Unlock()
+
+	if defaultSecretReader != nil {
+		return defaultSecretReader, nil
+	}
+
+	secretReader, err := newSecretReader(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSecretReader = secretReader
+	return secretReader, nil
+}
+
+// newSecretReader creates the SecretReader of the secret backend of the configuration
+func newSecretReader(cfg *env.Config) (SecretReader, error) {
+	log.WithField("backend", cfg.SecretBackend).Info("Reading credentials from secret backend")
+
+	httpClient := &http.Client{Timeout: secretBackendTimeout}
+	switch cfg.SecretBackend {
+	case env.KeptnSecretServiceSecretBackend:
+		return NewDefaultKeptnSecretServiceCredentialReader(cfg)
+	case env.VaultSecretBackend:
+		return NewVaultCredentialReader(httpClient, VaultOptions{
+			Address:            cfg.VaultAddress,
+			Token:              cfg.VaultToken,
+			KVMount:            cfg.VaultKVMount,
+			PathPrefix:         cfg.VaultPathPrefix,
+			KubernetesRole:     cfg.VaultKubernetesRole,
+			KubernetesAuthPath: cfg.VaultKubernetesAuthPath,
+		})
+	case env.AWSSecretsManagerSecretBackend:
+		return NewAWSSecretsManagerCredentialReader(httpClient, AWSSecretsManagerOptions{
+			Region:               cfg.AWSRegion,
+			Endpoint:             cfg.AWSSecretsManagerEndpoint,
+			SecretNamePrefix:     cfg.AWSSecretNamePrefix,
+			AccessKeyID:          cfg.AWSAccessKeyID,
+			SecretAccessKey:      cfg.AWSSecretAccessKey,
+			SessionToken:         cfg.AWSSessionToken,
+			RoleARN:              cfg.AWSRoleARN,
+			WebIdentityTokenFile: cfg.AWSWebIdentityTokenFile,
+		})
+	case env.AzureKeyVaultSecretBackend:
+		return NewAzureKeyVaultCredentialReader(httpClient, AzureKeyVaultOptions{
+			VaultURL:           cfg.AzureKeyVaultURL,
+			AuthorityHost:      cfg.AzureAuthorityHost,
+			TenantID:           cfg.AzureTenantID,
+			ClientID:           cfg.AzureClientID,
+			ClientSecret:       cfg.AzureClientSecret,
+			FederatedTokenFile: cfg.AzureFederatedTokenFile,
+		})
+	default:
+		// when running locally outside of a cluster, the credentials are read from environment variables, e.g. DT_TENANT and DT_API_TOKEN
+		if cfg.RunLocalEnabled && !cfg.InCluster {
+			return OSEnvCredentialReader{}, nil
+		}
+		return NewK8sCredentialReader(nil, cfg)
+	}
+}
+
+// readJSONSecretKey returns the value of a key of a secret stored as a JSON object of strings, e.g. {"DT_TENANT": "...", "DT_API_TOKEN": "..."}
+func readJSONSecretKey(secretValue string, secretName string, secretKey string) (string, error) {
+	values := map[string]string{}
+	err := json.Unmarshal([]byte(secretValue), &values)
+	if err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object of strings: %w", secretName, err)
+	}
+
+	value := values[secretKey]
+	if value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+// readTokenFile reads a token, e.g. of the Kubernetes service account, from a file
+func readTokenFile(file string) (string, error) {
+	token, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("could not read token file %s: %w", file, err)
+	}
+	return string(token), nil
+}
@@ -0,0 +1,27 @@
+package credentials
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+func TestGetDefaultSecretReader_RetriesAfterError(t *testing.T) {
+	defer func() {
+		defaultSecretReader = nil
+	}()
+
+	_, err := getDefaultSecretReader(&env.Config{SecretBackend: env.VaultSecretBackend})
+	assert.Error(t, err)
+
+	secretReader, err := getDefaultSecretReader(&env.Config{SecretBackend: env.VaultSecretBackend, VaultAddress: "https://vault.example.com:8200", VaultToken: "my-token"})
+	assert.NoError(t, err)
+	assert.IsType(t, &VaultCredentialReader{}, secretReader)
+
+	// the created reader is reused
+	sameSecretReader, err := getDefaultSecretReader(&env.Config{SecretBackend: env.VaultSecretBackend})
+	assert.NoError(t, err)
+	assert.Same(t, secretReader, sameSecretReader)
+}
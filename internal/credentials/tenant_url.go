@@ -0,0 +1,92 @@
+package credentials
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// managedEnvironmentPathSegment is the path segment preceding the environment ID in URLs of Dynatrace Managed environments, e.g. https://managed.example.com/e/<environment-id>
+const managedEnvironmentPathSegment = "e"
+
+// trailingPathSegments start paths that may follow the environment in URLs copied from the Dynatrace UI or API documentation and are removed
+var trailingPathSegments = []string{"api", "ui"}
+
+// InvalidTenantURLError is returned if DT_TENANT is not a valid URL of a Dynatrace environment
+type InvalidTenantURLError struct {
+	tenant string
+	reason string
+}
+
+func (e *InvalidTenantURLError) Error() string {
+	return fmt.Sprintf("invalid Dynatrace tenant URL \"%s\": %s", e.tenant, e.reason)
+}
+
+// NormalizeTenantURL validates the URL of a Dynatrace SaaS environment, e.g. https://abc12345.live.dynatrace.com, or Managed environment,
+// e.g. https://managed.example.com/e/<environment-id>, and returns it without query, fragment, trailing slash or trailing API and UI paths.
+// If no scheme is specified, https is used
+func NormalizeTenantURL(tenant string) (string, error) {
+	value := strings.TrimSpace(tenant)
+	if value == "" {
+		return "", &InvalidTenantURLError{tenant: tenant, reason: "no URL specified"}
+	}
+
+	if !strings.Contains(value, "://") {
+		value = "https://" + value
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", &InvalidTenantURLError{tenant: tenant, reason: err.Error()}
+	}
+
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return "", &InvalidTenantURLError{tenant: tenant, reason: fmt.Sprintf("unsupported scheme %s, only https and http are supported", u.Scheme)}
+	}
+
+	if u.Hostname() == "" {
+		return "", &InvalidTenantURLError{tenant: tenant, reason: "no host specified"}
+	}
+
+	if u.User != nil {
+		return "", &InvalidTenantURLError{tenant: tenant, reason: "user information is not supported, use DT_API_TOKEN instead"}
+	}
+
+	segments := normalizeTenantPathSegments(strings.Split(u.Path, "/"))
+	for i, segment := range segments {
+		if segment != managedEnvironmentPathSegment {
+			continue
+		}
+
+		if i == len(segments)-1 {
+			return "", &InvalidTenantURLError{tenant: tenant, reason: "no environment ID specified after /e/"}
+		}
+
+		segments = segments[:i+2]
+		break
+	}
+
+	normalized := u.Scheme + "://" + u.Host
+	if len(segments) > 0 {
+		normalized += "/" + strings.Join(segments, "/")
+	}
+	return normalized, nil
+}
+
+// normalizeTenantPathSegments removes empty segments and all segments from the first trailing API or UI path on
+func normalizeTenantPathSegments(segments []string) []string {
+	var normalized []string
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+
+		for _, trailingSegment := range trailingPathSegments {
+			if segment == trailingSegment {
+				return normalized
+			}
+		}
+		normalized = append(normalized, segment)
+	}
+	return normalized
+}
@@ -0,0 +1,188 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	vault "github.com/hashicorp/vault/api"
+)
+
+// defaultServiceAccountTokenFile is the file the token of the Kubernetes service account of the pod is mounted to
+const defaultServiceAccountTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// VaultOptions defines how secrets are read from HashiCorp Vault
+type VaultOptions struct {
+	// Address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token used to authenticate against Vault, if no KubernetesRole is set
+	Token string
+	// KVMount is the mount path of the KV version 2 secrets engine
+	KVMount string
+	// PathPrefix is the path within the secrets engine below which the secrets are stored
+	PathPrefix string
+	// KubernetesRole is the Vault role used to log in with the Kubernetes service account of the pod
+	KubernetesRole string
+	// KubernetesAuthPath is the mount path of the Kubernetes auth method
+	KubernetesAuthPath string
+	// ServiceAccountTokenFile is the file containing the token of the Kubernetes service account. The token of the pod is used by default
+	ServiceAccountTokenFile string
+}
+
+// VaultCredentialReader reads secrets from the KV version 2 secrets engine of HashiCorp Vault.
+// The secret is read from <KVMount>/data/<PathPrefix>/<secretName> and every key is a field of that secret
+type VaultCredentialReader struct {
+	client  *vault.Client
+	options VaultOptions
+
+	// loginMutex serializes logins with the Kubernetes service account
+	loginMutex sync.Mutex
+}
+
+// NewVaultCredentialReader creates a new VaultCredentialReader
+func NewVaultCredentialReader(httpClient *http.Client, options VaultOptions) (*VaultCredentialReader, error) {
+	if options.Address == "" {
+		return nil, errors.New("could not initialize VaultCredentialReader: no Vault address specified")
+	}
+
+	if options.Token == "" && options.KubernetesRole == "" {
+		return nil, errors.New("could not initialize VaultCredentialReader: neither a Vault token nor a Kubernetes role specified")
+	}
+
+	if options.KVMount == "" {
+		options.KVMount = "secret"
+	}
+
+	if options.KubernetesAuthPath == "" {
+		options.KubernetesAuthPath = "kubernetes"
+	}
+
+	if options.ServiceAccountTokenFile == "" {
+		options.ServiceAccountTokenFile = defaultServiceAccountTokenFile
+	}
+
+	client, err := vault.NewClient(&vault.Config{Address: options.Address, HttpClient: httpClient})
+	if err != nil {
+		return nil, fmt.Errorf("could not initialize VaultCredentialReader: %w", err)
+	}
+
+	// the token is set explicitly, so that VAULT_TOKEN of the environment is not used
+	client.SetToken(options.Token)
+
+	return &VaultCredentialReader{
+		client:  client,
+		options: options,
+	}, nil
+}
+
+// ReadSecret reads the key of the secret from Vault. The namespace is ignored
+func (r *VaultCredentialReader) ReadSecret(secretName, namespace, secretKey string) (string, error) {
+	data, err := r.readSecretData(secretName)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := data[secretKey].(string)
+	if !ok || value == "" {
+		return "", ErrSecretNotFound
+	}
+	return value, nil
+}
+
+func (r *VaultCredentialReader) readSecretData(secretName string) (map[string]interface{}, error) {
+	err := validateVaultSecretName(secretName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.ensureToken(false)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := r.getSecret(secretName)
+
+	// the token of a Kubernetes login may have expired, so log in again once
+	if isVaultPermissionDenied(err) && r.options.KubernetesRole != "" {
+		err = r.ensureToken(true)
+		if err != nil {
+			return nil, err
+		}
+		secret, err = r.getSecret(secretName)
+	}
+
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return nil, fmt.Errorf("secret %s was not found in Vault: %w", secretName, ErrSecretNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read secret %s from Vault: %w", secretName, err)
+	}
+
+	return secret.Data, nil
+}
+
+// validateVaultSecretName rejects names that are not a single path segment, as they could be used to read secrets outside of <KVMount>/data/<PathPrefix>
+func validateVaultSecretName(secretName string) error {
+	if secretName == "" || secretName == "." || strings.Contains(secretName, "..") || strings.ContainsAny(secretName, "/\\") {
+		return fmt.Errorf("invalid Vault secret name %q: must be a single path segment", secretName)
+	}
+	return nil
+}
+
+func (r *VaultCredentialReader) getSecret(secretName string) (*vault.KVSecret, error) {
+	return r.client.KVv2(r.options.KVMount).Get(context.Background(), path.Join(r.options.PathPrefix, secretName))
+}
+
+// isVaultPermissionDenied returns true if Vault rejected the request because of the token
+func isVaultPermissionDenied(err error) bool {
+	var responseErr *vault.ResponseError
+	return errors.As(err, &responseErr) && responseErr.StatusCode == http.StatusForbidden
+}
+
+// ensureToken logs in with the Kubernetes service account if a Kubernetes role is set and there is no token yet or it has to be renewed
+func (r *VaultCredentialReader) ensureToken(renew bool) error {
+	r.loginMutex.Lock()
+	defer r.loginMutex.Unlock()
+
+	if r.options.KubernetesRole == "" || (r.client.Token() != "" && !renew) {
+		return nil
+	}
+
+	token, err := r.loginWithKubernetes()
+	if err != nil {
+		return fmt.Errorf("could not log in to Vault: %w", err)
+	}
+
+	r.client.SetToken(token)
+	return nil
+}
+
+func (r *VaultCredentialReader) loginWithKubernetes() (string, error) {
+	jwt, err := readTokenFile(r.options.ServiceAccountTokenFile)
+	if err != nil {
+		return "", err
+	}
+
+	// the clone does not send the possibly expired token of the client
+	client, err := r.client.Clone()
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := client.Logical().WriteWithContext(context.Background(), path.Join("auth", r.options.KubernetesAuthPath, "login"), map[string]interface{}{
+		"role": r.options.KubernetesRole,
+		"jwt":  jwt,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return "", errors.New("no client token returned")
+	}
+	return secret.Auth.ClientToken, nil
+}
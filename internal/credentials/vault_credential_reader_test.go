@@ -0,0 +1,135 @@
+package credentials
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVaultCredentialReader_ReadSecretWithToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "my-token", r.Header.Get("X-Vault-Token"))
+
+		switch r.URL.Path {
+		case "/v1/kv/data/keptn/dynatrace":
+			w.Write([]byte(`{"data": {"data": {"DT_TENANT": "https://mySampleEnv.live.dynatrace.com", "DT_API_TOKEN": "abc123"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reader, err := NewVaultCredentialReader(server.Client(), VaultOptions{Address: server.URL, Token: "my-token", KVMount: "kv", PathPrefix: "keptn"})
+	assert.NoError(t, err)
+
+	tenant, err := reader.ReadSecret("dynatrace", "keptn", "DT_TENANT")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://mySampleEnv.live.dynatrace.com", tenant)
+
+	_, err = reader.ReadSecret("dynatrace", "keptn", "KEPTN_API_URL")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	_, err = reader.ReadSecret("dynatrace-prod", "keptn", "DT_TENANT")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestVaultCredentialReader_ReadSecretWithKubernetesLogin(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("service-account-jwt"), 0600))
+
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			var login map[string]string
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&login))
+			assert.Equal(t, map[string]string{"role": "dynatrace-service", "jwt": "service-account-jwt"}, login)
+
+			logins++
+			w.Write([]byte(`{"auth": {"client_token": "client-token"}}`))
+		case "/v1/secret/data/dynatrace":
+			assert.Equal(t, "client-token", r.Header.Get("X-Vault-Token"))
+			w.Write([]byte(`{"data": {"data": {"DT_API_TOKEN": "abc123"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reader, err := NewVaultCredentialReader(server.Client(), VaultOptions{Address: server.URL, KubernetesRole: "dynatrace-service", ServiceAccountTokenFile: tokenFile})
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		apiToken, err := reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", apiToken)
+	}
+
+	// the token of the login is reused
+	assert.Equal(t, 1, logins)
+}
+
+func TestVaultCredentialReader_LogsInAgainIfTokenIsRejected(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("service-account-jwt"), 0600))
+
+	logins := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/auth/kubernetes/login":
+			assert.Empty(t, r.Header.Get("X-Vault-Token"))
+
+			logins++
+			w.Write([]byte(fmt.Sprintf(`{"auth": {"client_token": "client-token-%d"}}`, logins)))
+		case "/v1/secret/data/dynatrace":
+			// the token of the first login has expired
+			if r.Header.Get("X-Vault-Token") != "client-token-2" {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"errors": ["permission denied"]}`))
+				return
+			}
+			w.Write([]byte(`{"data": {"data": {"DT_API_TOKEN": "abc123"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	reader, err := NewVaultCredentialReader(server.Client(), VaultOptions{Address: server.URL, KubernetesRole: "dynatrace-service", ServiceAccountTokenFile: tokenFile})
+	assert.NoError(t, err)
+
+	apiToken, err := reader.ReadSecret("dynatrace", "keptn", "DT_API_TOKEN")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", apiToken)
+	assert.Equal(t, 2, logins)
+}
+
+func TestVaultCredentialReader_RejectsSecretNamesOutsideOfPathPrefix(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	reader, err := NewVaultCredentialReader(server.Client(), VaultOptions{Address: server.URL, Token: "my-token", KVMount: "kv", PathPrefix: "keptn"})
+	assert.NoError(t, err)
+
+	for _, secretName := range []string{"", ".", "..", "../other-team/dynatrace", "keptn/../../dynatrace", "dynatrace/prod", "..\\dynatrace"} {
+		t.Run(secretName, func(t *testing.T) {
+			_, err := reader.ReadSecret(secretName, "keptn", "DT_API_TOKEN")
+			assert.Error(t, err)
+			assert.False(t, errors.Is(err, ErrSecretNotFound))
+		})
+	}
+}
+
+func TestNewVaultCredentialReader_RequiresAuthentication(t *testing.T) {
+	_, err := NewVaultCredentialReader(http.DefaultClient, VaultOptions{Address: "https://vault.example.com:8200"})
+	assert.Error(t, err)
+}
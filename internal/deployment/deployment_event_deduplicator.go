@@ -0,0 +1,107 @@
+package deployment
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+var defaultDeploymentEventDeduplicator *DeploymentEventDeduplicator
+var defaultDeploymentEventDeduplicatorOnce sync.Once
+
+// sending is the time recorded for deployment events that are being sent, i.e. that were not yet accepted by the Dynatrace API
+var sending = time.Time{}
+
+type deploymentEventKey struct {
+	keptnContext string
+	stage        string
+	service      string
+}
+
+// DeploymentEventDeduplicator suppresses Dynatrace deployment events for the same Keptn context, stage and service within a window,
+// e.g. if a deployment.finished event is sent again after a retry
+type DeploymentEventDeduplicator struct {
+	window time.Duration
+	now    func() time.Time
+
+	mutex  sync.Mutex
+	sentAt map[deploymentEventKey]time.Time
+}
+
+// GetDefaultDeploymentEventDeduplicator returns the DeploymentEventDeduplicator shared by all event handlers.
+// It uses the window of the configuration of the first call
+func GetDefaultDeploymentEventDeduplicator(cfg *env.Config) *DeploymentEventDeduplicator {
+	defaultDeploymentEventDeduplicatorOnce.Do(func() {
+		defaultDeploymentEventDeduplicator = NewDeploymentEventDeduplicator(time.Duration(cfg.DeploymentEventDeduplicationWindow) * time.Second)
+	})
+
+	return defaultDeploymentEventDeduplicator
+}
+
+// NewDeploymentEventDeduplicator creates a new DeploymentEventDeduplicator. If window is 0 or less, no event is suppressed
+func NewDeploymentEventDeduplicator(window time.Duration) *DeploymentEventDeduplicator {
+	return &DeploymentEventDeduplicator{
+		window: window,
+		now:    time.Now,
+		sentAt: make(map[deploymentEventKey]time.Time),
+	}
+}
+
+// IsDuplicate returns whether a deployment event for the Keptn context, stage and service was already sent within the window or is being sent.
+// Otherwise, the event is recorded as being sent until MarkSent or MarkFailed is called
+func (d *DeploymentEventDeduplicator) IsDuplicate(keptnContext string, stage string, service string) bool {
+	if d.window <= 0 || keptnContext == "" {
+		return false
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := d.now()
+	for key, sentAt := range d.sentAt {
+		if sentAt != sending && now.Sub(sentAt) >= d.window {
+			delete(d.sentAt, key)
+		}
+	}
+
+	key := deploymentEventKey{keptnContext: keptnContext, stage: stage, service: service}
+	if _, found := d.sentAt[key]; found {
+		return true
+	}
+
+	d.sentAt[key] = sending
+	return false
+}
+
+// MarkSent records that the Dynatrace API accepted the deployment event for the Keptn context, stage and service, so that the window starts
+func (d *DeploymentEventDeduplicator) MarkSent(keptnContext string, stage string, service string) {
+	if d.window <= 0 || keptnContext == "" {
+		return
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.sentAt[deploymentEventKey{keptnContext: keptnContext, stage: stage, service: service}] = d.now()
+}
+
+// MarkFailed forgets the deployment event for the Keptn context, stage and service, as it was not sent, so that it is not suppressed if it is sent again
+func (d *DeploymentEventDeduplicator) MarkFailed(keptnContext string, stage string, service string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	delete(d.sentAt, deploymentEventKey{keptnContext: keptnContext, stage: stage, service: service})
+}
+
+// Forget removes the deployment events recorded for the Keptn context, e.g. after the sequence was aborted
+func (d *DeploymentEventDeduplicator) Forget(keptnContext string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for key := range d.sentAt {
+		if key.keptnContext == keptnContext {
+			delete(d.sentAt, key)
+		}
+	}
+}
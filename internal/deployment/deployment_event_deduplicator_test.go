@@ -0,0 +1,83 @@
+package deployment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeploymentEventDeduplicator_IsDuplicate(t *testing.T) {
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	deduplicator := NewDeploymentEventDeduplicator(5 * time.Minute)
+	deduplicator.now = func() time.Time { return now }
+
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "first event")
+	assert.True(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "same event while sending")
+	deduplicator.MarkSent("ctx-1", "production", "carts")
+	assert.True(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "same event again")
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "staging", "carts"), "other stage")
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "orders"), "other service")
+	assert.False(t, deduplicator.IsDuplicate("ctx-2", "production", "carts"), "other Keptn context")
+
+	now = now.Add(4 * time.Minute)
+	assert.True(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "within window")
+
+	now = now.Add(time.Minute)
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "after window")
+	deduplicator.MarkSent("ctx-1", "production", "carts")
+	assert.True(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "within new window")
+}
+
+// Tests that an event that was not accepted by the Dynatrace API is not recorded as sent, so that it is sent again
+func TestDeploymentEventDeduplicator_MarkFailed(t *testing.T) {
+	now := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	deduplicator := NewDeploymentEventDeduplicator(5 * time.Minute)
+	deduplicator.now = func() time.Time { return now }
+
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"))
+	deduplicator.MarkFailed("ctx-1", "production", "carts")
+
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "failed event again")
+
+	now = now.Add(10 * time.Minute)
+	assert.True(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "event still being sent after window")
+}
+
+func TestDeploymentEventDeduplicator_IsDuplicateDisabled(t *testing.T) {
+	tests := []struct {
+		name         string
+		window       time.Duration
+		keptnContext string
+	}{
+		{
+			name:         "no window",
+			window:       0,
+			keptnContext: "ctx-1",
+		},
+		{
+			name:   "no Keptn context",
+			window: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deduplicator := NewDeploymentEventDeduplicator(tt.window)
+
+			assert.False(t, deduplicator.IsDuplicate(tt.keptnContext, "production", "carts"))
+			assert.False(t, deduplicator.IsDuplicate(tt.keptnContext, "production", "carts"))
+		})
+	}
+}
+
+func TestDeploymentEventDeduplicator_Forget(t *testing.T) {
+	deduplicator := NewDeploymentEventDeduplicator(5 * time.Minute)
+
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"))
+	assert.False(t, deduplicator.IsDuplicate("ctx-2", "production", "carts"))
+
+	deduplicator.Forget("ctx-1")
+
+	assert.False(t, deduplicator.IsDuplicate("ctx-1", "production", "carts"), "forgotten Keptn context")
+	assert.True(t, deduplicator.IsDuplicate("ctx-2", "production", "carts"), "other Keptn context")
+}
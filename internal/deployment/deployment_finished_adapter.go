@@ -5,6 +5,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -16,10 +17,11 @@ type DeploymentFinishedAdapterInterface interface {
 type DeploymentFinishedAdapter struct {
 	event      keptnv2.DeploymentFinishedEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewDeploymentFinishedAdapterFromEvent creates a new DeploymentFinishedAdapter from a cloudevents Event
-func NewDeploymentFinishedAdapterFromEvent(e cloudevents.Event) (*DeploymentFinishedAdapter, error) {
+func NewDeploymentFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*DeploymentFinishedAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	dfData := &keptnv2.DeploymentFinishedEventData{}
@@ -31,6 +33,7 @@ func NewDeploymentFinishedAdapterFromEvent(e cloudevents.Event) (*DeploymentFini
 	return &DeploymentFinishedAdapter{
 		event:      *dfData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -82,7 +85,7 @@ func (a DeploymentFinishedAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a DeploymentFinishedAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
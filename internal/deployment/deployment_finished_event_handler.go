@@ -2,34 +2,90 @@ package deployment
 
 import (
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	log "github.com/sirupsen/logrus"
 )
 
 type DeploymentFinishedEventHandler struct {
-	event       DeploymentFinishedAdapterInterface
-	dtClient    dynatrace.ClientInterface
-	eClient     keptn.EventClientInterface
-	attachRules *dynatrace.AttachRules
+	event        DeploymentFinishedAdapterInterface
+	dtClient     dynatrace.ClientInterface
+	eClient      keptn.EventClientInterface
+	attachRules  *dynatrace.AttachRules
+	eventTypes   dynatrace.EventTypeMapping
+	deduplicator *DeploymentEventDeduplicator
+	cfg          *env.Config
+
+	// enrichEntityTags adds the keptn_project, keptn_stage and keptn_service tags to the entities matched by custom attach rules
+	enrichEntityTags bool
 }
 
 // NewDeploymentFinishedEventHandler creates a new DeploymentFinishedEventHandler
-func NewDeploymentFinishedEventHandler(event DeploymentFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *DeploymentFinishedEventHandler {
+func NewDeploymentFinishedEventHandler(event DeploymentFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, deduplicator *DeploymentEventDeduplicator, cfg *env.Config) *DeploymentFinishedEventHandler {
 	return &DeploymentFinishedEventHandler{
-		event:       event,
-		dtClient:    dtClient,
-		eClient:     eClient,
-		attachRules: attachRules,
+		event:        event,
+		dtClient:     dtClient,
+		eClient:      eClient,
+		attachRules:  attachRules,
+		eventTypes:   eventTypes,
+		deduplicator: deduplicator,
+		cfg:          cfg,
+
+		enrichEntityTags: cfg.EntityTagEnrichmentEnabled,
 	}
 }
 
 // HandleEvent handles an action finished event
 func (eh *DeploymentFinishedEventHandler) HandleEvent() error {
+	if eh.deduplicator.IsDuplicate(eh.event.GetShKeptnContext(), eh.event.GetStage(), eh.event.GetService()) {
+		log.WithFields(
+			log.Fields{
+				"keptnContext": eh.event.GetShKeptnContext(),
+				"stage":        eh.event.GetStage(),
+				"service":      eh.event.GetService(),
+			}).Info("Dynatrace deployment event was already sent for this Keptn context, stage and service, skipping duplicate")
+		return nil
+	}
+
+	if eh.enrichEntityTags {
+		eh.tagMatchedEntities()
+	}
 
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
 
 	de := dynatrace.CreateDeploymentEventDTO(eh.event, imageAndTag, eh.attachRules)
 
-	dynatrace.NewEventsClient(eh.dtClient).AddDeploymentEvent(de)
+	keptnContext, stage, service := eh.event.GetShKeptnContext(), eh.event.GetStage(), eh.event.GetService()
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).OnSent(func(accepted bool) {
+		if accepted {
+			eh.deduplicator.MarkSent(keptnContext, stage, service)
+			return
+		}
+		eh.deduplicator.MarkFailed(keptnContext, stage, service)
+	}).AddDeploymentEvent(de)
 
 	return nil
 }
+
+// tagMatchedEntities adds the keptn_project, keptn_stage and keptn_service tags to the process group and service entities matched by the attach rules,
+// so that SLI entity selectors can rely on them even if they are not set using environment variables of the OneAgent.
+// The default attach rules already match these tags, so nothing is tagged if no attach rules are configured
+func (eh *DeploymentFinishedEventHandler) tagMatchedEntities() {
+	if eh.attachRules == nil {
+		return
+	}
+
+	tags := []dynatrace.CustomTag{
+		{Key: "keptn_project", Value: eh.event.GetProject()},
+		{Key: "keptn_stage", Value: eh.event.GetStage()},
+		{Key: "keptn_service", Value: eh.event.GetService()},
+	}
+
+	count, err := dynatrace.NewEntitiesClient(eh.dtClient).TagMatchingEntities(*eh.attachRules, tags)
+	if err != nil {
+		log.WithError(err).Error("Could not add Keptn tags to the entities matched by the attach rules")
+		return
+	}
+
+	log.WithField("matchedEntities", count).Info("Added Keptn tags to the entities matched by the attach rules")
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -13,16 +15,18 @@ type EvaluationFinishedAdapterInterface interface {
 
 	GetEvaluationScore() float64
 	GetResult() keptnv2.ResultType
+	GetIndicatorResults() []*keptnv2.SLIEvaluationResult
 }
 
 // EvaluationFinishedAdapter is a content adaptor for events of type sh.keptn.event.evaluation.finished
 type EvaluationFinishedAdapter struct {
 	event      keptnv2.EvaluationFinishedEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewEvaluationFinishedAdapterFromEvent creates a new EvaluationFinishedAdapter from a cloudevents Event
-func NewEvaluationFinishedAdapterFromEvent(e cloudevents.Event) (*EvaluationFinishedAdapter, error) {
+func NewEvaluationFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*EvaluationFinishedAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	efData := &keptnv2.EvaluationFinishedEventData{}
@@ -34,6 +38,7 @@ func NewEvaluationFinishedAdapterFromEvent(e cloudevents.Event) (*EvaluationFini
 	return &EvaluationFinishedAdapter{
 		event:      *efData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -85,12 +90,13 @@ func (a EvaluationFinishedAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a EvaluationFinishedAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
 	if err == nil {
-		labels["Keptns Bridge"] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+		labels[common.KEPTNSBRIDGE_EVALUATION_LABEL] = keptnBridgeURL + "/evaluation/" + a.GetShKeptnContext() + "/" + a.GetStage()
 	}
 	labels["Quality Gate Score"] = fmt.Sprintf("%.2f", a.event.Evaluation.Score)
 	labels["No of evaluated SLIs"] = fmt.Sprintf("%d", len(a.event.Evaluation.IndicatorResults))
@@ -106,3 +112,8 @@ func (a EvaluationFinishedAdapter) GetEvaluationScore() float64 {
 func (a EvaluationFinishedAdapter) GetResult() keptnv2.ResultType {
 	return a.event.Result
 }
+
+// GetIndicatorResults returns the evaluation results of the individual SLIs
+func (a EvaluationFinishedAdapter) GetIndicatorResults() []*keptnv2.SLIEvaluationResult {
+	return a.event.Evaluation.IndicatorResults
+}
@@ -2,8 +2,12 @@ package deployment
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
@@ -14,15 +18,27 @@ type EvaluationFinishedEventHandler struct {
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+
+	failureEvents    *failureEventSender
+	ingestMetrics    bool
+	publishDashboard bool
 }
 
 // NewEvaluationFinishedEventHandler creates a new EvaluationFinishedEventHandler
-func NewEvaluationFinishedEventHandler(event EvaluationFinishedAdapterInterface, client dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *EvaluationFinishedEventHandler {
+func NewEvaluationFinishedEventHandler(event EvaluationFinishedAdapterInterface, client dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *EvaluationFinishedEventHandler {
 	return &EvaluationFinishedEventHandler{
 		event:       event,
 		dtClient:    client,
 		eClient:     eClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+
+		failureEvents:    newFailureEventSender(client, attachRules, eventTypes, cfg),
+		ingestMetrics:    cfg.EvaluationMetricsIngestEnabled,
+		publishDashboard: cfg.QualityGateDashboardEnabled,
 	}
 }
 
@@ -57,8 +73,92 @@ func (eh *EvaluationFinishedEventHandler) HandleEvent() error {
 		}
 	}
 	ie.Description = qualityGateDescription
+	addIndicatorResultsToCustomProperties(ie.CustomProperties, eh.event.GetIndicatorResults())
+
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(ie)
+
+	if eh.event.GetResult() == keptnv2.ResultFailed {
+		eh.failureEvents.send(eh.event, imageAndTag, fmt.Sprintf("Keptn evaluation failed in stage %s", eh.event.GetStage()), qualityGateDescription)
+	}
+
+	if eh.ingestMetrics {
+		err := dynatrace.NewMetricsIngestClient(eh.dtClient).IngestMetrics(createEvaluationMetricLines(eh.event))
+		if err != nil {
+			log.WithError(err).Error("Could not ingest evaluation metrics")
+		}
+	}
 
-	dynatrace.NewEventsClient(eh.dtClient).AddInfoEvent(ie)
+	if eh.publishDashboard {
+		err := NewQualityGateDashboard(eh.dtClient).Update(eh.event, time.Now())
+		if err != nil {
+			log.WithError(err).Error("Could not publish evaluation result to quality gate dashboard")
+		}
+	}
 
 	return nil
 }
+
+// createEvaluationMetricLines creates the keptn.evaluation.score metric with the evaluation result as dimension
+func createEvaluationMetricLines(event EvaluationFinishedAdapterInterface) []dynatrace.MetricLine {
+	return []dynatrace.MetricLine{
+		{
+			MetricKey: "keptn.evaluation.score",
+			Dimensions: map[string]string{
+				"keptn_project": event.GetProject(),
+				"keptn_stage":   event.GetStage(),
+				"keptn_service": event.GetService(),
+				"result":        string(event.GetResult()),
+			},
+			Value: event.GetEvaluationScore(),
+		},
+	}
+}
+
+// addIndicatorResultsToCustomProperties adds one custom property per SLI containing its value, status and targets
+func addIndicatorResultsToCustomProperties(customProperties map[string]string, indicatorResults []*keptnv2.SLIEvaluationResult) {
+	for _, indicatorResult := range indicatorResults {
+		if indicatorResult == nil || indicatorResult.Value == nil {
+			continue
+		}
+
+		customProperties["SLI "+indicatorResult.Value.Metric] = formatIndicatorResult(indicatorResult)
+	}
+}
+
+func formatIndicatorResult(indicatorResult *keptnv2.SLIEvaluationResult) string {
+	var description string
+	if indicatorResult.Value.Success {
+		description = fmt.Sprintf("value: %.2f, status: %s", indicatorResult.Value.Value, indicatorResult.Status)
+	} else {
+		description = fmt.Sprintf("value: n/a (%s), status: %s", indicatorResult.Value.Message, indicatorResult.Status)
+	}
+
+	if len(indicatorResult.PassTargets) > 0 {
+		description += ", pass: " + formatSLITargets(indicatorResult.PassTargets)
+	}
+	if len(indicatorResult.WarningTargets) > 0 {
+		description += ", warning: " + formatSLITargets(indicatorResult.WarningTargets)
+	}
+	if indicatorResult.KeySLI {
+		description += ", key SLI"
+	}
+
+	return description
+}
+
+func formatSLITargets(targets []*keptnv2.SLITarget) string {
+	criteria := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if target == nil {
+			continue
+		}
+
+		if target.Violated {
+			criteria = append(criteria, target.Criteria+" (violated)")
+		} else {
+			criteria = append(criteria, target.Criteria)
+		}
+	}
+
+	return strings.Join(criteria, " ")
+}
@@ -0,0 +1,88 @@
+package deployment
+
+import (
+	"testing"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+func TestAddIndicatorResultsToCustomProperties(t *testing.T) {
+	indicatorResults := []*keptnv2.SLIEvaluationResult{
+		{
+			Value:  &keptnv2.SLIResult{Metric: "response_time_p95", Value: 612.3, Success: true},
+			Status: "warning",
+			PassTargets: []*keptnv2.SLITarget{
+				{Criteria: "<600", Violated: true},
+			},
+			WarningTargets: []*keptnv2.SLITarget{
+				{Criteria: "<=800"},
+			},
+			KeySLI: true,
+		},
+		{
+			Value:  &keptnv2.SLIResult{Metric: "error_rate", Success: false, Message: "no data"},
+			Status: "fail",
+		},
+		nil,
+	}
+
+	customProperties := map[string]string{}
+	addIndicatorResultsToCustomProperties(customProperties, indicatorResults)
+
+	assert.Equal(t,
+		map[string]string{
+			"SLI response_time_p95": "value: 612.30, status: warning, pass: <600 (violated), warning: <=800, key SLI",
+			"SLI error_rate":        "value: n/a (no data), status: fail",
+		},
+		customProperties)
+}
+
+func TestEvaluationFinishedEventHandler_HandleEventSendsFailureEvent(t *testing.T) {
+	tests := []struct {
+		name              string
+		result            keptnv2.ResultType
+		wantFailureEvents int
+	}{
+		{
+			name:              "failed evaluation",
+			result:            keptnv2.ResultFailed,
+			wantFailureEvents: 1,
+		},
+		{
+			name:   "passed evaluation",
+			result: keptnv2.ResultPass,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newDynatraceEventsRecorder(t)
+			dtClient := newTestDynatraceClient(t, recorder)
+
+			event := newTestEvaluationFinishedEventData("production", "carts", tt.result, 40)
+			event.Labels[common.KEPTNSBRIDGE_LABEL] = "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"
+
+			cfg := env.ReadConfig()
+			handler := &EvaluationFinishedEventHandler{
+				event:         event,
+				dtClient:      dtClient,
+				eClient:       &eventClientMock{},
+				cfg:           cfg,
+				failureEvents: &failureEventSender{dtClient: dtClient, cfg: cfg, eventType: dynatrace.AvailabilityEventType},
+			}
+			assert.NoError(t, handler.HandleEvent())
+
+			assert.Len(t, recorder.eventsOfType(dynatrace.InfoEventType), 1)
+
+			failureEvents := recorder.eventsOfType(dynatrace.AvailabilityEventType)
+			if assert.Len(t, failureEvents, tt.wantFailureEvents) && tt.wantFailureEvents > 0 {
+				assert.Equal(t, "Keptn evaluation failed in stage production", failureEvents[0]["title"])
+				assert.Equal(t, "Quality Gate Result in stage production: fail (40.00/100) - see https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", failureEvents[0]["description"])
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package deployment
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+// failureEventSender sends the Dynatrace failure events for failed evaluations and errored sequences
+type failureEventSender struct {
+	dtClient    dynatrace.ClientInterface
+	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+
+	// eventType is the Dynatrace event type of failure events, no event is sent if it is empty
+	eventType string
+}
+
+// newFailureEventSender creates a new failureEventSender using the failure event type of the configuration.
+// No failure events are sent for Keptn event types mapped to NONE by the eventTypes
+func newFailureEventSender(dtClient dynatrace.ClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *failureEventSender {
+	return &failureEventSender{
+		dtClient:    dtClient,
+		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		eventType:   getFailureEventType(cfg),
+		cfg:         cfg,
+	}
+}
+
+// isEnabled returns whether failure events are sent
+func (s *failureEventSender) isEnabled() bool {
+	return s.eventType != ""
+}
+
+// send sends a failure event for the Keptn event with the title and the description linking to the Keptn bridge, if failure events are enabled
+func (s *failureEventSender) send(event adapter.EventContentAdapter, imageAndTag common.ImageAndTag, title string, description string) {
+	if !s.isEnabled() {
+		return
+	}
+
+	ee := dynatrace.CreateErrorEventDTO(event, imageAndTag, s.attachRules, s.eventType)
+	ee.Title = title
+	ee.Description = withBridgeLink(description, event.GetLabels())
+
+	dynatrace.NewEventsClientWithEventTypes(s.dtClient, s.eventTypes, s.cfg).AddErrorEvent(ee)
+}
+
+// getFailureEventType returns the configured Dynatrace failure event type or an empty string if failure events are disabled
+func getFailureEventType(cfg *env.Config) string {
+	if !cfg.FailureEventsEnabled {
+		return ""
+	}
+
+	return cfg.FailureEventType
+}
+
+func withBridgeLink(description string, labels map[string]string) string {
+	bridgeURL := labels[common.KEPTNSBRIDGE_LABEL]
+	if bridgeURL == "" {
+		return description
+	}
+
+	return description + " - see " + bridgeURL
+}
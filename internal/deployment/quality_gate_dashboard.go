@@ -0,0 +1,256 @@
+package deployment
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+const qualityGateDashboardNamePrefix = "Keptn Quality Gate Results: "
+const qualityGateStageHeadingPrefix = "## Stage "
+const qualityGateTableHeader = "| Service | Result | Score | Evaluated | Details | History |\n|---|---|---|---|---|---|"
+
+// qualityGateHistoryLength is the number of results shown in the history column, including the latest one
+const qualityGateHistoryLength = 5
+
+// qualityGateTileWidth and qualityGateRowHeight define the size of the markdown tiles, the dashboard grid has a size of 38 pixels
+const qualityGateTileWidth = 1216
+const qualityGateRowHeight = 38
+
+// qualityGateResultRow is a row of the table of a stage, containing the latest evaluation result of a service
+type qualityGateResultRow struct {
+	service   string
+	result    string
+	score     string
+	evaluated string
+	details   string
+	history   []string
+}
+
+func (r qualityGateResultRow) String() string {
+	return fmt.Sprintf("| %s | %s | %s | %s | %s | %s |", r.service, r.result, r.score, r.evaluated, r.details, strings.Join(r.history, " "))
+}
+
+// qualityGateDashboardLocks serializes the updates of each dashboard, as concurrent evaluations would otherwise overwrite each other's results
+var qualityGateDashboardLocks = struct {
+	sync.Mutex
+	dashboards map[string]*sync.Mutex
+}{dashboards: map[string]*sync.Mutex{}}
+
+// lockQualityGateDashboard locks the dashboard of the project in the tenant and returns the function unlocking it
+func lockQualityGateDashboard(tenant string, project string) func() {
+	key := tenant + "|" + project
+
+	qualityGateDashboardLocks.Lock()
+	lock, ok := qualityGateDashboardLocks.dashboards[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		qualityGateDashboardLocks.dashboards[key] = lock
+	}
+	qualityGateDashboardLocks.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+// QualityGateDashboard publishes the latest evaluation results of the services of a project to a Dynatrace dashboard with one markdown tile per stage
+type QualityGateDashboard struct {
+	client dynatrace.ClientInterface
+}
+
+// NewQualityGateDashboard creates a new QualityGateDashboard
+func NewQualityGateDashboard(client dynatrace.ClientInterface) *QualityGateDashboard {
+	return &QualityGateDashboard{
+		client: client,
+	}
+}
+
+// Update adds the evaluation result to the dashboard of the project, which is created if it does not exist yet
+func (d *QualityGateDashboard) Update(event EvaluationFinishedAdapterInterface, evaluated time.Time) error {
+	unlock := lockQualityGateDashboard(d.client.Credentials().Tenant, event.GetProject())
+	defer unlock()
+
+	dashboardsClient := dynatrace.NewDashboardsClient(d.client)
+	dashboard, err := d.getOrCreateDashboard(dashboardsClient, event.GetProject())
+	if err != nil {
+		return err
+	}
+
+	addEvaluationResult(dashboard, event, evaluated)
+
+	if dashboard.ID == "" {
+		return dashboardsClient.Create(dashboard)
+	}
+	return dashboardsClient.Update(dashboard)
+}
+
+func (d *QualityGateDashboard) getOrCreateDashboard(dashboardsClient *dynatrace.DashboardsClient, project string) (*dynatrace.Dashboard, error) {
+	dashboards, err := dashboardsClient.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve dashboards: %w", err)
+	}
+
+	for _, dashboardEntry := range dashboards.Dashboards {
+		if dashboardEntry.Name == getQualityGateDashboardName(project) {
+			return dashboardsClient.GetByID(dashboardEntry.ID)
+		}
+	}
+
+	return &dynatrace.Dashboard{
+		DashboardMetadata: dynatrace.DashboardMetadata{
+			Name:   getQualityGateDashboardName(project),
+			Shared: true,
+			SharingDetails: dynatrace.SharingDetails{
+				LinkShared: true,
+			},
+		},
+		Tiles: []dynatrace.Tile{},
+	}, nil
+}
+
+func getQualityGateDashboardName(project string) string {
+	return qualityGateDashboardNamePrefix + project
+}
+
+// addEvaluationResult replaces the row of the service in the tile of the stage, adds the tile if necessary and arranges the stage tiles below each other.
+// Tiles added to the dashboard by users are not moved
+func addEvaluationResult(dashboard *dynatrace.Dashboard, event EvaluationFinishedAdapterInterface, evaluated time.Time) {
+	tileIndex := findStageTile(dashboard.Tiles, event.GetStage())
+	if tileIndex < 0 {
+		// the tile is added below all existing tiles, so that it does not overlap tiles added by users
+		dashboard.Tiles = append(dashboard.Tiles, dynatrace.Tile{
+			Name:       "Markdown",
+			TileType:   "MARKDOWN",
+			Configured: true,
+			Bounds:     dynatrace.Bounds{Top: getBottom(dashboard.Tiles)},
+		})
+		tileIndex = len(dashboard.Tiles) - 1
+	}
+
+	rows := parseQualityGateResultRows(dashboard.Tiles[tileIndex].Markdown)
+	rows = upsertQualityGateResultRow(rows, qualityGateResultRow{
+		service:   event.GetService(),
+		result:    string(event.GetResult()),
+		score:     fmt.Sprintf("%.2f", event.GetEvaluationScore()),
+		evaluated: evaluated.UTC().Format("2006-01-02 15:04 MST"),
+		details:   getEvaluationDetailsLink(event.GetLabels()),
+	})
+	dashboard.Tiles[tileIndex].Markdown = renderStageMarkdown(event.GetStage(), rows)
+	dashboard.Tiles[tileIndex].Bounds.Height = qualityGateRowHeight * (len(rows) + 3)
+
+	arrangeStageTiles(dashboard.Tiles)
+}
+
+// arrangeStageTiles arranges the stage tiles below each other, starting at the top of the first one, and leaves all other tiles where they are
+func arrangeStageTiles(tiles []dynatrace.Tile) {
+	top := -1
+	for i := range tiles {
+		if !isStageTile(tiles[i]) {
+			continue
+		}
+
+		if top < 0 {
+			top = tiles[i].Bounds.Top
+		}
+		tiles[i].Bounds.Top = top
+		tiles[i].Bounds.Left = 0
+		tiles[i].Bounds.Width = qualityGateTileWidth
+		top += tiles[i].Bounds.Height
+	}
+}
+
+// getBottom returns the bottom of the lowest tile
+func getBottom(tiles []dynatrace.Tile) int {
+	bottom := 0
+	for _, tile := range tiles {
+		if tile.Bounds.Top+tile.Bounds.Height > bottom {
+			bottom = tile.Bounds.Top + tile.Bounds.Height
+		}
+	}
+	return bottom
+}
+
+func findStageTile(tiles []dynatrace.Tile, stage string) int {
+	for i, tile := range tiles {
+		if isStageTile(tile) && strings.HasPrefix(tile.Markdown, qualityGateStageHeadingPrefix+stage+"\n") {
+			return i
+		}
+	}
+	return -1
+}
+
+// isStageTile returns whether the tile is a stage tile created by the dynatrace-service
+func isStageTile(tile dynatrace.Tile) bool {
+	return tile.TileType == "MARKDOWN" && strings.HasPrefix(tile.Markdown, qualityGateStageHeadingPrefix) && strings.Contains(tile.Markdown, "\n"+qualityGateTableHeader)
+}
+
+// parseQualityGateResultRows parses the rows of the table of a stage tile, skipping the header and rows that cannot be parsed
+func parseQualityGateResultRows(markdown string) []qualityGateResultRow {
+	var rows []qualityGateResultRow
+	for _, line := range strings.Split(markdown, "\n") {
+		if !strings.HasPrefix(line, "| ") || strings.HasPrefix(line, "| Service |") {
+			continue
+		}
+
+		cells := strings.Split(strings.TrimSuffix(strings.TrimPrefix(line, "| "), " |"), " | ")
+		if len(cells) != 6 {
+			continue
+		}
+
+		rows = append(rows, qualityGateResultRow{
+			service:   cells[0],
+			result:    cells[1],
+			score:     cells[2],
+			evaluated: cells[3],
+			details:   cells[4],
+			history:   strings.Fields(cells[5]),
+		})
+	}
+	return rows
+}
+
+// upsertQualityGateResultRow replaces the row of the service, keeping its history, or adds it. Rows are kept in the order they were added
+func upsertQualityGateResultRow(rows []qualityGateResultRow, row qualityGateResultRow) []qualityGateResultRow {
+	for i, existingRow := range rows {
+		if existingRow.service == row.service {
+			row.history = appendToHistory(existingRow.history, row.result)
+			rows[i] = row
+			return rows
+		}
+	}
+
+	row.history = []string{row.result}
+	return append(rows, row)
+}
+
+// appendToHistory returns the history with the result as latest entry, dropping the oldest entries if necessary
+func appendToHistory(history []string, result string) []string {
+	history = append(history, result)
+	if len(history) > qualityGateHistoryLength {
+		history = history[len(history)-qualityGateHistoryLength:]
+	}
+	return history
+}
+
+func renderStageMarkdown(stage string, rows []qualityGateResultRow) string {
+	lines := []string{qualityGateStageHeadingPrefix + stage, "", qualityGateTableHeader}
+	for _, row := range rows {
+		lines = append(lines, row.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+func getEvaluationDetailsLink(labels map[string]string) string {
+	bridgeURL := labels[common.KEPTNSBRIDGE_EVALUATION_LABEL]
+	if bridgeURL == "" {
+		bridgeURL = labels[common.KEPTNSBRIDGE_LABEL]
+	}
+	if bridgeURL == "" {
+		return "-"
+	}
+	return "[Keptn Bridge](" + bridgeURL + ")"
+}
@@ -0,0 +1,225 @@
+package deployment
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+type evaluationFinishedEventData struct {
+	test.EventData
+	score  float64
+	result keptnv2.ResultType
+}
+
+func (e *evaluationFinishedEventData) GetEvaluationScore() float64 {
+	return e.score
+}
+
+func (e *evaluationFinishedEventData) GetResult() keptnv2.ResultType {
+	return e.result
+}
+
+func (e *evaluationFinishedEventData) GetIndicatorResults() []*keptnv2.SLIEvaluationResult {
+	return nil
+}
+
+func newTestEvaluationFinishedEventData(stage string, service string, result keptnv2.ResultType, score float64) *evaluationFinishedEventData {
+	return &evaluationFinishedEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Project: "sockshop",
+			Stage:   stage,
+			Service: service,
+			Labels:  map[string]string{common.KEPTNSBRIDGE_EVALUATION_LABEL: "https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/" + stage},
+		},
+		score:  score,
+		result: result,
+	}
+}
+
+func TestAddEvaluationResult(t *testing.T) {
+	evaluated := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+	dashboard := &dynatrace.Dashboard{}
+
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("staging", "carts", keptnv2.ResultFailed, 40), evaluated)
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("staging", "orders", keptnv2.ResultPass, 100), evaluated)
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("production", "carts", keptnv2.ResultPass, 90), evaluated)
+	for i := 0; i < 5; i++ {
+		addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("staging", "carts", keptnv2.ResultWarning, 75), evaluated.Add(time.Hour))
+	}
+
+	if !assert.Len(t, dashboard.Tiles, 2) {
+		return
+	}
+
+	assert.Equal(t, "## Stage staging\n\n"+
+		"| Service | Result | Score | Evaluated | Details | History |\n"+
+		"|---|---|---|---|---|---|\n"+
+		"| carts | warning | 75.00 | 2021-03-24 13:00 UTC | [Keptn Bridge](https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/staging) | warning warning warning warning warning |\n"+
+		"| orders | pass | 100.00 | 2021-03-24 12:00 UTC | [Keptn Bridge](https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/staging) | pass |",
+		dashboard.Tiles[0].Markdown)
+	assert.Equal(t, dynatrace.Bounds{Top: 0, Left: 0, Width: qualityGateTileWidth, Height: 5 * qualityGateRowHeight}, dashboard.Tiles[0].Bounds)
+
+	assert.Equal(t, "## Stage production\n\n"+
+		"| Service | Result | Score | Evaluated | Details | History |\n"+
+		"|---|---|---|---|---|---|\n"+
+		"| carts | pass | 90.00 | 2021-03-24 12:00 UTC | [Keptn Bridge](https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/production) | pass |",
+		dashboard.Tiles[1].Markdown)
+	assert.Equal(t, dynatrace.Bounds{Top: 5 * qualityGateRowHeight, Left: 0, Width: qualityGateTileWidth, Height: 4 * qualityGateRowHeight}, dashboard.Tiles[1].Bounds)
+}
+
+func TestAddEvaluationResult_DoesNotMoveTilesOfUsers(t *testing.T) {
+	evaluated := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+	userTile := dynatrace.Tile{Name: "Markdown", TileType: "MARKDOWN", Markdown: "## Stage notes\n\nOwned by the carts team", Bounds: dynatrace.Bounds{Top: 0, Left: 0, Width: 304, Height: 152}}
+	userChart := dynatrace.Tile{Name: "Response time", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 0, Left: 304, Width: 608, Height: 304}}
+	dashboard := &dynatrace.Dashboard{Tiles: []dynatrace.Tile{userTile, userChart}}
+
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("staging", "carts", keptnv2.ResultPass, 100), evaluated)
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("production", "carts", keptnv2.ResultPass, 90), evaluated)
+	addEvaluationResult(dashboard, newTestEvaluationFinishedEventData("staging", "orders", keptnv2.ResultPass, 100), evaluated)
+
+	if !assert.Len(t, dashboard.Tiles, 4) {
+		return
+	}
+
+	assert.Equal(t, userTile, dashboard.Tiles[0])
+	assert.Equal(t, userChart, dashboard.Tiles[1])
+
+	// the stage tiles are added below the tiles of users and arranged below each other when they grow
+	assert.Equal(t, dynatrace.Bounds{Top: 304, Left: 0, Width: qualityGateTileWidth, Height: 5 * qualityGateRowHeight}, dashboard.Tiles[2].Bounds)
+	assert.Equal(t, dynatrace.Bounds{Top: 304 + 5*qualityGateRowHeight, Left: 0, Width: qualityGateTileWidth, Height: 4 * qualityGateRowHeight}, dashboard.Tiles[3].Bounds)
+}
+
+func TestQualityGateDashboard_UpdateSerializesConcurrentUpdates(t *testing.T) {
+	const dashboardID = "b6bd2cb4-9f02-4c2f-9b0a-7c6fbc0a1b7e"
+
+	var mutex sync.Mutex
+	var storedDashboard []byte
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/config/v1/dashboards":
+			if storedDashboard == nil {
+				w.Write([]byte(`{"dashboards": []}`))
+				return
+			}
+			w.Write([]byte(`{"dashboards": [{"id": "` + dashboardID + `", "name": "Keptn Quality Gate Results: sockshop"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/config/v1/dashboards/"+dashboardID:
+			w.Write(storedDashboard)
+		case r.Method == http.MethodPost || r.Method == http.MethodPut:
+			body, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+
+			dashboard := &dynatrace.Dashboard{}
+			assert.NoError(t, json.Unmarshal(body, dashboard))
+			dashboard.ID = dashboardID
+			storedDashboard, err = json.Marshal(dashboard)
+			assert.NoError(t, err)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+
+	services := []string{"carts", "orders", "payment", "shipping", "user"}
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func(service string) {
+			defer wg.Done()
+			err := NewQualityGateDashboard(dtClient).Update(newTestEvaluationFinishedEventData("staging", service, keptnv2.ResultPass, 100), time.Date(2021, 3, 24, 13, 0, 0, 0, time.UTC))
+			assert.NoError(t, err)
+		}(service)
+	}
+	wg.Wait()
+
+	dashboard := &dynatrace.Dashboard{}
+	assert.NoError(t, json.Unmarshal(storedDashboard, dashboard))
+	if assert.Len(t, dashboard.Tiles, 1) {
+		// no result was overwritten by a concurrent update
+		assert.Len(t, parseQualityGateResultRows(dashboard.Tiles[0].Markdown), len(services))
+	}
+}
+
+func TestQualityGateDashboard_Update(t *testing.T) {
+	existingDashboard := &dynatrace.Dashboard{ID: "b6bd2cb4-9f02-4c2f-9b0a-7c6fbc0a1b7e", DashboardMetadata: dynatrace.DashboardMetadata{Name: "Keptn Quality Gate Results: sockshop"}}
+	addEvaluationResult(existingDashboard, newTestEvaluationFinishedEventData("staging", "carts", keptnv2.ResultFailed, 40), time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name           string
+		dashboards     string
+		expectedMethod string
+		expectedPath   string
+		expectedRows   string
+	}{
+		{
+			name:           "new dashboard",
+			dashboards:     `{"dashboards": [{"id": "e03f4be0-4712-4f12-96ee-8c486d001e9b", "name": "sockshop@keptn: Digital Delivery & Operations Dashboard"}]}`,
+			expectedMethod: http.MethodPost,
+			expectedPath:   "/api/config/v1/dashboards",
+			expectedRows:   "| carts | pass | 95.00 | 2021-03-24 13:00 UTC | [Keptn Bridge](https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/staging) | pass |",
+		},
+		{
+			name:           "existing dashboard",
+			dashboards:     `{"dashboards": [{"id": "b6bd2cb4-9f02-4c2f-9b0a-7c6fbc0a1b7e", "name": "Keptn Quality Gate Results: sockshop"}]}`,
+			expectedMethod: http.MethodPut,
+			expectedPath:   "/api/config/v1/dashboards/b6bd2cb4-9f02-4c2f-9b0a-7c6fbc0a1b7e",
+			expectedRows:   "| carts | pass | 95.00 | 2021-03-24 13:00 UTC | [Keptn Bridge](https://bridge.example.com/evaluation/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9/staging) | fail pass |",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var writtenDashboard *dynatrace.Dashboard
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch {
+				case r.Method == http.MethodGet && r.URL.Path == "/api/config/v1/dashboards":
+					w.Write([]byte(tt.dashboards))
+				case r.Method == http.MethodGet && r.URL.Path == "/api/config/v1/dashboards/"+existingDashboard.ID:
+					body, _ := json.Marshal(existingDashboard)
+					w.Write(body)
+				case r.Method == tt.expectedMethod && r.URL.Path == tt.expectedPath:
+					body, err := ioutil.ReadAll(r.Body)
+					assert.NoError(t, err)
+					writtenDashboard = &dynatrace.Dashboard{}
+					assert.NoError(t, json.Unmarshal(body, writtenDashboard))
+					w.Write([]byte(`{}`))
+				default:
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			httpClient, teardown := test.CreateHTTPClient(handler)
+			defer teardown()
+
+			dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+			err := NewQualityGateDashboard(dtClient).Update(newTestEvaluationFinishedEventData("staging", "carts", keptnv2.ResultPass, 95), time.Date(2021, 3, 24, 13, 0, 0, 0, time.UTC))
+			assert.NoError(t, err)
+
+			if assert.NotNil(t, writtenDashboard) && assert.Len(t, writtenDashboard.Tiles, 1) {
+				assert.Equal(t, "Keptn Quality Gate Results: sockshop", writtenDashboard.DashboardMetadata.Name)
+				assert.Contains(t, writtenDashboard.Tiles[0].Markdown, tt.expectedRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package deployment
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type ReleaseFinishedAdapterInterface interface {
+	adapter.EventContentAdapter
+
+	GetResult() keptnv2.ResultType
+	GetMessage() string
+}
+
+// ReleaseFinishedAdapter is a content adaptor for events of type sh.keptn.event.release.finished
+type ReleaseFinishedAdapter struct {
+	event      keptnv2.ReleaseFinishedEventData
+	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
+}
+
+// NewReleaseFinishedAdapterFromEvent creates a new ReleaseFinishedAdapter from a cloudevents Event
+func NewReleaseFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*ReleaseFinishedAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	rfData := &keptnv2.ReleaseFinishedEventData{}
+	err := ceAdapter.PayloadAs(rfData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReleaseFinishedAdapter{
+		event:      *rfData,
+		cloudEvent: ceAdapter,
+		cfg:        cfg,
+	}, nil
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a ReleaseFinishedAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a ReleaseFinishedAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a ReleaseFinishedAdapter) GetEvent() string {
+	return keptnv2.GetFinishedEventType(keptnv2.ReleaseTaskName)
+}
+
+// GetProject returns the project
+func (a ReleaseFinishedAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a ReleaseFinishedAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a ReleaseFinishedAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a ReleaseFinishedAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a ReleaseFinishedAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a ReleaseFinishedAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a ReleaseFinishedAdapter) GetLabels() map[string]string {
+	labels := a.event.Labels
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if err == nil {
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+	}
+	return labels
+}
+
+// GetResult returns the result of the release
+func (a ReleaseFinishedAdapter) GetResult() keptnv2.ResultType {
+	return a.event.Result
+}
+
+// GetMessage returns the message of the release
+func (a ReleaseFinishedAdapter) GetMessage() string {
+	return a.event.Message
+}
@@ -0,0 +1,60 @@
+package deployment
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type ReleaseFinishedEventHandler struct {
+	event       ReleaseFinishedAdapterInterface
+	dtClient    dynatrace.ClientInterface
+	eClient     keptn.EventClientInterface
+	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+}
+
+// NewReleaseFinishedEventHandler creates a new ReleaseFinishedEventHandler
+func NewReleaseFinishedEventHandler(event ReleaseFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *ReleaseFinishedEventHandler {
+	return &ReleaseFinishedEventHandler{
+		event:       event,
+		dtClient:    dtClient,
+		eClient:     eClient,
+		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+	}
+}
+
+// HandleEvent handles a release finished event
+func (eh *ReleaseFinishedEventHandler) HandleEvent() error {
+	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+
+	ie := dynatrace.CreateReleaseInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
+	if ie.Title == "" {
+		ie.Title = getReleaseFinishedTitle(eh.event)
+	}
+	if ie.Description == "" {
+		ie.Description = getReleaseFinishedDescription(eh.event)
+	}
+
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(ie)
+
+	return nil
+}
+
+func getReleaseFinishedTitle(event ReleaseFinishedAdapterInterface) string {
+	if event.GetResult() == keptnv2.ResultFailed {
+		return "Release failed in " + event.GetStage()
+	}
+	return "Release finished in " + event.GetStage()
+}
+
+func getReleaseFinishedDescription(event ReleaseFinishedAdapterInterface) string {
+	if event.GetMessage() != "" {
+		return event.GetMessage()
+	}
+	return getReleaseFinishedTitle(event) + " for service " + event.GetService()
+}
@@ -4,6 +4,7 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -17,10 +18,11 @@ type ReleaseTriggeredAdapterInterface interface {
 type ReleaseTriggeredAdapter struct {
 	event      keptnv2.ReleaseTriggeredEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewReleaseTriggeredAdapterFromEvent creates a new ReleaseTriggeredAdapter from a cloudevents Event
-func NewReleaseTriggeredAdapterFromEvent(e cloudevents.Event) (*ReleaseTriggeredAdapter, error) {
+func NewReleaseTriggeredAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*ReleaseTriggeredAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	rtData := &keptnv2.ReleaseTriggeredEventData{}
@@ -32,6 +34,7 @@ func NewReleaseTriggeredAdapterFromEvent(e cloudevents.Event) (*ReleaseTriggered
 	return &ReleaseTriggeredAdapter{
 		event:      *rtData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -83,7 +86,7 @@ func (a ReleaseTriggeredAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a ReleaseTriggeredAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
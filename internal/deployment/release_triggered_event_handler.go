@@ -3,6 +3,7 @@ package deployment
 import (
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	keptnevents "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
@@ -14,15 +15,19 @@ type ReleaseTriggeredEventHandler struct {
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
 }
 
 // NewReleaseTriggeredEventHandler creates a new ReleaseTriggeredEventHandler
-func NewReleaseTriggeredEventHandler(event ReleaseTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *ReleaseTriggeredEventHandler {
+func NewReleaseTriggeredEventHandler(event ReleaseTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *ReleaseTriggeredEventHandler {
 	return &ReleaseTriggeredEventHandler{
 		event:       event,
 		dtClient:    dtClient,
 		eClient:     eClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
 	}
 }
 
@@ -36,7 +41,7 @@ func (eh *ReleaseTriggeredEventHandler) HandleEvent() error {
 
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
 
-	ie := dynatrace.CreateInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
+	ie := dynatrace.CreateReleaseInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
 	if strategy == keptnevents.Direct && eh.event.GetResult() == keptnv2.ResultPass || eh.event.GetResult() == keptnv2.ResultWarning {
 		title := fmt.Sprintf("PROMOTING from %s to next stage", eh.event.GetStage())
 		ie.Title = title
@@ -53,7 +58,7 @@ func (eh *ReleaseTriggeredEventHandler) HandleEvent() error {
 		}
 	}
 
-	dynatrace.NewEventsClient(eh.dtClient).AddInfoEvent(ie)
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(ie)
 
 	return nil
 }
@@ -0,0 +1,115 @@
+package deployment
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type RollbackAdapterInterface interface {
+	adapter.EventContentAdapter
+
+	IsFinishedEvent() bool
+	GetResult() keptnv2.ResultType
+	GetMessage() string
+}
+
+// RollbackAdapter is a content adaptor for events of type sh.keptn.event.rollback.triggered and sh.keptn.event.rollback.finished
+type RollbackAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
+}
+
+// NewRollbackAdapterFromEvent creates a new RollbackAdapter from a cloudevents Event
+func NewRollbackAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*RollbackAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	rData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(rData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RollbackAdapter{
+		event:      *rData,
+		cloudEvent: ceAdapter,
+		cfg:        cfg,
+	}, nil
+}
+
+// IsFinishedEvent returns whether the event is a rollback.finished event
+func (a RollbackAdapter) IsFinishedEvent() bool {
+	return a.cloudEvent.Type() == keptnv2.GetFinishedEventType(keptnv2.RollbackTaskName)
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a RollbackAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a RollbackAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a RollbackAdapter) GetEvent() string {
+	return a.cloudEvent.Type()
+}
+
+// GetProject returns the project
+func (a RollbackAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a RollbackAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a RollbackAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a RollbackAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a RollbackAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a RollbackAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a RollbackAdapter) GetLabels() map[string]string {
+	labels := a.event.Labels
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if err == nil {
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+	}
+	return labels
+}
+
+// GetResult returns the result of a rollback.finished event
+func (a RollbackAdapter) GetResult() keptnv2.ResultType {
+	return a.event.Result
+}
+
+// GetMessage returns the message of a rollback.finished event
+func (a RollbackAdapter) GetMessage() string {
+	return a.event.Message
+}
@@ -0,0 +1,63 @@
+package deployment
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type RollbackEventHandler struct {
+	event       RollbackAdapterInterface
+	dtClient    dynatrace.ClientInterface
+	eClient     keptn.EventClientInterface
+	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+}
+
+// NewRollbackEventHandler creates a new RollbackEventHandler
+func NewRollbackEventHandler(event RollbackAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *RollbackEventHandler {
+	return &RollbackEventHandler{
+		event:       event,
+		dtClient:    dtClient,
+		eClient:     eClient,
+		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+	}
+}
+
+// HandleEvent handles a rollback triggered or finished event
+func (eh *RollbackEventHandler) HandleEvent() error {
+	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+
+	ie := dynatrace.CreateInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
+	if ie.Title == "" {
+		ie.Title = getRollbackTitle(eh.event)
+	}
+	if ie.Description == "" {
+		ie.Description = getRollbackDescription(eh.event)
+	}
+
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(ie)
+
+	return nil
+}
+
+func getRollbackTitle(event RollbackAdapterInterface) string {
+	if !event.IsFinishedEvent() {
+		return "Rollback triggered in " + event.GetStage()
+	}
+	if event.GetResult() == keptnv2.ResultFailed {
+		return "Rollback failed in " + event.GetStage()
+	}
+	return "Rollback executed in " + event.GetStage()
+}
+
+func getRollbackDescription(event RollbackAdapterInterface) string {
+	if event.IsFinishedEvent() && event.GetMessage() != "" {
+		return event.GetMessage()
+	}
+	return getRollbackTitle(event) + " for service " + event.GetService()
+}
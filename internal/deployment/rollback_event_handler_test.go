@@ -0,0 +1,144 @@
+package deployment
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeRollbackAdapter struct {
+	test.EventData
+	finished bool
+	result   keptnv2.ResultType
+	message  string
+}
+
+func (a *fakeRollbackAdapter) IsFinishedEvent() bool {
+	return a.finished
+}
+
+func (a *fakeRollbackAdapter) GetResult() keptnv2.ResultType {
+	return a.result
+}
+
+func (a *fakeRollbackAdapter) GetMessage() string {
+	return a.message
+}
+
+func TestGetRollbackTitleAndDescription(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           *fakeRollbackAdapter
+		wantTitle       string
+		wantDescription string
+	}{
+		{
+			name:            "triggered",
+			event:           &fakeRollbackAdapter{},
+			wantTitle:       "Rollback triggered in production",
+			wantDescription: "Rollback triggered in production for service carts",
+		},
+		{
+			name:            "finished",
+			event:           &fakeRollbackAdapter{finished: true, result: keptnv2.ResultPass},
+			wantTitle:       "Rollback executed in production",
+			wantDescription: "Rollback executed in production for service carts",
+		},
+		{
+			name:            "failed with message",
+			event:           &fakeRollbackAdapter{finished: true, result: keptnv2.ResultFailed, message: "helm rollback failed"},
+			wantTitle:       "Rollback failed in production",
+			wantDescription: "helm rollback failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.EventData = test.EventData{Project: "sockshop", Stage: "production", Service: "carts"}
+
+			assert.Equal(t, tt.wantTitle, getRollbackTitle(tt.event))
+			assert.Equal(t, tt.wantDescription, getRollbackDescription(tt.event))
+		})
+	}
+}
+
+func TestRollbackEventHandler_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           *fakeRollbackAdapter
+		eventTypes      dynatrace.EventTypeMapping
+		wantEventType   string
+		wantTitle       string
+		wantDescription string
+	}{
+		{
+			name:            "triggered",
+			event:           &fakeRollbackAdapter{EventData: test.EventData{Event: "sh.keptn.event.rollback.triggered"}},
+			wantEventType:   dynatrace.InfoEventType,
+			wantTitle:       "Rollback triggered in production",
+			wantDescription: "Rollback triggered in production for service carts",
+		},
+		{
+			name:            "failed",
+			event:           &fakeRollbackAdapter{EventData: test.EventData{Event: "sh.keptn.event.rollback.finished"}, finished: true, result: keptnv2.ResultFailed, message: "helm rollback failed"},
+			wantEventType:   dynatrace.InfoEventType,
+			wantTitle:       "Rollback failed in production",
+			wantDescription: "helm rollback failed",
+		},
+		{
+			name: "title and description from labels",
+			event: &fakeRollbackAdapter{
+				EventData: test.EventData{Event: "sh.keptn.event.rollback.finished", Labels: map[string]string{"title": "Rolled back carts", "description": "by on-call"}},
+				finished:  true,
+				result:    keptnv2.ResultPass,
+			},
+			wantEventType:   dynatrace.InfoEventType,
+			wantTitle:       "Rolled back carts",
+			wantDescription: "by on-call",
+		},
+		{
+			name:            "mapped to annotation event",
+			event:           &fakeRollbackAdapter{EventData: test.EventData{Event: "sh.keptn.event.rollback.triggered"}},
+			eventTypes:      dynatrace.EventTypeMapping{"rollback.triggered": dynatrace.AnnotationEventType},
+			wantEventType:   dynatrace.AnnotationEventType,
+			wantTitle:       "Rollback triggered in production",
+			wantDescription: "Rollback triggered in production for service carts",
+		},
+		{
+			name:       "events disabled for rollback",
+			event:      &fakeRollbackAdapter{EventData: test.EventData{Event: "sh.keptn.event.rollback.triggered"}},
+			eventTypes: dynatrace.EventTypeMapping{"rollback.triggered": dynatrace.NoEventType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Project = "sockshop"
+			tt.event.Stage = "production"
+			tt.event.Service = "carts"
+
+			recorder := newDynatraceEventsRecorder(t)
+			handler := NewRollbackEventHandler(tt.event, newTestDynatraceClient(t, recorder), &eventClientMock{}, nil, tt.eventTypes, env.ReadConfig())
+
+			assert.NoError(t, handler.HandleEvent())
+
+			if tt.wantEventType == "" {
+				assert.Empty(t, recorder.events)
+				return
+			}
+
+			events := recorder.eventsOfType(tt.wantEventType)
+			if assert.Len(t, events, 1) {
+				title, description := events[0]["title"], events[0]["description"]
+				if tt.wantEventType == dynatrace.AnnotationEventType {
+					title, description = events[0]["annotationType"], events[0]["annotationDescription"]
+				}
+				assert.Equal(t, tt.wantTitle, title)
+				assert.Equal(t, tt.wantDescription, description)
+				assert.Equal(t, "0.12.1", events[0]["customProperties"].(map[string]interface{})["Tag"])
+			}
+		})
+	}
+}
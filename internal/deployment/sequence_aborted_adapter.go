@@ -0,0 +1,120 @@
+package deployment
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// SequenceAbortedEventType and SequenceTimeoutEventType are the control events sent when a sequence was aborted or timed out
+const (
+	SequenceAbortedEventType = "sh.keptn.event.sequence.aborted"
+	SequenceTimeoutEventType = "sh.keptn.event.sequence.timeout"
+)
+
+type SequenceAbortedAdapterInterface interface {
+	adapter.EventContentAdapter
+
+	IsTimeout() bool
+	GetMessage() string
+}
+
+// SequenceAbortedAdapter is a content adaptor for events of type sh.keptn.event.sequence.aborted and sh.keptn.event.sequence.timeout
+type SequenceAbortedAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
+}
+
+// IsSequenceAbortedEventType returns whether the event type is sh.keptn.event.sequence.aborted or sh.keptn.event.sequence.timeout
+func IsSequenceAbortedEventType(eventType string) bool {
+	return eventType == SequenceAbortedEventType || eventType == SequenceTimeoutEventType
+}
+
+// NewSequenceAbortedAdapterFromEvent creates a new SequenceAbortedAdapter from a cloudevents Event
+func NewSequenceAbortedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*SequenceAbortedAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	saData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(saData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SequenceAbortedAdapter{
+		event:      *saData,
+		cloudEvent: ceAdapter,
+		cfg:        cfg,
+	}, nil
+}
+
+// IsTimeout returns whether the sequence timed out rather than being aborted
+func (a SequenceAbortedAdapter) IsTimeout() bool {
+	return a.cloudEvent.Type() == SequenceTimeoutEventType
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a SequenceAbortedAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a SequenceAbortedAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a SequenceAbortedAdapter) GetEvent() string {
+	return a.cloudEvent.Type()
+}
+
+// GetProject returns the project
+func (a SequenceAbortedAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a SequenceAbortedAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a SequenceAbortedAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a SequenceAbortedAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a SequenceAbortedAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a SequenceAbortedAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a SequenceAbortedAdapter) GetLabels() map[string]string {
+	labels := a.event.Labels
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if err == nil {
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+	}
+	return labels
+}
+
+// GetMessage returns the message of the event, e.g. the reason of the abort
+func (a SequenceAbortedAdapter) GetMessage() string {
+	return a.event.Message
+}
@@ -0,0 +1,72 @@
+package deployment
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	log "github.com/sirupsen/logrus"
+)
+
+type SequenceAbortedEventHandler struct {
+	event        SequenceAbortedAdapterInterface
+	dtClient     dynatrace.ClientInterface
+	eClient      keptn.EventClientInterface
+	attachRules  *dynatrace.AttachRules
+	eventTypes   dynatrace.EventTypeMapping
+	deduplicator *DeploymentEventDeduplicator
+	cfg          *env.Config
+}
+
+// NewSequenceAbortedEventHandler creates a new SequenceAbortedEventHandler
+func NewSequenceAbortedEventHandler(event SequenceAbortedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, deduplicator *DeploymentEventDeduplicator, cfg *env.Config) *SequenceAbortedEventHandler {
+	return &SequenceAbortedEventHandler{
+		event:        event,
+		dtClient:     dtClient,
+		eClient:      eClient,
+		attachRules:  attachRules,
+		eventTypes:   eventTypes,
+		deduplicator: deduplicator,
+		cfg:          cfg,
+	}
+}
+
+// HandleEvent handles a sequence aborted or timeout event by sending a Dynatrace annotation event and forgetting the deployment events
+// sent for the Keptn context, so that a deployment of a rerun is not suppressed
+func (eh *SequenceAbortedEventHandler) HandleEvent() error {
+	log.WithFields(
+		log.Fields{
+			"keptnContext": eh.event.GetShKeptnContext(),
+			"stage":        eh.event.GetStage(),
+			"timeout":      eh.event.IsTimeout(),
+		}).Info("Sending annotation event for aborted sequence")
+
+	eh.deduplicator.Forget(eh.event.GetShKeptnContext())
+
+	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+
+	ae := dynatrace.CreateAnnotationEventDTO(eh.event, imageAndTag, eh.attachRules)
+	if ae.AnnotationType == "" {
+		ae.AnnotationType = getSequenceAbortedTitle(eh.event)
+	}
+	if ae.AnnotationDescription == "" {
+		ae.AnnotationDescription = withBridgeLink(getSequenceAbortedDescription(eh.event), eh.event.GetLabels())
+	}
+
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddAnnotationEvent(ae)
+
+	return nil
+}
+
+func getSequenceAbortedTitle(event SequenceAbortedAdapterInterface) string {
+	if event.IsTimeout() {
+		return "Keptn sequence timed out in stage " + event.GetStage()
+	}
+	return "Keptn sequence aborted in stage " + event.GetStage()
+}
+
+func getSequenceAbortedDescription(event SequenceAbortedAdapterInterface) string {
+	if event.GetMessage() != "" {
+		return event.GetMessage()
+	}
+	return getSequenceAbortedTitle(event) + " for service " + event.GetService()
+}
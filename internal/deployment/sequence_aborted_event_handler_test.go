@@ -0,0 +1,119 @@
+package deployment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSequenceAbortedAdapter struct {
+	test.EventData
+	timeout bool
+	message string
+}
+
+func (a *fakeSequenceAbortedAdapter) IsTimeout() bool {
+	return a.timeout
+}
+
+func (a *fakeSequenceAbortedAdapter) GetMessage() string {
+	return a.message
+}
+
+func TestGetSequenceAbortedTitleAndDescription(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           *fakeSequenceAbortedAdapter
+		wantTitle       string
+		wantDescription string
+	}{
+		{
+			name:            "aborted",
+			event:           &fakeSequenceAbortedAdapter{},
+			wantTitle:       "Keptn sequence aborted in stage production",
+			wantDescription: "Keptn sequence aborted in stage production for service carts",
+		},
+		{
+			name:            "timed out with message",
+			event:           &fakeSequenceAbortedAdapter{timeout: true, message: "no task responded within 10m"},
+			wantTitle:       "Keptn sequence timed out in stage production",
+			wantDescription: "no task responded within 10m",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.EventData = test.EventData{Project: "sockshop", Stage: "production", Service: "carts"}
+
+			assert.Equal(t, tt.wantTitle, getSequenceAbortedTitle(tt.event))
+			assert.Equal(t, tt.wantDescription, getSequenceAbortedDescription(tt.event))
+		})
+	}
+}
+
+func TestSequenceAbortedEventHandler_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name            string
+		event           *fakeSequenceAbortedAdapter
+		eventTypes      dynatrace.EventTypeMapping
+		wantEvent       bool
+		wantTitle       string
+		wantDescription string
+	}{
+		{
+			name:            "aborted",
+			event:           &fakeSequenceAbortedAdapter{EventData: test.EventData{Event: "sh.keptn.event.sequence.aborted"}},
+			wantEvent:       true,
+			wantTitle:       "Keptn sequence aborted in stage production",
+			wantDescription: "Keptn sequence aborted in stage production for service carts - see https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+		},
+		{
+			name:            "timed out",
+			event:           &fakeSequenceAbortedAdapter{EventData: test.EventData{Event: "sh.keptn.event.sequence.timeout"}, timeout: true, message: "no task responded within 10m"},
+			wantEvent:       true,
+			wantTitle:       "Keptn sequence timed out in stage production",
+			wantDescription: "no task responded within 10m - see https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+		},
+		{
+			name:       "events disabled for aborted sequences",
+			event:      &fakeSequenceAbortedAdapter{EventData: test.EventData{Event: "sh.keptn.event.sequence.aborted"}},
+			eventTypes: dynatrace.EventTypeMapping{"sequence.aborted": dynatrace.NoEventType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.Context = "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"
+			tt.event.Project = "sockshop"
+			tt.event.Stage = "production"
+			tt.event.Service = "carts"
+			tt.event.Labels = map[string]string{common.KEPTNSBRIDGE_LABEL: "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"}
+
+			deduplicator := NewDeploymentEventDeduplicator(5 * time.Minute)
+			assert.False(t, deduplicator.IsDuplicate(tt.event.Context, "production", "carts"))
+			deduplicator.MarkSent(tt.event.Context, "production", "carts")
+
+			recorder := newDynatraceEventsRecorder(t)
+			handler := NewSequenceAbortedEventHandler(tt.event, newTestDynatraceClient(t, recorder), &eventClientMock{}, nil, tt.eventTypes, deduplicator, env.ReadConfig())
+
+			assert.NoError(t, handler.HandleEvent())
+
+			assert.False(t, deduplicator.IsDuplicate(tt.event.Context, "production", "carts"), "deployment of a rerun is not suppressed")
+
+			if !tt.wantEvent {
+				assert.Empty(t, recorder.events)
+				return
+			}
+
+			events := recorder.eventsOfType(dynatrace.AnnotationEventType)
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, tt.wantTitle, events[0]["annotationType"])
+				assert.Equal(t, tt.wantDescription, events[0]["annotationDescription"])
+				assert.Equal(t, tt.event.Context, events[0]["customProperties"].(map[string]interface{})["KeptnContext"])
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package deployment
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type SequenceFinishedAdapterInterface interface {
+	adapter.EventContentAdapter
+
+	GetSequence() string
+	GetResult() keptnv2.ResultType
+	GetStatus() keptnv2.StatusType
+	GetMessage() string
+}
+
+// SequenceFinishedAdapter is a content adaptor for events of type sh.keptn.event.<stage>.<sequence>.finished
+type SequenceFinishedAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+	sequence   string
+	cfg        *env.Config
+}
+
+// IsSequenceFinishedEventType returns whether the event type is a sh.keptn.event.<stage>.<sequence>.finished sequence event type
+func IsSequenceFinishedEventType(eventType string) bool {
+	_, _, kind, err := keptnv2.ParseSequenceEventType(eventType)
+	if err != nil {
+		return false
+	}
+
+	return kind == "finished"
+}
+
+// NewSequenceFinishedAdapterFromEvent creates a new SequenceFinishedAdapter from a cloudevents Event
+func NewSequenceFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*SequenceFinishedAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	sfData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(sfData)
+	if err != nil {
+		return nil, err
+	}
+
+	_, sequence, _, err := keptnv2.ParseSequenceEventType(e.Type())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SequenceFinishedAdapter{
+		event:      *sfData,
+		cloudEvent: ceAdapter,
+		sequence:   sequence,
+		cfg:        cfg,
+	}, nil
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a SequenceFinishedAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a SequenceFinishedAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a SequenceFinishedAdapter) GetEvent() string {
+	return keptnv2.GetFinishedEventType(a.GetStage() + "." + a.sequence)
+}
+
+// GetProject returns the project
+func (a SequenceFinishedAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a SequenceFinishedAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a SequenceFinishedAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a SequenceFinishedAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a SequenceFinishedAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a SequenceFinishedAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a SequenceFinishedAdapter) GetLabels() map[string]string {
+	labels := a.event.Labels
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if err == nil {
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+	}
+	return labels
+}
+
+// GetSequence returns the name of the sequence
+func (a SequenceFinishedAdapter) GetSequence() string {
+	return a.sequence
+}
+
+func (a SequenceFinishedAdapter) GetResult() keptnv2.ResultType {
+	return a.event.Result
+}
+
+func (a SequenceFinishedAdapter) GetStatus() keptnv2.StatusType {
+	return a.event.Status
+}
+
+func (a SequenceFinishedAdapter) GetMessage() string {
+	return a.event.Message
+}
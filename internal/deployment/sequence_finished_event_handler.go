@@ -0,0 +1,55 @@
+package deployment
+
+import (
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+type SequenceFinishedEventHandler struct {
+	event       SequenceFinishedAdapterInterface
+	dtClient    dynatrace.ClientInterface
+	eClient     keptn.EventClientInterface
+	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+
+	failureEvents *failureEventSender
+}
+
+// NewSequenceFinishedEventHandler creates a new SequenceFinishedEventHandler
+func NewSequenceFinishedEventHandler(event SequenceFinishedAdapterInterface, client dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *SequenceFinishedEventHandler {
+	return &SequenceFinishedEventHandler{
+		event:       event,
+		dtClient:    client,
+		eClient:     eClient,
+		attachRules: attachRules,
+		eventTypes:  eventTypes,
+
+		failureEvents: newFailureEventSender(client, attachRules, eventTypes, cfg),
+	}
+}
+
+// HandleEvent handles a sequence finished event by sending a Dynatrace failure event if the sequence errored
+func (eh *SequenceFinishedEventHandler) HandleEvent() error {
+	if !eh.failureEvents.isEnabled() || eh.event.GetStatus() != keptnv2.StatusErrored {
+		return nil
+	}
+
+	log.WithFields(
+		log.Fields{
+			"sequence": eh.event.GetSequence(),
+			"stage":    eh.event.GetStage(),
+		}).Info("Sending failure event for errored sequence")
+
+	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+
+	eh.failureEvents.send(eh.event, imageAndTag,
+		fmt.Sprintf("Keptn sequence %s errored in stage %s", eh.event.GetSequence(), eh.event.GetStage()),
+		fmt.Sprintf("Sequence %s finished with status %s: %s", eh.event.GetSequence(), eh.event.GetStatus(), eh.event.GetMessage()))
+
+	return nil
+}
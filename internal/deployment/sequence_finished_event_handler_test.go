@@ -0,0 +1,179 @@
+package deployment
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+type eventClientMock struct {
+	isPartOfRemediation bool
+	problemID           string
+}
+
+func (m *eventClientMock) IsPartOfRemediation(event adapter.EventContentAdapter) (bool, error) {
+	return m.isPartOfRemediation, nil
+}
+
+func (m *eventClientMock) FindProblemID(keptnEvent adapter.EventContentAdapter) (string, error) {
+	return m.problemID, nil
+}
+
+func (m *eventClientMock) GetImageAndTag(keptnEvent adapter.EventContentAdapter) common.ImageAndTag {
+	return common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1")
+}
+
+func (m *eventClientMock) FindTriggeredEventTime(keptnEvent adapter.EventContentAdapter, taskName string, triggeredID string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+// dynatraceEventsRecorder records the Dynatrace events sent to the events endpoint and responds with an empty JSON object to all requests
+type dynatraceEventsRecorder struct {
+	t      *testing.T
+	events []map[string]interface{}
+}
+
+func newDynatraceEventsRecorder(t *testing.T) *dynatraceEventsRecorder {
+	return &dynatraceEventsRecorder{t: t}
+}
+
+func (r *dynatraceEventsRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodPost && req.URL.Path == "/api/v1/events" {
+		body, err := ioutil.ReadAll(req.Body)
+		assert.NoError(r.t, err)
+
+		event := map[string]interface{}{}
+		assert.NoError(r.t, json.Unmarshal(body, &event))
+		r.events = append(r.events, event)
+	}
+	w.Write([]byte(`{}`))
+}
+
+// eventsOfType returns the recorded events of the Dynatrace event type
+func (r *dynatraceEventsRecorder) eventsOfType(eventType string) []map[string]interface{} {
+	var events []map[string]interface{}
+	for _, event := range r.events {
+		if event["eventType"] == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// newTestDynatraceClient returns a Dynatrace client sending all requests to the handler
+func newTestDynatraceClient(t *testing.T, handler http.Handler) dynatrace.ClientInterface {
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	t.Cleanup(teardown)
+
+	return dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+}
+
+type sequenceFinishedEventData struct {
+	test.EventData
+	sequence string
+	status   keptnv2.StatusType
+	message  string
+}
+
+func (e *sequenceFinishedEventData) GetSequence() string {
+	return e.sequence
+}
+
+func (e *sequenceFinishedEventData) GetResult() keptnv2.ResultType {
+	return keptnv2.ResultFailed
+}
+
+func (e *sequenceFinishedEventData) GetStatus() keptnv2.StatusType {
+	return e.status
+}
+
+func (e *sequenceFinishedEventData) GetMessage() string {
+	return e.message
+}
+
+func newTestSequenceFinishedEventData(status keptnv2.StatusType) *sequenceFinishedEventData {
+	return &sequenceFinishedEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Event:   "sh.keptn.event.production.delivery.finished",
+			Project: "sockshop",
+			Stage:   "production",
+			Service: "carts",
+			Labels:  map[string]string{common.KEPTNSBRIDGE_LABEL: "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"},
+		},
+		sequence: "delivery",
+		status:   status,
+		message:  "helm upgrade failed",
+	}
+}
+
+func TestSequenceFinishedEventHandler_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name             string
+		status           keptnv2.StatusType
+		failureEventType string
+		eventTypes       dynatrace.EventTypeMapping
+		wantEvent        bool
+	}{
+		{
+			name:             "errored sequence",
+			status:           keptnv2.StatusErrored,
+			failureEventType: dynatrace.ErrorEventType,
+			wantEvent:        true,
+		},
+		{
+			name:             "succeeded sequence",
+			status:           keptnv2.StatusSucceeded,
+			failureEventType: dynatrace.ErrorEventType,
+		},
+		{
+			name:   "failure events disabled",
+			status: keptnv2.StatusErrored,
+		},
+		{
+			name:             "events disabled for sequence",
+			status:           keptnv2.StatusErrored,
+			failureEventType: dynatrace.ErrorEventType,
+			eventTypes:       dynatrace.EventTypeMapping{"production.delivery.finished": dynatrace.NoEventType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newDynatraceEventsRecorder(t)
+			dtClient := newTestDynatraceClient(t, recorder)
+
+			handler := &SequenceFinishedEventHandler{
+				event:         newTestSequenceFinishedEventData(tt.status),
+				dtClient:      dtClient,
+				eClient:       &eventClientMock{},
+				failureEvents: &failureEventSender{dtClient: dtClient, eventTypes: tt.eventTypes, cfg: env.ReadConfig(), eventType: tt.failureEventType},
+			}
+			assert.NoError(t, handler.HandleEvent())
+
+			if !tt.wantEvent {
+				assert.Empty(t, recorder.events)
+				return
+			}
+
+			if assert.Len(t, recorder.events, 1) {
+				event := recorder.events[0]
+				assert.Equal(t, dynatrace.ErrorEventType, event["eventType"])
+				assert.Equal(t, "Keptn sequence delivery errored in stage production", event["title"])
+				assert.Equal(t, "Sequence delivery finished with status errored: helm upgrade failed - see https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", event["description"])
+				assert.Equal(t, "0.12.1", event["customProperties"].(map[string]interface{})["Tag"])
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package deployment
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// TestStartedEventData is the payload of the test.started event sent by the dynatrace-service
+type TestStartedEventData struct {
+	keptnv2.EventData
+
+	// DynatraceTestHeader contains the x-dynatrace-test header values load testing tools should send with their requests
+	DynatraceTestHeader common.DynatraceTestHeader `json:"dynatraceTestHeader"`
+}
+
+type TestStartedEventFactory struct {
+	event TestTriggeredAdapterInterface
+}
+
+func NewTestStartedEventFactory(event TestTriggeredAdapterInterface) *TestStartedEventFactory {
+	return &TestStartedEventFactory{
+		event: event,
+	}
+}
+
+func (f *TestStartedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	testStartedEvent := TestStartedEventData{
+		EventData: keptnv2.EventData{
+			Project: f.event.GetProject(),
+			Stage:   f.event.GetStage(),
+			Service: f.event.GetService(),
+			Labels:  f.event.GetLabels(),
+			Status:  keptnv2.StatusSucceeded,
+		},
+		DynatraceTestHeader: common.NewDynatraceTestHeader(f.event),
+	}
+
+	return adapter.NewCloudEventFactory(f.event, keptnv2.GetStartedEventType(keptnv2.TestTaskName), testStartedEvent).CreateCloudEvent()
+}
+
+type TestFinishedEventFactory struct {
+	event TestFinishedAdapterInterface
+}
+
+func NewTestFinishedEventFactory(event TestFinishedAdapterInterface) *TestFinishedEventFactory {
+	return &TestFinishedEventFactory{
+		event: event,
+	}
+}
+
+func (f *TestFinishedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	testFinishedEvent := keptnv2.EventData{
+		Project: f.event.GetProject(),
+		Stage:   f.event.GetStage(),
+		Service: f.event.GetService(),
+		Labels:  f.event.GetLabels(),
+		Status:  keptnv2.StatusSucceeded,
+		Result:  keptnv2.ResultPass,
+	}
+
+	return adapter.NewCloudEventFactory(f.event, keptnv2.GetFinishedEventType(keptnv2.TestTaskName), testFinishedEvent).CreateCloudEvent()
+}
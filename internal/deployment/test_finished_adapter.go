@@ -1,25 +1,50 @@
 package deployment
 
 import (
+	"strconv"
+	"strings"
+	"time"
+
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
 )
 
 type TestFinishedAdapterInterface interface {
 	adapter.EventContentAdapter
+	adapter.TriggeredCloudEventContentAdapter
+
+	GetResult() keptnv2.ResultType
+	GetTestStart() (time.Time, bool)
+	GetTestEnd() (time.Time, bool)
+	GetTestMetrics() map[string]float64
+}
+
+// testMetricPrefix is the prefix of labels of test.finished events containing numeric test result metrics, e.g. keptn.test.response_time_p95,
+// and of the keys of the ingested test metrics
+const testMetricPrefix = "keptn.test."
+
+// testMetricsData contains the test result metrics a testing tool may add to the test details of a test.finished event
+type testMetricsData struct {
+	Test struct {
+		Metrics map[string]interface{} `json:"metrics"`
+	} `json:"test"`
 }
 
 // TestFinishedAdapter is a content adaptor for events of type sh.keptn.event.test.finished
 type TestFinishedAdapter struct {
 	event      keptnv2.TestFinishedEventData
+	metrics    map[string]float64
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewTestFinishedAdapterFromEvent creates a new TestFinishedAdapter from a cloudevents Event
-func NewTestFinishedAdapterFromEvent(e cloudevents.Event) (*TestFinishedAdapter, error) {
+func NewTestFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*TestFinishedAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	tfData := &keptnv2.TestFinishedEventData{}
@@ -30,10 +55,32 @@ func NewTestFinishedAdapterFromEvent(e cloudevents.Event) (*TestFinishedAdapter,
 
 	return &TestFinishedAdapter{
 		event:      *tfData,
+		metrics:    getNumericTestMetrics(ceAdapter),
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
+// getNumericTestMetrics returns the numeric values of data.test.metrics. Metrics are optional, so a payload without them is not an error,
+// and values that are not numbers, e.g. a textual summary, are skipped
+func getNumericTestMetrics(ceAdapter adapter.CloudEventAdapter) map[string]float64 {
+	metricsData := &testMetricsData{}
+	if err := ceAdapter.PayloadAs(metricsData); err != nil {
+		return nil
+	}
+
+	metrics := make(map[string]float64, len(metricsData.Test.Metrics))
+	for name, value := range metricsData.Test.Metrics {
+		number, ok := value.(float64)
+		if !ok {
+			log.WithField("metric", name).Debug("Ignoring test metric that is not a number")
+			continue
+		}
+		metrics[name] = number
+	}
+	return metrics
+}
+
 // GetShKeptnContext returns the shkeptncontext
 func (a TestFinishedAdapter) GetShKeptnContext() string {
 	return a.cloudEvent.ShKeptnContext()
@@ -82,7 +129,7 @@ func (a TestFinishedAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a TestFinishedAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
@@ -91,3 +138,53 @@ func (a TestFinishedAdapter) GetLabels() map[string]string {
 	}
 	return labels
 }
+
+// GetEventID returns the ID of the test.triggered event this event refers to
+func (a TestFinishedAdapter) GetEventID() string {
+	return a.cloudEvent.TriggeredID()
+}
+
+// GetResult returns the result of the tests
+func (a TestFinishedAdapter) GetResult() keptnv2.ResultType {
+	return a.event.Result
+}
+
+// GetTestStart returns the time the tests started or false if it is not specified as RFC3339 timestamp
+func (a TestFinishedAdapter) GetTestStart() (time.Time, bool) {
+	return parseTestTimestamp(a.event.Test.Start)
+}
+
+// GetTestEnd returns the time the tests ended or false if it is not specified as RFC3339 timestamp
+func (a TestFinishedAdapter) GetTestEnd() (time.Time, bool) {
+	return parseTestTimestamp(a.event.Test.End)
+}
+
+// GetTestMetrics returns the test result metrics contained in data.test.metrics and in numeric labels prefixed with keptn.test.
+// Labels take precedence over metrics of the same name in the test details
+func (a TestFinishedAdapter) GetTestMetrics() map[string]float64 {
+	metrics := make(map[string]float64, len(a.metrics))
+	for name, value := range a.metrics {
+		metrics[name] = value
+	}
+
+	for key, value := range a.event.Labels {
+		if !strings.HasPrefix(key, testMetricPrefix) {
+			continue
+		}
+
+		number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			continue
+		}
+		metrics[strings.TrimPrefix(key, testMetricPrefix)] = number
+	}
+	return metrics
+}
+
+func parseTestTimestamp(timestamp string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
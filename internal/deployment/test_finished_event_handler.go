@@ -1,29 +1,58 @@
 package deployment
 
 import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/event"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	log "github.com/sirupsen/logrus"
 )
 
 type TestFinishedEventHandler struct {
 	event       TestFinishedAdapterInterface
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
+	kClient     keptn.ClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+
+	ingestMetrics bool
+	// participateInTest defines whether the dynatrace-service sent a test.started event and must therefore finish the test as well
+	participateInTest bool
+	// sendTestWindowEvents defines whether a CUSTOM_INFO event marking the stop of the test window is sent
+	sendTestWindowEvents bool
 }
 
 // NewTestFinishedEventHandler creates a new TestFinishedEventHandler
-func NewTestFinishedEventHandler(event TestFinishedAdapterInterface, client dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *TestFinishedEventHandler {
+func NewTestFinishedEventHandler(event TestFinishedAdapterInterface, client dynatrace.ClientInterface, eClient keptn.EventClientInterface, kClient keptn.ClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *TestFinishedEventHandler {
 	return &TestFinishedEventHandler{
 		event:       event,
 		dtClient:    client,
 		eClient:     eClient,
+		kClient:     kClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+
+		ingestMetrics:        cfg.TestMetricsIngestEnabled,
+		participateInTest:    cfg.TestParticipationEnabled,
+		sendTestWindowEvents: cfg.TestWindowEventsEnabled,
 	}
 }
 
 // HandleEvent handles an action finished event
 func (eh *TestFinishedEventHandler) HandleEvent() error {
+	// ignore the test.finished events sent by the dynatrace-service itself
+	if eh.event.GetSource() == event.GetEventSource(eh.cfg) {
+		return nil
+	}
 
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
 
@@ -35,7 +64,100 @@ func (eh *TestFinishedEventHandler) HandleEvent() error {
 		ae.AnnotationDescription = "Stop running tests: against " + eh.event.GetService()
 	}
 
-	dynatrace.NewEventsClient(eh.dtClient).AddAnnotationEvent(ae)
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddAnnotationEvent(ae)
+
+	if eh.sendTestWindowEvents {
+		dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(createTestWindowStopEvent(eh.event, imageAndTag, eh.attachRules))
+	}
+
+	if eh.ingestMetrics {
+		err := dynatrace.NewMetricsIngestClient(eh.dtClient).IngestMetrics(createTestMetricLines(eh.event))
+		if err != nil {
+			log.WithError(err).Error("Could not ingest test metrics")
+		}
+	}
+
+	if eh.participateInTest {
+		err := eh.kClient.SendCloudEvent(NewTestFinishedEventFactory(eh.event))
+		if err != nil {
+			log.WithError(err).Error("Failed to send test.finished event")
+			return err
+		}
+	}
 
 	return nil
 }
+
+// createTestWindowStopEvent creates the CUSTOM_INFO event marking the stop of the test window, including the start and end of the tests if they are known
+func createTestWindowStopEvent(event TestFinishedAdapterInterface, imageAndTag common.ImageAndTag, attachRules *dynatrace.AttachRules) dynatrace.InfoEvent {
+	ie := createTestWindowEvent(event, imageAndTag, attachRules, testWindowStop)
+	if start, ok := event.GetTestStart(); ok {
+		ie.CustomProperties["Test Start"] = start.UTC().Format(time.RFC3339)
+	}
+	if end, ok := event.GetTestEnd(); ok {
+		ie.CustomProperties["Test End"] = end.UTC().Format(time.RFC3339)
+	}
+	return ie
+}
+
+// invalidMetricKeyCharacters matches characters that are not allowed in Dynatrace metric keys
+var invalidMetricKeyCharacters = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// createTestMetricLines creates the keptn.test.duration metric, if the start and end of the tests are known, and a keptn.test.<name>
+// metric for every test result metric of the event
+func createTestMetricLines(event TestFinishedAdapterInterface) []dynatrace.MetricLine {
+	dimensions := map[string]string{
+		"keptn_project": event.GetProject(),
+		"keptn_stage":   event.GetStage(),
+		"keptn_service": event.GetService(),
+		"result":        string(event.GetResult()),
+	}
+
+	var lines []dynatrace.MetricLine
+	start, hasStart := event.GetTestStart()
+	end, hasEnd := event.GetTestEnd()
+	if hasStart && hasEnd && !end.Before(start) {
+		lines = append(lines, dynatrace.MetricLine{
+			MetricKey:  testMetricPrefix + "duration",
+			Dimensions: dimensions,
+			Value:      end.Sub(start).Seconds(),
+		})
+	}
+
+	metrics := event.GetTestMetrics()
+	names := make([]string, 0, len(metrics))
+	for name := range metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		metricName := getTestMetricName(name)
+		if metricName == "" {
+			log.WithField("metric", name).Warn("Ignoring test metric with invalid name")
+			continue
+		}
+
+		lines = append(lines, dynatrace.MetricLine{
+			MetricKey:  testMetricPrefix + metricName,
+			Dimensions: dimensions,
+			Value:      metrics[name],
+		})
+	}
+
+	return lines
+}
+
+// getTestMetricName converts the name of a test result metric into a valid part of a Dynatrace metric key, e.g. "Response Time (p95)" into "response_time_p95"
+func getTestMetricName(name string) string {
+	sanitized := invalidMetricKeyCharacters.ReplaceAllString(strings.ToLower(strings.TrimSpace(name)), "_")
+
+	var sections []string
+	for _, section := range strings.Split(sanitized, ".") {
+		section = strings.Trim(section, "_")
+		if section != "" {
+			sections = append(sections, section)
+		}
+	}
+	return strings.Join(sections, ".")
+}
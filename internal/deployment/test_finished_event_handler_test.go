@@ -0,0 +1,247 @@
+package deployment
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/event"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFinishedAdapter(t *testing.T, payload string) *TestFinishedAdapter {
+	e := cloudevents.NewEvent()
+	e.SetType(keptnv2.GetFinishedEventType(keptnv2.TestTaskName))
+	e.SetSource("jmeter-service")
+	err := e.SetData(cloudevents.ApplicationJSON, []byte(payload))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	adapter, err := NewTestFinishedAdapterFromEvent(e, env.ReadConfig())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return adapter
+}
+
+func TestCreateTestMetricLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		payload       string
+		expectedLines []string
+	}{
+		{
+			name: "duration and metrics from test details and labels",
+			payload: `{
+				"project": "sockshop", "stage": "staging", "service": "carts", "result": "pass",
+				"labels": {"keptn.test.Error Rate (%)": "0.5", "keptn.test.throughput": "120", "buildId": "42", "keptn.test.comment": "stable"},
+				"test": {"start": "2021-03-24T12:00:00Z", "end": "2021-03-24T12:05:30Z", "metrics": {"response_time_p95": 312.5, "throughput": 100}}
+			}`,
+			expectedLines: []string{
+				`keptn.test.duration,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,330`,
+				`keptn.test.error_rate,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,0.5`,
+				`keptn.test.response_time_p95,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,312.5`,
+				`keptn.test.throughput,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,120`,
+			},
+		},
+		{
+			name: "no duration without valid timestamps",
+			payload: `{
+				"project": "sockshop", "stage": "staging", "service": "carts", "result": "fail",
+				"test": {"start": "2021-03-24T12:00:00Z", "end": "", "metrics": {"requests.failed": 7}}
+			}`,
+			expectedLines: []string{
+				`keptn.test.requests.failed,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="fail" gauge,7`,
+			},
+		},
+		{
+			name: "metrics that are not numbers are ignored",
+			payload: `{
+				"project": "sockshop", "stage": "staging", "service": "carts", "result": "pass",
+				"test": {"start": "2021-03-24T12:00:00Z", "end": "2021-03-24T12:01:00Z", "metrics": {"summary": "ok"}}
+			}`,
+			expectedLines: []string{
+				`keptn.test.duration,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,60`,
+			},
+		},
+		{
+			name: "numeric metrics are kept if others are not numbers",
+			payload: `{
+				"project": "sockshop", "stage": "staging", "service": "carts", "result": "pass",
+				"test": {"metrics": {"summary": "ok", "errors": null, "samples": {"count": 10}, "response_time_p95": 312.5}}
+			}`,
+			expectedLines: []string{
+				`keptn.test.response_time_p95,keptn_project="sockshop",keptn_service="carts",keptn_stage="staging",result="pass" gauge,312.5`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := createTestMetricLines(newTestFinishedAdapter(t, tt.payload))
+
+			actualLines := make([]string, len(lines))
+			for i, line := range lines {
+				actualLines[i] = line.String()
+			}
+			assert.Equal(t, strings.Join(tt.expectedLines, "\n"), strings.Join(actualLines, "\n"))
+		})
+	}
+}
+
+func TestGetTestMetricName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "response_time_p95", want: "response_time_p95"},
+		{name: "Response Time (p95)", want: "response_time_p95"},
+		{name: "requests..failed.", want: "requests.failed"},
+		{name: "%", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, getTestMetricName(tt.name))
+		})
+	}
+}
+
+func TestCreateTestWindowEvents(t *testing.T) {
+	e := cloudevents.NewEvent()
+	e.SetType(keptnv2.GetFinishedEventType(keptnv2.TestTaskName))
+	e.SetSource("jmeter-service")
+	e.SetExtension("shkeptncontext", "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+	err := e.SetData(cloudevents.ApplicationJSON, []byte(`{
+		"project": "sockshop", "stage": "staging", "service": "carts", "result": "pass",
+		"test": {"start": "2021-03-24T12:00:00Z", "end": "2021-03-24T12:05:30Z"}
+	}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	finishedAdapter, err := NewTestFinishedAdapterFromEvent(e, env.ReadConfig())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	startEvent := createTestWindowEvent(&test.EventData{Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", Project: "sockshop", Stage: "staging", Service: "carts"}, common.NewNotAvailableImageAndTag(), nil, testWindowStart)
+	stopEvent := createTestWindowStopEvent(finishedAdapter, common.NewNotAvailableImageAndTag(), nil)
+
+	assert.Equal(t, dynatrace.InfoEventType, startEvent.EventType)
+	assert.Equal(t, dynatrace.InfoEventType, stopEvent.EventType)
+	assert.Equal(t, "start", startEvent.CustomProperties[testWindowProperty])
+	assert.Equal(t, "stop", stopEvent.CustomProperties[testWindowProperty])
+	assert.Equal(t, "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", startEvent.CustomProperties[testWindowIDProperty])
+	assert.Equal(t, startEvent.CustomProperties[testWindowIDProperty], stopEvent.CustomProperties[testWindowIDProperty])
+	assert.Equal(t, "2021-03-24T12:00:00Z", stopEvent.CustomProperties["Test Start"])
+	assert.Equal(t, "2021-03-24T12:05:30Z", stopEvent.CustomProperties["Test End"])
+}
+
+func TestTestFinishedEventHandler_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name                   string
+		source                 string
+		sendTestWindowEvents   bool
+		ingestMetrics          bool
+		participateInTest      bool
+		eventTypes             dynatrace.EventTypeMapping
+		wantAnnotationEvents   int
+		wantTestWindowEvents   int
+		wantIngestRequests     int
+		wantTestFinishedEvents int
+	}{
+		{
+			name:                 "only annotation",
+			wantAnnotationEvents: 1,
+		},
+		{
+			name:                 "test window stop",
+			sendTestWindowEvents: true,
+			wantAnnotationEvents: 1,
+			wantTestWindowEvents: 1,
+		},
+		{
+			name:                 "test window stop disabled for test.finished",
+			sendTestWindowEvents: true,
+			eventTypes:           dynatrace.EventTypeMapping{"test.finished": dynatrace.NoEventType},
+		},
+		{
+			name:                 "metrics ingest",
+			ingestMetrics:        true,
+			wantAnnotationEvents: 1,
+			wantIngestRequests:   1,
+		},
+		{
+			name:                   "participation in test",
+			participateInTest:      true,
+			wantAnnotationEvents:   1,
+			wantTestFinishedEvents: 1,
+		},
+		{
+			name:                 "own test.finished events are ignored",
+			source:               event.GetEventSource(env.ReadConfig()),
+			sendTestWindowEvents: true,
+			ingestMetrics:        true,
+			participateInTest:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := cloudevents.NewEvent()
+			e.SetID("c4d5a8a0-5e2b-4a3e-8d9f-3f0e7b6c1a2d")
+			e.SetType(keptnv2.GetFinishedEventType(keptnv2.TestTaskName))
+			e.SetSource("jmeter-service")
+			if tt.source != "" {
+				e.SetSource(tt.source)
+			}
+			e.SetExtension("shkeptncontext", "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+			e.SetExtension("triggeredid", "b9a1e3f2-6c7d-4e8f-9a0b-1c2d3e4f5a6b")
+			err := e.SetData(cloudevents.ApplicationJSON, []byte(`{
+				"project": "sockshop", "stage": "staging", "service": "carts", "result": "pass",
+				"test": {"start": "2021-03-24T12:00:00Z", "end": "2021-03-24T12:05:30Z"}
+			}`))
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+			finishedAdapter, err := NewTestFinishedAdapterFromEvent(e, env.ReadConfig())
+			if !assert.NoError(t, err) {
+				t.FailNow()
+			}
+
+			recorder := newDynatraceEventsRecorder(t)
+			ingestRequests := 0
+			dtHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v2/metrics/ingest" {
+					ingestRequests++
+				}
+				recorder.ServeHTTP(w, r)
+			})
+			kClient := &keptnClientMock{}
+			handler := NewTestFinishedEventHandler(finishedAdapter, newTestDynatraceClient(t, dtHandler), &eventClientMock{}, kClient, nil, tt.eventTypes, env.ReadConfig())
+			handler.sendTestWindowEvents = tt.sendTestWindowEvents
+			handler.ingestMetrics = tt.ingestMetrics
+			handler.participateInTest = tt.participateInTest
+
+			assert.NoError(t, handler.HandleEvent())
+
+			assert.Len(t, recorder.eventsOfType(dynatrace.AnnotationEventType), tt.wantAnnotationEvents)
+			testWindowEvents := recorder.eventsOfType(dynatrace.InfoEventType)
+			if assert.Len(t, testWindowEvents, tt.wantTestWindowEvents) && tt.wantTestWindowEvents > 0 {
+				customProperties := testWindowEvents[0]["customProperties"].(map[string]interface{})
+				assert.Equal(t, testWindowStop, customProperties[testWindowProperty])
+				assert.Equal(t, "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", customProperties[testWindowIDProperty])
+			}
+			assert.Equal(t, tt.wantIngestRequests, ingestRequests)
+
+			assert.Len(t, kClient.eventSink, tt.wantTestFinishedEvents)
+			for _, ce := range kClient.eventSink {
+				assert.Equal(t, keptnv2.GetFinishedEventType(keptnv2.TestTaskName), ce.Type())
+			}
+		})
+	}
+}
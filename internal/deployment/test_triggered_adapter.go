@@ -4,21 +4,24 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
 type TestTriggeredAdapterInterface interface {
 	adapter.EventContentAdapter
+	adapter.TriggeredCloudEventContentAdapter
 }
 
 // TestTriggeredAdapter is a content adaptor for events of type sh.keptn.event.test.triggered
 type TestTriggeredAdapter struct {
 	event      keptnv2.TestTriggeredEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewTestTriggeredAdapterFromEvent creates a new TestTriggeredAdapter from a cloudevents Event
-func NewTestTriggeredAdapterFromEvent(e cloudevents.Event) (*TestTriggeredAdapter, error) {
+func NewTestTriggeredAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*TestTriggeredAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	ttData := &keptnv2.TestTriggeredEventData{}
@@ -30,6 +33,7 @@ func NewTestTriggeredAdapterFromEvent(e cloudevents.Event) (*TestTriggeredAdapte
 	return &TestTriggeredAdapter{
 		event:      *ttData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -45,7 +49,7 @@ func (a TestTriggeredAdapter) GetSource() string {
 
 // GetEvent returns the event type
 func (a TestTriggeredAdapter) GetEvent() string {
-	return keptnv2.GetFinishedEventType(keptnv2.TestTaskName)
+	return keptnv2.GetTriggeredEventType(keptnv2.TestTaskName)
 }
 
 // GetProject returns the project
@@ -81,7 +85,7 @@ func (a TestTriggeredAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a TestTriggeredAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
@@ -90,3 +94,8 @@ func (a TestTriggeredAdapter) GetLabels() map[string]string {
 	}
 	return labels
 }
+
+// GetEventID returns the ID of the test.triggered event
+func (a TestTriggeredAdapter) GetEventID() string {
+	return a.cloudEvent.ID()
+}
@@ -1,29 +1,53 @@
 package deployment
 
 import (
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	log "github.com/sirupsen/logrus"
 )
 
 type TestTriggeredEventHandler struct {
 	event       TestTriggeredAdapterInterface
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
+	kClient     keptn.ClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+
+	// participateInTest defines whether a test.started event with the x-dynatrace-test header values is sent
+	participateInTest bool
+	// sendTestWindowEvents defines whether a CUSTOM_INFO event marking the start of the test window is sent
+	sendTestWindowEvents bool
 }
 
 // NewTestTriggeredEventHandler creates a new TestTriggeredEventHandler
-func NewTestTriggeredEventHandler(event TestTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *TestTriggeredEventHandler {
+func NewTestTriggeredEventHandler(event TestTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, kClient keptn.ClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *TestTriggeredEventHandler {
 	return &TestTriggeredEventHandler{
 		event:       event,
 		dtClient:    dtClient,
 		eClient:     eClient,
+		kClient:     kClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+
+		participateInTest:    cfg.TestParticipationEnabled,
+		sendTestWindowEvents: cfg.TestWindowEventsEnabled,
 	}
 }
 
 // HandleEvent handles an action finished event
 func (eh *TestTriggeredEventHandler) HandleEvent() error {
+	if eh.participateInTest {
+		err := eh.kClient.SendCloudEvent(NewTestStartedEventFactory(eh.event))
+		if err != nil {
+			log.WithError(err).Error("Failed to send test.started event")
+			return err
+		}
+	}
 
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
 
@@ -35,8 +59,14 @@ func (eh *TestTriggeredEventHandler) HandleEvent() error {
 	if ie.AnnotationDescription == "" {
 		ie.AnnotationDescription = "Start running tests: " + eh.event.GetTestStrategy() + " against " + eh.event.GetService()
 	}
+	ie.CustomProperties[common.DynatraceTestHeaderName] = common.NewDynatraceTestHeader(eh.event).Header
 
-	dynatrace.NewEventsClient(eh.dtClient).AddAnnotationEvent(ie)
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddAnnotationEvent(ie)
+
+	if eh.sendTestWindowEvents {
+		// the test window events are not mapped to other event types, as Dynatrace expects a pair of CUSTOM_INFO events
+		dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(createTestWindowEvent(eh.event, imageAndTag, eh.attachRules, testWindowStart))
+	}
 
 	return nil
 }
@@ -0,0 +1,103 @@
+package deployment
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+// keptnClientMock records the events sent to Keptn
+type keptnClientMock struct {
+	eventSink []*cloudevents.Event
+}
+
+func (m *keptnClientMock) GetCustomQueries(project string, stage string, service string) (*keptn.CustomQueries, error) {
+	panic("GetCustomQueries() should not be needed in this mock!")
+}
+
+func (m *keptnClientMock) SendCloudEvent(factory adapter.CloudEventFactoryInterface) error {
+	ce, err := factory.CreateCloudEvent()
+	if err != nil {
+		return err
+	}
+
+	m.eventSink = append(m.eventSink, ce)
+	return nil
+}
+
+func newTestTriggeredAdapter(t *testing.T) *TestTriggeredAdapter {
+	e := cloudevents.NewEvent()
+	e.SetID("c4d5a8a0-5e2b-4a3e-8d9f-3f0e7b6c1a2d")
+	e.SetType(keptnv2.GetTriggeredEventType(keptnv2.TestTaskName))
+	e.SetSource("shipyard-controller")
+	e.SetExtension("shkeptncontext", "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+	err := e.SetData(cloudevents.ApplicationJSON, []byte(`{"project": "sockshop", "stage": "staging", "service": "carts", "test": {"teststrategy": "performance"}}`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	adapter, err := NewTestTriggeredAdapterFromEvent(e, env.ReadConfig())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return adapter
+}
+
+func TestTestTriggeredEventHandler_HandleEvent(t *testing.T) {
+	tests := []struct {
+		name                  string
+		sendTestWindowEvents  bool
+		participateInTest     bool
+		eventTypes            dynatrace.EventTypeMapping
+		wantTestWindowEvents  int
+		wantTestStartedEvents int
+	}{
+		{
+			name: "only annotation",
+		},
+		{
+			name:                 "test window start",
+			sendTestWindowEvents: true,
+			wantTestWindowEvents: 1,
+		},
+		{
+			name:                 "test window start disabled for test.triggered",
+			sendTestWindowEvents: true,
+			eventTypes:           dynatrace.EventTypeMapping{"test.triggered": dynatrace.NoEventType},
+		},
+		{
+			name:                  "participation in test",
+			participateInTest:     true,
+			wantTestStartedEvents: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newDynatraceEventsRecorder(t)
+			kClient := &keptnClientMock{}
+			handler := NewTestTriggeredEventHandler(newTestTriggeredAdapter(t), newTestDynatraceClient(t, recorder), &eventClientMock{}, kClient, nil, tt.eventTypes, env.ReadConfig())
+			handler.sendTestWindowEvents = tt.sendTestWindowEvents
+			handler.participateInTest = tt.participateInTest
+
+			assert.NoError(t, handler.HandleEvent())
+
+			testWindowEvents := recorder.eventsOfType(dynatrace.InfoEventType)
+			if assert.Len(t, testWindowEvents, tt.wantTestWindowEvents) && tt.wantTestWindowEvents > 0 {
+				customProperties := testWindowEvents[0]["customProperties"].(map[string]interface{})
+				assert.Equal(t, testWindowStart, customProperties[testWindowProperty])
+				assert.Equal(t, "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", customProperties[testWindowIDProperty])
+			}
+
+			assert.Len(t, kClient.eventSink, tt.wantTestStartedEvents)
+			for _, ce := range kClient.eventSink {
+				assert.Equal(t, keptnv2.GetStartedEventType(keptnv2.TestTaskName), ce.Type())
+			}
+		})
+	}
+}
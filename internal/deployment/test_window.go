@@ -0,0 +1,32 @@
+package deployment
+
+import (
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// Custom properties of the CUSTOM_INFO events marking the start and stop of a test window. Both events of a test contain the same
+// test window ID, which is the load test name (LTN) of the x-dynatrace-test header, so that requests tagged with the header can be matched
+const (
+	testWindowProperty   = "Test Window"
+	testWindowIDProperty = "Test Window ID"
+
+	testWindowStart = "start"
+	testWindowStop  = "stop"
+)
+
+// createTestWindowEvent creates the CUSTOM_INFO event marking the start or stop of the test window of a test
+func createTestWindowEvent(event adapter.EventContentAdapter, imageAndTag common.ImageAndTag, attachRules *dynatrace.AttachRules, state string) dynatrace.InfoEvent {
+	header := common.NewDynatraceTestHeader(event)
+
+	ie := dynatrace.CreateInfoEventDTO(event, imageAndTag, attachRules)
+	ie.Title = fmt.Sprintf("Test window %s: %s", state, header.LoadTestName)
+	ie.Description = fmt.Sprintf("Tests %s of %s in stage %s, test strategy: %s", state, event.GetService(), event.GetStage(), event.GetTestStrategy())
+	ie.CustomProperties[testWindowProperty] = state
+	ie.CustomProperties[testWindowIDProperty] = header.LoadTestName
+	ie.CustomProperties[common.DynatraceTestHeaderName] = header.Header
+	return ie
+}
@@ -0,0 +1,57 @@
+package diagnostics
+
+import (
+	"encoding/json"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// DiagnoseTaskHandler runs the diagnostics for a dynatrace-diagnose.triggered event and reports the results in the finished event
+type DiagnoseTaskHandler struct {
+	event       *DiagnoseTriggeredAdapter
+	diagnostics *Diagnostics
+}
+
+// NewDiagnoseTaskHandler creates a new DiagnoseTaskHandler
+func NewDiagnoseTaskHandler(event *DiagnoseTriggeredAdapter, diagnostics *Diagnostics) *DiagnoseTaskHandler {
+	return &DiagnoseTaskHandler{
+		event:       event,
+		diagnostics: diagnostics,
+	}
+}
+
+// HandleTask runs the diagnostics and returns the factory for the finished event, whose result is fail if any check failed
+func (h *DiagnoseTaskHandler) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	report := h.diagnostics.Run(h.event.GetProject(), h.event.GetStage(), h.event.GetService())
+	LogReport(report)
+
+	result := keptnv2.ResultPass
+	if report.HasFailures() {
+		result = keptnv2.ResultFailed
+	}
+
+	finishedEvent := keptnv2.EventData{
+		Project: h.event.GetProject(),
+		Stage:   h.event.GetStage(),
+		Service: h.event.GetService(),
+		Labels:  h.event.GetLabels(),
+		Status:  keptnv2.StatusSucceeded,
+		Result:  result,
+		Message: report.String(),
+	}
+
+	return adapter.NewCloudEventFactory(h.event, keptnv2.GetFinishedEventType(TaskName), finishedEvent), nil
+}
+
+// LogReport logs the report as structured JSON
+func LogReport(report *Report) {
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		log.WithError(err).Error("Could not marshal diagnostics report")
+		return
+	}
+
+	log.WithField("report", string(reportJSON)).Info("Diagnostics finished")
+}
@@ -0,0 +1,147 @@
+package diagnostics
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// TaskName is the name of the task that runs the diagnostics, i.e. it is triggered by events of type sh.keptn.event.dynatrace-diagnose.triggered
+const TaskName = "dynatrace-diagnose"
+
+// DiagnoseTriggeredAdapter is a content adaptor for events of type sh.keptn.event.dynatrace-diagnose.triggered
+type DiagnoseTriggeredAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+}
+
+// IsDiagnoseTriggeredEventType returns whether the event type is the one of the diagnose task
+func IsDiagnoseTriggeredEventType(eventType string) bool {
+	return eventType == keptnv2.GetTriggeredEventType(TaskName)
+}
+
+// NewDiagnoseTriggeredAdapterFromEvent creates a new DiagnoseTriggeredAdapter from a cloudevents Event
+func NewDiagnoseTriggeredAdapterFromEvent(e cloudevents.Event) (*DiagnoseTriggeredAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	eventData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DiagnoseTriggeredAdapter{
+		event:      *eventData,
+		cloudEvent: ceAdapter,
+	}, nil
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a DiagnoseTriggeredAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetEventID returns the ID of the triggered event
+func (a DiagnoseTriggeredAdapter) GetEventID() string {
+	return a.cloudEvent.ID()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a DiagnoseTriggeredAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a DiagnoseTriggeredAdapter) GetEvent() string {
+	return a.cloudEvent.Type()
+}
+
+// GetProject returns the project
+func (a DiagnoseTriggeredAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a DiagnoseTriggeredAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a DiagnoseTriggeredAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a DiagnoseTriggeredAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a DiagnoseTriggeredAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a DiagnoseTriggeredAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a DiagnoseTriggeredAdapter) GetLabels() map[string]string {
+	return a.event.Labels
+}
+
+// scopeAdapter provides project, stage and service for loading the dynatrace.conf.yaml outside of an event, e.g. when diagnosing from the command line
+type scopeAdapter struct {
+	project string
+	stage   string
+	service string
+}
+
+func newScopeAdapter(project string, stage string, service string) *scopeAdapter {
+	return &scopeAdapter{
+		project: project,
+		stage:   stage,
+		service: service,
+	}
+}
+
+func (a scopeAdapter) GetShKeptnContext() string {
+	return ""
+}
+
+func (a scopeAdapter) GetEvent() string {
+	return ""
+}
+
+func (a scopeAdapter) GetSource() string {
+	return ""
+}
+
+func (a scopeAdapter) GetProject() string {
+	return a.project
+}
+
+func (a scopeAdapter) GetStage() string {
+	return a.stage
+}
+
+func (a scopeAdapter) GetService() string {
+	return a.service
+}
+
+func (a scopeAdapter) GetDeployment() string {
+	return ""
+}
+
+func (a scopeAdapter) GetTestStrategy() string {
+	return ""
+}
+
+func (a scopeAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+func (a scopeAdapter) GetLabels() map[string]string {
+	return nil
+}
@@ -0,0 +1,234 @@
+package diagnostics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+// the possible outcomes of a check
+const (
+	StatusOK      Status = "ok"
+	StatusWarning Status = "warning"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// names of the checks in the order they are run
+const (
+	ResourceServiceCheck = "resource-service access"
+	DynatraceSecretCheck = "Dynatrace secret"
+	DynatraceTenantCheck = "Dynatrace tenant reachability"
+	DynatraceTokenCheck  = "Dynatrace API token scopes"
+	KeptnSecretCheck     = "Keptn API secret"
+	KeptnConnectionCheck = "Keptn API connectivity"
+)
+
+// CheckResult is the result of a single check
+type CheckResult struct {
+	Name    string `json:"name"`
+	Status  Status `json:"status"`
+	Message string `json:"message"`
+}
+
+// Report contains the results of all checks
+type Report struct {
+	Project string        `json:"project,omitempty"`
+	Stage   string        `json:"stage,omitempty"`
+	Service string        `json:"service,omitempty"`
+	Checks  []CheckResult `json:"checks"`
+}
+
+// HasFailures returns whether any check failed
+func (r *Report) HasFailures() bool {
+	for _, check := range r.Checks {
+		if check.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a human readable representation of the report, e.g. for the message of a finished event
+func (r *Report) String() string {
+	var sb strings.Builder
+	sb.WriteString("Dynatrace service diagnostics:\n")
+	for _, check := range r.Checks {
+		sb.WriteString(fmt.Sprintf("  - [%s] %s: %s\n", check.Status, check.Name, check.Message))
+	}
+	return sb.String()
+}
+
+func (r *Report) add(name string, status Status, message string) {
+	r.Checks = append(r.Checks, CheckResult{Name: name, Status: status, Message: message})
+}
+
+// Diagnostics checks everything the dynatrace-service needs to connect to Dynatrace and Keptn
+type Diagnostics struct {
+	credentialManager   credentials.CredentialManagerInterface
+	dtConfigGetter      config.DynatraceConfigGetterInterface
+	dtClientFunc        func(dtCredentials *credentials.DTCredentials, options *dynatrace.TLSOptions) (dynatrace.ClientInterface, error)
+	keptnConnectionFunc func(keptnCredentials *credentials.KeptnAPICredentials) error
+	cfg                 *env.Config
+}
+
+// NewDefaultDiagnostics creates a new Diagnostics reading secrets from Kubernetes and the dynatrace.conf.yaml from the configuration service
+func NewDefaultDiagnostics(cfg *env.Config) (*Diagnostics, error) {
+	cm, err := credentials.NewCredentialManager(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDiagnostics(
+		cm,
+		config.NewDynatraceConfigGetter(keptn.GetDefaultCachingDynatraceConfigResourceClient(cfg)),
+		func(dtCredentials *credentials.DTCredentials, options *dynatrace.TLSOptions) (dynatrace.ClientInterface, error) {
+			return dynatrace.NewClientWithTLSOptions(dtCredentials, options, cfg)
+		},
+		func(keptnCredentials *credentials.KeptnAPICredentials) error {
+			return credentials.CheckKeptnConnection(cfg, keptnCredentials)
+		},
+		cfg), nil
+}
+
+// NewDiagnostics creates a new Diagnostics
+func NewDiagnostics(
+	credentialManager credentials.CredentialManagerInterface,
+	dtConfigGetter config.DynatraceConfigGetterInterface,
+	dtClientFunc func(dtCredentials *credentials.DTCredentials, options *dynatrace.TLSOptions) (dynatrace.ClientInterface, error),
+	keptnConnectionFunc func(keptnCredentials *credentials.KeptnAPICredentials) error,
+	cfg *env.Config) *Diagnostics {
+	return &Diagnostics{
+		credentialManager:   credentialManager,
+		dtConfigGetter:      dtConfigGetter,
+		dtClientFunc:        dtClientFunc,
+		keptnConnectionFunc: keptnConnectionFunc,
+		cfg:                 cfg,
+	}
+}
+
+// Run runs all checks for the given project, stage and service, which may be empty, and returns the report
+func (d *Diagnostics) Run(project string, stage string, service string) *Report {
+	report := &Report{
+		Project: project,
+		Stage:   stage,
+		Service: service,
+	}
+
+	dynatraceConfig := d.checkResourceService(report, project, stage, service)
+	d.checkDynatrace(report, dynatraceConfig)
+	d.checkKeptn(report)
+
+	return report
+}
+
+// checkResourceService loads the dynatrace.conf.yaml and returns it, or a default one if there is none
+func (d *Diagnostics) checkResourceService(report *Report, project string, stage string, service string) *config.DynatraceConfigFile {
+	defaultConfig := &config.DynatraceConfigFile{DtCreds: d.cfg.DefaultDynatraceSecretName}
+	if project == "" {
+		report.add(ResourceServiceCheck, StatusSkipped, "no project specified")
+		return defaultConfig
+	}
+
+	dynatraceConfig, err := d.dtConfigGetter.GetDynatraceConfig(newScopeAdapter(project, stage, service))
+	if err != nil {
+		var rnfErr *keptn.ResourceNotFoundError
+		var reErr *keptn.ResourceEmptyError
+		if errors.As(err, &rnfErr) || errors.As(err, &reErr) {
+			report.add(ResourceServiceCheck, StatusOK, "no dynatrace.conf.yaml found, using default configuration")
+			return defaultConfig
+		}
+
+		report.add(ResourceServiceCheck, StatusFailed, fmt.Sprintf("could not load dynatrace.conf.yaml: %v", err))
+		return defaultConfig
+	}
+
+	if dynatraceConfig.DtCreds == "" {
+		dynatraceConfig.DtCreds = d.cfg.DefaultDynatraceSecretName
+	}
+
+	report.add(ResourceServiceCheck, StatusOK, "dynatrace.conf.yaml loaded")
+	return dynatraceConfig
+}
+
+// checkDynatrace resolves the credentials the same way the event handlers do, i.e. falling back to the default secret
+func (d *Diagnostics) checkDynatrace(report *Report, dynatraceConfig *config.DynatraceConfigFile) {
+	credentialManager := credentials.NewCredentialManagerDefaultFallbackDecorator(d.credentialManager, d.cfg)
+	dtCredentials, err := credentialManager.GetDynatraceCredentials(dynatraceConfig.DtCreds)
+	if err != nil {
+		report.add(DynatraceSecretCheck, StatusFailed, fmt.Sprintf("could not read secret %s: %v", dynatraceConfig.DtCreds, err))
+		report.add(DynatraceTenantCheck, StatusSkipped, "no Dynatrace credentials")
+		report.add(DynatraceTokenCheck, StatusSkipped, "no Dynatrace credentials")
+		return
+	}
+	report.add(DynatraceSecretCheck, StatusOK, fmt.Sprintf("secret %s found for tenant %s", credentialManager.GetSecretName(), dtCredentials.Tenant))
+
+	dtClient, err := d.dtClientFunc(dtCredentials, dynatraceConfig.TLS)
+	if err != nil {
+		report.add(DynatraceTenantCheck, StatusFailed, fmt.Sprintf("could not create Dynatrace client: %v", err))
+		report.add(DynatraceTokenCheck, StatusSkipped, "no Dynatrace client")
+		return
+	}
+
+	metadata, err := dynatrace.CheckConnectivity(dtClient)
+	if err != nil {
+		var connectivityErr *dynatrace.ConnectivityError
+		if errors.As(err, &connectivityErr) && connectivityErr.Problem() == dynatrace.ScopeProblem {
+			report.add(DynatraceTenantCheck, StatusOK, fmt.Sprintf("%s is reachable", dtCredentials.Tenant))
+			report.add(DynatraceTokenCheck, StatusFailed, err.Error())
+			return
+		}
+
+		if connectivityErr != nil && connectivityErr.Problem() != dynatrace.TokenProblem {
+			report.add(DynatraceTenantCheck, StatusFailed, err.Error())
+			report.add(DynatraceTokenCheck, StatusSkipped, "Dynatrace tenant not reachable")
+			return
+		}
+
+		var apiErr *dynatrace.APIError
+		if !errors.As(err, &apiErr) {
+			report.add(DynatraceTenantCheck, StatusFailed, fmt.Sprintf("could not connect to %s: %v", dtCredentials.Tenant, err))
+			report.add(DynatraceTokenCheck, StatusSkipped, "Dynatrace tenant not reachable")
+			return
+		}
+
+		report.add(DynatraceTenantCheck, StatusOK, fmt.Sprintf("%s is reachable", dtCredentials.Tenant))
+		report.add(DynatraceTokenCheck, StatusFailed, fmt.Sprintf("could not look up API token: %v", err))
+		return
+	}
+	report.add(DynatraceTenantCheck, StatusOK, fmt.Sprintf("%s is reachable", dtCredentials.Tenant))
+
+	missingScopes := dynatrace.FindMissingScopes(dynatrace.GetRequiredScopes(d.cfg), metadata.Scopes)
+	if len(missingScopes) > 0 {
+		report.add(DynatraceTokenCheck, StatusWarning, "missing scopes required for the enabled features: "+strings.Join(missingScopes, ", "))
+		return
+	}
+	report.add(DynatraceTokenCheck, StatusOK, "all scopes required for the enabled features are granted")
+}
+
+func (d *Diagnostics) checkKeptn(report *Report) {
+	keptnCredentials, err := d.credentialManager.GetKeptnAPICredentials()
+	if err != nil {
+		report.add(KeptnSecretCheck, StatusFailed, fmt.Sprintf("could not read Keptn API credentials: %v", err))
+		report.add(KeptnConnectionCheck, StatusSkipped, "no Keptn API credentials")
+		return
+	}
+	report.add(KeptnSecretCheck, StatusOK, fmt.Sprintf("Keptn API credentials found for %s", keptnCredentials.APIURL))
+
+	err = d.keptnConnectionFunc(keptnCredentials)
+	if err != nil {
+		// the Keptn API might not be reachable from within the cluster due to a no-loopback policy of the load balancer
+		report.add(KeptnConnectionCheck, StatusWarning, err.Error())
+		return
+	}
+	report.add(KeptnConnectionCheck, StatusOK, fmt.Sprintf("authenticated at %s", keptnCredentials.APIURL))
+}
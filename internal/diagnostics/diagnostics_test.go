@@ -0,0 +1,229 @@
+package diagnostics
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	adapter_mock "github.com/keptn-contrib/dynatrace-service/internal/adapter/mock"
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialManagerMock struct {
+	dtCredentials    map[string]*credentials.DTCredentials
+	keptnCredentials *credentials.KeptnAPICredentials
+}
+
+func (m *credentialManagerMock) GetDynatraceCredentials(secretName string) (*credentials.DTCredentials, error) {
+	dtCredentials, ok := m.dtCredentials[secretName]
+	if !ok {
+		return nil, credentials.ErrSecretNotFound
+	}
+	return dtCredentials, nil
+}
+
+func (m *credentialManagerMock) GetKeptnAPICredentials() (*credentials.KeptnAPICredentials, error) {
+	if m.keptnCredentials == nil {
+		return nil, errors.New("KEPTN_API_URL is not set")
+	}
+	return m.keptnCredentials, nil
+}
+
+func newConfigGetter(dynatraceConfig *config.DynatraceConfigFile, err error) *adapter_mock.DynatraceConfigGetterInterfaceMock {
+	return &adapter_mock.DynatraceConfigGetterInterfaceMock{
+		GetDynatraceConfigFunc: func(event adapter.EventContentAdapter) (*config.DynatraceConfigFile, error) {
+			return dynatraceConfig, err
+		},
+	}
+}
+
+func newDynatraceClientFunc(t *testing.T, lookupResponse string, lookupStatus int) (func(*credentials.DTCredentials, *dynatrace.TLSOptions) (dynatrace.ClientInterface, error), func()) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExactError("/api/v2/apiTokens/lookup", lookupStatus, []byte(lookupResponse))
+	handler.AddExactError("/api/v1/tokens/lookup", lookupStatus, []byte(lookupResponse))
+
+	httpClient, url, teardown := test.CreateHTTPSClient(handler)
+	return func(dtCredentials *credentials.DTCredentials, _ *dynatrace.TLSOptions) (dynatrace.ClientInterface, error) {
+		return dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: dtCredentials.ApiToken}, httpClient), nil
+	}, teardown
+}
+
+func getStatuses(report *Report) map[string]Status {
+	statuses := make(map[string]Status, len(report.Checks))
+	for _, check := range report.Checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestDiagnostics_Run_AllChecksSucceed(t *testing.T) {
+	dtClientFunc, teardown := newDynatraceClientFunc(t, `{"scopes":["DataExport","ReadConfig","metrics.read"]}`, http.StatusOK)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials:    map[string]*credentials.DTCredentials{"dynatrace-prod": {Tenant: "https://prod.live.dynatrace.com", ApiToken: "token"}},
+			keptnCredentials: &credentials.KeptnAPICredentials{APIURL: "https://keptn.example.com/api", APIToken: "token"},
+		},
+		newConfigGetter(&config.DynatraceConfigFile{DtCreds: "dynatrace-prod"}, nil),
+		dtClientFunc,
+		func(*credentials.KeptnAPICredentials) error { return nil },
+		env.ReadConfig())
+
+	report := d.Run("sockshop", "production", "carts")
+
+	assert.False(t, report.HasFailures())
+	assert.Equal(t, map[string]Status{
+		ResourceServiceCheck: StatusOK,
+		DynatraceSecretCheck: StatusOK,
+		DynatraceTenantCheck: StatusOK,
+		DynatraceTokenCheck:  StatusOK,
+		KeptnSecretCheck:     StatusOK,
+		KeptnConnectionCheck: StatusOK,
+	}, getStatuses(report))
+}
+
+func TestDiagnostics_Run_ReportsProblems(t *testing.T) {
+	dtClientFunc, teardown := newDynatraceClientFunc(t, `{"scopes":["DataExport"]}`, http.StatusOK)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials: map[string]*credentials.DTCredentials{"dynatrace": {Tenant: "https://prod.live.dynatrace.com", ApiToken: "token"}},
+		},
+		newConfigGetter(nil, &keptn.ResourceNotFoundError{}),
+		dtClientFunc,
+		func(*credentials.KeptnAPICredentials) error { return nil },
+		env.ReadConfig())
+
+	report := d.Run("sockshop", "production", "carts")
+
+	assert.True(t, report.HasFailures())
+	assert.Equal(t, map[string]Status{
+		ResourceServiceCheck: StatusOK,
+		DynatraceSecretCheck: StatusOK,
+		DynatraceTenantCheck: StatusOK,
+		DynatraceTokenCheck:  StatusWarning,
+		KeptnSecretCheck:     StatusFailed,
+		KeptnConnectionCheck: StatusSkipped,
+	}, getStatuses(report))
+}
+
+func TestDiagnostics_Run_MissingSecretSkipsDynatraceChecks(t *testing.T) {
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			keptnCredentials: &credentials.KeptnAPICredentials{APIURL: "https://keptn.example.com/api", APIToken: "token"},
+		},
+		newConfigGetter(nil, errors.New("configuration-service not reachable")),
+		nil,
+		func(*credentials.KeptnAPICredentials) error { return errors.New("invalid Keptn API Token") },
+		env.ReadConfig())
+
+	report := d.Run("sockshop", "", "")
+
+	assert.True(t, report.HasFailures())
+	assert.Equal(t, map[string]Status{
+		ResourceServiceCheck: StatusFailed,
+		DynatraceSecretCheck: StatusFailed,
+		DynatraceTenantCheck: StatusSkipped,
+		DynatraceTokenCheck:  StatusSkipped,
+		KeptnSecretCheck:     StatusOK,
+		KeptnConnectionCheck: StatusWarning,
+	}, getStatuses(report))
+}
+
+func TestDiagnostics_Run_InvalidTokenIsReportedAsReachableTenant(t *testing.T) {
+	dtClientFunc, teardown := newDynatraceClientFunc(t, `{"error":{"code":401,"message":"Token Authentication failed"}}`, http.StatusUnauthorized)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials: map[string]*credentials.DTCredentials{"dynatrace": {Tenant: "https://prod.live.dynatrace.com", ApiToken: "token"}},
+		},
+		newConfigGetter(nil, nil),
+		dtClientFunc,
+		nil,
+		env.ReadConfig())
+
+	report := d.Run("", "", "")
+
+	statuses := getStatuses(report)
+	assert.Equal(t, StatusSkipped, statuses[ResourceServiceCheck])
+	assert.Equal(t, StatusOK, statuses[DynatraceTenantCheck])
+	assert.Equal(t, StatusFailed, statuses[DynatraceTokenCheck])
+}
+
+func TestDiagnostics_Run_ActiveGateRoutingProblemIsReportedAsUnreachableTenant(t *testing.T) {
+	dtClientFunc, teardown := newDynatraceClientFunc(t, `<html><body>Not Found</body></html>`, http.StatusNotFound)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials: map[string]*credentials.DTCredentials{"dynatrace": {Tenant: "https://activegate.example.com:9999", ApiToken: "token"}},
+		},
+		newConfigGetter(nil, nil),
+		dtClientFunc,
+		nil,
+		env.ReadConfig())
+
+	report := d.Run("", "", "")
+
+	statuses := getStatuses(report)
+	assert.Equal(t, StatusFailed, statuses[DynatraceTenantCheck])
+	assert.Equal(t, StatusSkipped, statuses[DynatraceTokenCheck])
+	assert.Contains(t, report.String(), "/e/<environment-id>")
+}
+
+func TestDiagnostics_Run_FallsBackToDefaultSecret(t *testing.T) {
+	dtClientFunc, teardown := newDynatraceClientFunc(t, `{"scopes":["DataExport","ReadConfig","metrics.read"]}`, http.StatusOK)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials: map[string]*credentials.DTCredentials{"dynatrace": {Tenant: "https://prod.live.dynatrace.com", ApiToken: "token"}},
+		},
+		newConfigGetter(&config.DynatraceConfigFile{DtCreds: "dynatrace-missing"}, nil),
+		dtClientFunc,
+		nil,
+		env.ReadConfig())
+
+	report := d.Run("sockshop", "production", "carts")
+
+	statuses := getStatuses(report)
+	assert.Equal(t, StatusOK, statuses[DynatraceSecretCheck])
+	assert.Equal(t, StatusOK, statuses[DynatraceTenantCheck])
+	assert.Contains(t, report.String(), "secret dynatrace found")
+}
+
+func TestDiagnostics_Run_MissingScopeOfConfigurationAPIIsReportedAsTokenProblem(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/v2/apiTokens/lookup", []byte(`{"id":"dt0c01.ABC","name":"keptn","scopes":["DataExport","metrics.read"]}`))
+
+	httpClient, url, teardown := test.CreateHTTPSClient(handler)
+	defer teardown()
+
+	d := NewDiagnostics(
+		&credentialManagerMock{
+			dtCredentials: map[string]*credentials.DTCredentials{"dynatrace": {Tenant: "https://activegate.example.com:9999/e/abc12345", ApiToken: "token"}},
+		},
+		newConfigGetter(nil, nil),
+		func(dtCredentials *credentials.DTCredentials, _ *dynatrace.TLSOptions) (dynatrace.ClientInterface, error) {
+			return dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: dtCredentials.ApiToken, ConfigAPIURL: url + "/config"}, httpClient), nil
+		},
+		nil,
+		env.ReadConfig())
+
+	report := d.Run("", "", "")
+
+	statuses := getStatuses(report)
+	assert.Equal(t, StatusOK, statuses[DynatraceTenantCheck])
+	assert.Equal(t, StatusFailed, statuses[DynatraceTokenCheck])
+	assert.Contains(t, report.String(), "missing scope ReadConfig")
+}
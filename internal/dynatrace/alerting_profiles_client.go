@@ -43,6 +43,12 @@ type CustomEventFilter struct {
 	CustomTitleFilter CustomTitleFilter `json:"customTitleFilter"`
 }
 
+// AlertingProfilesClientInterface creates and looks up alerting profiles
+type AlertingProfilesClientInterface interface {
+	GetProfileID(profileName string) (string, error)
+	Create(alertingProfile *AlertingProfile) (string, error)
+}
+
 type AlertingProfilesClient struct {
 	client ClientInterface
 }
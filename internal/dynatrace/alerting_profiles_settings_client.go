@@ -0,0 +1,139 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type alertingProfileSettings struct {
+	Name           string                         `json:"name"`
+	ManagementZone string                         `json:"managementZone,omitempty"`
+	SeverityRules  []alertingProfileSeverityRule  `json:"severityRules"`
+	EventFilters   []alertingProfileSettingsEvent `json:"eventFilters"`
+}
+
+type alertingProfileSeverityRule struct {
+	SeverityLevel        string   `json:"severityLevel"`
+	DelayInMinutes       int      `json:"delayInMinutes"`
+	TagFilterIncludeMode string   `json:"tagFilterIncludeMode"`
+	TagFilter            []string `json:"tagFilter"`
+}
+
+type alertingProfileSettingsEvent struct {
+	Type         string                              `json:"type"`
+	CustomFilter alertingProfileSettingsCustomFilter `json:"customFilter"`
+}
+
+type alertingProfileSettingsCustomFilter struct {
+	TitleFilter alertingProfileSettingsTextFilter `json:"titleFilter"`
+}
+
+type alertingProfileSettingsTextFilter struct {
+	Enabled       bool   `json:"enabled"`
+	Value         string `json:"value"`
+	Operator      string `json:"operator"`
+	Negate        bool   `json:"negate"`
+	CaseSensitive bool   `json:"caseSensitive"`
+}
+
+// AlertingProfilesSettingsClient creates and looks up alerting profiles using the Settings 2.0 API
+type AlertingProfilesSettingsClient struct {
+	client *SettingsClient
+}
+
+// NewAlertingProfilesSettingsClient creates a new AlertingProfilesSettingsClient
+func NewAlertingProfilesSettingsClient(client *SettingsClient) *AlertingProfilesSettingsClient {
+	return &AlertingProfilesSettingsClient{
+		client: client,
+	}
+}
+
+// GetProfileID returns the object ID of the alerting profile with the given profileName if found, an empty string otherwise
+func (apc *AlertingProfilesSettingsClient) GetProfileID(profileName string) (string, error) {
+	objects, err := apc.client.List(AlertingProfileSchemaID, EnvironmentScope)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve alerting profiles: %w", err)
+	}
+
+	for _, object := range objects {
+		profile := &alertingProfileSettings{}
+		err := json.Unmarshal(object.Value, profile)
+		if err != nil {
+			return "", fmt.Errorf("failed to unmarshal alerting profile %s: %w", object.ObjectID, err)
+		}
+
+		if profile.Name == profileName {
+			return object.ObjectID, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Create creates the alerting profile as a settings object of schema builtin:alerting.profile and returns its object ID
+func (apc *AlertingProfilesSettingsClient) Create(alertingProfile *AlertingProfile) (string, error) {
+	objectIDs, err := apc.client.Create(
+		SettingsObjectCreate{
+			SchemaID: AlertingProfileSchemaID,
+			Scope:    EnvironmentScope,
+			Value:    toAlertingProfileSettings(alertingProfile),
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to setup alerting profile: %w", err)
+	}
+
+	if len(objectIDs) != 1 {
+		return "", errors.New("failed to setup alerting profile: no object ID returned")
+	}
+
+	return objectIDs[0], nil
+}
+
+func toAlertingProfileSettings(alertingProfile *AlertingProfile) *alertingProfileSettings {
+	settings := &alertingProfileSettings{
+		Name:          alertingProfile.DisplayName,
+		SeverityRules: []alertingProfileSeverityRule{},
+		EventFilters:  []alertingProfileSettingsEvent{},
+	}
+
+	if managementZoneID, ok := alertingProfile.ManagementZoneID.(string); ok {
+		settings.ManagementZone = managementZoneID
+	}
+
+	for _, rule := range alertingProfile.Rules {
+		tagFilters := rule.TagFilter.TagFilters
+		if tagFilters == nil {
+			tagFilters = []string{}
+		}
+
+		settings.SeverityRules = append(
+			settings.SeverityRules,
+			alertingProfileSeverityRule{
+				SeverityLevel:        rule.SeverityLevel,
+				DelayInMinutes:       rule.DelayInMinutes,
+				TagFilterIncludeMode: rule.TagFilter.IncludeMode,
+				TagFilter:            tagFilters,
+			})
+	}
+
+	for _, filter := range alertingProfile.EventTypeFilters {
+		titleFilter := filter.CustomEventFilter.CustomTitleFilter
+		settings.EventFilters = append(
+			settings.EventFilters,
+			alertingProfileSettingsEvent{
+				Type: "CUSTOM",
+				CustomFilter: alertingProfileSettingsCustomFilter{
+					TitleFilter: alertingProfileSettingsTextFilter{
+						Enabled:       titleFilter.Enabled,
+						Value:         titleFilter.Value,
+						Operator:      titleFilter.Operator,
+						Negate:        titleFilter.Negate,
+						CaseSensitive: !titleFilter.CaseInsensitive,
+					},
+				},
+			})
+	}
+
+	return settings
+}
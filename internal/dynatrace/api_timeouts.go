@@ -0,0 +1,57 @@
+package dynatrace
+
+import (
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+// APITimeouts are the timeouts of requests to the families of Dynatrace APIs. A timeout of 0 disables it.
+// Requests to other APIs, e.g. the configuration API, have no timeout
+type APITimeouts struct {
+	// Metrics is the timeout of the metrics, SLO and USQL APIs, which may take long for large timeframes
+	Metrics time.Duration
+	// Entities is the timeout of the entities API
+	Entities time.Duration
+	// Events is the timeout of the events API
+	Events time.Duration
+	// Problems is the timeout of the problems and security problems APIs
+	Problems time.Duration
+	// Dashboards is the timeout of the dashboards API
+	Dashboards time.Duration
+}
+
+// NewAPITimeouts returns the timeouts of the configuration
+func NewAPITimeouts(cfg *env.Config) APITimeouts {
+	return APITimeouts{
+		Metrics:    time.Duration(cfg.DynatraceAPIMetricsTimeout) * time.Second,
+		Entities:   time.Duration(cfg.DynatraceAPIEntitiesTimeout) * time.Second,
+		Events:     time.Duration(cfg.DynatraceAPIEventsTimeout) * time.Second,
+		Problems:   time.Duration(cfg.DynatraceAPIProblemsTimeout) * time.Second,
+		Dashboards: time.Duration(cfg.DynatraceAPIDashboardsTimeout) * time.Second,
+	}
+}
+
+// forPath returns the timeout of requests to the API path or 0 if there is none
+func (t APITimeouts) forPath(apiPath string) time.Duration {
+	path := "/" + strings.TrimPrefix(stripQuery(apiPath), "/")
+	switch {
+	case hasPathPrefix(path, metricsPath), hasPathPrefix(path, sloPath), hasPathPrefix(path, usqlPath):
+		return t.Metrics
+	case hasPathPrefix(path, entitiesPath):
+		return t.Entities
+	case hasPathPrefix(path, eventsPath):
+		return t.Events
+	case hasPathPrefix(path, problemDetailsPath), hasPathPrefix(path, problemsV2Path), hasPathPrefix(path, securityProblemsPath):
+		return t.Problems
+	case hasPathPrefix(path, dashboardsPath):
+		return t.Dashboards
+	default:
+		return 0
+	}
+}
+
+func hasPathPrefix(path string, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
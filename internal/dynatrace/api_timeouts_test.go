@@ -0,0 +1,67 @@
+package dynatrace
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITimeouts_forPath(t *testing.T) {
+	timeouts := APITimeouts{
+		Metrics:    1 * time.Second,
+		Entities:   2 * time.Second,
+		Events:     3 * time.Second,
+		Problems:   4 * time.Second,
+		Dashboards: 5 * time.Second,
+	}
+
+	tests := []struct {
+		name     string
+		apiPath  string
+		expected time.Duration
+	}{
+		{name: "metrics query", apiPath: "/api/v2/metrics/query?metricSelector=builtin:service.response.time", expected: 1 * time.Second},
+		{name: "metrics ingest", apiPath: "/api/v2/metrics/ingest", expected: 1 * time.Second},
+		{name: "SLO", apiPath: "/api/v2/slo/abc", expected: 1 * time.Second},
+		{name: "USQL", apiPath: "/api/v1/userSessionQueryLanguage/table?query=SELECT", expected: 1 * time.Second},
+		{name: "entities", apiPath: "/api/v2/entities?entitySelector=type(SERVICE)", expected: 2 * time.Second},
+		{name: "events", apiPath: "/api/v1/events", expected: 3 * time.Second},
+		{name: "problems", apiPath: "/api/v2/problems?problemSelector=status(OPEN)", expected: 4 * time.Second},
+		{name: "problem details", apiPath: "/api/v1/problem/details/123", expected: 4 * time.Second},
+		{name: "security problems", apiPath: "/api/v2/securityProblems", expected: 4 * time.Second},
+		{name: "dashboards", apiPath: "/api/config/v1/dashboards/abc", expected: 5 * time.Second},
+		{name: "path without leading slash", apiPath: "api/v2/entities", expected: 2 * time.Second},
+		{name: "other API", apiPath: "/api/config/v1/managementZones", expected: 0},
+		{name: "path only sharing a prefix", apiPath: "/api/v2/entitiesx", expected: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, timeouts.forPath(tt.apiPath))
+		})
+	}
+}
+
+func TestClient_RequestTimesOut(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(500 * time.Millisecond):
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	client, teardown := testingDynatraceClient(handler)
+	defer teardown()
+
+	client.SetTimeouts(APITimeouts{Entities: 50 * time.Millisecond})
+
+	_, err := client.Get("/api/v2/entities?entitySelector=type(SERVICE)")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "request timed out")
+	}
+
+	_, err = client.Get("/api/v2/metrics/query")
+	assert.NoError(t, err)
+}
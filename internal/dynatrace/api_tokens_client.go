@@ -0,0 +1,141 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+const apiTokensLookupPath = "/api/v2/apiTokens/lookup"
+const apiTokensV1LookupPath = "/api/v1/tokens/lookup"
+
+// token scopes required by the dynatrace-service
+const (
+	// DataExportScope is required for sending events and retrieving problem details
+	DataExportScope = "DataExport"
+	// ReadConfigScope is required for reading dashboards used for SLIs
+	ReadConfigScope = "ReadConfig"
+	// WriteConfigScope is required for creating tagging rules, problem notifications, management zones, dashboards, metric events and quality gate dashboards
+	WriteConfigScope = "WriteConfig"
+	// MetricsReadScope is required for retrieving SLIs
+	MetricsReadScope = "metrics.read"
+	// MetricsIngestScope is required for ingesting evaluation results, test results, remediation results and self-monitoring metrics
+	MetricsIngestScope = "metrics.ingest"
+	// EntitiesReadScope is required for synchronizing services and validating attach rules
+	EntitiesReadScope = "entities.read"
+	// EntitiesWriteScope is required for adding the keptn_project, keptn_stage and keptn_service tags to entities matched by attach rules
+	EntitiesWriteScope = "entities.write"
+	// ProblemsWriteScope is required for closing problems after successful remediations
+	ProblemsWriteScope = "problems.write"
+	// SettingsReadScope is required for reading tagging rules, problem notifications, alerting profiles and metric events from the Settings 2.0 API
+	SettingsReadScope = "settings.read"
+	// SettingsWriteScope is required for creating tagging rules, problem notifications, alerting profiles and metric events using the Settings 2.0 API
+	SettingsWriteScope = "settings.write"
+)
+
+// APITokenMetadata contains the metadata of an API token returned by the token lookup APIs
+type APITokenMetadata struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+type apiTokenLookupRequest struct {
+	Token string `json:"token"`
+}
+
+// APITokensClient is a client for retrieving the metadata of the API token used by the dynatrace-service
+type APITokensClient struct {
+	client ClientInterface
+}
+
+// NewAPITokensClient creates a new APITokensClient
+func NewAPITokensClient(client ClientInterface) *APITokensClient {
+	return &APITokensClient{
+		client: client,
+	}
+}
+
+// Lookup returns the metadata of the API token used by the client. If the token may not use the API tokens API of API v2,
+// the token lookup of API v1 is used instead
+func (c *APITokensClient) Lookup() (*APITokenMetadata, error) {
+	body, err := json.Marshal(apiTokenLookupRequest{Token: activeAPIToken(c.client.Credentials())})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.client.Post(apiTokensLookupPath, body)
+	if err != nil {
+		log.WithError(err).Debug("Could not look up API token using API v2, trying API v1")
+
+		response, err = c.client.Post(apiTokensV1LookupPath, body)
+		if err != nil {
+			return nil, fmt.Errorf("could not look up API token: %w", err)
+		}
+	}
+
+	var metadata APITokenMetadata
+	err = json.Unmarshal(response, &metadata)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse API token metadata: %w", err)
+	}
+
+	return &metadata, nil
+}
+
+// GetMissingScopes returns the scopes required for the enabled features that the API token used by the client does not have
+func (c *APITokensClient) GetMissingScopes(cfg *env.Config) ([]string, error) {
+	metadata, err := c.Lookup()
+	if err != nil {
+		return nil, err
+	}
+
+	return FindMissingScopes(GetRequiredScopes(cfg), metadata.Scopes), nil
+}
+
+// GetRequiredScopes returns the sorted token scopes required for the features enabled by the configuration
+func GetRequiredScopes(cfg *env.Config) []string {
+	scopes := []string{DataExportScope, ReadConfigScope, MetricsReadScope}
+
+	if cfg.TaggingRulesGenerationEnabled || cfg.ProblemNotificationsGenerationEnabled || cfg.ManagementZonesGenerationEnabled ||
+		cfg.DashboardsGenerationEnabled || cfg.MetricEventsGenerationEnabled || cfg.QualityGateDashboardEnabled {
+		scopes = append(scopes, WriteConfigScope)
+	}
+	if cfg.TaggingRulesGenerationEnabled || cfg.ProblemNotificationsGenerationEnabled || cfg.MetricEventsGenerationEnabled {
+		scopes = append(scopes, SettingsReadScope, SettingsWriteScope)
+	}
+	if cfg.EvaluationMetricsIngestEnabled || cfg.TestMetricsIngestEnabled || cfg.RemediationMetricsIngestEnabled || cfg.SelfMonitoringEnabled {
+		scopes = append(scopes, MetricsIngestScope)
+	}
+	if cfg.ServiceSyncEnabled || cfg.AttachRulesValidationEnabled {
+		scopes = append(scopes, EntitiesReadScope)
+	}
+	if cfg.EntityTagEnrichmentEnabled {
+		scopes = append(scopes, EntitiesWriteScope)
+	}
+	if cfg.ProblemClosingAfterRemediationEnabled {
+		scopes = append(scopes, ProblemsWriteScope)
+	}
+
+	sort.Strings(scopes)
+	return scopes
+}
+
+// FindMissingScopes returns the required scopes that are not granted
+func FindMissingScopes(required []string, granted []string) []string {
+	grantedScopes := make(map[string]bool, len(granted))
+	for _, scope := range granted {
+		grantedScopes[scope] = true
+	}
+
+	var missing []string
+	for _, scope := range required {
+		if !grantedScopes[scope] {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,69 @@
+package dynatrace
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokensClient_GetMissingScopes(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(apiTokensLookupPath, []byte(`{"id":"dt0c01.ABC","name":"keptn","scopes":["DataExport","metrics.read","WriteConfig"]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	missingScopes, err := NewAPITokensClient(dtClient).GetMissingScopes(&env.Config{DashboardsGenerationEnabled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ReadConfigScope}, missingScopes)
+}
+
+func TestAPITokensClient_LookupFallsBackToAPIv1(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExactError(apiTokensLookupPath, 403, []byte(`{"error":{"code":403,"message":"Token is missing required scope"}}`))
+	handler.AddExact(apiTokensV1LookupPath, []byte(`{"id":"dt0c01.ABC","name":"keptn","scopes":["DataExport"]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	metadata, err := NewAPITokensClient(dtClient).Lookup()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DataExportScope}, metadata.Scopes)
+}
+
+func TestGetRequiredScopes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  env.Config
+		want []string
+	}{
+		{
+			name: "default features",
+			want: []string{DataExportScope, ReadConfigScope, MetricsReadScope},
+		},
+		{
+			name: "all features",
+			cfg: env.Config{
+				TaggingRulesGenerationEnabled:  true,
+				ServiceSyncEnabled:             true,
+				EvaluationMetricsIngestEnabled: true,
+				EntityTagEnrichmentEnabled:     true,
+			},
+			want: []string{DataExportScope, ReadConfigScope, WriteConfigScope, EntitiesReadScope, EntitiesWriteScope, MetricsIngestScope, MetricsReadScope, SettingsReadScope, SettingsWriteScope},
+		},
+		{
+			name: "metric events",
+			cfg: env.Config{
+				MetricEventsGenerationEnabled: true,
+			},
+			want: []string{DataExportScope, ReadConfigScope, WriteConfigScope, MetricsReadScope, SettingsReadScope, SettingsWriteScope},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GetRequiredScopes(&tt.cfg))
+		})
+	}
+}
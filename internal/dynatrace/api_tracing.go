@@ -0,0 +1,134 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+const redactedToken = "***"
+
+// maxLoggedBodyLength limits the length of bodies that are logged, the trace file always contains the complete bodies
+const maxLoggedBodyLength = 4096
+
+// APICallTrace is the record of a single call of the Dynatrace API with the API token redacted
+type APICallTrace struct {
+	Time         time.Time `json:"time"`
+	Method       string    `json:"method"`
+	URL          string    `json:"url"`
+	RequestBody  string    `json:"requestBody,omitempty"`
+	StatusCode   int       `json:"statusCode,omitempty"`
+	ResponseBody string    `json:"responseBody,omitempty"`
+	DurationMs   int64     `json:"durationMs"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// traceFileMutex serializes writes of concurrent API calls to the trace file
+var traceFileMutex sync.Mutex
+
+// APICallTracing defines whether calls of the Dynatrace API are logged and whether they are appended to a trace file
+type APICallTracing struct {
+	// Enabled defines whether API calls are logged
+	Enabled bool
+	// TraceFile is the file API calls are appended to, none if empty
+	TraceFile string
+}
+
+// NewAPICallTracing returns the tracing of API calls of the configuration
+func NewAPICallTracing(cfg *env.Config) APICallTracing {
+	return APICallTracing{
+		Enabled:   cfg.DynatraceAPITracingEnabled,
+		TraceFile: cfg.DynatraceAPITraceFile,
+	}
+}
+
+// isActive returns whether API calls are logged or written to a trace file, which requires their complete response bodies
+func (t APICallTracing) isActive() bool {
+	return t.Enabled || t.TraceFile != ""
+}
+
+// traceAPICall logs the API call if tracing is enabled and appends it to the trace file if one is configured
+func (t APICallTracing) traceAPICall(apiToken string, req *http.Request, requestBody []byte, statusCode int, responseBody []byte, duration time.Duration, err error) {
+	if !t.isActive() {
+		return
+	}
+
+	trace := newAPICallTrace(apiToken, req, requestBody, statusCode, responseBody, duration, err)
+
+	if t.Enabled {
+		log.WithFields(
+			log.Fields{
+				"method":       trace.Method,
+				"url":          trace.URL,
+				"requestBody":  truncate(trace.RequestBody, maxLoggedBodyLength),
+				"statusCode":   trace.StatusCode,
+				"responseBody": truncate(trace.ResponseBody, maxLoggedBodyLength),
+				"durationMs":   trace.DurationMs,
+				"error":        trace.Error,
+			}).Info("Dynatrace API call")
+	}
+
+	if t.TraceFile != "" {
+		writeErr := appendAPICallTrace(t.TraceFile, trace)
+		if writeErr != nil {
+			log.WithError(writeErr).WithField("file", t.TraceFile).Warn("Could not write Dynatrace API call to trace file")
+		}
+	}
+}
+
+func newAPICallTrace(apiToken string, req *http.Request, requestBody []byte, statusCode int, responseBody []byte, duration time.Duration, err error) *APICallTrace {
+	trace := &APICallTrace{
+		Time:         time.Now().UTC(),
+		Method:       req.Method,
+		URL:          redact(req.URL.String(), apiToken),
+		RequestBody:  redact(string(requestBody), apiToken),
+		StatusCode:   statusCode,
+		ResponseBody: redact(string(responseBody), apiToken),
+		DurationMs:   duration.Milliseconds(),
+	}
+
+	if err != nil {
+		trace.Error = redact(err.Error(), apiToken)
+	}
+
+	return trace
+}
+
+func appendAPICallTrace(traceFile string, trace *APICallTrace) error {
+	line, err := json.Marshal(trace)
+	if err != nil {
+		return err
+	}
+
+	traceFileMutex.Lock()
+	defer traceFileMutex.Unlock()
+
+	file, err := os.OpenFile(traceFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
+
+func redact(value string, apiToken string) string {
+	if apiToken == "" {
+		return value
+	}
+	return strings.ReplaceAll(value, apiToken, redactedToken)
+}
+
+func truncate(value string, maxLength int) string {
+	if len(value) <= maxLength {
+		return value
+	}
+	return value[:maxLength] + "..."
+}
@@ -0,0 +1,50 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_TracesAPICallsToFileWithRedactedToken(t *testing.T) {
+	traceFile := filepath.Join(t.TempDir(), "trace.jsonl")
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(apiTokensLookupPath, []byte(`{"id":"dt0c01.ABC","scopes":["DataExport"]}`))
+	handler.AddExactError(metricsPath+"/unknown", 404, []byte(`{"error":{"code":404,"message":"Metric not found"}}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+	dtClient.(*Client).apiTracing = APICallTracing{TraceFile: traceFile}
+
+	_, err := NewAPITokensClient(dtClient).Lookup()
+	assert.NoError(t, err)
+	_, err = dtClient.Get(metricsPath + "/unknown")
+	assert.Error(t, err)
+
+	content, err := ioutil.ReadFile(traceFile)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if assert.Len(t, lines, 2) {
+		lookup := &APICallTrace{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), lookup))
+		assert.Equal(t, "POST", lookup.Method)
+		assert.Equal(t, `{"token":"***"}`, lookup.RequestBody)
+		assert.Equal(t, 200, lookup.StatusCode)
+
+		notFound := &APICallTrace{}
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), notFound))
+		assert.Equal(t, 404, notFound.StatusCode)
+		assert.Contains(t, notFound.Error, "Metric not found")
+	}
+}
+
+func Test_redact(t *testing.T) {
+	assert.Equal(t, "/api/v2/metrics?Api-Token=***", redact("/api/v2/metrics?Api-Token=secret", "secret"))
+	assert.Equal(t, "unchanged", redact("unchanged", ""))
+}
@@ -0,0 +1,44 @@
+package dynatrace
+
+import (
+	"fmt"
+	"time"
+)
+
+// ApplicationsClient is a client for looking up APPLICATION entities, i.e. the applications monitored by Real User Monitoring, and their key user actions
+type ApplicationsClient struct {
+	entitiesClient *EntitiesClient
+}
+
+// NewApplicationsClient creates a new ApplicationsClient
+func NewApplicationsClient(client ClientInterface) *ApplicationsClient {
+	return &ApplicationsClient{
+		entitiesClient: NewEntitiesClient(client),
+	}
+}
+
+// GetByEntitySelector returns the applications matching the entity selector, e.g. entityName.equals("sockshop"), that were seen during the timeframe.
+// The properties of the applications, e.g. their application type, are included
+func (c *ApplicationsClient) GetByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return c.entitiesClient.QueryForTimeframe(withEntityType(ApplicationEntityType, entitySelector), []string{"+properties"}, 0, startUnix, endUnix)
+}
+
+// CountByEntitySelector returns the number of applications matching the entity selector that were seen during the timeframe
+func (c *ApplicationsClient) CountByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+	return c.entitiesClient.CountEntitiesForTimeframe(withEntityType(ApplicationEntityType, entitySelector), startUnix, endUnix)
+}
+
+// CountKeyUserActionsByEntitySelector returns the number of key user actions matching the entity selector that were seen during the timeframe
+func (c *ApplicationsClient) CountKeyUserActionsByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+	return c.entitiesClient.CountEntitiesForTimeframe(withEntityType(ApplicationMethodEntityType, entitySelector), startUnix, endUnix)
+}
+
+// GetKeyUserActionsByEntitySelector returns the key user actions, i.e. APPLICATION_METHOD entities, matching the entity selector that were seen during the timeframe
+func (c *ApplicationsClient) GetKeyUserActionsByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return c.entitiesClient.QueryForTimeframe(withEntityType(ApplicationMethodEntityType, entitySelector), nil, 0, startUnix, endUnix)
+}
+
+// GetKeyUserActions returns the key user actions of the application that were seen during the timeframe
+func (c *ApplicationsClient) GetKeyUserActions(applicationID string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return c.GetKeyUserActionsByEntitySelector(fmt.Sprintf("fromRelationships.isApplicationMethodOf(entityId(\"%s\"))", escapeEntitySelectorValue(applicationID)), startUnix, endUnix)
+}
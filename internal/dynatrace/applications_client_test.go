@@ -0,0 +1,28 @@
+package dynatrace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationsClient_GetKeyUserActions(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type%28%22APPLICATION_METHOD%22%29%2CfromRelationships.isApplicationMethodOf%28entityId%28%22APPLICATION-1%22%29%29&from=1571649000000&to=1571649600000",
+		[]byte(`{"totalCount":1,"entities":[{"entityId":"APPLICATION_METHOD-1","type":"APPLICATION_METHOD","displayName":"click on \"Checkout\""}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	start := time.Unix(1571649000, 0)
+	keyUserActions, err := NewApplicationsClient(dtClient).GetKeyUserActions("APPLICATION-1", start, start.Add(10*time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entity{{
+		EntityID:    "APPLICATION_METHOD-1",
+		Type:        ApplicationMethodEntityType,
+		DisplayName: `click on "Checkout"`,
+	}}, keyUserActions)
+}
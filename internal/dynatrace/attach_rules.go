@@ -0,0 +1,122 @@
+package dynatrace
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextlessTagContext is the context of tags that were set manually or via the API in Dynatrace
+const contextlessTagContext = "CONTEXTLESS"
+
+// customTagEnrichmentEntityTypes are the types of entities matched by attach rules that TagMatchingEntities adds tags to
+var customTagEnrichmentEntityTypes = []string{"PROCESS_GROUP", "SERVICE"}
+
+// entitySelectors returns one Entities API v2 entity selector per tag rule and entity type matching the same entities as the AttachRules
+func (ar AttachRules) entitySelectors() []string {
+	return ar.entitySelectorsOfTypes(nil)
+}
+
+// entitySelectorsOfTypes returns the entity selectors of the AttachRules for the given entity types only, or for all types if none are given
+func (ar AttachRules) entitySelectorsOfTypes(entityTypes []string) []string {
+	var selectors []string
+	for _, tagRule := range ar.TagRule {
+		var tags []string
+		for _, tag := range tagRule.Tags {
+			tags = append(tags, fmt.Sprintf("tag(\"%s\")", escapeEntitySelectorValue(getTagSelectorValue(tag))))
+		}
+
+		for _, meType := range tagRule.MeTypes {
+			if len(entityTypes) > 0 && !containsString(entityTypes, meType) {
+				continue
+			}
+			selectors = append(selectors, strings.Join(append([]string{fmt.Sprintf("type(\"%s\")", escapeEntitySelectorValue(meType))}, tags...), ","))
+		}
+	}
+	return selectors
+}
+
+// getTagSelectorValue returns the tag in the format of entity selectors, e.g. [Environment]key:value or key:value for contextless tags
+func getTagSelectorValue(tag TagEntry) string {
+	value := tag.Key
+	if tag.Value != "" {
+		value += ":" + tag.Value
+	}
+	if tag.Context == "" || tag.Context == contextlessTagContext {
+		return value
+	}
+	return "[" + tag.Context + "]" + value
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeEntitySelectorValue escapes the tilde and quotes in a quoted value of an entity selector
+func escapeEntitySelectorValue(value string) string {
+	return strings.NewReplacer("~", "~~", "\"", "~\"").Replace(value)
+}
+
+// matchingEntitiesPageSize is the page size used to retrieve the entities matched by attach rules, which is the maximum of the API
+const matchingEntitiesPageSize = 500
+
+// GetMatchingEntities returns the entities the AttachRules match. Entities matched by several tag rules are only returned once
+func (ec *EntitiesClient) GetMatchingEntities(attachRules AttachRules) ([]Entity, error) {
+	entities := []Entity{}
+	isMatched := make(map[string]bool)
+	for _, selector := range attachRules.entitySelectors() {
+		selectorEntities, err := ec.Query(selector, nil, matchingEntitiesPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entity := range selectorEntities {
+			if isMatched[entity.EntityID] {
+				continue
+			}
+			isMatched[entity.EntityID] = true
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// CountMatchingEntities returns the number of entities the AttachRules match, see GetMatchingEntities
+func (ec *EntitiesClient) CountMatchingEntities(attachRules AttachRules) (int, error) {
+	entities, err := ec.GetMatchingEntities(attachRules)
+	if err != nil {
+		return 0, err
+	}
+	return len(entities), nil
+}
+
+// TagMatchingEntities sets the custom tags on the process group and service entities the AttachRules match and returns the number of matched entities.
+// Custom tags with the same key but another value, e.g. keptn_stage of the previous stage, are replaced, so that entities only have one value per key.
+// Entities matched by several tag rules are counted once per tag rule
+func (ec *EntitiesClient) TagMatchingEntities(attachRules AttachRules, tags []CustomTag) (int, error) {
+	count := 0
+	for _, selector := range attachRules.entitySelectorsOfTypes(customTagEnrichmentEntityTypes) {
+		for _, tag := range tags {
+			_, err := ec.DeleteCustomTags(selector+","+withoutTag(tag), tag.Key)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		selectorCount, err := ec.AddCustomTags(selector, tags)
+		if err != nil {
+			return 0, err
+		}
+		count += selectorCount
+	}
+	return count, nil
+}
+
+// withoutTag returns an entity selector excluding the entities that already have the custom tag
+func withoutTag(tag CustomTag) string {
+	return fmt.Sprintf("not(tag(\"%s\"))", escapeEntitySelectorValue(getTagSelectorValue(TagEntry{Key: tag.Key, Value: tag.Value})))
+}
@@ -0,0 +1,159 @@
+package dynatrace
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachRules_entitySelectors(t *testing.T) {
+	tests := []struct {
+		name        string
+		attachRules AttachRules
+		want        []string
+	}{
+		{
+			name: "contextless tags with and without value",
+			attachRules: AttachRules{TagRule: []TagRule{{
+				MeTypes: []string{"SERVICE"},
+				Tags: []TagEntry{
+					{Context: "CONTEXTLESS", Key: "keptn_project", Value: "sockshop"},
+					{Context: "CONTEXTLESS", Key: "carts"},
+				},
+			}}},
+			want: []string{`type("SERVICE"),tag("keptn_project:sockshop"),tag("carts")`},
+		},
+		{
+			name: "tag with context and several entity types",
+			attachRules: AttachRules{TagRule: []TagRule{{
+				MeTypes: []string{"SERVICE", "PROCESS_GROUP_INSTANCE"},
+				Tags:    []TagEntry{{Context: "ENVIRONMENT", Key: "app", Value: "carts"}},
+			}}},
+			want: []string{`type("SERVICE"),tag("[ENVIRONMENT]app:carts")`, `type("PROCESS_GROUP_INSTANCE"),tag("[ENVIRONMENT]app:carts")`},
+		},
+		{
+			name: "escaped values",
+			attachRules: AttachRules{TagRule: []TagRule{{
+				MeTypes: []string{"HOST"},
+				Tags:    []TagEntry{{Context: "CONTEXTLESS", Key: "owner", Value: `team "a"~b`}},
+			}}},
+			want: []string{`type("HOST"),tag("owner:team ~"a~"~~b")`},
+		},
+		{
+			name: "no tag rules",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.attachRules.entitySelectors())
+		})
+	}
+}
+
+func TestEntitiesClient_CountMatchingEntities(t *testing.T) {
+	var selectors []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, entitiesPath, r.URL.Path)
+
+		// the second page is requested using the nextPageKey only
+		if r.URL.Query().Get("nextPageKey") == "page2" {
+			assert.Empty(t, r.URL.Query().Get("entitySelector"))
+			w.Write([]byte(`{"totalCount": 3, "pageSize": 500, "entities": [{"entityId": "SERVICE-3"}]}`))
+			return
+		}
+
+		assert.Equal(t, "500", r.URL.Query().Get("pageSize"))
+		selector := r.URL.Query().Get("entitySelector")
+		selectors = append(selectors, selector)
+		if selector == `type("SERVICE"),tag("keptn_service:carts")` {
+			w.Write([]byte(`{"totalCount": 3, "pageSize": 500, "nextPageKey": "page2", "entities": [{"entityId": "SERVICE-1"}, {"entityId": "SERVICE-2"}]}`))
+			return
+		}
+		w.Write([]byte(`{"totalCount": 1, "pageSize": 500, "entities": [{"entityId": "SERVICE-2"}]}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	attachRules := AttachRules{TagRule: []TagRule{
+		{
+			MeTypes: []string{"SERVICE"},
+			Tags:    []TagEntry{{Context: "CONTEXTLESS", Key: "keptn_service", Value: "carts"}},
+		},
+		{
+			MeTypes: []string{"SERVICE"},
+			Tags:    []TagEntry{{Context: "CONTEXTLESS", Key: "keptn_stage", Value: "production"}},
+		},
+	}}
+
+	count, err := NewEntitiesClient(dtClient).CountMatchingEntities(attachRules)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count, "all pages are retrieved and entities matched by several tag rules are counted once")
+	assert.Equal(t, []string{`type("SERVICE"),tag("keptn_service:carts")`, `type("SERVICE"),tag("keptn_stage:production")`}, selectors)
+}
+
+func TestEntitiesClient_CountMatchingEntitiesWithError(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error": {"code": 403, "message": "Token is missing required scope"}}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	attachRules := AttachRules{TagRule: []TagRule{{MeTypes: []string{"SERVICE"}}}}
+
+	_, err := NewEntitiesClient(dtClient).CountMatchingEntities(attachRules)
+
+	assert.Error(t, err)
+}
+
+func TestEntitiesClient_TagMatchingEntities(t *testing.T) {
+	var requests []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, customTagsPath, r.URL.Path)
+
+		if r.Method == http.MethodDelete {
+			assert.Equal(t, "true", r.URL.Query().Get("deleteAllWithKey"))
+			requests = append(requests, "DELETE "+r.URL.Query().Get("key")+" "+r.URL.Query().Get("entitySelector"))
+			w.Write([]byte(`{"matchedEntitiesCount": 1}`))
+			return
+		}
+
+		assert.Equal(t, http.MethodPost, r.Method)
+		requests = append(requests, "POST "+r.URL.Query().Get("entitySelector"))
+
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"tags": [{"key": "keptn_project", "value": "sockshop"}, {"key": "keptn_stage", "value": "production"}]}`, string(body))
+
+		w.Write([]byte(`{"matchedEntitiesCount": 3, "appliedTags": [{"key": "keptn_project", "value": "sockshop"}]}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	attachRules := AttachRules{TagRule: []TagRule{{
+		MeTypes: []string{"SERVICE", "PROCESS_GROUP_INSTANCE", "PROCESS_GROUP"},
+		Tags:    []TagEntry{{Context: "ENVIRONMENT", Key: "app", Value: "carts"}},
+	}}}
+
+	count, err := NewEntitiesClient(dtClient).TagMatchingEntities(attachRules, []CustomTag{
+		{Key: "keptn_project", Value: "sockshop"},
+		{Key: "keptn_stage", Value: "production"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, count)
+	assert.Equal(t, []string{
+		`DELETE keptn_project type("SERVICE"),tag("[ENVIRONMENT]app:carts"),not(tag("keptn_project:sockshop"))`,
+		`DELETE keptn_stage type("SERVICE"),tag("[ENVIRONMENT]app:carts"),not(tag("keptn_stage:production"))`,
+		`POST type("SERVICE"),tag("[ENVIRONMENT]app:carts")`,
+		`DELETE keptn_project type("PROCESS_GROUP"),tag("[ENVIRONMENT]app:carts"),not(tag("keptn_project:sockshop"))`,
+		`DELETE keptn_stage type("PROCESS_GROUP"),tag("[ENVIRONMENT]app:carts"),not(tag("keptn_stage:production"))`,
+		`POST type("PROCESS_GROUP"),tag("[ENVIRONMENT]app:carts")`,
+	}, requests)
+}
@@ -43,6 +43,12 @@ type TagNames struct {
 	*StringSet
 }
 
+// AutoTagsClientInterface creates and lists auto-tagging rules
+type AutoTagsClientInterface interface {
+	Create(rule *DTTaggingRule) error
+	GetAllTagNames() (*TagNames, error)
+}
+
 type AutoTagsClient struct {
 	client ClientInterface
 }
@@ -0,0 +1,123 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const serviceToProcessGroupPropagation = "SERVICE_TO_PROCESS_GROUP_LIKE"
+
+type autoTaggingSettings struct {
+	Name  string                    `json:"name"`
+	Rules []autoTaggingSettingsRule `json:"rules"`
+}
+
+type autoTaggingSettingsRule struct {
+	Enabled            bool                          `json:"enabled"`
+	Type               string                        `json:"type"`
+	ValueFormat        string                        `json:"valueFormat"`
+	ValueNormalization string                        `json:"valueNormalization"`
+	AttributeRule      autoTaggingSettingsAttributes `json:"attributeRule"`
+}
+
+type autoTaggingSettingsAttributes struct {
+	EntityType             string                         `json:"entityType"`
+	ServiceToPGPropagation bool                           `json:"serviceToPGPropagation"`
+	Conditions             []autoTaggingSettingsCondition `json:"conditions"`
+}
+
+type autoTaggingSettingsCondition struct {
+	Key              string `json:"key"`
+	Operator         string `json:"operator"`
+	DynamicKey       string `json:"dynamicKey,omitempty"`
+	DynamicKeySource string `json:"dynamicKeySource,omitempty"`
+}
+
+// AutoTagsSettingsClient creates and lists auto-tagging rules using the Settings 2.0 API
+type AutoTagsSettingsClient struct {
+	client *SettingsClient
+}
+
+// NewAutoTagsSettingsClient creates a new AutoTagsSettingsClient
+func NewAutoTagsSettingsClient(client *SettingsClient) *AutoTagsSettingsClient {
+	return &AutoTagsSettingsClient{
+		client: client,
+	}
+}
+
+// Create creates the tagging rule as a settings object of schema builtin:tags.auto-tagging
+func (atc *AutoTagsSettingsClient) Create(rule *DTTaggingRule) error {
+	log.WithField("name", rule.Name).Info("Creating DT tagging rule using Settings 2.0")
+	_, err := atc.client.Create(
+		SettingsObjectCreate{
+			SchemaID: AutoTaggingSchemaID,
+			Scope:    EnvironmentScope,
+			Value:    toAutoTaggingSettings(rule),
+		})
+	return err
+}
+
+// GetAllTagNames returns the names of all auto-tagging rules
+func (atc *AutoTagsSettingsClient) GetAllTagNames() (*TagNames, error) {
+	objects, err := atc.client.List(AutoTaggingSchemaID, EnvironmentScope)
+	if err != nil {
+		log.WithError(err).Error("Could not get existing tagging rules")
+		return nil, err
+	}
+
+	tagNames := &StringSet{
+		values: make(map[string]struct{}, len(objects)),
+	}
+	for _, object := range objects {
+		rule := &autoTaggingSettings{}
+		err := json.Unmarshal(object.Value, rule)
+		if err != nil {
+			log.WithError(err).Error("Failed to unmarshal Dynatrace tagging rules")
+			return nil, fmt.Errorf("could not parse tagging rule %s: %w", object.ObjectID, err)
+		}
+		tagNames.values[rule.Name] = struct{}{}
+	}
+
+	return &TagNames{tagNames}, nil
+}
+
+func toAutoTaggingSettings(rule *DTTaggingRule) *autoTaggingSettings {
+	settings := &autoTaggingSettings{
+		Name: rule.Name,
+	}
+
+	for _, r := range rule.Rules {
+		attributes := autoTaggingSettingsAttributes{
+			EntityType: r.Type,
+		}
+		for _, propagationType := range r.PropagationTypes {
+			if propagationType == serviceToProcessGroupPropagation {
+				attributes.ServiceToPGPropagation = true
+			}
+		}
+		for _, condition := range r.Conditions {
+			attributes.Conditions = append(
+				attributes.Conditions,
+				autoTaggingSettingsCondition{
+					Key:              condition.Key.Attribute,
+					Operator:         condition.ComparisonInfo.Operator,
+					DynamicKey:       condition.Key.DynamicKey.Key,
+					DynamicKeySource: condition.Key.DynamicKey.Source,
+				})
+		}
+
+		settings.Rules = append(
+			settings.Rules,
+			autoTaggingSettingsRule{
+				Enabled:            r.Enabled,
+				Type:               "ME",
+				ValueFormat:        r.ValueFormat,
+				ValueNormalization: "Leave text as-is",
+				AttributeRule:      attributes,
+			})
+	}
+
+	return settings
+}
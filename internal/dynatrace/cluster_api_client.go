@@ -0,0 +1,116 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const clusterEnvironmentsPath = "/api/cluster/v2/environments"
+
+// managedEnvironmentPathSegment precedes the environment ID in URLs of Dynatrace Managed environments, e.g. https://managed.example.com/e/<environment-id>
+const managedEnvironmentPathSegment = "/e/"
+
+// enabledEnvironmentState is the state of Dynatrace Managed environments that are enabled
+const enabledEnvironmentState = "ENABLED"
+
+// ClusterEnvironment is an environment of a Dynatrace Managed cluster
+type ClusterEnvironment struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type clusterEnvironmentsResponse struct {
+	Environments []ClusterEnvironment `json:"environments"`
+	NextPageKey  string               `json:"nextPageKey"`
+}
+
+// ClusterAPIClient is a client for the Cluster API v2 of Dynatrace Managed. The tenant of its client must be the URL of the cluster
+// and its API token a cluster API token with the ServiceProviderAPI scope
+type ClusterAPIClient struct {
+	client ClientInterface
+}
+
+// NewClusterAPIClient creates a new ClusterAPIClient
+func NewClusterAPIClient(client ClientInterface) *ClusterAPIClient {
+	return &ClusterAPIClient{
+		client: client,
+	}
+}
+
+// GetEnvironments returns all environments of the cluster
+func (cc *ClusterAPIClient) GetEnvironments() ([]ClusterEnvironment, error) {
+	var environments []ClusterEnvironment
+	err := NewPager(cc.client, clusterEnvironmentsPath, "").
+		ForEachPage(func(body []byte) error {
+			response := &clusterEnvironmentsResponse{}
+			err := json.Unmarshal(body, response)
+			if err != nil {
+				return fmt.Errorf("could not deserialize cluster environments: %v", err)
+			}
+
+			environments = append(environments, response.Environments...)
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+	return environments, nil
+}
+
+// SelectEnvironments returns the environments whose ID or name is one of the selectors in the order of the selectors.
+// Without selectors, all enabled environments are returned. It returns an error if no environment matches a selector
+func (cc *ClusterAPIClient) SelectEnvironments(selectors []string) ([]ClusterEnvironment, error) {
+	environments, err := cc.GetEnvironments()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(selectors) == 0 {
+		var enabledEnvironments []ClusterEnvironment
+		for _, environment := range environments {
+			if environment.State == enabledEnvironmentState {
+				enabledEnvironments = append(enabledEnvironments, environment)
+			}
+		}
+		return enabledEnvironments, nil
+	}
+
+	selected := make([]ClusterEnvironment, 0, len(selectors))
+	for _, selector := range selectors {
+		environment, found := findClusterEnvironment(environments, selector)
+		if !found {
+			return nil, fmt.Errorf("Dynatrace Managed cluster has no environment with ID or name %s", selector)
+		}
+		selected = append(selected, environment)
+	}
+	return selected, nil
+}
+
+func findClusterEnvironment(environments []ClusterEnvironment, selector string) (ClusterEnvironment, bool) {
+	for _, environment := range environments {
+		if environment.ID == selector {
+			return environment, true
+		}
+	}
+	for _, environment := range environments {
+		if environment.Name == selector {
+			return environment, true
+		}
+	}
+	return ClusterEnvironment{}, false
+}
+
+// GetClusterURL returns the URL of the Dynatrace Managed cluster of an environment URL, e.g. https://managed.example.com for https://managed.example.com/e/<environment-id>
+func GetClusterURL(tenant string) string {
+	if i := strings.Index(tenant, managedEnvironmentPathSegment); i >= 0 {
+		return tenant[:i]
+	}
+	return strings.TrimSuffix(tenant, "/")
+}
+
+// GetEnvironmentURL returns the URL of an environment of a Dynatrace Managed cluster
+func GetEnvironmentURL(clusterURL string, environmentID string) string {
+	return strings.TrimSuffix(clusterURL, "/") + managedEnvironmentPathSegment + environmentID
+}
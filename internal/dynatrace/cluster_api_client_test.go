@@ -0,0 +1,61 @@
+package dynatrace
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterAPIClient_SelectEnvironments(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(clusterEnvironmentsPath, []byte(`{"environments":[{"id":"env-1","name":"production","state":"ENABLED"},{"id":"env-2","name":"staging","state":"DISABLED"}],"nextPageKey":"page-2"}`))
+	handler.AddExact(clusterEnvironmentsPath+"?nextPageKey=page-2", []byte(`{"environments":[{"id":"env-3","name":"hardening","state":"ENABLED"}],"nextPageKey":null}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	tests := []struct {
+		name                   string
+		selectors              []string
+		expectedEnvironmentIDs []string
+		expectedErr            string
+	}{
+		{
+			name:                   "all enabled environments without selectors",
+			expectedEnvironmentIDs: []string{"env-1", "env-3"},
+		},
+		{
+			name:                   "environments selected by ID and name in the order of the selectors",
+			selectors:              []string{"hardening", "env-2"},
+			expectedEnvironmentIDs: []string{"env-3", "env-2"},
+		},
+		{
+			name:        "unknown environment",
+			selectors:   []string{"env-1", "development"},
+			expectedErr: "Dynatrace Managed cluster has no environment with ID or name development",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environments, err := NewClusterAPIClient(dtClient).SelectEnvironments(tt.selectors)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			var environmentIDs []string
+			for _, environment := range environments {
+				environmentIDs = append(environmentIDs, environment.ID)
+			}
+			assert.Equal(t, tt.expectedEnvironmentIDs, environmentIDs)
+		})
+	}
+}
+
+func TestGetClusterURL(t *testing.T) {
+	assert.Equal(t, "https://managed.example.com", GetClusterURL("https://managed.example.com/e/env-1"))
+	assert.Equal(t, "https://managed.example.com", GetClusterURL("https://managed.example.com/"))
+	assert.Equal(t, "https://managed.example.com/e/env-2", GetEnvironmentURL(GetClusterURL("https://managed.example.com/e/env-1"), "env-2"))
+}
@@ -0,0 +1,126 @@
+package dynatrace
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// connectivityCheckConfigAPIPath is requested to check the configuration API if it is served by a separate base URL
+const connectivityCheckConfigAPIPath = "/api/config/v1/managementZones"
+
+// ConnectivityProblem is the kind of problem found by CheckConnectivity
+type ConnectivityProblem string
+
+// the kinds of problems found by CheckConnectivity
+const (
+	// UnreachableProblem means that no response was received, e.g. due to an unknown host, a firewall or a timeout
+	UnreachableProblem ConnectivityProblem = "unreachable"
+	// RoutingProblem means that a response was received, but not from the Dynatrace API, e.g. due to a missing /e/<environment-id> path
+	// or an Environment ActiveGate that cannot reach its Dynatrace cluster
+	RoutingProblem ConnectivityProblem = "routing"
+	// TokenProblem means that the Dynatrace API was reached, but rejected the API token
+	TokenProblem ConnectivityProblem = "token"
+	// ScopeProblem means that the Dynatrace API accepted the API token, but the token lacks a scope required by the check
+	ScopeProblem ConnectivityProblem = "scope"
+)
+
+// ConnectivityError is returned by CheckConnectivity if the Dynatrace API cannot be used with the credentials of the client
+type ConnectivityError struct {
+	problem ConnectivityProblem
+	baseURL string
+	scope   string
+	cause   error
+}
+
+// Problem returns the kind of the problem
+func (e *ConnectivityError) Problem() ConnectivityProblem {
+	return e.problem
+}
+
+// MissingScope returns the scope the API token lacks if the problem is a ScopeProblem, otherwise an empty string
+func (e *ConnectivityError) MissingScope() string {
+	return e.scope
+}
+
+func (e *ConnectivityError) Error() string {
+	switch e.problem {
+	case UnreachableProblem:
+		return fmt.Sprintf("could not reach the Dynatrace environment or ActiveGate at %s, check the URL and that it is reachable from the cluster: %v", e.baseURL, e.cause)
+	case RoutingProblem:
+		return fmt.Sprintf("%s did not respond as the Dynatrace API, if it is an Environment ActiveGate check that the URL ends with /e/<environment-id> and that the ActiveGate is connected to the Dynatrace cluster: %v", e.baseURL, e.cause)
+	case ScopeProblem:
+		if e.cause == nil {
+			return fmt.Sprintf("the Dynatrace API at %s is reachable, but the API token is missing scope %s", e.baseURL, e.scope)
+		}
+		return fmt.Sprintf("the Dynatrace API at %s is reachable, but the API token is missing scope %s: %v", e.baseURL, e.scope, e.cause)
+	default:
+		return fmt.Sprintf("the Dynatrace API at %s is reachable, but rejected the API token: %v", e.baseURL, e.cause)
+	}
+}
+
+// Unwrap returns the error causing the problem
+func (e *ConnectivityError) Unwrap() error {
+	return e.cause
+}
+
+// CheckConnectivity checks that the Dynatrace API is reachable with the credentials of the client by looking up its API token, and returns the token's metadata.
+// If the credentials specify a separate configuration API URL, it is checked as well, which requires scope ReadConfig. Problems are returned as ConnectivityError,
+// distinguishing unreachable hosts and ActiveGate routing problems from rejected tokens and missing scopes
+func CheckConnectivity(client ClientInterface) (*APITokenMetadata, error) {
+	metadata, err := NewAPITokensClient(client).Lookup()
+	if err != nil {
+		return nil, newConnectivityError(client.Credentials().Tenant, err)
+	}
+
+	configAPIURL := client.Credentials().ConfigAPIURL
+	if configAPIURL == "" {
+		return metadata, nil
+	}
+
+	if len(FindMissingScopes([]string{ReadConfigScope}, metadata.Scopes)) > 0 {
+		return nil, &ConnectivityError{problem: ScopeProblem, baseURL: configAPIURL, scope: ReadConfigScope}
+	}
+
+	_, err = client.Get(connectivityCheckConfigAPIPath)
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.Code() == http.StatusForbidden {
+			return nil, &ConnectivityError{problem: ScopeProblem, baseURL: configAPIURL, scope: ReadConfigScope, cause: err}
+		}
+		return nil, newConnectivityError(configAPIURL, err)
+	}
+
+	return metadata, nil
+}
+
+// newConnectivityError classifies the error returned for a request to the base URL, returning it unchanged if it indicates no connectivity problem
+func newConnectivityError(baseURL string, err error) error {
+	var clientErr *ClientError
+	if errors.As(err, &clientErr) {
+		return &ConnectivityError{problem: UnreachableProblem, baseURL: baseURL, cause: err}
+	}
+
+	var unauthorizedErr *UnauthorizedError
+	if errors.As(err, &unauthorizedErr) {
+		return &ConnectivityError{problem: TokenProblem, baseURL: baseURL, cause: err}
+	}
+
+	var notFoundErr *NotFoundError
+	if errors.As(err, &notFoundErr) {
+		return &ConnectivityError{problem: RoutingProblem, baseURL: baseURL, cause: err}
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code() {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return &ConnectivityError{problem: RoutingProblem, baseURL: baseURL, cause: err}
+		default:
+			return err
+		}
+	}
+
+	// a successful response that is no API token metadata, e.g. the HTML page of a proxy, was not served by the Dynatrace API
+	return &ConnectivityError{problem: RoutingProblem, baseURL: baseURL, cause: err}
+}
@@ -0,0 +1,142 @@
+package dynatrace
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const connectivityCheckLookupResponse = `{"id":"dt0c01.ABC","name":"keptn","scopes":["DataExport","ReadConfig","metrics.read"]}`
+
+func TestCheckConnectivity(t *testing.T) {
+	tests := []struct {
+		name            string
+		setupHandler    func(handler *test.PayloadBasedURLHandler)
+		configAPIPath   string
+		expectedProblem ConnectivityProblem
+		expectedScope   string
+		expectedError   bool
+	}{
+		{
+			name: "Environment ActiveGate routes the API",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddExact("/e/abc12345"+apiTokensLookupPath, []byte(connectivityCheckLookupResponse))
+			},
+		},
+		{
+			name: "separate configuration API is reachable",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddExact("/e/abc12345"+apiTokensLookupPath, []byte(connectivityCheckLookupResponse))
+				handler.AddExact("/config"+connectivityCheckConfigAPIPath, []byte(`{"values":[]}`))
+			},
+			configAPIPath: "/config",
+		},
+		{
+			name: "missing environment path",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddStartsWithError("/e/abc12345", http.StatusNotFound, []byte(`<html><body>Not Found</body></html>`))
+			},
+			expectedProblem: RoutingProblem,
+			expectedError:   true,
+		},
+		{
+			name: "ActiveGate not connected to the cluster",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddStartsWithError("/e/abc12345", http.StatusServiceUnavailable, []byte(`Service Unavailable`))
+			},
+			expectedProblem: RoutingProblem,
+			expectedError:   true,
+		},
+		{
+			name: "response of a proxy",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddStartsWith("/e/abc12345", []byte(`<html><body>Login</body></html>`))
+			},
+			expectedProblem: RoutingProblem,
+			expectedError:   true,
+		},
+		{
+			name: "rejected API token",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddStartsWithError("/e/abc12345", http.StatusUnauthorized, []byte(`{"error":{"code":401,"message":"Token Authentication failed"}}`))
+			},
+			expectedProblem: TokenProblem,
+			expectedError:   true,
+		},
+		{
+			name: "API token rejected by the configuration API",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddExact("/e/abc12345"+apiTokensLookupPath, []byte(connectivityCheckLookupResponse))
+				handler.AddExactError("/config"+connectivityCheckConfigAPIPath, http.StatusUnauthorized, []byte(`{"error":{"code":401,"message":"Token Authentication failed"}}`))
+			},
+			configAPIPath:   "/config",
+			expectedProblem: TokenProblem,
+			expectedError:   true,
+		},
+		{
+			name: "API token lacks scope of the configuration API",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddExact("/e/abc12345"+apiTokensLookupPath, []byte(connectivityCheckLookupResponse))
+				handler.AddExactError("/config"+connectivityCheckConfigAPIPath, http.StatusForbidden, []byte(`{"error":{"code":403,"message":"Token is missing required scope"}}`))
+			},
+			configAPIPath:   "/config",
+			expectedProblem: ScopeProblem,
+			expectedScope:   ReadConfigScope,
+			expectedError:   true,
+		},
+		{
+			name: "API token without scope ReadConfig",
+			setupHandler: func(handler *test.PayloadBasedURLHandler) {
+				handler.AddExact("/e/abc12345"+apiTokensLookupPath, []byte(`{"id":"dt0c01.ABC","name":"keptn","scopes":["DataExport","metrics.read"]}`))
+			},
+			configAPIPath:   "/config",
+			expectedProblem: ScopeProblem,
+			expectedScope:   ReadConfigScope,
+			expectedError:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			tt.setupHandler(handler)
+
+			httpClient, url, teardown := test.CreateHTTPSClient(handler)
+			defer teardown()
+
+			dtCredentials := &credentials.DTCredentials{Tenant: url + "/e/abc12345", ApiToken: "test"}
+			if tt.configAPIPath != "" {
+				dtCredentials.ConfigAPIURL = url + tt.configAPIPath
+			}
+
+			metadata, err := CheckConnectivity(NewClientWithHTTP(dtCredentials, httpClient))
+			if !tt.expectedError {
+				assert.NoError(t, err)
+				assert.Equal(t, []string{DataExportScope, ReadConfigScope, MetricsReadScope}, metadata.Scopes)
+				return
+			}
+
+			var connectivityErr *ConnectivityError
+			if assert.True(t, errors.As(err, &connectivityErr)) {
+				assert.Equal(t, tt.expectedProblem, connectivityErr.Problem())
+				assert.Equal(t, tt.expectedScope, connectivityErr.MissingScope())
+			}
+		})
+	}
+}
+
+func TestCheckConnectivity_Unreachable(t *testing.T) {
+	httpClient, url, teardown := test.CreateHTTPSClient(test.NewPayloadBasedURLHandler(t))
+	teardown()
+
+	_, err := CheckConnectivity(NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: "test"}, httpClient))
+
+	var connectivityErr *ConnectivityError
+	if assert.True(t, errors.As(err, &connectivityErr)) {
+		assert.Equal(t, UnreachableProblem, connectivityErr.Problem())
+		assert.Contains(t, err.Error(), "could not reach")
+	}
+}
@@ -1,6 +1,9 @@
 package dynatrace
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	log "github.com/sirupsen/logrus"
 	"strings"
@@ -56,6 +59,7 @@ type Tile struct {
 	AssignedEntities          []string            `json:"assignedEntities,omitempty"`
 	ExcludeMaintenanceWindows bool                `json:"excludeMaintenanceWindows,omitempty"`
 	FilterConfig              *FilterConfig       `json:"filterConfig,omitempty"`
+	VisualConfig              *VisualConfig       `json:"visualConfig,omitempty"`
 }
 
 type Bounds struct {
@@ -87,6 +91,35 @@ type DataExplorerQuery struct {
 	} `json:"filterBy,omitempty"`
 }
 
+// VisualConfig is the visualization configuration of a DATA_EXPLORER dashboard tile
+type VisualConfig struct {
+	Type       string              `json:"type,omitempty"`
+	Global     *VisualConfigGlobal `json:"global,omitempty"`
+	Thresholds []Threshold         `json:"thresholds,omitempty"`
+}
+
+// VisualConfigGlobal contains the global visualization settings of a DATA_EXPLORER dashboard tile. Older dashboards define the threshold here
+type VisualConfigGlobal struct {
+	Theme      string     `json:"theme,omitempty"`
+	Threshold  *Threshold `json:"threshold,omitempty"`
+	SeriesType string     `json:"seriesType,omitempty"`
+}
+
+// Threshold defines the colors of the values of a DATA_EXPLORER dashboard tile.
+// The rules are ordered green, yellow and red, each value being the lower (or upper if the values are descending) bound of the color
+type Threshold struct {
+	AxisTarget string          `json:"axisTarget,omitempty"`
+	QueryID    string          `json:"queryId,omitempty"`
+	Visible    *bool           `json:"visible,omitempty"`
+	Rules      []ThresholdRule `json:"rules"`
+}
+
+// ThresholdRule is a single threshold value and its color. The value is null if it is not set
+type ThresholdRule struct {
+	Value *float64 `json:"value"`
+	Color string   `json:"color"`
+}
+
 type NestedFilterDataExplorer struct {
 	Filter         string                     `json:"filter"`
 	FilterType     string                     `json:"filterType"`
@@ -192,10 +225,49 @@ func (tile Tile) Title() string {
 	return tile.Name
 }
 
+// ToJSON returns the dashboard as indented JSON ending with a newline and without escaped HTML characters, so that stored dashboards result in readable diffs.
+// Keys are ordered deterministically, i.e. as the fields of the structs or, for maps, sorted
+func (dashboard *Dashboard) ToJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(dashboard)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Version returns a short hash of the dashboard's JSON, which changes whenever anything on the dashboard is changed
+func (dashboard *Dashboard) Version() (string, error) {
+	jsonAsByteArray, err := dashboard.ToJSON()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(jsonAsByteArray)
+	return hex.EncodeToString(hash[:])[:12], nil
+}
+
+// GetDashboardVersion returns the version of a dashboard stored as JSON, e.g. by a previous evaluation. As the JSON is parsed first,
+// the version does not depend on how the dashboard was serialized
+func GetDashboardVersion(dashboardContent string) (string, error) {
+	dashboard := &Dashboard{}
+	err := json.Unmarshal([]byte(dashboardContent), dashboard)
+	if err != nil {
+		return "", err
+	}
+
+	return dashboard.Version()
+}
+
 // IsTheSameAs Will validate if the this dashboard is the same as the one passed as parameter
 func (dashboard *Dashboard) IsTheSameAs(existingDashboardContent string) bool {
 
-	jsonAsByteArray, err := json.MarshalIndent(dashboard, "", "  ")
+	jsonAsByteArray, err := dashboard.ToJSON()
 	if err != nil {
 		log.WithError(err).Warn("Could not marshal dashboard")
 	}
@@ -211,6 +283,12 @@ func (dashboard *Dashboard) IsTheSameAs(existingDashboardContent string) bool {
 		return true
 	}
 
+	// dashboards stored by previous versions were serialized without trailing newline and with escaped HTML characters
+	legacyJSONAsByteArray, err := json.MarshalIndent(dashboard, "", "  ")
+	if err == nil && strings.Compare(string(legacyJSONAsByteArray), existingDashboardContent) == 0 {
+		return true
+	}
+
 	return false
 }
 
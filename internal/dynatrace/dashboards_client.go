@@ -2,6 +2,8 @@ package dynatrace
 
 import (
 	"encoding/json"
+	"net/url"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 )
 
@@ -18,7 +20,23 @@ func NewDashboardsClient(client ClientInterface) *DashboardsClient {
 }
 
 func (dc *DashboardsClient) GetAll() (*Dashboards, error) {
-	res, err := dc.client.Get(dashboardsPath)
+	return dc.getDashboards(dashboardsPath)
+}
+
+// GetByOwnerAndTags returns the dashboards of the owner that have all of the tags. If owner is empty, the dashboards of all owners are returned
+func (dc *DashboardsClient) GetByOwnerAndTags(owner string, tags []string) (*Dashboards, error) {
+	query := url.Values{}
+	if owner != "" {
+		query.Set("owner", owner)
+	}
+	for _, tag := range tags {
+		query.Add("tags", tag)
+	}
+	return dc.getDashboards(dashboardsPath + "?" + query.Encode())
+}
+
+func (dc *DashboardsClient) getDashboards(path string) (*Dashboards, error) {
+	res, err := dc.client.Get(path)
 	if err != nil {
 		return nil, err
 	}
@@ -63,6 +81,21 @@ func (dc *DashboardsClient) Create(dashboard *Dashboard) error {
 	return nil
 }
 
+// Update replaces the dashboard with the ID of the given dashboard
+func (dc *DashboardsClient) Update(dashboard *Dashboard) error {
+	dashboardPayload, err := json.Marshal(dashboard)
+	if err != nil {
+		return common.NewMarshalJSONError("Dynatrace dashboard", err)
+	}
+
+	_, err = dc.client.Put(dashboardsPath+"/"+dashboard.ID, dashboardPayload)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (dc *DashboardsClient) Delete(dashboardID string) error {
 	_, err := dc.client.Delete(dashboardsPath + "/" + dashboardID)
 	if err != nil {
@@ -0,0 +1,50 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// dryRunReadPaths are the paths of APIs that only read data although they are called with POST requests
+var dryRunReadPaths = []string{apiTokensLookupPath, apiTokensV1LookupPath}
+
+// isDryRunRequest returns whether the request changes data and is therefore only logged instead of being sent when running locally
+func isDryRunRequest(apiPath string, method string) bool {
+	if method == http.MethodGet {
+		return false
+	}
+
+	path := strings.SplitN(apiPath, "?", 2)[0]
+	for _, readPath := range dryRunReadPaths {
+		if path == readPath {
+			return false
+		}
+	}
+	return true
+}
+
+// newDryRunResponse returns a plausible response to a request that was not sent, so that handlers reading IDs of created objects continue
+// with generated ones: one object ID per settings object created and the ID of the configuration created by any other POST request
+func newDryRunResponse(apiPath string, method string, body []byte) []byte {
+	if method != http.MethodPost {
+		return []byte("{}")
+	}
+
+	if strings.SplitN(apiPath, "?", 2)[0] == settingsObjectsPath {
+		var objects []json.RawMessage
+		_ = json.Unmarshal(body, &objects)
+
+		results := make([]settingsObjectResponse, len(objects))
+		for i := range results {
+			results[i] = settingsObjectResponse{Code: http.StatusOK, ObjectID: uuid.New().String()}
+		}
+		response, _ := json.Marshal(results)
+		return response
+	}
+
+	response, _ := json.Marshal(values{ID: uuid.New().String(), Name: "dry-run"})
+	return response
+}
@@ -4,6 +4,10 @@ const KeptnProject = "keptn_project"
 const KeptnStage = "keptn_stage"
 
 const ServiceEntityType = "SERVICE"
+const ProcessGroupInstanceEntityType = "PROCESS_GROUP_INSTANCE"
+const HostEntityType = "HOST"
+const ApplicationEntityType = "APPLICATION"
+const ApplicationMethodEntityType = "APPLICATION_METHOD"
 
 type listResponse struct {
 	Values []values `json:"values"`
@@ -2,16 +2,27 @@ package dynatrace
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http/httpproxy"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
 )
@@ -71,6 +82,110 @@ func (e *APIError) Error() string {
 	return fmt.Sprintf("Dynatrace API error (%d): %s - URL: %s", e.code, e.message, e.uri)
 }
 
+// ErrorType returns the type of the error
+func (e *APIError) ErrorType() common.ErrorType {
+	return common.DynatraceAPIErrorType
+}
+
+// ConstraintViolations returns the constraint violations reported by the Dynatrace API, if any
+func (e *APIError) ConstraintViolations() ConstraintViolations {
+	if e.details == nil {
+		return nil
+	}
+	return e.details.Error.ConstraintViolations
+}
+
+// NotFoundError is returned if the requested Dynatrace resource, e.g. a metric, SLO or dashboard, does not exist
+type NotFoundError struct {
+	*APIError
+}
+
+func (e *NotFoundError) Error() string {
+	return "Dynatrace resource not found: " + e.APIError.Error()
+}
+
+// Unwrap returns the underlying APIError
+func (e *NotFoundError) Unwrap() error {
+	return e.APIError
+}
+
+// UnauthorizedError is returned if the API token is invalid or lacks a scope required for the request
+type UnauthorizedError struct {
+	*APIError
+}
+
+func (e *UnauthorizedError) Error() string {
+	return "Dynatrace API token is invalid or lacks a required scope: " + e.APIError.Error()
+}
+
+// Unwrap returns the underlying APIError
+func (e *UnauthorizedError) Unwrap() error {
+	return e.APIError
+}
+
+// RateLimitedError is returned if the request was rejected because the rate limit of the Dynatrace API was exceeded
+type RateLimitedError struct {
+	*APIError
+	retryAfter time.Duration
+}
+
+// RetryAfter returns how long to wait before retrying the request as indicated by the Dynatrace API, or 0 if unknown
+func (e *RateLimitedError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func (e *RateLimitedError) Error() string {
+	if e.retryAfter > 0 {
+		return fmt.Sprintf("Dynatrace API rate limit exceeded, retry after %s: %s", e.retryAfter, e.APIError.Error())
+	}
+	return "Dynatrace API rate limit exceeded: " + e.APIError.Error()
+}
+
+// Unwrap returns the underlying APIError
+func (e *RateLimitedError) Unwrap() error {
+	return e.APIError
+}
+
+// newAPIError creates an error for a non-2xx response, which is typed according to the status code.
+// If the response contains the error envelope of the Dynatrace API, its message and constraint violations are included
+func newAPIError(req *http.Request, resp *http.Response, responseBody []byte) error {
+	apiErr := &APIError{
+		code:    resp.StatusCode,
+		message: string(responseBody),
+		uri:     req.URL.String(),
+	}
+
+	dtAPIError := &EnvironmentAPIv2Error{}
+	err := json.Unmarshal(responseBody, dtAPIError)
+	if err == nil && (dtAPIError.Error.Code != 0 || dtAPIError.Error.Message != "") {
+		apiErr.message = dtAPIError.Error.Message
+		apiErr.details = dtAPIError
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return &NotFoundError{apiErr}
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &UnauthorizedError{apiErr}
+	case http.StatusTooManyRequests:
+		return &RateLimitedError{
+			APIError:   apiErr,
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	default:
+		return apiErr
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header given in seconds, returning 0 if it is missing or invalid
+func parseRetryAfter(retryAfter string) time.Duration {
+	seconds, err := strconv.Atoi(retryAfter)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 type ClientError struct {
 	message string
 	cause   error
@@ -80,76 +195,254 @@ func (e *ClientError) Error() string {
 	return fmt.Sprintf("Dynatrace client error: %s [%v]", e.message, e.cause)
 }
 
+// ErrorType returns the type of the error
+func (e *ClientError) ErrorType() common.ErrorType {
+	return common.DynatraceAPIErrorType
+}
+
 type ClientInterface interface {
 	Get(apiPath string) ([]byte, error)
 	Post(apiPath string, body []byte) ([]byte, error)
+	PostPlainText(apiPath string, body []byte) ([]byte, error)
 	Put(apiPath string, body []byte) ([]byte, error)
 	Delete(apiPath string) ([]byte, error)
 
 	Credentials() *credentials.DTCredentials
 }
 
+// StreamingClientInterface is implemented by clients that can pass the body of a response to its consumer while it is being received
+type StreamingClientInterface interface {
+	// GetStream executes the GET request and calls handleBody with the body of a successful response
+	GetStream(apiPath string, handleBody func(body io.Reader) error) error
+}
+
+// getStream executes the GET request using the client and calls handleBody with the body of a successful response.
+// The body is only streamed if the client implements StreamingClientInterface, otherwise it is read completely first
+func getStream(client ClientInterface, apiPath string, handleBody func(body io.Reader) error) error {
+	if streamingClient, ok := client.(StreamingClientInterface); ok {
+		return streamingClient.GetStream(apiPath, handleBody)
+	}
+
+	body, err := client.Get(apiPath)
+	if err != nil {
+		return err
+	}
+	return handleBody(bytes.NewReader(body))
+}
+
 type Client struct {
 	credentials *credentials.DTCredentials
 	httpClient  *http.Client
+	parentSpan  trace.SpanContext
+	timeouts    APITimeouts
+	apiTracing  APICallTracing
+	maxPages    int
+
+	// version of the dynatrace-service sent in the User-Agent header
+	version string
+
+	// dryRun is set when running locally, requests changing data are then only logged and answered with generated responses instead of being sent
+	dryRun bool
+
+	// uninstrumented is set for clients whose requests must neither be traced nor recorded as self-monitoring metrics,
+	// e.g. the client ingesting the self-monitoring metrics, which would otherwise keep producing measurements of itself
+	uninstrumented bool
 }
 
-// NewClient creates a new Client
-func NewClient(dynatraceCreds *credentials.DTCredentials) *Client {
-	return NewClientWithHTTP(
+// NewClient creates a new Client using the TLS options of the configuration
+func NewClient(dynatraceCreds *credentials.DTCredentials, cfg *env.Config) *Client {
+	client, err := NewClientWithTLSOptions(dynatraceCreds, nil, cfg)
+	if err != nil {
+		log.WithError(err).Error("Invalid TLS options for Dynatrace API, only verifying against system certificates")
+		return newClientWithTLSConfig(dynatraceCreds, &tls.Config{InsecureSkipVerify: !cfg.HttpSSLVerificationEnabled}, cfg)
+	}
+	return client
+}
+
+// NewClientWithTLSOptions creates a new Client using the given TLS options, e.g. defined in dynatrace.conf.yaml, and those of the configuration for unset ones
+func NewClientWithTLSOptions(dynatraceCreds *credentials.DTCredentials, options *TLSOptions, cfg *env.Config) (*Client, error) {
+	tlsConfig, err := createTLSConfig(options, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return newClientWithTLSConfig(dynatraceCreds, tlsConfig, cfg), nil
+}
+
+func newClientWithTLSConfig(dynatraceCreds *credentials.DTCredentials, tlsConfig *tls.Config, cfg *env.Config) *Client {
+	client := NewClientWithHTTP(
 		dynatraceCreds,
 		&http.Client{
 			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{
-					InsecureSkipVerify: !env.IsHttpSSLVerificationEnabled()},
-				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+				Proxy:           getProxyFunc(cfg),
 			},
 		},
 	)
+	client.timeouts = NewAPITimeouts(cfg)
+	client.apiTracing = NewAPICallTracing(cfg)
+	client.maxPages = cfg.DynatraceAPIMaxPages
+	client.version = cfg.Version
+	client.dryRun = cfg.RunLocalEnabled
+	return client
+}
+
+// getProxyFunc returns the proxy configuration for requests to the Dynatrace API.
+// If a dedicated proxy for Dynatrace is configured, it is used instead of the proxy used for all outbound requests
+func getProxyFunc(cfg *env.Config) func(*http.Request) (*url.URL, error) {
+	if cfg.DynatraceHTTPProxy == "" && cfg.DynatraceHTTPSProxy == "" {
+		return http.ProxyFromEnvironment
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  cfg.DynatraceHTTPProxy,
+		HTTPSProxy: cfg.DynatraceHTTPSProxy,
+		NoProxy:    cfg.DynatraceNoProxy,
+	}).ProxyFunc()
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFunc(req.URL)
+	}
 }
 
+// NewClientWithHTTP creates a new Client using the given http.Client without timeouts, tracing of API calls or a dry run
 func NewClientWithHTTP(dynatraceCreds *credentials.DTCredentials, httpClient *http.Client) *Client {
 	return &Client{
 		credentials: dynatraceCreds,
 		httpClient:  httpClient,
+		maxPages:    defaultMaxPages,
 	}
 }
 
+// MaxPages returns the maximum number of pages retrieved by a Pager using the client
+func (dt *Client) MaxPages() int {
+	return dt.maxPages
+}
+
+// SetTimeouts sets the timeouts of subsequent requests per family of Dynatrace APIs
+func (dt *Client) SetTimeouts(timeouts APITimeouts) {
+	dt.timeouts = timeouts
+}
+
+const jsonContentType = "application/json"
+const plainTextContentType = "text/plain; charset=utf-8"
+
 func (dt *Client) Get(apiPath string) ([]byte, error) {
-	return dt.sendRequest(apiPath, http.MethodGet, nil)
+	return dt.sendRequest(apiPath, http.MethodGet, nil, jsonContentType)
+}
+
+// GetStream executes the GET request and calls handleBody with the body of a successful response while it is being received.
+// The body is read completely first if the API calls are traced
+func (dt *Client) GetStream(apiPath string, handleBody func(body io.Reader) error) error {
+	_, err := dt.sendRequestWithBodyHandler(apiPath, http.MethodGet, nil, jsonContentType, handleBody)
+	return err
 }
 
 func (dt *Client) Post(apiPath string, body []byte) ([]byte, error) {
-	return dt.sendRequest(apiPath, http.MethodPost, body)
+	return dt.sendRequest(apiPath, http.MethodPost, body, jsonContentType)
+}
+
+// PostPlainText sends a POST request with a plain text body, e.g. for the metrics ingest API
+func (dt *Client) PostPlainText(apiPath string, body []byte) ([]byte, error) {
+	return dt.sendRequest(apiPath, http.MethodPost, body, plainTextContentType)
 }
 
 func (dt *Client) Put(apiPath string, body []byte) ([]byte, error) {
-	return dt.sendRequest(apiPath, http.MethodPut, body)
+	return dt.sendRequest(apiPath, http.MethodPut, body, jsonContentType)
 }
 
 func (dt *Client) Delete(apiPath string) ([]byte, error) {
-	return dt.sendRequest(apiPath, http.MethodDelete, nil)
+	return dt.sendRequest(apiPath, http.MethodDelete, nil, jsonContentType)
 }
 
-// sendRequest makes an Dynatrace API request and returns the response
-func (dt *Client) sendRequest(apiPath string, method string, body []byte) ([]byte, error) {
+// sendRequest makes an Dynatrace API request and returns the response.
+// If the API token is rejected and the credentials contain a secondary API token, the request is retried with the other token
+func (dt *Client) sendRequest(apiPath string, method string, body []byte, contentType string) ([]byte, error) {
+	return dt.sendRequestWithBodyHandler(apiPath, method, body, contentType, nil)
+}
+
+// sendRequestWithBodyHandler makes an Dynatrace API request like sendRequest. If handleBody is set, it is called with the body of a successful response instead of returning it
+func (dt *Client) sendRequestWithBodyHandler(apiPath string, method string, body []byte, contentType string, handleBody func(body io.Reader) error) ([]byte, error) {
+	token := activeAPIToken(dt.credentials)
+	response, statusCode, err := dt.sendRequestWithToken(apiPath, method, body, contentType, token, handleBody)
+	if statusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	alternativeToken, ok := alternativeAPIToken(dt.credentials, token)
+	if !ok {
+		return response, err
+	}
+
+	alternativeResponse, alternativeStatusCode, alternativeErr := dt.sendRequestWithToken(apiPath, method, body, contentType, alternativeToken, handleBody)
+	if alternativeStatusCode == 0 || alternativeStatusCode == http.StatusUnauthorized {
+		return response, err
+	}
+
+	setActiveAPIToken(dt.credentials, alternativeToken)
+	return alternativeResponse, alternativeErr
+}
 
-	req, err := dt.createRequest(apiPath, method, body)
+// sendRequestWithToken makes an Dynatrace API request authorized by the given API token and returns the response and its status code
+func (dt *Client) sendRequestWithToken(apiPath string, method string, body []byte, contentType string, token string, handleBody func(body io.Reader) error) ([]byte, int, error) {
+	req, err := dt.createRequest(apiPath, method, body, contentType, token)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
+	}
+
+	if dt.dryRun && isDryRunRequest(apiPath, method) {
+		log.WithFields(log.Fields{"method": method, "url": req.URL.String(), "body": string(body)}).Info("Not sending Dynatrace API request when running locally")
+		return newDryRunResponse(apiPath, method, body), http.StatusOK, nil
+	}
+
+	if timeout := dt.timeouts.forPath(apiPath); timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
+	if dt.uninstrumented {
+		start := time.Now()
+		response, statusCode, err := dt.doRequest(req, handleBody)
+		dt.apiTracing.traceAPICall(token, req, body, statusCode, response, time.Since(start), err)
+		return response, statusCode, err
 	}
 
-	response, err := dt.doRequest(req)
+	span := tracing.StartSpan(method+" "+req.URL.Path, dt.parentSpan, trace.SpanKindClient)
+	span.SetAttributes(
+		attribute.String("http.method", method),
+		attribute.String("http.url", req.URL.String()),
+	)
+	tracing.InjectHTTPHeaders(req.Header, span.SpanContext())
+
+	start := time.Now()
+	response, statusCode, err := dt.doRequest(req, handleBody)
+	duration := time.Since(start)
+	dt.apiTracing.traceAPICall(token, req, body, statusCode, response, duration, err)
+	selfmonitoring.RecordAPICall(selfmonitoring.DynatraceAPI, duration, err)
+	if statusCode != 0 {
+		span.SetAttributes(attribute.Int("http.status_code", statusCode))
+	}
+	tracing.EndSpan(span, err)
 	if err != nil {
-		return response, err
+		return response, statusCode, err
 	}
 
-	return response, nil
+	return response, statusCode, nil
+}
+
+// DisableInstrumentation stops creating spans and recording self-monitoring metrics for subsequent API calls
+func (dt *Client) DisableInstrumentation() {
+	dt.uninstrumented = true
+}
+
+// SetParentSpan sets the span that the spans of subsequent API calls are children of, e.g. the span of the event being handled
+func (dt *Client) SetParentSpan(parentSpan trace.SpanContext) {
+	dt.parentSpan = parentSpan
 }
 
 // creates http request for api call with appropriate headers including authorization
-func (dt *Client) createRequest(apiPath string, method string, body []byte) (*http.Request, error) {
-	var url = dt.credentials.Tenant + apiPath
+func (dt *Client) createRequest(apiPath string, method string, body []byte, contentType string, token string) (*http.Request, error) {
+	var url = strings.TrimSuffix(getBaseURL(dt.credentials, apiPath), "/") + "/" + strings.TrimPrefix(apiPath, "/")
 
 	log.WithFields(log.Fields{"method": method, "url": url}).Debug("creating Dynatrace API request")
 
@@ -161,53 +454,60 @@ func (dt *Client) createRequest(apiPath string, method string, body []byte) (*ht
 		}
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Api-Token "+dt.credentials.ApiToken)
-	req.Header.Set("User-Agent", "keptn-contrib/dynatrace-service:"+os.Getenv("version"))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Api-Token "+token)
+	req.Header.Set("User-Agent", "keptn-contrib/dynatrace-service:"+dt.version)
 
 	return req, nil
 }
 
-// performs the request and reads the response
-func (dt *Client) doRequest(req *http.Request) ([]byte, error) {
+// configAPIPathPrefix starts the paths of the configuration API, which may be served by a different base URL than the other APIs
+const configAPIPathPrefix = "/api/config/"
+
+// getBaseURL returns the base URL of the API of the path, which is the configuration API URL of the credentials, if any, for the configuration API and the tenant otherwise
+func getBaseURL(dtCredentials *credentials.DTCredentials, apiPath string) string {
+	if dtCredentials.ConfigAPIURL != "" && strings.HasPrefix("/"+strings.TrimPrefix(apiPath, "/"), configAPIPathPrefix) {
+		return dtCredentials.ConfigAPIURL
+	}
+	return dtCredentials.Tenant
+}
+
+// performs the request and reads the response, returning the status code if a response was received.
+// If handleBody is set, it consumes the body of a successful response, which is then only returned if the API call is traced
+func (dt *Client) doRequest(req *http.Request, handleBody func(body io.Reader) error) ([]byte, int, error) {
 	resp, err := dt.httpClient.Do(req)
 	if err != nil {
-		return nil, &ClientError{
-			message: "failed to send request",
+		message := "failed to send request"
+		if errors.Is(req.Context().Err(), context.DeadlineExceeded) {
+			message = "request timed out"
+		}
+		return nil, 0, &ClientError{
+			message: message,
 			cause:   err,
 		}
 	}
 
 	defer resp.Body.Close()
+	if handleBody != nil && resp.StatusCode >= 200 && resp.StatusCode < 300 && !dt.apiTracing.isActive() {
+		return nil, resp.StatusCode, handleBody(resp.Body)
+	}
+
 	responseBody, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, &ClientError{
+		return nil, resp.StatusCode, &ClientError{
 			message: "failed to read response body",
 			cause:   err,
 		}
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-
-		// try to get the error information
-		dtAPIError := &EnvironmentAPIv2Error{}
-		err := json.Unmarshal(responseBody, dtAPIError)
-		if err != nil {
-			return responseBody, &APIError{
-				code:    resp.StatusCode,
-				message: string(responseBody),
-				uri:     req.URL.String(),
-			}
-		}
-		return responseBody, &APIError{
-			code:    dtAPIError.Error.Code,
-			message: dtAPIError.Error.Message,
-			details: dtAPIError,
-			uri:     req.URL.String(),
-		}
+		return responseBody, resp.StatusCode, newAPIError(req, resp, responseBody)
 	}
 
-	return responseBody, nil
+	if handleBody != nil {
+		return responseBody, resp.StatusCode, handleBody(bytes.NewReader(responseBody))
+	}
+	return responseBody, resp.StatusCode, nil
 }
 
 func (dt *Client) Credentials() *credentials.DTCredentials {
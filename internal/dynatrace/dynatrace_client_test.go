@@ -2,14 +2,26 @@ package dynatrace
 
 import (
 	"bytes"
+	"errors"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/test"
 	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
 )
 
 func TestDynatraceHelper_createClient(t *testing.T) {
@@ -87,7 +99,7 @@ func TestDynatraceHelper_createClient(t *testing.T) {
 			os.Setenv("HTTPS_PROXY", tt.proxyEnvVars.httpsProxy)
 			os.Setenv("NO_PROXY", tt.proxyEnvVars.noProxy)
 
-			dt := NewClient(tt.fields.DynatraceCreds)
+			dt := NewClient(tt.fields.DynatraceCreds, env.ReadConfig())
 
 			gotTransport := dt.httpClient.Transport.(*http.Transport)
 			gotProxyUrl, err := gotTransport.Proxy(tt.args.req)
@@ -132,6 +144,57 @@ func TestExecuteDynatraceREST(t *testing.T) {
 	assert.EqualValues(t, expected, actual)
 }
 
+func TestDynatraceClient_GetStream(t *testing.T) {
+	tests := []struct {
+		name         string
+		traceFile    bool
+		statusCode   int
+		wantBody     string
+		wantAPIError bool
+	}{
+		{
+			name:       "body is streamed",
+			statusCode: http.StatusOK,
+			wantBody:   `{"result":[]}`,
+		},
+		{
+			name:       "body is read first if API calls are traced",
+			traceFile:  true,
+			statusCode: http.StatusOK,
+			wantBody:   `{"result":[]}`,
+		},
+		{
+			name:         "body of error is not handled",
+			statusCode:   http.StatusBadRequest,
+			wantAPIError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, teardown := testingDynatraceClient(test.CreateHandler([]byte(`{"result":[]}`), tt.statusCode))
+			defer teardown()
+			if tt.traceFile {
+				client.apiTracing = APICallTracing{TraceFile: filepath.Join(t.TempDir(), "trace.jsonl")}
+			}
+
+			var handledBody string
+			err := client.GetStream("/api/v2/metrics/query", func(body io.Reader) error {
+				content, err := ioutil.ReadAll(body)
+				handledBody = string(content)
+				return err
+			})
+
+			if tt.wantAPIError {
+				var apiErr *APIError
+				assert.True(t, errors.As(err, &apiErr))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantBody, handledBody)
+		})
+	}
+}
+
 func TestExecuteDynatraceRESTBadRequest(t *testing.T) {
 	expected := []byte("my-message")
 	h := test.CreateHandler(expected, 200)
@@ -249,6 +312,105 @@ func TestDynatraceClient(t *testing.T) {
 	}
 }
 
+func TestDynatraceClient_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name                     string
+		statusCode               int
+		header                   map[string]string
+		response                 string
+		wantType                 interface{}
+		wantMessage              string
+		wantConstraintViolations int
+	}{
+		{
+			name:        "not found",
+			statusCode:  http.StatusNotFound,
+			response:    `{"error":{"code":404,"message":"Metric selector contains unknown metric"}}`,
+			wantType:    &NotFoundError{},
+			wantMessage: "Metric selector contains unknown metric",
+		},
+		{
+			name:        "unauthorized",
+			statusCode:  http.StatusUnauthorized,
+			response:    `{"error":{"code":401,"message":"Token Authentication failed"}}`,
+			wantType:    &UnauthorizedError{},
+			wantMessage: "Token Authentication failed",
+		},
+		{
+			name:        "missing scope",
+			statusCode:  http.StatusForbidden,
+			response:    `{"error":{"code":403,"message":"Token is missing required scope"}}`,
+			wantType:    &UnauthorizedError{},
+			wantMessage: "Token is missing required scope",
+		},
+		{
+			name:        "rate limited",
+			statusCode:  http.StatusTooManyRequests,
+			header:      map[string]string{"Retry-After": "30"},
+			response:    `{"error":{"code":429,"message":"Too Many Requests"}}`,
+			wantType:    &RateLimitedError{},
+			wantMessage: "retry after 30s",
+		},
+		{
+			name:                     "constraint violations",
+			statusCode:               http.StatusBadRequest,
+			response:                 `{"error":{"code":400,"message":"Constraints violated.","constraintViolations":[{"path":"resolution","message":"must be a valid resolution"}]}}`,
+			wantType:                 &APIError{},
+			wantMessage:              "must be a valid resolution",
+			wantConstraintViolations: 1,
+		},
+		{
+			name:        "no error envelope",
+			statusCode:  http.StatusBadGateway,
+			response:    `{}`,
+			wantType:    &APIError{},
+			wantMessage: "Dynatrace API error (502)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for key, value := range tt.header {
+					w.Header().Set(key, value)
+				}
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(tt.response))
+			})
+
+			client, teardown := testingDynatraceClient(handler)
+			defer teardown()
+
+			_, err := client.Get("/api/v2/metrics/query")
+			if assert.Error(t, err) {
+				assert.IsType(t, tt.wantType, err)
+				assert.Contains(t, err.Error(), tt.wantMessage)
+
+				var apiErr *APIError
+				if assert.True(t, errors.As(err, &apiErr)) {
+					assert.Equal(t, tt.statusCode, apiErr.Code())
+					assert.Len(t, apiErr.ConstraintViolations(), tt.wantConstraintViolations)
+				}
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	assert.Equal(t, 30*time.Second, parseRetryAfter("30"))
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"))
+}
+
+func Test_getBaseURL(t *testing.T) {
+	activeGateCredentials := &credentials.DTCredentials{Tenant: "https://activegate.example.com:9999/e/abc12345", ConfigAPIURL: "https://abc12345.live.dynatrace.com"}
+	assert.Equal(t, "https://activegate.example.com:9999/e/abc12345", getBaseURL(activeGateCredentials, "/api/v2/metrics/query"))
+	assert.Equal(t, "https://abc12345.live.dynatrace.com", getBaseURL(activeGateCredentials, "/api/config/v1/dashboards"))
+	assert.Equal(t, "https://abc12345.live.dynatrace.com", getBaseURL(activeGateCredentials, "api/config/v1/dashboards"))
+
+	tenantCredentials := &credentials.DTCredentials{Tenant: "https://abc12345.live.dynatrace.com"}
+	assert.Equal(t, "https://abc12345.live.dynatrace.com", getBaseURL(tenantCredentials, "/api/config/v1/dashboards"))
+}
+
 func testingDynatraceClient(handler http.Handler) (*Client, func()) {
 	httpClient, teardown := test.CreateHTTPClient(handler)
 
@@ -261,3 +423,105 @@ func testingDynatraceClient(handler http.Handler) (*Client, func()) {
 
 	return client, teardown
 }
+
+func Test_getProxyFunc_DedicatedProxy(t *testing.T) {
+	proxyFunc := getProxyFunc(&env.Config{
+		DynatraceHTTPSProxy: "http://dynatrace-proxy:8080",
+		DynatraceNoProxy:    "internal.dynatrace.com",
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, "https://mySampleEnv.live.dynatrace.com/api/v2/metrics", nil)
+	proxyURL, err := proxyFunc(req)
+	assert.NoError(t, err)
+	if assert.NotNil(t, proxyURL) {
+		assert.Equal(t, "dynatrace-proxy:8080", proxyURL.Host)
+	}
+
+	req, _ = http.NewRequest(http.MethodGet, "https://internal.dynatrace.com/api/v2/metrics", nil)
+	proxyURL, err = proxyFunc(req)
+	assert.NoError(t, err)
+	assert.Nil(t, proxyURL)
+}
+
+func TestDynatraceClient_DryRunOnlySendsGetRequests(t *testing.T) {
+	var requests []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`{"totalCount":0}`))
+	})
+
+	client, teardown := testingDynatraceClient(h)
+	defer teardown()
+	client.dryRun = true
+
+	response, err := client.Get("/api/v2/metrics")
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"totalCount":0}`, response)
+
+	_, err = client.Post(apiTokensLookupPath, []byte(`{"token":"test"}`))
+	assert.NoError(t, err)
+
+	_, err = client.Delete("/api/config/v1/dashboards/12345")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"GET /api/v2/metrics", "POST " + apiTokensLookupPath}, requests, "only requests reading data are sent")
+}
+
+func TestDynatraceClient_DryRunReturnsGeneratedIDs(t *testing.T) {
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+	})
+
+	client, teardown := testingDynatraceClient(h)
+	defer teardown()
+	client.dryRun = true
+
+	profileID, err := NewAlertingProfilesClient(client).Create(&AlertingProfile{DisplayName: "Keptn"})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, profileID)
+
+	objectIDs, err := NewSettingsClient(client).Create(SettingsObjectCreate{SchemaID: "builtin:tags.auto-tagging", Scope: "environment"}, SettingsObjectCreate{SchemaID: "builtin:tags.auto-tagging", Scope: "environment"})
+	assert.NoError(t, err)
+	if assert.Len(t, objectIDs, 2) {
+		assert.NotEmpty(t, objectIDs[0])
+		assert.NotEqual(t, objectIDs[0], objectIDs[1])
+	}
+
+	response, err := client.Put("/api/config/v1/dashboards/12345", []byte(`{}`))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{}", response)
+}
+
+func TestDynatraceClient_DisableInstrumentation(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousTracerProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previousTracerProvider)
+
+	var traceParents []string
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParents = append(traceParents, r.Header.Get(tracing.TraceParentExtension))
+		w.Write([]byte(`{}`))
+	})
+
+	client, teardown := testingDynatraceClient(h)
+	defer teardown()
+
+	event := cloudevents.NewEvent()
+	event.SetExtension(tracing.TraceParentExtension, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	client.SetParentSpan(tracing.FromEvent(event))
+
+	_, err := client.Get("/api/v2/metrics")
+	assert.NoError(t, err)
+	assert.Len(t, exporter.GetSpans(), 1)
+
+	client.DisableInstrumentation()
+	_, err = client.PostPlainText("/api/v2/metrics/ingest", []byte("keptn.dynatrace_service.events_handled,event_type=test count,delta=1"))
+	assert.NoError(t, err)
+	assert.Len(t, exporter.GetSpans(), 1)
+
+	if assert.Len(t, traceParents, 2) {
+		assert.NotEmpty(t, traceParents[0])
+		assert.Empty(t, traceParents[1])
+	}
+}
@@ -3,10 +3,16 @@ package dynatrace
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 )
 
 const entitiesPath = "/api/v2/entities"
+const customTagsPath = "/api/v2/tags"
 
 // EntitiesResponse represents the response from Dynatrace entities endpoints
 type EntitiesResponse struct {
@@ -24,11 +30,58 @@ type Tag struct {
 	Value                string `json:"value,omitempty"`
 }
 
-// Entity represents a Dynatrace entity
+// CustomTag is a custom tag that can be added to Dynatrace entities using the API
+type CustomTag struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type addCustomTagsRequest struct {
+	Tags []CustomTag `json:"tags"`
+}
+
+// addCustomTagsResponse is the response of adding or deleting custom tags
+type addCustomTagsResponse struct {
+	MatchedEntitiesCount int `json:"matchedEntitiesCount"`
+}
+
+// Entity represents a Dynatrace entity. Besides its ID and display name, only the fields requested by the query are set
 type Entity struct {
-	EntityID    string `json:"entityId"`
-	DisplayName string `json:"displayName"`
-	Tags        []Tag  `json:"tags"`
+	EntityID        string                 `json:"entityId"`
+	Type            string                 `json:"type,omitempty"`
+	DisplayName     string                 `json:"displayName"`
+	Tags            []Tag                  `json:"tags"`
+	FirstSeenTms    int64                  `json:"firstSeenTms,omitempty"`
+	LastSeenTms     int64                  `json:"lastSeenTms,omitempty"`
+	ManagementZones []EntityManagementZone `json:"managementZones,omitempty"`
+	Properties      *EntityProperties      `json:"properties,omitempty"`
+}
+
+// EntityManagementZone is a management zone an entity belongs to
+type EntityManagementZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// EntityProperties contains the properties of SERVICE, PROCESS_GROUP_INSTANCE, HOST and APPLICATION entities. Only the properties of the type of the entity are set
+type EntityProperties struct {
+	// ServiceType is the type of a SERVICE, e.g. WEB_REQUEST
+	ServiceType string `json:"serviceType,omitempty"`
+
+	// ProcessType is the type of a PROCESS_GROUP_INSTANCE, e.g. JAVA
+	ProcessType string `json:"processType,omitempty"`
+	// ListenPorts are the ports a PROCESS_GROUP_INSTANCE listens on
+	ListenPorts []int `json:"listenPorts,omitempty"`
+
+	// OSType is the operating system of a HOST, e.g. LINUX
+	OSType string `json:"osType,omitempty"`
+	// MonitoringMode is the monitoring mode of the OneAgent on a HOST, e.g. FULL_STACK
+	MonitoringMode string `json:"monitoringMode,omitempty"`
+	// IPAddresses are the IP addresses of a HOST
+	IPAddresses []string `json:"ipAddress,omitempty"`
+
+	// ApplicationType is the type of an APPLICATION, e.g. WEB_APPLICATION
+	ApplicationType string `json:"applicationType,omitempty"`
 }
 
 // EntitiesClient is a client for interacting with the Dynatrace entities endpoints
@@ -45,35 +98,148 @@ func NewEntitiesClient(client ClientInterface) *EntitiesClient {
 
 // GetKeptnManagedServices gets all service entities with a keptn_managed and keptn_service tag
 func (ec *EntitiesClient) GetKeptnManagedServices() ([]Entity, error) {
+	// TODO 2021-08-20: Investigate if pageSize should be optimized or removed
+	return ec.Query("type(\"SERVICE\") AND tag(\"keptn_managed\",\"[Environment]keptn_managed\") AND tag(\"keptn_service\",\"[Environment]keptn_service\")", []string{"+tags"}, 50)
+}
+
+// GetByIDs returns the entities with the given IDs including the requested fields, see Query
+func (ec *EntitiesClient) GetByIDs(entityIDs []string, fields []string) ([]Entity, error) {
+	if len(entityIDs) == 0 {
+		return []Entity{}, nil
+	}
+
+	quotedIDs := make([]string, 0, len(entityIDs))
+	for _, entityID := range entityIDs {
+		quotedIDs = append(quotedIDs, fmt.Sprintf("\"%s\"", escapeEntitySelectorValue(entityID)))
+	}
+	return ec.Query(fmt.Sprintf("entityId(%s)", strings.Join(quotedIDs, ",")), fields, 0)
+}
+
+// Query returns all entities matching the entity selector, retrieving all pages of the given size. The fields, e.g. +tags or +properties, are requested
+// in addition to the ID and display name of the entities. A pageSize of 0 or less uses the default page size of the API
+func (ec *EntitiesClient) Query(entitySelector string, fields []string, pageSize int) ([]Entity, error) {
+	return ec.queryAllPages(createEntitiesQuery(entitySelector, fields, pageSize))
+}
+
+// QueryForTimeframe returns all entities matching the entity selector that were seen during the timeframe, see Query.
+// Without a timeframe, the API only returns entities seen during the last three days
+func (ec *EntitiesClient) QueryForTimeframe(entitySelector string, fields []string, pageSize int, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return ec.queryAllPages(createEntitiesQuery(entitySelector, fields, pageSize) + "&" + createTimeframeQuery(startUnix, endUnix))
+}
+
+func (ec *EntitiesClient) queryAllPages(query string) ([]Entity, error) {
 	entities := []Entity{}
-	nextPageKey := ""
+	err := NewPager(ec.Client, entitiesPath, query).
+		ForEachPage(func(body []byte) error {
+			entitiesResponse := &EntitiesResponse{}
+			err := json.Unmarshal(body, entitiesResponse)
+			if err != nil {
+				return fmt.Errorf("could not deserialize EntitiesResponse: %v", err)
+			}
 
-	// TODO 2021-08-20: Investigate if pageSize should be optimized or removed
-	pageSize := 50
-	for {
-		var response []byte
-		var err error
-
-		if nextPageKey == "" {
-			response, err = ec.Client.Get(entitiesPath + "?entitySelector=type(\"SERVICE\")%20AND%20tag(\"keptn_managed\",\"[Environment]keptn_managed\")%20AND%20tag(\"keptn_service\",\"[Environment]keptn_service\")&fields=+tags&pageSize=" + strconv.FormatInt(int64(pageSize), 10))
-		} else {
-			response, err = ec.Client.Get(entitiesPath + "?nextPageKey=" + nextPageKey)
-		}
-		if err != nil {
-			return nil, err
-		}
-
-		entitiesResponse := &EntitiesResponse{}
-		err = json.Unmarshal(response, entitiesResponse)
-		if err != nil {
-			return nil, fmt.Errorf("could not deserialize EntitiesResponse: %v", err)
-		}
-
-		entities = append(entities, entitiesResponse.Entities...)
-		if entitiesResponse.NextPageKey == "" {
-			break
-		}
-		nextPageKey = entitiesResponse.NextPageKey
+			entities = append(entities, entitiesResponse.Entities...)
+			return nil
+		})
+	if err != nil {
+		return nil, err
 	}
 	return entities, nil
 }
+
+// CountEntities returns the number of entities matching the entity selector
+func (ec *EntitiesClient) CountEntities(entitySelector string) (int, error) {
+	return ec.countEntities(createEntitiesQuery(entitySelector, nil, 1))
+}
+
+// CountEntitiesForTimeframe returns the number of entities matching the entity selector that were seen during the timeframe, see QueryForTimeframe
+func (ec *EntitiesClient) CountEntitiesForTimeframe(entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+	return ec.countEntities(createEntitiesQuery(entitySelector, nil, 1) + "&" + createTimeframeQuery(startUnix, endUnix))
+}
+
+// countEntities returns the total count of the entities of the query, of which only the first page of a single entity is retrieved
+func (ec *EntitiesClient) countEntities(query string) (int, error) {
+	body, err := ec.Client.Get(entitiesPath + "?" + query)
+	if err != nil {
+		return 0, err
+	}
+
+	entitiesResponse := &EntitiesResponse{}
+	err = json.Unmarshal(body, entitiesResponse)
+	if err != nil {
+		return 0, fmt.Errorf("could not deserialize EntitiesResponse: %v", err)
+	}
+	return entitiesResponse.TotalCount, nil
+}
+
+// withEntityType returns the entity selector restricted to entities of the type
+func withEntityType(entityType string, entitySelector string) string {
+	typeSelector := fmt.Sprintf("type(\"%s\")", entityType)
+	if entitySelector == "" {
+		return typeSelector
+	}
+	return typeSelector + "," + entitySelector
+}
+
+// createEntitiesQuery returns the query of the first page of entities matching the entity selector
+func createEntitiesQuery(entitySelector string, fields []string, pageSize int) string {
+	query := url.Values{}
+	query.Set("entitySelector", entitySelector)
+	if len(fields) > 0 {
+		query.Set("fields", strings.Join(fields, ","))
+	}
+	if pageSize > 0 {
+		query.Set("pageSize", strconv.Itoa(pageSize))
+	}
+	return query.Encode()
+}
+
+// createTimeframeQuery returns the query parameters restricting the entities to those seen during the timeframe
+func createTimeframeQuery(startUnix time.Time, endUnix time.Time) string {
+	query := url.Values{}
+	query.Set("from", common.TimestampToString(startUnix))
+	query.Set("to", common.TimestampToString(endUnix))
+	return query.Encode()
+}
+
+// AddCustomTags adds the custom tags to all entities matching the entity selector and returns the number of matched entities
+func (ec *EntitiesClient) AddCustomTags(entitySelector string, tags []CustomTag) (int, error) {
+	query := url.Values{}
+	query.Set("entitySelector", entitySelector)
+
+	payload, err := json.Marshal(addCustomTagsRequest{Tags: tags})
+	if err != nil {
+		return 0, fmt.Errorf("could not marshal custom tags: %w", err)
+	}
+
+	body, err := ec.Client.Post(customTagsPath+"?"+query.Encode(), payload)
+	if err != nil {
+		return 0, err
+	}
+
+	response := &addCustomTagsResponse{}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		return 0, fmt.Errorf("could not deserialize custom tags response: %v", err)
+	}
+	return response.MatchedEntitiesCount, nil
+}
+
+// DeleteCustomTags deletes all custom tags with the key, whatever their value, from all entities matching the entity selector and returns the number of matched entities
+func (ec *EntitiesClient) DeleteCustomTags(entitySelector string, key string) (int, error) {
+	query := url.Values{}
+	query.Set("entitySelector", entitySelector)
+	query.Set("key", key)
+	query.Set("deleteAllWithKey", "true")
+
+	body, err := ec.Client.Delete(customTagsPath + "?" + query.Encode())
+	if err != nil {
+		return 0, err
+	}
+
+	response := &addCustomTagsResponse{}
+	err = json.Unmarshal(body, response)
+	if err != nil {
+		return 0, fmt.Errorf("could not deserialize custom tags response: %v", err)
+	}
+	return response.MatchedEntitiesCount, nil
+}
@@ -8,6 +8,9 @@ import (
 
 	"github.com/go-test/deep"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestEntitiesClient_GetKeptnManagedServices(t *testing.T) {
@@ -42,7 +45,8 @@ func TestEntitiesClient_GetKeptnManagedServices(t *testing.T) {
 					&credentials.DTCredentials{
 						Tenant:   dtMockServer.URL,
 						ApiToken: "",
-					}),
+					},
+					env.ReadConfig()),
 			},
 			args: args{
 				nextPageKey: "",
@@ -116,3 +120,88 @@ func TestEntitiesClient_GetKeptnManagedServices(t *testing.T) {
 		})
 	}
 }
+
+func TestEntitiesClient_Query(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type%28%22HOST%22%29%2Ctag%28%22keptn_project%3Asockshop%22%29&fields=%2Bproperties%2C%2BmanagementZones&pageSize=2",
+		[]byte(`{"totalCount":3,"pageSize":2,"nextPageKey":"next-page","entities":[
+			{"entityId":"HOST-1","type":"HOST","displayName":"host-1","properties":{"osType":"LINUX","monitoringMode":"FULL_STACK","ipAddress":["10.0.0.1"]},"managementZones":[{"id":"123","name":"sockshop"}]},
+			{"entityId":"HOST-2","type":"HOST","displayName":"host-2","properties":{"osType":"WINDOWS","monitoringMode":"INFRASTRUCTURE"}}]}`))
+	handler.AddExact(entitiesPath+"?nextPageKey=next-page",
+		[]byte(`{"totalCount":3,"pageSize":2,"entities":[{"entityId":"HOST-3","type":"HOST","displayName":"host-3","properties":{"osType":"LINUX"}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	entities, err := NewEntitiesClient(dtClient).Query(`type("HOST"),tag("keptn_project:sockshop")`, []string{"+properties", "+managementZones"}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entity{
+		{
+			EntityID:        "HOST-1",
+			Type:            HostEntityType,
+			DisplayName:     "host-1",
+			ManagementZones: []EntityManagementZone{{ID: "123", Name: "sockshop"}},
+			Properties:      &EntityProperties{OSType: "LINUX", MonitoringMode: "FULL_STACK", IPAddresses: []string{"10.0.0.1"}},
+		},
+		{
+			EntityID:    "HOST-2",
+			Type:        HostEntityType,
+			DisplayName: "host-2",
+			Properties:  &EntityProperties{OSType: "WINDOWS", MonitoringMode: "INFRASTRUCTURE"},
+		},
+		{
+			EntityID:    "HOST-3",
+			Type:        HostEntityType,
+			DisplayName: "host-3",
+			Properties:  &EntityProperties{OSType: "LINUX"},
+		},
+	}, entities)
+}
+
+func TestEntitiesClient_GetByIDs(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=entityId%28%22SERVICE-1%22%2C%22HOST-1%22%29&fields=%2Btags",
+		[]byte(`{"totalCount":2,"entities":[
+			{"entityId":"SERVICE-1","displayName":"carts","tags":[{"context":"CONTEXTLESS","key":"owner","value":"team-a","stringRepresentation":"owner:team-a"}]},
+			{"entityId":"HOST-1","displayName":"host-1","tags":[]}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	entities, err := NewEntitiesClient(dtClient).GetByIDs([]string{"SERVICE-1", "HOST-1"}, []string{"+tags"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entity{
+		{
+			EntityID:    "SERVICE-1",
+			DisplayName: "carts",
+			Tags:        []Tag{{Context: "CONTEXTLESS", Key: "owner", Value: "team-a", StringRepresentation: "owner:team-a"}},
+		},
+		{
+			EntityID:    "HOST-1",
+			DisplayName: "host-1",
+			Tags:        []Tag{},
+		},
+	}, entities)
+}
+
+func TestEntitiesClient_QueryTypedProperties(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWith(entitiesPath, []byte(`{"totalCount":3,"entities":[
+		{"entityId":"SERVICE-1","type":"SERVICE","displayName":"carts","properties":{"serviceType":"WEB_REQUEST"}},
+		{"entityId":"PROCESS_GROUP_INSTANCE-1","type":"PROCESS_GROUP_INSTANCE","displayName":"carts-pod","properties":{"processType":"JAVA","listenPorts":[8080]}},
+		{"entityId":"APPLICATION-1","type":"APPLICATION","displayName":"sockshop.example.com","properties":{"applicationType":"WEB_APPLICATION"}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	entities, err := NewEntitiesClient(dtClient).Query(`tag("keptn_project:sockshop")`, []string{"+properties"}, 0)
+
+	assert.NoError(t, err)
+	if assert.Len(t, entities, 3) {
+		assert.Equal(t, &EntityProperties{ServiceType: "WEB_REQUEST"}, entities[0].Properties)
+		assert.Equal(t, &EntityProperties{ProcessType: "JAVA", ListenPorts: []int{8080}}, entities[1].Properties)
+		assert.Equal(t, &EntityProperties{ApplicationType: "WEB_APPLICATION"}, entities[2].Properties)
+	}
+}
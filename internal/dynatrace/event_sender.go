@@ -0,0 +1,228 @@
+package dynatrace
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+// eventRetryDelay is the delay before the first retry of an event the Dynatrace API did not accept, doubled for each further retry
+const eventRetryDelay = 5 * time.Second
+
+// maxEventRetryDelay limits the exponential backoff between two attempts of sending an event
+const maxEventRetryDelay = 1 * time.Minute
+
+var defaultAsyncEventSender *AsyncEventSender
+var defaultAsyncEventSenderOnce sync.Once
+
+type queuedEvent struct {
+	client  ClientInterface
+	dtEvent interface{}
+
+	// onSent is called with whether the Dynatrace API accepted the event once it was sent or given up, if set
+	onSent func(accepted bool)
+
+	// attempt is the number of attempts of sending the event so far
+	attempt int
+	// retryDelay is the delay before the next retry of the event
+	retryDelay time.Duration
+}
+
+// AsyncEventSender sends Dynatrace events in the background, so that event handlers are not blocked by the latency of the Dynatrace API.
+// Queued events are taken in batches and the events of a batch are sent concurrently. Events the Dynatrace API did not accept are queued again
+// after a delay, so that retries do not hold up other events. If the bounded queue is full, events are sent synchronously and are not retried
+type AsyncEventSender struct {
+	queue      chan queuedEvent
+	batchSize  int
+	maxRetries int
+	retryDelay time.Duration
+	afterFunc  func(d time.Duration, f func())
+
+	// pending is the number of queued events that were not sent or given up yet and idle is closed while there are none. Unlike a
+	// sync.WaitGroup, events may be queued while Flush is waiting
+	mutex   sync.Mutex
+	pending int
+	idle    chan struct{}
+}
+
+// GetDefaultAsyncEventSender returns the AsyncEventSender shared by all events clients. It is created using the configuration of the first call
+func GetDefaultAsyncEventSender(cfg *env.Config) *AsyncEventSender {
+	defaultAsyncEventSenderOnce.Do(func() {
+		defaultAsyncEventSender = NewAsyncEventSender(cfg.EventQueueSize, cfg.EventBatchSize, cfg.EventMaxRetries)
+	})
+
+	return defaultAsyncEventSender
+}
+
+// NewAsyncEventSender creates a new AsyncEventSender and starts sending queued events. If queueSize is 0 or less, events are sent synchronously
+func NewAsyncEventSender(queueSize int, batchSize int, maxRetries int) *AsyncEventSender {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	s := &AsyncEventSender{
+		batchSize:  batchSize,
+		maxRetries: maxRetries,
+		retryDelay: eventRetryDelay,
+		afterFunc: func(d time.Duration, f func()) {
+			time.AfterFunc(d, f)
+		},
+		idle: make(chan struct{}),
+	}
+	close(s.idle)
+	if queueSize > 0 {
+		s.queue = make(chan queuedEvent, queueSize)
+		go s.run()
+	}
+	return s
+}
+
+// IsEnabled returns whether events are sent in the background
+func (s *AsyncEventSender) IsEnabled() bool {
+	return s.queue != nil
+}
+
+// Enqueue queues the event for sending in the background and returns whether it was queued. It is not queued if asynchronous sending
+// is disabled or the queue is full
+func (s *AsyncEventSender) Enqueue(client ClientInterface, dtEvent interface{}) bool {
+	return s.enqueue(queuedEvent{client: client, dtEvent: dtEvent})
+}
+
+func (s *AsyncEventSender) enqueue(e queuedEvent) bool {
+	if !s.IsEnabled() {
+		return false
+	}
+
+	e.retryDelay = s.retryDelay
+	s.addPending()
+	select {
+	case s.queue <- e:
+		selfmonitoring.RecordEventQueueDepth(len(s.queue))
+		return true
+	default:
+		s.removePending()
+		log.WithField("queueSize", cap(s.queue)).Warn("Dynatrace event queue is full, sending event synchronously")
+		return false
+	}
+}
+
+// Flush waits until all queued events, including their retries, were sent or given up, or until the context is done
+func (s *AsyncEventSender) Flush(ctx context.Context) error {
+	s.mutex.Lock()
+	idle := s.idle
+	s.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run takes batches of queued events and sends the events of each batch concurrently. Each event is only sent once per batch,
+// events that have to be retried are queued again by send
+func (s *AsyncEventSender) run() {
+	for event := range s.queue {
+		batch := []queuedEvent{event}
+		for len(batch) < s.batchSize && len(s.queue) > 0 {
+			batch = append(batch, <-s.queue)
+		}
+		selfmonitoring.RecordEventQueueDepth(len(s.queue))
+
+		var wg sync.WaitGroup
+		for _, e := range batch {
+			wg.Add(1)
+			go func(e queuedEvent) {
+				defer wg.Done()
+				s.send(e)
+			}(e)
+		}
+		wg.Wait()
+	}
+}
+
+// send sends the event once. If the Dynatrace API did not accept it and it may be accepted later, it is queued again after the retry delay,
+// otherwise the event is done
+func (s *AsyncEventSender) send(e queuedEvent) {
+	body, err := (&EventsClient{client: e.client}).addEvent(e.dtEvent)
+	if err == nil {
+		log.WithField("body", body).Debug("Dynatrace API has accepted the queued event")
+		s.done(e, true)
+		return
+	}
+
+	if e.attempt >= s.maxRetries || !isRetryableEventError(err) {
+		log.WithError(err).WithField("retries", e.attempt).Error("Failed sending queued Dynatrace event")
+		s.done(e, false)
+		return
+	}
+
+	log.WithError(err).WithField("attempt", e.attempt+1).Warn("Could not send queued Dynatrace event, retrying")
+	delay := e.retryDelay
+	var rateLimitedErr *RateLimitedError
+	if errors.As(err, &rateLimitedErr) && rateLimitedErr.RetryAfter() > delay {
+		delay = rateLimitedErr.RetryAfter()
+	}
+
+	e.attempt++
+	e.retryDelay = delay * 2
+	if e.retryDelay > maxEventRetryDelay {
+		e.retryDelay = maxEventRetryDelay
+	}
+
+	// the event stays pending until it is done, the queue is drained by run, so queuing it again blocks at most until there is space
+	s.afterFunc(delay, func() {
+		s.queue <- e
+		selfmonitoring.RecordEventQueueDepth(len(s.queue))
+	})
+}
+
+// done notifies about the outcome of sending the event, if required, and marks it as no longer pending
+func (s *AsyncEventSender) done(e queuedEvent, accepted bool) {
+	defer s.removePending()
+	if e.onSent != nil {
+		e.onSent(accepted)
+	}
+}
+
+// addPending marks a queued event as pending
+func (s *AsyncEventSender) addPending() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.pending == 0 {
+		s.idle = make(chan struct{})
+	}
+	s.pending++
+}
+
+// removePending marks a queued event as sent or given up and signals Flush once no event is pending anymore
+func (s *AsyncEventSender) removePending() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.pending--
+	if s.pending == 0 {
+		close(s.idle)
+	}
+}
+
+// isRetryableEventError returns whether sending the event again may succeed, i.e. it was not rejected because of the API token or the event itself
+func isRetryableEventError(err error) bool {
+	var unauthorizedErr *UnauthorizedError
+	var notFoundErr *NotFoundError
+	var apiErr *APIError
+	if errors.As(err, &unauthorizedErr) || errors.As(err, &notFoundErr) {
+		return false
+	}
+	if errors.As(err, &apiErr) && apiErr.Code() == 400 {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,211 @@
+package dynatrace
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncEventSender_Enqueue(t *testing.T) {
+	var mutex sync.Mutex
+	var methods []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, eventsPath, r.URL.Path)
+		mutex.Lock()
+		methods = append(methods, r.Method)
+		mutex.Unlock()
+		w.Write([]byte(`{"storedEventIds": [1]}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	sender := NewAsyncEventSender(10, 2, 0)
+	for i := 0; i < 5; i++ {
+		assert.True(t, sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType}))
+	}
+	sender.Flush(context.Background())
+
+	assert.Equal(t, []string{"POST", "POST", "POST", "POST", "POST"}, methods)
+}
+
+func TestAsyncEventSender_EnqueueDisabled(t *testing.T) {
+	sender := NewAsyncEventSender(0, 10, 3)
+
+	assert.False(t, sender.IsEnabled())
+	assert.False(t, sender.Enqueue(nil, InfoEvent{EventType: InfoEventType}))
+}
+
+func TestAsyncEventSender_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCodes  []int
+		maxRetries   int
+		wantRequests int
+	}{
+		{
+			name:         "accepted after retry",
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			maxRetries:   3,
+			wantRequests: 2,
+		},
+		{
+			name:         "given up after max retries",
+			statusCodes:  []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries:   2,
+			wantRequests: 3,
+		},
+		{
+			name:         "unauthorized is not retried",
+			statusCodes:  []int{http.StatusUnauthorized},
+			maxRetries:   3,
+			wantRequests: 1,
+		},
+		{
+			name:         "invalid event is not retried",
+			statusCodes:  []int{http.StatusBadRequest},
+			maxRetries:   3,
+			wantRequests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				statusCode := tt.statusCodes[requests]
+				requests++
+				w.WriteHeader(statusCode)
+				w.Write([]byte(`{}`))
+			})
+
+			dtClient, _, teardown := createDynatraceClient(handler)
+			defer teardown()
+
+			var delays []time.Duration
+			sender := NewAsyncEventSender(10, 1, tt.maxRetries)
+			sender.afterFunc = func(d time.Duration, f func()) {
+				delays = append(delays, d)
+				go f()
+			}
+
+			assert.True(t, sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType}))
+			sender.Flush(context.Background())
+
+			assert.Equal(t, tt.wantRequests, requests)
+			assert.Equal(t, tt.wantRequests-1, len(delays))
+		})
+	}
+}
+
+func TestAsyncEventSender_EnqueueFullQueue(t *testing.T) {
+	release := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`{}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	sender := NewAsyncEventSender(1, 1, 0)
+	queued := 0
+	for i := 0; i < 5; i++ {
+		if sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType}) {
+			queued++
+		}
+	}
+	close(release)
+	sender.Flush(context.Background())
+
+	assert.Less(t, queued, 5)
+}
+
+// Tests that an event waiting for its retry neither blocks other events nor is dropped by Flush before it is done
+func TestAsyncEventSender_RetryDoesNotBlockOtherEvents(t *testing.T) {
+	var mutex sync.Mutex
+	var titles []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		event := InfoEvent{}
+		assert.NoError(t, json.Unmarshal(body, &event))
+
+		mutex.Lock()
+		titles = append(titles, event.Title)
+		attempts := len(titles)
+		mutex.Unlock()
+
+		if event.Title == "retried" && attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		w.Write([]byte(`{}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	retries := make(chan func(), 1)
+	sender := NewAsyncEventSender(10, 1, 3)
+	sender.afterFunc = func(d time.Duration, f func()) {
+		retries <- f
+	}
+
+	assert.True(t, sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType, Title: "retried"}))
+	retry := <-retries
+	assert.True(t, sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType, Title: "other"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, sender.Flush(ctx), "retried event is still pending")
+
+	mutex.Lock()
+	assert.Equal(t, []string{"retried", "other"}, titles)
+	mutex.Unlock()
+
+	retry()
+	assert.NoError(t, sender.Flush(context.Background()))
+	assert.Equal(t, []string{"retried", "other", "retried"}, titles)
+}
+
+// Tests that events can be queued while Flush is waiting and that Flush returns once all of them were sent
+func TestAsyncEventSender_EnqueueWhileFlushing(t *testing.T) {
+	var mutex sync.Mutex
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests++
+		mutex.Unlock()
+		w.Write([]byte(`{}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	sender := NewAsyncEventSender(100, 5, 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				assert.True(t, sender.Enqueue(dtClient, InfoEvent{EventType: InfoEventType}))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, sender.Flush(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	assert.NoError(t, sender.Flush(context.Background()))
+	assert.Equal(t, 50, requests)
+}
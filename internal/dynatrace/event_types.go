@@ -0,0 +1,143 @@
+package dynatrace
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Dynatrace event types Keptn events can be mapped to
+const (
+	InfoEventType                 = "CUSTOM_INFO"
+	AnnotationEventType           = "CUSTOM_ANNOTATION"
+	ConfigurationEventType        = "CUSTOM_CONFIGURATION"
+	DeploymentEventType           = "CUSTOM_DEPLOYMENT"
+	MarkedForTerminationEventType = "MARKED_FOR_TERMINATION"
+)
+
+// keptnEventTypePrefix is the prefix of Keptn event types that can be omitted in an EventTypeMapping
+const keptnEventTypePrefix = "sh.keptn.event."
+
+// NoEventType disables sending Dynatrace events for a Keptn event
+const NoEventType = "NONE"
+
+// SupportedEventTypes contains the values supported in an EventTypeMapping
+var SupportedEventTypes = []string{InfoEventType, AnnotationEventType, ConfigurationEventType, DeploymentEventType, MarkedForTerminationEventType, ErrorEventType, AvailabilityEventType, NoEventType}
+
+// EventTypeMapping maps Keptn event types, e.g. deployment.finished or sh.keptn.event.deployment.finished, to the Dynatrace event type
+// sent for them, or NONE if no Dynatrace event should be sent
+type EventTypeMapping map[string]string
+
+// IsSupportedEventType returns whether the value can be used in an EventTypeMapping
+func IsSupportedEventType(eventType string) bool {
+	for _, supportedEventType := range SupportedEventTypes {
+		if eventType == supportedEventType {
+			return true
+		}
+	}
+	return false
+}
+
+// getEventType returns the Dynatrace event type configured for the Keptn event type or defaultEventType if there is no valid mapping
+func (m EventTypeMapping) getEventType(keptnEventType string, defaultEventType string) string {
+	eventType, ok := m[keptnEventType]
+	if !ok {
+		eventType, ok = m[strings.TrimPrefix(keptnEventType, keptnEventTypePrefix)]
+	}
+	if !ok || eventType == "" {
+		return defaultEventType
+	}
+
+	if !IsSupportedEventType(eventType) {
+		log.WithFields(log.Fields{"keptnEventType": keptnEventType, "eventType": eventType}).Error("Unsupported Dynatrace event type configured, using default event type")
+		return defaultEventType
+	}
+
+	return eventType
+}
+
+// isDisabled returns whether sending Dynatrace events is disabled for the Keptn event type, i.e. it is mapped to NONE
+func (m EventTypeMapping) isDisabled(keptnEventType string) bool {
+	return m.getEventType(keptnEventType, "") == NoEventType
+}
+
+// eventContent contains the parts of a Dynatrace event that are kept if it is sent as another event type
+type eventContent struct {
+	eventType        string
+	keptnEventType   string
+	source           string
+	attachRules      AttachRules
+	customProperties map[string]string
+	title            string
+	description      string
+}
+
+// createEventOfType creates a Dynatrace event of the given type from the content of another event
+func createEventOfType(c eventContent, eventType string) interface{} {
+	switch eventType {
+	case InfoEventType:
+		return InfoEvent{
+			EventType:        eventType,
+			Source:           c.source,
+			AttachRules:      c.attachRules,
+			CustomProperties: c.customProperties,
+			Title:            c.title,
+			Description:      c.description,
+		}
+	case AnnotationEventType:
+		return AnnotationEvent{
+			EventType:             eventType,
+			Source:                c.source,
+			AttachRules:           c.attachRules,
+			CustomProperties:      c.customProperties,
+			AnnotationType:        c.title,
+			AnnotationDescription: c.description,
+		}
+	case ConfigurationEventType:
+		return ConfigurationEvent{
+			EventType:        eventType,
+			Source:           c.source,
+			AttachRules:      c.attachRules,
+			CustomProperties: c.customProperties,
+			Description:      c.title,
+			Configuration:    c.description,
+		}
+	case DeploymentEventType:
+		return DeploymentEvent{
+			EventType:         eventType,
+			Source:            c.source,
+			AttachRules:       c.attachRules,
+			CustomProperties:  c.customProperties,
+			DeploymentName:    c.title,
+			DeploymentVersion: c.customProperties["Tag"],
+			DeploymentProject: c.customProperties["Project"],
+		}
+	case MarkedForTerminationEventType:
+		return MarkedForTerminationEvent{
+			EventType:        eventType,
+			Source:           c.source,
+			AttachRules:      c.attachRules,
+			CustomProperties: c.customProperties,
+			Description:      joinTitleAndDescription(c.title, c.description),
+		}
+	default:
+		return ErrorEvent{
+			EventType:        eventType,
+			Source:           c.source,
+			AttachRules:      c.attachRules,
+			CustomProperties: c.customProperties,
+			Title:            c.title,
+			Description:      c.description,
+		}
+	}
+}
+
+func joinTitleAndDescription(title string, description string) string {
+	if title == "" {
+		return description
+	}
+	if description == "" {
+		return title
+	}
+	return title + ": " + description
+}
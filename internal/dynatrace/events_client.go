@@ -3,13 +3,22 @@ package dynatrace
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	log "github.com/sirupsen/logrus"
 )
 
 const eventsPath = "/api/v1/events"
 
+// ErrorEventType is the Dynatrace event type for error events
+const ErrorEventType = "ERROR_EVENT"
+
+// AvailabilityEventType is the Dynatrace event type for availability events
+const AvailabilityEventType = "AVAILABILITY_EVENT"
+
 type ConfigurationEvent struct {
 	EventType   string      `json:"eventType"`
 	Source      string      `json:"source"`
@@ -19,6 +28,9 @@ type ConfigurationEvent struct {
 	Description      string            `json:"description"`
 	Configuration    string            `json:"configuration"`
 	Original         string            `json:"original,omitempty"`
+
+	// keptnEventType is the type of the Keptn event the Dynatrace event is sent for
+	keptnEventType string
 }
 
 type DeploymentEvent struct {
@@ -32,6 +44,9 @@ type DeploymentEvent struct {
 	DeploymentProject string            `json:"deploymentProject"`
 	CiBackLink        string            `json:"ciBackLink,omitempty"`
 	RemediationAction string            `json:"remediationAction,omitempty"`
+
+	// keptnEventType is the type of the Keptn event the Dynatrace event is sent for
+	keptnEventType string
 }
 
 type InfoEvent struct {
@@ -42,6 +57,22 @@ type InfoEvent struct {
 	CustomProperties map[string]string `json:"customProperties"`
 	Description      string            `json:"description"`
 	Title            string            `json:"title"`
+
+	// keptnEventType is the type of the Keptn event the Dynatrace event is sent for
+	keptnEventType string
+}
+
+// ErrorEvent is a Dynatrace ERROR_EVENT or AVAILABILITY_EVENT which opens a problem on the attached entities
+type ErrorEvent struct {
+	EventType        string            `json:"eventType"`
+	Source           string            `json:"source"`
+	AttachRules      AttachRules       `json:"attachRules"`
+	CustomProperties map[string]string `json:"customProperties"`
+	Description      string            `json:"description"`
+	Title            string            `json:"title"`
+
+	// keptnEventType is the type of the Keptn event the Dynatrace event is sent for
+	keptnEventType string
 }
 
 type AnnotationEvent struct {
@@ -52,6 +83,39 @@ type AnnotationEvent struct {
 	CustomProperties      map[string]string `json:"customProperties"`
 	AnnotationDescription string            `json:"annotationDescription"`
 	AnnotationType        string            `json:"annotationType"`
+
+	// keptnEventType is the type of the Keptn event the Dynatrace event is sent for
+	keptnEventType string
+}
+
+// MarkedForTerminationEvent is a Dynatrace MARKED_FOR_TERMINATION event
+type MarkedForTerminationEvent struct {
+	EventType        string            `json:"eventType"`
+	Source           string            `json:"source"`
+	AttachRules      AttachRules       `json:"attachRules"`
+	CustomProperties map[string]string `json:"customProperties"`
+	Description      string            `json:"description"`
+}
+
+// mappableEvent is a Dynatrace event that can be sent as another event type according to an EventTypeMapping
+type mappableEvent interface {
+	content() eventContent
+}
+
+func (e ConfigurationEvent) content() eventContent {
+	return eventContent{eventType: e.EventType, keptnEventType: e.keptnEventType, source: e.Source, attachRules: e.AttachRules, customProperties: e.CustomProperties, title: e.Description, description: e.Configuration}
+}
+
+func (e DeploymentEvent) content() eventContent {
+	return eventContent{eventType: e.EventType, keptnEventType: e.keptnEventType, source: e.Source, attachRules: e.AttachRules, customProperties: e.CustomProperties, title: e.DeploymentName, description: e.DeploymentVersion}
+}
+
+func (e InfoEvent) content() eventContent {
+	return eventContent{eventType: e.EventType, keptnEventType: e.keptnEventType, source: e.Source, attachRules: e.AttachRules, customProperties: e.CustomProperties, title: e.Title, description: e.Description}
+}
+
+func (e AnnotationEvent) content() eventContent {
+	return eventContent{eventType: e.EventType, keptnEventType: e.keptnEventType, source: e.Source, attachRules: e.AttachRules, customProperties: e.CustomProperties, title: e.AnnotationType, description: e.AnnotationDescription}
 }
 
 // TagEntry defines a Dynatrace configuration structure
@@ -108,20 +172,9 @@ func createAttachRules(a adapter.EventContentAdapter, attachRules *AttachRules)
 	return ar
 }
 
-/**
- * Change with #115_116: parse labels and move them into custom properties
- */
+// createCustomProperties returns the custom properties of Dynatrace events sent for a Keptn event, i.e. the Keptn context and
+// the project, stage, service and image of the event, followed by all labels of the event, which take precedence
 func createCustomProperties(a adapter.EventContentAdapter, imageAndTag common.ImageAndTag) map[string]string {
-	// TODO: AG - parse labels and push them through
-
-	// var customProperties dtCustomProperties
-	// customProperties.Project = Project
-	// customProperties.Stage = Stage
-	// customProperties.Service = Service
-	// customProperties.TestStrategy = TestStrategy
-	// customProperties.Image = Image
-	// customProperties.Tag = Tag
-	// customProperties.KeptnContext = keptnContext
 	var customProperties map[string]string
 	customProperties = make(map[string]string)
 	customProperties["Project"] = a.GetProject()
@@ -141,12 +194,43 @@ func createCustomProperties(a adapter.EventContentAdapter, imageAndTag common.Im
 	return customProperties
 }
 
+// Release detection properties as defined by Dynatrace, see https://www.dynatrace.com/support/help/how-to-use-dynatrace/cloud-automation/release-monitoring/version-detection-strategies
+const releaseVersionProperty = "dt.event.deployment.release_version"
+const releaseBuildVersionProperty = "dt.event.deployment.release_build_version"
+const releaseStageProperty = "dt.event.deployment.release_stage"
+const releaseProductProperty = "dt.event.deployment.release_product"
+
+// addReleaseProperties adds the Dynatrace release detection properties, values can be overridden by the labels releaseVersion, buildVersion and releaseProduct
+func addReleaseProperties(customProperties map[string]string, a adapter.EventContentAdapter, imageAndTag common.ImageAndTag) {
+	releaseVersion := getValueFromLabels(a, "releaseVersion", imageAndTag.Tag())
+	if releaseVersion != "" && releaseVersion != common.NotAvailable {
+		customProperties[releaseVersionProperty] = releaseVersion
+	}
+
+	buildVersion := getValueFromLabels(a, "buildVersion", "")
+	if buildVersion != "" {
+		customProperties[releaseBuildVersionProperty] = buildVersion
+	}
+
+	customProperties[releaseStageProperty] = a.GetStage()
+	customProperties[releaseProductProperty] = getValueFromLabels(a, "releaseProduct", a.GetProject())
+}
+
+// CreateReleaseInfoEventDTO creates a new Dynatrace CUSTOM_INFO event including the release detection properties
+func CreateReleaseInfoEventDTO(a adapter.EventContentAdapter, imageAndTag common.ImageAndTag, attachRules *AttachRules) InfoEvent {
+	ie := CreateInfoEventDTO(a, imageAndTag, attachRules)
+	addReleaseProperties(ie.CustomProperties, a, imageAndTag)
+
+	return ie
+}
+
 // CreateInfoEventDTO creates a new Dynatrace CUSTOM_INFO event
 func CreateInfoEventDTO(a adapter.EventContentAdapter, imageAndTag common.ImageAndTag, attachRules *AttachRules) InfoEvent {
 
 	// we fill the Dynatrace Info Event with values from the labels or use our defaults
 	var ie InfoEvent
-	ie.EventType = "CUSTOM_INFO"
+	ie.EventType = InfoEventType
+	ie.keptnEventType = a.GetEvent()
 	ie.Source = "Keptn dynatrace-service"
 	ie.Title = a.GetLabels()["title"]
 	ie.Description = a.GetLabels()["description"]
@@ -162,12 +246,33 @@ func CreateInfoEventDTO(a adapter.EventContentAdapter, imageAndTag common.ImageA
 	return ie
 }
 
+// CreateErrorEventDTO creates a Dynatrace ERROR_EVENT or AVAILABILITY_EVENT
+func CreateErrorEventDTO(a adapter.EventContentAdapter, imageAndTag common.ImageAndTag, attachRules *AttachRules, eventType string) ErrorEvent {
+	var ee ErrorEvent
+	ee.EventType = eventType
+	ee.keptnEventType = a.GetEvent()
+	ee.Source = "Keptn dynatrace-service"
+	ee.Title = a.GetLabels()["title"]
+	ee.Description = a.GetLabels()["description"]
+
+	// now we create our attach rules
+	ar := createAttachRules(a, attachRules)
+	ee.AttachRules = ar
+
+	// and add the rest of the labels and info as custom properties
+	customProperties := createCustomProperties(a, imageAndTag)
+	ee.CustomProperties = customProperties
+
+	return ee
+}
+
 // CreateAnnotationEventDTO creates a Dynatrace CUSTOM_ANNOTATION event
 func CreateAnnotationEventDTO(a adapter.EventContentAdapter, imageAndTag common.ImageAndTag, attachRules *AttachRules) AnnotationEvent {
 
 	// we fill the Dynatrace Info Event with values from the labels or use our defaults
 	var ie AnnotationEvent
-	ie.EventType = "CUSTOM_ANNOTATION"
+	ie.EventType = AnnotationEventType
+	ie.keptnEventType = a.GetEvent()
 	ie.Source = "Keptn dynatrace-service"
 	ie.AnnotationType = a.GetLabels()["type"]
 	ie.AnnotationDescription = a.GetLabels()["description"]
@@ -196,7 +301,8 @@ func CreateDeploymentEventDTO(a adapter.EventContentAdapter, imageAndTag common.
 
 	// we fill the Dynatrace Deployment Event with values from the labels or use our defaults
 	var de DeploymentEvent
-	de.EventType = "CUSTOM_DEPLOYMENT"
+	de.EventType = DeploymentEventType
+	de.keptnEventType = a.GetEvent()
 	de.Source = "Keptn dynatrace-service"
 	de.DeploymentName = getValueFromLabels(a, "deploymentName", "Deploy "+a.GetService()+" "+imageAndTag.Tag()+" with strategy "+a.GetDeploymentStrategy())
 	de.DeploymentProject = getValueFromLabels(a, "deploymentProject", a.GetProject())
@@ -209,8 +315,8 @@ func CreateDeploymentEventDTO(a adapter.EventContentAdapter, imageAndTag common.
 	de.AttachRules = ar
 
 	// and add the rest of the labels and info as custom properties
-	// TODO: event.Project, event.Stage, event.Service, event.TestStrategy, event.Image, event.Tag, event.Labels, keptnContext
 	customProperties := createCustomProperties(a, imageAndTag)
+	addReleaseProperties(customProperties, a, imageAndTag)
 	de.CustomProperties = customProperties
 
 	return de
@@ -221,7 +327,8 @@ func CreateConfigurationEventDTO(a adapter.EventContentAdapter, imageAndTag comm
 
 	// we fill the Dynatrace Deployment Event with values from the labels or use our defaults
 	var de ConfigurationEvent
-	de.EventType = "CUSTOM_CONFIGURATION"
+	de.EventType = ConfigurationEventType
+	de.keptnEventType = a.GetEvent()
 	de.Source = "Keptn dynatrace-service"
 
 	// now we create our attach rules
@@ -229,7 +336,6 @@ func CreateConfigurationEventDTO(a adapter.EventContentAdapter, imageAndTag comm
 	de.AttachRules = ar
 
 	// and add the rest of the labels and info as custom properties
-	// TODO: event.Project, event.Stage, event.Service, event.TestStrategy, event.Image, event.Tag, event.Labels, keptnContext
 	customProperties := createCustomProperties(a, imageAndTag)
 	de.CustomProperties = customProperties
 
@@ -237,13 +343,35 @@ func CreateConfigurationEventDTO(a adapter.EventContentAdapter, imageAndTag comm
 }
 
 type EventsClient struct {
-	client ClientInterface
+	client              ClientInterface
+	eventTypes          EventTypeMapping
+	validateAttachRules bool
+	sender              *AsyncEventSender
+	onSent              func(accepted bool)
 }
 
-// NewEventsClient creates a new EventsClient
-func NewEventsClient(client ClientInterface) *EventsClient {
+// NewEventsClientWithEventTypes creates a new EventsClient that sends events as the Dynatrace event types configured for their Keptn event types.
+// All events are sent using such a client, so that no events are sent for Keptn event types mapped to NONE
+func NewEventsClientWithEventTypes(client ClientInterface, eventTypes EventTypeMapping, cfg *env.Config) *EventsClient {
 	return &EventsClient{
-		client: client,
+		client:              client,
+		eventTypes:          eventTypes,
+		validateAttachRules: cfg.AttachRulesValidationEnabled,
+		sender:              GetDefaultAsyncEventSender(cfg),
+	}
+}
+
+// OnSent sets a function that is called with whether the Dynatrace API accepted an event once it was sent, retried or given up,
+// also if it is sent in the background. It is called with false if sending events is disabled for the Keptn event type
+func (ec *EventsClient) OnSent(onSent func(accepted bool)) *EventsClient {
+	ec.onSent = onSent
+	return ec
+}
+
+// notifySent calls the function set by OnSent, if any
+func (ec *EventsClient) notifySent(accepted bool) {
+	if ec.onSent != nil {
+		ec.onSent(accepted)
 	}
 }
 
@@ -256,40 +384,103 @@ func (ec *EventsClient) addEvent(dtEvent interface{}) (string, error) {
 
 	body, err := ec.client.Post(eventsPath, payload)
 	if err != nil {
-		return "", fmt.Errorf("could not create event: %v", err)
+		return "", fmt.Errorf("could not create event: %w", err)
 	}
 
 	return string(body), nil
 }
 
-// addEventAndLog sends an event to the Dynatrace events API and logs errors if necessary
+// addEventAndLog sends an event to the Dynatrace events API, in the background if asynchronous sending is enabled, and logs errors if necessary
 func (ec *EventsClient) addEventAndLog(dtEvent interface{}) {
+	if ec.sender != nil && ec.sender.enqueue(queuedEvent{client: ec.client, dtEvent: dtEvent, onSent: ec.onSent}) {
+		log.Info("Queued event for sending to Dynatrace API")
+		return
+	}
+
 	log.Info("Sending event to Dynatrace API")
 	body, err := ec.addEvent(dtEvent)
 	if err != nil {
 		log.WithError(err).Error("Failed sending Dynatrace events API request")
+		ec.notifySent(false)
 		return
 	}
 
 	log.WithField("body", body).Debug("Dynatrace API has accepted the event")
+	ec.notifySent(true)
+}
+
+// addMappableEventAndLog sends an event as the Dynatrace event type configured for its Keptn event type, or not at all if sending is disabled
+func (ec *EventsClient) addMappableEventAndLog(dtEvent mappableEvent) {
+	c := dtEvent.content()
+	eventType := ec.eventTypes.getEventType(c.keptnEventType, c.eventType)
+	switch eventType {
+	case NoEventType:
+		log.WithField("keptnEventType", c.keptnEventType).Info("Sending Dynatrace events is disabled for Keptn event type")
+		ec.notifySent(false)
+	case c.eventType:
+		ec.addMatchingEntitiesProperty(c)
+		ec.addEventAndLog(dtEvent)
+	default:
+		ec.addMatchingEntitiesProperty(c)
+		ec.addEventAndLog(createEventOfType(c, eventType))
+	}
+}
+
+// matchingEntitiesProperty is the custom property of Dynatrace events containing the number of entities matched by the attach rules
+const matchingEntitiesProperty = "Matching Entities"
+
+// addMatchingEntitiesProperty adds the number of entities the attach rules of the event match to its custom properties and warns if there are none, as Dynatrace does not store such events
+func (ec *EventsClient) addMatchingEntitiesProperty(c eventContent) {
+	if !ec.validateAttachRules {
+		return
+	}
+
+	count, err := NewEntitiesClient(ec.client).CountMatchingEntities(c.attachRules)
+	if err != nil {
+		log.WithError(err).Warn("Could not validate the attach rules of the Dynatrace event")
+		return
+	}
+
+	// the custom properties are shared with the event that is sent
+	if c.customProperties != nil {
+		c.customProperties[matchingEntitiesProperty] = strconv.Itoa(count)
+	}
+
+	logger := log.WithFields(log.Fields{"keptnEventType": c.keptnEventType, "matchingEntities": count})
+	if count == 0 {
+		logger.Warn("No Dynatrace entities match the attach rules, the event will not be attached to any entity")
+		return
+	}
+	logger.Info("Attach rules of the Dynatrace event match entities")
 }
 
 // AddDeploymentEvent sends a deployment event to the Dynatrace events API
 func (ec *EventsClient) AddDeploymentEvent(de DeploymentEvent) {
-	ec.addEventAndLog(de)
+	ec.addMappableEventAndLog(de)
 }
 
 // AddInfoEvent sends an info event to the Dynatrace events API
 func (ec *EventsClient) AddInfoEvent(ie InfoEvent) {
-	ec.addEventAndLog(ie)
+	ec.addMappableEventAndLog(ie)
+}
+
+// AddErrorEvent sends an error or availability event to the Dynatrace events API. As it opens a problem, its type is kept,
+// but it is not sent if sending events is disabled for its Keptn event type
+func (ec *EventsClient) AddErrorEvent(ee ErrorEvent) {
+	if ec.eventTypes.isDisabled(ee.keptnEventType) {
+		log.WithField("keptnEventType", ee.keptnEventType).Info("Sending Dynatrace events is disabled for Keptn event type")
+		ec.notifySent(false)
+		return
+	}
+	ec.addEventAndLog(ee)
 }
 
 // AddAnnotationEvent sends an annotation event to the Dynatrace events API
 func (ec *EventsClient) AddAnnotationEvent(ae AnnotationEvent) {
-	ec.addEventAndLog(ae)
+	ec.addMappableEventAndLog(ae)
 }
 
 // AddConfigurationEvent sends a configuration event to the Dynatrace events API
 func (ec *EventsClient) AddConfigurationEvent(ce ConfigurationEvent) {
-	ec.addEventAndLog(ce)
+	ec.addMappableEventAndLog(ce)
 }
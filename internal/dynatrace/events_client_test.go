@@ -0,0 +1,328 @@
+package dynatrace
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateReleaseInfoEventDTO(t *testing.T) {
+	tests := []struct {
+		name        string
+		labels      map[string]string
+		imageAndTag common.ImageAndTag
+		want        map[string]string
+	}{
+		{
+			name:        "version from tag",
+			imageAndTag: common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"),
+			want: map[string]string{
+				releaseVersionProperty: "0.12.1",
+				releaseStageProperty:   "production",
+				releaseProductProperty: "sockshop",
+			},
+		},
+		{
+			name: "values from labels",
+			labels: map[string]string{
+				"releaseVersion": "1.0.0",
+				"buildVersion":   "1.0.0-b42",
+				"releaseProduct": "carts",
+			},
+			imageAndTag: common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"),
+			want: map[string]string{
+				releaseVersionProperty:      "1.0.0",
+				releaseBuildVersionProperty: "1.0.0-b42",
+				releaseStageProperty:        "production",
+				releaseProductProperty:      "carts",
+			},
+		},
+		{
+			name:        "no version available",
+			imageAndTag: common.NewNotAvailableImageAndTag(),
+			want: map[string]string{
+				releaseStageProperty:   "production",
+				releaseProductProperty: "sockshop",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventData := &test.EventData{
+				Project: "sockshop",
+				Stage:   "production",
+				Service: "carts",
+				Labels:  tt.labels,
+			}
+
+			ie := CreateReleaseInfoEventDTO(eventData, tt.imageAndTag, nil)
+
+			for _, property := range []string{releaseVersionProperty, releaseBuildVersionProperty, releaseStageProperty, releaseProductProperty} {
+				value, ok := ie.CustomProperties[property]
+				expectedValue, expected := tt.want[property]
+				assert.Equal(t, expected, ok, property)
+				assert.Equal(t, expectedValue, value, property)
+			}
+		})
+	}
+}
+
+func TestCreateDeploymentEventDTO_LabelsAsCustomProperties(t *testing.T) {
+	eventData := &test.EventData{
+		Project: "sockshop",
+		Stage:   "production",
+		Service: "carts",
+		Labels: map[string]string{
+			"owner":  "team-a",
+			"jobUrl": "https://ci.example.com/job/42",
+		},
+	}
+
+	de := CreateDeploymentEventDTO(eventData, common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"), nil)
+
+	assert.Equal(t, "team-a", de.CustomProperties["owner"])
+	assert.Equal(t, "https://ci.example.com/job/42", de.CustomProperties["jobUrl"])
+	assert.Equal(t, "sockshop", de.CustomProperties["Project"])
+}
+
+func TestEventsClient_AddDeploymentEventWithEventTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes EventTypeMapping
+		want       map[string]interface{}
+	}{
+		{
+			name: "no mapping",
+			want: map[string]interface{}{"eventType": "CUSTOM_DEPLOYMENT", "deploymentName": "Deploy carts 0.12.1 with strategy blue_green_service", "deploymentVersion": "0.12.1"},
+		},
+		{
+			name:       "mapping for other event type",
+			eventTypes: EventTypeMapping{"test.triggered": NoEventType},
+			want:       map[string]interface{}{"eventType": "CUSTOM_DEPLOYMENT", "deploymentName": "Deploy carts 0.12.1 with strategy blue_green_service", "deploymentVersion": "0.12.1"},
+		},
+		{
+			name:       "mapped to info event",
+			eventTypes: EventTypeMapping{"deployment.finished": InfoEventType},
+			want:       map[string]interface{}{"eventType": "CUSTOM_INFO", "title": "Deploy carts 0.12.1 with strategy blue_green_service", "description": "0.12.1"},
+		},
+		{
+			name:       "mapped to annotation event by full Keptn event type",
+			eventTypes: EventTypeMapping{"sh.keptn.event.deployment.finished": AnnotationEventType},
+			want:       map[string]interface{}{"eventType": "CUSTOM_ANNOTATION", "annotationType": "Deploy carts 0.12.1 with strategy blue_green_service", "annotationDescription": "0.12.1"},
+		},
+		{
+			name:       "mapped to marked for termination event",
+			eventTypes: EventTypeMapping{"deployment.finished": MarkedForTerminationEventType},
+			want:       map[string]interface{}{"eventType": "MARKED_FOR_TERMINATION", "description": "Deploy carts 0.12.1 with strategy blue_green_service: 0.12.1"},
+		},
+		{
+			name:       "unsupported event type",
+			eventTypes: EventTypeMapping{"deployment.finished": "CUSTOM_EVENT"},
+			want:       map[string]interface{}{"eventType": "CUSTOM_DEPLOYMENT", "deploymentName": "Deploy carts 0.12.1 with strategy blue_green_service", "deploymentVersion": "0.12.1"},
+		},
+		{
+			name:       "disabled",
+			eventTypes: EventTypeMapping{"deployment.finished": NoEventType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload map[string]interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, json.Unmarshal(body, &payload))
+				w.Write([]byte(`{}`))
+			})
+
+			dtClient, _, teardown := createDynatraceClient(handler)
+			defer teardown()
+
+			eventData := &test.EventData{
+				Event:              "sh.keptn.event.deployment.finished",
+				Project:            "sockshop",
+				Stage:              "production",
+				Service:            "carts",
+				DeploymentStrategy: "blue_green_service",
+			}
+			de := CreateDeploymentEventDTO(eventData, common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"), nil)
+
+			NewEventsClientWithEventTypes(dtClient, tt.eventTypes, env.ReadConfig()).AddDeploymentEvent(de)
+
+			if tt.want == nil {
+				assert.Nil(t, payload)
+				return
+			}
+
+			for key, value := range tt.want {
+				assert.Equal(t, value, payload[key], key)
+			}
+			assert.Equal(t, "sockshop", payload["customProperties"].(map[string]interface{})["Project"])
+		})
+	}
+}
+
+func TestEventsClient_AddErrorEventWithEventTypes(t *testing.T) {
+	tests := []struct {
+		name          string
+		eventTypes    EventTypeMapping
+		wantEventType string
+	}{
+		{
+			name:          "no mapping",
+			wantEventType: ErrorEventType,
+		},
+		{
+			name:          "mapped to other event type keeps error event",
+			eventTypes:    EventTypeMapping{"evaluation.finished": InfoEventType},
+			wantEventType: ErrorEventType,
+		},
+		{
+			name:       "disabled",
+			eventTypes: EventTypeMapping{"evaluation.finished": NoEventType},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload map[string]interface{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.NoError(t, json.Unmarshal(body, &payload))
+				w.Write([]byte(`{}`))
+			})
+
+			dtClient, _, teardown := createDynatraceClient(handler)
+			defer teardown()
+
+			eventData := &test.EventData{
+				Event:   "sh.keptn.event.evaluation.finished",
+				Project: "sockshop",
+				Stage:   "production",
+				Service: "carts",
+			}
+			ee := CreateErrorEventDTO(eventData, common.NewNotAvailableImageAndTag(), nil, ErrorEventType)
+
+			NewEventsClientWithEventTypes(dtClient, tt.eventTypes, env.ReadConfig()).AddErrorEvent(ee)
+
+			if tt.wantEventType == "" {
+				assert.Nil(t, payload)
+				return
+			}
+			assert.Equal(t, tt.wantEventType, payload["eventType"])
+		})
+	}
+}
+
+func TestEventsClient_AddDeploymentEventWithAttachRulesValidation(t *testing.T) {
+	var requests []string
+	var payload map[string]interface{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.URL.Path == entitiesPath {
+			assert.Equal(t, `type("SERVICE"),tag("keptn_project:sockshop"),tag("keptn_stage:production"),tag("keptn_service:carts")`, r.URL.Query().Get("entitySelector"))
+			w.Write([]byte(`{"totalCount": 0, "pageSize": 1, "entities": []}`))
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.NoError(t, json.Unmarshal(body, &payload))
+		w.Write([]byte(`{}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	eventData := &test.EventData{
+		Event:   "sh.keptn.event.deployment.finished",
+		Project: "sockshop",
+		Stage:   "production",
+		Service: "carts",
+	}
+	de := CreateDeploymentEventDTO(eventData, common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"), nil)
+
+	cfg := env.ReadConfig()
+	cfg.AttachRulesValidationEnabled = true
+	NewEventsClientWithEventTypes(dtClient, nil, cfg).AddDeploymentEvent(de)
+
+	assert.Equal(t, []string{"GET " + entitiesPath, "POST " + eventsPath}, requests)
+	if assert.NotNil(t, payload) {
+		assert.Equal(t, "0", payload["customProperties"].(map[string]interface{})[matchingEntitiesProperty])
+	}
+}
+
+func TestEventsClient_OnSent(t *testing.T) {
+	tests := []struct {
+		name         string
+		statusCode   int
+		eventTypes   EventTypeMapping
+		async        bool
+		wantAccepted bool
+	}{
+		{
+			name:         "accepted",
+			statusCode:   http.StatusOK,
+			wantAccepted: true,
+		},
+		{
+			name:         "rejected",
+			statusCode:   http.StatusBadRequest,
+			wantAccepted: false,
+		},
+		{
+			name:         "accepted in the background",
+			statusCode:   http.StatusOK,
+			async:        true,
+			wantAccepted: true,
+		},
+		{
+			name:         "rejected in the background",
+			statusCode:   http.StatusBadRequest,
+			async:        true,
+			wantAccepted: false,
+		},
+		{
+			name:         "disabled for Keptn event type",
+			statusCode:   http.StatusOK,
+			eventTypes:   EventTypeMapping{"sh.keptn.event.deployment.finished": NoEventType},
+			wantAccepted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				w.Write([]byte(`{}`))
+			})
+
+			dtClient, _, teardown := createDynatraceClient(handler)
+			defer teardown()
+
+			eventsClient := NewEventsClientWithEventTypes(dtClient, tt.eventTypes, env.ReadConfig())
+			eventsClient.sender = nil
+			if tt.async {
+				eventsClient.sender = NewAsyncEventSender(10, 1, 0)
+			}
+
+			var results []bool
+			eventsClient.OnSent(func(accepted bool) {
+				results = append(results, accepted)
+			})
+
+			eventData := &test.EventData{Event: "sh.keptn.event.deployment.finished", Project: "sockshop", Stage: "production", Service: "carts"}
+			eventsClient.AddDeploymentEvent(CreateDeploymentEventDTO(eventData, common.NewImageAndTag("docker.io/keptnexamples/carts", "0.12.1"), nil))
+			if tt.async {
+				eventsClient.sender.Flush(context.Background())
+			}
+
+			assert.Equal(t, []bool{tt.wantAccepted}, results)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package dynatrace
+
+import "time"
+
+// HostsClient is a client for looking up HOST entities
+type HostsClient struct {
+	entitiesClient *EntitiesClient
+}
+
+// NewHostsClient creates a new HostsClient
+func NewHostsClient(client ClientInterface) *HostsClient {
+	return &HostsClient{
+		entitiesClient: NewEntitiesClient(client),
+	}
+}
+
+// GetByEntitySelector returns the hosts matching the entity selector, e.g. tag("keptn_stage:staging"), that were seen during the timeframe.
+// The properties of the hosts, e.g. their operating system and monitoring mode, are included
+func (hc *HostsClient) GetByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return hc.entitiesClient.QueryForTimeframe(withEntityType(HostEntityType, entitySelector), []string{"+properties"}, 0, startUnix, endUnix)
+}
+
+// CountByEntitySelector returns the number of hosts matching the entity selector that were seen during the timeframe
+func (hc *HostsClient) CountByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+	return hc.entitiesClient.CountEntitiesForTimeframe(withEntityType(HostEntityType, entitySelector), startUnix, endUnix)
+}
@@ -0,0 +1,68 @@
+package dynatrace
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostsClient_GetByEntitySelector(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type%28%22HOST%22%29%2Ctag%28keptn_stage%3Astaging%29&fields=%2Bproperties&from=1571649000000&to=1571649600000",
+		[]byte(`{"totalCount":2,"pageSize":1,"nextPageKey":"page2","entities":[{"entityId":"HOST-1","type":"HOST","displayName":"node-1","properties":{"osType":"LINUX","monitoringMode":"FULL_STACK"}}]}`))
+	handler.AddExact(entitiesPath+"?nextPageKey=page2",
+		[]byte(`{"totalCount":2,"pageSize":1,"entities":[{"entityId":"HOST-2","type":"HOST","displayName":"node-2","properties":{"osType":"LINUX","monitoringMode":"INFRASTRUCTURE"}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	start := time.Unix(1571649000, 0)
+	hosts, err := NewHostsClient(dtClient).GetByEntitySelector("tag(keptn_stage:staging)", start, start.Add(10*time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entity{
+		{
+			EntityID:    "HOST-1",
+			Type:        HostEntityType,
+			DisplayName: "node-1",
+			Properties:  &EntityProperties{OSType: "LINUX", MonitoringMode: "FULL_STACK"},
+		},
+		{
+			EntityID:    "HOST-2",
+			Type:        HostEntityType,
+			DisplayName: "node-2",
+			Properties:  &EntityProperties{OSType: "LINUX", MonitoringMode: "INFRASTRUCTURE"},
+		},
+	}, hosts)
+}
+
+func TestHostsClient_CountByEntitySelector(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type%28%22HOST%22%29%2Ctag%28keptn_stage%3Astaging%29&pageSize=1&from=1571649000000&to=1571649600000",
+		[]byte(`{"totalCount":3,"pageSize":1,"nextPageKey":"page2","entities":[{"entityId":"HOST-1","displayName":"node-1"}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	start := time.Unix(1571649000, 0)
+	count, err := NewHostsClient(dtClient).CountByEntitySelector("tag(keptn_stage:staging)", start, start.Add(10*time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count, "the total count is returned without retrieving further pages")
+}
+
+func TestHostsClient_CountByEntitySelectorWithError(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWithError(entitiesPath, http.StatusForbidden, []byte(`{"error": {"code": 403, "message": "Token is missing required scope"}}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	start := time.Unix(1571649000, 0)
+	_, err := NewHostsClient(dtClient).CountByEntitySelector("tag(keptn_stage:staging)", start, start.Add(10*time.Minute))
+
+	assert.Error(t, err)
+}
@@ -43,6 +43,13 @@ type MEAlertingScope struct {
 	ManagementZoneID int64        `json:"managementZoneId,omitempty"`
 }
 
+// MetricEventsClientInterface creates, updates and looks up metric events
+type MetricEventsClientInterface interface {
+	GetMetricEventByName(metricEventName string) (*MetricEvent, error)
+	Create(metricEvent *MetricEvent) error
+	Update(metricEvent *MetricEvent) error
+}
+
 type MetricEventsClient struct {
 	client ClientInterface
 }
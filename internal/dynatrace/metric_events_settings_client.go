@@ -0,0 +1,236 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const serviceEntityDimensionKey = "dt.entity.service"
+
+type metricEventSettings struct {
+	Enabled                 bool                               `json:"enabled"`
+	Summary                 string                             `json:"summary"`
+	QueryDefinition         metricEventSettingsQueryDefinition `json:"queryDefinition"`
+	ModelProperties         metricEventSettingsModelProperties `json:"modelProperties"`
+	EventTemplate           metricEventSettingsEventTemplate   `json:"eventTemplate"`
+	EventEntityDimensionKey string                             `json:"eventEntityDimensionKey,omitempty"`
+}
+
+type metricEventSettingsQueryDefinition struct {
+	Type            string                          `json:"type"`
+	MetricKey       string                          `json:"metricKey"`
+	Aggregation     string                          `json:"aggregation,omitempty"`
+	ManagementZone  string                          `json:"managementZone,omitempty"`
+	DimensionFilter []interface{}                   `json:"dimensionFilter"`
+	EntityFilter    metricEventSettingsEntityFilter `json:"entityFilter"`
+}
+
+type metricEventSettingsEntityFilter struct {
+	DimensionKey string                               `json:"dimensionKey"`
+	Conditions   []metricEventSettingsEntityCondition `json:"conditions"`
+}
+
+type metricEventSettingsEntityCondition struct {
+	Type     string `json:"type"`
+	Operator string `json:"operator"`
+	Value    string `json:"value"`
+}
+
+type metricEventSettingsModelProperties struct {
+	Type              string  `json:"type"`
+	Threshold         float64 `json:"threshold"`
+	AlertOnNoData     bool    `json:"alertOnNoData"`
+	AlertCondition    string  `json:"alertCondition"`
+	Samples           int     `json:"samples"`
+	ViolatingSamples  int     `json:"violatingSamples"`
+	DealertingSamples int     `json:"dealertingSamples"`
+}
+
+type metricEventSettingsEventTemplate struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	EventType   string `json:"eventType"`
+	DavisMerge  bool   `json:"davisMerge"`
+}
+
+// MetricEventsSettingsClient creates, updates and looks up metric events using the Settings 2.0 API
+type MetricEventsSettingsClient struct {
+	client *SettingsClient
+}
+
+// NewMetricEventsSettingsClient creates a new MetricEventsSettingsClient
+func NewMetricEventsSettingsClient(client *SettingsClient) *MetricEventsSettingsClient {
+	return &MetricEventsSettingsClient{
+		client: client,
+	}
+}
+
+// GetMetricEventByName retrieves the MetricEvent identified by metricEventName, or nil if not found. The ID of the returned MetricEvent is the object ID of the settings object
+func (mec *MetricEventsSettingsClient) GetMetricEventByName(metricEventName string) (*MetricEvent, error) {
+	objects, err := mec.client.List(MetricEventsSchemaID, EnvironmentScope)
+	if err != nil {
+		log.WithError(err).Error("Could not get existing Dynatrace metric events")
+		return nil, err
+	}
+
+	for _, object := range objects {
+		settings := &metricEventSettings{}
+		err := json.Unmarshal(object.Value, settings)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse metric event %s: %w", object.ObjectID, err)
+		}
+
+		if settings.Summary == metricEventName {
+			metricEvent := fromMetricEventSettings(settings)
+			metricEvent.ID = object.ObjectID
+			return metricEvent, nil
+		}
+	}
+	return nil, nil
+}
+
+// Create creates the metric event as a settings object of schema builtin:anomaly-detection.metric-events
+func (mec *MetricEventsSettingsClient) Create(metricEvent *MetricEvent) error {
+	_, err := mec.client.Create(
+		SettingsObjectCreate{
+			SchemaID: MetricEventsSchemaID,
+			Scope:    EnvironmentScope,
+			Value:    toMetricEventSettings(metricEvent),
+		})
+	if err != nil {
+		return fmt.Errorf("could not create metric event: %w", err)
+	}
+
+	return nil
+}
+
+// Update updates the settings object identified by the ID of the metric event
+func (mec *MetricEventsSettingsClient) Update(metricEvent *MetricEvent) error {
+	err := mec.client.Update(metricEvent.ID, toMetricEventSettings(metricEvent))
+	if err != nil {
+		return fmt.Errorf("could not update metric event: %w", err)
+	}
+
+	return nil
+}
+
+func toMetricEventSettings(metricEvent *MetricEvent) *metricEventSettings {
+	settings := &metricEventSettings{
+		Enabled: metricEvent.Enabled,
+		Summary: metricEvent.Name,
+		QueryDefinition: metricEventSettingsQueryDefinition{
+			Type:            "METRIC_KEY",
+			MetricKey:       metricEvent.MetricID,
+			Aggregation:     toMetricEventSettingsAggregation(metricEvent.AggregationType),
+			DimensionFilter: []interface{}{},
+			EntityFilter: metricEventSettingsEntityFilter{
+				DimensionKey: serviceEntityDimensionKey,
+				Conditions:   []metricEventSettingsEntityCondition{},
+			},
+		},
+		ModelProperties: metricEventSettingsModelProperties{
+			Type:              "STATIC_THRESHOLD",
+			Threshold:         metricEvent.Threshold,
+			AlertCondition:    metricEvent.AlertCondition,
+			Samples:           metricEvent.Samples,
+			ViolatingSamples:  metricEvent.ViolatingSamples,
+			DealertingSamples: metricEvent.DealertingSamples,
+		},
+		EventTemplate: metricEventSettingsEventTemplate{
+			Title:       metricEvent.Name,
+			Description: metricEvent.Description,
+			EventType:   metricEvent.EventType,
+			DavisMerge:  true,
+		},
+		EventEntityDimensionKey: serviceEntityDimensionKey,
+	}
+
+	for _, scope := range metricEvent.AlertingScope {
+		switch scope.FilterType {
+		case "MANAGEMENT_ZONE":
+			settings.QueryDefinition.ManagementZone = strconv.FormatInt(scope.ManagementZoneID, 10)
+		case "TAG":
+			if scope.TagFilter == nil {
+				continue
+			}
+			settings.QueryDefinition.EntityFilter.Conditions = append(
+				settings.QueryDefinition.EntityFilter.Conditions,
+				metricEventSettingsEntityCondition{
+					Type:     "TAG",
+					Operator: "EQUALS",
+					Value:    scope.TagFilter.Key + ":" + scope.TagFilter.Value,
+				})
+		}
+	}
+
+	return settings
+}
+
+func fromMetricEventSettings(settings *metricEventSettings) *MetricEvent {
+	metricEvent := &MetricEvent{
+		MetricID:          settings.QueryDefinition.MetricKey,
+		Name:              settings.Summary,
+		Description:       settings.EventTemplate.Description,
+		AggregationType:   fromMetricEventSettingsAggregation(settings.QueryDefinition.Aggregation),
+		EventType:         settings.EventTemplate.EventType,
+		Severity:          settings.EventTemplate.EventType,
+		AlertCondition:    settings.ModelProperties.AlertCondition,
+		Samples:           settings.ModelProperties.Samples,
+		ViolatingSamples:  settings.ModelProperties.ViolatingSamples,
+		DealertingSamples: settings.ModelProperties.DealertingSamples,
+		Threshold:         settings.ModelProperties.Threshold,
+		Enabled:           settings.Enabled,
+	}
+
+	if managementZoneID, err := strconv.ParseInt(settings.QueryDefinition.ManagementZone, 10, 64); err == nil {
+		metricEvent.AlertingScope = append(
+			metricEvent.AlertingScope,
+			MEAlertingScope{
+				FilterType:       "MANAGEMENT_ZONE",
+				ManagementZoneID: managementZoneID,
+			})
+	}
+
+	for _, condition := range settings.QueryDefinition.EntityFilter.Conditions {
+		if condition.Type != "TAG" {
+			continue
+		}
+
+		tag := strings.SplitN(condition.Value, ":", 2)
+		tagFilter := &METagFilter{
+			Context: "CONTEXTLESS",
+			Key:     tag[0],
+		}
+		if len(tag) == 2 {
+			tagFilter.Value = tag[1]
+		}
+
+		metricEvent.AlertingScope = append(
+			metricEvent.AlertingScope,
+			MEAlertingScope{
+				FilterType: "TAG",
+				TagFilter:  tagFilter,
+			})
+	}
+
+	return metricEvent
+}
+
+// the Settings 2.0 schema names the 90th percentile differently than the config API v1
+func toMetricEventSettingsAggregation(aggregationType string) string {
+	if aggregationType == "P90" {
+		return "PERCENTILE90"
+	}
+	return aggregationType
+}
+
+func fromMetricEventSettingsAggregation(aggregation string) string {
+	if aggregation == "PERCENTILE90" {
+		return "P90"
+	}
+	return aggregation
+}
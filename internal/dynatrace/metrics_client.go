@@ -3,6 +3,8 @@ package dynatrace
 import (
 	"encoding/json"
 	"errors"
+	"io"
+	"net/url"
 )
 
 const metricsPath = "/api/v2/metrics"
@@ -16,10 +18,15 @@ type MetricDefinition struct {
 	AggregationTypes   []string `json:"aggregationTypes"`
 	Transformations    []string `json:"transformations"`
 	DefaultAggregation struct {
-		Type string `json:"type"`
+		Type      string  `json:"type"`
+		Parameter float64 `json:"parameter,omitempty"`
 	} `json:"defaultAggregation"`
 	DimensionDefinitions []DimensionDefinition `json:"dimensionDefinitions"`
 	EntityType           []string              `json:"entityType"`
+
+	// ResolutionInfSupported and Created are only returned by GetMetadataByID
+	ResolutionInfSupported *bool  `json:"resolutionInfSupported,omitempty"`
+	Created                *int64 `json:"created,omitempty"`
 }
 
 type DimensionDefinition struct {
@@ -34,6 +41,9 @@ type MetricsQueryResult struct {
 	TotalCount  int                       `json:"totalCount"`
 	NextPageKey string                    `json:"nextPageKey"`
 	Result      []MetricQueryResultValues `json:"result"`
+
+	// Truncated is set if decoding stopped after the maximum number of series, i.e. the result does not contain all series
+	Truncated bool `json:"-"`
 }
 
 type MetricQueryResultValues struct {
@@ -49,6 +59,21 @@ type MetricQueryResultNumbers struct {
 	Values       []float64         `json:"values"`
 }
 
+// MetricsSeriesQueryResult is the first page of the result of a metrics query including the time buckets without data points
+type MetricsSeriesQueryResult struct {
+	Result []MetricQueryResultSeries `json:"result"`
+}
+
+// MetricQueryResultSeries contains the series of a metric, whose values are nil for time buckets without data points
+type MetricQueryResultSeries struct {
+	MetricID string `json:"metricId"`
+	Data     []struct {
+		Dimensions []string   `json:"dimensions"`
+		Timestamps []int64    `json:"timestamps"`
+		Values     []*float64 `json:"values"`
+	} `json:"data"`
+}
+
 // MetricsClient is a client for interacting with the Dynatrace problems endpoints
 type MetricsClient struct {
 	client ClientInterface
@@ -77,23 +102,93 @@ func (mc *MetricsClient) GetByID(metricID string) (*MetricDefinition, error) {
 	return &result, nil
 }
 
-// GetByQuery executes the passed Metrics API Call, validates that the call returns data and returns the data set
-func (mc *MetricsClient) GetByQuery(metricsQuery string) (*MetricsQueryResult, error) {
-	body, err := mc.client.Get(metricsPath + "/query?" + metricsQuery)
+// metricMetadataFields are the fields requested in addition to the default fields of a MetricDefinition by GetMetadataByID
+const metricMetadataFields = "+resolutionInfSupported,+created"
+
+// GetMetadataByID calls the Dynatrace API to retrieve the MetricDefinition including whether resolution=Inf is supported and when the metric was created
+func (mc *MetricsClient) GetMetadataByID(metricID string) (*MetricDefinition, error) {
+	body, err := mc.client.Get(metricsPath + "/" + url.PathEscape(metricID) + "?fields=" + url.QueryEscape(metricMetadataFields))
 	if err != nil {
 		return nil, err
 	}
 
-	var result MetricsQueryResult
+	var result MetricDefinition
 	err = json.Unmarshal(body, &result)
 	if err != nil {
 		return nil, err
 	}
 
+	return &result, nil
+}
+
+// GetByQuery executes the passed Metrics API Call, validates that the call returns data and returns the data set.
+// The data of all pages is merged into the results of the respective metrics
+func (mc *MetricsClient) GetByQuery(metricsQuery string) (*MetricsQueryResult, error) {
+	return mc.GetByQueryWithOptions(metricsQuery, MetricsQueryOptions{})
+}
+
+// GetByQueryWithOptions executes the passed Metrics API Call like GetByQuery, but only returns the series needed according to the options.
+// If more series than the maximum have been found, no further pages are requested and the result is marked as truncated
+func (mc *MetricsClient) GetByQueryWithOptions(metricsQuery string, options MetricsQueryOptions) (*MetricsQueryResult, error) {
+	result := &MetricsQueryResult{}
+	decoder := newMetricsQueryDecoder(options)
+	err := NewPager(mc.client, metricsPath+"/query", metricsQuery).
+		ForEachPageStream(func(body io.Reader) (string, error) {
+			page, err := decoder.decodePage(body)
+			if err != nil {
+				return "", err
+			}
+
+			result.TotalCount = page.TotalCount
+			result.mergeResults(page.Result)
+			if decoder.truncated {
+				result.Truncated = true
+				return "", errStopPaging
+			}
+			return page.NextPageKey, nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
 	if len(result.Result) == 0 {
 		// there are no data points - try again?
 		return nil, errors.New("dynatrace Metrics API returned no DataPoints")
 	}
 
-	return &result, nil
+	return result, nil
+}
+
+// GetSeriesByQuery executes the passed Metrics API Call and returns the series of the first page including the time buckets without data points, e.g. to find gaps in the data
+func (mc *MetricsClient) GetSeriesByQuery(metricsQuery string) (*MetricsSeriesQueryResult, error) {
+	body, err := mc.client.Get(metricsPath + "/query?" + metricsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &MetricsSeriesQueryResult{}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *MetricsQueryResult) mergeResults(results []MetricQueryResultValues) {
+	for _, values := range results {
+		merged := false
+		for i := range r.Result {
+			if r.Result[i].MetricID == values.MetricID {
+				r.Result[i].Data = append(r.Result[i].Data, values.Data...)
+				r.Result[i].Warnings = append(r.Result[i].Warnings, values.Warnings...)
+				merged = true
+				break
+			}
+		}
+
+		if !merged {
+			r.Result = append(r.Result, values)
+		}
+	}
 }
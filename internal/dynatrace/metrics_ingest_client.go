@@ -0,0 +1,100 @@
+package dynatrace
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const metricsIngestPath = "/api/v2/metrics/ingest"
+
+// MetricLine is a single data point sent to the Dynatrace metrics ingest API
+type MetricLine struct {
+	MetricKey  string
+	Dimensions map[string]string
+	Value      float64
+	// IsCounter sends Value as the increment of a counter rather than as a gauge value
+	IsCounter bool
+	// Summary, if set, is sent instead of Value as a gauge summarizing several values
+	Summary *GaugeSummary
+}
+
+// GaugeSummary summarizes several gauge values
+type GaugeSummary struct {
+	Min   float64
+	Max   float64
+	Sum   float64
+	Count int
+}
+
+// String returns the metric line in the Dynatrace metrics ingestion protocol, i.e. metric.key,dim1="a",dim2="b" gauge,42
+func (l MetricLine) String() string {
+	var sb strings.Builder
+	sb.WriteString(l.MetricKey)
+
+	// sort dimension keys to get a stable output
+	keys := make([]string, 0, len(l.Dimensions))
+	for key := range l.Dimensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		sb.WriteString(fmt.Sprintf(",%s=%s", key, quoteDimensionValue(l.Dimensions[key])))
+	}
+
+	switch {
+	case l.Summary != nil:
+		sb.WriteString(fmt.Sprintf(" gauge,min=%s,max=%s,sum=%s,count=%d", formatMetricValue(l.Summary.Min), formatMetricValue(l.Summary.Max), formatMetricValue(l.Summary.Sum), l.Summary.Count))
+	case l.IsCounter:
+		sb.WriteString(" count,delta=")
+		sb.WriteString(formatMetricValue(l.Value))
+	default:
+		sb.WriteString(" gauge,")
+		sb.WriteString(formatMetricValue(l.Value))
+	}
+
+	return sb.String()
+}
+
+func formatMetricValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func quoteDimensionValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + value + `"`
+}
+
+// MetricsIngestClient is a client for ingesting metrics into Dynatrace
+type MetricsIngestClient struct {
+	client ClientInterface
+}
+
+// NewMetricsIngestClient creates a new MetricsIngestClient
+func NewMetricsIngestClient(client ClientInterface) *MetricsIngestClient {
+	return &MetricsIngestClient{
+		client: client,
+	}
+}
+
+// IngestMetrics sends the metric lines to the Dynatrace metrics ingest API
+func (mc *MetricsIngestClient) IngestMetrics(lines []MetricLine) error {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	payload := make([]string, len(lines))
+	for i, line := range lines {
+		payload[i] = line.String()
+	}
+
+	_, err := mc.client.PostPlainText(metricsIngestPath, []byte(strings.Join(payload, "\n")))
+	if err != nil {
+		return fmt.Errorf("could not ingest metrics: %w", err)
+	}
+
+	return nil
+}
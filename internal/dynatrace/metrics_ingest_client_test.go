@@ -0,0 +1,64 @@
+package dynatrace
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricLine_String(t *testing.T) {
+	line := MetricLine{
+		MetricKey: "keptn.evaluation.score",
+		Dimensions: map[string]string{
+			"stage":   "production",
+			"project": "sockshop",
+			"service": `car"ts`,
+		},
+		Value: 87.5,
+	}
+
+	assert.Equal(t, `keptn.evaluation.score,project="sockshop",service="car\"ts",stage="production" gauge,87.5`, line.String())
+}
+
+func TestMetricLine_StringCounterAndSummary(t *testing.T) {
+	counter := MetricLine{
+		MetricKey:  "keptn.dynatrace_service.events_handled",
+		Dimensions: map[string]string{"event_type": "sh.keptn.event.get-sli.triggered"},
+		Value:      3,
+		IsCounter:  true,
+	}
+	assert.Equal(t, `keptn.dynatrace_service.events_handled,event_type="sh.keptn.event.get-sli.triggered" count,delta=3`, counter.String())
+
+	summary := MetricLine{
+		MetricKey:  "keptn.dynatrace_service.api_call.duration",
+		Dimensions: map[string]string{"api": "dynatrace"},
+		Summary:    &GaugeSummary{Min: 12.5, Max: 300, Sum: 412.5, Count: 3},
+	}
+	assert.Equal(t, `keptn.dynatrace_service.api_call.duration,api="dynatrace" gauge,min=12.5,max=300,sum=412.5,count=3`, summary.String())
+}
+
+func TestMetricsIngestClient_IngestMetrics(t *testing.T) {
+	var contentType string
+	var body string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		w.WriteHeader(http.StatusAccepted)
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	err := NewMetricsIngestClient(dtClient).IngestMetrics(
+		[]MetricLine{
+			{MetricKey: "keptn.evaluation.score", Dimensions: map[string]string{"project": "sockshop"}, Value: 100},
+			{MetricKey: "keptn.evaluation.score", Dimensions: map[string]string{"project": "podtato"}, Value: 0},
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", contentType)
+	assert.Equal(t, "keptn.evaluation.score,project=\"sockshop\" gauge,100\nkeptn.evaluation.score,project=\"podtato\" gauge,0", body)
+}
@@ -0,0 +1,197 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// MetricsQueryOptions restrict the series decoded from the responses of metrics queries
+type MetricsQueryOptions struct {
+	// IsWantedMetricID returns whether the series of the metric are needed. The series of all metrics are decoded if it is nil
+	IsWantedMetricID func(metricID string) bool
+	// MaxSeries stops decoding once more than this number of series of wanted metrics have been found. 0 means no limit
+	MaxSeries int
+}
+
+func (o MetricsQueryOptions) isWantedMetricID(metricID string) bool {
+	return o.IsWantedMetricID == nil || o.IsWantedMetricID(metricID)
+}
+
+// metricsQueryDecoder decodes the pages of metrics query responses token by token, so that the series of unwanted metrics
+// are skipped instead of being unmarshalled and decoding stops as soon as more series than needed have been found
+type metricsQueryDecoder struct {
+	options     MetricsQueryOptions
+	decoder     *json.Decoder
+	seriesCount int
+	truncated   bool
+}
+
+func newMetricsQueryDecoder(options MetricsQueryOptions) *metricsQueryDecoder {
+	return &metricsQueryDecoder{
+		options: options,
+	}
+}
+
+// decodePage decodes a page of a metrics query response while reading it from the body. If decoding stopped early, the rest of the body is not read
+// and the returned page only contains the series found so far
+func (d *metricsQueryDecoder) decodePage(body io.Reader) (*MetricsQueryResult, error) {
+	d.decoder = json.NewDecoder(body)
+
+	page := &MetricsQueryResult{}
+	err := d.decodeObject(func(key string) error {
+		switch key {
+		case "totalCount":
+			return d.decoder.Decode(&page.TotalCount)
+		case "nextPageKey":
+			var nextPageKey *string
+			err := d.decoder.Decode(&nextPageKey)
+			if err == nil && nextPageKey != nil {
+				page.NextPageKey = *nextPageKey
+			}
+			return err
+		case "result":
+			return d.decodeArray(func() error {
+				values, err := d.decodeResultValues()
+				if err != nil {
+					return err
+				}
+				page.Result = append(page.Result, *values)
+				return nil
+			})
+		default:
+			return d.skipValue()
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not decode metrics query result: %w", err)
+	}
+	return page, nil
+}
+
+// decodeResultValues decodes the series of a metric. The metric ID and warnings of unwanted metrics are kept, but their series are skipped
+func (d *metricsQueryDecoder) decodeResultValues() (*MetricQueryResultValues, error) {
+	values := &MetricQueryResultValues{}
+	metricIDDecoded := false
+	err := d.decodeObject(func(key string) error {
+		switch key {
+		case "metricId":
+			metricIDDecoded = true
+			return d.decoder.Decode(&values.MetricID)
+		case "warnings":
+			return d.decoder.Decode(&values.Warnings)
+		case "data":
+			if metricIDDecoded && !d.options.isWantedMetricID(values.MetricID) {
+				return d.skipValue()
+			}
+			return d.decodeArray(func() error {
+				series := MetricQueryResultNumbers{}
+				err := d.decoder.Decode(&series)
+				if err != nil {
+					return err
+				}
+				values.Data = append(values.Data, series)
+
+				// the series of metrics whose ID follows their data are only filtered afterwards and therefore not counted
+				if metricIDDecoded {
+					d.countSeries()
+				}
+				return nil
+			})
+		default:
+			return d.skipValue()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !d.options.isWantedMetricID(values.MetricID) {
+		values.Data = nil
+	}
+	return values, nil
+}
+
+func (d *metricsQueryDecoder) countSeries() {
+	d.seriesCount++
+	if d.options.MaxSeries > 0 && d.seriesCount > d.options.MaxSeries {
+		d.truncated = true
+	}
+}
+
+// decodeObject calls decodeValue with the key of each member of a JSON object, which must decode or skip the value.
+// It returns without consuming the rest of the object once decoding has been truncated
+func (d *metricsQueryDecoder) decodeObject(decodeValue func(key string) error) error {
+	err := d.expectDelim('{')
+	if err != nil {
+		return err
+	}
+
+	for d.decoder.More() {
+		token, err := d.decoder.Token()
+		if err != nil {
+			return err
+		}
+
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected an object key but found %v", token)
+		}
+
+		err = decodeValue(key)
+		if err != nil {
+			return err
+		}
+		if d.truncated {
+			return nil
+		}
+	}
+
+	return d.expectDelim('}')
+}
+
+// decodeArray calls decodeElement for each element of a JSON array, which must decode the element.
+// It returns without consuming the rest of the array once decoding has been truncated
+func (d *metricsQueryDecoder) decodeArray(decodeElement func() error) error {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	// null is returned instead of empty arrays
+	if token == nil {
+		return nil
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected an array but found %v", token)
+	}
+
+	for d.decoder.More() {
+		err = decodeElement()
+		if err != nil {
+			return err
+		}
+		if d.truncated {
+			return nil
+		}
+	}
+
+	return d.expectDelim(']')
+}
+
+func (d *metricsQueryDecoder) expectDelim(expected json.Delim) error {
+	token, err := d.decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != expected {
+		return fmt.Errorf("expected %v but found %v", expected, token)
+	}
+	return nil
+}
+
+func (d *metricsQueryDecoder) skipValue() error {
+	var skipped json.RawMessage
+	return d.decoder.Decode(&skipped)
+}
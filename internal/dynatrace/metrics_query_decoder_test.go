@@ -0,0 +1,250 @@
+package dynatrace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const testMetricsQueryPage = `{
+	"totalCount": 3,
+	"nextPageKey": null,
+	"resolution": "Inf",
+	"result": [
+		{
+			"metricId": "builtin:service.errors.total.count",
+			"data": [{"dimensions": ["SERVICE-1"], "timestamps": [1], "values": [1]}],
+			"warnings": ["The metric has no data"]
+		},
+		{
+			"metricId": "builtin:service.response.time",
+			"data": [
+				{"dimensions": ["SERVICE-1"], "dimensionMap": {"dt.entity.service": "SERVICE-1"}, "timestamps": [1], "values": [100]},
+				{"dimensions": ["SERVICE-2"], "dimensionMap": {"dt.entity.service": "SERVICE-2"}, "timestamps": [1], "values": [200]}
+			]
+		}
+	]
+}`
+
+func isResponseTimeMetricID(metricID string) bool {
+	return metricID == "builtin:service.response.time"
+}
+
+func TestMetricsQueryDecoder_DecodePage(t *testing.T) {
+	tests := []struct {
+		name              string
+		body              string
+		options           MetricsQueryOptions
+		expectedResult    *MetricsQueryResult
+		expectedTruncated bool
+		expectedErr       string
+	}{
+		{
+			name: "all series",
+			body: testMetricsQueryPage,
+			expectedResult: &MetricsQueryResult{
+				TotalCount: 3,
+				Result: []MetricQueryResultValues{
+					{
+						MetricID: "builtin:service.errors.total.count",
+						Data:     []MetricQueryResultNumbers{{Dimensions: []string{"SERVICE-1"}, Timestamps: []int64{1}, Values: []float64{1}}},
+						Warnings: []string{"The metric has no data"},
+					},
+					{
+						MetricID: "builtin:service.response.time",
+						Data: []MetricQueryResultNumbers{
+							{Dimensions: []string{"SERVICE-1"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-1"}, Timestamps: []int64{1}, Values: []float64{100}},
+							{Dimensions: []string{"SERVICE-2"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-2"}, Timestamps: []int64{1}, Values: []float64{200}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "series of unwanted metrics are skipped",
+			body:    testMetricsQueryPage,
+			options: MetricsQueryOptions{IsWantedMetricID: isResponseTimeMetricID},
+			expectedResult: &MetricsQueryResult{
+				TotalCount: 3,
+				Result: []MetricQueryResultValues{
+					{
+						MetricID: "builtin:service.errors.total.count",
+						Warnings: []string{"The metric has no data"},
+					},
+					{
+						MetricID: "builtin:service.response.time",
+						Data: []MetricQueryResultNumbers{
+							{Dimensions: []string{"SERVICE-1"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-1"}, Timestamps: []int64{1}, Values: []float64{100}},
+							{Dimensions: []string{"SERVICE-2"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-2"}, Timestamps: []int64{1}, Values: []float64{200}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "decoding stops after more than the maximum number of series",
+			body:    testMetricsQueryPage,
+			options: MetricsQueryOptions{IsWantedMetricID: isResponseTimeMetricID, MaxSeries: 1},
+			expectedResult: &MetricsQueryResult{
+				TotalCount: 3,
+				Result: []MetricQueryResultValues{
+					{
+						MetricID: "builtin:service.errors.total.count",
+						Warnings: []string{"The metric has no data"},
+					},
+					{
+						MetricID: "builtin:service.response.time",
+						Data: []MetricQueryResultNumbers{
+							{Dimensions: []string{"SERVICE-1"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-1"}, Timestamps: []int64{1}, Values: []float64{100}},
+							{Dimensions: []string{"SERVICE-2"}, DimensionMap: map[string]string{"dt.entity.service": "SERVICE-2"}, Timestamps: []int64{1}, Values: []float64{200}},
+						},
+					},
+				},
+			},
+			expectedTruncated: true,
+		},
+		{
+			name:    "series of unwanted metrics whose ID follows the data are dropped",
+			body:    `{"totalCount": 1, "result": [{"data": [{"dimensions": [], "timestamps": [1], "values": [1]}], "metricId": "builtin:service.errors.total.count"}]}`,
+			options: MetricsQueryOptions{IsWantedMetricID: isResponseTimeMetricID},
+			expectedResult: &MetricsQueryResult{
+				TotalCount: 1,
+				Result:     []MetricQueryResultValues{{MetricID: "builtin:service.errors.total.count"}},
+			},
+		},
+		{
+			name: "next page key and null data",
+			body: `{"totalCount": 0, "nextPageKey": "page-2", "result": [{"metricId": "builtin:service.response.time", "data": null}]}`,
+			expectedResult: &MetricsQueryResult{
+				NextPageKey: "page-2",
+				Result:      []MetricQueryResultValues{{MetricID: "builtin:service.response.time"}},
+			},
+		},
+		{
+			name:        "result is not an array",
+			body:        `{"totalCount": 0, "result": {"metricId": "builtin:service.response.time"}}`,
+			expectedErr: "could not decode metrics query result: expected an array but found {",
+		},
+		{
+			name:        "invalid JSON",
+			body:        `{"totalCount": 0, "result": [`,
+			expectedErr: "could not decode metrics query result: unexpected end of JSON input",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newMetricsQueryDecoder(tt.options)
+			result, err := decoder.decodePage(strings.NewReader(tt.body))
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedResult, result)
+			assert.Equal(t, tt.expectedTruncated, decoder.truncated)
+		})
+	}
+}
+
+// failingReader fails all reads, e.g. to check that the rest of a body is not read
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("body read after decoding stopped")
+}
+
+func TestMetricsQueryDecoder_DecodePageStopsReadingBodyWhenTruncated(t *testing.T) {
+	body := io.MultiReader(
+		strings.NewReader(`{"totalCount":3,"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":["SERVICE-1"],"timestamps":[1],"values":[1]},{"dimensions":["SERVICE-2"],"timestamps":[1],"values":[2]}`),
+		failingReader{})
+
+	decoder := newMetricsQueryDecoder(MetricsQueryOptions{MaxSeries: 1})
+	result, err := decoder.decodePage(body)
+
+	assert.NoError(t, err)
+	assert.True(t, decoder.truncated)
+	if assert.Len(t, result.Result, 1) {
+		assert.Len(t, result.Result[0].Data, 2)
+	}
+}
+
+func TestMetricsClient_GetByQueryWithOptionsStopsPagingWhenTruncated(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(metricsPath+"/query?metricSelector=builtin:service.response.time", []byte(`{"totalCount":3,"nextPageKey":"page-2","result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":["SERVICE-1"],"timestamps":[1],"values":[1]},{"dimensions":["SERVICE-2"],"timestamps":[1],"values":[2]}]}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	result, err := NewMetricsClient(dtClient).GetByQueryWithOptions("metricSelector=builtin:service.response.time", MetricsQueryOptions{MaxSeries: 1})
+	assert.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, 3, result.TotalCount)
+	if assert.Len(t, result.Result, 1) {
+		assert.Len(t, result.Result[0].Data, 2)
+	}
+}
+
+// createLargeMetricsQueryPage returns a page of a metrics query split by a dimension with many values, as returned for high-cardinality splitBy queries
+func createLargeMetricsQueryPage(seriesCount int) []byte {
+	data := make([]MetricQueryResultNumbers, seriesCount)
+	for i := range data {
+		entityID := fmt.Sprintf("SERVICE-%016X", i)
+		data[i] = MetricQueryResultNumbers{
+			Dimensions:   []string{entityID},
+			DimensionMap: map[string]string{"dt.entity.service": entityID},
+			Timestamps:   []int64{1632835320000, 1632835380000, 1632835440000},
+			Values:       []float64{12893.95, 807, 1045.5},
+		}
+	}
+
+	body, _ := json.Marshal(MetricsQueryResult{
+		TotalCount: seriesCount,
+		Result: []MetricQueryResultValues{
+			{MetricID: "builtin:service.response.time:splitBy(\"dt.entity.service\")", Data: data},
+		},
+	})
+	return body
+}
+
+func BenchmarkMetricsQueryDecoding(b *testing.B) {
+	body := createLargeMetricsQueryPage(20000)
+
+	b.Run("unmarshal", func(b *testing.B) {
+		b.SetBytes(int64(len(body)))
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			result := &MetricsQueryResult{}
+			if err := json.Unmarshal(body, result); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	benchmarks := []struct {
+		name    string
+		options MetricsQueryOptions
+	}{
+		{name: "all series"},
+		{name: "single series", options: MetricsQueryOptions{MaxSeries: 1}},
+		{name: "unwanted metric", options: MetricsQueryOptions{IsWantedMetricID: isResponseTimeMetricID}},
+	}
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(int64(len(body)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := newMetricsQueryDecoder(bm.options).decodePage(bytes.NewReader(body)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
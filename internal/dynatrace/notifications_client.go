@@ -9,6 +9,9 @@ import (
 
 const keptnProblemNotificationName = "Keptn Problem Notification"
 
+// keptnProblemNotificationWebhookPayload is the payload sent by the Keptn problem notification webhook
+const keptnProblemNotificationWebhookPayload = "{\n    \"specversion\":\"1.0\",\n    \"type\":\"sh.keptn.events.problem\",\n    \"shkeptncontext\":\"{PID}\",\n    \"source\":\"dynatrace\",\n    \"id\":\"{PID}\",\n    \"time\":\"\",\n    \"contenttype\":\"application/json\",\n    \"data\": {\n        \"State\":\"{State}\",\n        \"ProblemID\":\"{ProblemID}\",\n        \"PID\":\"{PID}\",\n        \"ProblemTitle\":\"{ProblemTitle}\",\n        \"ProblemURL\":\"{ProblemURL}\",\n        \"ProblemDetails\":{ProblemDetailsJSON},\n        \"Tags\":\"{Tags}\",\n        \"ImpactedEntities\":{ImpactedEntities},\n        \"ImpactedEntity\":\"{ImpactedEntity}\"\n    }\n}\n"
+
 const problemNotificationPayload string = `{ 
       "type": "WEBHOOK", 
       "name": "$KEPTN_PROBLEM_NOTIFICATION_NAME", 
@@ -20,7 +23,7 @@ const problemNotificationPayload string = `{
         { "name": "x-token", "value": "$KEPTN_TOKEN" },
         { "name": "Content-Type", "value": "application/cloudevents+json" }
       ],
-      "payload": "{\n    \"specversion\":\"1.0\",\n    \"type\":\"sh.keptn.events.problem\",\n    \"shkeptncontext\":\"{PID}\",\n    \"source\":\"dynatrace\",\n    \"id\":\"{PID}\",\n    \"time\":\"\",\n    \"contenttype\":\"application/json\",\n    \"data\": {\n        \"State\":\"{State}\",\n        \"ProblemID\":\"{ProblemID}\",\n        \"PID\":\"{PID}\",\n        \"ProblemTitle\":\"{ProblemTitle}\",\n        \"ProblemURL\":\"{ProblemURL}\",\n        \"ProblemDetails\":{ProblemDetailsJSON},\n        \"Tags\":\"{Tags}\",\n        \"ImpactedEntities\":{ImpactedEntities},\n        \"ImpactedEntity\":\"{ImpactedEntity}\"\n    }\n}\n" 
+      "payload": $KEPTN_PROBLEM_NOTIFICATION_PAYLOAD
 
       }`
 
@@ -45,6 +48,12 @@ func (ne *NotificationsError) Error() string {
 
 const notificationsPath = "/api/config/v1/notifications"
 
+// NotificationsClientInterface creates and deletes Keptn problem notifications
+type NotificationsClientInterface interface {
+	DeleteExistingKeptnProblemNotifications() error
+	Create(credentials *credentials.KeptnAPICredentials, alertingProfileID string) error
+}
+
 type NotificationsClient struct {
 	client ClientInterface
 }
@@ -98,13 +107,19 @@ func (nc *NotificationsClient) DeleteExistingKeptnProblemNotifications() error {
 
 // Create creates a new default notification for the given KeptnAPICredentials and the alertingProfileID
 func (nc *NotificationsClient) Create(credentials *credentials.KeptnAPICredentials, alertingProfileID string) error {
+	webhookPayload, err := json.Marshal(keptnProblemNotificationWebhookPayload)
+	if err != nil {
+		return err
+	}
+
 	notification := problemNotificationPayload
 	notification = strings.ReplaceAll(notification, "$KEPTN_DNS", credentials.APIURL)
 	notification = strings.ReplaceAll(notification, "$KEPTN_TOKEN", credentials.APIToken)
 	notification = strings.ReplaceAll(notification, "$ALERTING_PROFILE_ID", alertingProfileID)
 	notification = strings.ReplaceAll(notification, "$KEPTN_PROBLEM_NOTIFICATION_NAME", keptnProblemNotificationName)
+	notification = strings.ReplaceAll(notification, "$KEPTN_PROBLEM_NOTIFICATION_PAYLOAD", string(webhookPayload))
 
-	_, err := nc.client.Post(notificationsPath, []byte(notification))
+	_, err = nc.client.Post(notificationsPath, []byte(notification))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,101 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+)
+
+type problemNotificationSettings struct {
+	Enabled             bool                         `json:"enabled"`
+	Type                string                       `json:"type"`
+	DisplayName         string                       `json:"displayName"`
+	AlertingProfile     string                       `json:"alertingProfile"`
+	WebHookNotification *webHookNotificationSettings `json:"webHookNotification,omitempty"`
+}
+
+type webHookNotificationSettings struct {
+	URL                      string                              `json:"url"`
+	AcceptAnyCertificate     bool                                `json:"acceptAnyCertificate"`
+	NotifyEventMergesEnabled bool                                `json:"notifyEventMergesEnabled"`
+	Headers                  []webHookNotificationSettingsHeader `json:"headers"`
+	Payload                  string                              `json:"payload"`
+}
+
+type webHookNotificationSettingsHeader struct {
+	Name        string `json:"name"`
+	Secret      bool   `json:"secret"`
+	Value       string `json:"value,omitempty"`
+	SecretValue string `json:"secretValue,omitempty"`
+}
+
+// NotificationsSettingsClient creates and deletes Keptn problem notifications using the Settings 2.0 API
+type NotificationsSettingsClient struct {
+	client *SettingsClient
+}
+
+// NewNotificationsSettingsClient creates a new NotificationsSettingsClient
+func NewNotificationsSettingsClient(client *SettingsClient) *NotificationsSettingsClient {
+	return &NotificationsSettingsClient{
+		client: client,
+	}
+}
+
+// DeleteExistingKeptnProblemNotifications deletes all problem notifications named like the Keptn problem notification
+func (nc *NotificationsSettingsClient) DeleteExistingKeptnProblemNotifications() error {
+	objects, err := nc.client.List(ProblemNotificationsSchemaID, EnvironmentScope)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve notifications: %w", err)
+	}
+
+	notificationError := &NotificationsError{}
+	for _, object := range objects {
+		notification := &problemNotificationSettings{}
+		err := json.Unmarshal(object.Value, notification)
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal notification %s: %w", object.ObjectID, err)
+		}
+
+		if notification.DisplayName == keptnProblemNotificationName {
+			err := nc.client.Delete(object.ObjectID)
+			if err != nil {
+				// Error occurred but continue
+				notificationError.errors = append(
+					notificationError.errors,
+					fmt.Errorf("failed to delete notification with ID: %s", object.ObjectID))
+			}
+		}
+	}
+
+	if notificationError.HasErrors() {
+		return notificationError
+	}
+
+	return nil
+}
+
+// Create creates a new default notification for the given KeptnAPICredentials and the alertingProfileID as a settings object of schema builtin:problem.notifications
+func (nc *NotificationsSettingsClient) Create(credentials *credentials.KeptnAPICredentials, alertingProfileID string) error {
+	_, err := nc.client.Create(
+		SettingsObjectCreate{
+			SchemaID: ProblemNotificationsSchemaID,
+			Scope:    EnvironmentScope,
+			Value: &problemNotificationSettings{
+				Enabled:         true,
+				Type:            "WEBHOOK",
+				DisplayName:     keptnProblemNotificationName,
+				AlertingProfile: alertingProfileID,
+				WebHookNotification: &webHookNotificationSettings{
+					URL:                  credentials.APIURL + "/v1/event",
+					AcceptAnyCertificate: true,
+					Headers: []webHookNotificationSettingsHeader{
+						{Name: "x-token", Secret: true, SecretValue: credentials.APIToken},
+						{Name: "Content-Type", Secret: false, Value: "application/cloudevents+json"},
+					},
+					Payload: keptnProblemNotificationWebhookPayload,
+				},
+			},
+		})
+	return err
+}
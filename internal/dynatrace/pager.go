@@ -0,0 +1,121 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// nextPageKeyResponse contains the nextPageKey that all paginated endpoints of the Dynatrace API v2 return
+type nextPageKeyResponse struct {
+	NextPageKey string `json:"nextPageKey"`
+}
+
+// errStopPaging can be returned by the handler of a page to stop the Pager without an error
+var errStopPaging = errors.New("stop paging")
+
+// defaultMaxPages is the maximum number of pages retrieved using clients that do not limit it themselves
+const defaultMaxPages = 100
+
+// PageLimitingClientInterface is implemented by clients that limit the number of pages a Pager retrieves using them
+type PageLimitingClientInterface interface {
+	// MaxPages returns the maximum number of pages retrieved
+	MaxPages() int
+}
+
+// Pager retrieves all pages of a paginated endpoint of the Dynatrace API v2. The first page is requested using the query,
+// all subsequent ones only using the nextPageKey, as the API does not allow combining them
+type Pager struct {
+	client   ClientInterface
+	path     string
+	query    string
+	maxPages int
+}
+
+// NewPager creates a new Pager for the given path and query that retrieves at most the number of pages the client is limited to, see PageLimitingClientInterface
+func NewPager(client ClientInterface, path string, query string) *Pager {
+	maxPages := defaultMaxPages
+	if pageLimitingClient, ok := client.(PageLimitingClientInterface); ok {
+		maxPages = pageLimitingClient.MaxPages()
+	}
+
+	return &Pager{
+		client:   client,
+		path:     path,
+		query:    query,
+		maxPages: maxPages,
+	}
+}
+
+// WithMaxPages sets the maximum number of pages retrieved
+func (p *Pager) WithMaxPages(maxPages int) *Pager {
+	p.maxPages = maxPages
+	return p
+}
+
+// ForEachPage calls handlePage with the body of each page until there are no more pages or handlePage returns an error, errStopPaging stops without an error.
+// It returns an error if there are more pages than the maximum number of pages
+func (p *Pager) ForEachPage(handlePage func(body []byte) error) error {
+	return p.forEachPage(func(apiPath string) (string, error) {
+		body, err := p.client.Get(apiPath)
+		if err != nil {
+			return "", err
+		}
+
+		err = handlePage(body)
+		if err != nil {
+			return "", err
+		}
+
+		response := &nextPageKeyResponse{}
+		err = json.Unmarshal(body, response)
+		if err != nil {
+			return "", fmt.Errorf("could not parse nextPageKey: %w", err)
+		}
+		return response.NextPageKey, nil
+	})
+}
+
+// ForEachPageStream calls decodePage with the body of each page while it is being received, so that it does not have to be read completely.
+// decodePage must return the nextPageKey of the page, otherwise it behaves like ForEachPage
+func (p *Pager) ForEachPageStream(decodePage func(body io.Reader) (string, error)) error {
+	return p.forEachPage(func(apiPath string) (string, error) {
+		var nextPageKey string
+		err := getStream(p.client, apiPath, func(body io.Reader) error {
+			var err error
+			nextPageKey, err = decodePage(body)
+			return err
+		})
+		return nextPageKey, err
+	})
+}
+
+// forEachPage calls getPage with the API path of each page, which returns the nextPageKey of the page
+func (p *Pager) forEachPage(getPage func(apiPath string) (string, error)) error {
+	apiPath := p.path
+	if p.query != "" {
+		apiPath += "?" + p.query
+	}
+
+	for page := 1; ; page++ {
+		nextPageKey, err := getPage(apiPath)
+		if errors.Is(err, errStopPaging) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if nextPageKey == "" {
+			return nil
+		}
+
+		if page >= p.maxPages {
+			return fmt.Errorf("%s returned more than the maximum of %d pages", p.path, p.maxPages)
+		}
+
+		apiPath = p.path + "?nextPageKey=" + url.QueryEscape(nextPageKey)
+	}
+}
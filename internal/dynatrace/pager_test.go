@@ -0,0 +1,71 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPager_ForEachPage(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type(SERVICE)", []byte(`{"entities":[{"entityId":"SERVICE-1"}],"nextPageKey":"AQAAABQBAAAABQ=="}`))
+	handler.AddExact(entitiesPath+"?nextPageKey=AQAAABQBAAAABQ%3D%3D", []byte(`{"entities":[{"entityId":"SERVICE-2"}],"nextPageKey":null}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	var entityIDs []string
+	err := NewPager(dtClient, entitiesPath, "entitySelector=type(SERVICE)").
+		ForEachPage(func(body []byte) error {
+			page := &EntitiesResponse{}
+			err := json.Unmarshal(body, page)
+			if err != nil {
+				return err
+			}
+
+			for _, entity := range page.Entities {
+				entityIDs = append(entityIDs, entity.EntityID)
+			}
+			return nil
+		})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SERVICE-1", "SERVICE-2"}, entityIDs)
+}
+
+func TestPager_ForEachPageStopsAtMaxPages(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWith(entitiesPath, []byte(`{"entities":[],"nextPageKey":"next"}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	pages := 0
+	err := NewPager(dtClient, entitiesPath, "").
+		WithMaxPages(3).
+		ForEachPage(func(body []byte) error {
+			pages++
+			return nil
+		})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "more than the maximum of 3 pages")
+	assert.Equal(t, 3, pages)
+}
+
+func TestMetricsClient_GetByQueryMergesPages(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(metricsPath+"/query?metricSelector=builtin:service.errors.total.count", []byte(`{"totalCount":2,"nextPageKey":"page-2","result":[{"metricId":"builtin:service.errors.total.count","data":[{"dimensions":["SERVICE-1"],"timestamps":[1],"values":[1]}]}]}`))
+	handler.AddExact(metricsPath+"/query?nextPageKey=page-2", []byte(`{"totalCount":2,"nextPageKey":null,"result":[{"metricId":"builtin:service.errors.total.count","data":[{"dimensions":["SERVICE-2"],"timestamps":[1],"values":[2]}]}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	result, err := NewMetricsClient(dtClient).GetByQuery("metricSelector=builtin:service.errors.total.count")
+	assert.NoError(t, err)
+	if assert.Len(t, result.Result, 1) {
+		assert.Len(t, result.Result[0].Data, 2)
+	}
+}
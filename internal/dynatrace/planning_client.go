@@ -0,0 +1,188 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+)
+
+// plannedObjectID is returned as the ID of objects the PlanningClient pretends to create
+const plannedObjectID = "planned"
+
+// configurationKinds maps the API paths and Settings 2.0 schema IDs of configuration objects to a readable name
+var configurationKinds = map[string]string{
+	autoTagsPath:                 "auto tagging rule",
+	managementZonesPath:          "management zone",
+	alertingProfilesPath:         "alerting profile",
+	notificationsPath:            "problem notification",
+	metricEventsPath:             "metric event",
+	dashboardsPath:               "dashboard",
+	AutoTaggingSchemaID:          "auto tagging rule",
+	AlertingProfileSchemaID:      "alerting profile",
+	ProblemNotificationsSchemaID: "problem notification",
+	MetricEventsSchemaID:         "metric event",
+}
+
+// PlannedChange is a change of the Dynatrace configuration the PlanningClient did not apply
+type PlannedChange struct {
+	// Action is one of create, update or delete
+	Action string
+	// Kind is the type of the configuration object, e.g. management zone
+	Kind string
+	// Name is the name of the configuration object or its ID if the name is unknown
+	Name string
+}
+
+// String returns a description of the change, e.g. create management zone: Keptn: sockshop
+func (c PlannedChange) String() string {
+	if c.Name == "" {
+		return c.Action + " " + c.Kind
+	}
+	return c.Action + " " + c.Kind + ": " + c.Name
+}
+
+// PlanningClient is a ClientInterface that executes read requests, but only records write requests as planned changes instead of sending them to Dynatrace
+type PlanningClient struct {
+	client ClientInterface
+
+	mutex   sync.Mutex
+	changes []PlannedChange
+}
+
+// NewPlanningClient creates a new PlanningClient reading from the given client
+func NewPlanningClient(client ClientInterface) *PlanningClient {
+	return &PlanningClient{
+		client: client,
+	}
+}
+
+// Get executes the GET request
+func (pc *PlanningClient) Get(apiPath string) ([]byte, error) {
+	return pc.client.Get(apiPath)
+}
+
+// GetStream executes the GET request, streaming the body if the underlying client supports it
+func (pc *PlanningClient) GetStream(apiPath string, handleBody func(body io.Reader) error) error {
+	return getStream(pc.client, apiPath, handleBody)
+}
+
+// Post records the creation of the objects in the body. The API token lookup is executed, as it does not change the configuration
+func (pc *PlanningClient) Post(apiPath string, body []byte) ([]byte, error) {
+	path := stripQuery(apiPath)
+	if path == apiTokensLookupPath || path == apiTokensV1LookupPath {
+		return pc.client.Post(apiPath, body)
+	}
+
+	if path == settingsObjectsPath {
+		var objects []SettingsObjectCreate
+		if err := json.Unmarshal(body, &objects); err != nil {
+			return nil, fmt.Errorf("could not parse settings objects: %w", err)
+		}
+
+		responses := make([]settingsObjectResponse, len(objects))
+		for i, object := range objects {
+			value, _ := json.Marshal(object.Value)
+			pc.record("create", getConfigurationKind(object.SchemaID), getConfigurationObjectName(value))
+			responses[i] = settingsObjectResponse{Code: 200, ObjectID: plannedObjectID}
+		}
+		return json.Marshal(responses)
+	}
+
+	pc.record("create", getConfigurationKind(path), getConfigurationObjectName(body))
+	return json.Marshal(values{ID: plannedObjectID})
+}
+
+// PostPlainText records the request without sending it
+func (pc *PlanningClient) PostPlainText(apiPath string, body []byte) ([]byte, error) {
+	pc.record("create", getConfigurationKind(stripQuery(apiPath)), "")
+	return nil, nil
+}
+
+// Put records the update of the object in the body
+func (pc *PlanningClient) Put(apiPath string, body []byte) ([]byte, error) {
+	path := stripQuery(apiPath)
+	name := getConfigurationObjectName(body)
+	if name == "" {
+		name = getLastPathSegment(path)
+	}
+
+	pc.record("update", getConfigurationKind(path), name)
+	return nil, nil
+}
+
+// Delete records the deletion of the object
+func (pc *PlanningClient) Delete(apiPath string) ([]byte, error) {
+	path := stripQuery(apiPath)
+	pc.record("delete", getConfigurationKind(path), getLastPathSegment(path))
+	return nil, nil
+}
+
+// Credentials returns the credentials of the underlying client
+func (pc *PlanningClient) Credentials() *credentials.DTCredentials {
+	return pc.client.Credentials()
+}
+
+// GetPlannedChanges returns the recorded changes in the order they were planned
+func (pc *PlanningClient) GetPlannedChanges() []PlannedChange {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	changes := make([]PlannedChange, len(pc.changes))
+	copy(changes, pc.changes)
+	return changes
+}
+
+func (pc *PlanningClient) record(action string, kind string, name string) {
+	pc.mutex.Lock()
+	defer pc.mutex.Unlock()
+
+	pc.changes = append(pc.changes, PlannedChange{Action: action, Kind: kind, Name: name})
+}
+
+// getConfigurationKind returns the readable name of the configuration object type of the API path or Settings 2.0 schema ID
+func getConfigurationKind(pathOrSchemaID string) string {
+	for prefix, kind := range configurationKinds {
+		if pathOrSchemaID == prefix || strings.HasPrefix(pathOrSchemaID, prefix+"/") {
+			return kind
+		}
+	}
+
+	if strings.HasPrefix(pathOrSchemaID, settingsObjectsPath) {
+		return "settings object"
+	}
+	return pathOrSchemaID
+}
+
+// getConfigurationObjectName returns the name, display name or summary of the configuration object in the body, if it has one
+func getConfigurationObjectName(body []byte) string {
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return ""
+	}
+
+	if value, ok := object["value"].(map[string]interface{}); ok {
+		object = value
+	}
+	if metadata, ok := object["dashboardMetadata"].(map[string]interface{}); ok {
+		object = metadata
+	}
+
+	for _, key := range []string{"name", "displayName", "summary"} {
+		if name, ok := object[key].(string); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func stripQuery(apiPath string) string {
+	return strings.SplitN(apiPath, "?", 2)[0]
+}
+
+func getLastPathSegment(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
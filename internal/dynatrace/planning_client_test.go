@@ -0,0 +1,64 @@
+package dynatrace
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that the PlanningClient reads from Dynatrace, but only records changes of the configuration
+func TestPlanningClient_RecordsChangesWithoutApplyingThem(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected %s request to %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"values":[{"id":"6302461339473453612","name":"Keptn: sockshop"}]}`))
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	planningClient := NewPlanningClient(dtClient)
+
+	managementZones, err := NewManagementZonesClient(planningClient).GetAll()
+	if assert.NoError(t, err) {
+		assert.True(t, managementZones.Contains("Keptn: sockshop"))
+	}
+
+	assert.NoError(t, NewManagementZonesClient(planningClient).Create(&ManagementZone{Name: "Keptn: sockshop staging"}))
+
+	objectIDs, err := NewSettingsClient(planningClient).Create(
+		SettingsObjectCreate{SchemaID: AutoTaggingSchemaID, Scope: EnvironmentScope, Value: map[string]string{"name": "keptn_service"}},
+		SettingsObjectCreate{SchemaID: MetricEventsSchemaID, Scope: EnvironmentScope, Value: map[string]string{"summary": "response_time_p95 (Keptn.sockshop.staging.carts)"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{plannedObjectID, plannedObjectID}, objectIDs)
+	}
+
+	profileID, err := NewAlertingProfilesClient(planningClient).Create(&AlertingProfile{DisplayName: "Keptn"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, plannedObjectID, profileID)
+	}
+
+	assert.NoError(t, NewMetricEventsClient(planningClient).Update(&MetricEvent{ID: "ruxit.python.rabbitmq:node_status:node_failed", Name: "response_time_p95 (Keptn.sockshop.staging.carts)"}))
+	assert.NoError(t, NewDashboardsClient(planningClient).Delete("e03f4be0-4712-4f12-96ee-8c486d001e9b"))
+	assert.NoError(t, NewSettingsClient(planningClient).Delete("vu9U3hXa3q0AAAABABlidWlsdGluOnByb2JsZW0ubm90aWZpY2F0aW9ucw"))
+
+	expectedChanges := []string{
+		"create management zone: Keptn: sockshop staging",
+		"create auto tagging rule: keptn_service",
+		"create metric event: response_time_p95 (Keptn.sockshop.staging.carts)",
+		"create alerting profile: Keptn",
+		"update metric event: response_time_p95 (Keptn.sockshop.staging.carts)",
+		"delete dashboard: e03f4be0-4712-4f12-96ee-8c486d001e9b",
+		"delete settings object: vu9U3hXa3q0AAAABABlidWlsdGluOnByb2JsZW0ubm90aWZpY2F0aW9ucw",
+	}
+
+	var actualChanges []string
+	for _, change := range planningClient.GetPlannedChanges() {
+		actualChanges = append(actualChanges, change.String())
+	}
+	assert.Equal(t, expectedChanges, actualChanges)
+}
@@ -2,6 +2,7 @@ package dynatrace
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"time"
@@ -9,6 +10,8 @@ import (
 
 const problemsV2Path = "/api/v2/problems"
 
+const problemCommentContext = "keptn-remediation"
+
 // ProblemQueryResult Result of /api/v2/problems
 type ProblemQueryResult struct {
 	TotalCount int       `json:"totalCount"`
@@ -75,28 +78,41 @@ func NewProblemsV2Client(client ClientInterface) *ProblemsV2Client {
 // GetByQuery Calls the Dynatrace V2 API to retrieve the the list of problems for that timeframe
 // It returns a ProblemQueryResult object on success, an error otherwise
 func (pc *ProblemsV2Client) GetByQuery(problemQuery string, startUnix time.Time, endUnix time.Time) (*ProblemQueryResult, error) {
-	body, err := pc.client.Get(
-		fmt.Sprintf("%s?from=%s&to=%s&%s",
-			problemsV2Path,
+	result := &ProblemQueryResult{}
+	err := NewPager(
+		pc.client,
+		problemsV2Path,
+		fmt.Sprintf("from=%s&to=%s&%s",
 			common.TimestampToString(startUnix),
 			common.TimestampToString(endUnix),
-			problemQuery))
-	if err != nil {
-		return nil, err
-	}
+			problemQuery)).
+		ForEachPage(func(body []byte) error {
+			page := &ProblemQueryResult{}
+			err := json.Unmarshal(body, page)
+			if err != nil {
+				return err
+			}
 
-	var result ProblemQueryResult
-	err = json.Unmarshal(body, &result)
+			result.TotalCount = page.TotalCount
+			result.PageSize = page.PageSize
+			result.Problems = append(result.Problems, page.Problems...)
+			return nil
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 // GetById Calls the Dynatrace API to retrieve Problem Details for a given problemID
 // It returns a Problem object on success, an error otherwise
 func (pc *ProblemsV2Client) GetById(problemID string) (*Problem, error) {
+	// without an ID, the request would list all problems instead
+	if problemID == "" {
+		return nil, errors.New("could not retrieve problem: no problem ID specified")
+	}
+
 	body, err := pc.client.Get(problemsV2Path + "/" + problemID)
 	if err != nil {
 		return nil, err
@@ -111,3 +127,43 @@ func (pc *ProblemsV2Client) GetById(problemID string) (*Problem, error) {
 
 	return &result, nil
 }
+
+// ProblemCommentRequest is the payload for adding a comment to a problem via /api/v2/problems/{problemId}/comments
+type ProblemCommentRequest struct {
+	Message string `json:"message"`
+	Context string `json:"context,omitempty"`
+}
+
+// ProblemCloseRequest is the payload for closing a problem via /api/v2/problems/{problemId}/close
+type ProblemCloseRequest struct {
+	Message string `json:"message"`
+}
+
+// AddProblemComment adds a comment to the problem with the given problemID
+func (pc *ProblemsV2Client) AddProblemComment(problemID string, comment string) error {
+	payload, err := json.Marshal(
+		ProblemCommentRequest{
+			Message: comment,
+			Context: problemCommentContext,
+		})
+	if err != nil {
+		return err
+	}
+
+	_, err = pc.client.Post(problemsV2Path+"/"+problemID+"/comments", payload)
+	return err
+}
+
+// CloseProblem closes the problem with the given problemID and adds the message as closing comment
+func (pc *ProblemsV2Client) CloseProblem(problemID string, message string) error {
+	payload, err := json.Marshal(
+		ProblemCloseRequest{
+			Message: message,
+		})
+	if err != nil {
+		return err
+	}
+
+	_, err = pc.client.Post(problemsV2Path+"/"+problemID+"/close", payload)
+	return err
+}
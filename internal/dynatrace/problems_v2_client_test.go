@@ -1,9 +1,13 @@
 package dynatrace
 
 import (
-	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"io/ioutil"
+	"net/http"
 	"testing"
 	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestExecuteGetDynatraceProblems(t *testing.T) {
@@ -30,3 +34,32 @@ func TestExecuteGetDynatraceProblems(t *testing.T) {
 		t.Error("Not returning expected value for Problem Query")
 	}
 }
+
+func TestProblemsV2Client_AddProblemCommentAndCloseProblem(t *testing.T) {
+	requests := make(map[string]string)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	problemsClient := NewProblemsV2Client(dtClient)
+	assert.NoError(t, problemsClient.AddProblemComment("123_456V2", "remediation finished"))
+	assert.NoError(t, problemsClient.CloseProblem("123_456V2", "closed by keptn"))
+
+	assert.JSONEq(t, `{"message":"remediation finished","context":"keptn-remediation"}`, requests["POST /api/v2/problems/123_456V2/comments"])
+	assert.JSONEq(t, `{"message":"closed by keptn"}`, requests["POST /api/v2/problems/123_456V2/close"])
+}
+
+func TestProblemsV2Client_GetByIdWithoutProblemID(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	problem, err := NewProblemsV2Client(dtClient).GetById("")
+	assert.Error(t, err)
+	assert.Nil(t, problem)
+}
@@ -0,0 +1,34 @@
+package dynatrace
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProcessGroupInstancesClient is a client for looking up PROCESS_GROUP_INSTANCE entities, i.e. the processes monitored by the OneAgent
+type ProcessGroupInstancesClient struct {
+	entitiesClient *EntitiesClient
+}
+
+// NewProcessGroupInstancesClient creates a new ProcessGroupInstancesClient
+func NewProcessGroupInstancesClient(client ClientInterface) *ProcessGroupInstancesClient {
+	return &ProcessGroupInstancesClient{
+		entitiesClient: NewEntitiesClient(client),
+	}
+}
+
+// GetByEntitySelector returns the process group instances matching the entity selector, e.g. tag("keptn_service:carts"), that were seen during the timeframe.
+// The properties of the process group instances, e.g. their process type and listen ports, are included
+func (c *ProcessGroupInstancesClient) GetByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return c.entitiesClient.QueryForTimeframe(withEntityType(ProcessGroupInstanceEntityType, entitySelector), []string{"+properties"}, 0, startUnix, endUnix)
+}
+
+// CountByEntitySelector returns the number of process group instances matching the entity selector that were seen during the timeframe
+func (c *ProcessGroupInstancesClient) CountByEntitySelector(entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+	return c.entitiesClient.CountEntitiesForTimeframe(withEntityType(ProcessGroupInstanceEntityType, entitySelector), startUnix, endUnix)
+}
+
+// GetByHost returns the process group instances running on the host that were seen during the timeframe
+func (c *ProcessGroupInstancesClient) GetByHost(hostID string, startUnix time.Time, endUnix time.Time) ([]Entity, error) {
+	return c.GetByEntitySelector(fmt.Sprintf("fromRelationships.isProcessOf(entityId(\"%s\"))", escapeEntitySelectorValue(hostID)), startUnix, endUnix)
+}
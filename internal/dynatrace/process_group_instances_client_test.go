@@ -0,0 +1,29 @@
+package dynatrace
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProcessGroupInstancesClient_GetByHost(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(entitiesPath+"?entitySelector=type%28%22PROCESS_GROUP_INSTANCE%22%29%2CfromRelationships.isProcessOf%28entityId%28%22HOST-1%22%29%29&fields=%2Bproperties&from=1571649000000&to=1571649600000",
+		[]byte(`{"totalCount":1,"entities":[{"entityId":"PROCESS_GROUP_INSTANCE-1","type":"PROCESS_GROUP_INSTANCE","displayName":"carts","properties":{"processType":"JAVA","listenPorts":[8080]}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	start := time.Unix(1571649000, 0)
+	processGroupInstances, err := NewProcessGroupInstancesClient(dtClient).GetByHost("HOST-1", start, start.Add(10*time.Minute))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Entity{{
+		EntityID:    "PROCESS_GROUP_INSTANCE-1",
+		Type:        ProcessGroupInstanceEntityType,
+		DisplayName: "carts",
+		Properties:  &EntityProperties{ProcessType: "JAVA", ListenPorts: []int{8080}},
+	}}, processGroupInstances)
+}
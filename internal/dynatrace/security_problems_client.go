@@ -81,21 +81,29 @@ func NewSecurityProblemsClient(client ClientInterface) *SecurityProblemsClient {
 // GetByQuery Calls the Dynatrace API to retrieve the list of security problems for that timeframe.
 // It returns a SecurityProblemQueryResult object on success, an error otherwise.
 func (sc *SecurityProblemsClient) GetByQuery(problemQuery string, startUnix time.Time, endUnix time.Time) (*SecurityProblemQueryResult, error) {
-	body, err := sc.client.Get(
-		fmt.Sprintf("%s?from=%s&to=%s&%s",
-			securityProblemsPath,
+	result := &SecurityProblemQueryResult{}
+	err := NewPager(
+		sc.client,
+		securityProblemsPath,
+		fmt.Sprintf("from=%s&to=%s&%s",
 			common.TimestampToString(startUnix),
 			common.TimestampToString(endUnix),
-			problemQuery))
-	if err != nil {
-		return nil, err
-	}
+			problemQuery)).
+		ForEachPage(func(body []byte) error {
+			page := &SecurityProblemQueryResult{}
+			err := json.Unmarshal(body, page)
+			if err != nil {
+				return err
+			}
 
-	var result SecurityProblemQueryResult
-	err = json.Unmarshal(body, &result)
+			result.TotalCount = page.TotalCount
+			result.PageSize = page.PageSize
+			result.SecurityProblems = append(result.SecurityProblems, page.SecurityProblems...)
+			return nil
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	return &result, nil
+	return result, nil
 }
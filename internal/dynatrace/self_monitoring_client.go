@@ -0,0 +1,42 @@
+package dynatrace
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+)
+
+// SelfMonitoringClient ingests the operational metrics of the dynatrace-service into Dynatrace
+type SelfMonitoringClient struct {
+	client *MetricsIngestClient
+}
+
+// NewSelfMonitoringClient creates a new SelfMonitoringClient
+func NewSelfMonitoringClient(client ClientInterface) *SelfMonitoringClient {
+	return &SelfMonitoringClient{
+		client: NewMetricsIngestClient(client),
+	}
+}
+
+// IngestMeasurements sends counters as increments and gauges as summaries of the recorded values
+func (c *SelfMonitoringClient) IngestMeasurements(measurements []selfmonitoring.Measurement) error {
+	lines := make([]MetricLine, 0, len(measurements))
+	for _, measurement := range measurements {
+		line := MetricLine{
+			MetricKey:  measurement.MetricKey,
+			Dimensions: measurement.Dimensions,
+		}
+		if measurement.IsCounter {
+			line.IsCounter = true
+			line.Value = measurement.Sum
+		} else {
+			line.Summary = &GaugeSummary{
+				Min:   measurement.Min,
+				Max:   measurement.Max,
+				Sum:   measurement.Sum,
+				Count: measurement.Count,
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return c.client.IngestMetrics(lines)
+}
@@ -0,0 +1,188 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const settingsObjectsPath = "/api/v2/settings/objects"
+const settingsSchemasPath = "/api/v2/settings/schemas"
+
+// EnvironmentScope is the scope of settings objects that apply to the whole environment
+const EnvironmentScope = "environment"
+
+// schema IDs of the Settings 2.0 objects configured by the dynatrace-service
+const (
+	AutoTaggingSchemaID          = "builtin:tags.auto-tagging"
+	AlertingProfileSchemaID      = "builtin:alerting.profile"
+	ProblemNotificationsSchemaID = "builtin:problem.notifications"
+	MetricEventsSchemaID         = "builtin:anomaly-detection.metric-events"
+)
+
+const settingsObjectsFields = "objectId,schemaId,scope,value"
+const settingsObjectsPageSize = "500"
+
+// SettingsObject is a Settings 2.0 object as returned by the settings objects API
+type SettingsObject struct {
+	ObjectID string          `json:"objectId"`
+	SchemaID string          `json:"schemaId"`
+	Scope    string          `json:"scope"`
+	Value    json.RawMessage `json:"value"`
+}
+
+// SettingsObjectCreate is a Settings 2.0 object to be created
+type SettingsObjectCreate struct {
+	SchemaID string      `json:"schemaId"`
+	Scope    string      `json:"scope"`
+	Value    interface{} `json:"value"`
+}
+
+type settingsObjectsListResponse struct {
+	Items []SettingsObject `json:"items"`
+}
+
+type settingsObjectUpdate struct {
+	Value interface{} `json:"value"`
+}
+
+type settingsObjectResponse struct {
+	Code     int                  `json:"code"`
+	ObjectID string               `json:"objectId"`
+	Error    *settingsObjectError `json:"error"`
+}
+
+type settingsObjectError struct {
+	Code                 int                  `json:"code"`
+	Message              string               `json:"message"`
+	ConstraintViolations ConstraintViolations `json:"constraintViolations"`
+}
+
+// SettingsClient is a client for listing, creating, updating and deleting Settings 2.0 objects
+type SettingsClient struct {
+	client ClientInterface
+}
+
+// NewSettingsClient creates a new SettingsClient
+func NewSettingsClient(client ClientInterface) *SettingsClient {
+	return &SettingsClient{
+		client: client,
+	}
+}
+
+// IsSchemaSupported returns whether the tenant supports the schema with the given ID. It returns false without an error
+// if the tenant does not provide the Settings 2.0 API or the schema, or if the API token may not read settings
+func (sc *SettingsClient) IsSchemaSupported(schemaID string) (bool, error) {
+	_, err := sc.client.Get(settingsSchemasPath + "/" + url.PathEscape(schemaID))
+	if err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && (apiErr.Code() == http.StatusNotFound || apiErr.Code() == http.StatusForbidden) {
+			log.WithError(err).WithField("schemaId", schemaID).Debug("Settings schema is not supported")
+			return false, nil
+		}
+
+		return false, fmt.Errorf("could not retrieve settings schema %s: %w", schemaID, err)
+	}
+
+	return true, nil
+}
+
+// List returns all settings objects of the given schema in the given scope
+func (sc *SettingsClient) List(schemaID string, scope string) ([]SettingsObject, error) {
+	query := url.Values{}
+	query.Set("schemaIds", schemaID)
+	query.Set("scopes", scope)
+	query.Set("fields", settingsObjectsFields)
+	query.Set("pageSize", settingsObjectsPageSize)
+
+	var objects []SettingsObject
+	err := NewPager(sc.client, settingsObjectsPath, query.Encode()).
+		ForEachPage(func(body []byte) error {
+			page := &settingsObjectsListResponse{}
+			err := json.Unmarshal(body, page)
+			if err != nil {
+				return fmt.Errorf("could not parse settings objects: %w", err)
+			}
+
+			objects = append(objects, page.Items...)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve settings objects of schema %s: %w", schemaID, err)
+	}
+
+	return objects, nil
+}
+
+// Create creates the given settings objects and returns their object IDs in the same order
+func (sc *SettingsClient) Create(objects ...SettingsObjectCreate) ([]string, error) {
+	payload, err := json.Marshal(objects)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal settings objects: %w", err)
+	}
+
+	response, err := sc.client.Post(settingsObjectsPath, payload)
+	if err != nil {
+		return nil, fmt.Errorf("could not create settings objects: %w", err)
+	}
+
+	var results []settingsObjectResponse
+	err = json.Unmarshal(response, &results)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse settings objects creation response: %w", err)
+	}
+
+	objectIDs := make([]string, len(results))
+	var failures []string
+	for i, result := range results {
+		if result.Error != nil {
+			failures = append(failures, result.Error.String())
+			continue
+		}
+		objectIDs[i] = result.ObjectID
+	}
+
+	if len(failures) > 0 {
+		return nil, fmt.Errorf("could not create settings objects: %s", strings.Join(failures, ", "))
+	}
+
+	return objectIDs, nil
+}
+
+// Update replaces the value of the settings object with the given object ID
+func (sc *SettingsClient) Update(objectID string, value interface{}) error {
+	payload, err := json.Marshal(settingsObjectUpdate{Value: value})
+	if err != nil {
+		return fmt.Errorf("could not marshal settings object: %w", err)
+	}
+
+	_, err = sc.client.Put(settingsObjectsPath+"/"+url.PathEscape(objectID), payload)
+	if err != nil {
+		return fmt.Errorf("could not update settings object %s: %w", objectID, err)
+	}
+
+	return nil
+}
+
+// Delete deletes the settings object with the given object ID
+func (sc *SettingsClient) Delete(objectID string) error {
+	_, err := sc.client.Delete(settingsObjectsPath + "/" + url.PathEscape(objectID))
+	if err != nil {
+		return fmt.Errorf("could not delete settings object %s: %w", objectID, err)
+	}
+
+	return nil
+}
+
+func (e *settingsObjectError) String() string {
+	if len(e.ConstraintViolations) > 0 {
+		return fmt.Sprintf("%d %s %s", e.Code, e.Message, e.ConstraintViolations)
+	}
+
+	return fmt.Sprintf("%d %s", e.Code, e.Message)
+}
@@ -0,0 +1,306 @@
+package dynatrace
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const autoTaggingObjectsURL = "/api/v2/settings/objects?fields=objectId%2CschemaId%2Cscope%2Cvalue&pageSize=500&schemaIds=builtin%3Atags.auto-tagging&scopes=environment"
+const problemNotificationObjectsURL = "/api/v2/settings/objects?fields=objectId%2CschemaId%2Cscope%2Cvalue&pageSize=500&schemaIds=builtin%3Aproblem.notifications&scopes=environment"
+const metricEventObjectsURL = "/api/v2/settings/objects?fields=objectId%2CschemaId%2Cscope%2Cvalue&pageSize=500&schemaIds=builtin%3Aanomaly-detection.metric-events&scopes=environment"
+
+func TestSettingsClient_IsSchemaSupported(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		payload    string
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name:       "schema is supported",
+			statusCode: http.StatusOK,
+			payload:    `{"schemaId":"builtin:tags.auto-tagging"}`,
+			want:       true,
+		},
+		{
+			name:       "schema is unknown",
+			statusCode: http.StatusNotFound,
+			payload:    `{"error":{"code":404,"message":"Schema builtin:tags.auto-tagging not found"}}`,
+			want:       false,
+		},
+		{
+			name:       "token may not read settings",
+			statusCode: http.StatusForbidden,
+			payload:    `{"error":{"code":403,"message":"Token is missing required scope"}}`,
+			want:       false,
+		},
+		{
+			name:       "server error",
+			statusCode: http.StatusInternalServerError,
+			payload:    `{"error":{"code":500,"message":"Internal Server Error"}}`,
+			want:       false,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			handler.AddExactError("/api/v2/settings/schemas/builtin:tags.auto-tagging", tt.statusCode, []byte(tt.payload))
+
+			dtClient, _, teardown := createDynatraceClient(handler)
+			defer teardown()
+
+			got, err := NewSettingsClient(dtClient).IsSchemaSupported(AutoTaggingSchemaID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSettingsClient_ListFollowsNextPageKey(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(autoTaggingObjectsURL, []byte(`{"items":[{"objectId":"id-1","value":{"name":"keptn_service"}}],"nextPageKey":"page-2"}`))
+	handler.AddExact(settingsObjectsPath+"?nextPageKey=page-2", []byte(`{"items":[{"objectId":"id-2","value":{"name":"keptn_stage"}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	tagNames, err := NewAutoTagsSettingsClient(NewSettingsClient(dtClient)).GetAllTagNames()
+	assert.NoError(t, err)
+	assert.True(t, tagNames.Contains("keptn_service"))
+	assert.True(t, tagNames.Contains("keptn_stage"))
+	assert.False(t, tagNames.Contains("keptn_project"))
+}
+
+func TestSettingsClient_Create(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(settingsObjectsPath, []byte(`[{"code":200,"objectId":"id-1"}]`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	profileID, err := NewAlertingProfilesSettingsClient(NewSettingsClient(dtClient)).Create(&AlertingProfile{DisplayName: "Keptn"})
+	assert.NoError(t, err)
+	assert.Equal(t, "id-1", profileID)
+}
+
+func TestSettingsClient_CreateReturnsValidationErrors(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExactError(settingsObjectsPath, http.StatusBadRequest, []byte(`[{"code":400,"error":{"code":400,"message":"Validation failed","constraintViolations":[{"path":"name","message":"must not be empty"}]}}]`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	_, err := NewSettingsClient(dtClient).Create(SettingsObjectCreate{SchemaID: AutoTaggingSchemaID, Scope: EnvironmentScope})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "must not be empty")
+}
+
+func Test_toMetricEventSettings_RoundTrip(t *testing.T) {
+	metricEvent := &MetricEvent{
+		MetricID:          "builtin:service.response.time",
+		Name:              "response_time_p90 (Keptn.sockshop.production.carts)",
+		Description:       "Keptn SLI violated",
+		AggregationType:   "P90",
+		EventType:         "CUSTOM_ALERT",
+		Severity:          "CUSTOM_ALERT",
+		AlertCondition:    "ABOVE",
+		Samples:           5,
+		ViolatingSamples:  3,
+		DealertingSamples: 5,
+		Threshold:         600,
+		AlertingScope: []MEAlertingScope{
+			{
+				FilterType:       "MANAGEMENT_ZONE",
+				ManagementZoneID: 1234,
+			},
+			{
+				FilterType: "TAG",
+				TagFilter: &METagFilter{
+					Context: "CONTEXTLESS",
+					Key:     "keptn_service",
+					Value:   "carts",
+				},
+			},
+		},
+	}
+
+	settings := toMetricEventSettings(metricEvent)
+	assert.Equal(t, "PERCENTILE90", settings.QueryDefinition.Aggregation)
+	assert.Equal(t, "1234", settings.QueryDefinition.ManagementZone)
+	assert.Equal(t, []metricEventSettingsEntityCondition{{Type: "TAG", Operator: "EQUALS", Value: "keptn_service:carts"}}, settings.QueryDefinition.EntityFilter.Conditions)
+
+	assert.Equal(t, metricEvent, fromMetricEventSettings(settings))
+}
+
+type settingsRequest struct {
+	method string
+	url    string
+	body   []byte
+}
+
+// settingsRequestRecorder records all requests before passing them on to the PayloadBasedURLHandler
+type settingsRequestRecorder struct {
+	handler  *test.PayloadBasedURLHandler
+	requests []settingsRequest
+}
+
+func newSettingsRequestRecorder(t *testing.T) *settingsRequestRecorder {
+	return &settingsRequestRecorder{
+		handler: test.NewPayloadBasedURLHandler(t),
+	}
+}
+
+func (r *settingsRequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	r.requests = append(r.requests, settingsRequest{method: req.Method, url: req.URL.String(), body: body})
+	r.handler.ServeHTTP(w, req)
+}
+
+func (r *settingsRequestRecorder) requestsWithMethod(method string) []settingsRequest {
+	var requests []settingsRequest
+	for _, request := range r.requests {
+		if request.method == method {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+func TestAutoTagsSettingsClient_Create(t *testing.T) {
+	recorder := newSettingsRequestRecorder(t)
+	recorder.handler.AddExact(settingsObjectsPath, []byte(`[{"code":200,"objectId":"id-1"}]`))
+
+	dtClient, _, teardown := createDynatraceClient(recorder)
+	defer teardown()
+
+	err := NewAutoTagsSettingsClient(NewSettingsClient(dtClient)).Create(&DTTaggingRule{Name: "keptn_service"})
+	assert.NoError(t, err)
+
+	posts := recorder.requestsWithMethod(http.MethodPost)
+	if assert.Len(t, posts, 1) {
+		var objects []struct {
+			SchemaID string              `json:"schemaId"`
+			Scope    string              `json:"scope"`
+			Value    autoTaggingSettings `json:"value"`
+		}
+		assert.NoError(t, json.Unmarshal(posts[0].body, &objects))
+		if assert.Len(t, objects, 1) {
+			assert.Equal(t, AutoTaggingSchemaID, objects[0].SchemaID)
+			assert.Equal(t, EnvironmentScope, objects[0].Scope)
+			assert.Equal(t, "keptn_service", objects[0].Value.Name)
+		}
+	}
+}
+
+func TestNotificationsSettingsClient_DeleteExistingKeptnProblemNotifications(t *testing.T) {
+	recorder := newSettingsRequestRecorder(t)
+	recorder.handler.AddExact(problemNotificationObjectsURL, []byte(`{"items":[`+
+		`{"objectId":"id-keptn","value":{"enabled":true,"type":"WEBHOOK","displayName":"Keptn Problem Notification"}},`+
+		`{"objectId":"id-other","value":{"enabled":true,"type":"EMAIL","displayName":"Ops team"}}]}`))
+	recorder.handler.AddExact(settingsObjectsPath+"/id-keptn", []byte(``))
+
+	dtClient, _, teardown := createDynatraceClient(recorder)
+	defer teardown()
+
+	err := NewNotificationsSettingsClient(NewSettingsClient(dtClient)).DeleteExistingKeptnProblemNotifications()
+	assert.NoError(t, err)
+
+	deletes := recorder.requestsWithMethod(http.MethodDelete)
+	if assert.Len(t, deletes, 1) {
+		assert.Equal(t, settingsObjectsPath+"/id-keptn", deletes[0].url)
+	}
+}
+
+func TestNotificationsSettingsClient_CreateStoresTokenAsSecretHeader(t *testing.T) {
+	recorder := newSettingsRequestRecorder(t)
+	recorder.handler.AddExact(settingsObjectsPath, []byte(`[{"code":200,"objectId":"id-1"}]`))
+
+	dtClient, _, teardown := createDynatraceClient(recorder)
+	defer teardown()
+
+	err := NewNotificationsSettingsClient(NewSettingsClient(dtClient)).Create(
+		&credentials.KeptnAPICredentials{APIURL: "https://keptn.example.com/api", APIToken: "keptn-token"},
+		"profile-id")
+	assert.NoError(t, err)
+
+	posts := recorder.requestsWithMethod(http.MethodPost)
+	if assert.Len(t, posts, 1) {
+		var objects []struct {
+			SchemaID string                      `json:"schemaId"`
+			Value    problemNotificationSettings `json:"value"`
+		}
+		assert.NoError(t, json.Unmarshal(posts[0].body, &objects))
+		if assert.Len(t, objects, 1) {
+			assert.Equal(t, ProblemNotificationsSchemaID, objects[0].SchemaID)
+			assert.Equal(t, "profile-id", objects[0].Value.AlertingProfile)
+			assert.Equal(t, "https://keptn.example.com/api/v1/event", objects[0].Value.WebHookNotification.URL)
+			assert.Contains(t, objects[0].Value.WebHookNotification.Headers, webHookNotificationSettingsHeader{Name: "x-token", Secret: true, SecretValue: "keptn-token"})
+		}
+	}
+}
+
+func TestAlertingProfilesSettingsClient_GetProfileID(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/v2/settings/objects?fields=objectId%2CschemaId%2Cscope%2Cvalue&pageSize=500&schemaIds=builtin%3Aalerting.profile&scopes=environment",
+		[]byte(`{"items":[{"objectId":"id-default","value":{"name":"Default"}},{"objectId":"id-keptn","value":{"name":"Keptn"}}]}`))
+
+	dtClient, _, teardown := createDynatraceClient(handler)
+	defer teardown()
+
+	client := NewAlertingProfilesSettingsClient(NewSettingsClient(dtClient))
+
+	profileID, err := client.GetProfileID("Keptn")
+	assert.NoError(t, err)
+	assert.Equal(t, "id-keptn", profileID)
+
+	profileID, err = client.GetProfileID("Unknown")
+	assert.NoError(t, err)
+	assert.Empty(t, profileID)
+}
+
+func TestMetricEventsSettingsClient_GetMetricEventByNameAndUpdate(t *testing.T) {
+	recorder := newSettingsRequestRecorder(t)
+	recorder.handler.AddExact(metricEventObjectsURL, []byte(`{"items":[{"objectId":"id-1","value":{"enabled":true,"summary":"response_time_p90 (Keptn.sockshop.production.carts)",`+
+		`"queryDefinition":{"type":"METRIC_KEY","metricKey":"builtin:service.response.time","aggregation":"PERCENTILE90"},`+
+		`"modelProperties":{"type":"STATIC_THRESHOLD","threshold":600,"alertCondition":"ABOVE","samples":5,"violatingSamples":3,"dealertingSamples":5}}}]}`))
+	recorder.handler.AddExact(settingsObjectsPath+"/id-1", []byte(`{"code":200,"objectId":"id-1"}`))
+
+	dtClient, _, teardown := createDynatraceClient(recorder)
+	defer teardown()
+
+	client := NewMetricEventsSettingsClient(NewSettingsClient(dtClient))
+
+	metricEvent, err := client.GetMetricEventByName("response_time_p90 (Keptn.sockshop.production.carts)")
+	assert.NoError(t, err)
+	if assert.NotNil(t, metricEvent) {
+		assert.Equal(t, "id-1", metricEvent.ID)
+		assert.Equal(t, "builtin:service.response.time", metricEvent.MetricID)
+		assert.Equal(t, "P90", metricEvent.AggregationType)
+		assert.Equal(t, 600.0, metricEvent.Threshold)
+
+		metricEvent.Threshold = 800
+		assert.NoError(t, client.Update(metricEvent))
+	}
+
+	puts := recorder.requestsWithMethod(http.MethodPut)
+	if assert.Len(t, puts, 1) {
+		assert.Equal(t, settingsObjectsPath+"/id-1", puts[0].url)
+		assert.Contains(t, string(puts[0].body), `"threshold":800`)
+	}
+
+	metricEvent, err = client.GetMetricEventByName("unknown")
+	assert.NoError(t, err)
+	assert.Nil(t, metricEvent)
+}
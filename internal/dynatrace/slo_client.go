@@ -44,12 +44,21 @@ func NewSLOClient(client ClientInterface) *SLOClient {
 // Get calls Dynatrace API to retrieve the values of the Dynatrace SLO for that timeframe
 // It returns a SLOResult object on success, an error otherwise
 func (c *SLOClient) Get(sloID string, startUnix time.Time, endUnix time.Time) (*SLOResult, error) {
-	body, err := c.client.Get(
+	return c.get(
 		fmt.Sprintf("%s/%s?from=%s&to=%s",
 			sloPath,
 			sloID,
 			common.TimestampToString(startUnix),
 			common.TimestampToString(endUnix)))
+}
+
+// GetDefinition calls Dynatrace API to retrieve the Dynatrace SLO evaluated for its own timeframe, e.g. to read its name and targets
+func (c *SLOClient) GetDefinition(sloID string) (*SLOResult, error) {
+	return c.get(fmt.Sprintf("%s/%s", sloPath, sloID))
+}
+
+func (c *SLOClient) get(apiPath string) (*SLOResult, error) {
+	body, err := c.client.Get(apiPath)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package dynatrace
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+// TLSOptions defines how the connection to the Dynatrace API is secured. Unset options fall back to the configuration of the dynatrace-service
+type TLSOptions struct {
+	// SSLVerify defines whether the certificate of the Dynatrace API has to be valid
+	SSLVerify *bool `json:"sslVerify,omitempty" yaml:"sslVerify,omitempty"`
+	// CABundle is the path of a PEM encoded CA bundle, e.g. a mounted secret or config map, used in addition to the system certificates
+	CABundle string `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+	// MinVersion is the minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3
+	MinVersion string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// withDefaults returns the options with all unset options replaced by the values of the configuration
+func (o *TLSOptions) withDefaults(cfg *env.Config) TLSOptions {
+	options := TLSOptions{}
+	if o != nil {
+		options = *o
+	}
+
+	if options.SSLVerify == nil {
+		sslVerify := cfg.HttpSSLVerificationEnabled
+		options.SSLVerify = &sslVerify
+	}
+	if options.CABundle == "" {
+		options.CABundle = cfg.HttpCABundle
+	}
+	if options.MinVersion == "" {
+		options.MinVersion = cfg.HttpMinTLSVersion
+	}
+
+	return options
+}
+
+// createTLSConfig creates the TLS configuration for the options and their defaults
+func createTLSConfig(options *TLSOptions, cfg *env.Config) (*tls.Config, error) {
+	o := options.withDefaults(cfg)
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: !*o.SSLVerify,
+	}
+
+	if o.MinVersion != "" {
+		minVersion, ok := tlsVersions[o.MinVersion]
+		if !ok {
+			return nil, common.NewUserConfigurationError(fmt.Errorf("unsupported minimum TLS version '%s', expected one of: 1.0, 1.1, 1.2, 1.3", o.MinVersion))
+		}
+		tlsConfig.MinVersion = minVersion
+	}
+
+	if o.CABundle != "" {
+		pem, err := ioutil.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, common.NewUserConfigurationError(fmt.Errorf("could not read CA bundle %s: %w", o.CABundle, err))
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, common.NewUserConfigurationError(fmt.Errorf("CA bundle %s does not contain any PEM encoded certificates", o.CABundle))
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,136 @@
+package dynatrace
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCABundle writes a self-signed certificate to a temporary file and returns its path
+func writeTestCABundle(t *testing.T) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dynatrace"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	certificate, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	err = ioutil.WriteFile(caBundle, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate}), 0600)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return caBundle
+}
+
+func Test_createTLSConfig(t *testing.T) {
+	caBundle := writeTestCABundle(t)
+	invalidCABundle := filepath.Join(t.TempDir(), "invalid.pem")
+	err := ioutil.WriteFile(invalidCABundle, []byte("not a certificate"), 0600)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	falseValue := false
+	trueValue := true
+
+	tests := []struct {
+		name                   string
+		cfg                    env.Config
+		options                *TLSOptions
+		wantInsecureSkipVerify bool
+		wantMinVersion         uint16
+		wantRootCAs            bool
+		wantErr                bool
+	}{
+		{
+			name:                   "defaults",
+			cfg:                    env.Config{HttpSSLVerificationEnabled: true},
+			wantInsecureSkipVerify: false,
+		},
+		{
+			name:                   "verification disabled by configuration",
+			cfg:                    env.Config{HttpSSLVerificationEnabled: false},
+			wantInsecureSkipVerify: true,
+		},
+		{
+			name:                   "verification disabled by options",
+			options:                &TLSOptions{SSLVerify: &falseValue},
+			wantInsecureSkipVerify: true,
+		},
+		{
+			name:                   "options take precedence over configuration",
+			cfg:                    env.Config{HttpSSLVerificationEnabled: false, HttpMinTLSVersion: "1.2"},
+			options:                &TLSOptions{SSLVerify: &trueValue, MinVersion: "1.3"},
+			wantInsecureSkipVerify: false,
+			wantMinVersion:         tls.VersionTLS13,
+		},
+		{
+			name:           "minimum version from configuration",
+			cfg:            env.Config{HttpSSLVerificationEnabled: true, HttpMinTLSVersion: "1.2"},
+			wantMinVersion: tls.VersionTLS12,
+		},
+		{
+			name:    "unsupported minimum version",
+			options: &TLSOptions{MinVersion: "2.0"},
+			wantErr: true,
+		},
+		{
+			name:        "CA bundle",
+			cfg:         env.Config{HttpSSLVerificationEnabled: true},
+			options:     &TLSOptions{CABundle: caBundle},
+			wantRootCAs: true,
+		},
+		{
+			name:    "CA bundle without certificates",
+			options: &TLSOptions{CABundle: invalidCABundle},
+			wantErr: true,
+		},
+		{
+			name:    "missing CA bundle",
+			options: &TLSOptions{CABundle: filepath.Join(t.TempDir(), "missing.pem")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tlsConfig, err := createTLSConfig(tt.options, &tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, common.UserConfigurationErrorType, common.GetErrorType(err))
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tt.wantInsecureSkipVerify, tlsConfig.InsecureSkipVerify)
+			assert.Equal(t, tt.wantMinVersion, tlsConfig.MinVersion)
+			assert.Equal(t, tt.wantRootCAs, tlsConfig.RootCAs != nil)
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package dynatrace
+
+import (
+	"sync"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	log "github.com/sirupsen/logrus"
+)
+
+// secondaryTokenActive records per tenant the secondary API token that is active, i.e. the Dynatrace API rejected the primary one.
+// It is shared by all clients, so that not every new client first has to try the rejected token. As there is at most one entry per tenant,
+// which is replaced once the credentials of the tenant are rotated, the map does not grow with the number of tokens used over time
+var secondaryTokenActive = struct {
+	sync.RWMutex
+	tenants map[string]string
+}{tenants: map[string]string{}}
+
+// activeAPIToken returns the API token requests for the credentials are sent with
+func activeAPIToken(dtCredentials *credentials.DTCredentials) string {
+	if dtCredentials.SecondaryApiToken == "" {
+		return dtCredentials.ApiToken
+	}
+
+	secondaryTokenActive.RLock()
+	defer secondaryTokenActive.RUnlock()
+
+	if secondaryTokenActive.tenants[dtCredentials.Tenant] == dtCredentials.SecondaryApiToken {
+		return dtCredentials.SecondaryApiToken
+	}
+	return dtCredentials.ApiToken
+}
+
+// alternativeAPIToken returns the API token to retry a request with if the given token was rejected, or false if there is none
+func alternativeAPIToken(dtCredentials *credentials.DTCredentials, rejectedToken string) (string, bool) {
+	if dtCredentials.SecondaryApiToken == "" || dtCredentials.SecondaryApiToken == dtCredentials.ApiToken {
+		return "", false
+	}
+
+	if rejectedToken == dtCredentials.SecondaryApiToken {
+		return dtCredentials.ApiToken, true
+	}
+	return dtCredentials.SecondaryApiToken, true
+}
+
+// setActiveAPIToken records that requests for the credentials are sent with the given token from now on
+func setActiveAPIToken(dtCredentials *credentials.DTCredentials, token string) {
+	useSecondary := token == dtCredentials.SecondaryApiToken
+
+	secondaryTokenActive.Lock()
+	defer secondaryTokenActive.Unlock()
+
+	isSecondaryActive := secondaryTokenActive.tenants[dtCredentials.Tenant] == dtCredentials.SecondaryApiToken
+	if isSecondaryActive == useSecondary {
+		return
+	}
+
+	if useSecondary {
+		secondaryTokenActive.tenants[dtCredentials.Tenant] = dtCredentials.SecondaryApiToken
+		log.WithField("tenant", dtCredentials.Tenant).Warn("Dynatrace API rejected the primary API token, using the secondary API token")
+		return
+	}
+
+	delete(secondaryTokenActive.tenants, dtCredentials.Tenant)
+	log.WithField("tenant", dtCredentials.Tenant).Warn("Dynatrace API rejected the secondary API token, using the primary API token")
+}
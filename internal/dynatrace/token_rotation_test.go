@@ -0,0 +1,111 @@
+package dynatrace
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+// tokenCheckingHandler only accepts requests authorized by the valid token and records the tokens of all requests
+type tokenCheckingHandler struct {
+	mutex      sync.Mutex
+	validToken string
+	usedTokens []string
+}
+
+func (h *tokenCheckingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	token := r.Header.Get("Authorization")
+	h.usedTokens = append(h.usedTokens, token)
+	if token != "Api-Token "+h.validToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": {"code": 401, "message": "Token Authentication failed"}}`))
+		return
+	}
+	w.Write([]byte("response"))
+}
+
+func (h *tokenCheckingHandler) rotate(validToken string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.validToken = validToken
+	h.usedTokens = nil
+}
+
+func TestClient_FailsOverToSecondaryAPIToken(t *testing.T) {
+	handler := &tokenCheckingHandler{validToken: "secondary"}
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	dtCredentials := &credentials.DTCredentials{Tenant: "http://rotating-tenant.dynatrace.com", ApiToken: "primary", SecondaryApiToken: "secondary"}
+
+	response, err := NewClientWithHTTP(dtCredentials, httpClient).Get("/api/v2/metrics")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("response"), response)
+	assert.Equal(t, []string{"Api-Token primary", "Api-Token secondary"}, handler.usedTokens)
+
+	// a new client uses the active secondary token right away
+	handler.rotate("secondary")
+	_, err = NewClientWithHTTP(dtCredentials, httpClient).Get("/api/v2/metrics")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Api-Token secondary"}, handler.usedTokens)
+
+	// once the secondary token is revoked, the primary token is used again
+	handler.rotate("primary")
+	_, err = NewClientWithHTTP(dtCredentials, httpClient).Get("/api/v2/metrics")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Api-Token secondary", "Api-Token primary"}, handler.usedTokens)
+	assert.Equal(t, "primary", activeAPIToken(dtCredentials))
+}
+
+func TestClient_BothAPITokensRejected(t *testing.T) {
+	handler := &tokenCheckingHandler{validToken: "other"}
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	dtCredentials := &credentials.DTCredentials{Tenant: "http://rejecting-tenant.dynatrace.com", ApiToken: "primary", SecondaryApiToken: "secondary"}
+
+	_, err := NewClientWithHTTP(dtCredentials, httpClient).Get("/api/v2/metrics")
+	var unauthorizedErr *UnauthorizedError
+	assert.ErrorAs(t, err, &unauthorizedErr)
+	assert.Equal(t, []string{"Api-Token primary", "Api-Token secondary"}, handler.usedTokens)
+	assert.Equal(t, "primary", activeAPIToken(dtCredentials))
+}
+
+func TestClient_NoSecondaryAPIToken(t *testing.T) {
+	handler := &tokenCheckingHandler{validToken: "other"}
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	_, err := NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://single-token-tenant.dynatrace.com", ApiToken: "primary"}, httpClient).Get("/api/v2/metrics")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"Api-Token primary"}, handler.usedTokens)
+}
+
+func TestSetActiveAPIToken_KeepsOneEntryPerTenant(t *testing.T) {
+	tenant := "http://bounded-tenant.dynatrace.com"
+	defer setActiveAPIToken(&credentials.DTCredentials{Tenant: tenant, ApiToken: "primary-2", SecondaryApiToken: "secondary-2"}, "primary-2")
+
+	firstCredentials := &credentials.DTCredentials{Tenant: tenant, ApiToken: "primary-1", SecondaryApiToken: "secondary-1"}
+	setActiveAPIToken(firstCredentials, "secondary-1")
+	assert.Equal(t, "secondary-1", activeAPIToken(firstCredentials))
+
+	// the rotated credentials of the tenant replace the entry of the previous ones
+	rotatedCredentials := &credentials.DTCredentials{Tenant: tenant, ApiToken: "primary-2", SecondaryApiToken: "secondary-2"}
+	assert.Equal(t, "primary-2", activeAPIToken(rotatedCredentials))
+	setActiveAPIToken(rotatedCredentials, "secondary-2")
+	assert.Equal(t, "secondary-2", activeAPIToken(rotatedCredentials))
+	assert.Equal(t, "primary-1", activeAPIToken(firstCredentials))
+
+	secondaryTokenActive.RLock()
+	defer secondaryTokenActive.RUnlock()
+	assert.Equal(t, "secondary-2", secondaryTokenActive.tenants[tenant])
+}
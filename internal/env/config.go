@@ -0,0 +1,346 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Config is the effective configuration of the dynatrace-service read from its environment variables.
+// The env tag of each field names the environment variable it is read from, fields tagged as secret are redacted when logged
+type Config struct {
+	LogLevel                                  log.Level `env:"LOG_LEVEL_DYNATRACE_SERVICE"`
+	LogFormat                                 string    `env:"LOG_FORMAT"`
+	ReceiverPort                              int       `env:"RCV_PORT"`
+	ReceiverPath                              string    `env:"RCV_PATH"`
+	Version                                   string    `env:"version"`
+	EventSource                               string    `env:"EVENT_SOURCE"`
+	EventExtensions                           []string  `env:"EVENT_EXTENSIONS"`
+	TaggingRulesGenerationEnabled             bool      `env:"GENERATE_TAGGING_RULES"`
+	ProblemNotificationsGenerationEnabled     bool      `env:"GENERATE_PROBLEM_NOTIFICATIONS"`
+	ManagementZonesGenerationEnabled          bool      `env:"GENERATE_MANAGEMENT_ZONES"`
+	DashboardsGenerationEnabled               bool      `env:"GENERATE_DASHBOARDS"`
+	MetricEventsGenerationEnabled             bool      `env:"GENERATE_METRIC_EVENTS"`
+	ProblemClosingAfterRemediationEnabled     bool      `env:"CLOSE_PROBLEMS_AFTER_REMEDIATION"`
+	SkippingProblemsWithoutRemediationEnabled bool      `env:"SKIP_PROBLEMS_WITHOUT_REMEDIATION"`
+	ProblemLabels                             []string  `env:"PROBLEM_LABELS"`
+	ProblemWebhookEnabled                     bool      `env:"PROBLEM_WEBHOOK_ENABLED"`
+	ProblemWebhookPort                        int       `env:"PROBLEM_WEBHOOK_PORT"`
+	ProblemWebhookSecret                      string    `env:"PROBLEM_WEBHOOK_SECRET" secret:"true"`
+	FailureEventsEnabled                      bool      `env:"SEND_FAILURE_EVENTS"`
+	AttachRulesValidationEnabled              bool      `env:"VALIDATE_ATTACH_RULES"`
+	EntityTagEnrichmentEnabled                bool      `env:"ENTITY_TAG_ENRICHMENT"`
+	DeploymentEventDeduplicationWindow        int       `env:"DEPLOYMENT_EVENT_DEDUPLICATION_WINDOW_SECONDS"`
+	EventQueueSize                            int       `env:"DYNATRACE_EVENT_QUEUE_SIZE"`
+	EventBatchSize                            int       `env:"DYNATRACE_EVENT_BATCH_SIZE"`
+	EventMaxRetries                           int       `env:"DYNATRACE_EVENT_MAX_RETRIES"`
+	FailureEventType                          string    `env:"FAILURE_EVENT_TYPE"`
+	EvaluationMetricsIngestEnabled            bool      `env:"INGEST_EVALUATION_METRICS"`
+	QualityGateDashboardEnabled               bool      `env:"PUBLISH_QUALITY_GATE_DASHBOARD"`
+	TestMetricsIngestEnabled                  bool      `env:"INGEST_TEST_METRICS"`
+	RemediationMetricsIngestEnabled           bool      `env:"INGEST_REMEDIATION_METRICS"`
+	SelfMonitoringEnabled                     bool      `env:"SELF_MONITORING_ENABLED"`
+	SelfMonitoringInterval                    int       `env:"SELF_MONITORING_INTERVAL_SECONDS"`
+	SLITimeframeShift                         int       `env:"SLI_TIMEFRAME_SHIFT_SECONDS"`
+	SLIWaitForData                            int       `env:"SLI_WAIT_FOR_DATA_SECONDS"`
+	SLINoDataRetries                          int       `env:"SLI_NO_DATA_RETRIES"`
+	SLITimeframeValidationEnabled             bool      `env:"SLI_TIMEFRAME_VALIDATION"`
+	SLINoDataRetryDelay                       int       `env:"SLI_NO_DATA_RETRY_DELAY_SECONDS"`
+	SLIMaxConcurrentQueriesPerTenant          int       `env:"SLI_MAX_CONCURRENT_QUERIES_PER_TENANT"`
+	SLIResultCacheTTL                         int       `env:"SLI_RESULT_CACHE_TTL_SECONDS"`
+	DashboardTileThresholdsEnabled            bool      `env:"DASHBOARD_TILE_THRESHOLDS"`
+	TestWindowEventsEnabled                   bool      `env:"SEND_TEST_WINDOW_EVENTS"`
+	TestParticipationEnabled                  bool      `env:"PUBLISH_DYNATRACE_TEST_HEADER"`
+	DeadLetterMaxAttempts                     int       `env:"DEAD_LETTER_MAX_ATTEMPTS"`
+	DeadLetterSink                            string    `env:"DEAD_LETTER_SINK"`
+	DynatraceConfigCacheTTL                   int       `env:"DYNATRACE_CONFIG_CACHE_TTL_SECONDS"`
+	KeptnProjectCacheTTL                      int       `env:"KEPTN_PROJECT_CACHE_TTL_SECONDS"`
+	DashboardStorage                          string    `env:"DASHBOARD_STORAGE"`
+	DefaultDynatraceSecretName                string    `env:"DT_DEFAULT_SECRET_NAME"`
+	SLIProviderName                           string    `env:"SLI_PROVIDER_NAME"`
+	AllowedProjects                           []string  `env:"ALLOWED_PROJECTS"`
+	DeniedProjects                            []string  `env:"DENIED_PROJECTS"`
+	AllowedStages                             []string  `env:"ALLOWED_STAGES"`
+	DeniedStages                              []string  `env:"DENIED_STAGES"`
+	AllowedServices                           []string  `env:"ALLOWED_SERVICES"`
+	DeniedServices                            []string  `env:"DENIED_SERVICES"`
+	SecretBackend                             string    `env:"SECRET_BACKEND"`
+	KeptnSecretScope                          string    `env:"KEPTN_SECRET_SCOPE"`
+	VaultAddress                              string    `env:"VAULT_ADDR"`
+	VaultKVMount                              string    `env:"VAULT_KV_MOUNT"`
+	VaultPathPrefix                           string    `env:"VAULT_PATH_PREFIX"`
+	VaultKubernetesRole                       string    `env:"VAULT_KUBERNETES_ROLE"`
+	VaultKubernetesAuthPath                   string    `env:"VAULT_KUBERNETES_AUTH_PATH"`
+	AWSRegion                                 string    `env:"AWS_REGION"`
+	AWSSecretsManagerEndpoint                 string    `env:"AWS_SECRETS_MANAGER_ENDPOINT"`
+	AWSSecretNamePrefix                       string    `env:"AWS_SECRETS_MANAGER_SECRET_PREFIX"`
+	AzureKeyVaultURL                          string    `env:"AZURE_KEY_VAULT_URL"`
+	VaultToken                                string    `env:"VAULT_TOKEN" secret:"true"`
+	AWSAccessKeyID                            string    `env:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey                        string    `env:"AWS_SECRET_ACCESS_KEY" secret:"true"`
+	AWSSessionToken                           string    `env:"AWS_SESSION_TOKEN" secret:"true"`
+	AWSRoleARN                                string    `env:"AWS_ROLE_ARN"`
+	AWSWebIdentityTokenFile                   string    `env:"AWS_WEB_IDENTITY_TOKEN_FILE"`
+	AzureAuthorityHost                        string    `env:"AZURE_AUTHORITY_HOST"`
+	AzureTenantID                             string    `env:"AZURE_TENANT_ID"`
+	AzureClientID                             string    `env:"AZURE_CLIENT_ID"`
+	AzureClientSecret                         string    `env:"AZURE_CLIENT_SECRET" secret:"true"`
+	AzureFederatedTokenFile                   string    `env:"AZURE_FEDERATED_TOKEN_FILE"`
+	OutgoingEventMaxRetries                   int       `env:"OUTGOING_EVENT_MAX_RETRIES"`
+	OutgoingEventRetryDelay                   int       `env:"OUTGOING_EVENT_RETRY_DELAY_SECONDS"`
+	StartupReadinessTimeout                   int       `env:"STARTUP_READINESS_TIMEOUT_SECONDS"`
+	OutgoingEventBufferDir                    string    `env:"OUTGOING_EVENT_BUFFER_DIR"`
+	KeptnAPIEndpoint                          string    `env:"KEPTN_API_ENDPOINT"`
+	KeptnAPIToken                             string    `env:"KEPTN_API_TOKEN" secret:"true"`
+	KeptnAPIURL                               string    `env:"KEPTN_API_URL"`
+	KeptnBridgeURL                            string    `env:"KEPTN_BRIDGE_URL"`
+	KeptnAPISSLVerificationEnabled            bool      `env:"KEPTN_API_SSL_VERIFY"`
+	KeptnAPICABundle                          string    `env:"KEPTN_API_CA_BUNDLE"`
+	KeptnAPIMaxRetries                        int       `env:"KEPTN_API_MAX_RETRIES"`
+	EventTransport                            string    `env:"EVENT_TRANSPORT"`
+	NATSURL                                   string    `env:"NATS_URL"`
+	NATSTopics                                []string  `env:"NATS_TOPICS"`
+	NATSQueueGroup                            string    `env:"NATS_QUEUE_GROUP"`
+	NATSSSLVerificationEnabled                bool      `env:"NATS_SSL_VERIFY"`
+	NATSCABundle                              string    `env:"NATS_CA_BUNDLE"`
+	NATSMaxConcurrentEvents                   int       `env:"NATS_MAX_CONCURRENT_EVENTS"`
+	RunLocalEnabled                           bool      `env:"RUNLOCAL"`
+	RunLocalEventsDir                         string    `env:"RUNLOCAL_EVENTS_DIR"`
+	LeaderElectionEnabled                     bool      `env:"LEADER_ELECTION_ENABLED"`
+	PodNamespace                              string    `env:"POD_NAMESPACE"`
+	InCluster                                 bool      `env:"KUBERNETES_SERVICE_HOST"`
+	HttpSSLVerificationEnabled                bool      `env:"HTTP_SSL_VERIFY"`
+	DynatraceHTTPProxy                        string    `env:"DYNATRACE_HTTP_PROXY" secret:"true"`
+	DynatraceHTTPSProxy                       string    `env:"DYNATRACE_HTTPS_PROXY" secret:"true"`
+	DynatraceNoProxy                          string    `env:"DYNATRACE_NO_PROXY"`
+	DynatraceAPIMaxPages                      int       `env:"DYNATRACE_API_MAX_PAGES"`
+	DynatraceAPIMetricsTimeout                int       `env:"DYNATRACE_API_METRICS_TIMEOUT_SECONDS"`
+	DynatraceAPIEntitiesTimeout               int       `env:"DYNATRACE_API_ENTITIES_TIMEOUT_SECONDS"`
+	DynatraceAPIEventsTimeout                 int       `env:"DYNATRACE_API_EVENTS_TIMEOUT_SECONDS"`
+	DynatraceAPIProblemsTimeout               int       `env:"DYNATRACE_API_PROBLEMS_TIMEOUT_SECONDS"`
+	DynatraceAPIDashboardsTimeout             int       `env:"DYNATRACE_API_DASHBOARDS_TIMEOUT_SECONDS"`
+	DynatraceAPITracingEnabled                bool      `env:"DYNATRACE_API_TRACING"`
+	DynatraceAPITraceFile                     string    `env:"DYNATRACE_API_TRACE_FILE"`
+	OTLPTracesEndpoint                        string    `env:"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"`
+	OTLPHeaders                               string    `env:"OTEL_EXPORTER_OTLP_TRACES_HEADERS" secret:"true"`
+	OTelServiceName                           string    `env:"OTEL_SERVICE_NAME"`
+	HttpCABundle                              string    `env:"HTTP_CA_BUNDLE"`
+	HttpMinTLSVersion                         string    `env:"HTTP_MIN_TLS_VERSION"`
+	ServiceSyncEnabled                        bool      `env:"SYNCHRONIZE_DYNATRACE_SERVICES"`
+	ServiceSyncInterval                       int       `env:"SYNCHRONIZE_DYNATRACE_SERVICES_INTERVAL_SECONDS"`
+}
+
+// ReadConfig reads the configuration from the environment variables, using the default value of every variable that is not set or cannot be parsed
+func ReadConfig() *Config {
+	return &Config{
+		LogLevel:                                  readLogLevel(),
+		LogFormat:                                 readLogFormat(),
+		ReceiverPort:                              readEnvAsInt("RCV_PORT", 8080),
+		ReceiverPath:                              readEnvAsString("RCV_PATH", "/"),
+		Version:                                   os.Getenv("version"),
+		EventSource:                               readEnvAsString("EVENT_SOURCE", "dynatrace-service"),
+		EventExtensions:                           readEnvAsList("EVENT_EXTENSIONS"),
+		TaggingRulesGenerationEnabled:             readEnvAsBool("GENERATE_TAGGING_RULES", false),
+		ProblemNotificationsGenerationEnabled:     readEnvAsBool("GENERATE_PROBLEM_NOTIFICATIONS", false),
+		ManagementZonesGenerationEnabled:          readEnvAsBool("GENERATE_MANAGEMENT_ZONES", false),
+		DashboardsGenerationEnabled:               readEnvAsBool("GENERATE_DASHBOARDS", false),
+		MetricEventsGenerationEnabled:             readEnvAsBool("GENERATE_METRIC_EVENTS", false),
+		ProblemClosingAfterRemediationEnabled:     readEnvAsBool("CLOSE_PROBLEMS_AFTER_REMEDIATION", false),
+		SkippingProblemsWithoutRemediationEnabled: readEnvAsBool("SKIP_PROBLEMS_WITHOUT_REMEDIATION", false),
+		ProblemLabels:                             readEnvAsList("PROBLEM_LABELS"),
+		ProblemWebhookEnabled:                     readEnvAsBool("PROBLEM_WEBHOOK_ENABLED", false),
+		ProblemWebhookPort:                        readEnvAsInt("PROBLEM_WEBHOOK_PORT", 8090),
+		ProblemWebhookSecret:                      os.Getenv("PROBLEM_WEBHOOK_SECRET"),
+		FailureEventsEnabled:                      readEnvAsBool("SEND_FAILURE_EVENTS", false),
+		AttachRulesValidationEnabled:              readEnvAsBool("VALIDATE_ATTACH_RULES", false),
+		EntityTagEnrichmentEnabled:                readEnvAsBool("ENTITY_TAG_ENRICHMENT", false),
+		DeploymentEventDeduplicationWindow:        readEnvAsInt("DEPLOYMENT_EVENT_DEDUPLICATION_WINDOW_SECONDS", 300),
+		EventQueueSize:                            readEnvAsInt("DYNATRACE_EVENT_QUEUE_SIZE", 0),
+		EventBatchSize:                            readEnvAsInt("DYNATRACE_EVENT_BATCH_SIZE", 10),
+		EventMaxRetries:                           readEnvAsInt("DYNATRACE_EVENT_MAX_RETRIES", 3),
+		FailureEventType:                          readFailureEventType(),
+		EvaluationMetricsIngestEnabled:            readEnvAsBool("INGEST_EVALUATION_METRICS", false),
+		QualityGateDashboardEnabled:               readEnvAsBool("PUBLISH_QUALITY_GATE_DASHBOARD", false),
+		TestMetricsIngestEnabled:                  readEnvAsBool("INGEST_TEST_METRICS", false),
+		RemediationMetricsIngestEnabled:           readEnvAsBool("INGEST_REMEDIATION_METRICS", false),
+		SelfMonitoringEnabled:                     readEnvAsBool("SELF_MONITORING_ENABLED", false),
+		SelfMonitoringInterval:                    readEnvAsInt("SELF_MONITORING_INTERVAL_SECONDS", 60),
+		SLITimeframeShift:                         readEnvAsInt("SLI_TIMEFRAME_SHIFT_SECONDS", 0),
+		SLIWaitForData:                            readEnvAsInt("SLI_WAIT_FOR_DATA_SECONDS", -1),
+		SLINoDataRetries:                          readEnvAsInt("SLI_NO_DATA_RETRIES", 0),
+		SLITimeframeValidationEnabled:             readEnvAsBool("SLI_TIMEFRAME_VALIDATION", false),
+		SLINoDataRetryDelay:                       readEnvAsInt("SLI_NO_DATA_RETRY_DELAY_SECONDS", 10),
+		SLIMaxConcurrentQueriesPerTenant:          readEnvAsInt("SLI_MAX_CONCURRENT_QUERIES_PER_TENANT", 4),
+		SLIResultCacheTTL:                         readEnvAsInt("SLI_RESULT_CACHE_TTL_SECONDS", 0),
+		DashboardTileThresholdsEnabled:            readEnvAsBool("DASHBOARD_TILE_THRESHOLDS", false),
+		TestWindowEventsEnabled:                   readEnvAsBool("SEND_TEST_WINDOW_EVENTS", false),
+		TestParticipationEnabled:                  readEnvAsBool("PUBLISH_DYNATRACE_TEST_HEADER", false),
+		DeadLetterMaxAttempts:                     readEnvAsInt("DEAD_LETTER_MAX_ATTEMPTS", 3),
+		DeadLetterSink:                            readDeadLetterSink(),
+		DynatraceConfigCacheTTL:                   readEnvAsInt("DYNATRACE_CONFIG_CACHE_TTL_SECONDS", 30),
+		KeptnProjectCacheTTL:                      readEnvAsInt("KEPTN_PROJECT_CACHE_TTL_SECONDS", 10),
+		DashboardStorage:                          readDashboardStorage(),
+		DefaultDynatraceSecretName:                readEnvAsString("DT_DEFAULT_SECRET_NAME", "dynatrace"),
+		SLIProviderName:                           readEnvAsString("SLI_PROVIDER_NAME", "dynatrace"),
+		AllowedProjects:                           readEnvAsList("ALLOWED_PROJECTS"),
+		DeniedProjects:                            readEnvAsList("DENIED_PROJECTS"),
+		AllowedStages:                             readEnvAsList("ALLOWED_STAGES"),
+		DeniedStages:                              readEnvAsList("DENIED_STAGES"),
+		AllowedServices:                           readEnvAsList("ALLOWED_SERVICES"),
+		DeniedServices:                            readEnvAsList("DENIED_SERVICES"),
+		SecretBackend:                             readSecretBackend(),
+		KeptnSecretScope:                          readEnvAsString("KEPTN_SECRET_SCOPE", "dynatrace-service"),
+		VaultAddress:                              os.Getenv("VAULT_ADDR"),
+		VaultKVMount:                              readEnvAsString("VAULT_KV_MOUNT", "secret"),
+		VaultPathPrefix:                           os.Getenv("VAULT_PATH_PREFIX"),
+		VaultKubernetesRole:                       os.Getenv("VAULT_KUBERNETES_ROLE"),
+		VaultKubernetesAuthPath:                   readEnvAsString("VAULT_KUBERNETES_AUTH_PATH", "kubernetes"),
+		AWSRegion:                                 os.Getenv("AWS_REGION"),
+		AWSSecretsManagerEndpoint:                 os.Getenv("AWS_SECRETS_MANAGER_ENDPOINT"),
+		AWSSecretNamePrefix:                       os.Getenv("AWS_SECRETS_MANAGER_SECRET_PREFIX"),
+		AzureKeyVaultURL:                          os.Getenv("AZURE_KEY_VAULT_URL"),
+		VaultToken:                                os.Getenv("VAULT_TOKEN"),
+		AWSAccessKeyID:                            os.Getenv("AWS_ACCESS_KEY_ID"),
+		AWSSecretAccessKey:                        os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		AWSSessionToken:                           os.Getenv("AWS_SESSION_TOKEN"),
+		AWSRoleARN:                                os.Getenv("AWS_ROLE_ARN"),
+		AWSWebIdentityTokenFile:                   os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"),
+		AzureAuthorityHost:                        os.Getenv("AZURE_AUTHORITY_HOST"),
+		AzureTenantID:                             os.Getenv("AZURE_TENANT_ID"),
+		AzureClientID:                             os.Getenv("AZURE_CLIENT_ID"),
+		AzureClientSecret:                         os.Getenv("AZURE_CLIENT_SECRET"),
+		AzureFederatedTokenFile:                   os.Getenv("AZURE_FEDERATED_TOKEN_FILE"),
+		OutgoingEventMaxRetries:                   readEnvAsInt("OUTGOING_EVENT_MAX_RETRIES", 10),
+		OutgoingEventRetryDelay:                   readEnvAsInt("OUTGOING_EVENT_RETRY_DELAY_SECONDS", 5),
+		StartupReadinessTimeout:                   readEnvAsInt("STARTUP_READINESS_TIMEOUT_SECONDS", 300),
+		OutgoingEventBufferDir:                    os.Getenv("OUTGOING_EVENT_BUFFER_DIR"),
+		KeptnAPIEndpoint:                          os.Getenv("KEPTN_API_ENDPOINT"),
+		KeptnAPIToken:                             os.Getenv("KEPTN_API_TOKEN"),
+		KeptnAPIURL:                               os.Getenv("KEPTN_API_URL"),
+		KeptnBridgeURL:                            os.Getenv("KEPTN_BRIDGE_URL"),
+		KeptnAPISSLVerificationEnabled:            readEnvAsBool("KEPTN_API_SSL_VERIFY", false),
+		KeptnAPICABundle:                          os.Getenv("KEPTN_API_CA_BUNDLE"),
+		KeptnAPIMaxRetries:                        readEnvAsInt("KEPTN_API_MAX_RETRIES", 3),
+		EventTransport:                            readEventTransport(),
+		NATSURL:                                   readEnvAsString("NATS_URL", "nats://keptn-nats-cluster:4222"),
+		NATSTopics:                                strings.Split(readEnvAsString("NATS_TOPICS", "sh.keptn.>"), ","),
+		NATSQueueGroup:                            readEnvAsString("NATS_QUEUE_GROUP", "dynatrace-service"),
+		NATSSSLVerificationEnabled:                readEnvAsBool("NATS_SSL_VERIFY", true),
+		NATSCABundle:                              os.Getenv("NATS_CA_BUNDLE"),
+		NATSMaxConcurrentEvents:                   readEnvAsInt("NATS_MAX_CONCURRENT_EVENTS", 20),
+		RunLocalEnabled:                           readEnvAsBool("RUNLOCAL", false),
+		RunLocalEventsDir:                         readEnvAsString("RUNLOCAL_EVENTS_DIR", "events"),
+		LeaderElectionEnabled:                     readEnvAsBool("LEADER_ELECTION_ENABLED", false),
+		PodNamespace:                              readEnvAsString("POD_NAMESPACE", "keptn"),
+		InCluster:                                 os.Getenv("KUBERNETES_SERVICE_HOST") != "",
+		HttpSSLVerificationEnabled:                readEnvAsBool("HTTP_SSL_VERIFY", true),
+		DynatraceHTTPProxy:                        os.Getenv("DYNATRACE_HTTP_PROXY"),
+		DynatraceHTTPSProxy:                       os.Getenv("DYNATRACE_HTTPS_PROXY"),
+		DynatraceNoProxy:                          os.Getenv("DYNATRACE_NO_PROXY"),
+		DynatraceAPIMaxPages:                      readEnvAsInt("DYNATRACE_API_MAX_PAGES", 100),
+		DynatraceAPIMetricsTimeout:                readEnvAsInt("DYNATRACE_API_METRICS_TIMEOUT_SECONDS", 120),
+		DynatraceAPIEntitiesTimeout:               readEnvAsInt("DYNATRACE_API_ENTITIES_TIMEOUT_SECONDS", 10),
+		DynatraceAPIEventsTimeout:                 readEnvAsInt("DYNATRACE_API_EVENTS_TIMEOUT_SECONDS", 30),
+		DynatraceAPIProblemsTimeout:               readEnvAsInt("DYNATRACE_API_PROBLEMS_TIMEOUT_SECONDS", 30),
+		DynatraceAPIDashboardsTimeout:             readEnvAsInt("DYNATRACE_API_DASHBOARDS_TIMEOUT_SECONDS", 30),
+		DynatraceAPITracingEnabled:                readEnvAsBool("DYNATRACE_API_TRACING", false),
+		DynatraceAPITraceFile:                     os.Getenv("DYNATRACE_API_TRACE_FILE"),
+		OTLPTracesEndpoint:                        readOTLPTracesEndpoint(),
+		OTLPHeaders:                               readOTLPHeaders(),
+		OTelServiceName:                           readEnvAsString("OTEL_SERVICE_NAME", "dynatrace-service"),
+		HttpCABundle:                              os.Getenv("HTTP_CA_BUNDLE"),
+		HttpMinTLSVersion:                         os.Getenv("HTTP_MIN_TLS_VERSION"),
+		ServiceSyncEnabled:                        readEnvAsBool("SYNCHRONIZE_DYNATRACE_SERVICES", false),
+		ServiceSyncInterval:                       readEnvAsInt("SYNCHRONIZE_DYNATRACE_SERVICES_INTERVAL_SECONDS", 60),
+	}
+}
+
+// Validate returns an error listing all values of the configuration the dynatrace-service cannot work with. Settings of disabled features are not validated
+func (c *Config) Validate() error {
+	var errs []string
+	check := func(valid bool, envName string, format string, value interface{}) {
+		if !valid {
+			errs = append(errs, fmt.Sprintf("%s "+format, envName, value))
+		}
+	}
+
+	check(isPort(c.ReceiverPort), "RCV_PORT", "must be a port between 1 and 65535, got %v", c.ReceiverPort)
+	check(isPort(c.ProblemWebhookPort) || !c.ProblemWebhookEnabled, "PROBLEM_WEBHOOK_PORT", "must be a port between 1 and 65535, got %v", c.ProblemWebhookPort)
+	check(c.ServiceSyncInterval > 0 || !c.ServiceSyncEnabled, "SYNCHRONIZE_DYNATRACE_SERVICES_INTERVAL_SECONDS", "must be greater than 0, got %v", c.ServiceSyncInterval)
+	check(c.SelfMonitoringInterval > 0 || !c.SelfMonitoringEnabled, "SELF_MONITORING_INTERVAL_SECONDS", "must be greater than 0, got %v", c.SelfMonitoringInterval)
+	check(c.EventBatchSize > 0, "DYNATRACE_EVENT_BATCH_SIZE", "must be greater than 0, got %v", c.EventBatchSize)
+	check(c.SLIMaxConcurrentQueriesPerTenant > 0, "SLI_MAX_CONCURRENT_QUERIES_PER_TENANT", "must be greater than 0, got %v", c.SLIMaxConcurrentQueriesPerTenant)
+	check(c.NATSMaxConcurrentEvents > 0, "NATS_MAX_CONCURRENT_EVENTS", "must be greater than 0, got %v", c.NATSMaxConcurrentEvents)
+	check(c.DynatraceAPIMaxPages > 0, "DYNATRACE_API_MAX_PAGES", "must be greater than 0, got %v", c.DynatraceAPIMaxPages)
+	check(c.SLIWaitForData >= -1, "SLI_WAIT_FOR_DATA_SECONDS", "must be -1 or greater, got %v", c.SLIWaitForData)
+
+	nonNegative := map[string]int{
+		"DEPLOYMENT_EVENT_DEDUPLICATION_WINDOW_SECONDS": c.DeploymentEventDeduplicationWindow,
+		"DYNATRACE_EVENT_QUEUE_SIZE":                    c.EventQueueSize,
+		"DYNATRACE_EVENT_MAX_RETRIES":                   c.EventMaxRetries,
+		"SLI_TIMEFRAME_SHIFT_SECONDS":                   c.SLITimeframeShift,
+		"SLI_NO_DATA_RETRIES":                           c.SLINoDataRetries,
+		"SLI_NO_DATA_RETRY_DELAY_SECONDS":               c.SLINoDataRetryDelay,
+		"SLI_RESULT_CACHE_TTL_SECONDS":                  c.SLIResultCacheTTL,
+		"DEAD_LETTER_MAX_ATTEMPTS":                      c.DeadLetterMaxAttempts,
+		"DYNATRACE_CONFIG_CACHE_TTL_SECONDS":            c.DynatraceConfigCacheTTL,
+		"KEPTN_PROJECT_CACHE_TTL_SECONDS":               c.KeptnProjectCacheTTL,
+		"OUTGOING_EVENT_MAX_RETRIES":                    c.OutgoingEventMaxRetries,
+		"OUTGOING_EVENT_RETRY_DELAY_SECONDS":            c.OutgoingEventRetryDelay,
+		"STARTUP_READINESS_TIMEOUT_SECONDS":             c.StartupReadinessTimeout,
+		"KEPTN_API_MAX_RETRIES":                         c.KeptnAPIMaxRetries,
+		"DYNATRACE_API_METRICS_TIMEOUT_SECONDS":         c.DynatraceAPIMetricsTimeout,
+		"DYNATRACE_API_ENTITIES_TIMEOUT_SECONDS":        c.DynatraceAPIEntitiesTimeout,
+		"DYNATRACE_API_EVENTS_TIMEOUT_SECONDS":          c.DynatraceAPIEventsTimeout,
+		"DYNATRACE_API_PROBLEMS_TIMEOUT_SECONDS":        c.DynatraceAPIProblemsTimeout,
+		"DYNATRACE_API_DASHBOARDS_TIMEOUT_SECONDS":      c.DynatraceAPIDashboardsTimeout,
+	}
+	for envName, value := range nonNegative {
+		check(value >= 0, envName, "must not be negative, got %v", value)
+	}
+
+	switch c.HttpMinTLSVersion {
+	case "", "1.0", "1.1", "1.2", "1.3":
+	default:
+		errs = append(errs, fmt.Sprintf("HTTP_MIN_TLS_VERSION must be one of 1.0, 1.1, 1.2 or 1.3, got %s", c.HttpMinTLSVersion))
+	}
+
+	switch c.SecretBackend {
+	case VaultSecretBackend:
+		check(c.VaultAddress != "", "VAULT_ADDR", "must be set when using the %s secret backend", c.SecretBackend)
+	case AWSSecretsManagerSecretBackend:
+		check(c.AWSRegion != "", "AWS_REGION", "must be set when using the %s secret backend", c.SecretBackend)
+	case AzureKeyVaultSecretBackend:
+		check(c.AzureKeyVaultURL != "", "AZURE_KEY_VAULT_URL", "must be set when using the %s secret backend", c.SecretBackend)
+	}
+
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.New("invalid configuration: " + strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func isPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// LogFields returns the configuration keyed by environment variable, with the values of secrets redacted
+func (c *Config) LogFields() log.Fields {
+	fields := log.Fields{}
+	value := reflect.ValueOf(c).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i).Interface()
+		if field.Tag.Get("secret") == "true" && fieldValue != "" {
+			fieldValue = "***"
+		}
+		fields[field.Tag.Get("env")] = fieldValue
+	}
+	return fields
+}
@@ -3,16 +3,20 @@ package env
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 )
 
 const logLevelEnvironmentVariable = "LOG_LEVEL_DYNATRACE_SERVICE"
 
-// GetLogLevel gets the log level specified by the LOG_LEVEL_DYNATRACE_SERVICE environment variable.
-// If none is specified, log.InfoLevel is assumed.
-func GetLogLevel() log.Level {
-	level, err := log.ParseLevel(os.Getenv(logLevelEnvironmentVariable))
+func readLogLevel() log.Level {
+	envValue := os.Getenv(logLevelEnvironmentVariable)
+	if envValue == "" {
+		return log.InfoLevel
+	}
+
+	level, err := log.ParseLevel(envValue)
 	if err != nil {
 		log.WithError(err).Error("Couldn't parse " + logLevelEnvironmentVariable + " environment variable")
 		return log.InfoLevel
@@ -21,45 +25,186 @@ func GetLogLevel() log.Level {
 	return level
 }
 
-// IsTaggingRulesGenerationEnabled returns whether tagging rules should be generated when configuring the monitoring
-func IsTaggingRulesGenerationEnabled() bool {
-	return readEnvAsBool("GENERATE_TAGGING_RULES", false)
+// TextLogFormat and JSONLogFormat are the supported formats of the logs of the dynatrace-service
+const (
+	TextLogFormat = "text"
+	JSONLogFormat = "json"
+)
+
+func readLogFormat() string {
+	const envName = "LOG_FORMAT"
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case TextLogFormat, JSONLogFormat:
+		return envValue
+	case "":
+		return TextLogFormat
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": TextLogFormat,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return TextLogFormat
+	}
 }
 
-// IsProblemNotificationsGenerationEnabled returns whether problem notifications should be generated when configuring the monitoring
-func IsProblemNotificationsGenerationEnabled() bool {
-	return readEnvAsBool("GENERATE_PROBLEM_NOTIFICATIONS", false)
+func readFailureEventType() string {
+	const envName = "FAILURE_EVENT_TYPE"
+	const defaultValue = "ERROR_EVENT"
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case "ERROR_EVENT", "AVAILABILITY_EVENT":
+		return envValue
+	case "":
+		return defaultValue
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": defaultValue,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return defaultValue
+	}
 }
 
-// IsManagementZonesGenerationEnabled returns whether management zones should be generated when configuring the monitoring
-func IsManagementZonesGenerationEnabled() bool {
-	return readEnvAsBool("GENERATE_MANAGEMENT_ZONES", false)
+// DeadLetterLogSink, DeadLetterResourceSink and DeadLetterDynatraceSink are the supported sinks for events that repeatedly failed to be processed
+const (
+	DeadLetterLogSink       = "log"
+	DeadLetterResourceSink  = "resource"
+	DeadLetterDynatraceSink = "dynatrace"
+)
+
+func readDeadLetterSink() string {
+	const envName = "DEAD_LETTER_SINK"
+	const defaultValue = DeadLetterLogSink
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case DeadLetterLogSink, DeadLetterResourceSink, DeadLetterDynatraceSink:
+		return envValue
+	case "":
+		return defaultValue
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": defaultValue,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return defaultValue
+	}
 }
 
-// IsDashboardsGenerationEnabled returns whether dashboards should be generated when configuring the monitoring
-func IsDashboardsGenerationEnabled() bool {
-	return readEnvAsBool("GENERATE_DASHBOARDS", false)
+// JSONDashboardStorage, GzipDashboardStorage and NoDashboardStorage are the supported ways of storing the dashboard used for SLIs in the configuration repository
+const (
+	JSONDashboardStorage = "json"
+	GzipDashboardStorage = "gzip"
+	NoDashboardStorage   = "none"
+)
+
+func readDashboardStorage() string {
+	const envName = "DASHBOARD_STORAGE"
+	const defaultValue = JSONDashboardStorage
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case JSONDashboardStorage, GzipDashboardStorage, NoDashboardStorage:
+		return envValue
+	case "":
+		return defaultValue
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": defaultValue,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return defaultValue
+	}
 }
 
-// IsMetricEventsGenerationEnabled returns whether metric events should be generated when configuring the monitoring
-func IsMetricEventsGenerationEnabled() bool {
-	return readEnvAsBool("GENERATE_METRIC_EVENTS", false)
+// KubernetesSecretBackend, KeptnSecretServiceSecretBackend, VaultSecretBackend, AWSSecretsManagerSecretBackend and AzureKeyVaultSecretBackend
+// are the supported backends of the secrets containing the Dynatrace and Keptn API credentials
+const (
+	KubernetesSecretBackend         = "kubernetes"
+	KeptnSecretServiceSecretBackend = "keptn-secret-service"
+	VaultSecretBackend              = "vault"
+	AWSSecretsManagerSecretBackend  = "aws-secrets-manager"
+	AzureKeyVaultSecretBackend      = "azure-key-vault"
+)
+
+func readSecretBackend() string {
+	const envName = "SECRET_BACKEND"
+	const defaultValue = KubernetesSecretBackend
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case KubernetesSecretBackend, KeptnSecretServiceSecretBackend, VaultSecretBackend, AWSSecretsManagerSecretBackend, AzureKeyVaultSecretBackend:
+		return envValue
+	case "":
+		return defaultValue
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": defaultValue,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return defaultValue
+	}
 }
 
-// IsHttpSSLVerificationEnabled returns whether the SSL verification is enabled or disabled
-func IsHttpSSLVerificationEnabled() bool {
-	return readEnvAsBool("HTTP_SSL_VERIFY", true)
+// HTTPTransport and NATSTransport are the supported transports for receiving and sending events
+const (
+	HTTPTransport = "http"
+	NATSTransport = "nats"
+)
+
+func readEventTransport() string {
+	const envName = "EVENT_TRANSPORT"
+	const defaultValue = HTTPTransport
+
+	envValue := os.Getenv(envName)
+	switch envValue {
+	case HTTPTransport, NATSTransport:
+		return envValue
+	case "":
+		return defaultValue
+	default:
+		log.WithFields(
+			log.Fields{
+				"name":    envName,
+				"value":   envValue,
+				"default": defaultValue,
+			}).Error("Unsupported value for environment variable. Using default value.")
+		return defaultValue
+	}
 }
 
-// IsServiceSyncEnabled returns wether the service synchronization is enabled or disabled
-func IsServiceSyncEnabled() bool {
-	return readEnvAsBool("SYNCHRONIZE_DYNATRACE_SERVICES", false)
+func readOTLPTracesEndpoint() string {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if endpoint != "" {
+		return endpoint
+	}
+
+	endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return ""
+	}
+	return strings.TrimSuffix(endpoint, "/") + "/v1/traces"
 }
 
-// GetServiceSyncInterval returns the number of seconds the service synchronizer should sleep between synchronization runs.
-// If the environment variable is empty or cannot be parsed, a default sync interval is used.
-func GetServiceSyncInterval() int {
-	return readEnvAsInt("SYNCHRONIZE_DYNATRACE_SERVICES_INTERVAL_SECONDS", 60)
+func readOTLPHeaders() string {
+	headers := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS")
+	if headers != "" {
+		return headers
+	}
+	return os.Getenv("OTEL_EXPORTER_OTLP_HEADERS")
 }
 
 func readEnvAsBool(env string, defaultValue bool) bool {
@@ -69,7 +214,7 @@ func readEnvAsBool(env string, defaultValue bool) bool {
 			log.Fields{
 				"name":    env,
 				"default": defaultValue,
-			}).Info("Environment variable not set or empty. Using default value.")
+			}).Debug("Environment variable not set or empty. Using default value.")
 		return defaultValue
 	}
 
@@ -94,7 +239,7 @@ func readEnvAsInt(env string, defaultValue int) int {
 			log.Fields{
 				"name":    env,
 				"default": defaultValue,
-			}).Info("Environment variable not set or empty. Using default value.")
+			}).Debug("Environment variable not set or empty. Using default value.")
 		return defaultValue
 	}
 
@@ -111,3 +256,29 @@ func readEnvAsInt(env string, defaultValue int) int {
 
 	return int(parseInt)
 }
+
+func readEnvAsString(env string, defaultValue string) string {
+	envValue := os.Getenv(env)
+	if envValue == "" {
+		log.WithFields(
+			log.Fields{
+				"name":    env,
+				"default": defaultValue,
+			}).Debug("Environment variable not set or empty. Using default value.")
+		return defaultValue
+	}
+
+	return envValue
+}
+
+// readEnvAsList returns the non-empty, comma-separated values of the environment variable
+func readEnvAsList(env string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(env), ",") {
+		value = strings.TrimSpace(value)
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
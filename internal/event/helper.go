@@ -2,10 +2,45 @@ package event
 
 import (
 	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
 )
 
+// extensionNamePattern matches the names of cloud event extension attributes, which consist of lower-case letters and digits only
+var extensionNamePattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
+// reservedExtensionNames are the extensions set by the dynatrace-service itself, which cannot be overridden
+var reservedExtensionNames = map[string]bool{"shkeptncontext": true, "triggeredid": true}
+
 // GetEventSource gets the source to be used for CloudEvents originating from the dynatrace-service
-func GetEventSource() string {
-	source, _ := url.Parse("dynatrace-service")
+func GetEventSource(cfg *env.Config) string {
+	source, err := url.Parse(cfg.EventSource)
+	if err != nil {
+		log.WithError(err).Error("Invalid event source, using dynatrace-service")
+		return "dynatrace-service"
+	}
 	return source.String()
 }
+
+// GetEventExtensions gets the custom extensions added to CloudEvents originating from the dynatrace-service, e.g. gitcommitid=abc123.
+// Entries without value, with an invalid extension name or overriding shkeptncontext or triggeredid are ignored
+func GetEventExtensions(cfg *env.Config) map[string]string {
+	extensions := map[string]string{}
+	for _, entry := range cfg.EventExtensions {
+		parts := strings.SplitN(entry, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !extensionNamePattern.MatchString(name) {
+			log.WithField("extension", entry).Error("Ignoring invalid event extension, expected name=value with a name of lower-case letters and digits")
+			continue
+		}
+		if reservedExtensionNames[name] {
+			log.WithField("extension", name).Error("Ignoring event extension set by the dynatrace-service itself")
+			continue
+		}
+		extensions[name] = strings.TrimSpace(parts[1])
+	}
+	return extensions
+}
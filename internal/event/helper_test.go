@@ -0,0 +1,49 @@
+package event
+
+import (
+	"os"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEventSource(t *testing.T) {
+	assert.Equal(t, "dynatrace-service", GetEventSource(env.ReadConfig()))
+
+	os.Setenv("EVENT_SOURCE", "dynatrace-service-team-a")
+	defer os.Unsetenv("EVENT_SOURCE")
+
+	assert.Equal(t, "dynatrace-service-team-a", GetEventSource(env.ReadConfig()))
+}
+
+func TestGetEventExtensions(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  map[string]string
+	}{
+		{
+			name: "not set",
+			want: map[string]string{},
+		},
+		{
+			name:  "several extensions",
+			value: "gitcommitid=abc123, team = a",
+			want:  map[string]string{"gitcommitid": "abc123", "team": "a"},
+		},
+		{
+			name:  "invalid entries are ignored",
+			value: "gitcommitid,Team=a,team-name=a,=b,triggeredid=c,instance=2",
+			want:  map[string]string{"instance": "2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("EVENT_EXTENSIONS", tt.value)
+			defer os.Unsetenv("EVENT_EXTENSIONS")
+
+			assert.Equal(t, tt.want, GetEventExtensions(env.ReadConfig()))
+		})
+	}
+}
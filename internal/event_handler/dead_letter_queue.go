@@ -0,0 +1,264 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// deadLetterRetryDelay is the time waited before processing an event that failed again
+const deadLetterRetryDelay = 2 * time.Second
+
+const deadLetterResourceURIFormat = "dynatrace/dead-letters/%s.json"
+
+// DeadLetter contains an event that repeatedly failed to be processed together with the last error
+type DeadLetter struct {
+	EventID   string            `json:"eventID"`
+	EventType string            `json:"eventType"`
+	Attempts  int               `json:"attempts"`
+	Error     string            `json:"error"`
+	Event     cloudevents.Event `json:"event"`
+}
+
+// DeadLetterSink receives events that repeatedly failed to be processed
+type DeadLetterSink interface {
+	Write(deadLetter DeadLetter) error
+}
+
+// DeadLetterQueue processes an event again after it failed, as received events are not redelivered, and writes it to a DeadLetterSink
+// once maxAttempts attempts failed
+type DeadLetterQueue struct {
+	maxAttempts int
+	retryDelay  time.Duration
+	sink        DeadLetterSink
+}
+
+var defaultDeadLetterQueue *DeadLetterQueue
+var defaultDeadLetterQueueOnce sync.Once
+
+// GetDefaultDeadLetterQueue returns the DeadLetterQueue shared by all event handlers, created using the configuration of the first call
+func GetDefaultDeadLetterQueue(cfg *env.Config) *DeadLetterQueue {
+	defaultDeadLetterQueueOnce.Do(func() {
+		defaultDeadLetterQueue = NewDeadLetterQueue(cfg.DeadLetterMaxAttempts, newDeadLetterSink(cfg))
+	})
+
+	return defaultDeadLetterQueue
+}
+
+// NewDeadLetterQueue creates a new DeadLetterQueue. If maxAttempts is 0 or less, events are processed once and never dead-lettered
+func NewDeadLetterQueue(maxAttempts int, sink DeadLetterSink) *DeadLetterQueue {
+	return &DeadLetterQueue{
+		maxAttempts: maxAttempts,
+		retryDelay:  deadLetterRetryDelay,
+		sink:        sink,
+	}
+}
+
+// Handle processes the event using process until it succeeds or was attempted maxAttempts times and returns the error that should be
+// reported back for the event. If the last attempt failed, the event is written to the sink and nil is returned
+func (q *DeadLetterQueue) Handle(event cloudevents.Event, process func() error) error {
+	if q == nil || q.maxAttempts <= 0 {
+		return process()
+	}
+
+	var err error
+	for attempt := 1; attempt <= q.maxAttempts; attempt++ {
+		if attempt > 1 {
+			log.WithError(err).WithFields(log.Fields{"eventID": event.ID(), "attempt": attempt}).Info("Processing event again")
+			time.Sleep(q.retryDelay)
+		}
+
+		err = process()
+		if err == nil {
+			return nil
+		}
+	}
+
+	q.DeadLetter(event, q.maxAttempts, err)
+	return nil
+}
+
+// DeadLetter writes the event to the sink right away, e.g. an outgoing event that could not be delivered to Keptn after all retries
+func (q *DeadLetterQueue) DeadLetter(event cloudevents.Event, attempts int, err error) {
+	deadLetter := DeadLetter{
+		EventID:   event.ID(),
+		EventType: event.Type(),
+		Attempts:  attempts,
+		Error:     err.Error(),
+		Event:     event,
+	}
+	if sinkErr := q.sink.Write(deadLetter); sinkErr != nil {
+		log.WithError(sinkErr).WithField("eventID", event.ID()).Error("Could not write event to dead-letter sink")
+		logDeadLetter(deadLetter)
+	}
+}
+
+func newDeadLetterSink(cfg *env.Config) DeadLetterSink {
+	switch cfg.DeadLetterSink {
+	case env.DeadLetterResourceSink:
+		return NewResourceDeadLetterSink(keptn.NewDefaultConfigResourceClient(cfg))
+	case env.DeadLetterDynatraceSink:
+		return NewDynatraceDeadLetterSink(cfg)
+	default:
+		return LogDeadLetterSink{}
+	}
+}
+
+// LogDeadLetterSink writes dead-lettered events to the log
+type LogDeadLetterSink struct {
+}
+
+// Write writes the dead letter to the log
+func (s LogDeadLetterSink) Write(deadLetter DeadLetter) error {
+	logDeadLetter(deadLetter)
+	return nil
+}
+
+func logDeadLetter(deadLetter DeadLetter) {
+	log.WithFields(
+		log.Fields{
+			"eventID":   deadLetter.EventID,
+			"eventType": deadLetter.EventType,
+			"attempts":  deadLetter.Attempts,
+			"error":     deadLetter.Error,
+			"event":     string(deadLetter.Event.Data()),
+		}).Error("Giving up processing event")
+}
+
+// ResourceDeadLetterSink stores dead-lettered events as Keptn resources on project level
+type ResourceDeadLetterSink struct {
+	client keptn.ConfigResourceClientInterface
+}
+
+// NewResourceDeadLetterSink creates a new ResourceDeadLetterSink
+func NewResourceDeadLetterSink(client keptn.ConfigResourceClientInterface) *ResourceDeadLetterSink {
+	return &ResourceDeadLetterSink{
+		client: client,
+	}
+}
+
+// Write uploads the dead letter to the project of the event
+func (s *ResourceDeadLetterSink) Write(deadLetter DeadLetter) error {
+	eventData := &keptnv2.EventData{}
+	if err := deadLetter.Event.DataAs(eventData); err != nil || eventData.GetProject() == "" {
+		return fmt.Errorf("cannot store dead letter as resource: event %s has no project", deadLetter.EventID)
+	}
+
+	content, err := json.MarshalIndent(deadLetter, "", "  ")
+	if err != nil {
+		return common.NewMarshalJSONError("dead letter", err)
+	}
+
+	return s.client.UploadResource(content, fmt.Sprintf(deadLetterResourceURIFormat, deadLetter.EventID), eventData.GetProject(), "", "")
+}
+
+// DynatraceDeadLetterSink sends a CUSTOM_INFO event to the Dynatrace environment configured for the dead-lettered event
+type DynatraceDeadLetterSink struct {
+	dtConfigGetter config.DynatraceConfigGetterInterface
+	cfg            *env.Config
+}
+
+// NewDynatraceDeadLetterSink creates a new DynatraceDeadLetterSink using the default secret of the configuration for projects without dynatrace.conf.yaml
+func NewDynatraceDeadLetterSink(cfg *env.Config) *DynatraceDeadLetterSink {
+	return &DynatraceDeadLetterSink{
+		dtConfigGetter: config.NewDynatraceConfigGetter(keptn.GetDefaultCachingDynatraceConfigResourceClient(cfg)),
+		cfg:            cfg,
+	}
+}
+
+// Write sends the dead letter as a CUSTOM_INFO event to Dynatrace
+func (s *DynatraceDeadLetterSink) Write(deadLetter DeadLetter) error {
+	event, err := newDeadLetterEventAdapter(deadLetter.Event)
+	if err != nil {
+		return err
+	}
+
+	dynatraceConfig, dynatraceCredentials, _, err := getDynatraceCredentialsAndConfig(event, s.dtConfigGetter, s.cfg)
+	if err != nil {
+		return err
+	}
+
+	dtClient, err := dynatrace.NewClientWithTLSOptions(dynatraceCredentials, dynatraceConfig.TLS, s.cfg)
+	if err != nil {
+		return err
+	}
+
+	ie := dynatrace.CreateInfoEventDTO(event, common.NewNotAvailableImageAndTag(), dynatraceConfig.AttachRules)
+	ie.Title = fmt.Sprintf("Keptn event %s could not be processed", deadLetter.EventType)
+	ie.Description = fmt.Sprintf("Gave up processing event %s after %d attempts: %s", deadLetter.EventID, deadLetter.Attempts, deadLetter.Error)
+
+	dynatrace.NewEventsClientWithEventTypes(dtClient, dynatraceConfig.EventTypes, s.cfg).AddInfoEvent(ie)
+	return nil
+}
+
+// deadLetterEventAdapter provides the content of an arbitrary Keptn event
+type deadLetterEventAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+}
+
+func newDeadLetterEventAdapter(e cloudevents.Event) (*deadLetterEventAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	eventData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &deadLetterEventAdapter{
+		event:      *eventData,
+		cloudEvent: ceAdapter,
+	}, nil
+}
+
+func (a deadLetterEventAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+func (a deadLetterEventAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+func (a deadLetterEventAdapter) GetEvent() string {
+	return a.cloudEvent.Type()
+}
+
+func (a deadLetterEventAdapter) GetProject() string {
+	return a.event.GetProject()
+}
+
+func (a deadLetterEventAdapter) GetStage() string {
+	return a.event.GetStage()
+}
+
+func (a deadLetterEventAdapter) GetService() string {
+	return a.event.GetService()
+}
+
+func (a deadLetterEventAdapter) GetDeployment() string {
+	return ""
+}
+
+func (a deadLetterEventAdapter) GetTestStrategy() string {
+	return ""
+}
+
+func (a deadLetterEventAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+func (a deadLetterEventAdapter) GetLabels() map[string]string {
+	return a.event.GetLabels()
+}
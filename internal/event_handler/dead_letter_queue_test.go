@@ -0,0 +1,96 @@
+package event_handler
+
+import (
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type deadLetterSinkMock struct {
+	deadLetters []DeadLetter
+}
+
+func (s *deadLetterSinkMock) Write(deadLetter DeadLetter) error {
+	s.deadLetters = append(s.deadLetters, deadLetter)
+	return nil
+}
+
+func createDeadLetterTestEvent(id string) cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	ce.SetID(id)
+	ce.SetType("sh.keptn.event.get-sli.triggered")
+	ce.SetSource("lighthouse-service")
+	return ce
+}
+
+// createFailingProcess returns a function processing an event that fails the first failures times and counts its calls
+func createFailingProcess(failures int, err error) (func() error, *int) {
+	calls := 0
+	return func() error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestDeadLetterQueue_EventIsDeadLetteredAfterMaxAttempts(t *testing.T) {
+	sink := &deadLetterSinkMock{}
+	q := NewDeadLetterQueue(3, sink)
+	q.retryDelay = 0
+	processingErr := errors.New("processing failed")
+
+	process, calls := createFailingProcess(3, processingErr)
+	assert.NoError(t, q.Handle(createDeadLetterTestEvent("event-1"), process))
+	assert.Equal(t, 3, *calls)
+
+	if assert.Equal(t, 1, len(sink.deadLetters)) {
+		assert.Equal(t, "event-1", sink.deadLetters[0].EventID)
+		assert.Equal(t, "sh.keptn.event.get-sli.triggered", sink.deadLetters[0].EventType)
+		assert.Equal(t, 3, sink.deadLetters[0].Attempts)
+		assert.Equal(t, "processing failed", sink.deadLetters[0].Error)
+	}
+}
+
+func TestDeadLetterQueue_EventIsProcessedAgainUntilItSucceeds(t *testing.T) {
+	sink := &deadLetterSinkMock{}
+	q := NewDeadLetterQueue(3, sink)
+	q.retryDelay = 0
+
+	process, calls := createFailingProcess(2, errors.New("processing failed"))
+	assert.NoError(t, q.Handle(createDeadLetterTestEvent("event-1"), process))
+	assert.Equal(t, 3, *calls)
+	assert.Empty(t, sink.deadLetters)
+
+	// an event succeeding right away is processed once
+	process, calls = createFailingProcess(0, nil)
+	assert.NoError(t, q.Handle(createDeadLetterTestEvent("event-2"), process))
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, sink.deadLetters)
+}
+
+func TestDeadLetterQueue_DeadLetterWritesToSinkRightAway(t *testing.T) {
+	sink := &deadLetterSinkMock{}
+	q := NewDeadLetterQueue(3, sink)
+
+	q.DeadLetter(createDeadLetterTestEvent("event-1"), 5, errors.New("could not deliver outgoing event to Keptn"))
+	if assert.Equal(t, 1, len(sink.deadLetters)) {
+		assert.Equal(t, "event-1", sink.deadLetters[0].EventID)
+		assert.Equal(t, 5, sink.deadLetters[0].Attempts)
+		assert.Equal(t, "could not deliver outgoing event to Keptn", sink.deadLetters[0].Error)
+	}
+}
+
+func TestDeadLetterQueue_DisabledWithZeroMaxAttempts(t *testing.T) {
+	sink := &deadLetterSinkMock{}
+	q := NewDeadLetterQueue(0, sink)
+	processingErr := errors.New("processing failed")
+
+	process, calls := createFailingProcess(5, processingErr)
+	assert.Equal(t, processingErr, q.Handle(createDeadLetterTestEvent("event-1"), process))
+	assert.Equal(t, 1, *calls)
+	assert.Empty(t, sink.deadLetters)
+}
@@ -1,5 +1,7 @@
 package event_handler
 
+import "github.com/keptn-contrib/dynatrace-service/internal/adapter"
+
 type ErrorHandler struct {
 	err error
 }
@@ -7,3 +9,13 @@ type ErrorHandler struct {
 func (eh ErrorHandler) HandleEvent() error {
 	return eh.err
 }
+
+// failedTaskHandler is used for tasks that cannot be executed at all, e.g. because of an invalid dynatrace.conf.yaml
+type failedTaskHandler struct {
+	err error
+}
+
+// HandleTask returns the error that prevented the task from being executed
+func (h failedTaskHandler) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	return nil, h.err
+}
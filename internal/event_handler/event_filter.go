@@ -0,0 +1,65 @@
+package event_handler
+
+import (
+	"path"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+// filterList contains the patterns of allowed and denied values, e.g. sockshop or sockshop-*
+type filterList struct {
+	allowed []string
+	denied  []string
+}
+
+// isAllowed returns whether the value matches no denied and, if there are any, one of the allowed patterns.
+// Empty values, e.g. the stage of project-level events, are always allowed
+func (l filterList) isAllowed(value string) bool {
+	if value == "" {
+		return true
+	}
+
+	if matchesAny(value, l.denied) {
+		return false
+	}
+
+	return len(l.allowed) == 0 || matchesAny(value, l.allowed)
+}
+
+func matchesAny(value string, patterns []string) bool {
+	for _, pattern := range patterns {
+		matched, err := path.Match(pattern, value)
+		if err != nil {
+			log.WithError(err).WithField("pattern", pattern).Error("Invalid event filter pattern")
+			continue
+		}
+
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// eventFilter scopes the dynatrace-service to particular Keptn projects, stages and services, e.g. in shared Keptn installations
+type eventFilter struct {
+	projects filterList
+	stages   filterList
+	services filterList
+}
+
+// newEventFilter creates an eventFilter using the allow and deny lists of the configuration
+func newEventFilter(cfg *env.Config) eventFilter {
+	return eventFilter{
+		projects: filterList{allowed: cfg.AllowedProjects, denied: cfg.DeniedProjects},
+		stages:   filterList{allowed: cfg.AllowedStages, denied: cfg.DeniedStages},
+		services: filterList{allowed: cfg.AllowedServices, denied: cfg.DeniedServices},
+	}
+}
+
+// isAllowed returns whether the dynatrace-service acts on the event
+func (f eventFilter) isAllowed(event adapter.EventContentAdapter) bool {
+	return f.projects.isAllowed(event.GetProject()) && f.stages.isAllowed(event.GetStage()) && f.services.isAllowed(event.GetService())
+}
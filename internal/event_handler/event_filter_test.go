@@ -0,0 +1,85 @@
+package event_handler
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilter_IsAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  eventFilter
+		project string
+		stage   string
+		service string
+		want    bool
+	}{
+		{
+			name:    "no filter",
+			project: "sockshop",
+			stage:   "production",
+			service: "carts",
+			want:    true,
+		},
+		{
+			name:    "allowed project",
+			filter:  eventFilter{projects: filterList{allowed: []string{"podtato", "sockshop"}}},
+			project: "sockshop",
+			stage:   "production",
+			service: "carts",
+			want:    true,
+		},
+		{
+			name:    "project not allowed",
+			filter:  eventFilter{projects: filterList{allowed: []string{"podtato"}}},
+			project: "sockshop",
+			stage:   "production",
+			service: "carts",
+			want:    false,
+		},
+		{
+			name:    "allowed project pattern",
+			filter:  eventFilter{projects: filterList{allowed: []string{"sock*"}}},
+			project: "sockshop",
+			stage:   "production",
+			service: "carts",
+			want:    true,
+		},
+		{
+			name:    "denied stage",
+			filter:  eventFilter{stages: filterList{denied: []string{"production"}}},
+			project: "sockshop",
+			stage:   "production",
+			service: "carts",
+			want:    false,
+		},
+		{
+			name:    "deny list wins over allow list",
+			filter:  eventFilter{services: filterList{allowed: []string{"*"}, denied: []string{"carts-db"}}},
+			project: "sockshop",
+			stage:   "production",
+			service: "carts-db",
+			want:    false,
+		},
+		{
+			name:    "project-level event without stage and service",
+			filter:  eventFilter{stages: filterList{allowed: []string{"dev"}}, services: filterList{allowed: []string{"carts"}}},
+			project: "sockshop",
+			want:    true,
+		},
+		{
+			name:    "invalid pattern is ignored",
+			filter:  eventFilter{projects: filterList{allowed: []string{"[sockshop", "sockshop"}}},
+			project: "sockshop",
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &test.EventData{Project: tt.project, Stage: tt.stage, Service: tt.service}
+			assert.Equal(t, tt.want, tt.filter.isAllowed(event))
+		})
+	}
+}
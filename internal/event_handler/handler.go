@@ -1,18 +1,24 @@
 package event_handler
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/config"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
 	"github.com/keptn-contrib/dynatrace-service/internal/deployment"
+	"github.com/keptn-contrib/dynatrace-service/internal/diagnostics"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"github.com/keptn-contrib/dynatrace-service/internal/monitoring"
 	"github.com/keptn-contrib/dynatrace-service/internal/problem"
 	"github.com/keptn-contrib/dynatrace-service/internal/sli"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
 	keptnevents "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
@@ -22,22 +28,28 @@ type DynatraceEventHandler interface {
 	HandleEvent() error
 }
 
-// Retrieves Dynatrace Credential information
-func getDynatraceCredentialsAndConfig(keptnEvent adapter.EventContentAdapter, dtConfigGetter config.DynatraceConfigGetterInterface) (*config.DynatraceConfigFile, *credentials.DTCredentials, string, error) {
+// Retrieves Dynatrace Credential information, using the default secret of the configuration if there is no dynatrace.conf.yaml
+func getDynatraceCredentialsAndConfig(keptnEvent adapter.EventContentAdapter, dtConfigGetter config.DynatraceConfigGetterInterface, cfg *env.Config) (*config.DynatraceConfigFile, *credentials.DTCredentials, string, error) {
 	dynatraceConfig, err := dtConfigGetter.GetDynatraceConfig(keptnEvent)
 	if err != nil {
+		// a missing dynatrace.conf.yaml is fine, but an invalid one must not be silently replaced by the default one
+		if isInvalidDynatraceConfigError(err) {
+			log.WithError(err).Error("Invalid Dynatrace config")
+			return nil, nil, "", err
+		}
+
 		log.WithError(err).Warn("Failed to load Dynatrace config - will use a default one!")
 
 		// TODO 2021-09-08: think about a better way of handling it on a use-case per use-case basis
 		dynatraceConfig = &config.DynatraceConfigFile{
 			SpecVersion: "0.1.0",
-			DtCreds:     "dynatrace",
+			DtCreds:     cfg.DefaultDynatraceSecretName,
 			Dashboard:   "",
 			AttachRules: nil,
 		}
 	}
 
-	cm, err := credentials.NewCredentialManager(nil)
+	cm, err := credentials.NewCredentialManager(nil, cfg)
 	if err != nil {
 		return nil, nil, "", err
 	}
@@ -46,9 +58,9 @@ func getDynatraceCredentialsAndConfig(keptnEvent adapter.EventContentAdapter, dt
 	var fallbackDecorator *credentials.CredentialManagerFallbackDecorator
 	switch keptnEvent.(type) {
 	case *sli.GetSLITriggeredAdapter:
-		fallbackDecorator = credentials.NewCredentialManagerSLIServiceFallbackDecorator(cm, keptnEvent.GetProject())
+		fallbackDecorator = credentials.NewCredentialManagerSLIServiceFallbackDecorator(cm, keptnEvent.GetProject(), cfg)
 	default:
-		fallbackDecorator = credentials.NewCredentialManagerDefaultFallbackDecorator(cm)
+		fallbackDecorator = credentials.NewCredentialManagerDefaultFallbackDecorator(cm, cfg)
 	}
 
 	creds, err := fallbackDecorator.GetDynatraceCredentials(dynatraceConfig.DtCreds)
@@ -60,11 +72,23 @@ func getDynatraceCredentialsAndConfig(keptnEvent adapter.EventContentAdapter, dt
 	return dynatraceConfig, creds, fallbackDecorator.GetSecretName(), nil
 }
 
-func NewEventHandler(event cloudevents.Event) (DynatraceEventHandler, error) {
+// isInvalidDynatraceConfigError returns whether the dynatrace.conf.yaml could be retrieved, but is invalid
+func isInvalidDynatraceConfigError(err error) bool {
+	var rnfErr *keptn.ResourceNotFoundError
+	var reErr *keptn.ResourceEmptyError
+	if errors.As(err, &rnfErr) || errors.As(err, &reErr) {
+		return false
+	}
+
+	return common.GetErrorType(err) == common.UserConfigurationErrorType
+}
+
+func NewEventHandler(event cloudevents.Event, cfg *env.Config) (DynatraceEventHandler, error) {
 	log.WithField("eventType", event.Type()).Debug("Received event")
-	dtConfigGetter := config.NewDynatraceConfigGetter(keptn.NewDefaultResourceClient())
 
-	keptnEvent, err := getEventAdapter(event)
+	invalidateProjectMetadata(event, keptn.GetDefaultCachingProjectMetadataClient(cfg))
+
+	keptnEvent, err := getEventAdapter(event, cfg)
 	if err != nil {
 		log.WithError(err).Error("Could not create event adapter")
 		return ErrorHandler{err: err}, nil
@@ -75,52 +99,282 @@ func NewEventHandler(event cloudevents.Event) (DynatraceEventHandler, error) {
 		return NoOpHandler{}, nil
 	}
 
-	dynatraceConfig, dynatraceCredentials, secretName, err := getDynatraceCredentialsAndConfig(keptnEvent, dtConfigGetter)
+	// get-sli events for other SLI providers are ignored before any configuration or credentials are retrieved
+	if sliAdapter, ok := keptnEvent.(*sli.GetSLITriggeredAdapter); ok && sliAdapter.IsNotForDynatrace() {
+		log.WithField("sliProvider", sliAdapter.GetSLIProvider()).Debug("Ignoring get-sli event for other SLI provider")
+		return NoOpHandler{}, nil
+	}
+
+	dtConfigGetter := getDynatraceConfigGetter(keptnEvent, cfg)
+
+	if !newEventFilter(cfg).isAllowed(keptnEvent) {
+		log.WithFields(log.Fields{"project": keptnEvent.GetProject(), "stage": keptnEvent.GetStage(), "service": keptnEvent.GetService()}).Debug("Ignoring event excluded by event filter")
+		return NoOpHandler{}, nil
+	}
+
+	kClient, err := keptn.NewDefaultClient(event, cfg)
 	if err != nil {
-		log.WithError(err).Error("Could not get dynatrace credentials and config")
+		log.WithError(err).Error("Could not get create Keptn client")
 		return ErrorHandler{err: err}, nil
 	}
 
-	dtClient := dynatrace.NewClient(dynatraceCredentials)
-	kClient, err := keptn.NewDefaultClient(event)
+	// diagnostics must not depend on the credentials and the configuration they are supposed to check
+	if diagnoseAdapter, ok := keptnEvent.(*diagnostics.DiagnoseTriggeredAdapter); ok {
+		d, err := diagnostics.NewDefaultDiagnostics(cfg)
+		if err != nil {
+			return NewTaskLifecycleHandler(diagnoseAdapter, diagnostics.TaskName, kClient, failedTaskHandler{err: err}), nil
+		}
+		return NewBackgroundHandler(NewTaskLifecycleHandler(diagnoseAdapter, diagnostics.TaskName, kClient, diagnostics.NewDiagnoseTaskHandler(diagnoseAdapter, d)), event, GetDefaultDeadLetterQueue(cfg)), nil
+	}
+
+	dynatraceConfig, dynatraceCredentials, secretName, err := getDynatraceCredentialsAndConfig(keptnEvent, dtConfigGetter, cfg)
+	var dtClient *dynatrace.Client
+	if err == nil {
+		dtClient, err = dynatrace.NewClientWithTLSOptions(dynatraceCredentials, dynatraceConfig.TLS, cfg)
+	}
 	if err != nil {
-		log.WithError(err).Error("Could not get create Keptn client")
+		log.WithError(err).Error("Could not get dynatrace credentials and config")
+
+		// report the error in the finished event of tasks the dynatrace-service is responsible for
+		if taskEvent, taskName, ok := getTaskEventAdapter(keptnEvent); ok {
+			return NewTaskLifecycleHandler(taskEvent, taskName, kClient, failedTaskHandler{err: err}), nil
+		}
 		return ErrorHandler{err: err}, nil
 	}
+	dtClient.SetParentSpan(tracing.FromEvent(event))
 
 	switch aType := keptnEvent.(type) {
 	case *monitoring.ConfigureMonitoringAdapter:
-		return monitoring.NewConfigureMonitoringEventHandler(keptnEvent.(*monitoring.ConfigureMonitoringAdapter), dtClient, kClient, keptn.NewDefaultResourceClient(), keptn.NewDefaultServiceClient()), nil
+		cmAdapter := keptnEvent.(*monitoring.ConfigureMonitoringAdapter)
+		cmHandler := monitoring.NewConfigureMonitoringEventHandler(cmAdapter, dtClient, kClient, keptn.NewDefaultResourceClient(cfg), keptn.NewDefaultServiceClient(cfg), keptn.GetDefaultCachingProjectMetadataClient(cfg), cfg)
+		if !cmAdapter.IsTriggeredEvent() {
+			return cmHandler, nil
+		}
+		if cmAdapter.IsNotForDynatrace() {
+			return NoOpHandler{}, nil
+		}
+		if dynatraceConfig.Managed != nil {
+			cm, err := credentials.NewCredentialManager(nil, cfg)
+			if err != nil {
+				return NewTaskLifecycleHandler(cmAdapter, keptnv2.ConfigureMonitoringTaskName, kClient, failedTaskHandler{err: err}), nil
+			}
+
+			environments, err := getManagedEnvironments(dynatraceConfig, dynatraceCredentials, secretName, cm, event, cfg)
+			if err != nil {
+				log.WithError(err).Error("Could not get environments of Dynatrace Managed cluster")
+				return NewTaskLifecycleHandler(cmAdapter, keptnv2.ConfigureMonitoringTaskName, kClient, failedTaskHandler{err: err}), nil
+			}
+			cmHandler = cmHandler.WithManagedEnvironments(environments)
+		}
+		return NewTaskLifecycleHandler(cmAdapter, keptnv2.ConfigureMonitoringTaskName, kClient, cmHandler), nil
 	case *monitoring.ProjectCreateFinishedAdapter:
-		return monitoring.NewProjectCreateFinishedEventHandler(keptnEvent.(*monitoring.ProjectCreateFinishedAdapter), dtClient, kClient, keptn.NewDefaultResourceClient(), keptn.NewDefaultServiceClient()), nil
+		return monitoring.NewProjectCreateFinishedEventHandler(keptnEvent.(*monitoring.ProjectCreateFinishedAdapter), dtClient, kClient, keptn.NewDefaultResourceClient(cfg), keptn.NewDefaultServiceClient(cfg), cfg), nil
 	case *problem.ProblemAdapter:
-		return problem.NewProblemEventHandler(keptnEvent.(*problem.ProblemAdapter), kClient), nil
+		problemAdapter := keptnEvent.(*problem.ProblemAdapter)
+		router := problem.NewProblemRouter(keptn.NewDefaultResourceClient(cfg)).WithEntityTagsReader(problem.NewDynatraceEntityTagsReader(dtClient))
+		return newProblemRoutingHandler(problemAdapter, router, newEventFilter(cfg), problem.NewProblemEventHandler(problemAdapter, kClient, keptn.NewDefaultResourceClient(cfg), cfg)), nil
 	case *problem.ActionTriggeredAdapter:
-		return problem.NewActionTriggeredEventHandler(keptnEvent.(*problem.ActionTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return problem.NewActionTriggeredEventHandler(keptnEvent.(*problem.ActionTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
 	case *problem.ActionStartedAdapter:
-		return problem.NewActionStartedEventHandler(keptnEvent.(*problem.ActionStartedAdapter), dtClient, keptn.NewDefaultEventClient()), nil
+		return problem.NewActionStartedEventHandler(keptnEvent.(*problem.ActionStartedAdapter), dtClient, keptn.NewDefaultEventClient(cfg)), nil
 	case *problem.ActionFinishedAdapter:
-		return problem.NewActionFinishedEventHandler(keptnEvent.(*problem.ActionFinishedAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return problem.NewActionFinishedEventHandler(keptnEvent.(*problem.ActionFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
+	case *problem.RemediationFinishedAdapter:
+		return problem.NewRemediationFinishedEventHandler(keptnEvent.(*problem.RemediationFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), cfg), nil
 	case *sli.GetSLITriggeredAdapter:
-		return sli.NewGetSLITriggeredHandler(keptnEvent.(*sli.GetSLITriggeredAdapter), dtClient, kClient, keptn.NewDefaultResourceClient(), secretName, dynatraceConfig.Dashboard), nil
+		sliAdapter := keptnEvent.(*sli.GetSLITriggeredAdapter)
+		// the SLI configuration is read as of the start of the sequence, so that changes made in the meantime do not affect the evaluation
+		commitID := sliAdapter.GetGitCommitID()
+		sliHandler := sli.NewGetSLITriggeredHandler(sliAdapter, dtClient, kClient.AtCommit(commitID), keptn.NewDefaultResourceClientAtCommit(cfg, commitID), secretName, dynatraceConfig.Dashboard, dynatraceConfig.StrictKeySLIs, cfg).
+			WithCache(sli.GetDefaultSLIResultCache(cfg)).
+			WithDataCoverageCheck(dynatraceConfig.SLIDataCoverage).
+			WithSLIMerge(dynatraceConfig.SLIMerge)
+		if len(dynatraceConfig.AdditionalDtCreds) > 0 {
+			tenants, err := getAdditionalSLITenants(dynatraceConfig, event, cfg)
+			if err != nil {
+				log.WithError(err).Error("Could not get credentials of additional Dynatrace environments")
+				return NewTaskLifecycleHandler(sliAdapter, keptnv2.GetSLITaskName, kClient, failedTaskHandler{err: err}), nil
+			}
+			sliHandler = sliHandler.WithAdditionalTenants(tenants, dynatraceConfig.GetSLIAggregation())
+		}
+		return NewBackgroundHandler(NewTaskLifecycleHandler(sliAdapter, keptnv2.GetSLITaskName, kClient, sliHandler), event, GetDefaultDeadLetterQueue(cfg)), nil
+	case *sli.GenerateSLITriggeredAdapter:
+		generateSLIAdapter := keptnEvent.(*sli.GenerateSLITriggeredAdapter)
+		generateSLIHandler := sli.NewGenerateSLITaskHandler(generateSLIAdapter, dtClient, keptn.NewDefaultResourceClient(cfg), dynatraceConfig.Dashboard, cfg)
+		return NewBackgroundHandler(NewTaskLifecycleHandler(generateSLIAdapter, sli.GenerateSLITaskName, kClient, generateSLIHandler), event, GetDefaultDeadLetterQueue(cfg)), nil
 	case *deployment.DeploymentFinishedAdapter:
-		return deployment.NewDeploymentFinishedEventHandler(keptnEvent.(*deployment.DeploymentFinishedAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return deployment.NewDeploymentFinishedEventHandler(keptnEvent.(*deployment.DeploymentFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, deployment.GetDefaultDeploymentEventDeduplicator(cfg), cfg), nil
 	case *deployment.TestTriggeredAdapter:
-		return deployment.NewTestTriggeredEventHandler(keptnEvent.(*deployment.TestTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return deployment.NewTestTriggeredEventHandler(keptnEvent.(*deployment.TestTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(cfg), kClient, dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
 	case *deployment.TestFinishedAdapter:
-		return deployment.NewTestFinishedEventHandler(keptnEvent.(*deployment.TestFinishedAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return deployment.NewTestFinishedEventHandler(keptnEvent.(*deployment.TestFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), kClient, dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
 	case *deployment.EvaluationFinishedAdapter:
-		return deployment.NewEvaluationFinishedEventHandler(keptnEvent.(*deployment.EvaluationFinishedAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return deployment.NewEvaluationFinishedEventHandler(keptnEvent.(*deployment.EvaluationFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
+	case *deployment.SequenceFinishedAdapter:
+		return deployment.NewSequenceFinishedEventHandler(keptnEvent.(*deployment.SequenceFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
 	case *deployment.ReleaseTriggeredAdapter:
-		return deployment.NewReleaseTriggeredEventHandler(keptnEvent.(*deployment.ReleaseTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(), dynatraceConfig.AttachRules), nil
+		return deployment.NewReleaseTriggeredEventHandler(keptnEvent.(*deployment.ReleaseTriggeredAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
+	case *deployment.ReleaseFinishedAdapter:
+		return deployment.NewReleaseFinishedEventHandler(keptnEvent.(*deployment.ReleaseFinishedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
+	case *deployment.SequenceAbortedAdapter:
+		return deployment.NewSequenceAbortedEventHandler(keptnEvent.(*deployment.SequenceAbortedAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, deployment.GetDefaultDeploymentEventDeduplicator(cfg), cfg), nil
+	case *deployment.RollbackAdapter:
+		return deployment.NewRollbackEventHandler(keptnEvent.(*deployment.RollbackAdapter), dtClient, keptn.NewDefaultEventClient(cfg), dynatraceConfig.AttachRules, dynatraceConfig.EventTypes, cfg), nil
 	default:
 		return ErrorHandler{err: fmt.Errorf("this should not have happened, we are missing an implementation for: %T", aType)}, nil
 	}
 }
 
-func getEventAdapter(e cloudevents.Event) (adapter.EventContentAdapter, error) {
+// getAdditionalSLITenants creates clients for the additional Dynatrace environments SLIs are queried from
+func getAdditionalSLITenants(dynatraceConfig *config.DynatraceConfigFile, event cloudevents.Event, cfg *env.Config) ([]sli.Tenant, error) {
+	cm, err := credentials.NewCredentialManager(nil, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := make([]sli.Tenant, 0, len(dynatraceConfig.AdditionalDtCreds))
+	for _, secretName := range dynatraceConfig.AdditionalDtCreds {
+		creds, err := cm.GetDynatraceCredentials(secretName)
+		if err != nil {
+			return nil, fmt.Errorf("could not get Dynatrace credentials %s: %w", secretName, err)
+		}
+
+		dtClient, err := dynatrace.NewClientWithTLSOptions(creds, dynatraceConfig.TLS, cfg)
+		if err != nil {
+			return nil, err
+		}
+		dtClient.SetParentSpan(tracing.FromEvent(event))
+
+		tenants = append(tenants, sli.Tenant{Name: secretName, Client: dtClient})
+	}
+	return tenants, nil
+}
+
+// getManagedEnvironments returns clients for the environments of the Dynatrace Managed cluster selected in the dynatrace.conf.yaml.
+// The environments are discovered using the cluster credentials. Each environment is accessed using the API token of the credentials configured
+// for it or, if there are none, of the credentials of secretName, i.e. the Dynatrace credentials of the event
+func getManagedEnvironments(dynatraceConfig *config.DynatraceConfigFile, dynatraceCredentials *credentials.DTCredentials, secretName string, cm credentials.CredentialManagerInterface, event cloudevents.Event, cfg *env.Config) ([]monitoring.ManagedEnvironment, error) {
+	if dynatraceConfig.Managed.ClusterCreds == "" {
+		return nil, errors.New("managed.clusterCreds is not set in dynatrace.conf.yaml, the environments of a Dynatrace Managed cluster can only be discovered using credentials with the cluster URL and a cluster API token")
+	}
+
+	clusterCredentials, err := cm.GetDynatraceCredentials(dynatraceConfig.Managed.ClusterCreds)
+	if err != nil {
+		return nil, fmt.Errorf("could not get Dynatrace cluster credentials %s: %w", dynatraceConfig.Managed.ClusterCreds, err)
+	}
+
+	clusterClient, err := dynatrace.NewClientWithTLSOptions(clusterCredentials, dynatraceConfig.TLS, cfg)
+	if err != nil {
+		return nil, err
+	}
+	clusterClient.SetParentSpan(tracing.FromEvent(event))
+
+	clusterEnvironments, err := dynatrace.NewClusterAPIClient(clusterClient).SelectEnvironments(dynatraceConfig.Managed.Environments)
+	if err != nil {
+		return nil, fmt.Errorf("could not get environments of Dynatrace Managed cluster %s: %w", clusterCredentials.Tenant, err)
+	}
+
+	environments := make([]monitoring.ManagedEnvironment, 0, len(clusterEnvironments))
+	for _, clusterEnvironment := range clusterEnvironments {
+		environmentURL := dynatrace.GetEnvironmentURL(clusterCredentials.Tenant, clusterEnvironment.ID)
+
+		// the environment of the Dynatrace credentials is already configured by the configure-monitoring handler
+		if environmentURL == strings.TrimSuffix(dynatraceCredentials.Tenant, "/") {
+			continue
+		}
+
+		name := clusterEnvironment.Name
+		if name == "" {
+			name = clusterEnvironment.ID
+		}
+
+		environmentCredentials, err := getManagedEnvironmentCredentials(dynatraceConfig.Managed, clusterEnvironment, secretName, cm)
+		if err != nil {
+			return nil, fmt.Errorf("could not get credentials of Dynatrace environment %s: %w", name, err)
+		}
+
+		dtClient, err := dynatrace.NewClientWithTLSOptions(
+			&credentials.DTCredentials{
+				Tenant:            environmentURL,
+				ApiToken:          environmentCredentials.ApiToken,
+				SecondaryApiToken: environmentCredentials.SecondaryApiToken,
+			},
+			dynatraceConfig.TLS,
+			cfg)
+		if err != nil {
+			return nil, err
+		}
+		dtClient.SetParentSpan(tracing.FromEvent(event))
+
+		environments = append(environments, monitoring.ManagedEnvironment{Name: name, Client: dtClient})
+	}
+	return environments, nil
+}
+
+// getManagedEnvironmentCredentials returns the credentials configured for the environment by its ID or name, falling back to the credentials of secretName
+func getManagedEnvironmentCredentials(managedConfig *config.ManagedConfig, environment dynatrace.ClusterEnvironment, secretName string, cm credentials.CredentialManagerInterface) (*credentials.DTCredentials, error) {
+	environmentSecretName, ok := managedConfig.EnvironmentCreds[environment.ID]
+	if !ok {
+		environmentSecretName = managedConfig.EnvironmentCreds[environment.Name]
+	}
+
+	return credentials.NewCredentialManagerFallbackDecorator(cm, []string{secretName}).GetDynatraceCredentials(environmentSecretName)
+}
+
+// projectMetadataInvalidator removes the cached metadata of a project
+type projectMetadataInvalidator interface {
+	InvalidateProject(project string)
+}
+
+// invalidateProjectMetadata removes the cached shipyard and existence of a project once it was created or deleted, so that following events do not use outdated ones
+func invalidateProjectMetadata(event cloudevents.Event, invalidator projectMetadataInvalidator) {
+	switch event.Type() {
+	case keptnv2.GetFinishedEventType(keptnv2.ProjectCreateTaskName), keptnv2.GetFinishedEventType(keptnv2.ProjectDeleteTaskName):
+	default:
+		return
+	}
+
+	eventData := &keptnv2.EventData{}
+	err := event.DataAs(eventData)
+	if err != nil || eventData.Project == "" {
+		log.WithError(err).WithField("eventType", event.Type()).Warn("Could not get project of event to invalidate its cached metadata")
+		return
+	}
+
+	invalidator.InvalidateProject(eventData.Project)
+}
+
+// getDynatraceConfigGetter returns the getter for the dynatrace.conf.yaml. For get-sli events referring to a git commit, the dynatrace.conf.yaml
+// is read as of that commit, bypassing the cache which may contain a more recent version
+func getDynatraceConfigGetter(keptnEvent adapter.EventContentAdapter, cfg *env.Config) *config.DynatraceConfigGetter {
+	if sliAdapter, ok := keptnEvent.(*sli.GetSLITriggeredAdapter); ok && sliAdapter.GetGitCommitID() != "" {
+		return config.NewDynatraceConfigGetter(keptn.NewDefaultResourceClientAtCommit(cfg, sliAdapter.GetGitCommitID()))
+	}
+
+	return config.NewDynatraceConfigGetter(keptn.GetDefaultCachingDynatraceConfigResourceClient(cfg))
+}
+
+// getTaskEventAdapter returns the adapter and name of the task if the event triggers a task the dynatrace-service is responsible for
+func getTaskEventAdapter(keptnEvent adapter.EventContentAdapter) (TaskEventAdapter, string, bool) {
+	switch taskEvent := keptnEvent.(type) {
+	case *sli.GetSLITriggeredAdapter:
+		if !taskEvent.IsNotForDynatrace() {
+			return taskEvent, keptnv2.GetSLITaskName, true
+		}
+	case *monitoring.ConfigureMonitoringAdapter:
+		if taskEvent.IsTriggeredEvent() && !taskEvent.IsNotForDynatrace() {
+			return taskEvent, keptnv2.ConfigureMonitoringTaskName, true
+		}
+	case *sli.GenerateSLITriggeredAdapter:
+		return taskEvent, sli.GenerateSLITaskName, true
+	}
+
+	return nil, "", false
+}
+
+func getEventAdapter(e cloudevents.Event, cfg *env.Config) (adapter.EventContentAdapter, error) {
 	switch e.Type() {
-	case keptnevents.ConfigureMonitoringEventType:
+	case keptnevents.ConfigureMonitoringEventType, keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName):
 		keptnEvent, err := monitoring.NewConfigureMonitoringAdapterFromEvent(e)
 		if err != nil {
 			return nil, err
@@ -139,63 +393,113 @@ func getEventAdapter(e cloudevents.Event) (adapter.EventContentAdapter, error) {
 		}
 		return keptnEvent, nil
 	case keptnv2.GetTriggeredEventType(keptnv2.ActionTaskName):
-		keptnEvent, err := problem.NewActionTriggeredAdapterFromEvent(e)
+		keptnEvent, err := problem.NewActionTriggeredAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetStartedEventType(keptnv2.ActionTaskName):
-		keptnEvent, err := problem.NewActionStartedAdapterFromEvent(e)
+		keptnEvent, err := problem.NewActionStartedAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetFinishedEventType(keptnv2.ActionTaskName):
-		keptnEvent, err := problem.NewActionFinishedAdapterFromEvent(e)
+		keptnEvent, err := problem.NewActionFinishedAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetTriggeredEventType(keptnv2.GetSLITaskName):
-		keptnEvent, err := sli.NewGetSLITriggeredAdapterFromEvent(e)
+		keptnEvent, err := sli.NewGetSLITriggeredAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetFinishedEventType(keptnv2.DeploymentTaskName):
-		keptnEvent, err := deployment.NewDeploymentFinishedAdapterFromEvent(e)
+		keptnEvent, err := deployment.NewDeploymentFinishedAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetTriggeredEventType(keptnv2.TestTaskName):
-		keptnEvent, err := deployment.NewTestTriggeredAdapterFromEvent(e)
+		keptnEvent, err := deployment.NewTestTriggeredAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetFinishedEventType(keptnv2.TestTaskName):
-		keptnEvent, err := deployment.NewTestFinishedAdapterFromEvent(e)
+		keptnEvent, err := deployment.NewTestFinishedAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetFinishedEventType(keptnv2.EvaluationTaskName):
-		keptnEvent, err := deployment.NewEvaluationFinishedAdapterFromEvent(e)
+		keptnEvent, err := deployment.NewEvaluationFinishedAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetTriggeredEventType(keptnv2.ReleaseTaskName):
-		keptnEvent, err := deployment.NewReleaseTriggeredAdapterFromEvent(e)
+		keptnEvent, err := deployment.NewReleaseTriggeredAdapterFromEvent(e, cfg)
 		if err != nil {
 			return nil, err
 		}
 		return keptnEvent, nil
 	case keptnv2.GetFinishedEventType(keptnv2.ReleaseTaskName):
-		//do nothing, ignore the type, don't even log
-		return nil, nil
+		keptnEvent, err := deployment.NewReleaseFinishedAdapterFromEvent(e, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return keptnEvent, nil
+	case keptnv2.GetTriggeredEventType(keptnv2.RollbackTaskName), keptnv2.GetFinishedEventType(keptnv2.RollbackTaskName):
+		keptnEvent, err := deployment.NewRollbackAdapterFromEvent(e, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return keptnEvent, nil
 	default:
+		if problem.IsRemediationFinishedEventType(e.Type()) {
+			keptnEvent, err := problem.NewRemediationFinishedAdapterFromEvent(e, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return keptnEvent, nil
+		}
+
+		if sli.IsGenerateSLITriggeredEventType(e.Type()) {
+			keptnEvent, err := sli.NewGenerateSLITriggeredAdapterFromEvent(e)
+			if err != nil {
+				return nil, err
+			}
+			return keptnEvent, nil
+		}
+
+		if deployment.IsSequenceAbortedEventType(e.Type()) {
+			keptnEvent, err := deployment.NewSequenceAbortedAdapterFromEvent(e, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return keptnEvent, nil
+		}
+
+		if diagnostics.IsDiagnoseTriggeredEventType(e.Type()) {
+			keptnEvent, err := diagnostics.NewDiagnoseTriggeredAdapterFromEvent(e)
+			if err != nil {
+				return nil, err
+			}
+			return keptnEvent, nil
+		}
+
+		// other sequences are only of interest if failure events should be sent to Dynatrace
+		if deployment.IsSequenceFinishedEventType(e.Type()) && cfg.FailureEventsEnabled {
+			keptnEvent, err := deployment.NewSequenceFinishedAdapterFromEvent(e, cfg)
+			if err != nil {
+				return nil, err
+			}
+			return keptnEvent, nil
+		}
+
 		log.WithField("EventType", e.Type()).Debug("Ignoring event")
 		return nil, nil
 	}
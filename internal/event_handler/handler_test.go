@@ -0,0 +1,199 @@
+package event_handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	credentials_mock "github.com/keptn-contrib/dynatrace-service/internal/credentials/mock"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+const testClusterEnvironments = `{"environments": [
+	{"id": "env-dev", "name": "dev", "state": "ENABLED"},
+	{"id": "env-preprod", "name": "preprod", "state": "ENABLED"},
+	{"id": "env-prod", "name": "prod", "state": "ENABLED"},
+	{"id": "env-old", "name": "old", "state": "DISABLED"}
+]}`
+
+// newTestCluster returns the URL of a Dynatrace Managed cluster listing testClusterEnvironments to requests with the cluster API token
+func newTestCluster(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/cluster/v2/environments", r.URL.Path)
+		if r.Header.Get("Authorization") != "Api-Token cluster-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error": {"code": 401, "message": "Token Authentication failed"}}`))
+			return
+		}
+		w.Write([]byte(testClusterEnvironments))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func newTestCredentialManager(secrets map[string]*credentials.DTCredentials) *credentials_mock.CredentialManagerInterfaceMock {
+	return &credentials_mock.CredentialManagerInterfaceMock{
+		GetDynatraceCredentialsFunc: func(secretName string) (*credentials.DTCredentials, error) {
+			creds, ok := secrets[secretName]
+			if !ok {
+				return nil, errors.New("secret not found")
+			}
+			return creds, nil
+		},
+	}
+}
+
+func TestGetManagedEnvironments(t *testing.T) {
+	clusterURL := newTestCluster(t)
+	dynatraceCredentials := &credentials.DTCredentials{Tenant: clusterURL + "/e/env-dev", ApiToken: "dev-token"}
+	cm := newTestCredentialManager(map[string]*credentials.DTCredentials{
+		"dynatrace":         dynatraceCredentials,
+		"dynatrace-cluster": {Tenant: clusterURL, ApiToken: "cluster-token"},
+		"dynatrace-prod":    {Tenant: clusterURL + "/e/env-prod", ApiToken: "prod-token", SecondaryApiToken: "prod-token-2"},
+	})
+
+	tests := []struct {
+		name             string
+		managed          *config.ManagedConfig
+		wantEnvironments map[string]credentials.DTCredentials
+	}{
+		{
+			name:    "all enabled environments besides the one of the Dynatrace credentials",
+			managed: &config.ManagedConfig{ClusterCreds: "dynatrace-cluster", EnvironmentCreds: map[string]string{"env-prod": "dynatrace-prod"}},
+			wantEnvironments: map[string]credentials.DTCredentials{
+				"preprod": {Tenant: clusterURL + "/e/env-preprod", ApiToken: "dev-token"},
+				"prod":    {Tenant: clusterURL + "/e/env-prod", ApiToken: "prod-token", SecondaryApiToken: "prod-token-2"},
+			},
+		},
+		{
+			name:    "selected environments with credentials by name",
+			managed: &config.ManagedConfig{ClusterCreds: "dynatrace-cluster", Environments: []string{"prod", "env-old"}, EnvironmentCreds: map[string]string{"prod": "dynatrace-prod"}},
+			wantEnvironments: map[string]credentials.DTCredentials{
+				"prod": {Tenant: clusterURL + "/e/env-prod", ApiToken: "prod-token", SecondaryApiToken: "prod-token-2"},
+				"old":  {Tenant: clusterURL + "/e/env-old", ApiToken: "dev-token"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			environments, err := getManagedEnvironments(&config.DynatraceConfigFile{Managed: tt.managed}, dynatraceCredentials, "dynatrace", cm, cloudevents.NewEvent(), env.ReadConfig())
+
+			assert.NoError(t, err)
+			gotEnvironments := map[string]credentials.DTCredentials{}
+			for _, environment := range environments {
+				gotEnvironments[environment.Name] = *environment.Client.Credentials()
+			}
+			assert.Equal(t, tt.wantEnvironments, gotEnvironments)
+		})
+	}
+}
+
+func TestGetManagedEnvironments_Errors(t *testing.T) {
+	clusterURL := newTestCluster(t)
+	dynatraceCredentials := &credentials.DTCredentials{Tenant: clusterURL + "/e/env-dev", ApiToken: "dev-token"}
+
+	tests := []struct {
+		name    string
+		managed *config.ManagedConfig
+		secrets map[string]*credentials.DTCredentials
+		wantErr string
+	}{
+		{
+			name:    "no cluster credentials",
+			managed: &config.ManagedConfig{Environments: []string{"prod"}},
+			secrets: map[string]*credentials.DTCredentials{"dynatrace": dynatraceCredentials},
+			wantErr: "managed.clusterCreds is not set",
+		},
+		{
+			name:    "missing cluster credentials",
+			managed: &config.ManagedConfig{ClusterCreds: "dynatrace-cluster"},
+			secrets: map[string]*credentials.DTCredentials{"dynatrace": dynatraceCredentials},
+			wantErr: "could not get Dynatrace cluster credentials dynatrace-cluster",
+		},
+		{
+			name:    "environment token used as cluster token",
+			managed: &config.ManagedConfig{ClusterCreds: "dynatrace-cluster"},
+			secrets: map[string]*credentials.DTCredentials{"dynatrace": dynatraceCredentials, "dynatrace-cluster": {Tenant: clusterURL, ApiToken: "dev-token"}},
+			wantErr: "could not get environments of Dynatrace Managed cluster",
+		},
+		{
+			name:    "unknown environment",
+			managed: &config.ManagedConfig{ClusterCreds: "dynatrace-cluster", Environments: []string{"staging"}},
+			secrets: map[string]*credentials.DTCredentials{"dynatrace": dynatraceCredentials, "dynatrace-cluster": {Tenant: clusterURL, ApiToken: "cluster-token"}},
+			wantErr: "no environment with ID or name staging",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getManagedEnvironments(&config.DynatraceConfigFile{Managed: tt.managed}, dynatraceCredentials, "dynatrace", newTestCredentialManager(tt.secrets), cloudevents.NewEvent(), env.ReadConfig())
+
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+type projectMetadataInvalidatorMock struct {
+	invalidatedProjects []string
+}
+
+func (m *projectMetadataInvalidatorMock) InvalidateProject(project string) {
+	m.invalidatedProjects = append(m.invalidatedProjects, project)
+}
+
+func TestInvalidateProjectMetadata(t *testing.T) {
+	tests := []struct {
+		name                    string
+		eventType               string
+		data                    interface{}
+		wantInvalidatedProjects []string
+	}{
+		{
+			name:                    "project created",
+			eventType:               keptnv2.GetFinishedEventType(keptnv2.ProjectCreateTaskName),
+			data:                    keptnv2.EventData{Project: "sockshop"},
+			wantInvalidatedProjects: []string{"sockshop"},
+		},
+		{
+			name:                    "project deleted",
+			eventType:               keptnv2.GetFinishedEventType(keptnv2.ProjectDeleteTaskName),
+			data:                    keptnv2.EventData{Project: "sockshop"},
+			wantInvalidatedProjects: []string{"sockshop"},
+		},
+		{
+			name:      "other event of the project",
+			eventType: keptnv2.GetTriggeredEventType(keptnv2.GetSLITaskName),
+			data:      keptnv2.EventData{Project: "sockshop"},
+		},
+		{
+			name:      "project created without project",
+			eventType: keptnv2.GetFinishedEventType(keptnv2.ProjectCreateTaskName),
+			data:      keptnv2.EventData{},
+		},
+		{
+			name:      "project deleted with invalid data",
+			eventType: keptnv2.GetFinishedEventType(keptnv2.ProjectDeleteTaskName),
+			data:      []string{"sockshop"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := cloudevents.NewEvent()
+			event.SetType(tt.eventType)
+			assert.NoError(t, event.SetData(cloudevents.ApplicationJSON, tt.data))
+
+			invalidator := &projectMetadataInvalidatorMock{}
+			invalidateProjectMetadata(event, invalidator)
+
+			assert.Equal(t, tt.wantInvalidatedProjects, invalidator.invalidatedProjects)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package event_handler
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/problem"
+	log "github.com/sirupsen/logrus"
+)
+
+// problemRoutingHandler applies the problem routing rules before handling a problem, so that the resources holding the rules are only read
+// when the event is handled. As routing may change the project, stage and service of the problem, the event filter is applied again afterwards
+type problemRoutingHandler struct {
+	event   *problem.ProblemAdapter
+	router  *problem.ProblemRouter
+	filter  eventFilter
+	handler DynatraceEventHandler
+}
+
+func newProblemRoutingHandler(event *problem.ProblemAdapter, router *problem.ProblemRouter, filter eventFilter, handler DynatraceEventHandler) problemRoutingHandler {
+	return problemRoutingHandler{
+		event:   event,
+		router:  router,
+		filter:  filter,
+		handler: handler,
+	}
+}
+
+// HandleEvent routes the problem and handles it unless it was routed to a project, stage or service excluded by the event filter
+func (h problemRoutingHandler) HandleEvent() error {
+	if !h.event.IsNotFromDynatrace() {
+		h.router.Route(h.event)
+	}
+
+	if !h.filter.isAllowed(h.event) {
+		log.WithFields(log.Fields{"project": h.event.GetProject(), "stage": h.event.GetStage(), "service": h.event.GetService()}).Debug("Ignoring routed problem excluded by event filter")
+		return nil
+	}
+
+	return h.handler.HandleEvent()
+}
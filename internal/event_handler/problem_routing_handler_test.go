@@ -0,0 +1,78 @@
+package event_handler
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/problem"
+	keptnevents "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProblemRouting = `spec_version: '0.1.0'
+default:
+  project: sockshop
+  stage: production
+  service: carts
+`
+
+type problemRoutingReaderMock struct {
+	calls int
+}
+
+func (m *problemRoutingReaderMock) GetProblemRouting(project string) (string, error) {
+	m.calls++
+	return testProblemRouting, nil
+}
+
+type eventHandlerMock struct {
+	calls int
+}
+
+func (h *eventHandlerMock) HandleEvent() error {
+	h.calls++
+	return nil
+}
+
+func createUntaggedProblemAdapter(t *testing.T) *problem.ProblemAdapter {
+	event := cloudevents.NewEvent()
+	event.SetID("f2b878d3-03c0-4e8f-bc3f-454bc1b3d79d")
+	event.SetSource("dynatrace")
+	event.SetType(keptnevents.ProblemEventType)
+	err := event.SetData(cloudevents.ApplicationJSON, map[string]string{"PID": "-123", "State": "OPEN", "ProblemTitle": "CPU saturation"})
+	assert.NoError(t, err)
+
+	problemAdapter, err := problem.NewProblemAdapterFromEvent(event)
+	assert.NoError(t, err)
+	return problemAdapter
+}
+
+func TestProblemRoutingHandler_HandleEvent(t *testing.T) {
+	t.Run("routes the problem when handling it", func(t *testing.T) {
+		reader := &problemRoutingReaderMock{}
+		handler := &eventHandlerMock{}
+		problemAdapter := createUntaggedProblemAdapter(t)
+
+		routingHandler := newProblemRoutingHandler(problemAdapter, problem.NewProblemRouter(reader), eventFilter{}, handler)
+		assert.Equal(t, 0, reader.calls)
+
+		err := routingHandler.HandleEvent()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, reader.calls)
+		assert.Equal(t, 1, handler.calls)
+		assert.Equal(t, "sockshop", problemAdapter.GetProject())
+		assert.Equal(t, "production", problemAdapter.GetStage())
+		assert.Equal(t, "carts", problemAdapter.GetService())
+	})
+
+	t.Run("ignores problems routed to excluded projects", func(t *testing.T) {
+		handler := &eventHandlerMock{}
+		filter := eventFilter{projects: filterList{denied: []string{"sockshop"}}}
+
+		err := newProblemRoutingHandler(createUntaggedProblemAdapter(t), problem.NewProblemRouter(&problemRoutingReaderMock{}), filter, handler).HandleEvent()
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, handler.calls)
+	})
+}
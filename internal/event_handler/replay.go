@@ -0,0 +1,59 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"fmt"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+// ParseCapturedEvent parses a previously captured cloud event, which is either the JSON representation of the event itself,
+// e.g. as written when running locally, or a DeadLetter containing it, e.g. as stored by the ResourceDeadLetterSink
+func ParseCapturedEvent(data []byte) (cloudevents.Event, error) {
+	deadLetter := DeadLetter{}
+	if err := json.Unmarshal(data, &deadLetter); err == nil && deadLetter.Event.ID() != "" {
+		return deadLetter.Event, nil
+	}
+
+	event := cloudevents.NewEvent()
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("could not parse captured event: %w", err)
+	}
+
+	return event, nil
+}
+
+// ReplayEvent processes the event once, exactly as if it was received, but without retrying or dead-lettering it if processing fails.
+// Unlike for received events, the event is processed before ReplayEvent returns and an error is also returned if a task failed
+func ReplayEvent(event cloudevents.Event, cfg *env.Config) error {
+	logger := log.WithFields(log.Fields{"eventID": event.ID(), "eventType": event.Type()})
+	logger.Info("Replaying event")
+
+	handler, err := NewEventHandler(event, cfg)
+	if err != nil {
+		return err
+	}
+
+	err = newReplayHandler(handler).HandleEvent()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Replayed event")
+	return nil
+}
+
+// newReplayHandler returns the handler of a replayed event: handlers running in the background are run synchronously
+// and handlers of tasks return the errors of the tasks
+func newReplayHandler(handler DynatraceEventHandler) DynatraceEventHandler {
+	if backgroundHandler, ok := handler.(BackgroundHandler); ok {
+		handler = backgroundHandler.handler
+	}
+
+	if lifecycleHandler, ok := handler.(*TaskLifecycleHandler); ok {
+		return lifecycleHandler.WithTaskErrorsReturned()
+	}
+	return handler
+}
@@ -0,0 +1,89 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCapturedEvent(t *testing.T) {
+	event := createDeadLetterTestEvent("event-1")
+	eventJSON, err := json.Marshal(event)
+	assert.NoError(t, err)
+
+	deadLetterJSON, err := json.Marshal(DeadLetter{EventID: "event-1", EventType: event.Type(), Attempts: 3, Error: "processing failed", Event: event})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		data          []byte
+		expectedError string
+	}{
+		{
+			name: "cloud event",
+			data: eventJSON,
+		},
+		{
+			name: "dead letter",
+			data: deadLetterJSON,
+		},
+		{
+			name:          "no JSON",
+			data:          []byte("sh.keptn.event.get-sli.triggered"),
+			expectedError: "could not parse captured event",
+		},
+		{
+			name:          "no cloud event",
+			data:          []byte(`{"project": "sockshop"}`),
+			expectedError: "could not parse captured event",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsedEvent, err := ParseCapturedEvent(tt.data)
+			if tt.expectedError != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedError)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "event-1", parsedEvent.ID())
+			assert.Equal(t, "sh.keptn.event.get-sli.triggered", parsedEvent.Type())
+		})
+	}
+}
+
+// Tests that a failing get-sli task handled in the background is replayed synchronously and its error is returned
+func TestNewReplayHandler_ReturnsErrorOfFailedGetSLITask(t *testing.T) {
+	event := &taskEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Project: "sockshop",
+			Stage:   "staging",
+			Service: "carts",
+		},
+	}
+	kClient := &keptnClientMock{}
+	handler := NewBackgroundHandler(
+		NewTaskLifecycleHandler(event, keptnv2.GetSLITaskName, kClient, failedTaskHandler{err: errors.New("could not query Dynatrace")}),
+		cloudevents.NewEvent(),
+		nil)
+
+	err := newReplayHandler(handler).HandleEvent()
+
+	if assert.EqualError(t, err, "could not query Dynatrace") && assert.Len(t, kClient.eventSink, 2) {
+		assert.Equal(t, keptnv2.GetStartedEventType(keptnv2.GetSLITaskName), kClient.eventSink[0].Type())
+		assert.Equal(t, keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), kClient.eventSink[1].Type())
+
+		data := keptnv2.EventData{}
+		assert.NoError(t, kClient.eventSink[1].DataAs(&data))
+		assert.Equal(t, keptnv2.ResultFailed, data.Result)
+	}
+}
@@ -0,0 +1,165 @@
+package event_handler
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TaskHandler is implemented by handlers of triggered events for tasks the dynatrace-service is responsible for
+type TaskHandler interface {
+	// HandleTask executes the task and returns the factory for the corresponding finished event
+	HandleTask() (adapter.CloudEventFactoryInterface, error)
+}
+
+// TaskEventAdapter is the adapter of a triggered event for a task
+type TaskEventAdapter interface {
+	adapter.EventContentAdapter
+	adapter.TriggeredCloudEventContentAdapter
+}
+
+// TaskLifecycleHandler sends a started event, executes the task and sends a finished event as required by the Keptn spec.
+// If the task returns an error, a finished event with status and result depending on the type of the error is sent instead
+type TaskLifecycleHandler struct {
+	event    TaskEventAdapter
+	taskName string
+	kClient  keptn.ClientInterface
+	handler  TaskHandler
+
+	// returnTaskErrors makes HandleEvent return the error of the task after sending the finished event, e.g. when replaying the event
+	returnTaskErrors bool
+}
+
+// NewTaskLifecycleHandler creates a new TaskLifecycleHandler
+func NewTaskLifecycleHandler(event TaskEventAdapter, taskName string, kClient keptn.ClientInterface, handler TaskHandler) *TaskLifecycleHandler {
+	return &TaskLifecycleHandler{
+		event:    event,
+		taskName: taskName,
+		kClient:  kClient,
+		handler:  handler,
+	}
+}
+
+// WithTaskErrorsReturned makes HandleEvent return the error of the task after the finished event was sent
+func (h *TaskLifecycleHandler) WithTaskErrorsReturned() *TaskLifecycleHandler {
+	h.returnTaskErrors = true
+	return h
+}
+
+// HandleEvent handles the triggered event of the task
+func (h *TaskLifecycleHandler) HandleEvent() error {
+	err := h.kClient.SendCloudEvent(newTaskStartedEventFactory(h.event, h.taskName))
+	if err != nil {
+		log.WithError(err).WithField("task", h.taskName).Error("Failed to send started event")
+		return err
+	}
+
+	finishedEventFactory, taskErr := h.handler.HandleTask()
+	if taskErr != nil {
+		log.WithError(taskErr).WithField("task", h.taskName).Error("Task failed")
+		finishedEventFactory = newTaskFailedEventFactory(h.event, h.taskName, taskErr)
+	}
+
+	err = h.kClient.SendCloudEvent(finishedEventFactory)
+	if err != nil {
+		log.WithError(err).WithField("task", h.taskName).Error("Failed to send finished event")
+		return err
+	}
+
+	if h.returnTaskErrors {
+		return taskErr
+	}
+	return nil
+}
+
+// BackgroundHandler handles an event in a separate goroutine, so that the event can be acknowledged right away
+type BackgroundHandler struct {
+	handler         DynatraceEventHandler
+	event           cloudevents.Event
+	deadLetterQueue *DeadLetterQueue
+}
+
+// NewBackgroundHandler creates a new BackgroundHandler. The handling in the background is traced as child of the span carried by the event.
+// As the result cannot be reported back for the event, failed handling is retried and dead-lettered by the deadLetterQueue
+func NewBackgroundHandler(handler DynatraceEventHandler, event cloudevents.Event, deadLetterQueue *DeadLetterQueue) BackgroundHandler {
+	return BackgroundHandler{
+		handler:         handler,
+		event:           event,
+		deadLetterQueue: deadLetterQueue,
+	}
+}
+
+// HandleEvent starts handling the event and returns immediately
+func (h BackgroundHandler) HandleEvent() error {
+	go h.deadLetterQueue.Handle(h.event, func() error {
+		span := tracing.StartSpan("handle in background", tracing.FromEvent(h.event), trace.SpanKindInternal)
+		err := h.handler.HandleEvent()
+		tracing.EndSpan(span, err)
+		if err != nil {
+			selfmonitoring.RecordHandlerError(h.event.Type(), err)
+			log.WithError(err).Error("HandleEvent() returned an error")
+		}
+		return err
+	})
+
+	return nil
+}
+
+type taskStartedEventFactory struct {
+	event    TaskEventAdapter
+	taskName string
+}
+
+func newTaskStartedEventFactory(event TaskEventAdapter, taskName string) *taskStartedEventFactory {
+	return &taskStartedEventFactory{
+		event:    event,
+		taskName: taskName,
+	}
+}
+
+func (f *taskStartedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	startedEvent := keptnv2.EventData{
+		Project: f.event.GetProject(),
+		Stage:   f.event.GetStage(),
+		Service: f.event.GetService(),
+		Labels:  f.event.GetLabels(),
+		Status:  keptnv2.StatusSucceeded,
+	}
+
+	return adapter.NewCloudEventFactory(f.event, keptnv2.GetStartedEventType(f.taskName), startedEvent).CreateCloudEvent()
+}
+
+type taskFailedEventFactory struct {
+	event    TaskEventAdapter
+	taskName string
+	err      error
+}
+
+func newTaskFailedEventFactory(event TaskEventAdapter, taskName string, err error) *taskFailedEventFactory {
+	return &taskFailedEventFactory{
+		event:    event,
+		taskName: taskName,
+		err:      err,
+	}
+}
+
+func (f *taskFailedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	status, result := common.GetFinishedEventStatusAndResult(f.err)
+	finishedEvent := keptnv2.EventData{
+		Project: f.event.GetProject(),
+		Stage:   f.event.GetStage(),
+		Service: f.event.GetService(),
+		Labels:  f.event.GetLabels(),
+		Status:  status,
+		Result:  result,
+		Message: common.GetFinishedEventMessage(f.err),
+	}
+
+	return adapter.NewCloudEventFactory(f.event, keptnv2.GetFinishedEventType(f.taskName), finishedEvent).CreateCloudEvent()
+}
@@ -0,0 +1,159 @@
+package event_handler
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTaskName = "test-task"
+
+type taskEventData struct {
+	test.EventData
+}
+
+func (e *taskEventData) GetEventID() string {
+	return "a3e5f16d-8888-4720-82c7-6995062905c1"
+}
+
+type keptnClientMock struct {
+	eventSink []*cloudevents.Event
+}
+
+func (m *keptnClientMock) GetCustomQueries(project string, stage string, service string) (*keptn.CustomQueries, error) {
+	panic("GetCustomQueries() should not be needed in this mock!")
+}
+
+func (m *keptnClientMock) SendCloudEvent(factory adapter.CloudEventFactoryInterface) error {
+	ce, err := factory.CreateCloudEvent()
+	if err != nil {
+		return err
+	}
+
+	m.eventSink = append(m.eventSink, ce)
+	return nil
+}
+
+type taskHandlerMock struct {
+	factory adapter.CloudEventFactoryInterface
+	err     error
+}
+
+func (h *taskHandlerMock) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	return h.factory, h.err
+}
+
+func TestTaskLifecycleHandler_HandleEvent(t *testing.T) {
+	event := &taskEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Project: "sockshop",
+			Stage:   "staging",
+			Service: "carts",
+		},
+	}
+
+	tests := []struct {
+		name        string
+		handler     *taskHandlerMock
+		wantStatus  keptnv2.StatusType
+		wantResult  keptnv2.ResultType
+		wantMessage string
+	}{
+		{
+			name: "task succeeds",
+			handler: &taskHandlerMock{
+				factory: adapter.NewCloudEventFactory(event, keptnv2.GetFinishedEventType(testTaskName), keptnv2.EventData{
+					Status:  keptnv2.StatusSucceeded,
+					Result:  keptnv2.ResultPass,
+					Message: "all good",
+				}),
+			},
+			wantStatus:  keptnv2.StatusSucceeded,
+			wantResult:  keptnv2.ResultPass,
+			wantMessage: "all good",
+		},
+		{
+			name: "task fails",
+			handler: &taskHandlerMock{
+				err: errors.New("something went wrong"),
+			},
+			wantStatus:  keptnv2.StatusErrored,
+			wantResult:  keptnv2.ResultFailed,
+			wantMessage: "something went wrong",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kClient := &keptnClientMock{}
+
+			err := NewTaskLifecycleHandler(event, testTaskName, kClient, tt.handler).HandleEvent()
+			assert.NoError(t, err)
+
+			if assert.Equal(t, 2, len(kClient.eventSink)) {
+				assert.Equal(t, keptnv2.GetStartedEventType(testTaskName), kClient.eventSink[0].Type())
+				assert.Equal(t, keptnv2.GetFinishedEventType(testTaskName), kClient.eventSink[1].Type())
+
+				for _, ce := range kClient.eventSink {
+					assert.Equal(t, "a3e5f16d-8888-4720-82c7-6995062905c1", ce.Extensions()["triggeredid"])
+				}
+
+				var data keptnv2.EventData
+				err = json.Unmarshal(kClient.eventSink[1].Data(), &data)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, data.Status)
+				assert.Equal(t, tt.wantResult, data.Result)
+				assert.Equal(t, tt.wantMessage, data.Message)
+			}
+		})
+	}
+}
+
+type failingEventHandler struct {
+	err error
+}
+
+func (h failingEventHandler) HandleEvent() error {
+	return h.err
+}
+
+// deadLetterNotifyingSink signals each dead letter written to it
+type deadLetterNotifyingSink struct {
+	deadLetters chan DeadLetter
+}
+
+func (s *deadLetterNotifyingSink) Write(deadLetter DeadLetter) error {
+	s.deadLetters <- deadLetter
+	return nil
+}
+
+// Tests that the error of an event handled in the background is passed to the dead-letter queue
+func TestBackgroundHandler_FailedHandlingIsDeadLettered(t *testing.T) {
+	sink := &deadLetterNotifyingSink{deadLetters: make(chan DeadLetter, 1)}
+	q := NewDeadLetterQueue(2, sink)
+	q.retryDelay = 0
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType(keptnv2.GetTriggeredEventType(keptnv2.GetSLITaskName))
+
+	err := NewBackgroundHandler(failingEventHandler{err: errors.New("could not send finished event")}, event, q).HandleEvent()
+	assert.NoError(t, err)
+
+	select {
+	case deadLetter := <-sink.deadLetters:
+		assert.Equal(t, "event-1", deadLetter.EventID)
+		assert.Equal(t, 2, deadLetter.Attempts)
+		assert.Equal(t, "could not send finished event", deadLetter.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("event was not dead-lettered")
+	}
+}
@@ -0,0 +1,127 @@
+package keptn
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+)
+
+// shipyardControllerAPIPath is the path of the shipyard-controller behind the Keptn API
+const shipyardControllerAPIPath = "controlPlane"
+
+// remoteControlPlane contains the connection details of the Keptn API if the dynatrace-service runs on a remote execution plane
+type remoteControlPlane struct {
+	endpoint string
+	token    string
+	scheme   string
+}
+
+// getRemoteControlPlane returns the connection details of the Keptn API of a remote control plane or false if the dynatrace-service
+// runs on the control plane and connects to the Keptn services directly
+func getRemoteControlPlane(cfg *env.Config) (*remoteControlPlane, bool) {
+	endpoint := cfg.KeptnAPIEndpoint
+	if endpoint == "" {
+		return nil, false
+	}
+
+	scheme := "https"
+	if u, err := url.Parse(endpoint); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+
+	return &remoteControlPlane{
+		endpoint: endpoint,
+		token:    cfg.KeptnAPIToken,
+		scheme:   scheme,
+	}, true
+}
+
+// newResourceHandler creates a ResourceHandler using the shared Keptn API client. As the constructors of all Keptn API handlers replace the
+// transport of the given http.Client, the shared client is set afterwards so that its TLS, proxy and retry options apply consistently
+func newResourceHandler(cfg *env.Config) *keptnapi.ResourceHandler {
+	var handler *keptnapi.ResourceHandler
+	if cp, ok := getRemoteControlPlane(cfg); ok {
+		handler = keptnapi.NewAuthenticatedResourceHandler(cp.endpoint, cp.token, keptnhttp.AuthHeader, nil, cp.scheme)
+	} else {
+		handler = keptnapi.NewResourceHandler(common.GetConfigurationServiceURL())
+	}
+	handler.HTTPClient = keptnhttp.GetDefaultHTTPClient(cfg)
+	return handler
+}
+
+func newEventHandler(cfg *env.Config) *keptnapi.EventHandler {
+	var handler *keptnapi.EventHandler
+	if cp, ok := getRemoteControlPlane(cfg); ok {
+		handler = keptnapi.NewAuthenticatedEventHandler(cp.endpoint, cp.token, keptnhttp.AuthHeader, nil, cp.scheme)
+	} else {
+		handler = keptnapi.NewEventHandler(common.GetDatastoreURL())
+	}
+	handler.HTTPClient = keptnhttp.GetDefaultHTTPClient(cfg)
+	return handler
+}
+
+func newProjectHandler(cfg *env.Config) *keptnapi.ProjectHandler {
+	var handler *keptnapi.ProjectHandler
+	if cp, ok := getRemoteControlPlane(cfg); ok {
+		handler = keptnapi.NewAuthenticatedProjectHandler(cp.endpoint, cp.token, keptnhttp.AuthHeader, nil, cp.scheme)
+	} else {
+		handler = keptnapi.NewProjectHandler(common.GetShipyardControllerURL())
+	}
+	handler.HTTPClient = keptnhttp.GetDefaultHTTPClient(cfg)
+	return handler
+}
+
+func newStageHandler(cfg *env.Config) *keptnapi.StageHandler {
+	var handler *keptnapi.StageHandler
+	if cp, ok := getRemoteControlPlane(cfg); ok {
+		handler = keptnapi.NewAuthenticatedStageHandler(cp.endpoint, cp.token, keptnhttp.AuthHeader, nil, cp.scheme)
+	} else {
+		handler = keptnapi.NewStageHandler(common.GetShipyardControllerURL())
+	}
+	handler.HTTPClient = keptnhttp.GetDefaultHTTPClient(cfg)
+	return handler
+}
+
+// getShipyardControllerURLAndToken returns the URL of the shipyard-controller and the token required to access it, which is empty on the control plane
+func getShipyardControllerURLAndToken(cfg *env.Config) (string, string) {
+	if cp, ok := getRemoteControlPlane(cfg); ok {
+		return strings.TrimRight(cp.endpoint, "/") + "/" + shipyardControllerAPIPath, cp.token
+	}
+	return common.GetShipyardControllerURL(), ""
+}
+
+// CheckResourceServiceConnection returns an error if the resource-service does not respond, e.g. while the Keptn control plane is still starting.
+// Any response except those of an unavailable upstream service counts, as the response to the request depends on the version of the service
+func CheckResourceServiceConnection(cfg *env.Config) error {
+	handler := newResourceHandler(cfg)
+	return checkServiceResponds(handler.HTTPClient, handler.Scheme+"://"+strings.TrimRight(handler.BaseURL, "/")+"/v1/project", handler.AuthHeader, handler.AuthToken)
+}
+
+func checkServiceResponds(client *http.Client, url string, authHeader string, authToken string) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("could not create request: %w", err)
+	}
+	if authToken != "" {
+		req.Header.Set(authHeader, authToken)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not connect to %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return fmt.Errorf("received unexpected response from %s: %d", url, resp.StatusCode)
+	default:
+		return nil
+	}
+}
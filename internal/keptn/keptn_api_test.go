@@ -0,0 +1,95 @@
+package keptn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_getShipyardControllerURLAndToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoint  string
+		token     string
+		wantURL   string
+		wantToken string
+	}{
+		{
+			name:      "control plane",
+			wantURL:   "http://shipyard-controller:8080",
+			wantToken: "",
+		},
+		{
+			name:      "remote control plane",
+			endpoint:  "https://keptn.example.com/api/",
+			token:     "my-token",
+			wantURL:   "https://keptn.example.com/api/controlPlane",
+			wantToken: "my-token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, token := getShipyardControllerURLAndToken(&env.Config{KeptnAPIEndpoint: tt.endpoint, KeptnAPIToken: tt.token})
+			assert.Equal(t, tt.wantURL, url)
+			assert.Equal(t, tt.wantToken, token)
+		})
+	}
+}
+
+func Test_newResourceHandler_RemoteControlPlane(t *testing.T) {
+	cfg := env.ReadConfig()
+	cfg.KeptnAPIEndpoint = "http://keptn.example.com/api"
+	cfg.KeptnAPIToken = "my-token"
+
+	handler := newResourceHandler(cfg)
+	assert.Equal(t, "keptn.example.com/api/configuration-service", handler.BaseURL)
+	assert.Equal(t, "my-token", handler.AuthToken)
+	assert.Equal(t, keptnhttp.AuthHeader, handler.AuthHeader)
+	assert.Equal(t, "http", handler.Scheme)
+	assert.Same(t, keptnhttp.GetDefaultHTTPClient(cfg), handler.HTTPClient)
+}
+
+func Test_newHandlers_UseSharedHTTPClient(t *testing.T) {
+	cfg := env.ReadConfig()
+	transport := keptnhttp.GetDefaultHTTPClient(cfg).Transport
+
+	assert.Same(t, keptnhttp.GetDefaultHTTPClient(cfg), newResourceHandler(cfg).HTTPClient)
+	assert.Same(t, keptnhttp.GetDefaultHTTPClient(cfg), newEventHandler(cfg).HTTPClient)
+	assert.Same(t, keptnhttp.GetDefaultHTTPClient(cfg), newProjectHandler(cfg).HTTPClient)
+	assert.Same(t, keptnhttp.GetDefaultHTTPClient(cfg), newStageHandler(cfg).HTTPClient)
+
+	// the transport of the shared client must not be replaced by the constructors of the handlers
+	assert.Same(t, transport, keptnhttp.GetDefaultHTTPClient(cfg).Transport)
+}
+
+func Test_checkServiceResponds(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "service responds", statusCode: http.StatusOK},
+		{name: "service responds without the resource", statusCode: http.StatusNotFound},
+		{name: "service is not available yet", statusCode: http.StatusServiceUnavailable, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "my-token", r.Header.Get(keptnhttp.AuthHeader))
+				w.WriteHeader(tt.statusCode)
+			}))
+			defer server.Close()
+
+			err := checkServiceResponds(server.Client(), server.URL+"/v1/project", keptnhttp.AuthHeader, "my-token")
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
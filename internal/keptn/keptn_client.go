@@ -3,12 +3,22 @@ package keptn
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	dtevent "github.com/keptn-contrib/dynatrace-service/internal/event"
+	"github.com/keptn-contrib/dynatrace-service/internal/nats"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	"github.com/keptn-contrib/dynatrace-service/internal/tracing"
 	keptnapi "github.com/keptn/go-utils/pkg/lib/keptn"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v2"
 )
 
 const sliResourceURI = "dynatrace/sli.yaml"
@@ -49,6 +59,12 @@ func (cq *CustomQueries) GetQueryByNameOrDefault(sliName string) (string, error)
 	return defaultQuery, nil
 }
 
+// IsDefined returns whether the SLI is defined in the sli.yaml, i.e. not only by a default query
+func (cq *CustomQueries) IsDefined(sliName string) bool {
+	_, exists := cq.values[sliName]
+	return exists
+}
+
 func (cq *CustomQueries) GetQueryByNameOrDefaultIfEmpty(sliName string) (string, error) {
 	query, exists := cq.values[sliName]
 	if exists {
@@ -71,12 +87,20 @@ func (cq *CustomQueries) GetQueryByNameOrDefaultIfEmpty(sliName string) (string,
 
 type ClientInterface interface {
 	GetCustomQueries(project string, stage string, service string) (*CustomQueries, error)
-	GetShipyard() (*keptnv2.Shipyard, error)
 	SendCloudEvent(factory adapter.CloudEventFactoryInterface) error
 }
 
 type Client struct {
-	client *keptnv2.Keptn
+	client     *keptnv2.Keptn
+	parentSpan trace.SpanContext
+	queue      *OutgoingEventQueue
+
+	// localResources is set when running locally, the SLI configuration is then read from the local disk
+	localResources *LocalResourceClient
+
+	// eventSource and eventExtensions are set on all sent events if configured
+	eventSource     string
+	eventExtensions map[string]string
 }
 
 func NewClient(client *keptnv2.Keptn) *Client {
@@ -85,16 +109,42 @@ func NewClient(client *keptnv2.Keptn) *Client {
 	}
 }
 
-func NewDefaultClient(event event.Event) (*Client, error) {
+// NewDefaultClient creates a Client for the event, sending events as configured by cfg
+func NewDefaultClient(event event.Event, cfg *env.Config) (*Client, error) {
 	keptnOpts := keptnapi.KeptnOpts{
 		ConfigurationServiceURL: common.GetConfigurationServiceURL(),
 		DatastoreURL:            common.GetDatastoreURL(),
 	}
+
+	// without a distributor, events are published to the Keptn message bus directly
+	if cfg.EventTransport == env.NATSTransport {
+		keptnOpts.EventSender = nats.NewEventSender(cfg)
+	}
+
+	// when running locally, events are written to files instead
+	if cfg.RunLocalEnabled {
+		keptnOpts.EventSender = NewLocalEventSender(cfg.RunLocalEventsDir)
+		keptnOpts.UseLocalFileSystem = true
+	}
 	kClient, err := keptnv2.NewKeptn(&event, keptnOpts)
 	if err != nil {
 		return nil, fmt.Errorf("could not create default Keptn client: %v", err)
 	}
-	return NewClient(kClient), nil
+
+	// on a remote execution plane, the configuration service and the datastore are only reachable via the Keptn API
+	if _, ok := getRemoteControlPlane(cfg); ok {
+		kClient.ResourceHandler = newResourceHandler(cfg)
+		kClient.EventHandler = newEventHandler(cfg)
+	}
+	client := NewClient(kClient)
+	client.parentSpan = tracing.FromEvent(event)
+	client.queue = GetDefaultOutgoingEventQueue(cfg)
+	client.eventSource = dtevent.GetEventSource(cfg)
+	client.eventExtensions = dtevent.GetEventExtensions(cfg)
+	if cfg.RunLocalEnabled {
+		client.localResources = NewLocalResourceClient()
+	}
+	return client, nil
 }
 
 func (c *Client) GetCustomQueries(project string, stage string, service string) (*CustomQueries, error) {
@@ -102,21 +152,102 @@ func (c *Client) GetCustomQueries(project string, stage string, service string)
 		return nil, errors.New("could not retrieve SLI config: no Keptn client initialized")
 	}
 
-	customQueries, err := c.client.GetSLIConfiguration(project, stage, service, sliResourceURI)
+	span := tracing.StartSpan("get SLI configuration", c.parentSpan, trace.SpanKindClient)
+	span.SetAttributes(attribute.String("keptn.resource", sliResourceURI))
+	start := time.Now()
+	customQueries, err := c.getSLIConfiguration(project, stage, service)
+	selfmonitoring.RecordAPICall(selfmonitoring.KeptnAPI, time.Since(start), err)
+	tracing.EndSpan(span, err)
 	if err != nil {
-		return nil, err
+		// errors of the YAML parser are caused by an invalid sli.yaml, all others by the configuration service
+		var parseErr *sliConfigParseError
+		if errors.As(err, &parseErr) {
+			return nil, common.NewUserConfigurationError(err)
+		}
+		return nil, common.NewKeptnAPIError(err)
 	}
 
 	return &CustomQueries{values: customQueries}, nil
 }
 
-func (c *Client) GetShipyard() (*keptnv2.Shipyard, error) {
-	shipyard, err := c.client.GetShipyard()
-	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve shipyard for project %s: %v", c.client.Event.GetProject(), err)
+// sliConfigParseError is returned if an sli.yaml cannot be parsed
+type sliConfigParseError struct {
+	cause error
+}
+
+// Error returns a string representation of this error
+func (e *sliConfigParseError) Error() string {
+	return fmt.Sprintf("could not parse %s: %v", sliResourceURI, e.cause)
+}
+
+// Unwrap returns the error of the YAML parser
+func (e *sliConfigParseError) Unwrap() error {
+	return e.cause
+}
+
+// getSLIConfiguration returns the SLIs defined in the sli.yaml files of the project, stage and service, with later ones taking precedence
+func (c *Client) getSLIConfiguration(project string, stage string, service string) (map[string]string, error) {
+	if c.localResources != nil {
+		content, err := c.localResources.GetResource(project, stage, service, sliResourceURI)
+		if isResourceMissing(err) {
+			return make(map[string]string), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parseSLIConfiguration(content)
 	}
 
-	return shipyard, nil
+	resources := NewConfigResourceClient(c.client.ResourceHandler)
+	getters := []func() (string, error){
+		func() (string, error) { return resources.GetProjectResource(project, sliResourceURI) },
+	}
+	if stage != "" {
+		getters = append(getters, func() (string, error) { return resources.GetStageResource(project, stage, sliResourceURI) })
+		if service != "" {
+			getters = append(getters, func() (string, error) { return resources.GetServiceResource(project, stage, service, sliResourceURI) })
+		}
+	}
+
+	indicators := make(map[string]string)
+	for _, get := range getters {
+		content, err := get()
+		if isResourceMissing(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		levelIndicators, err := parseSLIConfiguration(content)
+		if err != nil {
+			return nil, err
+		}
+		for name, query := range levelIndicators {
+			indicators[name] = query
+		}
+	}
+	return indicators, nil
+}
+
+// isResourceMissing returns whether err means that the resource does not exist or is empty
+func isResourceMissing(err error) bool {
+	var rnfErr *ResourceNotFoundError
+	var reErr *ResourceEmptyError
+	return errors.As(err, &rnfErr) || errors.As(err, &reErr)
+}
+
+// parseSLIConfiguration returns the SLIs defined in the content of an sli.yaml
+func parseSLIConfiguration(content string) (map[string]string, error) {
+	sliConfig := keptnapi.SLIConfig{}
+	err := yaml.Unmarshal([]byte(content), &sliConfig)
+	if err != nil {
+		return nil, &sliConfigParseError{cause: err}
+	}
+	if sliConfig.Indicators == nil {
+		return make(map[string]string), nil
+	}
+	return sliConfig.Indicators, nil
 }
 
 func (c *Client) SendCloudEvent(factory adapter.CloudEventFactoryInterface) error {
@@ -124,9 +255,38 @@ func (c *Client) SendCloudEvent(factory adapter.CloudEventFactoryInterface) erro
 	if err != nil {
 		return fmt.Errorf("could not create cloud event: %s", err)
 	}
+	if c.eventSource != "" {
+		ev.SetSource(c.eventSource)
+	}
+	for name, value := range c.eventExtensions {
+		ev.SetExtension(name, value)
+	}
 
-	if err := c.client.SendCloudEvent(*ev); err != nil {
-		return fmt.Errorf("could not send %s event: %s", ev.Type(), err.Error())
+	// the receivers of the event continue the trace as children of the span sending it
+	span := tracing.StartSpan("send "+ev.Type(), c.parentSpan, trace.SpanKindProducer)
+	span.SetAttributes(
+		attribute.String("cloudevents.event_id", ev.ID()),
+		attribute.String("cloudevents.event_type", ev.Type()),
+	)
+	tracing.SetEventSpanContext(ev, span.SpanContext())
+
+	// events of a task must not overtake its events that are still queued
+	if c.queue != nil && c.queue.IsPending(*ev) && c.queue.Enqueue(*ev) {
+		log.WithField("eventType", ev.Type()).Info("Queued event behind earlier events of the task that are not yet delivered")
+		tracing.EndSpan(span, nil)
+		return nil
+	}
+
+	start := time.Now()
+	err = c.client.SendCloudEvent(*ev)
+	selfmonitoring.RecordAPICall(selfmonitoring.KeptnAPI, time.Since(start), err)
+	tracing.EndSpan(span, err)
+	if err != nil {
+		if c.queue != nil && c.queue.Enqueue(*ev) {
+			log.WithError(err).WithField("eventType", ev.Type()).Warn("Could not send event, it will be redelivered in the background")
+			return nil
+		}
+		return common.NewKeptnAPIError(fmt.Errorf("could not send %s event: %s", ev.Type(), err.Error()))
 	}
 
 	return nil
@@ -1,6 +1,20 @@
 package keptn
 
-import "testing"
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnlib "github.com/keptn/go-utils/pkg/lib/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+)
 
 // Test that unsupported metrics return an error
 func TestGetUnsupportedSLI(t *testing.T) {
@@ -21,3 +35,106 @@ func TestGetUnsupportedSLI(t *testing.T) {
 		}
 	}
 }
+
+// createClientWithSLIFiles returns a Client retrieving the sli.yaml files from a configuration service serving the contents by resource path prefix.
+// A content of "500" makes the configuration service fail the request
+func createClientWithSLIFiles(t *testing.T, sliFiles map[string]string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for prefix, content := range sliFiles {
+			if !strings.HasPrefix(r.URL.Path, prefix+"/resource/") {
+				continue
+			}
+
+			if content == "500" {
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"code": 500, "message": "internal error"}`))
+				return
+			}
+			w.Write([]byte(`{"resourceURI":"dynatrace/sli.yaml","resourceContent":"` + base64.StdEncoding.EncodeToString([]byte(content)) + `"}`))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code": 404, "message": "resource not found"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return NewClient(&keptnv2.Keptn{KeptnBase: keptnlib.KeptnBase{ResourceHandler: keptnapi.NewResourceHandler(server.URL)}})
+}
+
+func TestClient_GetCustomQueries(t *testing.T) {
+	client := createClientWithSLIFiles(t, map[string]string{
+		"/v1/project/sockshop/stage/staging/service/carts": "indicators:\n  throughput: builtin:service.requestCount.total:merge(0):sum",
+		"/v1/project/sockshop/stage/staging":               "indicators:\n  throughput: stage\n  error_rate: builtin:service.errors.total.rate:merge(0):avg",
+	})
+
+	customQueries, err := client.GetCustomQueries("sockshop", "staging", "carts")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the service level takes precedence over the stage level
+	throughput, _ := customQueries.GetQueryByNameOrDefault("throughput")
+	assert.Equal(t, "builtin:service.requestCount.total:merge(0):sum", throughput)
+	errorRate, _ := customQueries.GetQueryByNameOrDefault("error_rate")
+	assert.Equal(t, "builtin:service.errors.total.rate:merge(0):avg", errorRate)
+}
+
+func TestClient_GetCustomQueriesClassifiesErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		sliFile       string
+		wantErrorType common.ErrorType
+	}{
+		{
+			name:          "invalid sli.yaml",
+			sliFile:       "indicators:\n  throughput: [",
+			wantErrorType: common.UserConfigurationErrorType,
+		},
+		{
+			name:          "indicators of wrong type",
+			sliFile:       "indicators:\n  - throughput",
+			wantErrorType: common.UserConfigurationErrorType,
+		},
+		{
+			name:          "configuration service failure",
+			sliFile:       "500",
+			wantErrorType: common.KeptnAPIErrorType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createClientWithSLIFiles(t, map[string]string{"/v1/project/sockshop/stage/staging": tt.sliFile})
+
+			customQueries, err := client.GetCustomQueries("sockshop", "staging", "carts")
+			assert.Nil(t, customQueries)
+			assert.Equal(t, tt.wantErrorType, common.GetErrorType(err))
+		})
+	}
+}
+
+type testCloudEventFactory struct{}
+
+func (f testCloudEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	ev := cloudevents.NewEvent()
+	ev.SetID("my-id")
+	ev.SetSource("dynatrace-service")
+	ev.SetType("sh.keptn.event.test.finished")
+	ev.SetExtension("shkeptncontext", "my-context")
+	return &ev, nil
+}
+
+func TestClient_SendCloudEventSetsConfiguredSourceAndExtensions(t *testing.T) {
+	sender := &keptnv2.TestSender{}
+	client := NewClient(&keptnv2.Keptn{KeptnBase: keptnlib.KeptnBase{EventSender: sender}})
+	client.eventSource = "dynatrace-service-eu"
+	client.eventExtensions = map[string]string{"gitcommitid": "abc123"}
+
+	err := client.SendCloudEvent(testCloudEventFactory{})
+	assert.NoError(t, err)
+	if assert.Len(t, sender.SentEvents, 1) {
+		assert.Equal(t, "dynatrace-service-eu", sender.SentEvents[0].Source())
+		assert.Equal(t, "abc123", sender.SentEvents[0].Extensions()["gitcommitid"])
+		assert.Equal(t, "my-context", sender.SentEvents[0].Extensions()["shkeptncontext"])
+	}
+}
@@ -0,0 +1,94 @@
+package keptn
+
+import (
+	"net/http"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// gitCommitIDParameter is the query parameter of the resource-service to retrieve a resource as of a specific git commit
+const gitCommitIDParameter = "gitCommitID"
+
+// commitPinningTransport adds the git commit ID to every resource retrieval, so that resources are read as of that commit.
+// Resources are still written to the latest revision
+type commitPinningTransport struct {
+	transport http.RoundTripper
+	commitID  string
+}
+
+// RoundTrip adds the git commit ID to GET requests and then executes them with the wrapped transport
+func (t *commitPinningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet {
+		req = req.Clone(req.Context())
+		query := req.URL.Query()
+		query.Set(gitCommitIDParameter, t.commitID)
+		req.URL.RawQuery = query.Encode()
+	}
+
+	return t.transport.RoundTrip(req)
+}
+
+// newResourceHandlerAtCommit returns a copy of the handler that retrieves all resources as of the given git commit.
+// If commitID is empty, the handler itself is returned
+func newResourceHandlerAtCommit(handler *keptnapi.ResourceHandler, commitID string) *keptnapi.ResourceHandler {
+	if commitID == "" {
+		return handler
+	}
+
+	httpClient := &http.Client{}
+	if handler.HTTPClient != nil {
+		*httpClient = *handler.HTTPClient
+	}
+
+	transport := httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpClient.Transport = &commitPinningTransport{transport: transport, commitID: commitID}
+
+	pinnedHandler := *handler
+	pinnedHandler.HTTPClient = httpClient
+
+	log.WithField("gitCommitID", commitID).Debug("Retrieving resources as of git commit")
+	return &pinnedHandler
+}
+
+// NewDefaultConfigResourceClientAtCommit creates a new ConfigResourceClient with a default Keptn resource handler that retrieves all resources
+// as of the given git commit. If commitID is empty, the latest resources are retrieved. When running locally, a LocalResourceClient is returned instead
+func NewDefaultConfigResourceClientAtCommit(cfg *env.Config, commitID string) ConfigResourceClientInterface {
+	if cfg.RunLocalEnabled {
+		return NewLocalResourceClient()
+	}
+
+	return NewConfigResourceClient(
+		newResourceHandlerAtCommit(newResourceHandler(cfg), commitID))
+}
+
+// NewDefaultResourceClientAtCommit creates a new ResourceClient with a default Keptn resource handler that retrieves all resources
+// as of the given git commit. If commitID is empty, the latest resources are retrieved
+func NewDefaultResourceClientAtCommit(cfg *env.Config, commitID string) *ResourceClient {
+	return NewResourceClient(
+		NewDefaultConfigResourceClientAtCommit(cfg, commitID),
+		cfg.DashboardStorage)
+}
+
+// AtCommit returns a copy of the Client that retrieves the SLI configuration as of the given git commit.
+// If commitID is empty, the Client itself is returned
+func (c *Client) AtCommit(commitID string) *Client {
+	if commitID == "" || c.client == nil {
+		return c
+	}
+
+	pinnedKeptn := *c.client
+	pinnedKeptn.ResourceHandler = newResourceHandlerAtCommit(c.client.ResourceHandler, commitID)
+
+	return &Client{
+		client:         &pinnedKeptn,
+		parentSpan:     c.parentSpan,
+		queue:          c.queue,
+		localResources: c.localResources,
+	}
+}
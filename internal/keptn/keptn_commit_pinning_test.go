@@ -0,0 +1,45 @@
+package keptn
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigResourceClientAtCommit(t *testing.T) {
+	var requestedQueries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedQueries = append(requestedQueries, r.Method+" "+r.URL.RawQuery)
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"resourceURI":"dynatrace/sli.yaml","resourceContent":"` + base64.StdEncoding.EncodeToString([]byte("spec_version: '1.0'")) + `"}`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	handler := keptnapi.NewResourceHandler(server.URL)
+	client := NewConfigResourceClient(newResourceHandlerAtCommit(handler, "3a2d6f8c"))
+
+	content, err := client.GetServiceResource("sockshop", "staging", "carts", "dynatrace/sli.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, "spec_version: '1.0'", content)
+
+	err = client.UploadResource([]byte("spec_version: '1.0'"), "dynatrace/sli.yaml", "sockshop", "staging", "carts")
+	assert.NoError(t, err)
+
+	// only retrievals are pinned to the commit, uploads go to the latest revision
+	assert.Equal(t, []string{"GET gitCommitID=3a2d6f8c", "POST "}, requestedQueries)
+
+	// the original handler is not modified
+	assert.IsType(t, &http.Transport{}, handler.HTTPClient.Transport)
+}
+
+func TestNewResourceHandlerAtCommitWithoutCommitID(t *testing.T) {
+	handler := keptnapi.NewResourceHandler("http://configuration-service:8080")
+	assert.Same(t, handler, newResourceHandlerAtCommit(handler, ""))
+}
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnmodels "github.com/keptn/go-utils/pkg/api/models"
 	api "github.com/keptn/go-utils/pkg/api/utils"
 	log "github.com/sirupsen/logrus"
+	"strings"
 )
 
 // ConfigResourceClientInterface defines the methods for interacting with resources of Keptn's configuration service
@@ -16,6 +18,13 @@ type ConfigResourceClientInterface interface {
 	GetStageResource(project string, stage string, resourceURI string) (string, error)
 	GetServiceResource(project string, stage string, service string, resourceURI string) (string, error)
 	UploadResource(contentToUpload []byte, remoteResourceURI string, project string, stage string, service string) error
+	UploadResources(resources []Resource, project string, stage string, service string) error
+}
+
+// Resource is a file to be uploaded to Keptn's configuration service
+type Resource struct {
+	URI     string
+	Content []byte
 }
 
 // ResourceError represents an error for a resource that was not found
@@ -34,6 +43,11 @@ func (e *ResourceNotFoundError) Error() string {
 	return fmt.Sprintf("could not find resource: '%s' %s", e.uri, getLocation(e.service, e.stage, e.project))
 }
 
+// ErrorType returns the type of the error
+func (e *ResourceNotFoundError) ErrorType() common.ErrorType {
+	return common.UserConfigurationErrorType
+}
+
 // ResourceEmptyError represents an error for a resource that was found, but is empty
 type ResourceEmptyError ResourceError
 
@@ -42,6 +56,11 @@ func (e *ResourceEmptyError) Error() string {
 	return fmt.Sprintf("found resource: '%s' %s, but it is empty", e.uri, getLocation(e.service, e.stage, e.project))
 }
 
+// ErrorType returns the type of the error
+func (e *ResourceEmptyError) ErrorType() common.ErrorType {
+	return common.UserConfigurationErrorType
+}
+
 // ResourceUploadFailedError represents an error for a resource that could not be uploaded
 type ResourceUploadFailedError struct {
 	ResourceError
@@ -53,6 +72,11 @@ func (e *ResourceUploadFailedError) Error() string {
 	return fmt.Sprintf("could not upload resource: '%s' %s: %s", e.uri, getLocation(e.service, e.stage, e.project), e.message)
 }
 
+// ErrorType returns the type of the error
+func (e *ResourceUploadFailedError) ErrorType() common.ErrorType {
+	return common.KeptnAPIErrorType
+}
+
 // ResourceRetrievalFailedError represents an error for a resource that could not be retrieved because of an error
 type ResourceRetrievalFailedError struct {
 	ResourceError
@@ -64,6 +88,11 @@ func (e *ResourceRetrievalFailedError) Error() string {
 	return fmt.Sprintf("could not retrieve resource: '%s' %s: %s", e.uri, getLocation(e.service, e.stage, e.project), e.message)
 }
 
+// ErrorType returns the type of the error
+func (e *ResourceRetrievalFailedError) ErrorType() common.ErrorType {
+	return common.KeptnAPIErrorType
+}
+
 func getLocation(service string, stage string, project string) string {
 	var location string
 
@@ -85,10 +114,15 @@ type ConfigResourceClient struct {
 	handler *api.ResourceHandler
 }
 
-// NewDefaultConfigResourceClient creates a new ResourceClient with a default Keptn resource handler for the configuration service
-func NewDefaultConfigResourceClient() *ConfigResourceClient {
+// NewDefaultConfigResourceClient creates a new ResourceClient with a default Keptn resource handler for the configuration service.
+// When running locally, a LocalResourceClient is returned instead
+func NewDefaultConfigResourceClient(cfg *env.Config) ConfigResourceClientInterface {
+	if cfg.RunLocalEnabled {
+		return NewLocalResourceClient()
+	}
+
 	return NewConfigResourceClient(
-		api.NewResourceHandler(common.GetConfigurationServiceURL()))
+		newResourceHandler(cfg))
 }
 
 // NewConfigResourceClient creates a new ResourceClient with a Keptn resource handler for the configuration service
@@ -224,12 +258,27 @@ func getResourceByFunc(
 
 // UploadResource tries to upload a resourceURI on service level
 func (rc *ConfigResourceClient) UploadResource(contentToUpload []byte, remoteResourceURI string, project string, stage string, service string) error {
-	resources := []*keptnmodels.Resource{{ResourceContent: string(contentToUpload), ResourceURI: &remoteResourceURI}}
-	_, err := rc.handler.CreateResources(project, stage, service, resources)
+	return rc.UploadResources([]Resource{{URI: remoteResourceURI, Content: contentToUpload}}, project, stage, service)
+}
+
+// UploadResources tries to upload several resources on service level in a single request, which results in a single commit
+func (rc *ConfigResourceClient) UploadResources(resources []Resource, project string, stage string, service string) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	resourceURIs := make([]string, len(resources))
+	keptnResources := make([]*keptnmodels.Resource, len(resources))
+	for i := range resources {
+		resourceURIs[i] = resources[i].URI
+		keptnResources[i] = &keptnmodels.Resource{ResourceContent: string(resources[i].Content), ResourceURI: &resources[i].URI}
+	}
+
+	_, err := rc.handler.CreateResources(project, stage, service, keptnResources)
 	if err != nil {
 		return &ResourceUploadFailedError{
 			ResourceError{
-				uri:     remoteResourceURI,
+				uri:     strings.Join(resourceURIs, ", "),
 				project: project,
 				stage:   stage,
 				service: service,
@@ -238,6 +287,6 @@ func (rc *ConfigResourceClient) UploadResource(contentToUpload []byte, remoteRes
 		}
 	}
 
-	log.WithField("remoteResourceURI", remoteResourceURI).Info("Uploaded file")
+	log.WithField("remoteResourceURIs", resourceURIs).Info("Uploaded files")
 	return nil
 }
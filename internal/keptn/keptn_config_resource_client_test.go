@@ -0,0 +1,89 @@
+package keptn
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+type resourceUploadRequest struct {
+	path      string
+	resources []struct {
+		ResourceURI     string `json:"resourceURI"`
+		ResourceContent string `json:"resourceContent"`
+	}
+}
+
+func createConfigResourceClientRecordingUploads(t *testing.T, statusCode int) (*ConfigResourceClient, *[]resourceUploadRequest) {
+	var uploads []resourceUploadRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		upload := resourceUploadRequest{path: r.URL.Path}
+		var request struct {
+			Resources json.RawMessage `json:"resources"`
+		}
+		_ = json.Unmarshal(body, &request)
+		_ = json.Unmarshal(request.Resources, &upload.resources)
+		uploads = append(uploads, upload)
+
+		w.WriteHeader(statusCode)
+		if statusCode != http.StatusCreated {
+			w.Write([]byte(`{"code": 500, "message": "could not commit"}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return NewConfigResourceClient(keptnapi.NewResourceHandler(server.URL)), &uploads
+}
+
+func TestConfigResourceClient_UploadResourcesSendsSingleRequest(t *testing.T) {
+	client, uploads := createConfigResourceClientRecordingUploads(t, http.StatusCreated)
+
+	err := client.UploadResources(
+		[]Resource{
+			{URI: sliFilename, Content: []byte("indicators: {}")},
+			{URI: sloFilename, Content: []byte("objectives: []")},
+		},
+		"sockshop", "staging", "carts")
+	assert.NoError(t, err)
+
+	if assert.Len(t, *uploads, 1) {
+		upload := (*uploads)[0]
+		assert.Equal(t, "/v1/project/sockshop/stage/staging/service/carts/resource", upload.path)
+		if assert.Len(t, upload.resources, 2) {
+			assert.Equal(t, sliFilename, upload.resources[0].ResourceURI)
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("indicators: {}")), upload.resources[0].ResourceContent)
+			assert.Equal(t, sloFilename, upload.resources[1].ResourceURI)
+		}
+	}
+}
+
+func TestConfigResourceClient_UploadResourcesWithoutResourcesSendsNoRequest(t *testing.T) {
+	client, uploads := createConfigResourceClientRecordingUploads(t, http.StatusCreated)
+
+	err := client.UploadResources(nil, "sockshop", "staging", "carts")
+	assert.NoError(t, err)
+	assert.Empty(t, *uploads)
+}
+
+func TestConfigResourceClient_UploadResourcesReportsAllResourcesOnFailure(t *testing.T) {
+	client, _ := createConfigResourceClientRecordingUploads(t, http.StatusInternalServerError)
+
+	err := client.UploadResources(
+		[]Resource{
+			{URI: sliFilename, Content: []byte("indicators: {}")},
+			{URI: sloFilename, Content: []byte("objectives: []")},
+		},
+		"sockshop", "staging", "carts")
+
+	var uploadErr *ResourceUploadFailedError
+	if assert.ErrorAs(t, err, &uploadErr) {
+		assert.Contains(t, err.Error(), sliFilename+", "+sloFilename)
+	}
+}
@@ -0,0 +1,121 @@
+package keptn
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+var defaultDynatraceConfigCache *CachingDynatraceConfigResourceClient
+var defaultDynatraceConfigCacheOnce sync.Once
+
+type dynatraceConfigCacheKey struct {
+	project string
+	stage   string
+	service string
+}
+
+type cachedDynatraceConfig struct {
+	content   string
+	revision  string
+	err       error
+	fetchedAt time.Time
+}
+
+// CachingDynatraceConfigResourceClient caches dynatrace.conf.yaml files per project, stage and service.
+// Cached entries are revalidated against the configuration service once they are older than the TTL.
+// A missing file is cached as well, as most events are for projects without a dynatrace.conf.yaml on service level
+type CachingDynatraceConfigResourceClient struct {
+	client DynatraceConfigResourceClientInterface
+	ttl    time.Duration
+	now    func() time.Time
+
+	mutex   sync.Mutex
+	entries map[dynatraceConfigCacheKey]cachedDynatraceConfig
+}
+
+// GetDefaultCachingDynatraceConfigResourceClient returns the CachingDynatraceConfigResourceClient shared by all event handlers.
+// It uses the configuration service and the TTL of the configuration of the first call
+func GetDefaultCachingDynatraceConfigResourceClient(cfg *env.Config) *CachingDynatraceConfigResourceClient {
+	defaultDynatraceConfigCacheOnce.Do(func() {
+		defaultDynatraceConfigCache = NewCachingDynatraceConfigResourceClient(
+			NewDefaultResourceClient(cfg),
+			time.Duration(cfg.DynatraceConfigCacheTTL)*time.Second)
+	})
+
+	return defaultDynatraceConfigCache
+}
+
+// NewCachingDynatraceConfigResourceClient creates a new CachingDynatraceConfigResourceClient. If ttl is 0 or less, nothing is cached
+func NewCachingDynatraceConfigResourceClient(client DynatraceConfigResourceClientInterface, ttl time.Duration) *CachingDynatraceConfigResourceClient {
+	return &CachingDynatraceConfigResourceClient{
+		client:  client,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[dynatraceConfigCacheKey]cachedDynatraceConfig),
+	}
+}
+
+// GetDynatraceConfig returns the cached dynatrace.conf.yaml or retrieves it from the configuration service if the cached entry expired
+func (c *CachingDynatraceConfigResourceClient) GetDynatraceConfig(project string, stage string, service string) (string, error) {
+	if c.ttl <= 0 {
+		return c.client.GetDynatraceConfig(project, stage, service)
+	}
+
+	key := dynatraceConfigCacheKey{project: project, stage: stage, service: service}
+
+	c.mutex.Lock()
+	entry, found := c.entries[key]
+	c.mutex.Unlock()
+
+	now := c.now()
+	if found && now.Sub(entry.fetchedAt) < c.ttl {
+		return entry.content, entry.err
+	}
+
+	content, err := c.client.GetDynatraceConfig(project, stage, service)
+
+	// only a missing file is cached, other errors are retried with the next event
+	var rnfErr *ResourceNotFoundError
+	if err != nil && !errors.As(err, &rnfErr) {
+		return "", err
+	}
+
+	revision := getRevision(content, err)
+	if found && entry.revision != revision {
+		log.WithFields(
+			log.Fields{
+				"project":     project,
+				"stage":       stage,
+				"service":     service,
+				"oldRevision": entry.revision,
+				"newRevision": revision,
+			}).Info("Reloaded changed dynatrace.conf.yaml")
+	}
+
+	c.mutex.Lock()
+	c.entries[key] = cachedDynatraceConfig{
+		content:   content,
+		revision:  revision,
+		err:       err,
+		fetchedAt: now,
+	}
+	c.mutex.Unlock()
+
+	return content, err
+}
+
+// getRevision returns an identifier for the content of a dynatrace.conf.yaml that changes whenever the content changes
+func getRevision(content string, err error) string {
+	if err != nil {
+		return "not-found"
+	}
+
+	hash := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(hash[:8])
+}
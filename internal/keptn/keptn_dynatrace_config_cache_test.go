@@ -0,0 +1,83 @@
+package keptn
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type dynatraceConfigResourceClientMock struct {
+	content string
+	err     error
+	calls   int
+}
+
+func (m *dynatraceConfigResourceClientMock) GetDynatraceConfig(project string, stage string, service string) (string, error) {
+	m.calls++
+	return m.content, m.err
+}
+
+func TestCachingDynatraceConfigResourceClient_GetDynatraceConfig(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	mock := &dynatraceConfigResourceClientMock{content: "spec_version: '0.1.0'\ndtCreds: dynatrace"}
+	cache := NewCachingDynatraceConfigResourceClient(mock, 30*time.Second)
+	cache.now = func() time.Time { return now }
+
+	content, err := cache.GetDynatraceConfig("sockshop", "staging", "carts")
+	assert.NoError(t, err)
+	assert.Equal(t, "spec_version: '0.1.0'\ndtCreds: dynatrace", content)
+	assert.Equal(t, 1, mock.calls)
+
+	// within the TTL the cached file is returned
+	mock.content = "spec_version: '0.1.0'\ndtCreds: dynatrace-prod"
+	now = now.Add(10 * time.Second)
+	content, _ = cache.GetDynatraceConfig("sockshop", "staging", "carts")
+	assert.Equal(t, "spec_version: '0.1.0'\ndtCreds: dynatrace", content)
+	assert.Equal(t, 1, mock.calls)
+
+	// other services are cached separately
+	_, _ = cache.GetDynatraceConfig("sockshop", "staging", "orders")
+	assert.Equal(t, 2, mock.calls)
+
+	// after the TTL the changed file is picked up
+	now = now.Add(30 * time.Second)
+	content, _ = cache.GetDynatraceConfig("sockshop", "staging", "carts")
+	assert.Equal(t, "spec_version: '0.1.0'\ndtCreds: dynatrace-prod", content)
+	assert.Equal(t, 3, mock.calls)
+}
+
+func TestCachingDynatraceConfigResourceClient_CachesMissingFile(t *testing.T) {
+	mock := &dynatraceConfigResourceClientMock{err: &ResourceNotFoundError{uri: configFilename, project: "sockshop"}}
+	cache := NewCachingDynatraceConfigResourceClient(mock, 30*time.Second)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetDynatraceConfig("sockshop", "staging", "carts")
+		var rnfErr *ResourceNotFoundError
+		assert.True(t, errors.As(err, &rnfErr))
+	}
+	assert.Equal(t, 1, mock.calls)
+}
+
+func TestCachingDynatraceConfigResourceClient_DoesNotCacheOtherErrors(t *testing.T) {
+	mock := &dynatraceConfigResourceClientMock{err: &ResourceRetrievalFailedError{ResourceError{uri: configFilename, project: "sockshop"}, "connection refused"}}
+	cache := NewCachingDynatraceConfigResourceClient(mock, 30*time.Second)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetDynatraceConfig("sockshop", "staging", "carts")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, mock.calls)
+}
+
+func TestCachingDynatraceConfigResourceClient_Disabled(t *testing.T) {
+	mock := &dynatraceConfigResourceClientMock{content: "dtCreds: dynatrace"}
+	cache := NewCachingDynatraceConfigResourceClient(mock, 0)
+
+	for i := 0; i < 2; i++ {
+		_, _ = cache.GetDynatraceConfig("sockshop", "staging", "carts")
+	}
+	assert.Equal(t, 2, mock.calls)
+}
@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn/go-utils/pkg/api/models"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	keptncommon "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
-	"os"
 	"strings"
+	"time"
 )
 
 type EventClientBaseInterface interface {
@@ -22,9 +23,9 @@ type EventClientBase struct {
 	client *keptnapi.EventHandler
 }
 
-func NewEventClientBase() *EventClientBase {
+func NewEventClientBase(cfg *env.Config) *EventClientBase {
 	return &EventClientBase{
-		client: keptnapi.NewEventHandler(os.Getenv("DATASTORE")),
+		client: newEventHandler(cfg),
 	}
 }
 
@@ -38,11 +39,16 @@ func (c *EventClientBase) GetEvents(filter *keptnapi.EventFilter) ([]*models.Kep
 }
 
 const problemURLLabel = "Problem URL"
+const remediationTaskName = "remediation"
+
+// ErrProblemIDNotFound is returned by FindProblemID if the Keptn context was not started by a Dynatrace problem
+var ErrProblemIDNotFound = errors.New("no problem ID found")
 
 type EventClientInterface interface {
 	IsPartOfRemediation(event adapter.EventContentAdapter) (bool, error)
 	FindProblemID(keptnEvent adapter.EventContentAdapter) (string, error)
 	GetImageAndTag(keptnEvent adapter.EventContentAdapter) common.ImageAndTag
+	FindTriggeredEventTime(keptnEvent adapter.EventContentAdapter, taskName string, triggeredID string) (time.Time, error)
 }
 
 type EventClient struct {
@@ -55,9 +61,14 @@ func NewEventClient(client EventClientBaseInterface) *EventClient {
 	}
 }
 
-func NewDefaultEventClient() *EventClient {
+// NewDefaultEventClient creates an EventClient retrieving events from the datastore or, when running locally, from the local events directory
+func NewDefaultEventClient(cfg *env.Config) *EventClient {
+	if cfg.RunLocalEnabled {
+		return NewEventClient(NewLocalEventClientBase(cfg.RunLocalEventsDir))
+	}
+
 	return NewEventClient(
-		NewEventClientBase())
+		NewEventClientBase(cfg))
 }
 
 // IsPartOfRemediation checks whether the evaluation.finished event is part of a remediation task sequence
@@ -67,7 +78,7 @@ func (c *EventClient) IsPartOfRemediation(event adapter.EventContentAdapter) (bo
 			Project:      event.GetProject(),
 			Stage:        event.GetStage(),
 			Service:      event.GetService(),
-			EventType:    keptnv2.GetTriggeredEventType("remediation"),
+			EventType:    keptnv2.GetTriggeredEventType(remediationTaskName),
 			KeptnContext: event.GetShKeptnContext(),
 		})
 
@@ -84,6 +95,7 @@ func (c *EventClient) IsPartOfRemediation(event adapter.EventContentAdapter) (bo
 
 // FindProblemID finds the Problem ID that is associated with this Keptn Workflow
 // It first parses it from Problem URL label - if it cant be found there it will look for the Initial Problem Open Event and gets the ID from there!
+// If there is none, an error wrapping ErrProblemIDNotFound is returned
 func (c *EventClient) FindProblemID(keptnEvent adapter.EventContentAdapter) (string, error) {
 
 	// Step 1 - see if we have a Problem Url in the labels
@@ -100,7 +112,15 @@ func (c *EventClient) FindProblemID(keptnEvent adapter.EventContentAdapter) (str
 		}
 	}
 
-	// Step 2 - lets see if we have a ProblemOpenEvent for this KeptnContext - if so - we try to extract the Problem ID
+	// Step 2 - lets see if this KeptnContext was started by a remediation.triggered event sent for a Dynatrace problem
+	pid, err := c.findProblemIDInRemediationTriggeredEvent(keptnEvent)
+	if err != nil {
+		log.WithError(err).Debug("Could not find problem ID in remediation.triggered event")
+	} else if pid != "" {
+		return pid, nil
+	}
+
+	// Step 3 - lets see if we have a ProblemOpenEvent for this KeptnContext - if so - we try to extract the Problem ID
 	events, err := c.client.GetEvents(
 		&keptnapi.EventFilter{
 			Project:      keptnEvent.GetProject(),
@@ -113,7 +133,7 @@ func (c *EventClient) FindProblemID(keptnEvent adapter.EventContentAdapter) (str
 	}
 
 	if len(events) == 0 {
-		return "", errors.New("cannot send DT problem comment: Could not retrieve problem.open event for incoming event: no events returned")
+		return "", fmt.Errorf("cannot send DT problem comment: Could not retrieve problem.open event for incoming event: no events returned: %w", ErrProblemIDNotFound)
 	}
 
 	problemOpenEvent := &keptncommon.ProblemEventData{}
@@ -123,12 +143,72 @@ func (c *EventClient) FindProblemID(keptnEvent adapter.EventContentAdapter) (str
 	}
 
 	if problemOpenEvent.PID == "" {
-		return "", errors.New("cannot send DT problem comment: No problem ID is included in the event")
+		return "", fmt.Errorf("cannot send DT problem comment: No problem ID is included in the event: %w", ErrProblemIDNotFound)
 	}
 
 	return problemOpenEvent.PID, nil
 }
 
+// remediationTriggeredEventData contains the parts of a remediation.triggered event sent by the dynatrace-service needed to find the problem ID
+type remediationTriggeredEventData struct {
+	Problem struct {
+		PID string `json:"PID"`
+	} `json:"problem"`
+}
+
+func (c *EventClient) findProblemIDInRemediationTriggeredEvent(keptnEvent adapter.EventContentAdapter) (string, error) {
+	if keptnEvent.GetStage() == "" {
+		return "", nil
+	}
+
+	events, err := c.client.GetEvents(
+		&keptnapi.EventFilter{
+			Project:      keptnEvent.GetProject(),
+			EventType:    keptnv2.GetTriggeredEventType(keptnEvent.GetStage() + "." + remediationTaskName),
+			KeptnContext: keptnEvent.GetShKeptnContext(),
+		})
+	if err != nil {
+		return "", err
+	}
+
+	if len(events) == 0 {
+		return "", nil
+	}
+
+	remediationTriggeredData := &remediationTriggeredEventData{}
+	err = keptnv2.Decode(events[0].Data, remediationTriggeredData)
+	if err != nil {
+		return "", fmt.Errorf("could not decode remediation.triggered event: %s", err.Error())
+	}
+
+	return remediationTriggeredData.Problem.PID, nil
+}
+
+// FindTriggeredEventTime returns the time of the <taskName>.triggered event with the given ID in the Keptn context of the event.
+// If no ID is specified, the time of the first <taskName>.triggered event of the Keptn context is returned
+func (c *EventClient) FindTriggeredEventTime(keptnEvent adapter.EventContentAdapter, taskName string, triggeredID string) (time.Time, error) {
+	events, err := c.client.GetEvents(
+		&keptnapi.EventFilter{
+			Project:      keptnEvent.GetProject(),
+			Stage:        keptnEvent.GetStage(),
+			Service:      keptnEvent.GetService(),
+			EventType:    keptnv2.GetTriggeredEventType(taskName),
+			KeptnContext: keptnEvent.GetShKeptnContext(),
+			EventID:      triggeredID,
+		})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	for _, event := range events {
+		if event != nil && (triggeredID == "" || event.ID == triggeredID) {
+			return event.Time, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("could not find %s event", keptnv2.GetTriggeredEventType(taskName))
+}
+
 func (c *EventClient) GetImageAndTag(event adapter.EventContentAdapter) common.ImageAndTag {
 
 	events, err := c.client.GetEvents(
@@ -0,0 +1,375 @@
+package keptn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/cloudevents/sdk-go/v2/types"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxOutgoingEventRetryDelay limits the exponential backoff between two delivery attempts of a queued event
+const maxOutgoingEventRetryDelay = 5 * time.Minute
+
+const bufferedEventFileExtension = ".json"
+
+// errOutgoingEventQueueStopped is returned for an event whose delivery was interrupted by stopping the queue
+var errOutgoingEventQueueStopped = errors.New("outgoing event queue was stopped")
+
+var defaultOutgoingEventQueue *OutgoingEventQueue
+var defaultOutgoingEventQueueOnce sync.Once
+
+// OutgoingEventQueue redelivers events that could not be sent, e.g. during a transient outage of the Keptn control plane.
+// Events of the same task are delivered in order: once an event is queued, later events of the task are queued behind it.
+// If a buffer directory is set, queued events are stored there as well, so that they are delivered after a restart
+type OutgoingEventQueue struct {
+	send       func(event cloudevents.Event) error
+	maxRetries int
+	retryDelay time.Duration
+	bufferDir  string
+	after      func(d time.Duration) <-chan time.Time
+
+	mutex      sync.Mutex
+	pending    map[string][]cloudevents.Event
+	deadLetter func(event cloudevents.Event, attempts int, err error)
+	// workers is the number of tasks whose events are being delivered and idle is closed while there are none
+	workers int
+	idle    chan struct{}
+	// flushing is set once Flush was called, events are no longer queued from then on
+	flushing bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// GetDefaultOutgoingEventQueue returns the OutgoingEventQueue shared by all Keptn clients. It is created using the configuration of the first call
+func GetDefaultOutgoingEventQueue(cfg *env.Config) *OutgoingEventQueue {
+	defaultOutgoingEventQueueOnce.Do(func() {
+		defaultOutgoingEventQueue = NewOutgoingEventQueue(
+			func(event cloudevents.Event) error {
+				return sendCloudEvent(event, cfg)
+			},
+			cfg.OutgoingEventMaxRetries,
+			time.Duration(cfg.OutgoingEventRetryDelay)*time.Second,
+			cfg.OutgoingEventBufferDir)
+	})
+
+	return defaultOutgoingEventQueue
+}
+
+// NewOutgoingEventQueue creates a new OutgoingEventQueue. If maxRetries is 0 or less, no event is queued. If bufferDir is empty,
+// queued events are only kept in memory
+func NewOutgoingEventQueue(send func(event cloudevents.Event) error, maxRetries int, retryDelay time.Duration, bufferDir string) *OutgoingEventQueue {
+	q := &OutgoingEventQueue{
+		send:       send,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		bufferDir:  bufferDir,
+		after:      time.After,
+		pending:    make(map[string][]cloudevents.Event),
+		idle:       make(chan struct{}),
+		stop:       make(chan struct{}),
+	}
+	close(q.idle)
+	return q
+}
+
+// sendCloudEvent sends the event with a Keptn client created for the event itself, as the event that caused it may no longer be available
+func sendCloudEvent(event cloudevents.Event, cfg *env.Config) error {
+	client, err := NewDefaultClient(event, cfg)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	err = client.client.SendCloudEvent(event)
+	selfmonitoring.RecordAPICall(selfmonitoring.KeptnAPI, time.Since(start), err)
+	return err
+}
+
+// SetDeadLetterFunc sets the function events are passed to once they could not be delivered after all retries, e.g. to write them to a dead-letter sink
+func (q *OutgoingEventQueue) SetDeadLetterFunc(deadLetter func(event cloudevents.Event, attempts int, err error)) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.deadLetter = deadLetter
+}
+
+// Stop stops delivering queued events and waits until the deliveries in progress are interrupted.
+// Events that are not delivered yet stay in the buffer directory, if any, so that they are delivered after a restart
+func (q *OutgoingEventQueue) Stop() {
+	q.stopOnce.Do(func() {
+		close(q.stop)
+	})
+
+	q.mutex.Lock()
+	idle := q.idle
+	q.mutex.Unlock()
+	<-idle
+}
+
+// Flush waits until all queued events were delivered or given up, or until the context is done.
+// Once Flush was called, no further events are queued, i.e. they are sent right away
+func (q *OutgoingEventQueue) Flush(ctx context.Context) error {
+	q.mutex.Lock()
+	q.flushing = true
+	idle := q.idle
+	q.mutex.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// IsEnabled returns whether events that could not be sent are queued
+func (q *OutgoingEventQueue) IsEnabled() bool {
+	return q.maxRetries > 0
+}
+
+// IsPending returns whether earlier events of the same task are still queued, so that the event has to be queued behind them
+func (q *OutgoingEventQueue) IsPending(event cloudevents.Event) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	_, found := q.pending[getOutgoingEventQueueKey(event)]
+	return found
+}
+
+// Enqueue queues the event for delivery in the background and returns whether it was queued. It is not queued if queuing is disabled
+// or the queue is being flushed
+func (q *OutgoingEventQueue) Enqueue(event cloudevents.Event) bool {
+	if !q.IsEnabled() {
+		return false
+	}
+
+	q.bufferEvent(event)
+	if !q.enqueue(event) {
+		q.removeBufferedEvent(event)
+		return false
+	}
+	return true
+}
+
+// enqueue adds the event to the pending events of its task, starting their delivery if there were none, and returns whether it was added
+func (q *OutgoingEventQueue) enqueue(event cloudevents.Event) bool {
+	key := getOutgoingEventQueueKey(event)
+
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	if q.flushing {
+		return false
+	}
+
+	events, found := q.pending[key]
+	q.pending[key] = append(events, event)
+	if !found {
+		if q.workers == 0 {
+			q.idle = make(chan struct{})
+		}
+		q.workers++
+		go q.deliver(key)
+	}
+	return true
+}
+
+// RedeliverBufferedEvents queues the events left in the buffer directory by a previous run, ordered by their time
+func (q *OutgoingEventQueue) RedeliverBufferedEvents() {
+	if !q.IsEnabled() || q.bufferDir == "" {
+		return
+	}
+
+	files, err := ioutil.ReadDir(q.bufferDir)
+	if err != nil {
+		log.WithError(err).WithField("bufferDir", q.bufferDir).Error("Could not read buffered outgoing events")
+		return
+	}
+
+	var events []cloudevents.Event
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), bufferedEventFileExtension) {
+			continue
+		}
+
+		event, err := readBufferedEvent(filepath.Join(q.bufferDir, file.Name()))
+		if err != nil {
+			log.WithError(err).WithField("file", file.Name()).Error("Could not read buffered outgoing event")
+			continue
+		}
+		events = append(events, *event)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time().Before(events[j].Time())
+	})
+
+	for _, event := range events {
+		log.WithFields(
+			log.Fields{
+				"eventID":   event.ID(),
+				"eventType": event.Type(),
+			}).Info("Redelivering buffered outgoing event")
+		q.enqueue(event)
+	}
+}
+
+// deliver sends the queued events of a task one after another until the queue of the task is empty or the queue is stopped
+func (q *OutgoingEventQueue) deliver(key string) {
+	defer q.workerDone()
+
+	for {
+		q.mutex.Lock()
+		events := q.pending[key]
+		if len(events) == 0 {
+			delete(q.pending, key)
+			q.mutex.Unlock()
+			return
+		}
+		event := events[0]
+		q.mutex.Unlock()
+
+		err := q.sendWithRetries(event)
+		if errors.Is(err, errOutgoingEventQueueStopped) {
+			return
+		}
+		if err != nil {
+			q.giveUp(event, err)
+		}
+		q.removeBufferedEvent(event)
+
+		q.mutex.Lock()
+		q.pending[key] = q.pending[key][1:]
+		q.mutex.Unlock()
+	}
+}
+
+// workerDone marks the delivery of the events of a task as finished and signals Flush and Stop once no events are being delivered anymore
+func (q *OutgoingEventQueue) workerDone() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.workers--
+	if q.workers == 0 {
+		close(q.idle)
+	}
+}
+
+func (q *OutgoingEventQueue) sendWithRetries(event cloudevents.Event) error {
+	logger := log.WithFields(
+		log.Fields{
+			"eventID":   event.ID(),
+			"eventType": event.Type(),
+		})
+
+	var err error
+	delay := q.retryDelay
+	for attempt := 1; attempt <= q.maxRetries; attempt++ {
+		select {
+		case <-q.stop:
+			return errOutgoingEventQueueStopped
+		case <-q.after(delay):
+		}
+
+		err = q.send(event)
+		if err == nil {
+			logger.WithField("attempt", attempt).Info("Delivered queued outgoing event")
+			return nil
+		}
+
+		logger.WithError(err).WithField("attempt", attempt).Warn("Could not deliver queued outgoing event")
+		delay *= 2
+		if delay > maxOutgoingEventRetryDelay {
+			delay = maxOutgoingEventRetryDelay
+		}
+	}
+
+	return err
+}
+
+// giveUp passes the event that could not be delivered after all retries to the dead-letter function, or logs it if there is none
+func (q *OutgoingEventQueue) giveUp(event cloudevents.Event, err error) {
+	q.mutex.Lock()
+	deadLetter := q.deadLetter
+	q.mutex.Unlock()
+
+	if deadLetter != nil {
+		deadLetter(event, q.maxRetries, fmt.Errorf("could not deliver outgoing event to Keptn: %w", err))
+		return
+	}
+
+	log.WithError(err).WithFields(
+		log.Fields{
+			"eventID":   event.ID(),
+			"eventType": event.Type(),
+			"retries":   q.maxRetries,
+			"event":     string(event.Data()),
+		}).Error("Giving up delivering outgoing event")
+}
+
+func (q *OutgoingEventQueue) bufferEvent(event cloudevents.Event) {
+	if q.bufferDir == "" {
+		return
+	}
+
+	content, err := json.Marshal(event)
+	if err == nil {
+		err = ioutil.WriteFile(q.getBufferedEventFile(event), content, 0600)
+	}
+	if err != nil {
+		log.WithError(err).WithField("eventID", event.ID()).Error("Could not buffer outgoing event, it is only kept in memory")
+	}
+}
+
+func (q *OutgoingEventQueue) removeBufferedEvent(event cloudevents.Event) {
+	if q.bufferDir == "" {
+		return
+	}
+
+	err := os.Remove(q.getBufferedEventFile(event))
+	if err != nil && !os.IsNotExist(err) {
+		log.WithError(err).WithField("eventID", event.ID()).Error("Could not remove buffered outgoing event")
+	}
+}
+
+func (q *OutgoingEventQueue) getBufferedEventFile(event cloudevents.Event) string {
+	return filepath.Join(q.bufferDir, filepath.Base(event.ID())+bufferedEventFileExtension)
+}
+
+func readBufferedEvent(file string) (*cloudevents.Event, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &cloudevents.Event{}
+	err = json.Unmarshal(content, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// getOutgoingEventQueueKey returns the key of the task the event belongs to, i.e. the ID of the triggered event, or the event ID otherwise
+func getOutgoingEventQueueKey(event cloudevents.Event) string {
+	if triggeredID, err := types.ToString(event.Extensions()["triggeredid"]); err == nil && triggeredID != "" {
+		return triggeredID
+	}
+
+	return event.ID()
+}
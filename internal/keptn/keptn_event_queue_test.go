@@ -0,0 +1,229 @@
+package keptn
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+type eventSenderMock struct {
+	mutex      sync.Mutex
+	failures   int
+	sentEvents []string
+	delivered  chan string
+}
+
+func newEventSenderMock(failures int) *eventSenderMock {
+	return &eventSenderMock{
+		failures:  failures,
+		delivered: make(chan string, 10),
+	}
+}
+
+func (m *eventSenderMock) send(event cloudevents.Event) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.failures > 0 {
+		m.failures--
+		return errors.New("connection refused")
+	}
+
+	m.sentEvents = append(m.sentEvents, event.ID())
+	m.delivered <- event.ID()
+	return nil
+}
+
+func (m *eventSenderMock) waitForDeliveries(t *testing.T, count int) []string {
+	var delivered []string
+	for len(delivered) < count {
+		select {
+		case eventID := <-m.delivered:
+			delivered = append(delivered, eventID)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d of %d events were delivered", len(delivered), count)
+		}
+	}
+	return delivered
+}
+
+func newTestOutgoingEvent(id string, triggeredID string, eventTime time.Time) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(id)
+	event.SetType("sh.keptn.event.get-sli.finished")
+	event.SetSource("dynatrace-service")
+	event.SetTime(eventTime)
+	event.SetExtension("triggeredid", triggeredID)
+	return event
+}
+
+func immediately() <-chan time.Time {
+	c := make(chan time.Time, 1)
+	c <- time.Now()
+	return c
+}
+
+func TestOutgoingEventQueue_RetriesAndKeepsOrderOfTask(t *testing.T) {
+	sender := newEventSenderMock(2)
+
+	var delays []time.Duration
+	queue := NewOutgoingEventQueue(sender.send, 5, time.Second, "")
+	queue.after = func(d time.Duration) <-chan time.Time {
+		delays = append(delays, d)
+		return immediately()
+	}
+	t.Cleanup(queue.Stop)
+
+	started := newTestOutgoingEvent("started", "triggered-1", time.Now())
+	finished := newTestOutgoingEvent("finished", "triggered-1", time.Now())
+
+	assert.False(t, queue.IsPending(started))
+	assert.True(t, queue.Enqueue(started))
+	assert.True(t, queue.IsPending(finished))
+	assert.True(t, queue.Enqueue(finished))
+
+	assert.Equal(t, []string{"started", "finished"}, sender.waitForDeliveries(t, 2))
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, time.Second}, delays)
+}
+
+func TestOutgoingEventQueue_Flush(t *testing.T) {
+	sender := newEventSenderMock(1)
+
+	queue := NewOutgoingEventQueue(sender.send, 3, time.Second, "")
+	queue.after = func(d time.Duration) <-chan time.Time { return immediately() }
+	t.Cleanup(queue.Stop)
+
+	queue.Enqueue(newTestOutgoingEvent("finished", "triggered-1", time.Now()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	assert.NoError(t, queue.Flush(ctx))
+	assert.Equal(t, []string{"finished"}, sender.sentEvents)
+}
+
+// Tests that events are no longer queued once the queue is flushed, while the events queued before are still delivered
+func TestOutgoingEventQueue_FlushRejectsEvents(t *testing.T) {
+	sender := newEventSenderMock(0)
+	release := make(chan time.Time)
+
+	queue := NewOutgoingEventQueue(sender.send, 3, time.Second, t.TempDir())
+	queue.after = func(d time.Duration) <-chan time.Time { return release }
+	t.Cleanup(queue.Stop)
+
+	assert.True(t, queue.Enqueue(newTestOutgoingEvent("started", "triggered-1", time.Now())))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, queue.Flush(ctx), context.DeadlineExceeded)
+
+	assert.False(t, queue.Enqueue(newTestOutgoingEvent("finished", "triggered-2", time.Now())))
+
+	close(release)
+	assert.NoError(t, queue.Flush(context.Background()))
+	assert.Equal(t, []string{"started"}, sender.sentEvents)
+
+	files, err := ioutil.ReadDir(queue.bufferDir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestOutgoingEventQueue_FlushTimesOut(t *testing.T) {
+	queue := NewOutgoingEventQueue(newEventSenderMock(0).send, 1, time.Hour, "")
+	queue.after = func(d time.Duration) <-chan time.Time { return nil }
+	t.Cleanup(queue.Stop)
+
+	queue.Enqueue(newTestOutgoingEvent("finished", "triggered-1", time.Now()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, queue.Flush(ctx), context.DeadlineExceeded)
+}
+
+func TestOutgoingEventQueue_Disabled(t *testing.T) {
+	queue := NewOutgoingEventQueue(newEventSenderMock(0).send, 0, time.Second, "")
+	assert.False(t, queue.Enqueue(newTestOutgoingEvent("finished", "triggered-1", time.Now())))
+}
+
+func TestOutgoingEventQueue_RedeliverBufferedEvents(t *testing.T) {
+	bufferDir := t.TempDir()
+	now := time.Now()
+
+	// a queue that cannot deliver any event before it is stopped, e.g. by a restart of the dynatrace-service
+	failingQueue := NewOutgoingEventQueue(func(event cloudevents.Event) error { return errors.New("connection refused") }, 1, time.Hour, bufferDir)
+	failingQueue.after = func(d time.Duration) <-chan time.Time { return nil }
+	failingQueue.Enqueue(newTestOutgoingEvent("finished-2", "triggered-2", now.Add(time.Second)))
+	failingQueue.Enqueue(newTestOutgoingEvent("finished-1", "triggered-1", now))
+	failingQueue.Stop()
+
+	files, err := ioutil.ReadDir(bufferDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	sender := newEventSenderMock(0)
+	queue := NewOutgoingEventQueue(sender.send, 1, time.Second, bufferDir)
+	queue.after = func(d time.Duration) <-chan time.Time { return immediately() }
+	t.Cleanup(queue.Stop)
+	queue.RedeliverBufferedEvents()
+
+	assert.ElementsMatch(t, []string{"finished-1", "finished-2"}, sender.waitForDeliveries(t, 2))
+
+	// delivered events are removed from the buffer
+	assert.Eventually(t, func() bool {
+		files, err := ioutil.ReadDir(bufferDir)
+		return err == nil && len(files) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestOutgoingEventQueue_PassesUndeliverableEventsToDeadLetterFunc(t *testing.T) {
+	sender := newEventSenderMock(3)
+	queue := NewOutgoingEventQueue(sender.send, 2, time.Second, "")
+	queue.after = func(d time.Duration) <-chan time.Time { return immediately() }
+	t.Cleanup(queue.Stop)
+
+	type deadLetter struct {
+		eventID  string
+		attempts int
+		err      error
+	}
+	deadLetters := make(chan deadLetter, 1)
+	queue.SetDeadLetterFunc(func(event cloudevents.Event, attempts int, err error) {
+		deadLetters <- deadLetter{eventID: event.ID(), attempts: attempts, err: err}
+	})
+
+	queue.Enqueue(newTestOutgoingEvent("finished-1", "triggered-1", time.Now()))
+	queue.Enqueue(newTestOutgoingEvent("finished-2", "triggered-1", time.Now()))
+
+	select {
+	case d := <-deadLetters:
+		assert.Equal(t, "finished-1", d.eventID)
+		assert.Equal(t, 2, d.attempts)
+		assert.Error(t, d.err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("undeliverable event was not dead-lettered")
+	}
+
+	// later events of the task are still delivered
+	assert.Equal(t, []string{"finished-2"}, sender.waitForDeliveries(t, 1))
+}
+
+func TestOutgoingEventQueue_StopKeepsBufferedEvents(t *testing.T) {
+	bufferDir := t.TempDir()
+
+	queue := NewOutgoingEventQueue(newEventSenderMock(0).send, 1, time.Hour, bufferDir)
+	queue.after = func(d time.Duration) <-chan time.Time { return nil }
+	queue.Enqueue(newTestOutgoingEvent("finished-1", "triggered-1", time.Now()))
+	queue.Stop()
+
+	files, err := ioutil.ReadDir(bufferDir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+	assert.True(t, queue.IsPending(newTestOutgoingEvent("finished-2", "triggered-1", time.Now())))
+}
@@ -0,0 +1,84 @@
+package keptn
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/keptn/go-utils/pkg/api/models"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// LocalEventClientBase retrieves events from the files in a local directory instead of Keptn's datastore, i.e. the events written by
+// the LocalEventSender and those placed there manually, e.g. the triggered events of a sequence. It is used when running locally, see env.Config.RunLocalEnabled
+type LocalEventClientBase struct {
+	directory string
+}
+
+// NewLocalEventClientBase creates a new LocalEventClientBase reading events from the given directory
+func NewLocalEventClientBase(directory string) *LocalEventClientBase {
+	return &LocalEventClientBase{
+		directory: directory,
+	}
+}
+
+// GetEvents returns the events of the directory matching the filter, the most recent first as named by the LocalEventSender. Files that are no events are skipped
+func (c *LocalEventClientBase) GetEvents(filter *keptnapi.EventFilter) ([]*models.KeptnContextExtendedCE, error) {
+	paths, err := filepath.Glob(filepath.Join(c.directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(paths)))
+
+	var events []*models.KeptnContextExtendedCE
+	for _, path := range paths {
+		event, err := readLocalEvent(path)
+		if err != nil {
+			log.WithError(err).WithField("path", path).Debug("Skipping local file that is no event")
+			continue
+		}
+
+		if matchesEventFilter(event, filter) {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func readLocalEvent(path string) (*models.KeptnContextExtendedCE, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.KeptnContextExtendedCE{}
+	err = json.Unmarshal(content, event)
+	if err != nil {
+		return nil, err
+	}
+	if event.Type == nil {
+		return nil, errors.New("file contains no event type")
+	}
+	return event, nil
+}
+
+func matchesEventFilter(event *models.KeptnContextExtendedCE, filter *keptnapi.EventFilter) bool {
+	eventData := &keptnv2.EventData{}
+	_ = keptnv2.Decode(event.Data, eventData)
+
+	return matchesFilterValue(*event.Type, filter.EventType) &&
+		matchesFilterValue(event.Shkeptncontext, filter.KeptnContext) &&
+		matchesFilterValue(event.ID, filter.EventID) &&
+		matchesFilterValue(eventData.Project, filter.Project) &&
+		matchesFilterValue(eventData.Stage, filter.Stage) &&
+		matchesFilterValue(eventData.Service, filter.Service)
+}
+
+// matchesFilterValue returns whether the value matches the value of the filter, which matches any value if it is empty
+func matchesFilterValue(value string, filterValue string) bool {
+	return filterValue == "" || value == filterValue
+}
@@ -0,0 +1,60 @@
+package keptn
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalEventClientBase_GetEventsReturnsEventsWrittenBySender(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-events")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	now := time.Unix(1633089600, 0)
+	sender := NewLocalEventSender(dir)
+	sender.now = func() time.Time { return now }
+
+	sendEvent := func(id string, eventType string, stage string) {
+		event := cloudevents.NewEvent()
+		event.SetID(id)
+		event.SetType(eventType)
+		event.SetSource("shipyard-controller")
+		event.SetExtension("shkeptncontext", "ctx-1")
+		assert.NoError(t, event.SetData(cloudevents.ApplicationJSON, keptnv2.EventData{Project: "sockshop", Stage: stage, Service: "carts"}))
+		assert.NoError(t, sender.SendEvent(event))
+		now = now.Add(time.Second)
+	}
+	sendEvent("1", keptnv2.GetTriggeredEventType(keptnv2.DeploymentTaskName), "staging")
+	sendEvent("2", keptnv2.GetTriggeredEventType(keptnv2.DeploymentTaskName), "production")
+	sendEvent("3", keptnv2.GetTriggeredEventType(keptnv2.DeploymentTaskName), "staging")
+	sendEvent("4", keptnv2.GetFinishedEventType(keptnv2.DeploymentTaskName), "staging")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "notes.json"), []byte(`{"note": "no event"}`), 0644))
+
+	events, err := NewLocalEventClientBase(dir).GetEvents(&keptnapi.EventFilter{
+		Project:      "sockshop",
+		Stage:        "staging",
+		EventType:    keptnv2.GetTriggeredEventType(keptnv2.DeploymentTaskName),
+		KeptnContext: "ctx-1",
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "3", events[0].ID, "the most recent event comes first")
+		assert.Equal(t, "1", events[1].ID)
+	}
+}
+
+func TestLocalEventClientBase_GetEventsWithoutDirectory(t *testing.T) {
+	events, err := NewLocalEventClientBase(filepath.Join(os.TempDir(), "does-not-exist")).GetEvents(&keptnapi.EventFilter{Project: "sockshop"})
+
+	assert.NoError(t, err)
+	assert.Empty(t, events)
+}
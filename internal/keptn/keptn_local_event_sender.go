@@ -0,0 +1,48 @@
+package keptn
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// LocalEventSender writes events to files in a local directory instead of sending them to Keptn. It is used when running locally, see env.Config.RunLocalEnabled
+type LocalEventSender struct {
+	directory string
+	now       func() time.Time
+}
+
+// NewLocalEventSender creates a new LocalEventSender writing events to the given directory, which is created if it does not exist
+func NewLocalEventSender(directory string) *LocalEventSender {
+	return &LocalEventSender{
+		directory: directory,
+		now:       time.Now,
+	}
+}
+
+// SendEvent writes the event to a file
+func (s *LocalEventSender) SendEvent(event cloudevents.Event) error {
+	return s.Send(context.Background(), event)
+}
+
+// Send writes the event to a file named after the current time, the type and the ID of the event, so that the files are ordered by the time they were sent
+func (s *LocalEventSender) Send(ctx context.Context, event cloudevents.Event) error {
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	path := filepath.Join(s.directory, fmt.Sprintf("%d-%s-%s.json", s.now().UnixNano(), event.Type(), event.ID()))
+	err = writeLocalFile(path, data)
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{"eventType": event.Type(), "path": path}).Info("Event written to local file")
+	return nil
+}
@@ -0,0 +1,31 @@
+package keptn
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalEventSender_WritesEventsToFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-events")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sender := NewLocalEventSender(filepath.Join(dir, "events"))
+	sender.now = func() time.Time { return time.Unix(1633089600, 0) }
+
+	event := cloudevents.NewEvent()
+	event.SetID("7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+	event.SetType("sh.keptn.event.get-sli.finished")
+	event.SetSource("dynatrace-service")
+	assert.NoError(t, sender.SendEvent(event))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "events", "1633089600000000000-sh.keptn.event.get-sli.finished-7c2c890f-b3ac-4caa-8922-f44d2aa54ec9.json"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), `"type": "sh.keptn.event.get-sli.finished"`)
+}
@@ -0,0 +1,16 @@
+package keptn
+
+// LocalProjectClient is an implementation of the ProjectClientInterface not calling Keptn's shipyard controller, every project exists.
+// It is used when running locally, see env.Config.RunLocalEnabled
+type LocalProjectClient struct {
+}
+
+// NewLocalProjectClient creates a new LocalProjectClient
+func NewLocalProjectClient() *LocalProjectClient {
+	return &LocalProjectClient{}
+}
+
+// AssertProjectExists returns nil, as every project exists
+func (c *LocalProjectClient) AssertProjectExists(projectName string) error {
+	return nil
+}
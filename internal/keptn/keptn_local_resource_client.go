@@ -2,17 +2,23 @@ package keptn
 
 import (
 	"fmt"
-	log "github.com/sirupsen/logrus"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
+
+	log "github.com/sirupsen/logrus"
 )
 
 // is the local test resource path for the dynatrace.conf.yaml
 const localConfigFilename = "dynatrace/_dynatrace.conf.yaml"
 
+// LocalResourceClient is an implementation of the ConfigResourceClientInterface reading and writing resources from and to the local disk
+// instead of Keptn's configuration service. It is used when running locally, see env.Config.RunLocalEnabled
 type LocalResourceClient struct {
 }
 
+// NewLocalResourceClient creates a new LocalResourceClient
 func NewLocalResourceClient() *LocalResourceClient {
 	return &LocalResourceClient{}
 }
@@ -21,6 +27,7 @@ func (c *LocalResourceClient) GetDynatraceConfig(project string, stage string, s
 	return c.GetResource(project, stage, service, configFilename)
 }
 
+// GetResource reads the resource from the local disk, relative to the working directory
 func (c *LocalResourceClient) GetResource(project string, stage string, service string, resourceURI string) (string, error) {
 	// hack to retrieve the local config file
 	if resourceURI == configFilename {
@@ -36,7 +43,7 @@ func (c *LocalResourceClient) GetResource(project string, stage string, service
 				"stage":       stage,
 				"project":     project,
 			}).Info("File not found locally")
-		return "", nil
+		return "", &ResourceNotFoundError{uri: resourceURI, project: project, stage: stage, service: service}
 	}
 
 	log.WithField("resourceURI", resourceURI).Info("Loaded LOCAL file")
@@ -47,7 +54,7 @@ func (c *LocalResourceClient) GetProjectResource(project string, resourceURI str
 	return c.GetResource(project, "", "", strings.ToLower(strings.ReplaceAll(resourceURI, "dynatrace/", "../../../dynatrace/project_")))
 }
 
-func (c *LocalResourceClient) getStageResource(project string, stage string, resourceURI string) (string, error) {
+func (c *LocalResourceClient) GetStageResource(project string, stage string, resourceURI string) (string, error) {
 	return c.GetResource(project, stage, "", strings.ToLower(strings.ReplaceAll(resourceURI, "dynatrace/", "../../../dynatrace/stage_")))
 }
 
@@ -55,13 +62,43 @@ func (c *LocalResourceClient) GetServiceResource(project string, stage string, s
 	return c.GetResource(project, stage, service, strings.ToLower(strings.ReplaceAll(resourceURI, "dynatrace/", "../../../dynatrace/service_")))
 }
 
-func (c *LocalResourceClient) UploadKeptnResource(contentToUpload []byte, remoteResourceURI string) error {
-	// if we run in a runlocal mode we are just getting the file from the local disk
-	err := ioutil.WriteFile(remoteResourceURI, contentToUpload, 0644)
-	if err != nil {
-		return fmt.Errorf("couldnt write local file %s: %v", remoteResourceURI, err)
+// UploadResource writes the resource to the local disk, relative to the working directory
+func (c *LocalResourceClient) UploadResource(contentToUpload []byte, remoteResourceURI string, project string, stage string, service string) error {
+	return c.UploadResources([]Resource{{URI: remoteResourceURI, Content: contentToUpload}}, project, stage, service)
+}
+
+// UploadResources writes the resources to the local disk, relative to the working directory
+func (c *LocalResourceClient) UploadResources(resources []Resource, project string, stage string, service string) error {
+	for _, resource := range resources {
+		// if we run in a runlocal mode we are just writing the file to the local disk
+		err := writeLocalFile(resource.URI, resource.Content)
+		if err != nil {
+			return &ResourceUploadFailedError{
+				ResourceError{
+					uri:     resource.URI,
+					project: project,
+					stage:   stage,
+					service: service,
+				},
+				err.Error(),
+			}
+		}
+
+		log.WithField("remoteResourceURI", resource.URI).Info("Local file written")
+	}
+	return nil
+}
+
+func writeLocalFile(path string, content []byte) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("couldnt create local directory %s: %v", dir, err)
+		}
 	}
 
-	log.WithField("remoteResourceURI", remoteResourceURI).Info("Local file written")
+	err := ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return fmt.Errorf("couldnt write local file %s: %v", path, err)
+	}
 	return nil
 }
@@ -0,0 +1,31 @@
+package keptn
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalResourceClient_UploadAndGetResource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "local-resources")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client := NewLocalResourceClient()
+	resourceURI := filepath.Join(dir, "dynatrace", "sli.yaml")
+
+	_, err = client.GetResource("sockshop", "staging", "carts", resourceURI)
+	var rnfErr *ResourceNotFoundError
+	assert.True(t, errors.As(err, &rnfErr))
+
+	err = client.UploadResources([]Resource{{URI: resourceURI, Content: []byte("spec_version: '1.0'")}}, "sockshop", "staging", "carts")
+	assert.NoError(t, err)
+
+	content, err := client.GetResource("sockshop", "staging", "carts", resourceURI)
+	assert.NoError(t, err)
+	assert.Equal(t, "spec_version: '1.0'", content)
+}
@@ -0,0 +1,37 @@
+package keptn
+
+import (
+	apimodels "github.com/keptn/go-utils/pkg/api/models"
+	log "github.com/sirupsen/logrus"
+)
+
+// LocalServiceClient is an implementation of the ServiceClientInterface not calling Keptn's shipyard controller. Projects have no services,
+// every service asked for exists and creating services is only logged. It is used when running locally, see env.Config.RunLocalEnabled
+type LocalServiceClient struct {
+}
+
+// NewLocalServiceClient creates a new LocalServiceClient
+func NewLocalServiceClient() *LocalServiceClient {
+	return &LocalServiceClient{}
+}
+
+// GetServiceNamesPerStage returns no services
+func (c *LocalServiceClient) GetServiceNamesPerStage(project string) (map[string][]string, error) {
+	return map[string][]string{}, nil
+}
+
+// GetServiceNamesInAllStages returns no services
+func (c *LocalServiceClient) GetServiceNamesInAllStages(project string) ([]string, error) {
+	return []string{}, nil
+}
+
+// GetService returns the service without any details
+func (c *LocalServiceClient) GetService(project string, stage string, service string) (*apimodels.Service, error) {
+	return &apimodels.Service{ServiceName: service}, nil
+}
+
+// CreateServiceInProject only logs the service
+func (c *LocalServiceClient) CreateServiceInProject(project string, service string) error {
+	log.WithFields(log.Fields{"project": project, "service": service}).Info("Not creating service when running locally")
+	return nil
+}
@@ -0,0 +1,150 @@
+package keptn
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+var defaultProjectMetadataCache *CachingProjectMetadataClient
+var defaultProjectMetadataCacheOnce sync.Once
+
+type cachedProjectMetadata struct {
+	shipyard          *keptnv2.Shipyard
+	shipyardFetchedAt time.Time
+
+	hasExistence       bool
+	existenceErr       error
+	existenceFetchedAt time.Time
+}
+
+// CachingProjectMetadataClient caches the shipyard and the existence of projects for a short time, so that bursts of events for the same project
+// do not retrieve them from the configuration service and the shipyard controller over and over again.
+// Entries of a project are removed when it is created or deleted
+type CachingProjectMetadataClient struct {
+	shipyardClient ShipyardClientInterface
+	projectClient  ProjectClientInterface
+	ttl            time.Duration
+	now            func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]*cachedProjectMetadata
+	// generations counts the invalidations per project, so that results retrieved while the project was invalidated are not stored
+	generations map[string]uint64
+}
+
+// GetDefaultCachingProjectMetadataClient returns the CachingProjectMetadataClient shared by all event handlers.
+// It uses the default shipyard and project clients and the TTL of the configuration of the first call
+func GetDefaultCachingProjectMetadataClient(cfg *env.Config) *CachingProjectMetadataClient {
+	defaultProjectMetadataCacheOnce.Do(func() {
+		defaultProjectMetadataCache = NewCachingProjectMetadataClient(
+			NewDefaultShipyardClient(cfg),
+			NewDefaultProjectClient(cfg),
+			time.Duration(cfg.KeptnProjectCacheTTL)*time.Second)
+	})
+
+	return defaultProjectMetadataCache
+}
+
+// NewCachingProjectMetadataClient creates a new CachingProjectMetadataClient. If ttl is 0 or less, nothing is cached
+func NewCachingProjectMetadataClient(shipyardClient ShipyardClientInterface, projectClient ProjectClientInterface, ttl time.Duration) *CachingProjectMetadataClient {
+	return &CachingProjectMetadataClient{
+		shipyardClient: shipyardClient,
+		projectClient:  projectClient,
+		ttl:            ttl,
+		now:            time.Now,
+		entries:        make(map[string]*cachedProjectMetadata),
+		generations:    make(map[string]uint64),
+	}
+}
+
+// GetShipyard returns the cached shipyard of the project or retrieves it if the cached one expired. Errors are not cached
+func (c *CachingProjectMetadataClient) GetShipyard(project string) (*keptnv2.Shipyard, error) {
+	if c.ttl <= 0 {
+		return c.shipyardClient.GetShipyard(project)
+	}
+
+	c.mutex.Lock()
+	entry, found := c.entries[project]
+	if found && entry.shipyard != nil && c.now().Sub(entry.shipyardFetchedAt) < c.ttl {
+		c.mutex.Unlock()
+		return entry.shipyard, nil
+	}
+	generation := c.generations[project]
+	c.mutex.Unlock()
+
+	shipyard, err := c.shipyardClient.GetShipyard(project)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generations[project] == generation {
+		entry = c.getOrCreateEntry(project)
+		entry.shipyard = shipyard
+		entry.shipyardFetchedAt = c.now()
+	}
+
+	return shipyard, nil
+}
+
+// AssertProjectExists returns the cached result of checking whether the project exists or checks it again if the cached result expired.
+// Only the results that the project exists or does not exist are cached, other errors are retried with the next call
+func (c *CachingProjectMetadataClient) AssertProjectExists(projectName string) error {
+	if c.ttl <= 0 {
+		return c.projectClient.AssertProjectExists(projectName)
+	}
+
+	c.mutex.Lock()
+	entry, found := c.entries[projectName]
+	if found && entry.hasExistence && c.now().Sub(entry.existenceFetchedAt) < c.ttl {
+		c.mutex.Unlock()
+		return entry.existenceErr
+	}
+	generation := c.generations[projectName]
+	c.mutex.Unlock()
+
+	err := c.projectClient.AssertProjectExists(projectName)
+	if err != nil && common.GetErrorType(err) != common.UserConfigurationErrorType {
+		return err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.generations[projectName] == generation {
+		entry = c.getOrCreateEntry(projectName)
+		entry.hasExistence = true
+		entry.existenceErr = err
+		entry.existenceFetchedAt = c.now()
+	}
+
+	return err
+}
+
+// InvalidateProject removes the cached shipyard and existence of the project, e.g. because it was created or deleted.
+// Results of retrievals still in progress are not cached either
+func (c *CachingProjectMetadataClient) InvalidateProject(project string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.generations[project]++
+	if _, found := c.entries[project]; found {
+		log.WithField("project", project).Debug("Invalidating cached project metadata")
+		delete(c.entries, project)
+	}
+}
+
+// getOrCreateEntry returns the entry of the project, creating it if necessary. The mutex must be held by the caller
+func (c *CachingProjectMetadataClient) getOrCreateEntry(project string) *cachedProjectMetadata {
+	entry, found := c.entries[project]
+	if !found {
+		entry = &cachedProjectMetadata{}
+		c.entries[project] = entry
+	}
+	return entry
+}
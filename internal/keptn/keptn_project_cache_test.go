@@ -0,0 +1,178 @@
+package keptn
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+type shipyardClientMock struct {
+	shipyard *keptnv2.Shipyard
+	err      error
+	calls    int
+	// during is called while the shipyard is retrieved, if set
+	during func()
+}
+
+func (m *shipyardClientMock) GetShipyard(project string) (*keptnv2.Shipyard, error) {
+	m.calls++
+	if m.during != nil {
+		m.during()
+	}
+	return m.shipyard, m.err
+}
+
+type projectClientMock struct {
+	err   error
+	calls int
+	// during is called while the existence of the project is checked, if set
+	during func()
+}
+
+func (m *projectClientMock) AssertProjectExists(projectName string) error {
+	m.calls++
+	if m.during != nil {
+		m.during()
+	}
+	return m.err
+}
+
+func TestCachingProjectMetadataClient_GetShipyard(t *testing.T) {
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	shipyardMock := &shipyardClientMock{shipyard: &keptnv2.Shipyard{ApiVersion: "spec.keptn.sh/0.2.2"}}
+	cache := NewCachingProjectMetadataClient(shipyardMock, &projectClientMock{}, 10*time.Second)
+	cache.now = func() time.Time { return now }
+
+	shipyard, err := cache.GetShipyard("sockshop")
+	assert.NoError(t, err)
+	assert.Equal(t, "spec.keptn.sh/0.2.2", shipyard.ApiVersion)
+	assert.Equal(t, 1, shipyardMock.calls)
+
+	// within the TTL the cached shipyard is returned
+	shipyardMock.shipyard = &keptnv2.Shipyard{ApiVersion: "spec.keptn.sh/0.2.3"}
+	now = now.Add(5 * time.Second)
+	shipyard, _ = cache.GetShipyard("sockshop")
+	assert.Equal(t, "spec.keptn.sh/0.2.2", shipyard.ApiVersion)
+	assert.Equal(t, 1, shipyardMock.calls)
+
+	// other projects are cached separately
+	_, _ = cache.GetShipyard("podtato-head")
+	assert.Equal(t, 2, shipyardMock.calls)
+
+	// after the TTL the changed shipyard is picked up
+	now = now.Add(10 * time.Second)
+	shipyard, _ = cache.GetShipyard("sockshop")
+	assert.Equal(t, "spec.keptn.sh/0.2.3", shipyard.ApiVersion)
+	assert.Equal(t, 3, shipyardMock.calls)
+}
+
+func TestCachingProjectMetadataClient_DoesNotCacheShipyardErrors(t *testing.T) {
+	shipyardMock := &shipyardClientMock{err: errors.New("connection refused")}
+	cache := NewCachingProjectMetadataClient(shipyardMock, &projectClientMock{}, 10*time.Second)
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.GetShipyard("sockshop")
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 2, shipyardMock.calls)
+}
+
+func TestCachingProjectMetadataClient_AssertProjectExists(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+	}{
+		{
+			name:      "existing project is cached",
+			wantCalls: 1,
+		},
+		{
+			name:      "missing project is cached",
+			err:       common.NewUserConfigurationError(fmt.Errorf("project sockshop does not exist")),
+			wantCalls: 1,
+		},
+		{
+			name:      "other errors are not cached",
+			err:       common.NewKeptnAPIError(fmt.Errorf("could not get project sockshop")),
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			projectMock := &projectClientMock{err: tt.err}
+			cache := NewCachingProjectMetadataClient(&shipyardClientMock{}, projectMock, 10*time.Second)
+
+			for i := 0; i < 2; i++ {
+				assert.Equal(t, tt.err, cache.AssertProjectExists("sockshop"))
+			}
+			assert.Equal(t, tt.wantCalls, projectMock.calls)
+		})
+	}
+}
+
+func TestCachingProjectMetadataClient_InvalidateProject(t *testing.T) {
+	shipyardMock := &shipyardClientMock{shipyard: &keptnv2.Shipyard{}}
+	projectMock := &projectClientMock{err: common.NewUserConfigurationError(fmt.Errorf("project sockshop does not exist"))}
+	cache := NewCachingProjectMetadataClient(shipyardMock, projectMock, 10*time.Second)
+
+	_, _ = cache.GetShipyard("sockshop")
+	assert.Error(t, cache.AssertProjectExists("sockshop"))
+
+	// once the project was created, it is checked again
+	projectMock.err = nil
+	cache.InvalidateProject("sockshop")
+
+	_, _ = cache.GetShipyard("sockshop")
+	assert.NoError(t, cache.AssertProjectExists("sockshop"))
+	assert.Equal(t, 2, shipyardMock.calls)
+	assert.Equal(t, 2, projectMock.calls)
+}
+
+// Tests that results retrieved while the project is invalidated, e.g. because it is deleted, are returned but not cached
+func TestCachingProjectMetadataClient_InvalidateProjectDuringRetrieval(t *testing.T) {
+	shipyardMock := &shipyardClientMock{shipyard: &keptnv2.Shipyard{ApiVersion: "spec.keptn.sh/0.2.2"}}
+	projectMock := &projectClientMock{}
+	cache := NewCachingProjectMetadataClient(shipyardMock, projectMock, 10*time.Second)
+	shipyardMock.during = func() { cache.InvalidateProject("sockshop") }
+	projectMock.during = func() { cache.InvalidateProject("sockshop") }
+
+	shipyard, err := cache.GetShipyard("sockshop")
+	assert.NoError(t, err)
+	assert.Equal(t, "spec.keptn.sh/0.2.2", shipyard.ApiVersion)
+	assert.NoError(t, cache.AssertProjectExists("sockshop"))
+
+	// the outdated results were not cached, so they are retrieved again
+	shipyardMock.during = nil
+	projectMock.during = nil
+	projectMock.err = common.NewUserConfigurationError(fmt.Errorf("project sockshop does not exist"))
+	_, _ = cache.GetShipyard("sockshop")
+	assert.Error(t, cache.AssertProjectExists("sockshop"))
+	assert.Equal(t, 2, shipyardMock.calls)
+	assert.Equal(t, 2, projectMock.calls)
+
+	// results retrieved without invalidation are cached
+	_, _ = cache.GetShipyard("sockshop")
+	assert.Error(t, cache.AssertProjectExists("sockshop"))
+	assert.Equal(t, 2, shipyardMock.calls)
+	assert.Equal(t, 2, projectMock.calls)
+}
+
+func TestCachingProjectMetadataClient_Disabled(t *testing.T) {
+	shipyardMock := &shipyardClientMock{shipyard: &keptnv2.Shipyard{}}
+	projectMock := &projectClientMock{}
+	cache := NewCachingProjectMetadataClient(shipyardMock, projectMock, 0)
+
+	for i := 0; i < 2; i++ {
+		_, _ = cache.GetShipyard("sockshop")
+		_ = cache.AssertProjectExists("sockshop")
+	}
+	assert.Equal(t, 2, shipyardMock.calls)
+	assert.Equal(t, 2, projectMock.calls)
+}
@@ -3,6 +3,7 @@ package keptn
 import (
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn/go-utils/pkg/api/models"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 )
@@ -15,9 +16,14 @@ type ProjectClient struct {
 	client *keptnapi.ProjectHandler
 }
 
-func NewDefaultProjectClient() *ProjectClient {
+// NewDefaultProjectClient creates a ProjectClient calling the shipyard controller or, when running locally, a LocalProjectClient
+func NewDefaultProjectClient(cfg *env.Config) ProjectClientInterface {
+	if cfg.RunLocalEnabled {
+		return NewLocalProjectClient()
+	}
+
 	return NewProjectClient(
-		keptnapi.NewProjectHandler(common.GetShipyardControllerURL()))
+		newProjectHandler(cfg))
 }
 
 func NewProjectClient(client *keptnapi.ProjectHandler) *ProjectClient {
@@ -35,10 +41,10 @@ func (c *ProjectClient) AssertProjectExists(projectName string) error {
 
 	if err != nil {
 		if err.Code == 404 {
-			return fmt.Errorf("project %s does not exist", projectName)
+			return common.NewUserConfigurationError(fmt.Errorf("project %s does not exist", projectName))
 		}
 
-		return fmt.Errorf("could not get project %s: %s", projectName, err.GetMessage())
+		return common.NewKeptnAPIError(fmt.Errorf("could not get project %s: %s", projectName, err.GetMessage()))
 	}
 
 	if project == nil {
@@ -1,10 +1,15 @@
 package keptn
 
 import (
-	"encoding/json"
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io/ioutil"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptn "github.com/keptn/go-utils/pkg/lib"
 	"gopkg.in/yaml.v2"
 )
@@ -22,37 +27,57 @@ type DashboardResourceReaderInterface interface {
 type DashboardResourceWriterInterface interface {
 	UploadDashboard(project string, stage string, service string, dashboard *dynatrace.Dashboard) error
 }
+type DashboardSLIAndSLOResourceWriterInterface interface {
+	UploadDashboardSLIAndSLOs(project string, stage string, service string, dashboard *dynatrace.Dashboard, sli *dynatrace.SLI, slos *keptn.ServiceLevelObjectives) error
+}
 type ResourceClientInterface interface {
 	SLOResourceReaderInterface
 	SLIAndSLOResourceWriterInterface
 	DashboardResourceReaderInterface
 	DashboardResourceWriterInterface
+	DashboardSLIAndSLOResourceWriterInterface
 }
 
 type DynatraceConfigResourceClientInterface interface {
 	GetDynatraceConfig(project string, stage string, service string) (string, error)
 }
 
+type ProblemRoutingResourceReaderInterface interface {
+	GetProblemRouting(project string) (string, error)
+}
+
+type RemediationResourceReaderInterface interface {
+	GetRemediation(project string, stage string, service string) (string, error)
+}
+
 const sloFilename = "slo.yaml"
 const sliFilename = "dynatrace/sli.yaml"
 const dashboardFilename = "dynatrace/dashboard.json"
+const gzipDashboardFilename = "dynatrace/dashboard.json.gz"
 const configFilename = "dynatrace/dynatrace.conf.yaml"
+const problemRoutingFilename = "dynatrace/problem-routing.yaml"
+const remediationFilename = "remediation.yaml"
 
 // ResourceClient is the default implementation for the *ResourceClientInterfaces using a ConfigResourceClientInterface
 type ResourceClient struct {
 	client ConfigResourceClientInterface
+
+	// dashboardStorage defines how dashboards are stored, see env.Config.DashboardStorage
+	dashboardStorage string
 }
 
 // NewDefaultResourceClient creates a new ResourceClient with a default Keptn resource handler for the configuration service
-func NewDefaultResourceClient() *ResourceClient {
+func NewDefaultResourceClient(cfg *env.Config) *ResourceClient {
 	return NewResourceClient(
-		NewDefaultConfigResourceClient())
+		NewDefaultConfigResourceClient(cfg),
+		cfg.DashboardStorage)
 }
 
-// NewResourceClient creates a new ResourceClient with a Keptn resource handler for the configuration service
-func NewResourceClient(client ConfigResourceClientInterface) *ResourceClient {
+// NewResourceClient creates a new ResourceClient with a Keptn resource handler for the configuration service, storing dashboards as defined by dashboardStorage
+func NewResourceClient(client ConfigResourceClientInterface, dashboardStorage string) *ResourceClient {
 	return &ResourceClient{
-		client: client,
+		client:           client,
+		dashboardStorage: dashboardStorage,
 	}
 }
 
@@ -65,7 +90,7 @@ func (rc *ResourceClient) GetSLOs(project string, stage string, service string)
 	slos := &keptn.ServiceLevelObjectives{}
 	err = yaml.Unmarshal([]byte(resource), slos)
 	if err != nil {
-		return nil, errors.New("invalid SLO file format")
+		return nil, common.NewUserConfigurationError(errors.New("invalid SLO file format"))
 	}
 
 	return slos, nil
@@ -81,17 +106,106 @@ func (rc *ResourceClient) UploadSLOs(project string, stage string, service strin
 	return rc.client.UploadResource(yamlAsByteArray, sloFilename, project, stage, service)
 }
 
+// UploadDashboardSLIAndSLOs uploads the dashboard, the SLIs and the SLOs in a single request, so that they are stored in a single commit.
+// Any of them may be nil, in which case it is not uploaded
+func (rc *ResourceClient) UploadDashboardSLIAndSLOs(project string, stage string, service string, dashboard *dynatrace.Dashboard, sli *dynatrace.SLI, slos *keptn.ServiceLevelObjectives) error {
+	var resources []Resource
+	if dashboard != nil && rc.dashboardStorage != env.NoDashboardStorage {
+		dashboardResource, err := rc.createDashboardResource(dashboard)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, *dashboardResource)
+	}
+
+	if sli != nil {
+		yamlAsByteArray, err := yaml.Marshal(sli)
+		if err != nil {
+			return fmt.Errorf("could not convert dashboardSLI to YAML: %s", err)
+		}
+		resources = append(resources, Resource{URI: sliFilename, Content: yamlAsByteArray})
+	}
+
+	if slos != nil {
+		yamlAsByteArray, err := yaml.Marshal(slos)
+		if err != nil {
+			return fmt.Errorf("could not convert SLOs to YAML: %s", err)
+		}
+		resources = append(resources, Resource{URI: sloFilename, Content: yamlAsByteArray})
+	}
+
+	return rc.client.UploadResources(resources, project, stage, service)
+}
+
+// GetDashboard returns the JSON of the stored dashboard. If dashboards are stored compressed, but no compressed dashboard exists yet,
+// an uncompressed dashboard stored before is returned
 func (rc *ResourceClient) GetDashboard(project string, stage string, service string) (string, error) {
+	if rc.dashboardStorage == env.GzipDashboardStorage {
+		compressedDashboard, err := rc.client.GetServiceResource(project, stage, service, gzipDashboardFilename)
+		if err == nil {
+			return decompressDashboard(compressedDashboard)
+		}
+
+		var rnfErr *ResourceNotFoundError
+		if !errors.As(err, &rnfErr) {
+			return "", err
+		}
+	}
+
 	return rc.client.GetServiceResource(project, stage, service, dashboardFilename)
 }
 
+// UploadDashboard stores the dashboard unless storing dashboards is disabled
 func (rc *ResourceClient) UploadDashboard(project string, stage string, service string, dashboard *dynatrace.Dashboard) error {
-	jsonAsByteArray, err := json.MarshalIndent(dashboard, "", "  ")
+	if rc.dashboardStorage == env.NoDashboardStorage {
+		return nil
+	}
+
+	dashboardResource, err := rc.createDashboardResource(dashboard)
+	if err != nil {
+		return err
+	}
+
+	return rc.client.UploadResource(dashboardResource.Content, dashboardResource.URI, project, stage, service)
+}
+
+// createDashboardResource creates the resource the dashboard is stored as, i.e. dashboard.json or dashboard.json.gz
+func (rc *ResourceClient) createDashboardResource(dashboard *dynatrace.Dashboard) (*Resource, error) {
+	jsonAsByteArray, err := dashboard.ToJSON()
 	if err != nil {
-		return fmt.Errorf("could not convert dashboard to JSON: %s", err)
+		return nil, fmt.Errorf("could not convert dashboard to JSON: %s", err)
 	}
 
-	return rc.client.UploadResource(jsonAsByteArray, dashboardFilename, project, stage, service)
+	if rc.dashboardStorage != env.GzipDashboardStorage {
+		return &Resource{URI: dashboardFilename, Content: jsonAsByteArray}, nil
+	}
+
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	_, err = writer.Write(jsonAsByteArray)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not compress dashboard: %s", err)
+	}
+
+	return &Resource{URI: gzipDashboardFilename, Content: buffer.Bytes()}, nil
+}
+
+func decompressDashboard(compressedDashboard string) (string, error) {
+	reader, err := gzip.NewReader(bytes.NewReader([]byte(compressedDashboard)))
+	if err != nil {
+		return "", fmt.Errorf("could not decompress dashboard: %w", err)
+	}
+	defer reader.Close()
+
+	jsonAsByteArray, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("could not decompress dashboard: %w", err)
+	}
+
+	return string(jsonAsByteArray), nil
 }
 
 func (rc *ResourceClient) UploadSLI(project string, stage string, service string, sli *dynatrace.SLI) error {
@@ -106,3 +220,12 @@ func (rc *ResourceClient) UploadSLI(project string, stage string, service string
 func (rc *ResourceClient) GetDynatraceConfig(project string, stage string, service string) (string, error) {
 	return rc.client.GetResource(project, stage, service, configFilename)
 }
+
+func (rc *ResourceClient) GetProblemRouting(project string) (string, error) {
+	return rc.client.GetProjectResource(project, problemRoutingFilename)
+}
+
+// GetRemediation returns the remediation.yaml of the service, stage or project
+func (rc *ResourceClient) GetRemediation(project string, stage string, service string) (string, error) {
+	return rc.client.GetResource(project, stage, service, remediationFilename)
+}
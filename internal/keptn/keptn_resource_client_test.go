@@ -0,0 +1,157 @@
+package keptn
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapConfigResourceClient stores service resources by URI
+type mapConfigResourceClient struct {
+	resources map[string]string
+	uploads   int
+}
+
+func (c *mapConfigResourceClient) GetResource(project string, stage string, service string, resourceURI string) (string, error) {
+	return c.GetServiceResource(project, stage, service, resourceURI)
+}
+
+func (c *mapConfigResourceClient) GetProjectResource(project string, resourceURI string) (string, error) {
+	return c.GetServiceResource(project, "", "", resourceURI)
+}
+
+func (c *mapConfigResourceClient) GetStageResource(project string, stage string, resourceURI string) (string, error) {
+	return c.GetServiceResource(project, stage, "", resourceURI)
+}
+
+func (c *mapConfigResourceClient) GetServiceResource(project string, stage string, service string, resourceURI string) (string, error) {
+	resource, ok := c.resources[resourceURI]
+	if !ok {
+		return "", &ResourceNotFoundError{uri: resourceURI, project: project, stage: stage, service: service}
+	}
+	return resource, nil
+}
+
+func (c *mapConfigResourceClient) UploadResource(contentToUpload []byte, remoteResourceURI string, project string, stage string, service string) error {
+	c.resources[remoteResourceURI] = string(contentToUpload)
+	return nil
+}
+
+func (c *mapConfigResourceClient) UploadResources(resources []Resource, project string, stage string, service string) error {
+	c.uploads++
+	for _, resource := range resources {
+		c.resources[resource.URI] = string(resource.Content)
+	}
+	return nil
+}
+
+func createTestDashboard() *dynatrace.Dashboard {
+	return &dynatrace.Dashboard{
+		ID: "12345678-1111-4444-8888-123456789012",
+		DashboardMetadata: dynatrace.DashboardMetadata{
+			Name: "KQG;project=sockshop;stage=staging;service=carts",
+		},
+		Tiles: []dynatrace.Tile{
+			{
+				Name:       "Markdown",
+				TileType:   "MARKDOWN",
+				Markdown:   "KQG.Total.Pass=90%;KQG.Total.Warning=75%;KQG.QueryBehavior=ParseOnChange",
+				CustomName: "response_time_p95;sli=svc_rt_p95;pass=<600",
+			},
+		},
+	}
+}
+
+const expectedDashboardJSON = `{
+  "id": "12345678-1111-4444-8888-123456789012",
+  "dashboardMetadata": {
+    "name": "KQG;project=sockshop;stage=staging;service=carts",
+`
+
+func TestResourceClient_UploadDashboardSLIAndSLOs(t *testing.T) {
+	tests := []struct {
+		name             string
+		dashboardStorage string
+		wantURI          string
+	}{
+		{
+			name:             "json",
+			dashboardStorage: env.JSONDashboardStorage,
+			wantURI:          dashboardFilename,
+		},
+		{
+			name:             "gzip",
+			dashboardStorage: env.GzipDashboardStorage,
+			wantURI:          gzipDashboardFilename,
+		},
+		{
+			name:             "none",
+			dashboardStorage: env.NoDashboardStorage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			configClient := &mapConfigResourceClient{resources: map[string]string{}}
+			resourceClient := &ResourceClient{client: configClient, dashboardStorage: tt.dashboardStorage}
+
+			err := resourceClient.UploadDashboardSLIAndSLOs("sockshop", "staging", "carts", createTestDashboard(), &dynatrace.SLI{SpecVersion: "1.0"}, nil)
+			assert.NoError(t, err)
+			assert.Contains(t, configClient.resources, sliFilename)
+
+			if tt.wantURI == "" {
+				assert.NotContains(t, configClient.resources, dashboardFilename)
+				assert.NotContains(t, configClient.resources, gzipDashboardFilename)
+				return
+			}
+			assert.Contains(t, configClient.resources, tt.wantURI)
+
+			dashboardJSON, err := resourceClient.GetDashboard("sockshop", "staging", "carts")
+			assert.NoError(t, err)
+			assert.Contains(t, dashboardJSON, expectedDashboardJSON)
+			assert.Contains(t, dashboardJSON, `pass=<600"`)
+			assert.True(t, createTestDashboard().IsTheSameAs(dashboardJSON))
+		})
+	}
+}
+
+func TestResourceClient_UploadDashboardSLIAndSLOsUsesSingleUpload(t *testing.T) {
+	configClient := &mapConfigResourceClient{resources: map[string]string{}}
+	resourceClient := &ResourceClient{client: configClient, dashboardStorage: env.JSONDashboardStorage}
+
+	err := resourceClient.UploadDashboardSLIAndSLOs("sockshop", "staging", "carts", createTestDashboard(), &dynatrace.SLI{SpecVersion: "1.0"}, &keptn.ServiceLevelObjectives{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, configClient.uploads)
+	assert.Contains(t, configClient.resources, dashboardFilename)
+	assert.Contains(t, configClient.resources, sliFilename)
+	assert.Contains(t, configClient.resources, sloFilename)
+}
+
+func TestResourceClient_UploadDashboardSLIAndSLOsSkipsMissingFiles(t *testing.T) {
+	configClient := &mapConfigResourceClient{resources: map[string]string{}}
+	resourceClient := &ResourceClient{client: configClient, dashboardStorage: env.JSONDashboardStorage}
+
+	err := resourceClient.UploadDashboardSLIAndSLOs("sockshop", "staging", "carts", nil, nil, &keptn.ServiceLevelObjectives{})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, configClient.uploads)
+	assert.Equal(t, []string{sloFilename}, resourceURIs(configClient.resources))
+}
+
+func resourceURIs(resources map[string]string) []string {
+	uris := make([]string, 0, len(resources))
+	for uri := range resources {
+		uris = append(uris, uri)
+	}
+	return uris
+}
+
+func TestResourceClient_GetDashboardFallsBackToUncompressedDashboard(t *testing.T) {
+	configClient := &mapConfigResourceClient{resources: map[string]string{dashboardFilename: "{}"}}
+	resourceClient := &ResourceClient{client: configClient, dashboardStorage: env.GzipDashboardStorage}
+
+	dashboardJSON, err := resourceClient.GetDashboard("sockshop", "staging", "carts")
+	assert.NoError(t, err)
+	assert.Equal(t, "{}", dashboardJSON)
+}
@@ -5,53 +5,129 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
 	apimodels "github.com/keptn/go-utils/pkg/api/models"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
 )
 
 type ServiceClientInterface interface {
-	GetServiceNames(project string, stage string) ([]string, error)
+	GetServiceNamesPerStage(project string) (map[string][]string, error)
+	GetServiceNamesInAllStages(project string) ([]string, error)
+	GetService(project string, stage string, service string) (*apimodels.Service, error)
 	CreateServiceInProject(project string, service string) error
 }
 
 type ServiceClient struct {
-	client     *keptnapi.ServiceHandler
-	httpClient *http.Client
+	stageClient           *keptnapi.StageHandler
+	httpClient            *http.Client
+	shipyardControllerURL string
 }
 
-func NewDefaultServiceClient() *ServiceClient {
-	return NewServiceClient(
-		keptnapi.NewServiceHandler(common.GetShipyardControllerURL()),
-		&http.Client{})
+// NewDefaultServiceClient creates a ServiceClient calling the shipyard controller or, when running locally, a LocalServiceClient
+func NewDefaultServiceClient(cfg *env.Config) ServiceClientInterface {
+	if cfg.RunLocalEnabled {
+		return NewLocalServiceClient()
+	}
+
+	shipyardControllerURL, token := getShipyardControllerURLAndToken(cfg)
+	client := NewServiceClient(
+		newStageHandler(cfg),
+		keptnhttp.NewDefaultAuthenticatedHTTPClient(cfg, token))
+	client.shipyardControllerURL = shipyardControllerURL
+	return client
 }
 
-func NewServiceClient(client *keptnapi.ServiceHandler, httpClient *http.Client) *ServiceClient {
+// NewServiceClient creates a ServiceClient calling the shipyard controller of the control plane
+func NewServiceClient(stageClient *keptnapi.StageHandler, httpClient *http.Client) *ServiceClient {
 	return &ServiceClient{
-		client:     client,
-		httpClient: httpClient,
+		stageClient:           stageClient,
+		httpClient:            httpClient,
+		shipyardControllerURL: common.GetShipyardControllerURL(),
+	}
+}
+
+// GetServiceNamesPerStage returns the names of the services of each stage of the project by stage name. Stages are retrieved page by page
+func (c *ServiceClient) GetServiceNamesPerStage(project string) (map[string][]string, error) {
+	stages, err := c.stageClient.GetAllStages(project)
+	if err != nil {
+		return nil, common.NewKeptnAPIError(fmt.Errorf("could not fetch stages of Keptn project %s: %s", project, err.Error()))
+	}
+
+	serviceNamesPerStage := make(map[string][]string, len(stages))
+	for _, stage := range stages {
+		serviceNames := make([]string, 0, len(stage.Services))
+		for _, service := range stage.Services {
+			serviceNames = append(serviceNames, service.ServiceName)
+		}
+		serviceNamesPerStage[stage.StageName] = serviceNames
 	}
+
+	return serviceNamesPerStage, nil
 }
 
-func (c *ServiceClient) GetServiceNames(project string, stage string) ([]string, error) {
-	services, err := c.client.GetAllServices(project, stage)
+// GetServiceNamesInAllStages returns the sorted names of the services of all stages of the project. Stages are retrieved page by page
+func (c *ServiceClient) GetServiceNamesInAllStages(project string) ([]string, error) {
+	serviceNamesPerStage, err := c.GetServiceNamesPerStage(project)
 	if err != nil {
-		return nil, fmt.Errorf("could not fetch services of Keptn project %s at stage %s: %s", project, stage, err.Error())
+		return nil, err
 	}
 
-	if services == nil {
-		return nil, nil
+	uniqueServiceNames := make(map[string]bool)
+	for _, stageServiceNames := range serviceNamesPerStage {
+		for _, serviceName := range stageServiceNames {
+			uniqueServiceNames[serviceName] = true
+		}
 	}
 
-	serviceNames := make([]string, len(services))
-	for i, service := range services {
-		serviceNames[i] = service.ServiceName
+	serviceNames := make([]string, 0, len(uniqueServiceNames))
+	for serviceName := range uniqueServiceNames {
+		serviceNames = append(serviceNames, serviceName)
 	}
+	sort.Strings(serviceNames)
 
 	return serviceNames, nil
 }
 
+// GetService returns the service in the stage of the project or a user configuration error if it does not exist
+func (c *ServiceClient) GetService(project string, stage string, service string) (*apimodels.Service, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v1/project/%s/stage/%s/service/%s", c.shipyardControllerURL, url.PathEscape(project), url.PathEscape(stage), url.PathEscape(service)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create request: %v", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, common.NewKeptnAPIError(err)
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, common.NewUserConfigurationError(fmt.Errorf("service %s does not exist in stage %s of project %s", service, stage, project))
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, common.NewKeptnAPIError(fmt.Errorf("request failed with %d: %s", resp.StatusCode, string(body)))
+	}
+
+	keptnService := &apimodels.Service{}
+	err = json.Unmarshal(body, keptnService)
+	if err != nil {
+		return nil, common.NewKeptnAPIError(fmt.Errorf("could not parse service %s: %v", service, err))
+	}
+
+	return keptnService, nil
+}
+
 func (c *ServiceClient) CreateServiceInProject(project string, service string) error {
 	serviceModel := &apimodels.CreateService{
 		ServiceName: &service,
@@ -61,8 +137,7 @@ func (c *ServiceClient) CreateServiceInProject(project string, service string) e
 		return fmt.Errorf("could not marshal service payload: %s", err.Error())
 	}
 
-	// TODO 2021-09-08: extract the http client and maybe consolidate with dynatrace (http) client
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/project/%s/service", common.GetShipyardControllerURL(), project), bytes.NewBuffer(reqBody))
+	req, err := http.NewRequest("POST", fmt.Sprintf("%s/v1/project/%s/service", c.shipyardControllerURL, project), bytes.NewBuffer(reqBody))
 	if err != nil {
 		return fmt.Errorf("could not create request: %v", err)
 	}
@@ -71,7 +146,7 @@ func (c *ServiceClient) CreateServiceInProject(project string, service string) e
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return err
+		return common.NewKeptnAPIError(err)
 	}
 	defer resp.Body.Close()
 
@@ -81,7 +156,7 @@ func (c *ServiceClient) CreateServiceInProject(project string, service string) e
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("request failed with %d: %s", resp.StatusCode, string(body))
+		return common.NewKeptnAPIError(fmt.Errorf("request failed with %d: %s", resp.StatusCode, string(body)))
 	}
 
 	return nil
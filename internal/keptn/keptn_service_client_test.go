@@ -0,0 +1,73 @@
+package keptn
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceClient_GetServiceNamesInAllStages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/project/sockshop/stage", r.URL.Path)
+		if r.URL.Query().Get("nextPageKey") == "" {
+			w.Write([]byte(`{"stages":[{"stageName":"dev","services":[{"serviceName":"carts"}]},{"stageName":"staging","services":[{"serviceName":"carts"},{"serviceName":"orders"}]}],"nextPageKey":"2"}`))
+			return
+		}
+		w.Write([]byte(`{"stages":[{"stageName":"production","services":[{"serviceName":"catalogue"}]}],"nextPageKey":"0"}`))
+	}))
+	defer server.Close()
+
+	client := NewServiceClient(keptnapi.NewStageHandler(server.URL), server.Client())
+
+	serviceNames, err := client.GetServiceNamesInAllStages("sockshop")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"carts", "catalogue", "orders"}, serviceNames)
+}
+
+func TestServiceClient_GetServiceNamesPerStage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/project/sockshop/stage", r.URL.Path)
+		w.Write([]byte(`{"stages":[{"stageName":"dev","services":[{"serviceName":"carts"}]},{"stageName":"staging","services":[{"serviceName":"carts"},{"serviceName":"orders"}]},{"stageName":"production"}],"nextPageKey":"0"}`))
+	}))
+	defer server.Close()
+
+	client := NewServiceClient(keptnapi.NewStageHandler(server.URL), server.Client())
+
+	serviceNamesPerStage, err := client.GetServiceNamesPerStage("sockshop")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]string{"dev": {"carts"}, "staging": {"carts", "orders"}, "production": {}}, serviceNamesPerStage)
+}
+
+func TestServiceClient_GetService(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/project/sockshop/stage/staging/service/carts" {
+			w.Write([]byte(`{"serviceName":"carts"}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":404,"message":"Service not found"}`))
+	}))
+	defer server.Close()
+
+	os.Setenv("SHIPYARD_CONTROLLER", server.URL)
+	defer os.Unsetenv("SHIPYARD_CONTROLLER")
+
+	client := NewServiceClient(keptnapi.NewStageHandler(server.URL), server.Client())
+
+	service, err := client.GetService("sockshop", "staging", "carts")
+	assert.NoError(t, err)
+	if assert.NotNil(t, service) {
+		assert.Equal(t, "carts", service.ServiceName)
+	}
+
+	service, err = client.GetService("sockshop", "production", "carts")
+	assert.Nil(t, service)
+	if assert.Error(t, err) {
+		assert.Equal(t, common.UserConfigurationErrorType, common.GetErrorType(err))
+	}
+}
@@ -0,0 +1,54 @@
+package keptn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/selfmonitoring"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"gopkg.in/yaml.v2"
+)
+
+const shipyardFilename = "shipyard.yaml"
+
+type ShipyardClientInterface interface {
+	GetShipyard(project string) (*keptnv2.Shipyard, error)
+}
+
+// ShipyardClient retrieves the shipyard of a project from the configuration service. Unlike the Client it does not require a cloud event
+type ShipyardClient struct {
+	client ConfigResourceClientInterface
+}
+
+// NewDefaultShipyardClient creates a new ShipyardClient with a default Keptn resource handler for the configuration service
+func NewDefaultShipyardClient(cfg *env.Config) *ShipyardClient {
+	return NewShipyardClient(
+		NewDefaultConfigResourceClient(cfg))
+}
+
+// NewShipyardClient creates a new ShipyardClient using the given ConfigResourceClientInterface
+func NewShipyardClient(client ConfigResourceClientInterface) *ShipyardClient {
+	return &ShipyardClient{
+		client: client,
+	}
+}
+
+// GetShipyard returns the shipyard of the given project
+func (c *ShipyardClient) GetShipyard(project string) (*keptnv2.Shipyard, error) {
+	start := time.Now()
+	resource, err := c.client.GetProjectResource(project, shipyardFilename)
+	selfmonitoring.RecordAPICall(selfmonitoring.KeptnAPI, time.Since(start), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve shipyard for project %s: %w", project, err)
+	}
+
+	shipyard := &keptnv2.Shipyard{}
+	err = yaml.Unmarshal([]byte(resource), shipyard)
+	if err != nil {
+		return nil, common.NewUserConfigurationError(fmt.Errorf("invalid shipyard for project %s: %w", project, err))
+	}
+
+	return shipyard, nil
+}
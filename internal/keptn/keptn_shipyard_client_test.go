@@ -0,0 +1,71 @@
+package keptn
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type projectResourceClientMock struct {
+	ConfigResourceClientInterface
+	content string
+	err     error
+}
+
+func (m *projectResourceClientMock) GetProjectResource(project string, resourceURI string) (string, error) {
+	return m.content, m.err
+}
+
+func TestShipyardClient_GetShipyard(t *testing.T) {
+	client := NewShipyardClient(&projectResourceClientMock{
+		content: `apiVersion: "spec.keptn.sh/0.2.2"
+kind: "Shipyard"
+metadata:
+  name: "shipyard-sockshop"
+spec:
+  stages:
+    - name: "staging"
+    - name: "production"`,
+	})
+
+	shipyard, err := client.GetShipyard("sockshop")
+	assert.NoError(t, err)
+	if assert.Len(t, shipyard.Spec.Stages, 2) {
+		assert.Equal(t, "staging", shipyard.Spec.Stages[0].Name)
+		assert.Equal(t, "production", shipyard.Spec.Stages[1].Name)
+	}
+}
+
+func TestShipyardClient_GetShipyardErrors(t *testing.T) {
+	tests := []struct {
+		name          string
+		client        *projectResourceClientMock
+		wantErrorType common.ErrorType
+	}{
+		{
+			name:          "shipyard not found",
+			client:        &projectResourceClientMock{err: &ResourceNotFoundError{uri: shipyardFilename, project: "sockshop"}},
+			wantErrorType: common.UserConfigurationErrorType,
+		},
+		{
+			name:          "configuration service not available",
+			client:        &projectResourceClientMock{err: &ResourceRetrievalFailedError{ResourceError{uri: shipyardFilename, project: "sockshop"}, "connection refused"}},
+			wantErrorType: common.KeptnAPIErrorType,
+		},
+		{
+			name:          "invalid shipyard",
+			client:        &projectResourceClientMock{content: "spec: [stages"},
+			wantErrorType: common.UserConfigurationErrorType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shipyard, err := NewShipyardClient(tt.client).GetShipyard("sockshop")
+			assert.Nil(t, shipyard)
+			if assert.Error(t, err) {
+				assert.Equal(t, tt.wantErrorType, common.GetErrorType(err))
+			}
+		})
+	}
+}
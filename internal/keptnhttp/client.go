@@ -0,0 +1,205 @@
+package keptnhttp
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+// AuthHeader is the header used to authenticate against the Keptn API
+const AuthHeader = "x-token"
+
+// the delay before the first retry, doubled for every further retry. A variable so that tests can shorten it
+var retryDelay = 500 * time.Millisecond
+
+var defaultClient *http.Client
+var defaultClientOnce sync.Once
+
+// GetDefaultHTTPClient returns the http.Client shared by all requests to Keptn APIs. It is created using the configuration of the first call:
+// the SSL certificate of the Keptn API is only verified if required, an additional CA bundle may be provided, proxies are used as
+// described in http.ProxyFromEnvironment and failed requests are retried
+func GetDefaultHTTPClient(cfg *env.Config) *http.Client {
+	defaultClientOnce.Do(func() {
+		tlsConfig, err := NewTLSConfig(cfg.KeptnAPISSLVerificationEnabled, cfg.KeptnAPICABundle)
+		if err != nil {
+			log.WithError(err).Error("Could not load CA bundle for Keptn API, using system certificates only")
+			tlsConfig = &tls.Config{InsecureSkipVerify: !cfg.KeptnAPISSLVerificationEnabled}
+		}
+
+		defaultClient = NewHTTPClient(
+			&http.Transport{
+				TLSClientConfig: tlsConfig,
+				Proxy:           http.ProxyFromEnvironment,
+			},
+			cfg.KeptnAPIMaxRetries)
+	})
+	return defaultClient
+}
+
+// NewDefaultAuthenticatedHTTPClient returns a http.Client like GetDefaultHTTPClient that additionally authenticates every request using the given Keptn API token
+func NewDefaultAuthenticatedHTTPClient(cfg *env.Config, token string) *http.Client {
+	return NewAuthenticatedHTTPClient(GetDefaultHTTPClient(cfg), token)
+}
+
+// NewHTTPClient creates a new http.Client using the given transport that retries failed requests up to maxRetries times
+func NewHTTPClient(transport http.RoundTripper, maxRetries int) *http.Client {
+	return &http.Client{
+		Transport: &retryTransport{
+			next:       transport,
+			maxRetries: maxRetries,
+		},
+	}
+}
+
+// NewAuthenticatedHTTPClient creates a new http.Client based on the given client that sets the Keptn API token on every request.
+// If the token is empty, requests are sent unauthenticated, e.g. to Keptn services on the control plane
+func NewAuthenticatedHTTPClient(client *http.Client, token string) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &http.Client{
+		Transport: &authTransport{
+			next:  transport,
+			token: token,
+		},
+		Timeout: client.Timeout,
+	}
+}
+
+// NewTLSConfig returns a TLS configuration that only verifies certificates if required and additionally trusts the certificates of the
+// CA bundle, if one is given
+func NewTLSConfig(verify bool, caBundle string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: !verify}
+	if caBundle == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := ioutil.ReadFile(caBundle)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CA bundle %s: %w", caBundle, err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("CA bundle %s does not contain any PEM encoded certificates", caBundle)
+	}
+
+	tlsConfig.RootCAs = pool
+	return tlsConfig, nil
+}
+
+// authTransport sets the Keptn API token on requests that are not authenticated yet
+type authTransport struct {
+	next  http.RoundTripper
+	token string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.token == "" || req.Header.Get(AuthHeader) != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// a RoundTripper must not modify the request
+	req = req.Clone(req.Context())
+	req.Header.Set(AuthHeader, t.token)
+	return t.next.RoundTrip(req)
+}
+
+// retryTransport retries idempotent requests that failed due to a network error or an unavailable service, backing off exponentially
+type retryTransport struct {
+	next       http.RoundTripper
+	maxRetries int
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	delay := retryDelay
+	for attempt := 0; ; attempt++ {
+		attemptReq, err := rewindRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt >= t.maxRetries || !isIdempotent(req) || !isRetryable(resp, err) || !canRewind(req) {
+			return resp, err
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		log.WithFields(log.Fields{"url": req.URL.String(), "attempt": attempt + 1, "retryIn": delay}).Debug("Retrying request to Keptn API")
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// rewindRequest returns the request to send for the given attempt, with a fresh body for every retry
+func rewindRequest(req *http.Request, attempt int) (*http.Request, error) {
+	if attempt == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("could not rewind request body: %w", err)
+	}
+
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+	return retryReq, nil
+}
+
+// isIdempotent returns whether the request may be sent more than once, i.e. whether its method is idempotent or it carries an
+// Idempotency-Key or X-Idempotency-Key header, following the convention of http.Transport
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+
+	_, hasIdempotencyKey := req.Header["Idempotency-Key"]
+	_, hasXIdempotencyKey := req.Header["X-Idempotency-Key"]
+	return hasIdempotencyKey || hasXIdempotencyKey
+}
+
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(delay):
+		return nil
+	}
+}
@@ -0,0 +1,191 @@
+package keptnhttp
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	retryDelay = time.Millisecond
+}
+
+func TestHTTPClient_RetriesUnavailableService(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(http.DefaultTransport, 3)
+	req, err := http.NewRequest(http.MethodPut, server.URL, bytes.NewBufferString(`{"a":1}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp, err := client.Do(req)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"a":1}`, `{"a":1}`, `{"a":1}`}, bodies)
+}
+
+func TestHTTPClient_RetriesOnlyIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name           string
+		idempotencyKey string
+		wantAttempts   int32
+	}{
+		{
+			name:         "POST",
+			wantAttempts: 1,
+		},
+		{
+			name:           "POST with idempotency key",
+			idempotencyKey: "3a2d6f8c",
+			wantAttempts:   3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&attempts, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			req, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewBufferString(`{"a":1}`))
+			if !assert.NoError(t, err) {
+				return
+			}
+			if tt.idempotencyKey != "" {
+				req.Header.Set("Idempotency-Key", tt.idempotencyKey)
+			}
+
+			resp, err := NewHTTPClient(http.DefaultTransport, 2).Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.Equal(t, tt.wantAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestHTTPClient_StopsAfterMaxRetries(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(http.DefaultTransport, 2)
+	resp, err := client.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&attempts))
+}
+
+func TestHTTPClient_DoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(http.DefaultTransport, 3)
+	resp, err := client.Get(server.URL)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&attempts))
+}
+
+func TestAuthenticatedHTTPClient(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		requestToken  string
+		expectedToken string
+	}{
+		{
+			name:          "token is set",
+			token:         "my-token",
+			expectedToken: "my-token",
+		},
+		{
+			name:          "token already set on request is kept",
+			token:         "my-token",
+			requestToken:  "other-token",
+			expectedToken: "other-token",
+		},
+		{
+			name:          "empty token is not set",
+			expectedToken: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var receivedToken string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				receivedToken = r.Header.Get(AuthHeader)
+			}))
+			defer server.Close()
+
+			client := NewAuthenticatedHTTPClient(NewHTTPClient(http.DefaultTransport, 0), tt.token)
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			if tt.requestToken != "" {
+				req.Header.Set(AuthHeader, tt.requestToken)
+			}
+
+			resp, err := client.Do(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp.Body.Close()
+
+			assert.Equal(t, tt.expectedToken, receivedToken)
+		})
+	}
+}
+
+func TestGetTLSConfig(t *testing.T) {
+	config, err := NewTLSConfig(false, "")
+	assert.NoError(t, err)
+	assert.True(t, config.InsecureSkipVerify)
+	assert.Nil(t, config.RootCAs)
+
+	config, err = NewTLSConfig(true, "")
+	assert.NoError(t, err)
+	assert.False(t, config.InsecureSkipVerify)
+
+	_, err = NewTLSConfig(true, "does-not-exist.pem")
+	assert.Error(t, err)
+}
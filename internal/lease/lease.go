@@ -0,0 +1,168 @@
+package lease
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnkubeutils "github.com/keptn/kubernetes-utils/pkg"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const leaseNamePrefix = "dynatrace-service-"
+
+// the durations used for all leases, variables so that tests can shorten them
+var (
+	leaseDuration  = 15 * time.Second
+	renewDeadline  = 10 * time.Second
+	retryPeriod    = 2 * time.Second
+	acquireTimeout = 5 * time.Minute
+)
+
+var identityCounter uint64
+
+// RunWhileLeader runs fn whenever this replica is the leader for the lease with the given name and cancels the context passed to fn once leadership is lost.
+// If leader election is disabled, fn is run right away. RunWhileLeader blocks until ctx is canceled
+func RunWhileLeader(ctx context.Context, cfg *env.Config, name string, fn func(ctx context.Context)) {
+	if !cfg.LeaderElectionEnabled {
+		fn(ctx)
+		return
+	}
+
+	client, err := getKubernetesClient(cfg)
+	if err != nil {
+		log.WithError(err).Error("Could not create Kubernetes client for leader election, running without it")
+		fn(ctx)
+		return
+	}
+
+	runWhileLeader(ctx, client, cfg.PodNamespace, name, fn)
+}
+
+func runWhileLeader(ctx context.Context, client kubernetes.Interface, namespace string, name string, fn func(ctx context.Context)) {
+	for ctx.Err() == nil {
+		elector, err := newLeaderElector(client, namespace, name, leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				log.WithField("lease", name).Info("Became leader")
+				fn(ctx)
+			},
+			OnStoppedLeading: func() {
+				log.WithField("lease", name).Info("Not leading")
+			},
+		})
+		if err != nil {
+			log.WithError(err).Error("Could not create leader elector")
+			return
+		}
+
+		// returns once leadership is lost, so try again to become leader
+		elector.Run(ctx)
+	}
+}
+
+// WithLock runs fn while holding the lease with the given name, so that fn is not executed by several replicas or goroutines at the same time.
+// The context passed to fn is canceled once the lease is lost, in which case an error is returned instead of the result of fn.
+// If leader election is disabled, fn is run right away
+func WithLock(cfg *env.Config, name string, fn func(ctx context.Context) error) error {
+	if !cfg.LeaderElectionEnabled {
+		return fn(context.Background())
+	}
+
+	client, err := getKubernetesClient(cfg)
+	if err != nil {
+		log.WithError(err).Error("Could not create Kubernetes client for lock, running without it")
+		return fn(context.Background())
+	}
+
+	return withLock(client, cfg.PodNamespace, name, fn)
+}
+
+func withLock(client kubernetes.Interface, namespace string, name string, fn func(ctx context.Context) error) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	acquired := make(chan struct{})
+	result := make(chan error, 1)
+
+	var elector *leaderelection.LeaderElector
+	elector, err := newLeaderElector(client, namespace, name, leaderelection.LeaderCallbacks{
+		OnStartedLeading: func(leaderCtx context.Context) {
+			close(acquired)
+			err := fn(leaderCtx)
+			// another replica may have run fn as well once the lease was lost, so the result must not be used
+			if leaderCtx.Err() != nil || !elector.IsLeader() {
+				err = fmt.Errorf("lost lock %s while holding it", name)
+			}
+			result <- err
+			// releases the lease
+			cancel()
+		},
+		OnStoppedLeading: func() {},
+	})
+	if err != nil {
+		return fmt.Errorf("could not create lock %s: %w", name, err)
+	}
+
+	go func() {
+		select {
+		case <-acquired:
+		case <-ctx.Done():
+		case <-time.After(acquireTimeout):
+			cancel()
+		}
+	}()
+
+	elector.Run(ctx)
+
+	// fn is run in the background and may still be running if the lease was lost
+	select {
+	case <-acquired:
+		return <-result
+	default:
+		return fmt.Errorf("could not acquire lock %s within %s", name, acquireTimeout)
+	}
+}
+
+func newLeaderElector(client kubernetes.Interface, namespace string, name string, callbacks leaderelection.LeaderCallbacks) (*leaderelection.LeaderElector, error) {
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta: metav1.ObjectMeta{
+			Name:      leaseNamePrefix + name,
+			Namespace: namespace,
+		},
+		Client: client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{
+			Identity: getIdentity(),
+		},
+	}
+
+	return leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		Callbacks:       callbacks,
+		ReleaseOnCancel: true,
+		Name:            name,
+	})
+}
+
+// getIdentity returns a unique identity for every elector, so that electors within the same replica exclude each other as well
+func getIdentity() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "dynatrace-service"
+	}
+
+	return fmt.Sprintf("%s_%d", hostname, atomic.AddUint64(&identityCounter, 1))
+}
+
+func getKubernetesClient(cfg *env.Config) (*kubernetes.Clientset, error) {
+	return keptnkubeutils.GetClientset(cfg.InCluster)
+}
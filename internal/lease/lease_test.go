@@ -0,0 +1,110 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func init() {
+	leaseDuration = 3 * time.Second
+	renewDeadline = 2 * time.Second
+	retryPeriod = 100 * time.Millisecond
+	acquireTimeout = 10 * time.Second
+}
+
+func TestWithLock_ReturnsResultOfFunction(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	err := withLock(client, "keptn", "test", func(_ context.Context) error {
+		return errors.New("failed")
+	})
+	assert.EqualError(t, err, "failed")
+
+	err = withLock(client, "keptn", "test", func(_ context.Context) error {
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+func TestWithLock_ExcludesConcurrentCalls(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	var running int32
+	var overlaps int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := withLock(client, "keptn", "test", func(_ context.Context) error {
+				if atomic.AddInt32(&running, 1) > 1 {
+					atomic.AddInt32(&overlaps, 1)
+				}
+				time.Sleep(50 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(0), atomic.LoadInt32(&overlaps))
+}
+
+func TestWithLock_ReturnsErrorIfLockIsLost(t *testing.T) {
+	client := fake.NewSimpleClientset()
+
+	err := withLock(client, "keptn", "test", func(ctx context.Context) error {
+		// another replica takes over the lease
+		leases := client.CoordinationV1().Leases("keptn")
+		lease, err := leases.Get(ctx, leaseNamePrefix+"test", metav1.GetOptions{})
+		if !assert.NoError(t, err) {
+			return err
+		}
+
+		otherIdentity := "other"
+		now := metav1.NowMicro()
+		lease.Spec.HolderIdentity = &otherIdentity
+		lease.Spec.AcquireTime = &now
+		lease.Spec.RenewTime = &now
+		_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		if !assert.NoError(t, err) {
+			return err
+		}
+
+		<-ctx.Done()
+		return nil
+	})
+	assert.EqualError(t, err, "lost lock test while holding it")
+}
+
+func TestRunWhileLeader_OnlyOneLeader(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var leaders int32
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			runWhileLeader(ctx, client, "keptn", "test", func(ctx context.Context) {
+				atomic.AddInt32(&leaders, 1)
+				<-ctx.Done()
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&leaders))
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"os"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+)
+
+// serviceName is the name the logs of the dynatrace-service are attributed to
+const serviceName = "dynatrace-service"
+
+// dynatraceFieldMap renames the standard fields of log entries to the attributes Dynatrace Log Monitoring detects automatically
+var dynatraceFieldMap = log.FieldMap{
+	log.FieldKeyTime:  "timestamp",
+	log.FieldKeyLevel: "loglevel",
+	log.FieldKeyMsg:   "content",
+}
+
+// keptnFieldNames renames the fields used for Keptn context information to a common keptn namespace
+var keptnFieldNames = map[string]string{
+	"keptnContext": "keptn.context",
+	"project":      "keptn.project",
+	"stage":        "keptn.stage",
+	"service":      "keptn.service",
+	"eventType":    "keptn.event_type",
+	"eventID":      "keptn.event_id",
+}
+
+// Init configures the format of the logs as specified by the configuration
+func Init(cfg *env.Config) {
+	log.SetFormatter(NewFormatter(cfg.LogFormat, cfg.PodNamespace))
+}
+
+// NewFormatter returns the formatter for the given log format, text or json. JSON log entries are attributed to the pod in namespace
+func NewFormatter(format string, namespace string) log.Formatter {
+	if format != env.JSONLogFormat {
+		return &log.TextFormatter{}
+	}
+
+	hostname, _ := os.Hostname()
+	return &dynatraceJSONFormatter{
+		formatter: &log.JSONFormatter{FieldMap: dynatraceFieldMap},
+		staticFields: log.Fields{
+			"service.name":       serviceName,
+			"k8s.namespace.name": namespace,
+			"k8s.pod.name":       hostname,
+		},
+	}
+}
+
+// dynatraceJSONFormatter formats log entries as JSON objects with attributes compatible with Dynatrace Log Monitoring
+type dynatraceJSONFormatter struct {
+	formatter    *log.JSONFormatter
+	staticFields log.Fields
+}
+
+// Format adds the static fields to the entry and renames fields with Keptn context information before formatting it as JSON
+func (f *dynatraceJSONFormatter) Format(entry *log.Entry) ([]byte, error) {
+	data := make(log.Fields, len(entry.Data)+len(f.staticFields))
+	for key, value := range f.staticFields {
+		data[key] = value
+	}
+	for key, value := range entry.Data {
+		if keptnKey, ok := keptnFieldNames[key]; ok {
+			key = keptnKey
+		}
+		data[key] = value
+	}
+
+	formatted := *entry
+	formatted.Data = data
+	return f.formatter.Format(&formatted)
+}
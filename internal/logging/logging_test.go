@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFormatter_JSON(t *testing.T) {
+	entry := &log.Entry{
+		Time:    time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC),
+		Level:   log.WarnLevel,
+		Message: "Could not send event",
+		Data: log.Fields{
+			"keptnContext": "ctx-1",
+			"project":      "sockshop",
+			"attempt":      2,
+		},
+	}
+
+	formatted, err := NewFormatter("json", "keptn-team-a").Format(entry)
+	assert.NoError(t, err)
+
+	fields := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(formatted, &fields))
+
+	assert.Equal(t, "Could not send event", fields["content"])
+	assert.Equal(t, "warning", fields["loglevel"])
+	assert.Equal(t, "2021-11-01T12:00:00Z", fields["timestamp"])
+	assert.Equal(t, "dynatrace-service", fields["service.name"])
+	assert.Equal(t, "keptn-team-a", fields["k8s.namespace.name"])
+	assert.Equal(t, "ctx-1", fields["keptn.context"])
+	assert.Equal(t, "sockshop", fields["keptn.project"])
+	assert.Equal(t, float64(2), fields["attempt"])
+	assert.NotContains(t, fields, "keptnContext")
+	assert.NotContains(t, fields, "msg")
+
+	assert.Equal(t, "ctx-1", entry.Data["keptnContext"], "the original entry must not be changed")
+}
+
+func TestNewFormatter_Text(t *testing.T) {
+	assert.IsType(t, &log.TextFormatter{}, NewFormatter("text", "keptn"))
+	assert.IsType(t, &log.TextFormatter{}, NewFormatter("", "keptn"))
+}
@@ -2,7 +2,6 @@ package monitoring
 
 import (
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
-	"github.com/keptn-contrib/dynatrace-service/internal/env"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -19,13 +18,9 @@ func NewAutoTagCreation(client dynatrace.ClientInterface) *AutoTagCreation {
 
 // Create creates auto-tags in Dynatrace and returns the tagging rules
 func (at *AutoTagCreation) Create() []ConfigResult {
-	if !env.IsTaggingRulesGenerationEnabled() {
-		return nil
-	}
-
 	log.Info("Setting up auto-tagging rules in Dynatrace Tenant")
 
-	autoTagsClient := dynatrace.NewAutoTagClient(at.client)
+	autoTagsClient := getAutoTagsClient(at.client)
 	existingDTRuleNames, err := autoTagsClient.GetAllTagNames()
 	if err != nil {
 		// Error occurred but continue
@@ -42,7 +37,15 @@ func (at *AutoTagCreation) Create() []ConfigResult {
 	return taggingRulesResults
 }
 
-func createAutoTaggingRuleForRuleName(client *dynatrace.AutoTagsClient, existingTagNames *dynatrace.TagNames, ruleName string) ConfigResult {
+// getAutoTagsClient returns a client using Settings 2.0 if the tenant supports it, or the config API v1 otherwise
+func getAutoTagsClient(client dynatrace.ClientInterface) dynatrace.AutoTagsClientInterface {
+	if isSettingsSchemaSupported(client, dynatrace.AutoTaggingSchemaID) {
+		return dynatrace.NewAutoTagsSettingsClient(dynatrace.NewSettingsClient(client))
+	}
+	return dynatrace.NewAutoTagClient(client)
+}
+
+func createAutoTaggingRuleForRuleName(client dynatrace.AutoTagsClientInterface, existingTagNames *dynatrace.TagNames, ruleName string) ConfigResult {
 	if !existingTagNames.Contains(ruleName) {
 		rule := createAutoTaggingRuleDTO(ruleName)
 
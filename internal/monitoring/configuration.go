@@ -1,10 +1,15 @@
 package monitoring
 
 import (
+	"context"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/lease"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+
+	log "github.com/sirupsen/logrus"
 )
 
 // ConfiguredEntities contains information about the entities configures in Dynatrace
@@ -21,6 +26,26 @@ type ConfiguredEntities struct {
 	MetricEvents                []ConfigResult
 }
 
+// GenerationSteps defines which Dynatrace configuration objects are generated when configuring the monitoring
+type GenerationSteps struct {
+	TaggingRules         bool
+	ProblemNotifications bool
+	ManagementZones      bool
+	Dashboards           bool
+	MetricEvents         bool
+}
+
+// NewGenerationSteps creates GenerationSteps configured by the GENERATE_* environment variables of the configuration
+func NewGenerationSteps(cfg *env.Config) GenerationSteps {
+	return GenerationSteps{
+		TaggingRules:         cfg.TaggingRulesGenerationEnabled,
+		ProblemNotifications: cfg.ProblemNotificationsGenerationEnabled,
+		ManagementZones:      cfg.ManagementZonesGenerationEnabled,
+		Dashboards:           cfg.DashboardsGenerationEnabled,
+		MetricEvents:         cfg.MetricEventsGenerationEnabled,
+	}
+}
+
 type ConfigResult struct {
 	Name    string
 	Success bool
@@ -32,46 +57,76 @@ type Configuration struct {
 	kClient        keptn.ClientInterface
 	resourceClient keptn.ResourceClientInterface
 	serviceClient  keptn.ServiceClientInterface
+	steps          GenerationSteps
+	cfg            *env.Config
 }
 
-func NewConfiguration(dynatraceClient dynatrace.ClientInterface, keptnClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface) *Configuration {
+// NewConfiguration creates a new Configuration generating the Dynatrace configuration objects enabled by steps
+func NewConfiguration(dynatraceClient dynatrace.ClientInterface, keptnClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface, steps GenerationSteps, cfg *env.Config) *Configuration {
 	return &Configuration{
 		dtClient:       dynatraceClient,
 		kClient:        keptnClient,
 		resourceClient: resourceClient,
 		serviceClient:  serviceClient,
+		steps:          steps,
+		cfg:            cfg,
 	}
 }
 
-// ConfigureMonitoring configures Dynatrace for a Keptn project
+// ConfigureMonitoring configures Dynatrace for a Keptn project. With multiple replicas, only one of them configures monitoring at a time
 func (mc *Configuration) ConfigureMonitoring(project string, shipyard *keptnv2.Shipyard) (*ConfiguredEntities, error) {
+	var configuredEntities *ConfiguredEntities
+	err := lease.WithLock(mc.cfg, "configure-monitoring", func(_ context.Context) error {
+		var err error
+		configuredEntities, err = mc.configureMonitoring(project, shipyard)
+		return err
+	})
+
+	return configuredEntities, err
+}
+
+func (mc *Configuration) configureMonitoring(project string, shipyard *keptnv2.Shipyard) (*ConfiguredEntities, error) {
 
 	configuredEntities := &ConfiguredEntities{
-		TaggingRulesEnabled:         env.IsTaggingRulesGenerationEnabled(),
-		TaggingRules:                NewAutoTagCreation(mc.dtClient).Create(),
-		ProblemNotificationsEnabled: env.IsProblemNotificationsGenerationEnabled(),
-		ProblemNotifications:        NewProblemNotificationCreation(mc.dtClient).Create(),
-		ManagementZonesEnabled:      env.IsManagementZonesGenerationEnabled(),
+		TaggingRulesEnabled:         mc.steps.TaggingRules,
+		ProblemNotificationsEnabled: mc.steps.ProblemNotifications,
+		ManagementZonesEnabled:      mc.steps.ManagementZones,
 		ManagementZones:             []ConfigResult{},
-		DashboardEnabled:            env.IsDashboardsGenerationEnabled(),
+		DashboardEnabled:            mc.steps.Dashboards,
 		Dashboard:                   ConfigResult{},
-		MetricEventsEnabled:         env.IsMetricEventsGenerationEnabled(),
+		MetricEventsEnabled:         mc.steps.MetricEvents,
 		MetricEvents:                []ConfigResult{},
 	}
 
-	if project != "" && shipyard != nil {
+	if mc.steps.TaggingRules {
+		configuredEntities.TaggingRules = NewAutoTagCreation(mc.dtClient).Create()
+	}
+	if mc.steps.ProblemNotifications {
+		configuredEntities.ProblemNotifications = NewProblemNotificationCreation(mc.dtClient, mc.cfg).Create()
+	}
+
+	if project == "" || shipyard == nil {
+		return configuredEntities, nil
+	}
+
+	if mc.steps.ManagementZones {
 		configuredEntities.ManagementZones = NewManagementZoneCreation(mc.dtClient).Create(project, *shipyard)
+	}
+	if mc.steps.Dashboards {
 		configuredEntities.Dashboard = NewDashboardCreation(mc.dtClient).Create(project, *shipyard)
+	}
+
+	if mc.steps.MetricEvents {
+		serviceNamesPerStage, err := mc.serviceClient.GetServiceNamesPerStage(project)
+		if err != nil {
+			return nil, err
+		}
 
 		var metricEvents []ConfigResult
 		// try to create metric events - if one fails, don't fail the whole setup
 		for _, stage := range shipyard.Spec.Stages {
 			if shouldCreateMetricEvents(stage) {
-				serviceNames, err := mc.serviceClient.GetServiceNames(project, stage.Name)
-				if err != nil {
-					return nil, err
-				}
-				for _, serviceName := range serviceNames {
+				for _, serviceName := range serviceNamesPerStage[stage.Name] {
 					metricEvents = append(
 						metricEvents,
 						NewMetricEventCreation(mc.dtClient, mc.kClient, mc.resourceClient).Create(project, stage.Name, serviceName)...)
@@ -92,3 +147,13 @@ func shouldCreateMetricEvents(stage keptnv2.Stage) bool {
 	}
 	return false
 }
+
+// isSettingsSchemaSupported checks if the tenant supports the given Settings 2.0 schema - if not, or if this cannot be determined, the config API v1 is used instead
+func isSettingsSchemaSupported(dtClient dynatrace.ClientInterface, schemaID string) bool {
+	isSupported, err := dynatrace.NewSettingsClient(dtClient).IsSchemaSupported(schemaID)
+	if err != nil {
+		log.WithError(err).WithField("schemaId", schemaID).Warn("Could not determine whether Settings 2.0 schema is supported, falling back to config API v1")
+		return false
+	}
+	return isSupported
+}
@@ -0,0 +1,79 @@
+package monitoring
+
+import (
+	"net/http"
+	"testing"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+// Tests that only the enabled generation steps read from or write to Dynatrace
+func TestConfiguration_ConfigureMonitoringOnlyRunsEnabledSteps(t *testing.T) {
+	shipyard := &keptnv2.Shipyard{
+		Spec: keptnv2.ShipyardSpec{
+			Stages: []keptnv2.Stage{
+				{Name: "dev"},
+				{Name: "production", Sequences: []keptnv2.Sequence{{Name: "remediation"}}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		steps           GenerationSteps
+		expectedChanges []string
+	}{
+		{
+			name: "all steps disabled",
+		},
+		{
+			name:  "only management zones enabled",
+			steps: GenerationSteps{ManagementZones: true},
+			expectedChanges: []string{
+				"create management zone: Keptn: sockshop",
+				"create management zone: Keptn: sockshop dev",
+				"create management zone: Keptn: sockshop production",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestedPaths := []string{}
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requestedPaths = append(requestedPaths, r.URL.Path)
+				w.Write([]byte(`{"values":[]}`))
+			})
+
+			httpClient, teardown := test.CreateHTTPClient(handler)
+			defer teardown()
+
+			dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+			planningClient := dynatrace.NewPlanningClient(dtClient)
+
+			// the service client is not set, as it must only be used when generating metric events
+			configuredEntities, err := NewConfiguration(planningClient, nil, nil, nil, tt.steps, env.ReadConfig()).ConfigureMonitoring("sockshop", shipyard)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.steps.ManagementZones, configuredEntities.ManagementZonesEnabled)
+			assert.Equal(t, tt.steps.MetricEvents, configuredEntities.MetricEventsEnabled)
+
+			var actualChanges []string
+			for _, change := range planningClient.GetPlannedChanges() {
+				actualChanges = append(actualChanges, change.String())
+			}
+			assert.Equal(t, tt.expectedChanges, actualChanges)
+
+			if tt.expectedChanges == nil {
+				assert.Empty(t, requestedPaths)
+			}
+		})
+	}
+}
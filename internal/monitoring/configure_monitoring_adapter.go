@@ -4,6 +4,7 @@ import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	keptn "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
 type ConfigureMonitoringAdapterInterface interface {
@@ -11,26 +12,69 @@ type ConfigureMonitoringAdapterInterface interface {
 	adapter.TriggeredCloudEventContentAdapter
 
 	IsNotForDynatrace() bool
+	IsTriggeredEvent() bool
+	IsPlan() bool
+}
+
+// configureMonitoringPlanData contains the plan option of a configure-monitoring.triggered event, which is not part of the Keptn spec
+type configureMonitoringPlanData struct {
+	ConfigureMonitoring struct {
+		Plan bool `json:"plan"`
+	} `json:"configureMonitoring"`
 }
 
 // ConfigureMonitoringAdapter encapsulates a cloud event and its parsed payload
 type ConfigureMonitoringAdapter struct {
-	event      keptn.ConfigureMonitoringEventData
+	event      keptnv2.ConfigureMonitoringTriggeredEventData
+	plan       bool
 	cloudEvent adapter.CloudEventAdapter
 }
 
-// NewConfigureMonitoringAdapterFromEvent creates a new ConfigureMonitoringAdapter from a cloudevents Event
+// NewConfigureMonitoringAdapterFromEvent creates a new ConfigureMonitoringAdapter from a cloudevents Event.
+// Both the legacy configure monitoring event and the configure-monitoring.triggered event are supported
 func NewConfigureMonitoringAdapterFromEvent(e cloudevents.Event) (*ConfigureMonitoringAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
-	cmData := &keptn.ConfigureMonitoringEventData{}
+	if e.Type() == keptn.ConfigureMonitoringEventType {
+		return newConfigureMonitoringAdapterFromLegacyEvent(ceAdapter)
+	}
+
+	cmData := &keptnv2.ConfigureMonitoringTriggeredEventData{}
 	err := ceAdapter.PayloadAs(cmData)
 	if err != nil {
 		return nil, err
 	}
 
+	planData := &configureMonitoringPlanData{}
+	err = ceAdapter.PayloadAs(planData)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ConfigureMonitoringAdapter{
 		event:      *cmData,
+		plan:       planData.ConfigureMonitoring.Plan,
+		cloudEvent: ceAdapter,
+	}, nil
+}
+
+func newConfigureMonitoringAdapterFromLegacyEvent(ceAdapter adapter.CloudEventAdapter) (*ConfigureMonitoringAdapter, error) {
+	cmData := &keptn.ConfigureMonitoringEventData{}
+	err := ceAdapter.PayloadAs(cmData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConfigureMonitoringAdapter{
+		event: keptnv2.ConfigureMonitoringTriggeredEventData{
+			EventData: keptnv2.EventData{
+				Project: cmData.Project,
+				Service: cmData.Service,
+			},
+			ConfigureMonitoring: keptnv2.ConfigureMonitoringTriggeredParams{
+				Type: cmData.Type,
+			},
+		},
 		cloudEvent: ceAdapter,
 	}, nil
 }
@@ -47,7 +91,7 @@ func (a ConfigureMonitoringAdapter) GetSource() string {
 
 // GetEvent returns the event type
 func (a ConfigureMonitoringAdapter) GetEvent() string {
-	return keptn.ConfigureMonitoringEventType
+	return a.cloudEvent.Type()
 }
 
 // GetProject returns the project
@@ -57,7 +101,7 @@ func (a ConfigureMonitoringAdapter) GetProject() string {
 
 // GetStage returns the stage
 func (a ConfigureMonitoringAdapter) GetStage() string {
-	return ""
+	return a.event.Stage
 }
 
 // GetService returns the service
@@ -82,11 +126,21 @@ func (a ConfigureMonitoringAdapter) GetDeploymentStrategy() string {
 
 // GetLabels returns a map of labels
 func (a ConfigureMonitoringAdapter) GetLabels() map[string]string {
-	return nil
+	return a.event.Labels
 }
 
 func (a ConfigureMonitoringAdapter) IsNotForDynatrace() bool {
-	return a.event.Type != "dynatrace"
+	return a.event.ConfigureMonitoring.Type != "dynatrace"
+}
+
+// IsTriggeredEvent returns true if the event is a configure-monitoring.triggered event rather than the legacy configure monitoring event
+func (a ConfigureMonitoringAdapter) IsTriggeredEvent() bool {
+	return a.GetEvent() == keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName)
+}
+
+// IsPlan returns true if the Dynatrace objects that would be configured should only be listed rather than applied
+func (a ConfigureMonitoringAdapter) IsPlan() bool {
+	return a.plan
 }
 
 func (a ConfigureMonitoringAdapter) GetEventID() string {
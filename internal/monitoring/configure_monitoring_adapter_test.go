@@ -0,0 +1,112 @@
+package monitoring
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func createConfigureMonitoringCloudEvent(t *testing.T, eventType string, payload interface{}) cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	ce.SetID("a3e5f16d-8888-4720-82c7-6995062905c1")
+	ce.SetType(eventType)
+	ce.SetSource("shipyard-controller")
+	ce.SetExtension("shkeptncontext", "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+	if err := ce.SetData(cloudevents.ApplicationJSON, payload); err != nil {
+		t.Fatalf("could not set cloud event data: %v", err)
+	}
+
+	return ce
+}
+
+func TestNewConfigureMonitoringAdapterFromEvent(t *testing.T) {
+	tests := []struct {
+		name                string
+		event               cloudevents.Event
+		wantStage           string
+		wantTriggeredEvent  bool
+		wantNotForDynatrace bool
+		wantPlan            bool
+		wantEvent           string
+	}{
+		{
+			name: "legacy configure monitoring event",
+			event: createConfigureMonitoringCloudEvent(t, keptn.ConfigureMonitoringEventType,
+				keptn.ConfigureMonitoringEventData{
+					Type:    "dynatrace",
+					Project: "sockshop",
+					Service: "carts",
+				}),
+			wantStage:          "",
+			wantTriggeredEvent: false,
+			wantEvent:          keptn.ConfigureMonitoringEventType,
+		},
+		{
+			name: "configure-monitoring.triggered event",
+			event: createConfigureMonitoringCloudEvent(t, keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+				keptnv2.ConfigureMonitoringTriggeredEventData{
+					EventData: keptnv2.EventData{
+						Project: "sockshop",
+						Stage:   "dev",
+						Service: "carts",
+					},
+					ConfigureMonitoring: keptnv2.ConfigureMonitoringTriggeredParams{
+						Type: "dynatrace",
+					},
+				}),
+			wantStage:          "dev",
+			wantTriggeredEvent: true,
+			wantEvent:          keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+		},
+		{
+			name: "configure-monitoring.triggered event for another monitoring provider",
+			event: createConfigureMonitoringCloudEvent(t, keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+				keptnv2.ConfigureMonitoringTriggeredEventData{
+					EventData: keptnv2.EventData{
+						Project: "sockshop",
+						Stage:   "dev",
+						Service: "carts",
+					},
+					ConfigureMonitoring: keptnv2.ConfigureMonitoringTriggeredParams{
+						Type: "prometheus",
+					},
+				}),
+			wantStage:           "dev",
+			wantTriggeredEvent:  true,
+			wantNotForDynatrace: true,
+			wantEvent:           keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+		},
+		{
+			name: "configure-monitoring.triggered event with plan option",
+			event: createConfigureMonitoringCloudEvent(t, keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+				map[string]interface{}{
+					"project":             "sockshop",
+					"stage":               "dev",
+					"service":             "carts",
+					"configureMonitoring": map[string]interface{}{"type": "dynatrace", "plan": true},
+				}),
+			wantStage:          "dev",
+			wantTriggeredEvent: true,
+			wantPlan:           true,
+			wantEvent:          keptnv2.GetTriggeredEventType(keptnv2.ConfigureMonitoringTaskName),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := NewConfigureMonitoringAdapterFromEvent(tt.event)
+			assert.NoError(t, err)
+
+			assert.Equal(t, "sockshop", a.GetProject())
+			assert.Equal(t, tt.wantStage, a.GetStage())
+			assert.Equal(t, "carts", a.GetService())
+			assert.Equal(t, tt.wantEvent, a.GetEvent())
+			assert.Equal(t, "a3e5f16d-8888-4720-82c7-6995062905c1", a.GetEventID())
+			assert.Equal(t, tt.wantTriggeredEvent, a.IsTriggeredEvent())
+			assert.Equal(t, tt.wantNotForDynatrace, a.IsNotForDynatrace())
+			assert.Equal(t, tt.wantPlan, a.IsPlan())
+		})
+	}
+}
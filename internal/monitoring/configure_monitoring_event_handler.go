@@ -2,9 +2,11 @@ package monitoring
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
@@ -19,42 +21,81 @@ type KeptnAPIConnectionCheck struct {
 	Message              string
 }
 
+// DynatraceAPITokenCheck contains the result of verifying the scopes of the Dynatrace API token
+type DynatraceAPITokenCheck struct {
+	MissingScopes []string
+	Message       string
+}
+
 type ConfigureMonitoringEventHandler struct {
 	event          ConfigureMonitoringAdapterInterface
 	dtClient       dynatrace.ClientInterface
 	kClient        keptn.ClientInterface
 	resourceClient keptn.ResourceClientInterface
 	serviceClient  keptn.ServiceClientInterface
+	shipyardClient keptn.ShipyardClientInterface
+	cfg            *env.Config
+
+	// managedEnvironments are further environments of a Dynatrace Managed cluster monitoring is configured in
+	managedEnvironments []ManagedEnvironment
+}
+
+// ManagedEnvironment is an environment of a Dynatrace Managed cluster
+type ManagedEnvironment struct {
+	Name   string
+	Client dynatrace.ClientInterface
 }
 
 // NewConfigureMonitoringEventHandler returns a new ConfigureMonitoringEventHandler
-func NewConfigureMonitoringEventHandler(event ConfigureMonitoringAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface) ConfigureMonitoringEventHandler {
+func NewConfigureMonitoringEventHandler(event ConfigureMonitoringAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface, shipyardClient keptn.ShipyardClientInterface, cfg *env.Config) ConfigureMonitoringEventHandler {
 	return ConfigureMonitoringEventHandler{
 		event:          event,
 		dtClient:       dtClient,
 		kClient:        kClient,
 		resourceClient: resourceClient,
 		serviceClient:  serviceClient,
+		shipyardClient: shipyardClient,
+		cfg:            cfg,
 	}
 }
 
+// WithManagedEnvironments returns a copy of the handler that also configures monitoring in the environments of a Dynatrace Managed cluster
+func (eh ConfigureMonitoringEventHandler) WithManagedEnvironments(environments []ManagedEnvironment) ConfigureMonitoringEventHandler {
+	eh.managedEnvironments = environments
+	return eh
+}
+
+// HandleEvent handles a legacy configure monitoring event, which is not part of a sequence and therefore only gets a finished event
 func (eh ConfigureMonitoringEventHandler) HandleEvent() error {
-	err := eh.configureMonitoring()
+	if eh.event.IsNotForDynatrace() {
+		return nil
+	}
+
+	factory, err := eh.HandleTask()
 	if err != nil {
 		log.WithError(err).Error("Configure monitoring failed")
+		factory = NewFailureEventFactory(eh.event, err)
 	}
-	return nil
+
+	return eh.sendConfigureMonitoringFinishedEvent(factory)
 }
 
-func (eh *ConfigureMonitoringEventHandler) configureMonitoring() error {
-	log.Info("Configuring Dynatrace monitoring")
-	if eh.event.IsNotForDynatrace() {
-		return nil
+// HandleTask configures Dynatrace monitoring and returns the factory for the configure-monitoring.finished event
+func (eh ConfigureMonitoringEventHandler) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	message, err := eh.configureMonitoring()
+	if err != nil {
+		return nil, err
 	}
 
+	return NewSuccessEventFactory(eh.event, message), nil
+}
+
+func (eh *ConfigureMonitoringEventHandler) configureMonitoring() (string, error) {
+	log.Info("Configuring Dynatrace monitoring")
+
 	keptnAPICheck := &KeptnAPIConnectionCheck{}
 	// check the connection to the Keptn API
-	keptnCredentials, err := credentials.GetKeptnCredentials()
+	keptnCredentials, err := credentials.GetKeptnCredentials(eh.cfg)
 	if err != nil {
 		log.WithError(err).Error("Failed to get Keptn API credentials")
 		keptnAPICheck.Message = "Failed to get Keptn API Credentials"
@@ -64,7 +105,7 @@ func (eh *ConfigureMonitoringEventHandler) configureMonitoring() error {
 		keptnAPICheck.APIURL = keptnCredentials.APIURL
 		log.WithField("apiUrl", keptnCredentials.APIURL).Print("Verifying access to Keptn API")
 
-		err = credentials.CheckKeptnConnection(keptnCredentials)
+		err = credentials.CheckKeptnConnection(eh.cfg, keptnCredentials)
 		if err != nil {
 			keptnAPICheck.ConnectionSuccessful = false
 			keptnAPICheck.Message = "Warning: Keptn API connection cannot be verified. This might be due to a no-loopback policy of your LoadBalancer. The endpoint might still be reachable from outside the cluster."
@@ -74,30 +115,98 @@ func (eh *ConfigureMonitoringEventHandler) configureMonitoring() error {
 		}
 	}
 
+	tokenCheck := eh.checkDynatraceAPIToken()
+
 	var shipyard *keptnv2.Shipyard
 	if eh.event.GetProject() != "" {
-		shipyard, err = eh.kClient.GetShipyard()
+		shipyard, err = eh.shipyardClient.GetShipyard(eh.event.GetProject())
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if eh.event.IsPlan() {
+		log.Info("Planning Dynatrace monitoring without applying changes")
+		planningClient := dynatrace.NewPlanningClient(eh.dtClient)
+		plannedEntities, err := NewConfiguration(planningClient, eh.kClient, eh.resourceClient, eh.serviceClient, NewGenerationSteps(eh.cfg), eh.cfg).ConfigureMonitoring(eh.event.GetProject(), shipyard)
 		if err != nil {
-			return eh.handleError(err)
+			return "", err
+		}
+
+		managedEnvironmentsMessage := ""
+		for _, environment := range eh.managedEnvironments {
+			log.WithField("environment", environment.Name).Info("Planning Dynatrace monitoring in Dynatrace Managed environment")
+			environmentPlanningClient := dynatrace.NewPlanningClient(environment.Client)
+			environmentEntities, err := NewConfiguration(environmentPlanningClient, eh.kClient, eh.resourceClient, eh.serviceClient, NewGenerationSteps(eh.cfg), eh.cfg).ConfigureMonitoring(eh.event.GetProject(), shipyard)
+			if err != nil {
+				return "", fmt.Errorf("could not plan monitoring in Dynatrace environment %s: %w", environment.Name, err)
+			}
+			managedEnvironmentsMessage += getManagedEnvironmentMessage(environment.Name, getPlannedChangesMessage(environmentPlanningClient.GetPlannedChanges())+getPlanFailuresMessage(environmentEntities))
 		}
+
+		return getConfigureMonitoringPlanMessage(keptnAPICheck, tokenCheck, planningClient.GetPlannedChanges(), plannedEntities, managedEnvironmentsMessage), nil
 	}
 
-	cfg := NewConfiguration(eh.dtClient, eh.kClient, eh.resourceClient, eh.serviceClient)
+	cfg := NewConfiguration(eh.dtClient, eh.kClient, eh.resourceClient, eh.serviceClient, NewGenerationSteps(eh.cfg), eh.cfg)
 
 	configuredEntities, err := cfg.ConfigureMonitoring(eh.event.GetProject(), shipyard)
 	if err != nil {
-		return eh.handleError(err)
+		return "", err
+	}
+
+	managedEnvironmentsMessage := ""
+	for _, environment := range eh.managedEnvironments {
+		log.WithField("environment", environment.Name).Info("Configuring Dynatrace monitoring in Dynatrace Managed environment")
+		environmentEntities, err := NewConfiguration(environment.Client, eh.kClient, eh.resourceClient, eh.serviceClient, NewGenerationSteps(eh.cfg), eh.cfg).ConfigureMonitoring(eh.event.GetProject(), shipyard)
+		if err != nil {
+			return "", fmt.Errorf("could not configure monitoring in Dynatrace environment %s: %w", environment.Name, err)
+		}
+		managedEnvironmentsMessage += getManagedEnvironmentMessage(environment.Name, getConfiguredEntitiesMessage(environmentEntities))
 	}
 
 	log.Info("Dynatrace Monitoring setup done")
-	return eh.handleSuccess(getConfigureMonitoringResultMessage(keptnAPICheck, configuredEntities))
+	return getConfigureMonitoringResultMessage(keptnAPICheck, tokenCheck, configuredEntities, managedEnvironmentsMessage), nil
+}
+
+// checkDynatraceAPIToken verifies that the Dynatrace API token has the scopes required for the enabled features
+func (eh *ConfigureMonitoringEventHandler) checkDynatraceAPIToken() *DynatraceAPITokenCheck {
+	log.Info("Verifying scopes of Dynatrace API token")
+
+	missingScopes, err := dynatrace.NewAPITokensClient(eh.dtClient).GetMissingScopes(eh.cfg)
+	if err != nil {
+		log.WithError(err).Warn("Could not verify scopes of Dynatrace API token")
+		return &DynatraceAPITokenCheck{
+			Message: "Warning: scopes of the Dynatrace API token cannot be verified: " + err.Error(),
+		}
+	}
+
+	if len(missingScopes) > 0 {
+		log.WithField("missingScopes", missingScopes).Warn("Dynatrace API token is missing required scopes")
+		return &DynatraceAPITokenCheck{
+			MissingScopes: missingScopes,
+			Message:       "Warning: the Dynatrace API token is missing the following scopes required for the enabled features: " + strings.Join(missingScopes, ", "),
+		}
+	}
+
+	return &DynatraceAPITokenCheck{
+		Message: "The Dynatrace API token has all scopes required for the enabled features",
+	}
 }
 
-func getConfigureMonitoringResultMessage(apiCheck *KeptnAPIConnectionCheck, entities *ConfiguredEntities) string {
+func getConfigureMonitoringResultMessage(apiCheck *KeptnAPIConnectionCheck, tokenCheck *DynatraceAPITokenCheck, entities *ConfiguredEntities, managedEnvironmentsMessage string) string {
 	if entities == nil {
 		return ""
 	}
-	msg := "Dynatrace monitoring setup done.\nThe following entities have been configured:\n\n"
+
+	return "Dynatrace monitoring setup done.\nThe following entities have been configured:\n\n" + getConfiguredEntitiesMessage(entities) + managedEnvironmentsMessage + getChecksMessage(apiCheck, tokenCheck)
+}
+
+// getConfiguredEntitiesMessage lists the results of configuring the entities
+func getConfiguredEntitiesMessage(entities *ConfiguredEntities) string {
+	if entities == nil {
+		return ""
+	}
+	msg := ""
 
 	if entities.ManagementZonesEnabled && len(entities.ManagementZones) > 0 {
 		msg = msg + "---Management Zones:--- \n"
@@ -147,6 +256,95 @@ func getConfigureMonitoringResultMessage(apiCheck *KeptnAPIConnectionCheck, enti
 		msg = msg + "\n\n"
 	}
 
+	return msg
+}
+
+// getConfigureMonitoringPlanMessage lists the changes configure-monitoring would apply to the Dynatrace configuration
+func getConfigureMonitoringPlanMessage(apiCheck *KeptnAPIConnectionCheck, tokenCheck *DynatraceAPITokenCheck, changes []dynatrace.PlannedChange, entities *ConfiguredEntities, managedEnvironmentsMessage string) string {
+	return "Dynatrace monitoring plan - no changes have been applied.\n" + getPlannedChangesMessage(changes) + getPlanFailuresMessage(entities) + managedEnvironmentsMessage + getChecksMessage(apiCheck, tokenCheck)
+}
+
+// getPlanFailuresMessage lists the entities that could not be planned, e.g. because the existing configuration could not be read.
+// The planned changes are incomplete for these entities
+func getPlanFailuresMessage(entities *ConfiguredEntities) string {
+	failures := getFailedConfigResults(entities)
+	if len(failures) == 0 {
+		return ""
+	}
+
+	msg := "The following entities could not be planned, the plan is incomplete:\n\n"
+	for _, failure := range failures {
+		msg = msg + "  - " + failure.Name + ": Error: " + failure.Message + "\n"
+	}
+	return msg + "\n"
+}
+
+// getFailedConfigResults returns the results of the enabled entities that could not be configured
+func getFailedConfigResults(entities *ConfiguredEntities) []ConfigResult {
+	if entities == nil {
+		return nil
+	}
+
+	var results []ConfigResult
+	if entities.ManagementZonesEnabled {
+		results = append(results, entities.ManagementZones...)
+	}
+	if entities.TaggingRulesEnabled {
+		results = append(results, entities.TaggingRules...)
+	}
+	if entities.ProblemNotificationsEnabled {
+		results = append(results, withDefaultName(entities.ProblemNotifications, "Problem Notification"))
+	}
+	if entities.MetricEventsEnabled {
+		results = append(results, entities.MetricEvents...)
+	}
+	// the dashboard is only set up if there is a project
+	if entities.DashboardEnabled && entities.Dashboard.Message != "" {
+		results = append(results, withDefaultName(entities.Dashboard, "Dashboard"))
+	}
+
+	var failures []ConfigResult
+	for _, result := range results {
+		if !result.Success {
+			failures = append(failures, result)
+		}
+	}
+	return failures
+}
+
+func withDefaultName(result ConfigResult, name string) ConfigResult {
+	if result.Name == "" {
+		result.Name = name
+	}
+	return result
+}
+
+// getPlannedChangesMessage lists the planned changes
+func getPlannedChangesMessage(changes []dynatrace.PlannedChange) string {
+	if len(changes) == 0 {
+		return "The Dynatrace configuration is up to date, no changes would be applied.\n\n"
+	}
+
+	msg := "The following changes would be applied:\n\n"
+	for _, change := range changes {
+		msg = msg + "  - " + change.String() + "\n"
+	}
+	return msg + "\n"
+}
+
+// getManagedEnvironmentMessage returns the part of the message describing the changes in an environment of a Dynatrace Managed cluster
+func getManagedEnvironmentMessage(environmentName string, message string) string {
+	return "===Dynatrace environment " + environmentName + ":=== \n\n" + message
+}
+
+func getChecksMessage(apiCheck *KeptnAPIConnectionCheck, tokenCheck *DynatraceAPITokenCheck) string {
+	msg := ""
+	if tokenCheck != nil {
+		msg = msg + "---Dynatrace API Token Check:--- \n"
+		msg = msg + "  - " + tokenCheck.Message + "\n"
+		msg = msg + "\n"
+	}
+
 	if apiCheck != nil {
 		msg = msg + "---Keptn API Connection Check:--- \n"
 		msg = msg + "  - Keptn API URL: " + apiCheck.APIURL + "\n"
@@ -157,16 +355,7 @@ func getConfigureMonitoringResultMessage(apiCheck *KeptnAPIConnectionCheck, enti
 	return msg
 }
 
-func (eh *ConfigureMonitoringEventHandler) handleError(err error) error {
-	log.Error(err)
-	return eh.sendConfigureMonitoringFinishedEvent(NewFailureEventFactory(eh.event, err.Error()))
-}
-
-func (eh *ConfigureMonitoringEventHandler) handleSuccess(message string) error {
-	return eh.sendConfigureMonitoringFinishedEvent(NewSuccessEventFactory(eh.event, message))
-}
-
-func (eh *ConfigureMonitoringEventHandler) sendConfigureMonitoringFinishedEvent(factory adapter.CloudEventFactoryInterface) error {
+func (eh ConfigureMonitoringEventHandler) sendConfigureMonitoringFinishedEvent(factory adapter.CloudEventFactoryInterface) error {
 	if err := eh.kClient.SendCloudEvent(factory); err != nil {
 		log.WithError(err).Error("Failed to send configure monitoring finished event")
 		return err
@@ -0,0 +1,46 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// Tests that the plan reports entities that could not be read from Dynatrace, because their planned changes are incomplete
+func TestGetConfigureMonitoringPlanMessage_ReportsReadFailures(t *testing.T) {
+	entities := &ConfiguredEntities{
+		TaggingRulesEnabled: true,
+		TaggingRules: []ConfigResult{
+			{Name: "keptn_service", Success: true},
+			{Name: "keptn_stage", Success: false, Message: "could not read auto tags: 500"},
+		},
+		ProblemNotificationsEnabled: true,
+		ProblemNotifications:        ConfigResult{Success: false, Message: "failed to set up problem notification: 403"},
+		DashboardEnabled:            true,
+		MetricEventsEnabled:         false,
+		MetricEvents:                []ConfigResult{{Name: "disabled", Success: false, Message: "ignored"}},
+	}
+
+	message := getConfigureMonitoringPlanMessage(nil, nil, []dynatrace.PlannedChange{}, entities, "")
+
+	assert.Contains(t, message, "The following entities could not be planned, the plan is incomplete:")
+	assert.Contains(t, message, "  - keptn_stage: Error: could not read auto tags: 500\n")
+	assert.Contains(t, message, "  - Problem Notification: Error: failed to set up problem notification: 403\n")
+	assert.NotContains(t, message, "keptn_service")
+	assert.NotContains(t, message, "Dashboard")
+	assert.NotContains(t, message, "ignored")
+}
+
+// Tests that the plan does not mention failures if all entities could be planned
+func TestGetConfigureMonitoringPlanMessage_NoFailures(t *testing.T) {
+	entities := &ConfiguredEntities{
+		TaggingRulesEnabled: true,
+		TaggingRules:        []ConfigResult{{Name: "keptn_service", Success: true}},
+	}
+
+	message := getConfigureMonitoringPlanMessage(nil, nil, []dynatrace.PlannedChange{}, entities, "")
+
+	assert.NotContains(t, message, "could not be planned")
+}
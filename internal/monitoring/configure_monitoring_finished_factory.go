@@ -3,6 +3,7 @@ package monitoring
 import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -26,12 +27,13 @@ func NewSuccessEventFactory(eventData ConfigureMonitoringAdapterInterface, messa
 	}
 }
 
-func NewFailureEventFactory(eventData ConfigureMonitoringAdapterInterface, message string) *ConfigureMonitoringFinishedEventFactory {
+func NewFailureEventFactory(eventData ConfigureMonitoringAdapterInterface, err error) *ConfigureMonitoringFinishedEventFactory {
+	status, result := common.GetFinishedEventStatusAndResult(err)
 	return &ConfigureMonitoringFinishedEventFactory{
 		eventData: eventData,
-		status:    keptnv2.StatusErrored,
-		result:    keptnv2.ResultFailed,
-		message:   message,
+		status:    status,
+		result:    result,
+		message:   common.GetFinishedEventMessage(err),
 	}
 }
 
@@ -39,7 +41,9 @@ func (f *ConfigureMonitoringFinishedEventFactory) getEventFactory(status keptnv2
 	cmFinishedEvent := &keptnv2.ConfigureMonitoringFinishedEventData{
 		EventData: keptnv2.EventData{
 			Project: f.eventData.GetProject(),
+			Stage:   f.eventData.GetStage(),
 			Service: f.eventData.GetService(),
+			Labels:  f.eventData.GetLabels(),
 			Status:  status,
 			Result:  result,
 			Message: message,
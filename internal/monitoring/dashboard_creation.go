@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
-	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
 )
@@ -28,10 +27,6 @@ func NewDashboardCreation(client dynatrace.ClientInterface) *DashboardCreation {
 
 // Create creates a new dashboard for the provided project
 func (dc *DashboardCreation) Create(project string, shipyard keptnv2.Shipyard) ConfigResult {
-	if !env.IsDashboardsGenerationEnabled() {
-		return ConfigResult{}
-	}
-
 	// first, check if dashboard for this project already exists and delete that
 	dashboardClient := dynatrace.NewDashboardsClient(dc.client)
 	err := deleteExistingDashboard(project, dashboardClient)
@@ -2,7 +2,6 @@ package monitoring
 
 import (
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
-	"github.com/keptn-contrib/dynatrace-service/internal/env"
 
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
@@ -20,10 +19,6 @@ func NewManagementZoneCreation(client dynatrace.ClientInterface) *ManagementZone
 
 // Create creates a new management zone for the project
 func (mzc *ManagementZoneCreation) Create(project string, shipyard keptnv2.Shipyard) []ConfigResult {
-	if !env.IsManagementZonesGenerationEnabled() {
-		return nil
-	}
-
 	// get existing management zones
 	managementZoneClient := dynatrace.NewManagementZonesClient(mzc.client)
 	managementZoneNames, err := managementZoneClient.GetAll()
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
-	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	keptnlib "github.com/keptn/go-utils/pkg/lib"
 
@@ -42,10 +41,6 @@ func NewMetricEventCreation(dynatraceClient dynatrace.ClientInterface, keptnClie
 
 // Create creates new metric events if SLOs are specified
 func (mec MetricEventCreation) Create(project string, stage string, service string) []ConfigResult {
-	if !env.IsMetricEventsGenerationEnabled() {
-		return nil
-	}
-
 	log.Info("Creating custom metric events for project SLIs")
 	slos, err := mec.sloReader.GetSLOs(project, stage, service)
 	if err != nil {
@@ -81,7 +76,7 @@ func (mec MetricEventCreation) Create(project string, stage string, service stri
 		return nil
 	}
 
-	metricEventsClient := dynatrace.NewMetricEventsClient(mec.dtClient)
+	metricEventsClient := getMetricEventsClient(mec.dtClient)
 	var metricsEventResults []ConfigResult
 	// try to create metric events using best effort.
 	for _, objective := range slos.Objectives {
@@ -111,7 +106,15 @@ func (mec MetricEventCreation) Create(project string, stage string, service stri
 	return metricsEventResults
 }
 
-func setupAllMetricEvents(client *dynatrace.MetricEventsClient, project string, stage string, service string, slo *keptnlib.SLO, query string, managementZoneID int64) []ConfigResult {
+// getMetricEventsClient returns a client using Settings 2.0 if the tenant supports it, or the config API v1 otherwise
+func getMetricEventsClient(client dynatrace.ClientInterface) dynatrace.MetricEventsClientInterface {
+	if isSettingsSchemaSupported(client, dynatrace.MetricEventsSchemaID) {
+		return dynatrace.NewMetricEventsSettingsClient(dynatrace.NewSettingsClient(client))
+	}
+	return dynatrace.NewMetricEventsClient(client)
+}
+
+func setupAllMetricEvents(client dynatrace.MetricEventsClientInterface, project string, stage string, service string, slo *keptnlib.SLO, query string, managementZoneID int64) []ConfigResult {
 	var metricEventsResults []ConfigResult
 	for _, criteria := range slo.Pass {
 		for _, crit := range criteria.Criteria {
@@ -128,7 +131,7 @@ func setupAllMetricEvents(client *dynatrace.MetricEventsClient, project string,
 	return metricEventsResults
 }
 
-func setupSingleMetricEvent(client *dynatrace.MetricEventsClient, project string, stage string, service string, metric string, query string, crit string, managementZoneID int64) (*ConfigResult, error) {
+func setupSingleMetricEvent(client dynatrace.MetricEventsClientInterface, project string, stage string, service string, metric string, query string, crit string, managementZoneID int64) (*ConfigResult, error) {
 	// criteria.Criteria
 	criteriaObject, err := parseCriteriaString(crit)
 	if err != nil {
@@ -166,7 +169,7 @@ func setupSingleMetricEvent(client *dynatrace.MetricEventsClient, project string
 	}, nil
 }
 
-func createOrUpdateMetricEvent(client *dynatrace.MetricEventsClient, newMetricEvent *dynatrace.MetricEvent) error {
+func createOrUpdateMetricEvent(client dynatrace.MetricEventsClientInterface, newMetricEvent *dynatrace.MetricEvent) error {
 	existingMetricEvent, err := client.GetMetricEventByName(newMetricEvent.Name)
 	if err != nil {
 		return err
@@ -11,24 +11,22 @@ import (
 
 type ProblemNotificationCreation struct {
 	client dynatrace.ClientInterface
+	cfg    *env.Config
 }
 
-func NewProblemNotificationCreation(client dynatrace.ClientInterface) *ProblemNotificationCreation {
+func NewProblemNotificationCreation(client dynatrace.ClientInterface, cfg *env.Config) *ProblemNotificationCreation {
 	return &ProblemNotificationCreation{
 		client: client,
+		cfg:    cfg,
 	}
 }
 
 // Create sets up/updates the DT problem notification and returns it
 func (pn *ProblemNotificationCreation) Create() ConfigResult {
-	if !env.IsProblemNotificationsGenerationEnabled() {
-		return ConfigResult{}
-	}
-
 	log.Info("Setting up problem notifications in Dynatrace Tenant")
 
-	alertingProfileId, err := getOrCreateKeptnAlertingProfile(
-		dynatrace.NewAlertingProfilesClient(pn.client))
+	alertingProfilesClient, notificationsClient := getProblemNotificationClients(pn.client)
+	alertingProfileId, err := getOrCreateKeptnAlertingProfile(alertingProfilesClient)
 	if err != nil {
 		log.WithError(err).Error("Failed to set up problem notification")
 		return ConfigResult{
@@ -37,13 +35,12 @@ func (pn *ProblemNotificationCreation) Create() ConfigResult {
 		}
 	}
 
-	notificationsClient := dynatrace.NewNotificationsClient(pn.client)
 	err = notificationsClient.DeleteExistingKeptnProblemNotifications()
 	if err != nil {
 		log.WithError(err).Error("failed to delete existing notifications")
 	}
 
-	keptnCredentials, err := credentials.GetKeptnCredentials()
+	keptnCredentials, err := credentials.GetKeptnCredentials(pn.cfg)
 	if err != nil {
 		log.WithError(err).Error("Failed to retrieve Keptn API credentials")
 		return ConfigResult{
@@ -67,7 +64,17 @@ func (pn *ProblemNotificationCreation) Create() ConfigResult {
 	}
 }
 
-func getOrCreateKeptnAlertingProfile(alertingProfilesClient *dynatrace.AlertingProfilesClient) (string, error) {
+// getProblemNotificationClients returns clients using Settings 2.0 if the tenant supports it for both alerting profiles and problem notifications, or the config API v1 otherwise.
+// Both have to use the same API, as the notification references the alerting profile by its ID
+func getProblemNotificationClients(client dynatrace.ClientInterface) (dynatrace.AlertingProfilesClientInterface, dynatrace.NotificationsClientInterface) {
+	if isSettingsSchemaSupported(client, dynatrace.AlertingProfileSchemaID) && isSettingsSchemaSupported(client, dynatrace.ProblemNotificationsSchemaID) {
+		settingsClient := dynatrace.NewSettingsClient(client)
+		return dynatrace.NewAlertingProfilesSettingsClient(settingsClient), dynatrace.NewNotificationsSettingsClient(settingsClient)
+	}
+	return dynatrace.NewAlertingProfilesClient(client), dynatrace.NewNotificationsClient(client)
+}
+
+func getOrCreateKeptnAlertingProfile(alertingProfilesClient dynatrace.AlertingProfilesClientInterface) (string, error) {
 	log.Info("Checking Keptn alerting profile availability")
 	alertingProfileID, err := alertingProfilesClient.GetProfileID("Keptn")
 	if err != nil {
@@ -2,6 +2,7 @@ package monitoring
 
 import (
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	log "github.com/sirupsen/logrus"
 )
@@ -12,16 +13,18 @@ type ProjectCreateFinishedEventHandler struct {
 	kClient        keptn.ClientInterface
 	resourceClient keptn.ResourceClientInterface
 	serviceClient  keptn.ServiceClientInterface
+	cfg            *env.Config
 }
 
 // NewProjectCreateFinishedEventHandler creates a new ProjectCreateFinishedEventHandler
-func NewProjectCreateFinishedEventHandler(event ProjectCreateFinishedAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface) ProjectCreateFinishedEventHandler {
+func NewProjectCreateFinishedEventHandler(event ProjectCreateFinishedAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, serviceClient keptn.ServiceClientInterface, cfg *env.Config) ProjectCreateFinishedEventHandler {
 	return ProjectCreateFinishedEventHandler{
 		event:          event,
 		dtClient:       dtClient,
 		kClient:        kClient,
 		resourceClient: resourceClient,
 		serviceClient:  serviceClient,
+		cfg:            cfg,
 	}
 }
 
@@ -31,7 +34,7 @@ func (eh ProjectCreateFinishedEventHandler) HandleEvent() error {
 		log.WithError(err).Error("Could not load Keptn shipyard file")
 	}
 
-	cfg := NewConfiguration(eh.dtClient, eh.kClient, eh.resourceClient, eh.serviceClient)
+	cfg := NewConfiguration(eh.dtClient, eh.kClient, eh.resourceClient, eh.serviceClient, NewGenerationSteps(eh.cfg), eh.cfg)
 
 	_, err = cfg.ConfigureMonitoring(eh.event.GetProject(), shipyard)
 	if err != nil {
@@ -0,0 +1,40 @@
+package nats
+
+import (
+	"crypto/tls"
+	"time"
+
+	natsgo "github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+)
+
+const clientName = "dynatrace-service"
+const reconnectWait = 2 * time.Second
+
+// Connect connects to the NATS server at the given URL, e.g. nats://keptn-nats-cluster:4222. If the server requires TLS or the URL uses
+// the tls scheme, TLS is used with tlsConfig, which may be nil to use the default configuration.
+// The connection reconnects and resubscribes on its own if it is lost, it is only closed by calling Close
+func Connect(natsURL string, tlsConfig *tls.Config) (*natsgo.Conn, error) {
+	return natsgo.Connect(natsURL,
+		natsgo.Name(clientName),
+		withTLSConfig(tlsConfig),
+		natsgo.MaxReconnects(-1),
+		natsgo.ReconnectWait(reconnectWait),
+		natsgo.DisconnectErrHandler(func(_ *natsgo.Conn, err error) {
+			if err != nil {
+				log.WithError(err).Warn("Lost connection to NATS, reconnecting")
+			}
+		}),
+		natsgo.ReconnectHandler(func(conn *natsgo.Conn) {
+			log.WithField("url", conn.ConnectedUrl()).Info("Reconnected to NATS")
+		}),
+	)
+}
+
+// withTLSConfig sets the configuration used if the server requires TLS. Unlike natsgo.Secure, it does not require TLS for servers not supporting it
+func withTLSConfig(tlsConfig *tls.Config) natsgo.Option {
+	return func(o *natsgo.Options) error {
+		o.TLSConfig = tlsConfig
+		return nil
+	}
+}
@@ -0,0 +1,127 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	natsgo "github.com/nats-io/nats.go"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxReconnectDelay = 30 * time.Second
+
+// EventHandlerFunc handles a received event
+type EventHandlerFunc func(ctx context.Context, event cloudevents.Event) error
+
+// Receiver receives events by subscribing to the Keptn message bus directly instead of via a distributor.
+// All instances of the dynatrace-service use the same queue group, so that each event is only handled by one of them
+type Receiver struct {
+	topics     []string
+	queueGroup string
+	handler    EventHandlerFunc
+
+	// handlerSlots limits the number of events handled concurrently, receiving further messages blocks until a slot is free
+	handlerSlots chan struct{}
+
+	connect func() (*natsgo.Conn, error)
+	after   func(d time.Duration) <-chan time.Time
+}
+
+// NewDefaultReceiver creates a new Receiver as specified by the configuration
+func NewDefaultReceiver(cfg *env.Config, handler EventHandlerFunc) *Receiver {
+	connect := func() (*natsgo.Conn, error) {
+		return GetDefaultConn(cfg)
+	}
+	return NewReceiver(connect, cfg.NATSTopics, cfg.NATSQueueGroup, cfg.NATSMaxConcurrentEvents, handler)
+}
+
+// NewReceiver creates a new Receiver using the connection returned by connect that handles at most maxConcurrentEvents events at the same time
+func NewReceiver(connect func() (*natsgo.Conn, error), topics []string, queueGroup string, maxConcurrentEvents int, handler EventHandlerFunc) *Receiver {
+	if maxConcurrentEvents < 1 {
+		maxConcurrentEvents = 1
+	}
+
+	return &Receiver{
+		topics:       topics,
+		queueGroup:   queueGroup,
+		handler:      handler,
+		handlerSlots: make(chan struct{}, maxConcurrentEvents),
+		connect:      connect,
+		after:        time.After,
+	}
+}
+
+// Run subscribes to the topics and handles events until the context is canceled. If the connection cannot be established, it is retried.
+// Once subscribed, the connection reconnects and resubscribes on its own if it is lost
+func (r *Receiver) Run(ctx context.Context) {
+	delay := time.Second
+	for ctx.Err() == nil {
+		conn, err := r.connect()
+		var subscriptions []*natsgo.Subscription
+		if err == nil {
+			subscriptions, err = r.subscribe(ctx, conn)
+		}
+
+		if err == nil {
+			log.WithFields(log.Fields{"topics": r.topics, "queueGroup": r.queueGroup}).Info("Subscribed to NATS")
+			<-ctx.Done()
+			unsubscribe(subscriptions)
+			return
+		}
+
+		unsubscribe(subscriptions)
+		log.WithError(err).WithField("retryIn", delay).Error("Could not subscribe to NATS")
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.after(delay):
+		}
+
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+func (r *Receiver) subscribe(ctx context.Context, conn *natsgo.Conn) ([]*natsgo.Subscription, error) {
+	subscriptions := make([]*natsgo.Subscription, 0, len(r.topics))
+	for _, topic := range r.topics {
+		subscription, err := conn.QueueSubscribe(topic, r.queueGroup, func(msg *natsgo.Msg) {
+			r.handlerSlots <- struct{}{}
+			go func() {
+				defer func() { <-r.handlerSlots }()
+				r.handleMessage(ctx, msg.Subject, msg.Data)
+			}()
+		})
+		if err != nil {
+			return subscriptions, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, nil
+}
+
+func unsubscribe(subscriptions []*natsgo.Subscription) {
+	for _, subscription := range subscriptions {
+		if err := subscription.Unsubscribe(); err != nil {
+			log.WithError(err).WithField("subject", subscription.Subject).Warn("Could not unsubscribe from NATS")
+		}
+	}
+}
+
+func (r *Receiver) handleMessage(ctx context.Context, subject string, data []byte) {
+	event := cloudevents.NewEvent()
+	if err := json.Unmarshal(data, &event); err != nil {
+		log.WithError(err).WithField("subject", subject).Error("Could not decode event received from NATS")
+		return
+	}
+
+	if err := r.handler(ctx, event); err != nil {
+		log.WithError(err).WithField("eventID", event.ID()).Error("Failed to handle event received from NATS")
+	}
+}
@@ -0,0 +1,150 @@
+package nats
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	natsserver "github.com/nats-io/nats-server/v2/test"
+	natsgo "github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingAfter returns an after function that records the requested delays and fires immediately
+func recordingAfter(delays *[]time.Duration) func(d time.Duration) <-chan time.Time {
+	return func(d time.Duration) <-chan time.Time {
+		*delays = append(*delays, d)
+		c := make(chan time.Time, 1)
+		c <- time.Now()
+		return c
+	}
+}
+
+// runTestServer runs a NATS server on a random port until the test finished and returns its URL
+func runTestServer(t *testing.T) string {
+	opts := natsserver.DefaultTestOptions
+	opts.Port = -1
+	server := natsserver.RunServer(&opts)
+	t.Cleanup(server.Shutdown)
+	return server.ClientURL()
+}
+
+func publishTestEvent(t *testing.T, conn *natsgo.Conn, id string) {
+	event := fmt.Sprintf(`{"specversion":"1.0","id":"%s","source":"test","type":"sh.keptn.event.get-sli.triggered"}`, id)
+	assert.NoError(t, conn.Publish("sh.keptn.event.get-sli.triggered", []byte(event)))
+}
+
+func TestReceiver_ReconnectsWithExponentialBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	attempts := 0
+	var delays []time.Duration
+	connect := func() (*natsgo.Conn, error) {
+		attempts++
+		if attempts == 7 {
+			cancel()
+		}
+		return nil, errors.New("connection refused")
+	}
+	r := NewReceiver(connect, []string{"sh.keptn.>"}, "dynatrace-service", 1, nil)
+	r.after = recordingAfter(&delays)
+
+	r.Run(ctx)
+
+	assert.Equal(t, 7, attempts)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}, delays)
+}
+
+func TestReceiver_LimitsConcurrentlyHandledEvents(t *testing.T) {
+	url := runTestServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscribed := make(chan struct{})
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	connect := func() (*natsgo.Conn, error) {
+		defer close(subscribed)
+		return Connect(url, nil)
+	}
+	r := NewReceiver(connect, []string{"sh.keptn.>"}, "dynatrace-service", 1, func(ctx context.Context, event cloudevents.Event) error {
+		started <- event.ID()
+		<-release
+		return nil
+	})
+	go r.Run(ctx)
+	<-subscribed
+
+	publisher, err := Connect(url, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer publisher.Close()
+
+	// the subscription is only registered with the server once the receiver flushed it, so retry until the first event arrives
+	var firstID string
+	for i := 0; firstID == ""; i++ {
+		publishTestEvent(t, publisher, fmt.Sprint(i))
+		select {
+		case firstID = <-started:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	publishTestEvent(t, publisher, "last")
+	select {
+	case id := <-started:
+		t.Errorf("event %s was handled while the only handler slot was taken", id)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	for id := range started {
+		if id == "last" {
+			break
+		}
+	}
+}
+
+func TestReceiver_UnsubscribesWhenContextIsCanceled(t *testing.T) {
+	url := runTestServer(t)
+
+	conn, err := Connect(url, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connect := func() (*natsgo.Conn, error) {
+		return conn, nil
+	}
+	r := NewReceiver(connect, []string{"sh.keptn.>", "sh.keptn.event.>"}, "dynatrace-service", 1, nil)
+
+	done := make(chan struct{})
+	go func() {
+		r.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return conn.NumSubscriptions() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+	assert.Equal(t, 0, conn.NumSubscriptions())
+}
+
+func TestWithTLSConfig(t *testing.T) {
+	tlsConfig := &tls.Config{ServerName: "keptn-nats"}
+	options := natsgo.GetDefaultOptions()
+
+	assert.NoError(t, withTLSConfig(tlsConfig)(&options))
+	assert.Same(t, tlsConfig, options.TLSConfig)
+	assert.False(t, options.Secure, "TLS is only used if the server requires it")
+}
@@ -0,0 +1,70 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptnhttp"
+	natsgo "github.com/nats-io/nats.go"
+)
+
+var defaultConn *natsgo.Conn
+var defaultConnMutex sync.Mutex
+
+// GetDefaultConn returns the connection to the NATS server of the configuration shared by the receiver and the event sender.
+// A new connection is established if there is none yet or the previous one was closed
+func GetDefaultConn(cfg *env.Config) (*natsgo.Conn, error) {
+	defaultConnMutex.Lock()
+	defer defaultConnMutex.Unlock()
+
+	if defaultConn != nil && !defaultConn.IsClosed() {
+		return defaultConn, nil
+	}
+
+	tlsConfig, err := keptnhttp.NewTLSConfig(cfg.NATSSSLVerificationEnabled, cfg.NATSCABundle)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := Connect(cfg.NATSURL, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultConn = conn
+	return defaultConn, nil
+}
+
+// EventSender publishes events to the Keptn message bus using the event type as subject
+type EventSender struct {
+	cfg *env.Config
+}
+
+// NewEventSender creates a new EventSender using the default connection
+func NewEventSender(cfg *env.Config) *EventSender {
+	return &EventSender{cfg: cfg}
+}
+
+// SendEvent publishes the event
+func (s *EventSender) SendEvent(event cloudevents.Event) error {
+	return s.Send(context.Background(), event)
+}
+
+// Send publishes the event
+func (s *EventSender) Send(ctx context.Context, event cloudevents.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal event: %w", err)
+	}
+
+	conn, err := GetDefaultConn(s.cfg)
+	if err != nil {
+		return err
+	}
+
+	return conn.Publish(event.Type(), data)
+}
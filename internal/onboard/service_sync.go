@@ -1,18 +1,20 @@
 package onboard
 
 import (
+	"context"
 	"fmt"
 	"time"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/lease"
 	keptnlib "github.com/keptn/go-utils/pkg/lib"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
-	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnapi "github.com/keptn/go-utils/pkg/api/utils"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
@@ -75,6 +77,7 @@ type serviceSynchronizer struct {
 	keptnHandler       *keptnv2.Keptn
 	servicesInKeptn    []string
 	dtConfigGetter     config.DynatraceConfigGetterInterface
+	cfg                *env.Config
 }
 
 var serviceSynchronizerInstance *serviceSynchronizer
@@ -82,20 +85,21 @@ var serviceSynchronizerInstance *serviceSynchronizer
 const shipyardController = "SHIPYARD_CONTROLLER"
 const defaultShipyardControllerURL = "http://shipyard-controller:8080"
 
-// ActivateServiceSynchronizer godoc
-func ActivateServiceSynchronizer(c credentials.CredentialManagerInterface) *serviceSynchronizer {
+// ActivateServiceSynchronizer starts synchronizing the services of Keptn with Dynatrace every syncInterval
+func ActivateServiceSynchronizer(c credentials.CredentialManagerInterface, syncInterval time.Duration, cfg *env.Config) *serviceSynchronizer {
 	if serviceSynchronizerInstance == nil {
 
 		serviceSynchronizerInstance = &serviceSynchronizer{
 			credentialManager: c,
+			cfg:               cfg,
 		}
 
-		resourceClient := keptn.NewDefaultResourceClient()
+		resourceClient := keptn.NewDefaultResourceClient(cfg)
 
-		serviceSynchronizerInstance.dtConfigGetter = config.NewDynatraceConfigGetter(resourceClient)
+		serviceSynchronizerInstance.dtConfigGetter = config.NewDynatraceConfigGetter(keptn.GetDefaultCachingDynatraceConfigResourceClient(cfg))
 		serviceSynchronizerInstance.EntitiesClientFunc =
 			func(credentials *credentials.DTCredentials) *dynatrace.EntitiesClient {
-				dtClient := dynatrace.NewClient(credentials)
+				dtClient := dynatrace.NewClient(credentials, cfg)
 				return dynatrace.NewEntitiesClient(dtClient)
 			}
 
@@ -107,27 +111,32 @@ func ActivateServiceSynchronizer(c credentials.CredentialManagerInterface) *serv
 				"shipyardControllerBaseURL": shipyardControllerBaseURL,
 			}).Debug("Initializing Service Synchronizer")
 
-		serviceSynchronizerInstance.projectClient = keptn.NewDefaultProjectClient()
-		serviceSynchronizerInstance.servicesClient = keptn.NewDefaultServiceClient()
+		serviceSynchronizerInstance.projectClient = keptn.GetDefaultCachingProjectMetadataClient(cfg)
+		serviceSynchronizerInstance.servicesClient = keptn.NewDefaultServiceClient(cfg)
 		serviceSynchronizerInstance.resourcesClient = resourceClient
 
-		serviceSynchronizerInstance.initializeSynchronizationTimer()
+		serviceSynchronizerInstance.initializeSynchronizationTimer(syncInterval)
 
 	}
 	return serviceSynchronizerInstance
 }
 
-func (s *serviceSynchronizer) initializeSynchronizationTimer() {
-	syncInterval := env.GetServiceSyncInterval()
+func (s *serviceSynchronizer) initializeSynchronizationTimer(syncInterval time.Duration) {
 	log.WithField("syncInterval", syncInterval).Info("Service Synchronizer will sync periodically")
-	s.syncTimer = time.NewTicker(time.Duration(syncInterval) * time.Second)
-	go func() {
+	s.syncTimer = time.NewTicker(syncInterval)
+
+	// with multiple replicas, only the leader synchronizes services to avoid creating them several times
+	go lease.RunWhileLeader(context.Background(), s.cfg, "service-synchronizer", func(ctx context.Context) {
 		for {
 			s.synchronizeServices()
-			<-s.syncTimer.C
-			log.WithField("delaySeconds", syncInterval).Info("Synchronizing services")
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.syncTimer.C:
+			}
+			log.WithField("syncInterval", syncInterval).Info("Synchronizing services")
 		}
-	}()
+	})
 }
 
 func (s *serviceSynchronizer) synchronizeServices() {
@@ -204,7 +213,7 @@ func (s *serviceSynchronizer) fetchExistingServices() error {
 
 	// get all services currently in the project
 	s.servicesInKeptn = []string{}
-	serviceNames, err := s.servicesClient.GetServiceNames(defaultDTProjectName, defaultDTProjectStage)
+	serviceNames, err := s.servicesClient.GetServiceNamesInAllStages(defaultDTProjectName)
 	if err != nil {
 		return err
 	}
@@ -239,6 +248,11 @@ func (s *serviceSynchronizer) addServiceToKeptn(serviceName string) error {
 		return fmt.Errorf("could not create service %s: %s", serviceName, err)
 	}
 
+	_, err = s.servicesClient.GetService(defaultDTProjectName, defaultDTProjectStage, serviceName)
+	if err != nil {
+		return fmt.Errorf("could not verify that service %s was created: %s", serviceName, err)
+	}
+
 	log.WithField("service", serviceName).Debug("Service is available. Proceeding with SLO upload.")
 
 	if err := s.createSLOResource(serviceName); err == nil {
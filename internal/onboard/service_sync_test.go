@@ -5,6 +5,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	adapter_mock "github.com/keptn-contrib/dynatrace-service/internal/adapter/mock"
 	credentials_mock "github.com/keptn-contrib/dynatrace-service/internal/credentials/mock"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"io/ioutil"
 	"net/http"
@@ -12,7 +13,6 @@ import (
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 	"testing"
 	"time"
 
@@ -161,6 +161,15 @@ func getTestConfigService() (chan string, chan string, chan string, *httptest.Se
 	mockCS := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
 		bytes, err := ioutil.ReadAll(request.Body)
 
+		// the shipyard-controller returns created services
+		if request.Method == http.MethodGet && strings.Contains(request.URL.Path, "/stage/") {
+			split := strings.Split(request.URL.Path, "/")
+			marshal, _ := json.Marshal(&models.Service{ServiceName: split[len(split)-1]})
+			writer.WriteHeader(http.StatusOK)
+			writer.Write(marshal)
+			return
+		}
+
 		if strings.HasSuffix(request.URL.String(), "dynatrace/service") {
 			createSvcParam := &createServiceParams{}
 			err = json.Unmarshal(bytes, createSvcParam)
@@ -350,32 +359,29 @@ func Test_serviceSynchronizer_synchronizeServices(t *testing.T) {
 	projectsMockAPI := getTestProjectsAPI()
 	defer projectsMockAPI.Close()
 
-	firstRequest := true
-	servicesMockAPI := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		// for the first request, return a list of the services already available in Keptn
-		if firstRequest {
-			svcList := &models.Services{
-				Services: []*models.Service{
-					{
-						ServiceName: "my-already-synced-service",
+	servicesMockAPI := getTestServicesAPI()
+	defer servicesMockAPI.Close()
+
+	// return the services already available in Keptn
+	stagesMockAPI := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		stages := &models.Stages{
+			Stages: []*models.Stage{
+				{
+					StageName: defaultDTProjectStage,
+					Services: []*models.Service{
+						{
+							ServiceName: "my-already-synced-service",
+						},
 					},
 				},
-			}
-			marshal, _ := json.Marshal(svcList)
-
-			writer.WriteHeader(http.StatusOK)
-			writer.Write(marshal)
-			return
-		}
-		svc := &models.Service{
-			ServiceName: "my-service",
+			},
 		}
-		marshal, _ := json.Marshal(svc)
+		marshal, _ := json.Marshal(stages)
 
 		writer.WriteHeader(http.StatusOK)
 		writer.Write(marshal)
 	}))
-	defer servicesMockAPI.Close()
+	defer stagesMockAPI.Close()
 
 	_, mockEventBroker := getTestMockEventBroker()
 	defer mockEventBroker.Close()
@@ -388,15 +394,15 @@ func Test_serviceSynchronizer_synchronizeServices(t *testing.T) {
 	k := getTestKeptnHandler(mockCS, mockEventBroker)
 	s := &serviceSynchronizer{
 		projectClient:   keptn.NewProjectClient(keptnapi.NewProjectHandler(projectsMockAPI.URL)),
-		servicesClient:  keptn.NewServiceClient(keptnapi.NewServiceHandler(servicesMockAPI.URL), mockCS.Client()),
-		resourcesClient: keptn.NewResourceClient(keptn.NewConfigResourceClient(keptnapi.NewResourceHandler(mockCS.URL))),
+		servicesClient:  keptn.NewServiceClient(keptnapi.NewStageHandler(stagesMockAPI.URL), mockCS.Client()),
+		resourcesClient: keptn.NewResourceClient(keptn.NewConfigResourceClient(keptnapi.NewResourceHandler(mockCS.URL)), env.JSONDashboardStorage),
 		EntitiesClientFunc: func(creds *credentials.DTCredentials) *dynatrace.EntitiesClient {
 			return dynatrace.NewEntitiesClient(
 				dynatrace.NewClient(
 					&credentials.DTCredentials{
 						Tenant:   dtMockServer.URL,
 						ApiToken: "",
-					}))
+					}, env.ReadConfig()))
 		},
 		syncTimer:       nil,
 		keptnHandler:    k,
@@ -534,7 +540,6 @@ func Test_serviceSynchronizer_addServiceToKeptn(t *testing.T) {
 		resourcesAPI      keptn.SLIAndSLOResourceWriterInterface
 		apiHandler        *keptnapi.APIHandler
 		credentialManager credentials.CredentialManagerInterface
-		apiMutex          sync.Mutex
 		EntitiesClient    func(*credentials.DTCredentials) *dynatrace.EntitiesClient
 		syncTimer         *time.Ticker
 		keptnHandler      *keptnv2.Keptn
@@ -555,9 +560,8 @@ func Test_serviceSynchronizer_addServiceToKeptn(t *testing.T) {
 			fields: fields{
 				logger:          keptncommon.NewLogger("", "", ""),
 				projectsAPI:     nil,
-				servicesAPI:     keptn.NewServiceClient(keptnapi.NewServiceHandler(servicesMockAPI.URL), mockCS.Client()),
-				resourcesAPI:    keptn.NewResourceClient(keptn.NewConfigResourceClient(keptnapi.NewResourceHandler(mockCS.URL))),
-				apiMutex:        sync.Mutex{},
+				servicesAPI:     keptn.NewServiceClient(keptnapi.NewStageHandler(servicesMockAPI.URL), mockCS.Client()),
+				resourcesAPI:    keptn.NewResourceClient(keptn.NewConfigResourceClient(keptnapi.NewResourceHandler(mockCS.URL)), env.JSONDashboardStorage),
 				EntitiesClient:  nil,
 				syncTimer:       nil,
 				keptnHandler:    k,
@@ -5,7 +5,9 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"time"
 )
 
 type ActionFinishedAdapterInterface interface {
@@ -13,16 +15,19 @@ type ActionFinishedAdapterInterface interface {
 
 	GetResult() keptnv2.ResultType
 	GetStatus() keptnv2.StatusType
+	GetTriggeredID() string
+	GetTime() time.Time
 }
 
 // ActionFinishedAdapter is a content adaptor for events of type sh.keptn.event.action.finished
 type ActionFinishedAdapter struct {
 	event      keptnv2.ActionFinishedEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewActionFinishedAdapterFromEvent creates a new ActionFinishedAdapter from a cloudevents Event
-func NewActionFinishedAdapterFromEvent(e cloudevents.Event) (*ActionFinishedAdapter, error) {
+func NewActionFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*ActionFinishedAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	afData := &keptnv2.ActionFinishedEventData{}
@@ -34,6 +39,7 @@ func NewActionFinishedAdapterFromEvent(e cloudevents.Event) (*ActionFinishedAdap
 	return &ActionFinishedAdapter{
 		event:      *afData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -85,7 +91,7 @@ func (a ActionFinishedAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a ActionFinishedAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
@@ -102,3 +108,13 @@ func (a ActionFinishedAdapter) GetResult() keptnv2.ResultType {
 func (a ActionFinishedAdapter) GetStatus() keptnv2.StatusType {
 	return a.event.Status
 }
+
+// GetTriggeredID returns the ID of the action.triggered event this event refers to
+func (a ActionFinishedAdapter) GetTriggeredID() string {
+	return a.cloudEvent.TriggeredID()
+}
+
+// GetTime returns the time the action finished
+func (a ActionFinishedAdapter) GetTime() time.Time {
+	return a.cloudEvent.Time()
+}
@@ -2,27 +2,41 @@ package problem
 
 import (
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
 )
 
+const actionDurationProperty = "Action Duration"
+
 type ActionFinishedEventHandler struct {
 	event       ActionFinishedAdapterInterface
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
+
+	ingestMetrics bool
 }
 
 // NewActionFinishedEventHandler creates a new ActionFinishedEventHandler
-func NewActionFinishedEventHandler(event ActionFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *ActionFinishedEventHandler {
+func NewActionFinishedEventHandler(event ActionFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *ActionFinishedEventHandler {
 	return &ActionFinishedEventHandler{
 		event:       event,
 		dtClient:    dtClient,
 		eClient:     eClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
+
+		ingestMetrics: cfg.RemediationMetricsIngestEnabled,
 	}
 }
 
@@ -43,6 +57,10 @@ func (eh *ActionFinishedEventHandler) HandleEvent() error {
 		eh.event.GetStatus())
 
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+	duration, durationErr := eh.getActionDuration()
+	if durationErr != nil {
+		log.WithError(durationErr).Warn("Could not determine duration of remediation action")
+	}
 
 	// https://github.com/keptn-contrib/dynatrace-service/issues/174
 	// Additionally to the problem comment, send Info and Configuration Change Event to the entities in Dynatrace to indicate that remediation actions have been executed
@@ -51,17 +69,107 @@ func (eh *ActionFinishedEventHandler) HandleEvent() error {
 		dtConfigEvent := dynatrace.CreateConfigurationEventDTO(eh.event, imageAndTag, eh.attachRules)
 		dtConfigEvent.Description = "Keptn Remediation Action Finished"
 		dtConfigEvent.Configuration = "successful"
+		if durationErr == nil {
+			dtConfigEvent.CustomProperties[actionDurationProperty] = duration.String()
+		}
 
-		dynatrace.NewEventsClient(eh.dtClient).AddConfigurationEvent(dtConfigEvent)
+		dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddConfigurationEvent(dtConfigEvent)
 	} else {
 		dtInfoEvent := dynatrace.CreateInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
 		dtInfoEvent.Title = "Keptn Remediation Action Finished"
 		dtInfoEvent.Description = "error during execution"
+		if durationErr == nil {
+			dtInfoEvent.CustomProperties[actionDurationProperty] = duration.String()
+		}
 
-		dynatrace.NewEventsClient(eh.dtClient).AddInfoEvent(dtInfoEvent)
+		dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(dtInfoEvent)
 	}
 
 	dynatrace.NewProblemsClient(eh.dtClient).AddProblemComment(pid, comment)
 
+	if eh.ingestMetrics {
+		err := dynatrace.NewMetricsIngestClient(eh.dtClient).IngestMetrics(createRemediationMetricLines(eh.event, eh.getAffectedEntityDimensions(pid), duration, durationErr == nil))
+		if err != nil {
+			log.WithError(err).Error("Could not ingest remediation metrics")
+		}
+	}
+
 	return nil
 }
+
+// getActionDuration returns the time between the action.triggered event and this action.finished event
+func (eh *ActionFinishedEventHandler) getActionDuration() (time.Duration, error) {
+	finishedTime := eh.event.GetTime()
+	if finishedTime.IsZero() {
+		return 0, fmt.Errorf("no time specified in %s event", eh.event.GetEvent())
+	}
+
+	triggeredTime, err := eh.eClient.FindTriggeredEventTime(eh.event, keptnv2.ActionTaskName, eh.event.GetTriggeredID())
+	if err != nil {
+		return 0, err
+	}
+
+	duration := finishedTime.Sub(triggeredTime)
+	if duration < 0 {
+		return 0, fmt.Errorf("%s event is older than the %s event", eh.event.GetEvent(), keptnv2.GetTriggeredEventType(keptnv2.ActionTaskName))
+	}
+	return duration, nil
+}
+
+// getAffectedEntityDimensions returns the dimension identifying the root cause or first affected entity of the problem, e.g. dt.entity.service="SERVICE-123",
+// so that the remediation metrics can be charted per entity
+func (eh *ActionFinishedEventHandler) getAffectedEntityDimensions(pid string) map[string]string {
+	dimensions := map[string]string{}
+	if pid == "" {
+		return dimensions
+	}
+
+	problem, err := dynatrace.NewProblemsV2Client(eh.dtClient).GetById(pid)
+	if err != nil {
+		log.WithError(err).Warn("Could not retrieve affected entity of problem")
+		return dimensions
+	}
+
+	entityID, entityType := problem.RootCauseEntity.EntityID.ID, problem.RootCauseEntity.EntityID.Type
+	if entityID == "" && len(problem.AffectedEntities) > 0 {
+		entityID, entityType = problem.AffectedEntities[0].EntityID.ID, problem.AffectedEntities[0].EntityID.Type
+	}
+
+	if entityID != "" && entityType != "" {
+		dimensions["dt.entity."+strings.ToLower(entityType)] = entityID
+	}
+	return dimensions
+}
+
+// createRemediationMetricLines creates the keptn.remediation.action.count metric and, if the duration is known, the keptn.remediation.action.duration metric in seconds
+func createRemediationMetricLines(event ActionFinishedAdapterInterface, entityDimensions map[string]string, duration time.Duration, durationKnown bool) []dynatrace.MetricLine {
+	dimensions := map[string]string{
+		"keptn_project": event.GetProject(),
+		"keptn_stage":   event.GetStage(),
+		"keptn_service": event.GetService(),
+		"result":        string(event.GetResult()),
+		"status":        string(event.GetStatus()),
+	}
+	for key, value := range entityDimensions {
+		dimensions[key] = value
+	}
+
+	lines := []dynatrace.MetricLine{
+		{
+			MetricKey:  "keptn.remediation.action.count",
+			Dimensions: dimensions,
+			Value:      1,
+			IsCounter:  true,
+		},
+	}
+
+	if durationKnown {
+		lines = append(lines, dynatrace.MetricLine{
+			MetricKey:  "keptn.remediation.action.duration",
+			Dimensions: dimensions,
+			Value:      duration.Seconds(),
+		})
+	}
+
+	return lines
+}
@@ -0,0 +1,141 @@
+package problem
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+const testProblemID = "-4962035303683441893_1616590561288V2"
+
+type actionFinishedEventData struct {
+	test.EventData
+	result      keptnv2.ResultType
+	status      keptnv2.StatusType
+	triggeredID string
+	time        time.Time
+}
+
+func (e *actionFinishedEventData) GetResult() keptnv2.ResultType {
+	return e.result
+}
+
+func (e *actionFinishedEventData) GetStatus() keptnv2.StatusType {
+	return e.status
+}
+
+func (e *actionFinishedEventData) GetTriggeredID() string {
+	return e.triggeredID
+}
+
+func (e *actionFinishedEventData) GetTime() time.Time {
+	return e.time
+}
+
+func newTestActionFinishedEventData(result keptnv2.ResultType, status keptnv2.StatusType, finishedTime time.Time) *actionFinishedEventData {
+	return &actionFinishedEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Event:   keptnv2.GetFinishedEventType(keptnv2.ActionTaskName),
+			Source:  "unleash-service",
+			Project: "sockshop",
+			Stage:   "production",
+			Service: "carts",
+		},
+		result:      result,
+		status:      status,
+		triggeredID: "5f1d5e7e-b4e1-4b8f-a8b1-0b1f2d9d0c11",
+		time:        finishedTime,
+	}
+}
+
+func TestActionFinishedEventHandler_IngestsRemediationMetrics(t *testing.T) {
+	cfg := env.ReadConfig()
+	cfg.RemediationMetricsIngestEnabled = true
+
+	triggeredTime := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+
+	testConfigs := []struct {
+		name          string
+		result        keptnv2.ResultType
+		status        keptnv2.StatusType
+		eClient       *eventClientMock
+		expectedLines string
+	}{
+		{
+			name:    "successful action",
+			result:  keptnv2.ResultPass,
+			status:  keptnv2.StatusSucceeded,
+			eClient: &eventClientMock{problemID: testProblemID, triggeredTime: triggeredTime},
+			expectedLines: `keptn.remediation.action.count,dt.entity.service="SERVICE-C6876D601CA5DDFD",keptn_project="sockshop",keptn_service="carts",keptn_stage="production",result="pass",status="succeeded" count,delta=1` + "\n" +
+				`keptn.remediation.action.duration,dt.entity.service="SERVICE-C6876D601CA5DDFD",keptn_project="sockshop",keptn_service="carts",keptn_stage="production",result="pass",status="succeeded" gauge,90`,
+		},
+		{
+			name:          "failed action with unknown duration",
+			result:        keptnv2.ResultFailed,
+			status:        keptnv2.StatusErrored,
+			eClient:       &eventClientMock{problemID: testProblemID, triggeredTimeErr: errors.New("no action.triggered event")},
+			expectedLines: `keptn.remediation.action.count,dt.entity.service="SERVICE-C6876D601CA5DDFD",keptn_project="sockshop",keptn_service="carts",keptn_stage="production",result="fail",status="errored" count,delta=1`,
+		},
+	}
+
+	for _, tc := range testConfigs {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := newDynatraceRequestRecorder(t)
+			recorder.responses["/api/v2/problems/"+testProblemID] = `{"problemId": "` + testProblemID + `", "rootCauseEntity": {"entityId": {"id": "SERVICE-C6876D601CA5DDFD", "type": "SERVICE"}, "name": "carts"}}`
+
+			httpClient, teardown := test.CreateHTTPClient(recorder)
+			defer teardown()
+
+			dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+			event := newTestActionFinishedEventData(tc.result, tc.status, triggeredTime.Add(90*time.Second))
+
+			assert.NoError(t, NewActionFinishedEventHandler(event, dtClient, tc.eClient, nil, nil, cfg).HandleEvent())
+			assert.Equal(t, tc.expectedLines, recorder.bodies["/api/v2/metrics/ingest"])
+		})
+	}
+}
+
+func TestActionFinishedEventHandler_AddsDurationToEvent(t *testing.T) {
+	triggeredTime := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	event := newTestActionFinishedEventData(keptnv2.ResultPass, keptnv2.StatusSucceeded, triggeredTime.Add(2*time.Minute))
+
+	assert.NoError(t, NewActionFinishedEventHandler(event, dtClient, &eventClientMock{problemID: testProblemID, triggeredTime: triggeredTime}, nil, nil, env.ReadConfig()).HandleEvent())
+
+	customProperties := recorder.jsonPayload("/api/v1/events")["customProperties"].(map[string]interface{})
+	assert.Equal(t, "2m0s", customProperties["Action Duration"])
+	assert.NotContains(t, recorder.bodies, "/api/v2/metrics/ingest")
+}
+
+func TestActionFinishedEventHandler_DoesNotRetrieveProblemWithoutProblemID(t *testing.T) {
+	cfg := env.ReadConfig()
+	cfg.RemediationMetricsIngestEnabled = true
+
+	triggeredTime := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	event := newTestActionFinishedEventData(keptnv2.ResultPass, keptnv2.StatusSucceeded, triggeredTime.Add(90*time.Second))
+
+	assert.NoError(t, NewActionFinishedEventHandler(event, dtClient, &eventClientMock{triggeredTime: triggeredTime}, nil, nil, cfg).HandleEvent())
+	assert.NotContains(t, recorder.bodies, "/api/v2/problems/")
+	assert.Equal(t, `keptn.remediation.action.count,keptn_project="sockshop",keptn_service="carts",keptn_stage="production",result="pass",status="succeeded" count,delta=1`+"\n"+
+		`keptn.remediation.action.duration,keptn_project="sockshop",keptn_service="carts",keptn_stage="production",result="pass",status="succeeded" gauge,90`, recorder.bodies["/api/v2/metrics/ingest"])
+}
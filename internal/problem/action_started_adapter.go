@@ -5,6 +5,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -16,10 +17,11 @@ type ActionStartedAdapterInterface interface {
 type ActionStartedAdapter struct {
 	event      keptnv2.ActionStartedEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewActionStartedAdapterFromEvent creates a new ActionStartedAdapter from a cloudevents Event
-func NewActionStartedAdapterFromEvent(e cloudevents.Event) (*ActionStartedAdapter, error) {
+func NewActionStartedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*ActionStartedAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	asData := &keptnv2.ActionStartedEventData{}
@@ -31,6 +33,7 @@ func NewActionStartedAdapterFromEvent(e cloudevents.Event) (*ActionStartedAdapte
 	return &ActionStartedAdapter{
 		event:      *asData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -82,7 +85,7 @@ func (a ActionStartedAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a ActionStartedAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
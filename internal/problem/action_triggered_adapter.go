@@ -5,6 +5,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -13,16 +14,18 @@ type ActionTriggeredAdapterInterface interface {
 
 	GetAction() string
 	GetActionDescription() string
+	GetActionValue() interface{}
 }
 
 // ActionTriggeredAdapter encapsulates a cloud event and its parsed payload
 type ActionTriggeredAdapter struct {
 	event      keptnv2.ActionTriggeredEventData
 	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
 }
 
 // NewActionTriggeredAdapterFromEvent creates a new ActionTriggeredAdapter from a cloudevents Event
-func NewActionTriggeredAdapterFromEvent(e cloudevents.Event) (*ActionTriggeredAdapter, error) {
+func NewActionTriggeredAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*ActionTriggeredAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	atData := &keptnv2.ActionTriggeredEventData{}
@@ -34,6 +37,7 @@ func NewActionTriggeredAdapterFromEvent(e cloudevents.Event) (*ActionTriggeredAd
 	return &ActionTriggeredAdapter{
 		event:      *atData,
 		cloudEvent: ceAdapter,
+		cfg:        cfg,
 	}, nil
 }
 
@@ -85,7 +89,7 @@ func (a ActionTriggeredAdapter) GetDeploymentStrategy() string {
 // GetLabels returns a map of labels
 func (a ActionTriggeredAdapter) GetLabels() map[string]string {
 	labels := a.event.Labels
-	keptnBridgeURL, err := credentials.GetKeptnBridgeURL()
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
 	if labels == nil {
 		labels = make(map[string]string)
 	}
@@ -102,3 +106,8 @@ func (a ActionTriggeredAdapter) GetAction() string {
 func (a ActionTriggeredAdapter) GetActionDescription() string {
 	return a.event.Action.Description
 }
+
+// GetActionValue returns the value payload of the action, e.g. the parameters of a scaling action
+func (a ActionTriggeredAdapter) GetActionValue() interface{} {
+	return a.event.Action.Value
+}
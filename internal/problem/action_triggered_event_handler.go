@@ -1,10 +1,11 @@
 package problem
 
 import (
-	"errors"
+	"encoding/json"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	log "github.com/sirupsen/logrus"
 )
@@ -14,53 +15,84 @@ type ActionTriggeredEventHandler struct {
 	dtClient    dynatrace.ClientInterface
 	eClient     keptn.EventClientInterface
 	attachRules *dynatrace.AttachRules
+	eventTypes  dynatrace.EventTypeMapping
+	cfg         *env.Config
 }
 
 // NewActionTriggeredEventHandler creates a new ActionTriggeredEventHandler
-func NewActionTriggeredEventHandler(event ActionTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules) *ActionTriggeredEventHandler {
+func NewActionTriggeredEventHandler(event ActionTriggeredAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, attachRules *dynatrace.AttachRules, eventTypes dynatrace.EventTypeMapping, cfg *env.Config) *ActionTriggeredEventHandler {
 	return &ActionTriggeredEventHandler{
 		event:       event,
 		dtClient:    dtClient,
 		eClient:     eClient,
 		attachRules: attachRules,
+		eventTypes:  eventTypes,
+		cfg:         cfg,
 	}
 }
 
+// Custom properties describing the triggered remediation action
+const actionProperty = "Action"
+const actionDescriptionProperty = "Action Description"
+const actionValueProperty = "Action Value"
+
 // HandleEvent handles an action triggered event
 func (eh *ActionTriggeredEventHandler) HandleEvent() error {
-	pid, err := eh.eClient.FindProblemID(eh.event)
-	if err != nil {
-		log.WithError(err).Error("Could not find problem ID for event")
-		return err
-	}
-
-	if pid == "" {
-		log.Error("Cannot send DT problem comment: No problem ID is included in the event.")
-		return errors.New("cannot send DT problem comment: No problem ID is included in the event")
-	}
-
-	comment := "Keptn triggered action " + eh.event.GetAction()
-	if eh.event.GetActionDescription() != "" {
-		comment = comment + ": " + eh.event.GetActionDescription()
-	}
-
 	imageAndTag := eh.eClient.GetImageAndTag(eh.event)
+	actionValue := eh.getActionValue()
 
 	// https://github.com/keptn-contrib/dynatrace-service/issues/174
 	// In addition to the problem comment, send Info and Configuration Change Event to the entities in Dynatrace to indicate that remediation actions have been executed
 	dtInfoEvent := dynatrace.CreateInfoEventDTO(eh.event, imageAndTag, eh.attachRules)
 	dtInfoEvent.Title = "Keptn Remediation Action Triggered"
-	dtInfoEvent.Description = eh.event.GetAction()
+	dtInfoEvent.Description = eh.getActionSummary()
+	dtInfoEvent.CustomProperties[actionProperty] = eh.event.GetAction()
+	if eh.event.GetActionDescription() != "" {
+		dtInfoEvent.CustomProperties[actionDescriptionProperty] = eh.event.GetActionDescription()
+	}
+	if actionValue != "" {
+		dtInfoEvent.CustomProperties[actionValueProperty] = actionValue
+	}
 
-	dynatrace.NewEventsClient(eh.dtClient).AddInfoEvent(dtInfoEvent)
+	dynatrace.NewEventsClientWithEventTypes(eh.dtClient, eh.eventTypes, eh.cfg).AddInfoEvent(dtInfoEvent)
+
+	// only sequences triggered by a Dynatrace problem have a problem to comment on
+	pid, err := eh.eClient.FindProblemID(eh.event)
+	if err != nil || pid == "" {
+		log.WithError(err).Info("Remediation was not triggered by a Dynatrace problem, not adding a problem comment")
+		return nil
+	}
 
 	// this is posting the Event on the problem as a comment
-	comment = fmt.Sprintf("[Keptn triggered action](%s) %s", eh.event.GetLabels()[common.KEPTNSBRIDGE_LABEL], eh.event.GetAction())
-	if eh.event.GetActionDescription() != "" {
-		comment = comment + ": " + eh.event.GetActionDescription()
+	comment := fmt.Sprintf("[Keptn triggered action](%s) %s", eh.event.GetLabels()[common.KEPTNSBRIDGE_LABEL], eh.getActionSummary())
+	if actionValue != "" {
+		comment = comment + "\nValue: " + actionValue
 	}
 
 	dynatrace.NewProblemsClient(eh.dtClient).AddProblemComment(pid, comment)
 
 	return nil
 }
+
+// getActionSummary returns the name of the action followed by its description, if there is one
+func (eh *ActionTriggeredEventHandler) getActionSummary() string {
+	if eh.event.GetActionDescription() == "" {
+		return eh.event.GetAction()
+	}
+	return eh.event.GetAction() + ": " + eh.event.GetActionDescription()
+}
+
+// getActionValue returns the value payload of the action as JSON, or an empty string if there is none
+func (eh *ActionTriggeredEventHandler) getActionValue() string {
+	value := eh.event.GetActionValue()
+	if value == nil {
+		return ""
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		log.WithError(err).Warn("Could not serialize value of action")
+		return ""
+	}
+	return string(valueJSON)
+}
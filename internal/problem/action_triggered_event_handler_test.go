@@ -0,0 +1,143 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+type actionTriggeredEventData struct {
+	test.EventData
+	action            string
+	actionDescription string
+	actionValue       interface{}
+}
+
+func (e *actionTriggeredEventData) GetAction() string {
+	return e.action
+}
+
+func (e *actionTriggeredEventData) GetActionDescription() string {
+	return e.actionDescription
+}
+
+func (e *actionTriggeredEventData) GetActionValue() interface{} {
+	return e.actionValue
+}
+
+type eventClientMock struct {
+	problemID        string
+	err              error
+	triggeredTime    time.Time
+	triggeredTimeErr error
+}
+
+func (m *eventClientMock) IsPartOfRemediation(event adapter.EventContentAdapter) (bool, error) {
+	return true, nil
+}
+
+func (m *eventClientMock) FindProblemID(keptnEvent adapter.EventContentAdapter) (string, error) {
+	return m.problemID, m.err
+}
+
+func (m *eventClientMock) GetImageAndTag(keptnEvent adapter.EventContentAdapter) common.ImageAndTag {
+	return common.NewNotAvailableImageAndTag()
+}
+
+func (m *eventClientMock) FindTriggeredEventTime(keptnEvent adapter.EventContentAdapter, taskName string, triggeredID string) (time.Time, error) {
+	return m.triggeredTime, m.triggeredTimeErr
+}
+
+// dynatraceRequestRecorder records the bodies of all requests by path and responds with the configured responses or an empty JSON object
+type dynatraceRequestRecorder struct {
+	t         *testing.T
+	bodies    map[string]string
+	responses map[string]string
+}
+
+func newDynatraceRequestRecorder(t *testing.T) *dynatraceRequestRecorder {
+	return &dynatraceRequestRecorder{t: t, bodies: map[string]string{}, responses: map[string]string{}}
+}
+
+func (r *dynatraceRequestRecorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := ioutil.ReadAll(req.Body)
+	assert.NoError(r.t, err)
+	r.bodies[req.URL.Path] = string(body)
+
+	response, ok := r.responses[req.URL.Path]
+	if !ok {
+		response = `{}`
+	}
+	w.Write([]byte(response))
+}
+
+// jsonPayload returns the JSON body of the request sent to the path
+func (r *dynatraceRequestRecorder) jsonPayload(path string) map[string]interface{} {
+	payload := map[string]interface{}{}
+	assert.NoError(r.t, json.Unmarshal([]byte(r.bodies[path]), &payload))
+	return payload
+}
+
+func newTestActionTriggeredEventData() *actionTriggeredEventData {
+	return &actionTriggeredEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Project: "sockshop",
+			Stage:   "production",
+			Service: "carts",
+			Labels:  map[string]string{common.KEPTNSBRIDGE_LABEL: "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"},
+		},
+		action:            "scaling",
+		actionDescription: "Scales the carts deployment",
+		actionValue:       map[string]interface{}{"replicas": 3},
+	}
+}
+
+func TestActionTriggeredEventHandler_ProblemTriggeredSequence(t *testing.T) {
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	handler := NewActionTriggeredEventHandler(newTestActionTriggeredEventData(), dtClient, &eventClientMock{problemID: "-4962035303683441893_1616590561288V2"}, nil, nil, env.ReadConfig())
+
+	assert.NoError(t, handler.HandleEvent())
+
+	infoEvent := recorder.jsonPayload("/api/v1/events")
+	assert.Equal(t, "Keptn Remediation Action Triggered", infoEvent["title"])
+	assert.Equal(t, "scaling: Scales the carts deployment", infoEvent["description"])
+
+	customProperties := infoEvent["customProperties"].(map[string]interface{})
+	assert.Equal(t, "scaling", customProperties["Action"])
+	assert.Equal(t, "Scales the carts deployment", customProperties["Action Description"])
+	assert.Equal(t, `{"replicas":3}`, customProperties["Action Value"])
+	assert.Equal(t, "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9", customProperties[common.KEPTNSBRIDGE_LABEL])
+
+	comment := recorder.jsonPayload("/api/v1/problem/details/-4962035303683441893_1616590561288V2/comments")
+	assert.Equal(t, "[Keptn triggered action](https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9) scaling: Scales the carts deployment\nValue: {\"replicas\":3}", comment["comment"])
+}
+
+func TestActionTriggeredEventHandler_SequenceNotTriggeredByProblem(t *testing.T) {
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	handler := NewActionTriggeredEventHandler(newTestActionTriggeredEventData(), dtClient, &eventClientMock{err: errors.New("no problem.open event")}, nil, nil, env.ReadConfig())
+
+	assert.NoError(t, handler.HandleEvent())
+	assert.Contains(t, recorder.bodies, "/api/v1/events")
+	assert.Len(t, recorder.bodies, 1)
+}
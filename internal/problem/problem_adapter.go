@@ -31,12 +31,20 @@ type ProblemAdapterInterface interface {
 	GetProblemDetailsText() string
 	GetProblemImpact() string
 	GetProblemSeverity() string
+	GetSeverityLevel() string
+	GetImpactLevel() string
+	GetRootCauseEntity() *ProblemEntity
+	GetAffectedEntities() []ProblemEntity
+	GetOwnerTeam() string
 }
 
 // ProblemAdapter is a content adaptor for events of type sh.keptn.event.action.finished
 type ProblemAdapter struct {
 	event      DTProblemEvent
 	cloudEvent adapter.CloudEventAdapter
+
+	// team is the owner team of the problem, as determined by the ProblemRouter
+	team string
 }
 
 // NewProblemAdapterFromEvent creates a new ProblemAdapter from a cloudevents Event
@@ -49,6 +57,13 @@ func NewProblemAdapterFromEvent(e cloudevents.Event) (*ProblemAdapter, error) {
 		return nil, err
 	}
 
+	log.WithFields(log.Fields{"PID": pData.PID, "version": pData.ProblemDetails.Version}).Debug("Parsed problem details")
+
+	// version 2 problem details contain the tags of the affected entities, which are used if the payload contains no tags
+	if pData.Tags == "" {
+		pData.Tags = strings.Join(pData.ProblemDetails.entityTags, ", ")
+	}
+
 	// we need to set the project, stage and service names also from tags, if available
 	setProjectStageAndServiceFromTags(pData)
 
@@ -171,6 +186,131 @@ func (a ProblemAdapter) GetProblemSeverity() string {
 	return a.event.ProblemSeverity
 }
 
+// GetSeverityLevel returns the severity level from the problem details, falling back to the problem severity
+func (a ProblemAdapter) GetSeverityLevel() string {
+	if a.event.ProblemDetails.SeverityLevel != "" {
+		return a.event.ProblemDetails.SeverityLevel
+	}
+	return a.event.ProblemSeverity
+}
+
+// GetImpactLevel returns the impact level from the problem details, falling back to the problem impact
+func (a ProblemAdapter) GetImpactLevel() string {
+	if a.event.ProblemDetails.ImpactLevel != "" {
+		return a.event.ProblemDetails.ImpactLevel
+	}
+	return a.event.ProblemImpact
+}
+
+// GetRootCauseEntity returns the root cause entity of the problem or nil if Dynatrace did not identify one
+func (a ProblemAdapter) GetRootCauseEntity() *ProblemEntity {
+	for _, rankedEvent := range a.event.ProblemDetails.RankedEvents {
+		if rankedEvent.IsRootCause && rankedEvent.EntityID != "" {
+			return &ProblemEntity{
+				ID:   rankedEvent.EntityID,
+				Name: rankedEvent.EntityName,
+				Type: a.getEntityType(rankedEvent.EntityID),
+				URL:  a.getEntityURL(rankedEvent.EntityID),
+			}
+		}
+	}
+	return nil
+}
+
+// GetAffectedEntities returns all entities impacted by the problem
+func (a ProblemAdapter) GetAffectedEntities() []ProblemEntity {
+	if len(a.event.ImpactedEntities) == 0 {
+		if len(a.event.ProblemDetails.affectedEntities) == 0 {
+			return nil
+		}
+
+		entities := make([]ProblemEntity, 0, len(a.event.ProblemDetails.affectedEntities))
+		for _, entity := range a.event.ProblemDetails.affectedEntities {
+			entity.URL = a.getEntityURL(entity.ID)
+			entities = append(entities, entity)
+		}
+		return entities
+	}
+
+	entities := make([]ProblemEntity, 0, len(a.event.ImpactedEntities))
+	for _, impactedEntity := range a.event.ImpactedEntities {
+		entities = append(entities, ProblemEntity{
+			ID:   impactedEntity.Entity,
+			Name: impactedEntity.Name,
+			Type: impactedEntity.Type,
+			URL:  a.getEntityURL(impactedEntity.Entity),
+		})
+	}
+	return entities
+}
+
+// GetOwnerTeam returns the team owning the entities of the problem according to Dynatrace ownership, or an empty string if it is unknown
+func (a ProblemAdapter) GetOwnerTeam() string {
+	return a.team
+}
+
+// getEntityIDs returns the IDs of the root cause entity, if any, and of all affected entities
+func (a ProblemAdapter) getEntityIDs() []string {
+	var entityIDs []string
+	if rootCauseEntity := a.GetRootCauseEntity(); rootCauseEntity != nil {
+		entityIDs = append(entityIDs, rootCauseEntity.ID)
+	}
+	for _, entity := range a.GetAffectedEntities() {
+		if entity.ID != "" && !containsString(entityIDs, entity.ID) {
+			entityIDs = append(entityIDs, entity.ID)
+		}
+	}
+	return entityIDs
+}
+
+// getEntityURL returns a link to the entity in the Dynatrace tenant the problem URL points to, or an empty string if there is no problem URL
+func (a ProblemAdapter) getEntityURL(entityID string) string {
+	if entityID == "" {
+		return ""
+	}
+
+	tenantURL := a.getTenantURL()
+	if tenantURL == "" {
+		return ""
+	}
+	return tenantURL + "/#entity;id=" + entityID
+}
+
+// getTenantURL returns the URL of the Dynatrace tenant the problem URL points to, or an empty string if there is no problem URL
+func (a ProblemAdapter) getTenantURL() string {
+	// the problem URL is of form https://mytenant.live.dynatrace.com/#problems/problemdetails;pid=..., the tenant UI is everything before the fragment
+	fragmentIndex := strings.Index(a.event.ProblemURL, "#")
+	if fragmentIndex < 0 {
+		return ""
+	}
+
+	return strings.TrimSuffix(a.event.ProblemURL[:fragmentIndex], "/")
+}
+
+func (a ProblemAdapter) getEntityType(entityID string) string {
+	for _, impactedEntity := range a.event.ImpactedEntities {
+		if impactedEntity.Entity == entityID {
+			return impactedEntity.Type
+		}
+	}
+	return a.event.ProblemDetails.getEntityType(entityID)
+}
+
+func (a ProblemAdapter) getTags() []string {
+	return splitTags(a.event.Tags)
+}
+
+func (a ProblemAdapter) getImpactedEntityNames() []string {
+	var names []string
+	for _, impactedEntity := range a.event.ImpactedEntities {
+		names = append(names, impactedEntity.Name)
+	}
+	if a.event.ImpactedEntity != "" {
+		names = append(names, a.event.ImpactedEntity)
+	}
+	return names
+}
+
 func (a ProblemAdapter) IsResolved() bool {
 	return a.GetState() == "RESOLVED"
 }
@@ -186,10 +326,7 @@ func marshalProblemDetails(details DTProblemDetails) []byte {
 
 func setProjectStageAndServiceFromTags(dtProblemEvent *DTProblemEvent) {
 	// we analyze the tag list as its possible that the problem was raised for a specific monitored service that has keptn tags
-	splittedTags := strings.Split(dtProblemEvent.Tags, ",")
-
-	for _, tag := range splittedTags {
-		tag = strings.TrimSpace(tag)
+	for _, tag := range splitTags(dtProblemEvent.Tags) {
 		split := strings.Split(tag, ":")
 		if len(split) > 1 {
 			if split[0] == "keptn_project" {
@@ -204,3 +341,23 @@ func setProjectStageAndServiceFromTags(dtProblemEvent *DTProblemEvent) {
 		}
 	}
 }
+
+func splitTags(tags string) []string {
+	var result []string
+	for _, tag := range strings.Split(tags, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
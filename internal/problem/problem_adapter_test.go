@@ -0,0 +1,216 @@
+package problem
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func createProblemCloudEvent(t *testing.T, fileName string) cloudevents.Event {
+	payload, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatalf("could not read test file %s: %v", fileName, err)
+	}
+
+	ce := cloudevents.NewEvent()
+	ce.SetType(keptn.ProblemEventType)
+	ce.SetSource("dynatrace")
+	if err := ce.SetData(cloudevents.ApplicationJSON, payload); err != nil {
+		t.Fatalf("could not set cloud event data: %v", err)
+	}
+
+	return ce
+}
+
+func TestProblemAdapter_SeverityAndEntities(t *testing.T) {
+	problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, "./testdata/problem_open_event.json"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "sockshop", problemAdapter.GetProject())
+	assert.Equal(t, "production", problemAdapter.GetStage())
+	assert.Equal(t, "carts", problemAdapter.GetService())
+
+	assert.Equal(t, "ERROR", problemAdapter.GetSeverityLevel())
+	assert.Equal(t, "SERVICE", problemAdapter.GetImpactLevel())
+	assert.Equal(t,
+		&ProblemEntity{ID: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE", URL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B"},
+		problemAdapter.GetRootCauseEntity())
+	assert.Equal(t,
+		[]ProblemEntity{
+			{ID: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE", URL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B"},
+			{ID: "APPLICATION-1B2C3D4E5F6A7B8C", Name: "sockshop", Type: "APPLICATION", URL: "https://mytenant.live.dynatrace.com/#entity;id=APPLICATION-1B2C3D4E5F6A7B8C"},
+		},
+		problemAdapter.GetAffectedEntities())
+}
+
+func TestProblemAdapter_ProblemDetailsVersions(t *testing.T) {
+	tests := []struct {
+		name                     string
+		fileName                 string
+		expectedVersion          string
+		expectedDisplayName      string
+		expectedSeverityLevel    string
+		expectedImpactLevel      string
+		expectedRootCauseEntity  *ProblemEntity
+		expectedAffectedEntities []ProblemEntity
+	}{
+		{
+			name:                  "ProblemDetailsJSON",
+			fileName:              "./testdata/problem_open_event.json",
+			expectedVersion:       ProblemDetailsV1,
+			expectedDisplayName:   "P-210910",
+			expectedSeverityLevel: "ERROR",
+			expectedImpactLevel:   "SERVICE",
+			expectedRootCauseEntity: &ProblemEntity{
+				ID: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE", URL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B",
+			},
+			expectedAffectedEntities: []ProblemEntity{
+				{ID: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE", URL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B"},
+				{ID: "APPLICATION-1B2C3D4E5F6A7B8C", Name: "sockshop", Type: "APPLICATION", URL: "https://mytenant.live.dynatrace.com/#entity;id=APPLICATION-1B2C3D4E5F6A7B8C"},
+			},
+		},
+		{
+			name:                  "ProblemDetailsJSONv2",
+			fileName:              "./testdata/problem_open_event_v2.json",
+			expectedVersion:       ProblemDetailsV2,
+			expectedDisplayName:   "P-210925",
+			expectedSeverityLevel: "PERFORMANCE",
+			expectedImpactLevel:   "SERVICE",
+			expectedRootCauseEntity: &ProblemEntity{
+				ID: "PROCESS_GROUP_INSTANCE-5C7A2B9E1D3F4A6B", Name: "carts-7d9f8b6c4-x2k8q", Type: "PROCESS_GROUP_INSTANCE",
+				URL: "https://mytenant.live.dynatrace.com/#entity;id=PROCESS_GROUP_INSTANCE-5C7A2B9E1D3F4A6B",
+			},
+			expectedAffectedEntities: []ProblemEntity{
+				{ID: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE", URL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, tt.fileName))
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.expectedVersion, problemAdapter.event.ProblemDetails.Version)
+			assert.Equal(t, "sockshop", problemAdapter.GetProject())
+			assert.Equal(t, "production", problemAdapter.GetStage())
+			assert.Equal(t, "carts", problemAdapter.GetService())
+			assert.Equal(t, tt.expectedSeverityLevel, problemAdapter.GetSeverityLevel())
+			assert.Equal(t, tt.expectedImpactLevel, problemAdapter.GetImpactLevel())
+			assert.Equal(t, tt.expectedRootCauseEntity, problemAdapter.GetRootCauseEntity())
+			assert.Equal(t, tt.expectedAffectedEntities, problemAdapter.GetAffectedEntities())
+
+			// the problem details sent to Keptn have the same format for both versions
+			details := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(problemAdapter.GetProblemDetails(), &details))
+			assert.Equal(t, tt.expectedDisplayName, details["displayName"])
+			assert.Equal(t, "OPEN", details["status"])
+			assert.Equal(t, true, details["hasRootCause"])
+		})
+	}
+}
+
+func TestProblemAdapter_SeverityFallsBackToProblemFields(t *testing.T) {
+	problemAdapter := ProblemAdapter{
+		event: DTProblemEvent{
+			ProblemImpact:   "INFRASTRUCTURE",
+			ProblemSeverity: "RESOURCE_CONTENTION",
+		},
+	}
+
+	assert.Equal(t, "RESOURCE_CONTENTION", problemAdapter.GetSeverityLevel())
+	assert.Equal(t, "INFRASTRUCTURE", problemAdapter.GetImpactLevel())
+	assert.Nil(t, problemAdapter.GetRootCauseEntity())
+	assert.Nil(t, problemAdapter.GetAffectedEntities())
+}
+
+func TestRemediationTriggeredEventFactory_ContainsProblemMetadata(t *testing.T) {
+	problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, "./testdata/problem_open_event.json"))
+	assert.NoError(t, err)
+
+	ce, err := NewRemediationTriggeredEventFactory(problemAdapter, nil).CreateCloudEvent()
+	assert.NoError(t, err)
+	assert.Equal(t, "sh.keptn.event.production.remediation.triggered", ce.Type())
+
+	data := RemediationTriggeredEventData{}
+	assert.NoError(t, ce.DataAs(&data))
+	assert.Equal(t, "OPEN", data.Problem.State)
+	assert.Equal(t, "ERROR", data.Problem.SeverityLevel)
+	assert.Equal(t, "SERVICE", data.Problem.ImpactLevel)
+	if assert.NotNil(t, data.Problem.RootCauseEntity) {
+		assert.Equal(t, "SERVICE-FFD81F5D2F6A1A2B", data.Problem.RootCauseEntity.ID)
+	}
+	assert.Len(t, data.Problem.AffectedEntities, 2)
+}
+
+func TestRemediationTriggeredEventFactory_CopiesAllowedProblemPropertiesAndTagsIntoLabels(t *testing.T) {
+	problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, "./testdata/problem_open_event.json"))
+	assert.NoError(t, err)
+
+	ce, err := NewRemediationTriggeredEventFactory(problemAdapter, []string{"ProblemSeverity", "keptn_service", "owner"}).CreateCloudEvent()
+	assert.NoError(t, err)
+
+	data := RemediationTriggeredEventData{}
+	assert.NoError(t, ce.DataAs(&data))
+	assert.Equal(t, map[string]string{
+		"ProblemSeverity":         "AVAILABILITY",
+		"keptn_service":           "carts",
+		common.PROBLEMURL_LABEL:   problemAdapter.GetProblemURL(),
+		common.ROOTCAUSEURL_LABEL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B",
+	}, data.Labels)
+}
+
+func TestRemediationTriggeredEventFactory_WithRemediationAvailable(t *testing.T) {
+	problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, "./testdata/problem_open_event.json"))
+	assert.NoError(t, err)
+
+	ce, err := NewRemediationTriggeredEventFactory(problemAdapter, nil).WithRemediationAvailable(false).CreateCloudEvent()
+	assert.NoError(t, err)
+
+	data := RemediationTriggeredEventData{}
+	assert.NoError(t, ce.DataAs(&data))
+	if assert.NotNil(t, data.RemediationAvailable) {
+		assert.False(t, *data.RemediationAvailable)
+	}
+}
+
+func TestProblemAdapter_EntityURLs(t *testing.T) {
+	tests := []struct {
+		name        string
+		problemURL  string
+		expectedURL string
+	}{
+		{
+			name:        "SaaS tenant",
+			problemURL:  "https://mytenant.live.dynatrace.com/#problems/problemdetails;pid=-3305418834123422563_1631104680000V2",
+			expectedURL: "https://mytenant.live.dynatrace.com/#entity;id=SERVICE-FFD81F5D2F6A1A2B",
+		},
+		{
+			name:        "Managed environment",
+			problemURL:  "https://managed.example.com/e/abc12345/#problems/problemdetails;pid=-3305418834123422563_1631104680000V2",
+			expectedURL: "https://managed.example.com/e/abc12345/#entity;id=SERVICE-FFD81F5D2F6A1A2B",
+		},
+		{
+			name:       "no problem URL",
+			problemURL: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problemAdapter := ProblemAdapter{
+				event: DTProblemEvent{
+					ProblemURL:       tt.problemURL,
+					ImpactedEntities: []DTImpactedEntity{{Entity: "SERVICE-FFD81F5D2F6A1A2B", Name: "carts", Type: "SERVICE"}},
+				},
+			}
+
+			if assert.Len(t, problemAdapter.GetAffectedEntities(), 1) {
+				assert.Equal(t, tt.expectedURL, problemAdapter.GetAffectedEntities()[0].URL)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package problem
+
+import (
+	"encoding/json"
+)
+
+// Versions of the problem details contained in Dynatrace problem notification payloads
+const (
+	// ProblemDetailsV1 is the format of the {ProblemDetailsJSON} placeholder, i.e. the problem as returned by the Problems API v1
+	ProblemDetailsV1 = "v1"
+	// ProblemDetailsV2 is the format of the {ProblemDetailsJSONv2} placeholder, i.e. the problem as returned by the Problems API v2
+	ProblemDetailsV2 = "v2"
+)
+
+// problemDetailsV2 are the problem details of the {ProblemDetailsJSONv2} placeholder
+type problemDetailsV2 struct {
+	ProblemID        string            `json:"problemId"`
+	DisplayID        string            `json:"displayId"`
+	ImpactLevel      string            `json:"impactLevel"`
+	SeverityLevel    string            `json:"severityLevel"`
+	Status           string            `json:"status"`
+	StartTime        int64             `json:"startTime"`
+	EndTime          int64             `json:"endTime"`
+	RootCauseEntity  *problemEntityV2  `json:"rootCauseEntity"`
+	AffectedEntities []problemEntityV2 `json:"affectedEntities"`
+	EntityTags       []struct {
+		StringRepresentation string `json:"stringRepresentation"`
+	} `json:"entityTags"`
+}
+
+type problemEntityV2 struct {
+	EntityID struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"entityId"`
+	Name string `json:"name"`
+}
+
+func (e problemEntityV2) toProblemEntity() ProblemEntity {
+	return ProblemEntity{ID: e.EntityID.ID, Name: e.Name, Type: e.EntityID.Type}
+}
+
+// UnmarshalJSON parses problem details of both versions. Version 2 details are converted into the version 1 format,
+// so that events sent to Keptn contain the same problem details regardless of the problem notification payload
+func (d *DTProblemDetails) UnmarshalJSON(data []byte) error {
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return err
+	}
+
+	_, hasProblemID := keys["problemId"]
+	_, hasDisplayID := keys["displayId"]
+	if !hasProblemID && !hasDisplayID {
+		type problemDetailsV1 DTProblemDetails
+		details := problemDetailsV1{}
+		if err := json.Unmarshal(data, &details); err != nil {
+			return err
+		}
+
+		*d = DTProblemDetails(details)
+		d.Version = ProblemDetailsV1
+		return nil
+	}
+
+	details := problemDetailsV2{}
+	if err := json.Unmarshal(data, &details); err != nil {
+		return err
+	}
+
+	*d = DTProblemDetails{
+		DisplayName:   details.DisplayID,
+		EndTime:       int(details.EndTime),
+		HasRootCause:  details.RootCauseEntity != nil,
+		ID:            details.ProblemID,
+		ImpactLevel:   normalizeImpactLevel(details.ImpactLevel),
+		SeverityLevel: details.SeverityLevel,
+		StartTime:     details.StartTime,
+		Status:        details.Status,
+		Version:       ProblemDetailsV2,
+	}
+
+	if details.RootCauseEntity != nil {
+		d.RankedEvents = []DTProblemRankedEvent{{EntityID: details.RootCauseEntity.EntityID.ID, EntityName: details.RootCauseEntity.Name, IsRootCause: true}}
+		d.entities = append(d.entities, details.RootCauseEntity.toProblemEntity())
+	}
+	for _, entity := range details.AffectedEntities {
+		d.affectedEntities = append(d.affectedEntities, entity.toProblemEntity())
+		d.entities = append(d.entities, entity.toProblemEntity())
+	}
+	for _, tag := range details.EntityTags {
+		d.entityTags = append(d.entityTags, tag.StringRepresentation)
+	}
+	return nil
+}
+
+// normalizeImpactLevel returns the version 1 value of a version 2 impact level, e.g. SERVICE instead of SERVICES
+func normalizeImpactLevel(impactLevel string) string {
+	if impactLevel == "SERVICES" {
+		return "SERVICE"
+	}
+	return impactLevel
+}
+
+// getEntityType returns the type of an entity contained in version 2 problem details
+func (d DTProblemDetails) getEntityType(entityID string) string {
+	for _, entity := range d.entities {
+		if entity.ID == entityID {
+			return entity.Type
+		}
+	}
+	return ""
+}
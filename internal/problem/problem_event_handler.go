@@ -4,29 +4,26 @@ import (
 	"encoding/json"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
 )
 
 type DTProblemEvent struct {
-	ImpactedEntities []struct {
-		Entity string `json:"entity"`
-		Name   string `json:"name"`
-		Type   string `json:"type"`
-	} `json:"ImpactedEntities"`
-	ImpactedEntity     string           `json:"ImpactedEntity"`
-	PID                string           `json:"PID"`
-	ProblemDetails     DTProblemDetails `json:"ProblemDetails"`
-	ProblemDetailsHTML string           `json:"ProblemDetailsHTML"`
-	ProblemDetailsText string           `json:"ProblemDetailsText"`
-	ProblemID          string           `json:"ProblemID"`
-	ProblemImpact      string           `json:"ProblemImpact"`
-	ProblemSeverity    string           `json:"ProblemSeverity"`
-	ProblemTitle       string           `json:"ProblemTitle"`
-	ProblemURL         string           `json:"ProblemURL"`
-	State              string           `json:"State"`
-	Tags               string           `json:"Tags"`
+	ImpactedEntities   []DTImpactedEntity `json:"ImpactedEntities"`
+	ImpactedEntity     string             `json:"ImpactedEntity"`
+	PID                string             `json:"PID"`
+	ProblemDetails     DTProblemDetails   `json:"ProblemDetails"`
+	ProblemDetailsHTML string             `json:"ProblemDetailsHTML"`
+	ProblemDetailsText string             `json:"ProblemDetailsText"`
+	ProblemID          string             `json:"ProblemID"`
+	ProblemImpact      string             `json:"ProblemImpact"`
+	ProblemSeverity    string             `json:"ProblemSeverity"`
+	ProblemTitle       string             `json:"ProblemTitle"`
+	ProblemURL         string             `json:"ProblemURL"`
+	State              string             `json:"State"`
+	Tags               string             `json:"Tags"`
 	EventContext       struct {
 		KeptnContext string `json:"keptnContext"`
 		Token        string `json:"token"`
@@ -36,26 +33,53 @@ type DTProblemEvent struct {
 	KeptnStage   string `json:"KeptnStage"`
 }
 
+type DTImpactedEntity struct {
+	Entity string `json:"entity"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
 type DTProblemDetails struct {
-	DisplayName   string `json:"displayName"`
-	EndTime       int    `json:"endTime"`
-	HasRootCause  bool   `json:"hasRootCause"`
-	ID            string `json:"id"`
-	ImpactLevel   string `json:"impactLevel"`
-	SeverityLevel string `json:"severityLevel"`
-	StartTime     int64  `json:"startTime"`
-	Status        string `json:"status"`
+	DisplayName   string                 `json:"displayName"`
+	EndTime       int                    `json:"endTime"`
+	HasRootCause  bool                   `json:"hasRootCause"`
+	ID            string                 `json:"id"`
+	ImpactLevel   string                 `json:"impactLevel"`
+	SeverityLevel string                 `json:"severityLevel"`
+	StartTime     int64                  `json:"startTime"`
+	Status        string                 `json:"status"`
+	RankedEvents  []DTProblemRankedEvent `json:"rankedEvents,omitempty"`
+
+	// Version is the version of the problem details in the problem notification payload, i.e. ProblemDetailsV1 or ProblemDetailsV2
+	Version string `json:"-"`
+
+	// entities, affectedEntities and entityTags are only contained in version 2 problem details
+	entities         []ProblemEntity
+	affectedEntities []ProblemEntity
+	entityTags       []string
+}
+
+type DTProblemRankedEvent struct {
+	EntityID    string `json:"entityId"`
+	EntityName  string `json:"entityName"`
+	IsRootCause bool   `json:"isRootCause"`
 }
 
 type ProblemEventHandler struct {
-	event  ProblemAdapterInterface
-	client keptn.ClientInterface
+	event                          ProblemAdapterInterface
+	client                         keptn.ClientInterface
+	remediationLookup              *RemediationLookup
+	labelAllowlist                 []string
+	skipProblemsWithoutRemediation bool
 }
 
-func NewProblemEventHandler(event ProblemAdapterInterface, client keptn.ClientInterface) ProblemEventHandler {
+func NewProblemEventHandler(event ProblemAdapterInterface, client keptn.ClientInterface, resourceClient keptn.RemediationResourceReaderInterface, cfg *env.Config) ProblemEventHandler {
 	return ProblemEventHandler{
-		event:  event,
-		client: client,
+		event:                          event,
+		client:                         client,
+		remediationLookup:              NewRemediationLookup(resourceClient),
+		labelAllowlist:                 cfg.ProblemLabels,
+		skipProblemsWithoutRemediation: cfg.SkippingProblemsWithoutRemediationEnabled,
 	}
 }
 
@@ -64,6 +88,9 @@ type RemediationTriggeredEventData struct {
 
 	// Problem contains details about the problem
 	Problem ProblemDetails `json:"problem"`
+
+	// RemediationAvailable is whether the remediation.yaml of the service contains a remediation for the problem; it is omitted if this is unknown
+	RemediationAvailable *bool `json:"remediationAvailable,omitempty"`
 }
 
 type ProblemDetails struct {
@@ -102,6 +129,33 @@ type ProblemDetails struct {
 
 	// Tags is a comma separated list of tags that are defined for all impacted entities.
 	Tags string `json:"Tags,omitempty"`
+
+	// SeverityLevel is the severity level of the problem as reported in the problem details, e.g. AVAILABILITY or ERROR
+	SeverityLevel string `json:"SeverityLevel,omitempty"`
+
+	// ImpactLevel is the impact level of the problem as reported in the problem details, e.g. APPLICATION or SERVICE
+	ImpactLevel string `json:"ImpactLevel,omitempty"`
+
+	// RootCauseEntity is the entity Dynatrace identified as root cause of the problem, if any
+	RootCauseEntity *ProblemEntity `json:"RootCauseEntity,omitempty"`
+
+	// AffectedEntities is the list of entities impacted by the problem
+	AffectedEntities []ProblemEntity `json:"AffectedEntities,omitempty"`
+}
+
+// ProblemEntity identifies a Dynatrace entity related to a problem
+type ProblemEntity struct {
+	// ID is the Dynatrace entity ID, e.g. SERVICE-123456789
+	ID string `json:"id"`
+
+	// Name is the display name of the entity
+	Name string `json:"name,omitempty"`
+
+	// Type is the entity type, e.g. SERVICE; it may be empty if unknown
+	Type string `json:"type,omitempty"`
+
+	// URL is a link to the entity in the Dynatrace tenant; it is empty if the problem has no ProblemURL
+	URL string `json:"url,omitempty"`
 }
 
 func (eh ProblemEventHandler) HandleEvent() error {
@@ -127,7 +181,7 @@ func (eh ProblemEventHandler) HandleEvent() error {
 }
 
 func (eh ProblemEventHandler) handleClosedProblemFromDT() error {
-	err := eh.sendEvent(NewRemediationTriggeredEventFactory(eh.event))
+	err := eh.sendEvent(NewProblemClosedEventFactory(eh.event, eh.labelAllowlist))
 	if err != nil {
 		return err
 	}
@@ -137,8 +191,28 @@ func (eh ProblemEventHandler) handleClosedProblemFromDT() error {
 }
 
 func (eh ProblemEventHandler) handleOpenedProblemFromDT() error {
+	factory := NewRemediationTriggeredEventFactory(eh.event, eh.labelAllowlist)
+
+	hasRemediation, err := eh.remediationLookup.HasRemediation(eh.event)
+	if err != nil {
+		// the availability is unknown, so the remediation is triggered anyway
+		log.WithError(err).Warn("Could not look up the remediation.yaml of the problem")
+	} else {
+		if !hasRemediation && eh.skipProblemsWithoutRemediation {
+			log.WithFields(
+				log.Fields{
+					"PID":     eh.event.GetPID(),
+					"project": eh.event.GetProject(),
+					"stage":   eh.event.GetStage(),
+					"service": eh.event.GetService(),
+				}).Info("Not triggering a remediation as the remediation.yaml contains none for the problem")
+			return nil
+		}
+		factory.WithRemediationAvailable(hasRemediation)
+	}
+
 	// Send a sh.keptn.event.${STAGE}.remediation.triggered event
-	err := eh.sendEvent(NewProblemClosedEventFactory(eh.event))
+	err = eh.sendEvent(factory)
 	if err != nil {
 		return err
 	}
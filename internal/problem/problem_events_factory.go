@@ -1,6 +1,8 @@
 package problem
 
 import (
+	"strings"
+
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
@@ -9,12 +11,15 @@ import (
 )
 
 type ProblemClosedEventFactory struct {
-	event ProblemAdapterInterface
+	event          ProblemAdapterInterface
+	labelAllowlist []string
 }
 
-func NewProblemClosedEventFactory(event ProblemAdapterInterface) *ProblemClosedEventFactory {
+// NewProblemClosedEventFactory creates a new ProblemClosedEventFactory, the problem properties and tags in labelAllowlist are added as labels
+func NewProblemClosedEventFactory(event ProblemAdapterInterface, labelAllowlist []string) *ProblemClosedEventFactory {
 	return &ProblemClosedEventFactory{
-		event: event,
+		event:          event,
+		labelAllowlist: labelAllowlist,
 	}
 }
 
@@ -35,23 +40,33 @@ func (f *ProblemClosedEventFactory) CreateCloudEvent() (*cloudevents.Event, erro
 
 	// https://github.com/keptn-contrib/dynatrace-service/issues/176
 	// add problem URL as label so it becomes clickable
-	problemData.Labels = make(map[string]string)
+	problemData.Labels = createProblemLabels(f.event, f.labelAllowlist)
 	problemData.Labels[common.PROBLEMURL_LABEL] = f.event.GetProblemURL()
+	addRootCauseURLLabel(problemData.Labels, f.event)
 
 	return adapter.NewCloudEventFactoryBase(f.event, keptn.ProblemEventType, problemData).CreateCloudEvent()
-
 }
 
 type RemediationTriggeredEventFactory struct {
-	event ProblemAdapterInterface
+	event                ProblemAdapterInterface
+	labelAllowlist       []string
+	remediationAvailable *bool
 }
 
-func NewRemediationTriggeredEventFactory(event ProblemAdapterInterface) *RemediationTriggeredEventFactory {
+// NewRemediationTriggeredEventFactory creates a new RemediationTriggeredEventFactory, the problem properties and tags in labelAllowlist are added as labels
+func NewRemediationTriggeredEventFactory(event ProblemAdapterInterface, labelAllowlist []string) *RemediationTriggeredEventFactory {
 	return &RemediationTriggeredEventFactory{
-		event: event,
+		event:          event,
+		labelAllowlist: labelAllowlist,
 	}
 }
 
+// WithRemediationAvailable adds whether the remediation.yaml contains a remediation for the problem to the event
+func (f *RemediationTriggeredEventFactory) WithRemediationAvailable(available bool) *RemediationTriggeredEventFactory {
+	f.remediationAvailable = &available
+	return f
+}
+
 func (f *RemediationTriggeredEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
 	remediationEventData := RemediationTriggeredEventData{
 		EventData: keptnv2.EventData{
@@ -72,15 +87,68 @@ func (f *RemediationTriggeredEventFactory) CreateCloudEvent() (*cloudevents.Even
 			ProblemURL:         f.event.GetProblemURL(),
 			ImpactedEntity:     f.event.GetImpactedEntity(),
 			Tags:               f.event.GetProblemTags(),
+			SeverityLevel:      f.event.GetSeverityLevel(),
+			ImpactLevel:        f.event.GetImpactLevel(),
+			RootCauseEntity:    f.event.GetRootCauseEntity(),
+			AffectedEntities:   f.event.GetAffectedEntities(),
 		},
+		RemediationAvailable: f.remediationAvailable,
 	}
 
 	// https://github.com/keptn-contrib/dynatrace-service/issues/176
 	// add problem URL as label so it becomes clickable
-	remediationEventData.Labels = make(map[string]string)
+	remediationEventData.Labels = createProblemLabels(f.event, f.labelAllowlist)
 	remediationEventData.Labels[common.PROBLEMURL_LABEL] = f.event.GetProblemURL()
+	addRootCauseURLLabel(remediationEventData.Labels, f.event)
 
 	eventType := keptnv2.GetTriggeredEventType(f.event.GetStage() + "." + remediationTaskName)
 
 	return adapter.NewCloudEventFactoryBase(f.event, eventType, remediationEventData).CreateCloudEvent()
 }
+
+// addRootCauseURLLabel adds the link to the root cause entity of the problem as label, so that it becomes clickable in the Keptn Bridge
+func addRootCauseURLLabel(labels map[string]string, event ProblemAdapterInterface) {
+	rootCauseEntity := event.GetRootCauseEntity()
+	if rootCauseEntity != nil && rootCauseEntity.URL != "" {
+		labels[common.ROOTCAUSEURL_LABEL] = rootCauseEntity.URL
+	}
+}
+
+// createProblemLabels returns the labels of the Keptn events sent for a problem. For each entry of the allowlist, either the problem
+// property of that name, e.g. ProblemSeverity, or the value of the problem tag with that key, e.g. owner for the tag owner:team-a, is added
+func createProblemLabels(event ProblemAdapterInterface, labelAllowlist []string) map[string]string {
+	properties := map[string]string{
+		"PID":             event.GetPID(),
+		"ProblemID":       event.GetProblemID(),
+		"ProblemTitle":    event.GetProblemTitle(),
+		"ProblemImpact":   event.GetProblemImpact(),
+		"ProblemSeverity": event.GetProblemSeverity(),
+		"ImpactedEntity":  event.GetImpactedEntity(),
+		"SeverityLevel":   event.GetSeverityLevel(),
+		"ImpactLevel":     event.GetImpactLevel(),
+		"Team":            event.GetOwnerTeam(),
+	}
+
+	tags := make(map[string]string)
+	for _, tag := range splitTags(event.GetProblemTags()) {
+		split := strings.SplitN(tag, ":", 2)
+		if len(split) == 2 {
+			tags[split[0]] = split[1]
+		}
+	}
+
+	labels := make(map[string]string)
+	for _, key := range labelAllowlist {
+		if value, ok := properties[key]; ok {
+			if value != "" {
+				labels[key] = value
+			}
+			continue
+		}
+
+		if value, ok := tags[key]; ok && value != "" {
+			labels[key] = value
+		}
+	}
+	return labels
+}
@@ -0,0 +1,95 @@
+package problem
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// defaultOwnershipTagKeys are the tag keys Dynatrace ownership uses by default to assign entities to teams
+var defaultOwnershipTagKeys = []string{"dt-owner", "owner"}
+
+// EntityTagsReaderInterface reads the tags of Dynatrace entities
+type EntityTagsReaderInterface interface {
+	// GetEntityTags returns the tags of the given entities of the tenant with the given URL in the form "key:value" or "key", by entity ID.
+	// The tenant URL is empty if it is unknown
+	GetEntityTags(tenantURL string, entityIDs []string) (map[string][]string, error)
+}
+
+// DynatraceEntityTagsReader reads the tags of entities from the Dynatrace API using the client of the Dynatrace credentials of the problem
+type DynatraceEntityTagsReader struct {
+	client dynatrace.ClientInterface
+}
+
+// NewDynatraceEntityTagsReader creates a new DynatraceEntityTagsReader
+func NewDynatraceEntityTagsReader(client dynatrace.ClientInterface) *DynatraceEntityTagsReader {
+	return &DynatraceEntityTagsReader{
+		client: client,
+	}
+}
+
+// GetEntityTags returns the tags of the given entities. It returns an error if the tenant is not the one of the client,
+// as the entities would not be found in a different tenant
+func (r *DynatraceEntityTagsReader) GetEntityTags(tenantURL string, entityIDs []string) (map[string][]string, error) {
+	clientTenantURL := r.client.Credentials().Tenant
+	if tenantURL != "" && !isSameTenant(tenantURL, clientTenantURL) {
+		return nil, fmt.Errorf("the problem is from tenant %s, but the Dynatrace credentials are for tenant %s", tenantURL, clientTenantURL)
+	}
+
+	entities, err := dynatrace.NewEntitiesClient(r.client).GetByIDs(entityIDs, []string{"+tags"})
+	if err != nil {
+		return nil, err
+	}
+
+	entityTags := make(map[string][]string, len(entities))
+	for _, entity := range entities {
+		tags := make([]string, 0, len(entity.Tags))
+		for _, tag := range entity.Tags {
+			if tag.Value == "" {
+				tags = append(tags, tag.Key)
+				continue
+			}
+			tags = append(tags, tag.Key+":"+tag.Value)
+		}
+		entityTags[entity.EntityID] = tags
+	}
+	return entityTags, nil
+}
+
+// isSameTenant returns whether both URLs point to the same tenant, i.e. have the same host and, as for Dynatrace Managed environments, the same path
+func isSameTenant(tenantURL string, otherTenantURL string) bool {
+	parsedTenantURL, err := url.Parse(tenantURL)
+	if err != nil {
+		return false
+	}
+
+	parsedOtherTenantURL, err := url.Parse(otherTenantURL)
+	if err != nil {
+		return false
+	}
+
+	return strings.EqualFold(parsedTenantURL.Host, parsedOtherTenantURL.Host) &&
+		strings.TrimSuffix(parsedTenantURL.Path, "/") == strings.TrimSuffix(parsedOtherTenantURL.Path, "/")
+}
+
+// getOwnerTeam returns the value of the first tag with one of the ownership keys, or an empty string if there is none.
+// Tags may carry a context prefix such as [Environment], as contained in version 2 problem details
+func getOwnerTeam(tags []string, ownershipTagKeys []string) string {
+	for _, key := range ownershipTagKeys {
+		for _, tag := range tags {
+			if strings.HasPrefix(tag, "[") {
+				if end := strings.Index(tag, "]"); end >= 0 {
+					tag = tag[end+1:]
+				}
+			}
+
+			split := strings.SplitN(tag, ":", 2)
+			if len(split) == 2 && split[0] == key && split[1] != "" {
+				return split[1]
+			}
+		}
+	}
+	return ""
+}
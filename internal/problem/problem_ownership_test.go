@@ -0,0 +1,104 @@
+package problem
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDynatraceEntityTagsReader_GetEntityTags(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWith("/api/v2/entities", []byte(`{"totalCount": 2, "pageSize": 50, "entities": [
+		{"entityId": "SERVICE-1", "displayName": "carts", "tags": [{"context": "CONTEXTLESS", "key": "owner", "value": "team-a"}, {"context": "CONTEXTLESS", "key": "critical"}]},
+		{"entityId": "HOST-1", "displayName": "host-1", "tags": []}
+	]}`))
+
+	httpClient, url, teardown := test.CreateHTTPSClient(handler)
+	defer teardown()
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: "test"}, httpClient)
+
+	tests := []struct {
+		name      string
+		tenantURL string
+	}{
+		{
+			name:      "tenant of problem",
+			tenantURL: url + "/",
+		},
+		{
+			name:      "unknown tenant",
+			tenantURL: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entityTags, err := NewDynatraceEntityTagsReader(dtClient).GetEntityTags(tt.tenantURL, []string{"SERVICE-1", "HOST-1"})
+
+			assert.NoError(t, err)
+			assert.Equal(t, map[string][]string{
+				"SERVICE-1": {"owner:team-a", "critical"},
+				"HOST-1":    {},
+			}, entityTags)
+		})
+	}
+}
+
+// Tests that entities of a problem from another tenant are not looked up in the tenant of the credentials, where they would not be found
+func TestDynatraceEntityTagsReader_GetEntityTagsOfOtherTenant(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWithError("/api/v2/entities", http.StatusInternalServerError, []byte(`{"error": {"code": 500, "message": "should not be requested"}}`))
+
+	httpClient, url, teardown := test.CreateHTTPSClient(handler)
+	defer teardown()
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: "test"}, httpClient)
+
+	entityTags, err := NewDynatraceEntityTagsReader(dtClient).GetEntityTags("https://other.live.dynatrace.com", []string{"SERVICE-1"})
+
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "the problem is from tenant https://other.live.dynatrace.com")
+	}
+	assert.Nil(t, entityTags)
+}
+
+func TestIsSameTenant(t *testing.T) {
+	tests := []struct {
+		name           string
+		tenantURL      string
+		otherTenantURL string
+		want           bool
+	}{
+		{
+			name:           "same tenant",
+			tenantURL:      "https://abc12345.live.dynatrace.com",
+			otherTenantURL: "https://ABC12345.live.dynatrace.com/",
+			want:           true,
+		},
+		{
+			name:           "other tenant",
+			tenantURL:      "https://abc12345.live.dynatrace.com",
+			otherTenantURL: "https://xyz98765.live.dynatrace.com",
+			want:           false,
+		},
+		{
+			name:           "same Managed environment",
+			tenantURL:      "https://managed.example.com/e/environment-1",
+			otherTenantURL: "https://managed.example.com/e/environment-1/",
+			want:           true,
+		},
+		{
+			name:           "other Managed environment",
+			tenantURL:      "https://managed.example.com/e/environment-1",
+			otherTenantURL: "https://managed.example.com/e/environment-2",
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isSameTenant(tt.tenantURL, tt.otherTenantURL))
+		})
+	}
+}
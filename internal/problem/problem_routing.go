@@ -0,0 +1,229 @@
+package problem
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultRoutingProject is the project used to look up routing rules for problems without a keptn_project tag
+const defaultRoutingProject = "dynatrace"
+
+// ProblemRoutingConfig defines how problems are mapped to a Keptn project, stage and service if the problem tags do not provide them
+type ProblemRoutingConfig struct {
+	SpecVersion string               `json:"spec_version" yaml:"spec_version"`
+	Rules       []ProblemRoutingRule `json:"rules,omitempty" yaml:"rules,omitempty"`
+
+	// Teams maps the owner team of a problem, as derived from the ownership tags of its entities, to a target
+	Teams map[string]ProblemRoutingTarget `json:"teams,omitempty" yaml:"teams,omitempty"`
+
+	// OwnershipTags are the tag keys defining the owner team, by default the keys used by Dynatrace ownership
+	OwnershipTags []string `json:"ownershipTags,omitempty" yaml:"ownershipTags,omitempty"`
+
+	Default *ProblemRoutingTarget `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+// ProblemRoutingRule matches a problem either by tag or by a regular expression on the names of the impacted entities
+type ProblemRoutingRule struct {
+	// Tag is matched against the problem tags, either as "key" or as "key:value"
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// EntityName is a regular expression matched against the names of the impacted entities
+	EntityName string `json:"entityName,omitempty" yaml:"entityName,omitempty"`
+
+	ProblemRoutingTarget `json:",inline" yaml:",inline"`
+}
+
+// ProblemRoutingTarget is the Keptn project, stage and service a problem is routed to
+type ProblemRoutingTarget struct {
+	Project string `json:"project,omitempty" yaml:"project,omitempty"`
+	Stage   string `json:"stage,omitempty" yaml:"stage,omitempty"`
+	Service string `json:"service,omitempty" yaml:"service,omitempty"`
+}
+
+// Resolve returns the target of the first matching rule, the target of the owner team if no rule matches, the default target, or nil
+func (c ProblemRoutingConfig) Resolve(tags []string, entityNames []string, team string) *ProblemRoutingTarget {
+	for _, rule := range c.Rules {
+		matches, err := rule.matches(tags, entityNames)
+		if err != nil {
+			log.WithError(err).Warn("Skipping invalid problem routing rule")
+			continue
+		}
+
+		if matches {
+			target := rule.ProblemRoutingTarget
+			return &target
+		}
+	}
+
+	if target, ok := c.Teams[team]; ok && team != "" {
+		return &target
+	}
+
+	return c.Default
+}
+
+func (c ProblemRoutingConfig) getOwnershipTagKeys() []string {
+	if len(c.OwnershipTags) > 0 {
+		return c.OwnershipTags
+	}
+	return defaultOwnershipTagKeys
+}
+
+func (r ProblemRoutingRule) matches(tags []string, entityNames []string) (bool, error) {
+	if r.Tag == "" && r.EntityName == "" {
+		return false, fmt.Errorf("rule for project '%s' has neither a tag nor an entityName", r.Project)
+	}
+
+	if r.Tag != "" && !containsTag(tags, r.Tag) {
+		return false, nil
+	}
+
+	if r.EntityName != "" {
+		regex, err := regexp.Compile(r.EntityName)
+		if err != nil {
+			return false, fmt.Errorf("invalid entityName expression '%s': %w", r.EntityName, err)
+		}
+
+		for _, entityName := range entityNames {
+			if regex.MatchString(entityName) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func containsTag(tags []string, wanted string) bool {
+	for _, tag := range tags {
+		if tag == wanted {
+			return true
+		}
+
+		// a rule without a value matches any tag with that key
+		if !strings.Contains(wanted, ":") && strings.SplitN(tag, ":", 2)[0] == wanted {
+			return true
+		}
+	}
+	return false
+}
+
+func parseProblemRoutingConfig(input string) (*ProblemRoutingConfig, error) {
+	routingConfig := &ProblemRoutingConfig{}
+	err := yaml.Unmarshal([]byte(input), routingConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return routingConfig, nil
+}
+
+// ProblemRouter fills in the project, stage and service of problems based on routing rules stored as a Keptn resource
+type ProblemRouter struct {
+	resourceClient   keptn.ProblemRoutingResourceReaderInterface
+	entityTagsReader EntityTagsReaderInterface
+}
+
+// NewProblemRouter creates a new ProblemRouter
+func NewProblemRouter(resourceClient keptn.ProblemRoutingResourceReaderInterface) *ProblemRouter {
+	return &ProblemRouter{
+		resourceClient: resourceClient,
+	}
+}
+
+// WithEntityTagsReader sets the reader used to look up the owner team of problems whose tags do not contain it
+func (r *ProblemRouter) WithEntityTagsReader(entityTagsReader EntityTagsReaderInterface) *ProblemRouter {
+	r.entityTagsReader = entityTagsReader
+	return r
+}
+
+// Route applies the routing rules to all of project, stage and service that could not be derived from the problem tags
+func (r *ProblemRouter) Route(a *ProblemAdapter) {
+	// the owner team is also set for problems that need no routing, using the default ownership tag keys as the routing rules are not read
+	a.team = getOwnerTeam(a.getTags(), defaultOwnershipTagKeys)
+
+	if a.GetProject() != "" && a.GetStage() != "" && a.GetService() != "" {
+		return
+	}
+
+	routingProject := a.GetProject()
+	if routingProject == "" {
+		routingProject = defaultRoutingProject
+	}
+
+	fileContent, err := r.resourceClient.GetProblemRouting(routingProject)
+	if err != nil {
+		log.WithError(err).WithField("project", routingProject).Debug("No problem routing rules available")
+		return
+	}
+
+	routingConfig, err := parseProblemRoutingConfig(fileContent)
+	if err != nil {
+		log.WithError(err).WithField("project", routingProject).Error("Could not parse problem routing rules")
+		return
+	}
+
+	a.team = r.getOwnerTeam(a, routingConfig)
+
+	target := routingConfig.Resolve(a.getTags(), a.getImpactedEntityNames(), a.team)
+	if target == nil {
+		log.WithField("PID", a.GetPID()).Debug("No problem routing rule matched")
+		return
+	}
+
+	if a.event.KeptnProject == "" {
+		a.event.KeptnProject = target.Project
+	}
+	if a.event.KeptnStage == "" {
+		a.event.KeptnStage = target.Stage
+	}
+	if a.event.KeptnService == "" {
+		a.event.KeptnService = target.Service
+	}
+
+	log.WithFields(
+		log.Fields{
+			"PID":     a.GetPID(),
+			"project": a.GetProject(),
+			"stage":   a.GetStage(),
+			"service": a.GetService(),
+			"team":    a.GetOwnerTeam(),
+		}).Info("Routed problem using problem routing rules")
+}
+
+// getOwnerTeam returns the owner team from the problem tags or, if team targets are configured, from the tags of the root cause and affected entities
+func (r *ProblemRouter) getOwnerTeam(a *ProblemAdapter, routingConfig *ProblemRoutingConfig) string {
+	ownershipTagKeys := routingConfig.getOwnershipTagKeys()
+	if team := getOwnerTeam(a.getTags(), ownershipTagKeys); team != "" {
+		return team
+	}
+
+	if len(routingConfig.Teams) == 0 || r.entityTagsReader == nil {
+		return ""
+	}
+
+	entityIDs := a.getEntityIDs()
+	if len(entityIDs) == 0 {
+		return ""
+	}
+
+	entityTags, err := r.entityTagsReader.GetEntityTags(a.getTenantURL(), entityIDs)
+	if err != nil {
+		log.WithError(err).WithField("PID", a.GetPID()).Warn("Could not read ownership tags of problem entities")
+		return ""
+	}
+
+	// the entities are ordered by relevance, i.e. the root cause first
+	for _, entityID := range entityIDs {
+		if team := getOwnerTeam(entityTags[entityID], ownershipTagKeys); team != "" {
+			return team
+		}
+	}
+	return ""
+}
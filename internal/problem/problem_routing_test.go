@@ -0,0 +1,240 @@
+package problem
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testProblemRoutingConfig = `spec_version: '0.1.0'
+rules:
+  - tag: "app:carts"
+    project: sockshop
+    stage: production
+    service: carts
+  - entityName: "^payment-.*"
+    project: sockshop
+    stage: production
+    service: payment
+  - tag: "team"
+    project: team-project
+    stage: dev
+    service: team-service
+teams:
+  team-a:
+    project: team-a-project
+    stage: production
+    service: team-a-service
+default:
+  project: dynatrace
+  stage: production
+  service: allproblems
+`
+
+type problemRoutingResourceClientMock struct {
+	content string
+	err     error
+	project string
+}
+
+func (m *problemRoutingResourceClientMock) GetProblemRouting(project string) (string, error) {
+	m.project = project
+	return m.content, m.err
+}
+
+type entityTagsReaderMock struct {
+	entityTags map[string][]string
+	err        error
+	tenantURL  string
+	entityIDs  []string
+}
+
+func (m *entityTagsReaderMock) GetEntityTags(tenantURL string, entityIDs []string) (map[string][]string, error) {
+	m.tenantURL = tenantURL
+	m.entityIDs = entityIDs
+	return m.entityTags, m.err
+}
+
+func TestProblemRoutingConfig_Resolve(t *testing.T) {
+	routingConfig, err := parseProblemRoutingConfig(testProblemRoutingConfig)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		tags        []string
+		entityNames []string
+		team        string
+		want        *ProblemRoutingTarget
+	}{
+		{
+			name: "match by tag with value",
+			tags: []string{"environment:prod", "app:carts"},
+			want: &ProblemRoutingTarget{Project: "sockshop", Stage: "production", Service: "carts"},
+		},
+		{
+			name:        "match by entity name",
+			tags:        []string{"app:orders"},
+			entityNames: []string{"payment-service"},
+			want:        &ProblemRoutingTarget{Project: "sockshop", Stage: "production", Service: "payment"},
+		},
+		{
+			name: "match by tag key only",
+			tags: []string{"team:a"},
+			want: &ProblemRoutingTarget{Project: "team-project", Stage: "dev", Service: "team-service"},
+		},
+		{
+			name: "match by owner team",
+			tags: []string{"app:orders"},
+			team: "team-a",
+			want: &ProblemRoutingTarget{Project: "team-a-project", Stage: "production", Service: "team-a-service"},
+		},
+		{
+			name: "rules take precedence over owner team",
+			tags: []string{"app:carts"},
+			team: "team-a",
+			want: &ProblemRoutingTarget{Project: "sockshop", Stage: "production", Service: "carts"},
+		},
+		{
+			name: "fall back to default for unknown owner team",
+			tags: []string{"app:orders"},
+			team: "team-b",
+			want: &ProblemRoutingTarget{Project: "dynatrace", Stage: "production", Service: "allproblems"},
+		},
+		{
+			name:        "fall back to default",
+			tags:        []string{"app:orders"},
+			entityNames: []string{"orders-service"},
+			want:        &ProblemRoutingTarget{Project: "dynatrace", Stage: "production", Service: "allproblems"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, routingConfig.Resolve(tt.tags, tt.entityNames, tt.team))
+		})
+	}
+}
+
+func TestProblemRouter_Route(t *testing.T) {
+	t.Run("fills in missing values only", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				Tags:         "keptn_project:sockshop, app:carts",
+				KeptnProject: "sockshop",
+			},
+		}
+
+		NewProblemRouter(client).Route(problemAdapter)
+
+		assert.Equal(t, "sockshop", client.project)
+		assert.Equal(t, "sockshop", problemAdapter.GetProject())
+		assert.Equal(t, "production", problemAdapter.GetStage())
+		assert.Equal(t, "carts", problemAdapter.GetService())
+	})
+
+	t.Run("uses default project for untagged problems", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				ImpactedEntities: []DTImpactedEntity{{Entity: "HOST-1", Name: "host-1", Type: "HOST"}},
+			},
+		}
+
+		NewProblemRouter(client).Route(problemAdapter)
+
+		assert.Equal(t, defaultRoutingProject, client.project)
+		assert.Equal(t, "dynatrace", problemAdapter.GetProject())
+		assert.Equal(t, "production", problemAdapter.GetStage())
+		assert.Equal(t, "allproblems", problemAdapter.GetService())
+	})
+
+	t.Run("routes by owner team of problem tags", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		reader := &entityTagsReaderMock{}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				Tags: "[Environment]dt-owner:team-a",
+			},
+		}
+
+		NewProblemRouter(client).WithEntityTagsReader(reader).Route(problemAdapter)
+
+		assert.Nil(t, reader.entityIDs)
+		assert.Equal(t, "team-a", problemAdapter.GetOwnerTeam())
+		assert.Equal(t, "team-a-project", problemAdapter.GetProject())
+		assert.Equal(t, "production", problemAdapter.GetStage())
+		assert.Equal(t, "team-a-service", problemAdapter.GetService())
+	})
+
+	t.Run("routes by owner team of root cause entity", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		reader := &entityTagsReaderMock{
+			entityTags: map[string][]string{
+				"SERVICE-1": {"owner:team-a"},
+				"HOST-1":    {"owner:team-b"},
+			},
+		}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				ImpactedEntities: []DTImpactedEntity{{Entity: "HOST-1", Name: "host-1", Type: "HOST"}},
+				ProblemDetails: DTProblemDetails{
+					RankedEvents: []DTProblemRankedEvent{{EntityID: "SERVICE-1", EntityName: "carts", IsRootCause: true}},
+				},
+				ProblemURL: "https://abc12345.live.dynatrace.com/#problems/problemdetails;pid=-123",
+			},
+		}
+
+		NewProblemRouter(client).WithEntityTagsReader(reader).Route(problemAdapter)
+
+		assert.Equal(t, "https://abc12345.live.dynatrace.com", reader.tenantURL)
+		assert.Equal(t, []string{"SERVICE-1", "HOST-1"}, reader.entityIDs)
+		assert.Equal(t, "team-a", problemAdapter.GetOwnerTeam())
+		assert.Equal(t, "team-a-project", problemAdapter.GetProject())
+	})
+
+	t.Run("falls back to default if entity tags cannot be read", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		reader := &entityTagsReaderMock{err: errors.New("unauthorized")}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				ImpactedEntities: []DTImpactedEntity{{Entity: "HOST-1", Name: "host-1", Type: "HOST"}},
+			},
+		}
+
+		NewProblemRouter(client).WithEntityTagsReader(reader).Route(problemAdapter)
+
+		assert.Empty(t, problemAdapter.GetOwnerTeam())
+		assert.Equal(t, "dynatrace", problemAdapter.GetProject())
+		assert.Equal(t, "allproblems", problemAdapter.GetService())
+	})
+
+	t.Run("sets owner team of problem that needs no routing", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{content: testProblemRoutingConfig}
+		problemAdapter := &ProblemAdapter{
+			event: DTProblemEvent{
+				Tags:         "dt-owner:team-b",
+				KeptnProject: "sockshop",
+				KeptnStage:   "production",
+				KeptnService: "carts",
+			},
+		}
+
+		NewProblemRouter(client).Route(problemAdapter)
+
+		assert.Empty(t, client.project, "the routing rules are not read")
+		assert.Equal(t, "team-b", problemAdapter.GetOwnerTeam())
+		assert.Equal(t, "sockshop", problemAdapter.GetProject())
+	})
+
+	t.Run("leaves problem unchanged without routing rules", func(t *testing.T) {
+		client := &problemRoutingResourceClientMock{err: errors.New("not found")}
+		problemAdapter := &ProblemAdapter{}
+
+		NewProblemRouter(client).Route(problemAdapter)
+
+		assert.Empty(t, problemAdapter.GetProject())
+		assert.Empty(t, problemAdapter.GetStage())
+		assert.Empty(t, problemAdapter.GetService())
+	})
+}
@@ -0,0 +1,129 @@
+package problem
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProblemWebhookPath is the path of the endpoint receiving Dynatrace problem notification webhooks
+const ProblemWebhookPath = "/problem-webhook"
+
+// ProblemWebhookSecretHeader is the header the shared secret of the problem webhook is sent in
+const ProblemWebhookSecretHeader = "X-Webhook-Secret"
+
+// problemWebhookSource is the source of the problem events created from webhook payloads, as expected by the ProblemEventHandler
+const problemWebhookSource = "dynatrace"
+
+// maxProblemWebhookPayloadSize is the maximum size of accepted webhook payloads in bytes
+const maxProblemWebhookPayloadSize = 1 << 20
+
+// ProblemWebhookHandler receives Dynatrace problem notification webhooks and handles them like sh.keptn.events.problem events sent via the Keptn API
+type ProblemWebhookHandler struct {
+	secret      string
+	handleEvent func(ctx context.Context, event cloudevents.Event) error
+}
+
+// NewProblemWebhookHandler creates a new ProblemWebhookHandler accepting requests with the shared secret, which passes the problem events to handleEvent
+func NewProblemWebhookHandler(secret string, handleEvent func(ctx context.Context, event cloudevents.Event) error) *ProblemWebhookHandler {
+	return &ProblemWebhookHandler{
+		secret:      secret,
+		handleEvent: handleEvent,
+	}
+}
+
+// ServeHTTP validates the shared secret and converts the payload into a problem event.
+// The payload is either the problem notification payload or a cloud event containing it, as sent by the problem notification set up for Keptn
+func (h *ProblemWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	if h.secret == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get(ProblemWebhookSecretHeader)), []byte(h.secret)) != 1 {
+		log.Warn("Rejected problem webhook request with invalid secret")
+		http.Error(w, "invalid secret", http.StatusUnauthorized)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxProblemWebhookPayloadSize))
+	if err != nil {
+		http.Error(w, "could not read payload", http.StatusBadRequest)
+		return
+	}
+
+	event, err := newProblemEventFromWebhookPayload(body)
+	if err != nil {
+		log.WithError(err).Warn("Rejected invalid problem webhook payload")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.handleEvent(r.Context(), *event); err != nil {
+		log.WithError(err).Error("Could not handle problem webhook payload")
+		http.Error(w, "could not handle problem", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// newProblemEventFromWebhookPayload creates a sh.keptn.events.problem event from the webhook payload.
+// As the Keptn API is not involved, the Keptn context is derived from the PID, so that all notifications of a problem share it
+func newProblemEventFromWebhookPayload(payload []byte) (*cloudevents.Event, error) {
+	data := json.RawMessage(payload)
+
+	envelope := struct {
+		SpecVersion string          `json:"specversion"`
+		Data        json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, &webhookPayloadError{message: "payload is not a JSON object", cause: err}
+	}
+	if envelope.SpecVersion != "" {
+		data = envelope.Data
+	}
+
+	problem := DTProblemEvent{}
+	if err := json.Unmarshal(data, &problem); err != nil {
+		return nil, &webhookPayloadError{message: "payload is not a problem notification", cause: err}
+	}
+	if problem.PID == "" {
+		return nil, &webhookPayloadError{message: "payload does not contain a PID"}
+	}
+
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType(keptn.ProblemEventType)
+	event.SetSource(problemWebhookSource)
+	event.SetExtension("shkeptncontext", uuid.NewSHA1(uuid.NameSpaceOID, []byte(problem.PID)).String())
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return nil, &webhookPayloadError{message: "could not create problem event", cause: err}
+	}
+
+	return &event, nil
+}
+
+type webhookPayloadError struct {
+	message string
+	cause   error
+}
+
+func (e *webhookPayloadError) Error() string {
+	if e.cause == nil {
+		return e.message
+	}
+	return e.message + ": " + e.cause.Error()
+}
+
+func (e *webhookPayloadError) Unwrap() error {
+	return e.cause
+}
@@ -0,0 +1,140 @@
+package problem
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	keptn "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblemWebhookHandler(t *testing.T) {
+	problemPayload, err := ioutil.ReadFile("./testdata/problem_open_event.json")
+	if err != nil {
+		t.Fatalf("could not read test file: %v", err)
+	}
+	cloudEventPayload := []byte(`{"specversion": "1.0", "type": "sh.keptn.events.problem", "shkeptncontext": "-3305418834123422563_1631104680000V2", "source": "dynatrace", "id": "-3305418834123422563_1631104680000V2", "data": ` + string(problemPayload) + `}`)
+
+	tests := []struct {
+		name               string
+		method             string
+		secret             string
+		payload            []byte
+		handleErr          error
+		expectedStatusCode int
+		expectEvent        bool
+	}{
+		{
+			name:               "problem notification payload",
+			method:             http.MethodPost,
+			secret:             "my-secret",
+			payload:            problemPayload,
+			expectedStatusCode: http.StatusAccepted,
+			expectEvent:        true,
+		},
+		{
+			name:               "cloud event payload of the Keptn problem notification",
+			method:             http.MethodPost,
+			secret:             "my-secret",
+			payload:            cloudEventPayload,
+			expectedStatusCode: http.StatusAccepted,
+			expectEvent:        true,
+		},
+		{
+			name:               "invalid secret",
+			method:             http.MethodPost,
+			secret:             "other-secret",
+			payload:            problemPayload,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "no secret",
+			method:             http.MethodPost,
+			payload:            problemPayload,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+		{
+			name:               "GET request",
+			method:             http.MethodGet,
+			secret:             "my-secret",
+			expectedStatusCode: http.StatusMethodNotAllowed,
+		},
+		{
+			name:               "payload without PID",
+			method:             http.MethodPost,
+			secret:             "my-secret",
+			payload:            []byte(`{"State": "OPEN", "ProblemTitle": "Failure rate increase"}`),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "payload is not JSON",
+			method:             http.MethodPost,
+			secret:             "my-secret",
+			payload:            []byte(`PID=123`),
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "problem could not be handled",
+			method:             http.MethodPost,
+			secret:             "my-secret",
+			payload:            problemPayload,
+			handleErr:          errors.New("could not send event"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectEvent:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var events []cloudevents.Event
+			handler := NewProblemWebhookHandler("my-secret", func(ctx context.Context, event cloudevents.Event) error {
+				events = append(events, event)
+				return tt.handleErr
+			})
+
+			req := httptest.NewRequest(tt.method, ProblemWebhookPath, bytes.NewReader(tt.payload))
+			if tt.secret != "" {
+				req.Header.Set(ProblemWebhookSecretHeader, tt.secret)
+			}
+			recorder := httptest.NewRecorder()
+
+			handler.ServeHTTP(recorder, req)
+
+			assert.Equal(t, tt.expectedStatusCode, recorder.Code)
+			if !tt.expectEvent {
+				assert.Empty(t, events)
+				return
+			}
+
+			if assert.Len(t, events, 1) {
+				assert.Equal(t, keptn.ProblemEventType, events[0].Type())
+				assert.Equal(t, "dynatrace", events[0].Source())
+
+				problemAdapter, err := NewProblemAdapterFromEvent(events[0])
+				assert.NoError(t, err)
+				assert.Equal(t, "-3305418834123422563_1631104680000V2", problemAdapter.GetPID())
+				assert.Equal(t, "sockshop", problemAdapter.GetProject())
+				assert.False(t, problemAdapter.IsNotFromDynatrace())
+			}
+		})
+	}
+}
+
+func TestProblemWebhookHandler_SameKeptnContextForAllNotificationsOfAProblem(t *testing.T) {
+	open, err := newProblemEventFromWebhookPayload([]byte(`{"PID": "123V2", "State": "OPEN"}`))
+	assert.NoError(t, err)
+	resolved, err := newProblemEventFromWebhookPayload([]byte(`{"PID": "123V2", "State": "RESOLVED"}`))
+	assert.NoError(t, err)
+	other, err := newProblemEventFromWebhookPayload([]byte(`{"PID": "456V2", "State": "OPEN"}`))
+	assert.NoError(t, err)
+
+	assert.NotEmpty(t, open.Extensions()["shkeptncontext"])
+	assert.Equal(t, open.Extensions()["shkeptncontext"], resolved.Extensions()["shkeptncontext"])
+	assert.NotEqual(t, open.Extensions()["shkeptncontext"], other.Extensions()["shkeptncontext"])
+	assert.NotEqual(t, open.ID(), resolved.ID())
+}
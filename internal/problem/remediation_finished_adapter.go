@@ -0,0 +1,122 @@
+package problem
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+type RemediationFinishedAdapterInterface interface {
+	adapter.EventContentAdapter
+
+	GetResult() keptnv2.ResultType
+	GetStatus() keptnv2.StatusType
+	GetMessage() string
+}
+
+// RemediationFinishedAdapter is a content adaptor for events of type sh.keptn.event.<stage>.remediation.finished
+type RemediationFinishedAdapter struct {
+	event      keptnv2.EventData
+	cloudEvent adapter.CloudEventAdapter
+	cfg        *env.Config
+}
+
+// IsRemediationFinishedEventType returns whether the event type is a sh.keptn.event.<stage>.remediation.finished sequence event type
+func IsRemediationFinishedEventType(eventType string) bool {
+	_, sequence, kind, err := keptnv2.ParseSequenceEventType(eventType)
+	if err != nil {
+		return false
+	}
+
+	return sequence == remediationTaskName && kind == "finished"
+}
+
+// NewRemediationFinishedAdapterFromEvent creates a new RemediationFinishedAdapter from a cloudevents Event
+func NewRemediationFinishedAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*RemediationFinishedAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	rfData := &keptnv2.EventData{}
+	err := ceAdapter.PayloadAs(rfData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RemediationFinishedAdapter{
+		event:      *rfData,
+		cloudEvent: ceAdapter,
+		cfg:        cfg,
+	}, nil
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a RemediationFinishedAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a RemediationFinishedAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a RemediationFinishedAdapter) GetEvent() string {
+	return keptnv2.GetFinishedEventType(a.GetStage() + "." + remediationTaskName)
+}
+
+// GetProject returns the project
+func (a RemediationFinishedAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a RemediationFinishedAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a RemediationFinishedAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a RemediationFinishedAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a RemediationFinishedAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a RemediationFinishedAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a RemediationFinishedAdapter) GetLabels() map[string]string {
+	labels := a.event.Labels
+	keptnBridgeURL, err := credentials.GetKeptnBridgeURL(a.cfg)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	if err == nil {
+		labels[common.KEPTNSBRIDGE_LABEL] = keptnBridgeURL + "/trace/" + a.GetShKeptnContext()
+	}
+	return labels
+}
+
+func (a RemediationFinishedAdapter) GetResult() keptnv2.ResultType {
+	return a.event.Result
+}
+
+func (a RemediationFinishedAdapter) GetStatus() keptnv2.StatusType {
+	return a.event.Status
+}
+
+func (a RemediationFinishedAdapter) GetMessage() string {
+	return a.event.Message
+}
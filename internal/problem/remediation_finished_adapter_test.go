@@ -0,0 +1,24 @@
+package problem
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRemediationFinishedEventType(t *testing.T) {
+	tests := []struct {
+		eventType string
+		want      bool
+	}{
+		{eventType: "sh.keptn.event.production.remediation.finished", want: true},
+		{eventType: "sh.keptn.event.production.remediation.triggered", want: false},
+		{eventType: "sh.keptn.event.production.delivery.finished", want: false},
+		{eventType: "sh.keptn.event.action.finished", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.eventType, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsRemediationFinishedEventType(tt.eventType))
+		})
+	}
+}
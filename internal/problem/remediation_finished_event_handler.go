@@ -0,0 +1,77 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+type RemediationFinishedEventHandler struct {
+	event         RemediationFinishedAdapterInterface
+	dtClient      dynatrace.ClientInterface
+	eClient       keptn.EventClientInterface
+	closeProblems bool
+}
+
+// NewRemediationFinishedEventHandler creates a new RemediationFinishedEventHandler
+func NewRemediationFinishedEventHandler(event RemediationFinishedAdapterInterface, dtClient dynatrace.ClientInterface, eClient keptn.EventClientInterface, cfg *env.Config) *RemediationFinishedEventHandler {
+	return &RemediationFinishedEventHandler{
+		event:         event,
+		dtClient:      dtClient,
+		eClient:       eClient,
+		closeProblems: cfg.ProblemClosingAfterRemediationEnabled,
+	}
+}
+
+// HandleEvent handles a remediation finished event by commenting on and optionally closing the originating problem
+func (eh *RemediationFinishedEventHandler) HandleEvent() error {
+	// only remediations triggered by a Dynatrace problem have a problem to comment on and close
+	pid, err := eh.eClient.FindProblemID(eh.event)
+	if errors.Is(err, keptn.ErrProblemIDNotFound) || (err == nil && pid == "") {
+		log.Debug("Remediation was not triggered by a Dynatrace problem, ignoring remediation.finished event")
+		return nil
+	}
+	if err != nil {
+		log.WithError(err).Error("Could not find problem ID of remediation")
+		return err
+	}
+
+	comment := "Keptn finished remediation"
+	if bridgeURL := eh.event.GetLabels()[common.KEPTNSBRIDGE_LABEL]; bridgeURL != "" {
+		comment = fmt.Sprintf("[%s](%s)", comment, bridgeURL)
+	}
+	comment += fmt.Sprintf("\nResult: %s\nStatus: %s", eh.event.GetResult(), eh.event.GetStatus())
+	if eh.event.GetMessage() != "" {
+		comment += "\nMessage: " + eh.event.GetMessage()
+	}
+
+	problemsClient := dynatrace.NewProblemsV2Client(eh.dtClient)
+	err = problemsClient.AddProblemComment(pid, comment)
+	if err != nil {
+		log.WithError(err).WithField("PID", pid).Error("Could not add remediation comment to problem")
+		return err
+	}
+
+	if !eh.closeProblems || !eh.isRemediationSuccessful() {
+		return nil
+	}
+
+	err = problemsClient.CloseProblem(pid, "Problem closed by Keptn after successful remediation")
+	if err != nil {
+		log.WithError(err).WithField("PID", pid).Error("Could not close problem")
+		return err
+	}
+
+	log.WithField("PID", pid).Info("Closed problem after successful remediation")
+	return nil
+}
+
+func (eh *RemediationFinishedEventHandler) isRemediationSuccessful() bool {
+	return eh.event.GetStatus() == keptnv2.StatusSucceeded && eh.event.GetResult() == keptnv2.ResultPass
+}
@@ -0,0 +1,121 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+type remediationFinishedEventData struct {
+	test.EventData
+	result  keptnv2.ResultType
+	status  keptnv2.StatusType
+	message string
+}
+
+func (e *remediationFinishedEventData) GetResult() keptnv2.ResultType {
+	return e.result
+}
+
+func (e *remediationFinishedEventData) GetStatus() keptnv2.StatusType {
+	return e.status
+}
+
+func (e *remediationFinishedEventData) GetMessage() string {
+	return e.message
+}
+
+func newTestRemediationFinishedEventData() *remediationFinishedEventData {
+	return &remediationFinishedEventData{
+		EventData: test.EventData{
+			Context: "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			Project: "sockshop",
+			Stage:   "production",
+			Service: "carts",
+			Labels:  map[string]string{common.KEPTNSBRIDGE_LABEL: "https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9"},
+		},
+		result: keptnv2.ResultPass,
+		status: keptnv2.StatusSucceeded,
+	}
+}
+
+func TestRemediationFinishedEventHandler_CommentsOnProblem(t *testing.T) {
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	handler := NewRemediationFinishedEventHandler(newTestRemediationFinishedEventData(), dtClient, &eventClientMock{problemID: testProblemID}, env.ReadConfig())
+
+	assert.NoError(t, handler.HandleEvent())
+
+	comment := recorder.jsonPayload("/api/v2/problems/" + testProblemID + "/comments")
+	assert.Equal(t, "[Keptn finished remediation](https://bridge.example.com/trace/7c2c890f-b3ac-4caa-8922-f44d2aa54ec9)\nResult: pass\nStatus: succeeded", comment["message"])
+}
+
+func TestRemediationFinishedEventHandler_CommentsOnProblemWithoutBridgeLink(t *testing.T) {
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	event := newTestRemediationFinishedEventData()
+	event.Labels = nil
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	handler := NewRemediationFinishedEventHandler(event, dtClient, &eventClientMock{problemID: testProblemID}, env.ReadConfig())
+
+	assert.NoError(t, handler.HandleEvent())
+
+	comment := recorder.jsonPayload("/api/v2/problems/" + testProblemID + "/comments")
+	assert.Equal(t, "Keptn finished remediation\nResult: pass\nStatus: succeeded", comment["message"])
+}
+
+func TestRemediationFinishedEventHandler_ReturnsErrorIfProblemIDCannotBeFound(t *testing.T) {
+	recorder := newDynatraceRequestRecorder(t)
+	httpClient, teardown := test.CreateHTTPClient(recorder)
+	defer teardown()
+
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+	handler := NewRemediationFinishedEventHandler(newTestRemediationFinishedEventData(), dtClient, &eventClientMock{err: errors.New("could not get events: connection refused")}, env.ReadConfig())
+
+	assert.EqualError(t, handler.HandleEvent(), "could not get events: connection refused")
+	assert.Empty(t, recorder.bodies)
+}
+
+func TestRemediationFinishedEventHandler_IgnoresRemediationsNotTriggeredByProblem(t *testing.T) {
+	tests := []struct {
+		name    string
+		eClient *eventClientMock
+	}{
+		{
+			name:    "no problem.open event",
+			eClient: &eventClientMock{err: fmt.Errorf("no problem.open event: %w", keptn.ErrProblemIDNotFound)},
+		},
+		{
+			name:    "no problem ID",
+			eClient: &eventClientMock{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := newDynatraceRequestRecorder(t)
+			httpClient, teardown := test.CreateHTTPClient(recorder)
+			defer teardown()
+
+			dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+			handler := NewRemediationFinishedEventHandler(newTestRemediationFinishedEventData(), dtClient, tt.eClient, env.ReadConfig())
+
+			assert.NoError(t, handler.HandleEvent())
+			assert.Empty(t, recorder.bodies)
+		})
+	}
+}
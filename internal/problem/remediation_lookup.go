@@ -0,0 +1,67 @@
+package problem
+
+import (
+	"errors"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"gopkg.in/yaml.v2"
+)
+
+// defaultProblemType is the problem type of remediations that apply to all problems without a remediation of their own
+const defaultProblemType = "default"
+
+// remediationConfig contains the parts of a Keptn remediation.yaml needed to decide whether a problem can be remediated
+type remediationConfig struct {
+	Spec struct {
+		Remediations []struct {
+			ProblemType   string        `yaml:"problemType"`
+			ActionsOnOpen []interface{} `yaml:"actionsOnOpen"`
+		} `yaml:"remediations"`
+	} `yaml:"spec"`
+}
+
+// hasRemediationFor returns whether the remediation.yaml defines actions for the problem title, either specifically or using the default problem type
+func (c remediationConfig) hasRemediationFor(problemTitle string) bool {
+	for _, remediation := range c.Spec.Remediations {
+		if len(remediation.ActionsOnOpen) == 0 {
+			continue
+		}
+		if remediation.ProblemType == problemTitle || remediation.ProblemType == defaultProblemType || remediation.ProblemType == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// RemediationLookup finds out whether the remediation.yaml of a Keptn service contains a remediation for a problem
+type RemediationLookup struct {
+	resourceClient keptn.RemediationResourceReaderInterface
+}
+
+// NewRemediationLookup creates a new RemediationLookup
+func NewRemediationLookup(resourceClient keptn.RemediationResourceReaderInterface) *RemediationLookup {
+	return &RemediationLookup{
+		resourceClient: resourceClient,
+	}
+}
+
+// HasRemediation returns whether the remediation.yaml of the service, stage or project of the problem contains a remediation for it.
+// A missing remediation.yaml means that there is none, other errors are returned as the availability is unknown
+func (l *RemediationLookup) HasRemediation(event ProblemAdapterInterface) (bool, error) {
+	fileContent, err := l.resourceClient.GetRemediation(event.GetProject(), event.GetStage(), event.GetService())
+	if err != nil {
+		var rnfErr *keptn.ResourceNotFoundError
+		if errors.As(err, &rnfErr) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	config := remediationConfig{}
+	err = yaml.Unmarshal([]byte(fileContent), &config)
+	if err != nil {
+		return false, err
+	}
+
+	return config.hasRemediationFor(event.GetProblemTitle()), nil
+}
@@ -0,0 +1,150 @@
+package problem
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/stretchr/testify/assert"
+)
+
+const testRemediationConfig = `apiVersion: spec.keptn.sh/0.1.4
+kind: Remediation
+metadata:
+  name: carts-remediation
+spec:
+  remediations:
+    - problemType: "Failure rate increase"
+      actionsOnOpen:
+        - action: scale
+          name: Scale Deployment
+          value: "1"
+    - problemType: "Response time degradation"
+      actionsOnOpen: []
+`
+
+type remediationResourceClientMock struct {
+	content string
+	err     error
+}
+
+func (m *remediationResourceClientMock) GetRemediation(project string, stage string, service string) (string, error) {
+	return m.content, m.err
+}
+
+type problemKeptnClientMock struct {
+	eventTypes []string
+}
+
+func (m *problemKeptnClientMock) GetCustomQueries(project string, stage string, service string) (*keptn.CustomQueries, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *problemKeptnClientMock) SendCloudEvent(factory adapter.CloudEventFactoryInterface) error {
+	ce, err := factory.CreateCloudEvent()
+	if err != nil {
+		return err
+	}
+	m.eventTypes = append(m.eventTypes, ce.Type())
+	return nil
+}
+
+func TestRemediationLookup_HasRemediation(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		err           error
+		problemTitle  string
+		want          bool
+		expectedError bool
+	}{
+		{
+			name:         "remediation for problem type",
+			content:      testRemediationConfig,
+			problemTitle: "Failure rate increase",
+			want:         true,
+		},
+		{
+			name:         "remediation without actions",
+			content:      testRemediationConfig,
+			problemTitle: "Response time degradation",
+		},
+		{
+			name:         "no remediation for problem type",
+			content:      testRemediationConfig,
+			problemTitle: "Memory saturation",
+		},
+		{
+			name:         "default remediation",
+			content:      "spec:\n  remediations:\n    - problemType: default\n      actionsOnOpen:\n        - action: restart\n",
+			problemTitle: "Memory saturation",
+			want:         true,
+		},
+		{
+			name:         "no remediation.yaml",
+			err:          &keptn.ResourceNotFoundError{},
+			problemTitle: "Failure rate increase",
+		},
+		{
+			name:          "remediation.yaml could not be retrieved",
+			err:           errors.New("configuration service unavailable"),
+			problemTitle:  "Failure rate increase",
+			expectedError: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problemAdapter := &ProblemAdapter{event: DTProblemEvent{ProblemTitle: tt.problemTitle, KeptnProject: "sockshop", KeptnStage: "production", KeptnService: "carts"}}
+
+			hasRemediation, err := NewRemediationLookup(&remediationResourceClientMock{content: tt.content, err: tt.err}).HasRemediation(problemAdapter)
+			if tt.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, hasRemediation)
+		})
+	}
+}
+
+func TestProblemEventHandler_SkipsProblemsWithoutRemediation(t *testing.T) {
+	problemAdapter, err := NewProblemAdapterFromEvent(createProblemCloudEvent(t, "./testdata/problem_open_event.json"))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name               string
+		content            string
+		skip               bool
+		expectedEventTypes []string
+	}{
+		{
+			name:               "remediation available",
+			content:            testRemediationConfig,
+			skip:               true,
+			expectedEventTypes: []string{"sh.keptn.event.production.remediation.triggered"},
+		},
+		{
+			name:    "no remediation available",
+			content: "spec:\n  remediations: []\n",
+			skip:    true,
+		},
+		{
+			name:               "no remediation available, but skipping is disabled",
+			content:            "spec:\n  remediations: []\n",
+			expectedEventTypes: []string{"sh.keptn.event.production.remediation.triggered"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keptnClient := &problemKeptnClientMock{}
+			eh := NewProblemEventHandler(problemAdapter, keptnClient, &remediationResourceClientMock{content: tt.content}, env.ReadConfig())
+			eh.skipProblemsWithoutRemediation = tt.skip
+
+			assert.NoError(t, eh.HandleEvent())
+			assert.Equal(t, tt.expectedEventTypes, keptnClient.eventTypes)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package selfmonitoring
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+)
+
+// metric keys of the operational metrics of the dynatrace-service
+const (
+	EventsHandledMetricKey   = "keptn.dynatrace_service.events_handled"
+	HandlerErrorsMetricKey   = "keptn.dynatrace_service.handler_errors"
+	APICallDurationMetricKey = "keptn.dynatrace_service.api_call.duration"
+	EventQueueDepthMetricKey = "keptn.dynatrace_service.event_queue.depth"
+)
+
+// DynatraceAPI and KeptnAPI are the values of the api dimension of the API call duration metric
+const (
+	DynatraceAPI = "dynatrace"
+	KeptnAPI     = "keptn"
+)
+
+// Measurement aggregates the values recorded for a metric key and set of dimensions since the last snapshot
+type Measurement struct {
+	MetricKey  string
+	Dimensions map[string]string
+	// IsCounter is true if the values are increments of a counter and false if they are gauge values
+	IsCounter bool
+	Count     int
+	Sum       float64
+	Min       float64
+	Max       float64
+}
+
+// Recorder aggregates measurements until they are taken by a snapshot
+type Recorder struct {
+	mutex        sync.Mutex
+	measurements map[string]*Measurement
+}
+
+// NewRecorder creates a new Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		measurements: make(map[string]*Measurement),
+	}
+}
+
+// RecordEventHandled records that an event of the given type was received
+func (r *Recorder) RecordEventHandled(eventType string) {
+	r.add(EventsHandledMetricKey, map[string]string{"event_type": eventType}, true, 1)
+}
+
+// RecordHandlerError records that handling an event of the given type failed with err
+func (r *Recorder) RecordHandlerError(eventType string, err error) {
+	r.add(HandlerErrorsMetricKey, map[string]string{"event_type": eventType, "error_type": string(common.GetErrorType(err))}, true, 1)
+}
+
+// RecordAPICall records the duration of a call of the given API, which is either DynatraceAPI or KeptnAPI
+func (r *Recorder) RecordAPICall(api string, duration time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	r.add(APICallDurationMetricKey, map[string]string{"api": api, "result": result}, false, float64(duration)/float64(time.Millisecond))
+}
+
+// RecordEventQueueDepth records the number of Dynatrace events waiting to be sent in the background
+func (r *Recorder) RecordEventQueueDepth(depth int) {
+	r.add(EventQueueDepthMetricKey, map[string]string{}, false, float64(depth))
+}
+
+// Snapshot returns the measurements recorded since the last snapshot sorted by metric key and dimensions, and resets them
+func (r *Recorder) Snapshot() []Measurement {
+	r.mutex.Lock()
+	measurements := r.measurements
+	r.measurements = make(map[string]*Measurement)
+	r.mutex.Unlock()
+
+	keys := make([]string, 0, len(measurements))
+	for key := range measurements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	snapshot := make([]Measurement, 0, len(keys))
+	for _, key := range keys {
+		snapshot = append(snapshot, *measurements[key])
+	}
+	return snapshot
+}
+
+func (r *Recorder) add(metricKey string, dimensions map[string]string, isCounter bool, value float64) {
+	key := measurementKey(metricKey, dimensions)
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	measurement, ok := r.measurements[key]
+	if !ok {
+		r.measurements[key] = &Measurement{
+			MetricKey:  metricKey,
+			Dimensions: dimensions,
+			IsCounter:  isCounter,
+			Count:      1,
+			Sum:        value,
+			Min:        value,
+			Max:        value,
+		}
+		return
+	}
+
+	measurement.Count++
+	measurement.Sum += value
+	if value < measurement.Min {
+		measurement.Min = value
+	}
+	if value > measurement.Max {
+		measurement.Max = value
+	}
+}
+
+func measurementKey(metricKey string, dimensions map[string]string) string {
+	dimensionKeys := make([]string, 0, len(dimensions))
+	for key := range dimensions {
+		dimensionKeys = append(dimensionKeys, key)
+	}
+	sort.Strings(dimensionKeys)
+
+	var sb strings.Builder
+	sb.WriteString(metricKey)
+	for _, key := range dimensionKeys {
+		sb.WriteString("," + key + "=" + dimensions[key])
+	}
+	return sb.String()
+}
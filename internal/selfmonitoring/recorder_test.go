@@ -0,0 +1,71 @@
+package selfmonitoring
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder_Snapshot(t *testing.T) {
+	r := NewRecorder()
+	r.RecordEventHandled("sh.keptn.event.get-sli.triggered")
+	r.RecordEventHandled("sh.keptn.event.get-sli.triggered")
+	r.RecordHandlerError("sh.keptn.event.get-sli.triggered", common.NewDynatraceAPIError(errors.New("401 Unauthorized")))
+	r.RecordAPICall(DynatraceAPI, 100*time.Millisecond, nil)
+	r.RecordAPICall(DynatraceAPI, 300*time.Millisecond, nil)
+	r.RecordAPICall(DynatraceAPI, 50*time.Millisecond, errors.New("timeout"))
+	r.RecordEventQueueDepth(3)
+	r.RecordEventQueueDepth(1)
+
+	assert.Equal(t,
+		[]Measurement{
+			{
+				MetricKey:  APICallDurationMetricKey,
+				Dimensions: map[string]string{"api": "dynatrace", "result": "error"},
+				Count:      1,
+				Sum:        50,
+				Min:        50,
+				Max:        50,
+			},
+			{
+				MetricKey:  APICallDurationMetricKey,
+				Dimensions: map[string]string{"api": "dynatrace", "result": "success"},
+				Count:      2,
+				Sum:        400,
+				Min:        100,
+				Max:        300,
+			},
+			{
+				MetricKey:  EventQueueDepthMetricKey,
+				Dimensions: map[string]string{},
+				Count:      2,
+				Sum:        4,
+				Min:        1,
+				Max:        3,
+			},
+			{
+				MetricKey:  EventsHandledMetricKey,
+				Dimensions: map[string]string{"event_type": "sh.keptn.event.get-sli.triggered"},
+				IsCounter:  true,
+				Count:      2,
+				Sum:        2,
+				Min:        1,
+				Max:        1,
+			},
+			{
+				MetricKey:  HandlerErrorsMetricKey,
+				Dimensions: map[string]string{"event_type": "sh.keptn.event.get-sli.triggered", "error_type": "Dynatrace API"},
+				IsCounter:  true,
+				Count:      1,
+				Sum:        1,
+				Min:        1,
+				Max:        1,
+			},
+		},
+		r.Snapshot())
+
+	assert.Empty(t, r.Snapshot())
+}
@@ -0,0 +1,68 @@
+package selfmonitoring
+
+import (
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// IngestFunc sends measurements to Dynatrace
+type IngestFunc func(measurements []Measurement) error
+
+var defaultRecorder = NewRecorder()
+var enabled int32
+
+// Start enables recording the operational metrics of the dynatrace-service and ingests them every interval in the background
+func Start(interval time.Duration, ingest IngestFunc) {
+	atomic.StoreInt32(&enabled, 1)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			measurements := defaultRecorder.Snapshot()
+			if len(measurements) == 0 {
+				continue
+			}
+
+			err := ingest(measurements)
+			if err != nil {
+				log.WithError(err).Warn("Could not ingest self-monitoring metrics")
+			}
+		}
+	}()
+}
+
+// RecordEventHandled records that an event of the given type was received, if self-monitoring is enabled
+func RecordEventHandled(eventType string) {
+	if isEnabled() {
+		defaultRecorder.RecordEventHandled(eventType)
+	}
+}
+
+// RecordHandlerError records that handling an event of the given type failed, if self-monitoring is enabled
+func RecordHandlerError(eventType string, err error) {
+	if isEnabled() {
+		defaultRecorder.RecordHandlerError(eventType, err)
+	}
+}
+
+// RecordAPICall records the duration of a call of the Dynatrace or the Keptn API, if self-monitoring is enabled
+func RecordAPICall(api string, duration time.Duration, err error) {
+	if isEnabled() {
+		defaultRecorder.RecordAPICall(api, duration, err)
+	}
+}
+
+// RecordEventQueueDepth records the number of Dynatrace events waiting to be sent in the background, if self-monitoring is enabled
+func RecordEventQueueDepth(depth int) {
+	if isEnabled() {
+		defaultRecorder.RecordEventQueueDepth(depth)
+	}
+}
+
+func isEnabled() bool {
+	return atomic.LoadInt32(&enabled) == 1
+}
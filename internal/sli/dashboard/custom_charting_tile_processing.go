@@ -31,6 +31,11 @@ func NewCustomChartingTileProcessing(client dynatrace.ClientInterface, eventData
 	}
 }
 
+// CanProcess returns whether the tile is a custom chart
+func (p *CustomChartingTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == "CUSTOM_CHARTING"
+}
+
 func (p *CustomChartingTileProcessing) Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
 	tileTitle := tile.Title()
 
@@ -76,6 +81,50 @@ func (p *CustomChartingTileProcessing) Process(tile *dynatrace.Tile, dashboardFi
 	return tileResults
 }
 
+// ProcessDefinitions generates the SLI & SLO definitions of the tile without querying any metric values
+func (p *CustomChartingTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	tileTitle := tile.Title()
+
+	sloDefinition := common.ParsePassAndWarningWithoutDefaultsFrom(tileTitle)
+	if sloDefinition.SLI == "" {
+		log.WithField("tileTitle", tileTitle).Debug("Tile not included as name doesnt include sli=SLINAME")
+		return nil
+	}
+
+	tileManagementZoneFilter := NewManagementZoneFilter(dashboardFilter, tile.TileFilter.ManagementZone)
+
+	if tile.FilterConfig == nil {
+		return nil
+	}
+
+	var tileResults []*TileResult
+	for _, series := range tile.FilterConfig.ChartConfig.Series {
+		metricQuery, err := p.generateMetricQueryFromChart(series, tileManagementZoneFilter, tile.FilterConfig.FiltersPerEntityType, p.startUnix, p.endUnix)
+		if err != nil {
+			log.WithError(err).Warn("generateMetricQueryFromChart returned an error, SLI will not be used")
+			continue
+		}
+
+		tileResult := NewMetricsQueryProcessing(p.client).ProcessDefinition(getSplitDimensionCount(series), sloDefinition, metricQuery)
+		if tileResult != nil {
+			tileResults = append(tileResults, tileResult)
+		}
+	}
+
+	return tileResults
+}
+
+// getSplitDimensionCount returns the number of dimensions of the series that are not filtered to a single value
+func getSplitDimensionCount(series dynatrace.Series) int {
+	count := 0
+	for _, dimension := range series.Dimensions {
+		if len(dimension.Values) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
 // Looks at the ChartSeries configuration of a regular chart and generates the Metrics Query
 //
 // Returns a queryComponents object
@@ -149,7 +198,15 @@ func (p *CustomChartingTileProcessing) generateMetricQueryFromChart(series dynat
 	}
 	// for percentile we need to specify the percentile itself
 	if metricAggregation == "PERCENTILE" {
-		metricAggregation = fmt.Sprintf("%s(%f)", metricAggregation, series.Percentile)
+		percentile, ok := series.Percentile.(float64)
+		if !ok {
+			return nil, fmt.Errorf("percentile aggregation of metric %s has no valid percentile: %v", series.Metric, series.Percentile)
+		}
+
+		metricAggregation, err = getPercentileAggregation(percentile)
+		if err != nil {
+			return nil, err
+		}
 	}
 	// for rate measures such as failure rate we take average if it is "OF_INTEREST_RATIO"
 	if metricAggregation == "OF_INTEREST_RATIO" {
@@ -218,5 +275,33 @@ func getEntitySelectorFromEntityFilter(filtersPerEntityType map[string]map[strin
 			}
 		}
 	}
+
+	// key user actions can also be filtered by the application they belong to
+	if entityType == dynatrace.ApplicationMethodEntityType {
+		entityTileFilter = entityTileFilter + getApplicationSelectorFromEntityFilter(filtersPerEntityType)
+	}
 	return entityTileFilter
 }
+
+// getApplicationSelectorFromEntityFilter returns the entitySelector query filter restricting key user actions to the applications of the APPLICATION filter or an empty string if there is none
+//   return example: ,fromRelationships.isApplicationMethodOf(type(APPLICATION),entityId("APPLICATION-EA7C4B59F27D43EB"))
+func getApplicationSelectorFromEntityFilter(filtersPerEntityType map[string]map[string][]string) string {
+	applicationFilter, containsApplications := filtersPerEntityType[dynatrace.ApplicationEntityType]
+	if !containsApplications {
+		return ""
+	}
+
+	applicationSelector := ""
+	if entityArray, containsSpecificEntities := applicationFilter["SPECIFIC_ENTITIES"]; containsSpecificEntities && len(entityArray) > 0 {
+		applicationSelector = applicationSelector + fmt.Sprintf(",entityId(\"%s\")", strings.Join(entityArray, "\",\""))
+	}
+	if tagArray, containsAutoTags := applicationFilter["AUTO_TAGS"]; containsAutoTags {
+		for _, tag := range tagArray {
+			applicationSelector = applicationSelector + fmt.Sprintf(",tag(\"%s\")", tag)
+		}
+	}
+	if applicationSelector == "" {
+		return ""
+	}
+	return fmt.Sprintf(",fromRelationships.isApplicationMethodOf(type(%s)%s)", dynatrace.ApplicationEntityType, applicationSelector)
+}
@@ -18,3 +18,47 @@ func TestGetEntitySelectorFromEntityFilter(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestGetEntitySelectorFromEntityFilter_KeyUserActionsOfApplications(t *testing.T) {
+	tests := []struct {
+		name                 string
+		filtersPerEntityType map[string]map[string][]string
+		expected             string
+	}{
+		{
+			name: "specific applications",
+			filtersPerEntityType: map[string]map[string][]string{
+				"APPLICATION": {
+					"SPECIFIC_ENTITIES": {"APPLICATION-EA7C4B59F27D43EB", "APPLICATION-6AF34D3F9F1C1A30"},
+				},
+			},
+			expected: ",fromRelationships.isApplicationMethodOf(type(APPLICATION),entityId(\"APPLICATION-EA7C4B59F27D43EB\",\"APPLICATION-6AF34D3F9F1C1A30\"))",
+		},
+		{
+			name: "tagged applications and specific key user action",
+			filtersPerEntityType: map[string]map[string][]string{
+				"APPLICATION_METHOD": {
+					"SPECIFIC_ENTITIES": {"APPLICATION_METHOD-7B11AF03C396DCBC"},
+				},
+				"APPLICATION": {
+					"AUTO_TAGS": {"keptn_stage:production"},
+				},
+			},
+			expected: ",entityId(\"APPLICATION_METHOD-7B11AF03C396DCBC\"),fromRelationships.isApplicationMethodOf(type(APPLICATION),tag(\"keptn_stage:production\"))",
+		},
+		{
+			name: "no application filter",
+			filtersPerEntityType: map[string]map[string][]string{
+				"SERVICE": {
+					"SPECIFIC_ENTITIES": {"SERVICE-086C46F600BA1DC6"},
+				},
+			},
+			expected: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, getEntitySelectorFromEntityFilter(tt.filtersPerEntityType, "APPLICATION_METHOD"))
+		})
+	}
+}
@@ -32,19 +32,40 @@ type Processing struct {
 	customFilters []*keptnv2.SLIFilter
 	startUnix     time.Time
 	endUnix       time.Time
+
+	// tileProcessors are asked in order whether they can process a tile, the first one that can is used
+	tileProcessors []TileProcessor
 }
 
-// NewProcessing will create a new Processing
-func NewProcessing(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time) *Processing {
+// NewProcessing will create a new Processing using the default tile processors, see NewDefaultTileProcessors
+func NewProcessing(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time, tileThresholds bool) *Processing {
 	return &Processing{
-		client:        client,
-		eventData:     eventData,
-		customFilters: customFilters,
-		startUnix:     startUnix,
-		endUnix:       endUnix,
+		client:         client,
+		eventData:      eventData,
+		customFilters:  customFilters,
+		startUnix:      startUnix,
+		endUnix:        endUnix,
+		tileProcessors: NewDefaultTileProcessors(client, eventData, customFilters, startUnix, endUnix, tileThresholds),
 	}
 }
 
+// WithTileProcessors adds tile processors, e.g. for proprietary tile types. They take precedence over the default tile processors,
+// so they may also replace the processing of a default tile type
+func (p *Processing) WithTileProcessors(tileProcessors ...TileProcessor) *Processing {
+	p.tileProcessors = append(append([]TileProcessor{}, tileProcessors...), p.tileProcessors...)
+	return p
+}
+
+// getTileProcessor returns the first tile processor that can process the tile or nil if there is none
+func (p *Processing) getTileProcessor(tile *dynatrace.Tile) TileProcessor {
+	for _, tileProcessor := range p.tileProcessors {
+		if tileProcessor.CanProcess(tile) {
+			return tileProcessor
+		}
+	}
+	return nil
+}
+
 // Process will process a dynatrace.Dashboard
 func (p *Processing) Process(dashboard *dynatrace.Dashboard) *QueryResult {
 
@@ -68,38 +89,71 @@ func (p *Processing) Process(dashboard *dynatrace.Dashboard) *QueryResult {
 	log.Debug("Dashboard has changed: reparsing it!")
 
 	// now lets iterate through the dashboard to find our SLIs
-	for _, tile := range dashboard.Tiles {
-		switch tile.TileType {
-		case "MARKDOWN":
+	excludedTiles := getExcludedTiles(dashboard.Tiles)
+	for i, tile := range dashboard.Tiles {
+		if excludedTiles[i] {
+			log.WithField("tileName", tile.Title()).Debug("Tile excluded from SLI processing")
+			continue
+		}
+
+		// markdowns define the global SLO properties rather than SLIs
+		if tile.TileType == "MARKDOWN" {
+			score, comparison := NewMarkdownTileProcessing().Process(&tile, createDefaultSLOScore(), createDefaultSLOComparison())
+			if score != nil && comparison != nil {
+				result.slo.TotalScore = score
+				result.slo.Comparison = comparison
+			}
+			continue
+		}
+
+		// tiles no processor can process, e.g. headers (HEADER) or synthetic tests (SYNTHETIC_TESTS), are skipped
+		tileProcessor := p.getTileProcessor(&tile)
+		if tileProcessor == nil {
+			continue
+		}
+
+		result.addTileResults(tileProcessor.Process(&tile, dashboard.GetFilter()))
+	}
+
+	return result
+}
+
+// ProcessDefinitions will process a dynatrace.Dashboard into SLI & SLO definitions without querying any values.
+// Tiles whose indicators depend on the dimension values returned by a query are skipped.
+func (p *Processing) ProcessDefinitions(dashboard *dynatrace.Dashboard) *QueryResult {
+	result := &QueryResult{
+		dashboard: dashboard,
+		sli: &dynatrace.SLI{
+			SpecVersion: "0.1.4",
+			Indicators:  make(map[string]string),
+		},
+		slo: &keptncommon.ServiceLevelObjectives{
+			Objectives: []*keptncommon.SLO{},
+		},
+		sliResults: []*keptnv2.SLIResult{},
+	}
+
+	excludedTiles := getExcludedTiles(dashboard.Tiles)
+	for i, tile := range dashboard.Tiles {
+		if excludedTiles[i] {
+			continue
+		}
+
+		if tile.TileType == "MARKDOWN" {
 			score, comparison := NewMarkdownTileProcessing().Process(&tile, createDefaultSLOScore(), createDefaultSLOComparison())
 			if score != nil && comparison != nil {
 				result.slo.TotalScore = score
 				result.slo.Comparison = comparison
 			}
-		case "SLO":
-			tileResults := NewSLOTileProcessing(p.client, p.startUnix, p.endUnix).Process(&tile)
-			result.addTileResults(tileResults)
-		case "OPEN_PROBLEMS":
-			tileResult := NewProblemTileProcessing(p.client, p.startUnix, p.endUnix).Process(&tile, dashboard.GetFilter())
-			result.addTileResult(tileResult)
-
-			// current logic also does security tile processing for open problem tiles
-			tileResult = NewSecurityProblemTileProcessing(p.client, p.startUnix, p.endUnix).Process(&tile, dashboard.GetFilter())
-			result.addTileResult(tileResult)
-		case "DATA_EXPLORER":
-			// here we handle the new Metric Data Explorer Tile
-			tileResults := NewDataExplorerTileProcessing(p.client, p.eventData, p.customFilters, p.startUnix, p.endUnix).Process(&tile, dashboard.GetFilter())
-			result.addTileResults(tileResults)
-		case "CUSTOM_CHARTING":
-			tileResults := NewCustomChartingTileProcessing(p.client, p.eventData, p.customFilters, p.startUnix, p.endUnix).Process(&tile, dashboard.GetFilter())
-			result.addTileResults(tileResults)
-		case "DTAQL":
-			tileResults := NewUSQLTileProcessing(p.client, p.eventData, p.customFilters, p.startUnix, p.endUnix).Process(&tile)
-			result.addTileResults(tileResults)
-		default:
-			// we do not do markdowns (HEADER) or synthetic tests (SYNTHETIC_TESTS)
 			continue
 		}
+
+		tileProcessor := p.getTileProcessor(&tile)
+		if tileProcessor == nil {
+			continue
+		}
+
+		result.addTileResults(tileProcessor.ProcessDefinitions(&tile, dashboard.GetFilter()))
 	}
 
 	return result
@@ -6,6 +6,7 @@ import (
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
+// TileResult is an SLI generated from a dashboard tile
 type TileResult struct {
 	sliResult *keptnv2.SLIResult
 	objective *keptnapi.SLO
@@ -13,6 +14,26 @@ type TileResult struct {
 	sliQuery  string
 }
 
+// NewTileResult creates a new TileResult for the SLI with the name and query, its objective and, unless only definitions are processed, its value
+func NewTileResult(sliName string, sliQuery string, objective *keptnapi.SLO, sliResult *keptnv2.SLIResult) *TileResult {
+	return &TileResult{
+		sliResult: sliResult,
+		objective: objective,
+		sliName:   sliName,
+		sliQuery:  sliQuery,
+	}
+}
+
+// newFailedTileResult creates a TileResult for an SLI that could not be queried, reporting the error as the message of its failed value
+func newFailedTileResult(sliName string, err error) *TileResult {
+	return NewTileResult(sliName, "", nil, &keptnv2.SLIResult{
+		Metric:  sliName,
+		Value:   0,
+		Success: false,
+		Message: err.Error(),
+	})
+}
+
 // QueryResult is the object returned by querying a Dynatrace dashboard for SLIs
 type QueryResult struct {
 	dashboardLink *DashboardLink
@@ -20,6 +41,10 @@ type QueryResult struct {
 	sli           *dynatrace.SLI
 	slo           *keptnapi.ServiceLevelObjectives
 	sliResults    []*keptnv2.SLIResult
+
+	// dashboardVersion is the version of the processed dashboard and previousDashboardVersion the one of the dashboard stored by the previous evaluation, if any
+	dashboardVersion         string
+	previousDashboardVersion string
 }
 
 // NewQueryResultFrom creates a new QueryResult object just from a DashboardLink
@@ -49,6 +74,19 @@ func (r *QueryResult) SLIResults() []*keptnv2.SLIResult {
 	return r.sliResults
 }
 
+func (r *QueryResult) DashboardVersion() string {
+	return r.dashboardVersion
+}
+
+func (r *QueryResult) PreviousDashboardVersion() string {
+	return r.previousDashboardVersion
+}
+
+// HasDashboardChanged returns whether the dashboard changed compared to the one stored by the previous evaluation
+func (r *QueryResult) HasDashboardChanged() bool {
+	return r.previousDashboardVersion != "" && r.dashboardVersion != r.previousDashboardVersion
+}
+
 // addTileResult adds a TileResult to the QueryResult, also allows nil values for convenience
 func (r *QueryResult) addTileResult(result *TileResult) {
 	if result == nil {
@@ -57,7 +95,11 @@ func (r *QueryResult) addTileResult(result *TileResult) {
 
 	r.sli.Indicators[result.sliName] = result.sliQuery
 	r.slo.Objectives = append(r.slo.Objectives, result.objective)
-	r.sliResults = append(r.sliResults, result.sliResult)
+
+	// tile results of SLI definitions only do not have a value
+	if result.sliResult != nil {
+		r.sliResults = append(r.sliResults, result.sliResult)
+	}
 }
 
 // addTileResult adds multiple TileResult to the QueryResult,
@@ -5,6 +5,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	pkgdashboard "github.com/keptn-contrib/dynatrace-service/pkg/dashboard"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"time"
 
@@ -19,18 +20,43 @@ type Querying struct {
 	customSLIFilters []*keptnv2.SLIFilter
 	dtClient         dynatrace.ClientInterface
 	dashboardReader  keptn.DashboardResourceReaderInterface
+
+	// tileProcessorFactories create the tile processors used in addition to the default ones
+	tileProcessorFactories []pkgdashboard.TileProcessorFactory
+
+	// tileThresholds enables deriving the SLO criteria of Data Explorer tiles from the thresholds of their queries
+	tileThresholds bool
 }
 
-// NewQuerying returns a new dynatrace handler that interacts with the Dynatrace REST API
+// NewQuerying returns a new dynatrace handler that interacts with the Dynatrace REST API, using the tile processors registered using pkg/dashboard.RegisterTileProcessor in addition to the default ones
 func NewQuerying(eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, dtClient dynatrace.ClientInterface, dashboardReader keptn.DashboardResourceReaderInterface) *Querying {
 	return &Querying{
-		eventData:        eventData,
-		customSLIFilters: customFilters,
-		dtClient:         dtClient,
-		dashboardReader:  dashboardReader,
+		eventData:              eventData,
+		customSLIFilters:       customFilters,
+		dtClient:               dtClient,
+		dashboardReader:        dashboardReader,
+		tileProcessorFactories: pkgdashboard.RegisteredTileProcessors(),
 	}
 }
 
+// WithTileProcessors adds factories of tile processors, e.g. for proprietary tile types, that take precedence over the default tile processors
+func (q *Querying) WithTileProcessors(tileProcessorFactories ...pkgdashboard.TileProcessorFactory) *Querying {
+	q.tileProcessorFactories = append(q.tileProcessorFactories, tileProcessorFactories...)
+	return q
+}
+
+// WithTileThresholds sets whether the SLO criteria of Data Explorer tiles without criteria in their names are derived from the thresholds of their queries
+func (q *Querying) WithTileThresholds(enabled bool) *Querying {
+	q.tileThresholds = enabled
+	return q
+}
+
+// newProcessing creates the processing of a dashboard for the timeframe using the default and the added tile processors
+func (q *Querying) newProcessing(startUnix time.Time, endUnix time.Time) *Processing {
+	tileProcessors := newRegisteredTileProcessors(q.tileProcessorFactories, q.dtClient, q.eventData, q.customSLIFilters, startUnix, endUnix)
+	return NewProcessing(q.dtClient, q.eventData, q.customSLIFilters, startUnix, endUnix, q.tileThresholds).WithTileProcessors(tileProcessors...)
+}
+
 // GetSLIValues implements - https://github.com/keptn-contrib/dynatrace-sli-service/issues/60
 // Queries Dynatrace for the existance of a dashboard tagged with keptn_project:project, keptn_stage:stage, keptn_service:service, SLI
 // if this dashboard exists it will be parsed and a custom SLI_dashboard.yaml and an SLO_dashboard.yaml will be created
@@ -63,17 +89,71 @@ func (q *Querying) GetSLIValues(dashboardID string, startUnix time.Time, endUnix
 
 	// Lets validate if we really need to process this dashboard as it might be the same (without change) from the previous runs
 	// see https://github.com/keptn-contrib/dynatrace-sli-service/issues/92 for more details
+	var result *QueryResult
 	if dashbd.IsTheSameAs(existingDashboardContent) {
 		log.Debug("Dashboard hasn't changed: skipping parsing of dashboard")
-		return NewQueryResultFrom(
-				NewLink(
-					q.dtClient.Credentials().Tenant,
-					startUnix,
-					endUnix,
-					dashbd.ID,
-					dashbd.GetFilter())),
-			nil
+		result = NewQueryResultFrom(
+			NewLink(
+				q.dtClient.Credentials().Tenant,
+				startUnix,
+				endUnix,
+				dashbd.ID,
+				dashbd.GetFilter()))
+	} else {
+		result = q.newProcessing(startUnix, endUnix).Process(dashbd)
+	}
+
+	setDashboardVersions(result, dashbd, existingDashboardContent)
+	return result, nil
+}
+
+// setDashboardVersions sets the version of the processed dashboard and of the one stored by the previous evaluation, logging a warning if they differ
+func setDashboardVersions(result *QueryResult, dashbd *dynatrace.Dashboard, existingDashboardContent string) {
+	version, err := dashbd.Version()
+	if err != nil {
+		log.WithError(err).Warn("Could not determine dashboard version")
+		return
+	}
+	result.dashboardVersion = version
+
+	if existingDashboardContent == "" {
+		return
+	}
+
+	previousVersion, err := dynatrace.GetDashboardVersion(existingDashboardContent)
+	if err != nil {
+		log.WithError(err).Warn("Could not determine version of the dashboard stored by the previous evaluation")
+		return
+	}
+	result.previousDashboardVersion = previousVersion
+
+	if result.HasDashboardChanged() {
+		log.WithFields(
+			log.Fields{
+				"dashboardID":     dashbd.ID,
+				"version":         version,
+				"previousVersion": previousVersion,
+			}).Warn("Dashboard changed since the previous evaluation")
+	}
+}
+
+// GetSLIDefinitions retrieves the dashboard and converts it into SLI & SLO definitions without querying any values, e.g. to migrate to file-based quality gates.
+// If dashboardID is empty, the dashboard matching project, stage and service is used.
+func (q *Querying) GetSLIDefinitions(dashboardID string) (*QueryResult, error) {
+	if dashboardID == "" {
+		dashboardID = common.DynatraceConfigDashboardQUERY
+	}
+
+	dashbd, dashboardID, err := NewRetrieval(q.dtClient, q.eventData).Retrieve(dashboardID)
+	if err != nil {
+		return nil, fmt.Errorf("error while processing dashboard config '%s' - %w", dashboardID, err)
+	}
+
+	if dashbd == nil {
+		return nil, common.NewUserConfigurationError(fmt.Errorf("no dashboard found for project %s, stage %s and service %s", q.eventData.GetProject(), q.eventData.GetStage(), q.eventData.GetService()))
 	}
 
-	return NewProcessing(q.dtClient, q.eventData, q.customSLIFilters, startUnix, endUnix).Process(dashbd), nil
+	// the timeframe is only used for building the metric queries, which are not run
+	now := time.Now()
+	return q.newProcessing(now, now).ProcessDefinitions(dashbd), nil
 }
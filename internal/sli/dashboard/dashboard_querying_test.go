@@ -1,6 +1,7 @@
 package dashboard
 
 import (
+	"encoding/json"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/test"
@@ -69,6 +70,43 @@ func TestQueryDynatraceDashboardForSLIs(t *testing.T) {
 	assert.Equal(t, expectedSLOs, len(result.sliResults))
 }
 
+// Converting a dashboard to SLI and SLO definitions only retrieves the metric and SLO definitions, but does not query any values
+func TestGetSLIDefinitionsFromDashboard(t *testing.T) {
+	keptnEvent := createKeptnEvent(QUALITYGATE_PROJECT, QUALITYGATE_STAGE, QUALTIYGATE_SERVICE)
+
+	handler := test.NewFileBasedURLHandler(t)
+	handler.AddExact("/api/config/v1/dashboards", "./testdata/test_get_dashboards.json")
+	handler.AddExact("/api/config/v1/dashboards/12345678-1111-4444-8888-123456789012", "./testdata/test_get_dashboards_id.json")
+	handler.AddExact("/api/v2/metrics/builtin:tech.generic.processCount", "./testdata/test_get_metrics_processcount.json")
+	handler.AddExact("/api/v2/metrics/builtin:service.response.time", "./testdata/test_get_metrics_svcresponsetime.json")
+	handler.AddExact("/api/v2/metrics/builtin:tech.generic.mem.workingSetSize", "./testdata/test_get_metrics_workingsetsize.json")
+	handler.AddExact("/api/v2/metrics/builtin:tech.generic.cpu.usage", "./testdata/test_get_metrics_cpuusage.json")
+	handler.AddExact("/api/v2/metrics/builtin:service.errors.server.rate", "./testdata/test_get_metrics_errorrate.json")
+	handler.AddExact("/api/v2/metrics/builtin:service.requestCount.total", "./testdata/test_get_metrics_requestcount.json")
+	handler.AddExact("/api/v2/metrics/builtin:host.cpu.usage", "./testdata/test_get_metrics_hostcpuusage.json")
+	handler.AddExact("/api/v2/metrics/builtin:host.mem.usage", "./testdata/test_get_metrics_hostmemusage.json")
+	handler.AddExact("/api/v2/metrics/builtin:host.disk.queueLength", "./testdata/test_get_metrics_hostdiskqueue.json")
+	handler.AddExact("/api/v2/metrics/builtin:service.nonDbChildCallCount", "./testdata/test_get_metrics_nondbcallcount.json")
+	handler.AddExact("/api/v2/metrics/jmeter.usermetrics.transaction.meantime", "./testdata/test_get_metrics_jmeter_usermetrics_transaction_meantime.json")
+	handler.AddStartsWith("/api/v2/slo", "./testdata/test_get_slo_id.json")
+
+	querying, _, teardown := createQueryingWithHandler(keptnEvent, handler)
+	defer teardown()
+
+	result, err := querying.GetSLIDefinitions("")
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, result) {
+		assert.Equal(t, len(result.sli.Indicators), len(result.slo.Objectives))
+		assert.Len(t, result.sli.Indicators, 15)
+		assert.Empty(t, result.sliResults)
+		assert.Equal(t, "PV2;problemSelector=status(open),managementZoneIds(7030365576649815430)", result.sli.Indicators["problems"])
+		assert.Equal(t, "metricSelector=builtin:host.cpu.usage:merge(\"dt.entity.host\"):avg:names&entitySelector=type(HOST)", result.sli.Indicators["host_cpu"])
+		assert.Equal(t, "MV2;Byte;metricSelector=builtin:tech.generic.mem.workingSetSize:merge(\"dt.entity.process_group_instance\"):avg:names&entitySelector=type(PROCESS_GROUP_INSTANCE)", result.sli.Indicators["process_memory"])
+		assert.EqualValues(t, &keptnapi.SLOScore{Pass: "90%", Warning: "70%"}, result.slo.TotalScore)
+	}
+}
+
 // If you do not specify a Dashboard in dynatrace.conf.yaml (-> dashboard: "") and there is no dashboard already stored
 // in Keptn resources then we do not do anything
 func TestNoQueryingOfDashboardNecessaryDueToNotSpecifiedAndNotDashboardInKeptn(t *testing.T) {
@@ -168,8 +206,6 @@ func TestQueryingOfDashboardNecessaryDueToNotSpecifiedButStoredDashboardInKeptnW
 
 	from := time.Date(2021, 9, 17, 7, 0, 0, 0, time.UTC)
 	to := time.Date(2021, 9, 17, 8, 0, 0, 0, time.UTC)
-	actualResult, err := querying.GetSLIValues(dashboardID, from, to)
-
 	expectedResult := NewQueryResultFrom(&DashboardLink{
 		apiURL:         url,
 		startTimestamp: from,
@@ -177,8 +213,15 @@ func TestQueryingOfDashboardNecessaryDueToNotSpecifiedButStoredDashboardInKeptnW
 		dashboardID:    matchingDashboardID,
 	})
 
+	// the dashboard is the same as the stored one, so it has the same version and has not changed
+	expectedResult.dashboardVersion, err = dynatrace.GetDashboardVersion(string(dashboardContent))
+	assert.NoError(t, err)
+	expectedResult.previousDashboardVersion = expectedResult.dashboardVersion
+
+	actualResult, err := querying.GetSLIValues(dashboardID, from, to)
 	assert.Nil(t, err)
 	assert.EqualValues(t, expectedResult, actualResult)
+	assert.False(t, actualResult.HasDashboardChanged())
 }
 
 // If you do specify a Dashboard in dynatrace.conf.yaml (-> dashboard: "<some-dashboard-uuid>") then we will retrieve the
@@ -210,8 +253,6 @@ func TestRetrieveDashboardWithValidIDAndStoredDashboardInKeptnIsTheSame(t *testi
 
 	from := time.Date(2021, 9, 17, 7, 0, 0, 0, time.UTC)
 	to := time.Date(2021, 9, 17, 8, 0, 0, 0, time.UTC)
-	actualResult, err := querying.GetSLIValues(dashboardID, from, to)
-
 	expectedResult := NewQueryResultFrom(&DashboardLink{
 		apiURL:         url,
 		startTimestamp: from,
@@ -219,8 +260,15 @@ func TestRetrieveDashboardWithValidIDAndStoredDashboardInKeptnIsTheSame(t *testi
 		dashboardID:    dashboardID,
 	})
 
+	// the dashboard is the same as the stored one, so it has the same version and has not changed
+	expectedResult.dashboardVersion, err = dynatrace.GetDashboardVersion(string(dashboardContent))
+	assert.NoError(t, err)
+	expectedResult.previousDashboardVersion = expectedResult.dashboardVersion
+
+	actualResult, err := querying.GetSLIValues(dashboardID, from, to)
 	assert.Nil(t, err)
 	assert.EqualValues(t, expectedResult, actualResult)
+	assert.False(t, actualResult.HasDashboardChanged())
 }
 
 // If you do specify a Dashboard in dynatrace.conf.yaml (-> dashboard: "<some-dashboard-uuid>") then we will retrieve the
@@ -280,3 +328,47 @@ func TestRetrieveDashboardWithInvalidID(t *testing.T) {
 	}
 	assert.Contains(t, err.Error(), "UUID")
 }
+
+// Tests that a dashboard differing from the one stored by the previous evaluation is reported as changed, regardless of how the stored one was serialized
+func TestSetDashboardVersions(t *testing.T) {
+	dashboardContent, err := ioutil.ReadFile("./testdata/test_query_dynatrace_dashboard_dashboard_kqg.json")
+	if err != nil {
+		panic(err)
+	}
+
+	storedDashboard := &dynatrace.Dashboard{}
+	err = json.Unmarshal(dashboardContent, storedDashboard)
+	assert.NoError(t, err)
+
+	legacyDashboardContent, err := json.Marshal(storedDashboard)
+	assert.NoError(t, err)
+
+	changedDashboard := &dynatrace.Dashboard{}
+	err = json.Unmarshal(dashboardContent, changedDashboard)
+	assert.NoError(t, err)
+	changedDashboard.Tiles[0].Name = changedDashboard.Tiles[0].Name + ";pass=<500"
+
+	tests := []struct {
+		name                     string
+		dashboard                *dynatrace.Dashboard
+		existingDashboardContent string
+		expectedChanged          bool
+	}{
+		{name: "same dashboard", dashboard: storedDashboard, existingDashboardContent: string(dashboardContent)},
+		{name: "same dashboard stored without indentation", dashboard: storedDashboard, existingDashboardContent: string(legacyDashboardContent)},
+		{name: "changed dashboard", dashboard: changedDashboard, existingDashboardContent: string(dashboardContent), expectedChanged: true},
+		{name: "no stored dashboard", dashboard: changedDashboard},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &QueryResult{}
+			setDashboardVersions(result, tt.dashboard, tt.existingDashboardContent)
+
+			assert.Len(t, result.DashboardVersion(), 12)
+			assert.Equal(t, tt.expectedChanged, result.HasDashboardChanged())
+			if tt.existingDashboardContent == "" {
+				assert.Empty(t, result.PreviousDashboardVersion())
+			}
+		})
+	}
+}
@@ -1,12 +1,21 @@
 package dashboard
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	log "github.com/sirupsen/logrus"
 )
 
+// Prefixes of the criteria the dashboard is found by, e.g. dashboard: tag:quality-gate;owner:jane.doe@example.com
+const (
+	dashboardTagCriterionPrefix   = "tag:"
+	dashboardOwnerCriterionPrefix = "owner:"
+)
+
 type Retrieval struct {
 	client    dynatrace.ClientInterface
 	eventData adapter.EventContentAdapter
@@ -21,6 +30,7 @@ func NewRetrieval(client dynatrace.ClientInterface, eventData adapter.EventConte
 
 // Retrieve Depending on the dashboard parameter which is pulled from dynatrace.conf.yaml:dashboard this method either
 //   - query:        queries all dashboards on the Dynatrace Tenant and returns the one that matches project/service/stage, or
+//   - tag:/owner:  queries the dashboards with all of the tags and the owner, e.g. tag:quality-gate;tag:carts, and returns the only match, or
 //   - dashboard-ID: if this is a valid dashboard ID it will query the dashboard with this ID, e.g: ddb6a571-4bda-4e8b-a9c0-4a3e02c2e14a, or
 //   - <empty>:      it will not query any dashboard.
 // It returns a parsed Dynatrace Dashboard and the actual dashboard ID in case we queried a dashboard.
@@ -56,6 +66,15 @@ func (r *Retrieval) Retrieve(dashboard string) (*dynatrace.Dashboard, string, er
 			}).Debug("Dashboard option query found for dashboard")
 	}
 
+	// Option 3: Find the dashboard by its tags and owner
+	if isDashboardCriteria(dashboard) {
+		dashboardID, err := r.findDynatraceDashboardByCriteria(dashboard)
+		if err != nil {
+			return nil, dashboard, err
+		}
+		dashboard = dashboardID
+	}
+
 	// We have a Dashboard UUID - now lets query it!
 	log.WithField("dashboard", dashboard).Debug("Query dashboard")
 	dynatraceDashboard, err := dynatrace.NewDashboardsClient(r.client).GetByID(dashboard)
@@ -76,3 +95,51 @@ func (r *Retrieval) findDynatraceDashboard() (string, error) {
 
 	return dashboards.SearchForDashboardMatching(r.eventData.GetProject(), r.eventData.GetStage(), r.eventData.GetService()), nil
 }
+
+// findDynatraceDashboardByCriteria returns the ID of the only dashboard having all tags and the owner of the criteria
+func (r *Retrieval) findDynatraceDashboardByCriteria(criteria string) (string, error) {
+	owner, tags, err := parseDashboardCriteria(criteria)
+	if err != nil {
+		return "", err
+	}
+
+	dashboards, err := dynatrace.NewDashboardsClient(r.client).GetByOwnerAndTags(owner, tags)
+	if err != nil {
+		return "", err
+	}
+
+	switch len(dashboards.Dashboards) {
+	case 0:
+		return "", common.NewUserConfigurationError(fmt.Errorf("no dashboard matches '%s'", criteria))
+	case 1:
+		return dashboards.Dashboards[0].ID, nil
+	default:
+		ids := make([]string, 0, len(dashboards.Dashboards))
+		for _, dashboard := range dashboards.Dashboards {
+			ids = append(ids, dashboard.ID)
+		}
+		return "", common.NewUserConfigurationError(fmt.Errorf("several dashboards match '%s': %s", criteria, strings.Join(ids, ", ")))
+	}
+}
+
+// isDashboardCriteria returns whether the dashboard is specified by tags or owner instead of its ID
+func isDashboardCriteria(dashboard string) bool {
+	return strings.HasPrefix(dashboard, dashboardTagCriterionPrefix) || strings.HasPrefix(dashboard, dashboardOwnerCriterionPrefix)
+}
+
+// parseDashboardCriteria returns the owner and tags of semicolon-separated criteria, e.g. tag:quality-gate;owner:jane.doe@example.com
+func parseDashboardCriteria(criteria string) (string, []string, error) {
+	owner := ""
+	var tags []string
+	for _, criterion := range strings.Split(criteria, ";") {
+		switch {
+		case strings.HasPrefix(criterion, dashboardTagCriterionPrefix) && len(criterion) > len(dashboardTagCriterionPrefix):
+			tags = append(tags, strings.TrimPrefix(criterion, dashboardTagCriterionPrefix))
+		case strings.HasPrefix(criterion, dashboardOwnerCriterionPrefix) && len(criterion) > len(dashboardOwnerCriterionPrefix) && owner == "":
+			owner = strings.TrimPrefix(criterion, dashboardOwnerCriterionPrefix)
+		default:
+			return "", nil, common.NewUserConfigurationError(fmt.Errorf("invalid dashboard criterion '%s', expected tag:<tag> or a single owner:<owner>", criterion))
+		}
+	}
+	return owner, tags, nil
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/test"
 	"net/http"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestFindDynatraceDashboardSuccess(t *testing.T) {
@@ -139,3 +141,108 @@ func createDashboardRetrieval(eventData adapter.EventContentAdapter, handler htt
 
 	return retrieval, teardown
 }
+
+func TestRetrieveDynatraceDashboardByCriteria(t *testing.T) {
+	const dashboardsURL = "/api/config/v1/dashboards?owner=jane.doe%40example.com&tags=quality-gate&tags=carts"
+
+	tests := []struct {
+		name            string
+		dashboards      string
+		wantDashboardID string
+		wantErr         string
+	}{
+		{
+			name:            "single match",
+			dashboards:      `{"dashboards": [{"id": "12345678-1111-4444-8888-123456789012", "name": "Carts", "owner": "jane.doe@example.com"}]}`,
+			wantDashboardID: QUALITYGATE_DASHBOARD_ID,
+		},
+		{
+			name:       "no match",
+			dashboards: `{"dashboards": []}`,
+			wantErr:    "no dashboard matches 'tag:quality-gate;tag:carts;owner:jane.doe@example.com'",
+		},
+		{
+			name:       "several matches",
+			dashboards: `{"dashboards": [{"id": "dashboard-1"}, {"id": "dashboard-2"}]}`,
+			wantErr:    "several dashboards match 'tag:quality-gate;tag:carts;owner:jane.doe@example.com': dashboard-1, dashboard-2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			handler.AddExact(dashboardsURL, []byte(tt.dashboards))
+			handler.AddExact("/api/config/v1/dashboards/12345678-1111-4444-8888-123456789012", []byte(`{"id": "12345678-1111-4444-8888-123456789012", "dashboardMetadata": {"name": "Carts"}, "tiles": []}`))
+
+			dh, teardown := createDashboardRetrieval(createKeptnEvent(QUALITYGATE_PROJECT, QUALITYGATE_STAGE, QUALTIYGATE_SERVICE), handler)
+			defer teardown()
+
+			dashboard, dashboardID, err := dh.Retrieve("tag:quality-gate;tag:carts;owner:jane.doe@example.com")
+
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				assert.Equal(t, common.UserConfigurationErrorType, common.GetErrorType(err))
+				assert.Nil(t, dashboard)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, dashboard)
+			assert.Equal(t, tt.wantDashboardID, dashboardID)
+		})
+	}
+}
+
+func TestParseDashboardCriteria(t *testing.T) {
+	tests := []struct {
+		name      string
+		criteria  string
+		wantOwner string
+		wantTags  []string
+		wantErr   bool
+	}{
+		{
+			name:     "single tag",
+			criteria: "tag:quality-gate",
+			wantTags: []string{"quality-gate"},
+		},
+		{
+			name:      "tags and owner",
+			criteria:  "tag:quality-gate;owner:jane.doe@example.com;tag:keptn_service:carts",
+			wantOwner: "jane.doe@example.com",
+			wantTags:  []string{"quality-gate", "keptn_service:carts"},
+		},
+		{
+			name:      "owner only",
+			criteria:  "owner:jane.doe@example.com",
+			wantOwner: "jane.doe@example.com",
+		},
+		{
+			name:     "empty tag",
+			criteria: "tag:",
+			wantErr:  true,
+		},
+		{
+			name:     "several owners",
+			criteria: "owner:jane;owner:john",
+			wantErr:  true,
+		},
+		{
+			name:     "unknown criterion",
+			criteria: "tag:quality-gate;name:carts",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, tags, err := parseDashboardCriteria(tt.criteria)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOwner, owner)
+			assert.Equal(t, tt.wantTags, tags)
+		})
+	}
+}
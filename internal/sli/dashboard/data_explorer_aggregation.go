@@ -0,0 +1,128 @@
+package dashboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	log "github.com/sirupsen/logrus"
+)
+
+// percentileSpaceAggregationPrefix precedes the percentile of Data Explorer space aggregations, e.g. PERCENTILE_90
+const percentileSpaceAggregationPrefix = "PERCENTILE_"
+
+// dataExplorerSpaceAggregations maps the space aggregations of Data Explorer queries to aggregation transformations of the Metrics API v2
+var dataExplorerSpaceAggregations = map[string]string{
+	"AVG":    "avg",
+	"SUM":    "sum",
+	"MIN":    "min",
+	"MAX":    "max",
+	"COUNT":  "count",
+	"VALUE":  "value",
+	"MEDIAN": "median",
+}
+
+// aggregationError is returned if the aggregation of a query cannot be translated into an aggregation transformation, which makes the SLI of the query fail
+type aggregationError struct {
+	message string
+}
+
+func (e *aggregationError) Error() string {
+	return e.message
+}
+
+// getDataExplorerAggregation returns the aggregation transformation for the space aggregation of a Data Explorer query, e.g. percentile(90) for PERCENTILE_90.
+// The default aggregation of the metric is used for AUTO or if the metric does not support the aggregation. Unknown aggregations and invalid percentiles are returned as an aggregationError
+func getDataExplorerAggregation(spaceAggregation string, metricDefinition *dynatrace.MetricDefinition) (string, error) {
+	defaultAggregation, err := getDefaultAggregation(metricDefinition)
+	if err != nil {
+		return "", err
+	}
+
+	aggregationType, aggregation, err := parseDataExplorerSpaceAggregation(spaceAggregation)
+	if err != nil {
+		return "", err
+	}
+	if aggregation == "" {
+		return defaultAggregation, nil
+	}
+
+	if !isAggregationTypeSupported(aggregationType, metricDefinition) {
+		log.WithFields(
+			log.Fields{
+				"metric":             metricDefinition.MetricID,
+				"spaceAggregation":   spaceAggregation,
+				"defaultAggregation": defaultAggregation,
+			}).Warn("Metric does not support the aggregation of the Data Explorer query, using its default aggregation")
+		return defaultAggregation, nil
+	}
+
+	return aggregation, nil
+}
+
+// parseDataExplorerSpaceAggregation returns the type and the transformation of a space aggregation, or empty strings for the default aggregation
+func parseDataExplorerSpaceAggregation(spaceAggregation string) (string, string, error) {
+	normalizedAggregation := strings.ToUpper(strings.TrimSpace(spaceAggregation))
+	switch normalizedAggregation {
+	case "", "AUTO", "DEFAULT", "NONE":
+		return "", "", nil
+	}
+
+	if aggregation, ok := dataExplorerSpaceAggregations[normalizedAggregation]; ok {
+		return aggregation, aggregation, nil
+	}
+
+	if strings.HasPrefix(normalizedAggregation, percentileSpaceAggregationPrefix) {
+		percentile, err := strconv.ParseFloat(strings.TrimPrefix(normalizedAggregation, percentileSpaceAggregationPrefix), 64)
+		if err != nil {
+			return "", "", &aggregationError{message: fmt.Sprintf("invalid percentile in aggregation %s", spaceAggregation)}
+		}
+
+		aggregation, err := getPercentileAggregation(percentile)
+		if err != nil {
+			return "", "", err
+		}
+		return "percentile", aggregation, nil
+	}
+
+	return "", "", &aggregationError{message: fmt.Sprintf("unsupported Data Explorer aggregation %s", spaceAggregation)}
+}
+
+// getDefaultAggregation returns the aggregation transformation of the default aggregation of the metric
+func getDefaultAggregation(metricDefinition *dynatrace.MetricDefinition) (string, error) {
+	aggregationType := strings.ToUpper(metricDefinition.DefaultAggregation.Type)
+	if aggregationType == "PERCENTILE" {
+		return getPercentileAggregation(metricDefinition.DefaultAggregation.Parameter)
+	}
+
+	aggregation, ok := dataExplorerSpaceAggregations[aggregationType]
+	if !ok {
+		return "", &aggregationError{message: fmt.Sprintf("unsupported default aggregation %s of metric %s", metricDefinition.DefaultAggregation.Type, metricDefinition.MetricID)}
+	}
+	return aggregation, nil
+}
+
+// getPercentileAggregation returns the percentile aggregation transformation, e.g. percentile(99.9), validating that the percentile is between 0 and 100
+func getPercentileAggregation(percentile float64) (string, error) {
+	if percentile < 0 || percentile > 100 {
+		return "", &aggregationError{message: fmt.Sprintf("percentile %g is not between 0 and 100", percentile)}
+	}
+	return "percentile(" + strconv.FormatFloat(percentile, 'f', -1, 64) + ")", nil
+}
+
+// isAggregationTypeSupported returns whether the metric supports the aggregation type, assuming all types are supported if the metric does not list them.
+// median is a shortcut for percentile(50)
+func isAggregationTypeSupported(aggregationType string, metricDefinition *dynatrace.MetricDefinition) bool {
+	if len(metricDefinition.AggregationTypes) == 0 {
+		return true
+	}
+
+	for _, supportedType := range metricDefinition.AggregationTypes {
+		supportedType = strings.ToLower(supportedType)
+		if supportedType == aggregationType || (aggregationType == "median" && supportedType == "percentile") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDataExplorerAggregation(t *testing.T) {
+	responseTime := createMetricDefinition("builtin:service.response.time", "avg", 0, "auto", "avg", "count", "max", "median", "min", "percentile", "sum", "value")
+	requestCount := createMetricDefinition("builtin:service.requestCount.total", "value", 0, "auto", "value", "count", "sum", "avg", "max", "min")
+	percentileDefault := createMetricDefinition("calc:service.response.time", "percentile", 95)
+	invalidPercentileDefault := createMetricDefinition("calc:service.response.time", "percentile", 120)
+	unknownDefault := createMetricDefinition("calc:service.response.time", "rate", 0)
+
+	tests := []struct {
+		name                string
+		spaceAggregation    string
+		metricDefinition    *dynatrace.MetricDefinition
+		expectedAggregation string
+		expectedErr         string
+	}{
+		{name: "avg", spaceAggregation: "AVG", metricDefinition: responseTime, expectedAggregation: "avg"},
+		{name: "sum", spaceAggregation: "SUM", metricDefinition: responseTime, expectedAggregation: "sum"},
+		{name: "count", spaceAggregation: "COUNT", metricDefinition: responseTime, expectedAggregation: "count"},
+		{name: "min", spaceAggregation: "MIN", metricDefinition: responseTime, expectedAggregation: "min"},
+		{name: "max", spaceAggregation: "MAX", metricDefinition: responseTime, expectedAggregation: "max"},
+		{name: "value", spaceAggregation: "VALUE", metricDefinition: requestCount, expectedAggregation: "value"},
+		{name: "median", spaceAggregation: "MEDIAN", metricDefinition: responseTime, expectedAggregation: "median"},
+		{name: "percentile", spaceAggregation: "PERCENTILE_90", metricDefinition: responseTime, expectedAggregation: "percentile(90)"},
+		{name: "fractional percentile", spaceAggregation: "PERCENTILE_99.9", metricDefinition: responseTime, expectedAggregation: "percentile(99.9)"},
+		{name: "lower case", spaceAggregation: "percentile_50", metricDefinition: responseTime, expectedAggregation: "percentile(50)"},
+		{name: "auto", spaceAggregation: "AUTO", metricDefinition: responseTime, expectedAggregation: "avg"},
+		{name: "no aggregation", spaceAggregation: "", metricDefinition: requestCount, expectedAggregation: "value"},
+		{name: "percentile default aggregation", spaceAggregation: "AUTO", metricDefinition: percentileDefault, expectedAggregation: "percentile(95)"},
+		{name: "aggregation types not listed", spaceAggregation: "PERCENTILE_75", metricDefinition: percentileDefault, expectedAggregation: "percentile(75)"},
+		{name: "unsupported percentile falls back to default", spaceAggregation: "PERCENTILE_90", metricDefinition: requestCount, expectedAggregation: "value"},
+		{name: "unsupported median falls back to default", spaceAggregation: "MEDIAN", metricDefinition: requestCount, expectedAggregation: "value"},
+		{name: "percentile above 100", spaceAggregation: "PERCENTILE_150", metricDefinition: responseTime, expectedErr: "percentile 150 is not between 0 and 100"},
+		{name: "invalid percentile", spaceAggregation: "PERCENTILE_x", metricDefinition: responseTime, expectedErr: "invalid percentile in aggregation PERCENTILE_x"},
+		{name: "invalid default percentile", spaceAggregation: "AVG", metricDefinition: invalidPercentileDefault, expectedErr: "percentile 120 is not between 0 and 100"},
+		{name: "unknown aggregation", spaceAggregation: "RATE", metricDefinition: responseTime, expectedErr: "unsupported Data Explorer aggregation RATE"},
+		{name: "unknown default aggregation", spaceAggregation: "AUTO", metricDefinition: unknownDefault, expectedErr: "unsupported default aggregation rate of metric calc:service.response.time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aggregation, err := getDataExplorerAggregation(tt.spaceAggregation, tt.metricDefinition)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedAggregation, aggregation)
+		})
+	}
+}
+
+// Tests that a query of a Data Explorer tile with an aggregation that cannot be translated results in a failed SLI
+func TestDataExplorerTileProcessing_InvalidAggregationFailsSLI(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/v2/metrics/builtin:service.response.time", []byte(`{"metricId":"builtin:service.response.time","unit":"MicroSecond","aggregationTypes":["auto","avg","percentile"],"defaultAggregation":{"type":"avg"},"dimensionDefinitions":[]}`))
+
+	httpClient, url, teardown := test.CreateHTTPSClient(handler)
+	defer teardown()
+
+	client := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: "test"}, httpClient)
+	start := time.Unix(1571649000, 0).UTC()
+	processing := NewDataExplorerTileProcessing(client, createKeptnEvent("sockshop", "dev", "carts"), nil, start, start.Add(5*time.Minute), false)
+
+	tile := &dynatrace.Tile{
+		Name:     "Response time;sli=svc_rt_p95",
+		TileType: "DATA_EXPLORER",
+		Queries: []dynatrace.DataExplorerQuery{
+			{ID: "A", Metric: "builtin:service.response.time", SpaceAggregation: "PERCENTILE_150"},
+		},
+	}
+
+	tileResults := processing.Process(tile, nil)
+	if assert.Len(t, tileResults, 1) {
+		assert.Equal(t, "svc_rt_p95", tileResults[0].sliName)
+		assert.False(t, tileResults[0].sliResult.Success)
+		assert.Equal(t, "percentile 150 is not between 0 and 100", tileResults[0].sliResult.Message)
+	}
+}
+
+func createMetricDefinition(metricID string, defaultAggregationType string, defaultAggregationParameter float64, aggregationTypes ...string) *dynatrace.MetricDefinition {
+	metricDefinition := &dynatrace.MetricDefinition{
+		MetricID:         metricID,
+		AggregationTypes: aggregationTypes,
+	}
+	metricDefinition.DefaultAggregation.Type = defaultAggregationType
+	metricDefinition.DefaultAggregation.Parameter = defaultAggregationParameter
+	return metricDefinition
+}
@@ -0,0 +1,105 @@
+package dashboard
+
+import (
+	"strconv"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	log "github.com/sirupsen/logrus"
+)
+
+// applyTileThresholds returns the SLO definition of a query of a Data Explorer tile with the pass and warning criteria derived from the threshold of the query.
+// Criteria specified in the tile name take precedence, so the thresholds are only used if the tile name defines neither pass nor warning criteria.
+// The SLO definition of the tile is returned unchanged if no criteria are derived
+func applyTileThresholds(sloDefinition *keptncommon.SLO, tile *dynatrace.Tile, queryID string) *keptncommon.SLO {
+	if len(sloDefinition.Pass) > 0 || len(sloDefinition.Warning) > 0 {
+		return sloDefinition
+	}
+
+	threshold := getTileThreshold(tile, queryID)
+	if threshold == nil {
+		return sloDefinition
+	}
+
+	pass, warning, ok := getCriteriaFromThreshold(threshold)
+	if !ok {
+		log.WithFields(log.Fields{"tileName": tile.Name, "queryId": queryID}).Warn("Thresholds of Data Explorer tile must have three ascending or descending values, SLO criteria cannot be derived")
+		return sloDefinition
+	}
+
+	querySLODefinition := *sloDefinition
+	querySLODefinition.Pass = []*keptncommon.SLOCriteria{{Criteria: []string{pass}}}
+	querySLODefinition.Warning = []*keptncommon.SLOCriteria{{Criteria: []string{warning}}}
+	return &querySLODefinition
+}
+
+// getTileThreshold returns the visible threshold with values of the query of a Data Explorer tile. If the query has none, the threshold applying to all queries
+// of the tile is returned, or nil if there is none either
+func getTileThreshold(tile *dynatrace.Tile, queryID string) *dynatrace.Threshold {
+	if tile.VisualConfig == nil {
+		return nil
+	}
+
+	var tileThreshold *dynatrace.Threshold
+	for i := range tile.VisualConfig.Thresholds {
+		threshold := &tile.VisualConfig.Thresholds[i]
+		if (threshold.Visible != nil && !*threshold.Visible) || !hasThresholdValues(threshold) {
+			continue
+		}
+
+		if threshold.QueryID == queryID {
+			return threshold
+		}
+		if threshold.QueryID == "" && tileThreshold == nil {
+			tileThreshold = threshold
+		}
+	}
+
+	if tileThreshold != nil {
+		return tileThreshold
+	}
+
+	if tile.VisualConfig.Global != nil && tile.VisualConfig.Global.Threshold != nil && hasThresholdValues(tile.VisualConfig.Global.Threshold) {
+		return tile.VisualConfig.Global.Threshold
+	}
+
+	return nil
+}
+
+// hasThresholdValues returns whether any rule of the threshold has a value
+func hasThresholdValues(threshold *dynatrace.Threshold) bool {
+	for _, rule := range threshold.Rules {
+		if rule.Value != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getCriteriaFromThreshold returns the pass and warning criteria of the green, yellow and red rules of a threshold.
+// Ascending values mean lower values are better, e.g. 0, 500, 1000 results in <500 and <1000.
+// Descending values mean higher values are better, e.g. 99, 95, 0 results in >=99 and >=95
+func getCriteriaFromThreshold(threshold *dynatrace.Threshold) (string, string, bool) {
+	if len(threshold.Rules) != 3 {
+		return "", "", false
+	}
+
+	green, yellow, red := threshold.Rules[0].Value, threshold.Rules[1].Value, threshold.Rules[2].Value
+	if green == nil || yellow == nil || red == nil {
+		return "", "", false
+	}
+
+	if *green < *yellow && *yellow < *red {
+		return "<" + formatThresholdValue(*yellow), "<" + formatThresholdValue(*red), true
+	}
+
+	if *green > *yellow && *yellow > *red {
+		return ">=" + formatThresholdValue(*green), ">=" + formatThresholdValue(*yellow), true
+	}
+
+	return "", "", false
+}
+
+func formatThresholdValue(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
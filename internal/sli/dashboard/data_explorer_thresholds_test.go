@@ -0,0 +1,149 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyTileThresholds(t *testing.T) {
+	hidden := false
+
+	tests := []struct {
+		name            string
+		tileName        string
+		queryID         string
+		visualConfig    *dynatrace.VisualConfig
+		expectedPass    []*keptncommon.SLOCriteria
+		expectedWarning []*keptncommon.SLOCriteria
+	}{
+		{
+			name:         "ascending thresholds",
+			tileName:     "Response time;sli=svc_rt",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(0, 500, 1000)}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{"<1000"}}},
+		},
+		{
+			name:         "descending thresholds",
+			tileName:     "Availability;sli=availability",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(99.5, 95, 0)}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{">=99.5"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{">=95"}}},
+		},
+		{
+			name:         "global threshold of older dashboards",
+			tileName:     "Response time;sli=svc_rt",
+			visualConfig: &dynatrace.VisualConfig{Global: &dynatrace.VisualConfigGlobal{Threshold: thresholdPointer(createThreshold(0, 500, 1000))}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{"<1000"}}},
+		},
+		{
+			name:         "criteria in tile name take precedence",
+			tileName:     "Response time;sli=svc_rt;pass=<+10%",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(0, 500, 1000)}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<+10%"}}},
+		},
+		{
+			name:         "hidden thresholds are ignored",
+			tileName:     "Response time;sli=svc_rt",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{{Visible: &hidden, Rules: createThreshold(0, 500, 1000).Rules}}},
+		},
+		{
+			name:         "thresholds without values are ignored",
+			tileName:     "Response time;sli=svc_rt",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{{Rules: []dynatrace.ThresholdRule{{Color: "#7dc540"}, {Color: "#f5d30f"}, {Color: "#dc172a"}}}}},
+		},
+		{
+			name:         "unordered thresholds are ignored",
+			tileName:     "Response time;sli=svc_rt",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(0, 1000, 500)}},
+		},
+		{
+			name:         "threshold of the query",
+			tileName:     "Response time;sli=svc_rt",
+			queryID:      "B",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{withQueryID(createThreshold(0, 500, 1000), "A"), withQueryID(createThreshold(0, 200, 400), "B")}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<200"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{"<400"}}},
+		},
+		{
+			name:         "threshold of the query takes precedence over threshold of the tile",
+			tileName:     "Response time;sli=svc_rt",
+			queryID:      "B",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(0, 500, 1000), withQueryID(createThreshold(0, 200, 400), "B")}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<200"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{"<400"}}},
+		},
+		{
+			name:         "threshold of the tile applies to queries without threshold",
+			tileName:     "Response time;sli=svc_rt",
+			queryID:      "B",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{withQueryID(createThreshold(0, 200, 400), "A"), createThreshold(0, 500, 1000)}},
+			expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}, expectedWarning: []*keptncommon.SLOCriteria{{Criteria: []string{"<1000"}}},
+		},
+		{
+			name:         "thresholds of other queries are ignored",
+			tileName:     "Response time;sli=svc_rt",
+			queryID:      "B",
+			visualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{withQueryID(createThreshold(0, 200, 400), "A")}},
+		},
+		{
+			name:     "no visual config",
+			tileName: "Response time;sli=svc_rt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tileSLODefinition := common.ParsePassAndWarningWithoutDefaultsFrom(tt.tileName)
+			sloDefinition := applyTileThresholds(tileSLODefinition, &dynatrace.Tile{Name: tt.tileName, VisualConfig: tt.visualConfig}, tt.queryID)
+
+			assert.Equal(t, tileSLODefinition.SLI, sloDefinition.SLI)
+			assert.Equal(t, tt.expectedPass, sloDefinition.Pass)
+			assert.Equal(t, tt.expectedWarning, sloDefinition.Warning)
+		})
+	}
+}
+
+// Tests that the SLO criteria are only derived from the thresholds of Data Explorer tiles if enabled
+func TestDataExplorerTileProcessing_TileThresholdsEnabled(t *testing.T) {
+	tile := &dynatrace.Tile{
+		Name:         "Response time;sli=svc_rt",
+		VisualConfig: &dynatrace.VisualConfig{Thresholds: []dynatrace.Threshold{createThreshold(0, 500, 1000)}},
+	}
+	dataQuery := dynatrace.DataExplorerQuery{ID: "A", Metric: "builtin:service.response.time"}
+
+	tests := []struct {
+		name         string
+		enabled      bool
+		expectedPass []*keptncommon.SLOCriteria
+	}{
+		{name: "disabled"},
+		{name: "enabled", enabled: true, expectedPass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processing := NewDataExplorerTileProcessing(nil, createKeptnEvent("sockshop", "dev", "carts"), nil, time.Now(), time.Now(), tt.enabled)
+			sloDefinition := processing.getQuerySLODefinition(common.ParsePassAndWarningWithoutDefaultsFrom(tile.Name), tile, dataQuery)
+			assert.Equal(t, tt.expectedPass, sloDefinition.Pass)
+		})
+	}
+}
+
+func withQueryID(threshold dynatrace.Threshold, queryID string) dynatrace.Threshold {
+	threshold.QueryID = queryID
+	return threshold
+}
+
+func createThreshold(green float64, yellow float64, red float64) dynatrace.Threshold {
+	return dynatrace.Threshold{
+		Rules: []dynatrace.ThresholdRule{
+			{Value: &green, Color: "#7dc540"},
+			{Value: &yellow, Color: "#f5d30f"},
+			{Value: &red, Color: "#dc172a"},
+		},
+	}
+}
+
+func thresholdPointer(threshold dynatrace.Threshold) *dynatrace.Threshold {
+	return &threshold
+}
@@ -1,11 +1,13 @@
 package dashboard
 
 import (
+	"errors"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/sli/metrics"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
 	"strings"
@@ -18,18 +20,28 @@ type DataExplorerTileProcessing struct {
 	customFilters []*keptnv2.SLIFilter
 	startUnix     time.Time
 	endUnix       time.Time
+
+	// tileThresholds enables deriving the SLO criteria of tiles without criteria in their names from the thresholds of their queries
+	tileThresholds bool
 }
 
-func NewDataExplorerTileProcessing(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time) *DataExplorerTileProcessing {
+func NewDataExplorerTileProcessing(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time, tileThresholds bool) *DataExplorerTileProcessing {
 	return &DataExplorerTileProcessing{
 		client:        client,
 		eventData:     eventData,
 		customFilters: customFilters,
 		startUnix:     startUnix,
 		endUnix:       endUnix,
+
+		tileThresholds: tileThresholds,
 	}
 }
 
+// CanProcess returns whether the tile is a Data Explorer tile
+func (p *DataExplorerTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == "DATA_EXPLORER"
+}
+
 func (p *DataExplorerTileProcessing) Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
 	// get the tile specific management zone filter that might be needed by different tile processors
 	// Check for tile management zone filter - this would overwrite the dashboardManagementZoneFilter
@@ -51,19 +63,62 @@ func (p *DataExplorerTileProcessing) Process(tile *dynatrace.Tile, dashboardFilt
 		// First lets generate the query and extract all important metric information we need for generating SLIs & SLOs
 		metricQuery, err := p.generateMetricQueryFromDataExplorerQuery(dataQuery, tileManagementZoneFilter, p.startUnix, p.endUnix)
 
+		// an aggregation that cannot be translated makes the SLI fail, as querying another aggregation would return a misleading value
+		var aggErr *aggregationError
+		if errors.As(err, &aggErr) {
+			log.WithError(err).WithField("metric", dataQuery.Metric).Warn("Data explorer query has an invalid aggregation, SLI fails")
+			tileResults = append(tileResults, newFailedTileResult(sloDefinition.SLI, err))
+			continue
+		}
+
 		// if there was no error we generate the SLO & SLO definition
 		if err != nil {
 			log.WithError(err).Warn("generateMetricQueryFromDataExplorerQuery returned an error, SLI will not be used")
 			continue
 		}
 
-		results := NewMetricsQueryProcessing(p.client).Process(len(dataQuery.SplitBy), sloDefinition, metricQuery)
+		results := NewMetricsQueryProcessing(p.client).Process(len(dataQuery.SplitBy), p.getQuerySLODefinition(sloDefinition, tile, dataQuery), metricQuery)
 		tileResults = append(tileResults, results...)
 	}
 
 	return tileResults
 }
 
+// getQuerySLODefinition returns the SLO definition of a query of the tile, which derives its criteria from the thresholds of the query if enabled
+func (p *DataExplorerTileProcessing) getQuerySLODefinition(sloDefinition *keptncommon.SLO, tile *dynatrace.Tile, dataQuery dynatrace.DataExplorerQuery) *keptncommon.SLO {
+	if !p.tileThresholds {
+		return sloDefinition
+	}
+	return applyTileThresholds(sloDefinition, tile, dataQuery.ID)
+}
+
+// ProcessDefinitions generates the SLI & SLO definitions of the tile without querying any metric values
+func (p *DataExplorerTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	tileManagementZoneFilter := NewManagementZoneFilter(dashboardFilter, tile.TileFilter.ManagementZone)
+
+	sloDefinition := common.ParsePassAndWarningWithoutDefaultsFrom(tile.Name)
+	if sloDefinition.SLI == "" {
+		log.WithField("tileName", tile.Name).Debug("Data explorer tile not included as name doesnt include sli=SLINAME")
+		return nil
+	}
+
+	var tileResults []*TileResult
+	for _, dataQuery := range tile.Queries {
+		metricQuery, err := p.generateMetricQueryFromDataExplorerQuery(dataQuery, tileManagementZoneFilter, p.startUnix, p.endUnix)
+		if err != nil {
+			log.WithError(err).Warn("generateMetricQueryFromDataExplorerQuery returned an error, SLI will not be used")
+			continue
+		}
+
+		tileResult := NewMetricsQueryProcessing(p.client).ProcessDefinition(len(dataQuery.SplitBy), p.getQuerySLODefinition(sloDefinition, tile, dataQuery), metricQuery)
+		if tileResult != nil {
+			tileResults = append(tileResults, tileResult)
+		}
+	}
+
+	return tileResults
+}
+
 // Looks at the DataExplorerQuery configuration of a data explorer chart and generates the Metrics Query.
 //
 // Returns a queryComponents object
@@ -84,10 +139,14 @@ func (p *DataExplorerTileProcessing) generateMetricQueryFromDataExplorerQuery(da
 		return nil, err
 	}
 
+	metricAggregation, err := getDataExplorerAggregation(dataQuery.SpaceAggregation, metricDefinition)
+	if err != nil {
+		return nil, err
+	}
+
 	// building the merge aggregator string, e.g: merge("dt.entity.disk"):merge("dt.entity.host") - or merge("dt.entity.service")
 	// TODO: 2021-09-20: Check for redundant code after update to use dimension keys rather than indexes
 	metricDimensionCount := len(metricDefinition.DimensionDefinitions)
-	metricAggregation := metricDefinition.DefaultAggregation.Type
 	mergeAggregator := ""
 	filterAggregator := ""
 	filterSLIDefinitionAggregator := ""
@@ -149,7 +208,7 @@ func (p *DataExplorerTileProcessing) generateMetricQueryFromDataExplorerQuery(da
 	// lets create the metricSelector and entitySelector
 	// ATTENTION: adding :names so we also get the names of the dimensions and not just the entities. This means we get two values for each dimension
 	metricQuery := fmt.Sprintf("metricSelector=%s%s%s:%s:names%s%s",
-		dataQuery.Metric, mergeAggregator, filterAggregator, strings.ToLower(metricAggregation),
+		dataQuery.Metric, mergeAggregator, filterAggregator, metricAggregation,
 		entityFilter, tileManagementZoneFilter.ForEntitySelector())
 
 	// lets build the Dynatrace API Metric query for the proposed timeframe and additonal filters!
@@ -9,9 +9,13 @@ import (
 	keptncommon "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
+	"regexp"
 	"strings"
 )
 
+// entityIDPattern matches the IDs of Dynatrace entities, e.g. SERVICE-0123456789ABCDEF
+var entityIDPattern = regexp.MustCompile(`^[A-Z_]+-[0-9A-F]{16}$`)
+
 type MetricsQueryProcessing struct {
 	client dynatrace.ClientInterface
 }
@@ -27,7 +31,10 @@ func NewMetricsQueryProcessing(client dynatrace.ClientInterface) *MetricsQueryPr
 func (r *MetricsQueryProcessing) Process(noOfDimensionsInChart int, sloDefinition *keptncommon.SLO, metricQueryComponents *queryComponents) []*TileResult {
 
 	// Lets run the Query and iterate through all data per dimension. Each Dimension will become its own indicator
-	queryResult, err := dynatrace.NewMetricsClient(r.client).GetByQuery(metricQueryComponents.fullMetricQueryString)
+	queryResult, err := dynatrace.NewMetricsClient(r.client).GetByQueryWithOptions(metricQueryComponents.fullMetricQueryString,
+		dynatrace.MetricsQueryOptions{
+			IsWantedMetricID: func(metricID string) bool { return query.IsMatchingMetricID(metricID, metricQueryComponents.metricID) },
+		})
 	if err != nil {
 		log.WithError(err).Debug("No result for query")
 
@@ -49,6 +56,7 @@ func (r *MetricsQueryProcessing) Process(noOfDimensionsInChart int, sloDefinitio
 	}
 
 	var tileResults []*TileResult
+	entityDisplayNames := r.getEntityDisplayNames(queryResult.Result, noOfDimensionsInChart)
 
 	// SUCCESS-CASE: we retrieved values - now we iterate through the results and create an indicator result for every dimension
 	for _, singleResult := range queryResult.Result {
@@ -97,7 +105,11 @@ func (r *MetricsQueryProcessing) Process(noOfDimensionsInChart int, sloDefinitio
 				// lets iterate through the list and get all names
 				for dimIx := 0; dimIx < len(singleDataEntry.Dimensions); dimIx = dimIx + dimensionIncrement {
 					dimensionValue := singleDataEntry.Dimensions[dimIx]
-					indicatorName = indicatorName + "_" + dimensionValue
+					if displayName, ok := entityDisplayNames[dimensionValue]; ok && dimensionIncrement == 1 {
+						indicatorName = indicatorName + "_" + displayName
+					} else {
+						indicatorName = indicatorName + "_" + dimensionValue
+					}
 
 					filterSLIDefinitionAggregatorValue = ":names" + strings.Replace(metricQueryComponents.filterSLIDefinitionAggregator, "FILTERDIMENSIONVALUE", dimensionValue, 1)
 
@@ -156,3 +168,76 @@ func (r *MetricsQueryProcessing) Process(noOfDimensionsInChart int, sloDefinitio
 
 	return tileResults
 }
+
+// getEntityDisplayNames returns the display names of the entities whose IDs are returned as dimension values without their names,
+// so that the indicator names are the same as for entity dimensions with names. Entities that cannot be retrieved keep their IDs as names
+func (r *MetricsQueryProcessing) getEntityDisplayNames(results []dynatrace.MetricQueryResultValues, noOfDimensionsInChart int) map[string]string {
+	var entityIDs []string
+	isAdded := make(map[string]bool)
+	for _, singleResult := range results {
+		if len(singleResult.Data) <= 1 {
+			continue
+		}
+		for _, singleDataEntry := range singleResult.Data {
+			if len(singleDataEntry.Dimensions) == noOfDimensionsInChart*2 {
+				continue
+			}
+			for _, dimensionValue := range singleDataEntry.Dimensions {
+				if entityIDPattern.MatchString(dimensionValue) && !isAdded[dimensionValue] {
+					isAdded[dimensionValue] = true
+					entityIDs = append(entityIDs, dimensionValue)
+				}
+			}
+		}
+	}
+
+	if len(entityIDs) == 0 {
+		return nil
+	}
+
+	entities, err := dynatrace.NewEntitiesClient(r.client).GetByIDs(entityIDs, nil)
+	if err != nil {
+		log.WithError(err).Warn("Could not retrieve the names of the entities, using their IDs in the indicator names")
+		return nil
+	}
+
+	displayNames := make(map[string]string, len(entities))
+	for _, entity := range entities {
+		if entity.DisplayName != "" {
+			displayNames[entity.EntityID] = entity.DisplayName
+		}
+	}
+	return displayNames
+}
+
+// ProcessDefinition generates the SLI & SLO definition based on the metric query without running it.
+// Charts split by dimensions are not supported, as an indicator is generated per dimension value returned by the query.
+func (r *MetricsQueryProcessing) ProcessDefinition(noOfSplitDimensionsInChart int, sloDefinition *keptncommon.SLO, metricQueryComponents *queryComponents) *TileResult {
+	if noOfSplitDimensionsInChart > 0 {
+		log.WithField("sli", sloDefinition.SLI).Warn("Chart is split by dimensions, SLI cannot be generated without querying the dimension values")
+		return nil
+	}
+
+	indicatorName := common.CleanIndicatorName(sloDefinition.SLI)
+	return &TileResult{
+		objective: &keptncommon.SLO{
+			SLI:     indicatorName,
+			Weight:  sloDefinition.Weight,
+			KeySLI:  sloDefinition.KeySLI,
+			Pass:    sloDefinition.Pass,
+			Warning: sloDefinition.Warning,
+		},
+		sliName:  indicatorName,
+		sliQuery: createSLIDefinitionQuery(metricQueryComponents),
+	}
+}
+
+// createSLIDefinitionQuery only adds the MV2 prefix for the units that are converted when the query is executed, as other units are rejected
+func createSLIDefinitionQuery(metricQueryComponents *queryComponents) string {
+	switch strings.ToLower(metricQueryComponents.metricUnit) {
+	case "byte", "microsecond":
+		return fmt.Sprintf("MV2;%s;%s", metricQueryComponents.metricUnit, metricQueryComponents.metricQuery)
+	default:
+		return metricQueryComponents.metricQuery
+	}
+}
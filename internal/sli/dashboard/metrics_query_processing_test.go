@@ -0,0 +1,72 @@
+package dashboard
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that entity IDs returned without names are replaced by the display names of the entities in the indicator names
+func TestMetricsQueryProcessing_Process_ResolvesEntityIDsWithoutNames(t *testing.T) {
+	tests := []struct {
+		name                   string
+		entitiesStatus         int
+		entitiesResponse       string
+		expectedIndicatorNames []string
+	}{
+		{
+			name:                   "names of entities are used",
+			entitiesStatus:         http.StatusOK,
+			entitiesResponse:       `{"totalCount": 2, "pageSize": 50, "entities": [{"entityId": "SERVICE-0123456789ABCDEF", "displayName": "carts"}, {"entityId": "SERVICE-FEDCBA9876543210", "displayName": "orders"}]}`,
+			expectedIndicatorNames: []string{"response_time_carts", "response_time_orders"},
+		},
+		{
+			name:                   "missing entities keep their IDs",
+			entitiesStatus:         http.StatusOK,
+			entitiesResponse:       `{"totalCount": 1, "pageSize": 50, "entities": [{"entityId": "SERVICE-0123456789ABCDEF", "displayName": "carts"}]}`,
+			expectedIndicatorNames: []string{"response_time_carts", "response_time_SERVICE-FEDCBA9876543210"},
+		},
+		{
+			name:                   "entities cannot be retrieved",
+			entitiesStatus:         http.StatusForbidden,
+			entitiesResponse:       `{"error": {"code": 403, "message": "Token is missing required scope"}}`,
+			expectedIndicatorNames: []string{"response_time_SERVICE-0123456789ABCDEF", "response_time_SERVICE-FEDCBA9876543210"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			handler.AddStartsWith("/api/v2/metrics/query", []byte(`{"totalCount": 2, "resolution": "Inf", "result": [{"metricId": "builtin:service.response.time:splitBy(\"dt.entity.service\")", "data": [
+				{"dimensions": ["SERVICE-0123456789ABCDEF"], "timestamps": [1], "values": [1000]},
+				{"dimensions": ["SERVICE-FEDCBA9876543210"], "timestamps": [1], "values": [2000]}
+			]}]}`))
+			if tt.entitiesStatus == http.StatusOK {
+				handler.AddStartsWith("/api/v2/entities", []byte(tt.entitiesResponse))
+			} else {
+				handler.AddStartsWithError("/api/v2/entities", tt.entitiesStatus, []byte(tt.entitiesResponse))
+			}
+
+			httpClient, url, teardown := test.CreateHTTPSClient(handler)
+			defer teardown()
+			dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: url, ApiToken: "test"}, httpClient)
+
+			tileResults := NewMetricsQueryProcessing(dtClient).Process(1, &keptncommon.SLO{SLI: "response_time"}, &queryComponents{
+				metricID:              "builtin:service.response.time:splitBy(\"dt.entity.service\")",
+				metricUnit:            "MicroSecond",
+				metricQuery:           "metricSelector=builtin:service.response.time:splitBy(\"dt.entity.service\")",
+				fullMetricQueryString: "metricSelector=builtin:service.response.time:splitBy(\"dt.entity.service\")&from=1&to=2&resolution=Inf",
+			})
+
+			var indicatorNames []string
+			for _, tileResult := range tileResults {
+				indicatorNames = append(indicatorNames, tileResult.sliName)
+			}
+			assert.Equal(t, tt.expectedIndicatorNames, indicatorNames)
+		})
+	}
+}
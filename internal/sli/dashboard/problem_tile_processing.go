@@ -45,10 +45,7 @@ func (p *ProblemTileProcessing) Process(tile *dynatrace.Tile, dashboardFilter *d
 // If successful returns sliResult, sliIndicatorName, sliQuery & sloDefinition
 func (p *ProblemTileProcessing) processOpenProblemTile(problemSelector string, startUnix time.Time, endUnix time.Time) (*TileResult, error) {
 
-	problemQuery := ""
-	if problemSelector != "" {
-		problemQuery = fmt.Sprintf("problemSelector=%s", problemSelector)
-	}
+	problemQuery := createProblemQuery(problemSelector)
 
 	// Step 1: Query the Dynatrace API to get the number of actual problems matching that query and timeframe
 	problemQueryResult, err := dynatrace.NewProblemsV2Client(p.client).GetByQuery(problemQuery, startUnix, endUnix)
@@ -75,8 +72,26 @@ func (p *ProblemTileProcessing) processOpenProblemTile(problemSelector string, s
 
 	// add this to our SLI Indicator JSON in case we need to generate an SLI.yaml
 	// we prepend this with PV2;entitySelector=asdaf&problemSelector=asdf
-	sliQuery := fmt.Sprintf("PV2;%s", problemQuery)
+	return createProblemCountTileResult(indicatorName, fmt.Sprintf("PV2;%s", problemQuery), sliResult), nil
+}
+
+// ProcessDefinition generates the SLI & SLO definition of the tile without querying the number of open problems
+func (p *ProblemTileProcessing) ProcessDefinition(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) *TileResult {
+	tileManagementZoneFilter := NewManagementZoneFilter(dashboardFilter, tile.TileFilter.ManagementZone)
+	problemQuery := createProblemQuery("status(open)" + tileManagementZoneFilter.ForProblemSelector())
+
+	return createProblemCountTileResult("problems", fmt.Sprintf("PV2;%s", problemQuery), nil)
+}
 
+func createProblemQuery(problemSelector string) string {
+	if problemSelector == "" {
+		return ""
+	}
+	return fmt.Sprintf("problemSelector=%s", problemSelector)
+}
+
+// createProblemCountTileResult creates the TileResult for a count of problems. The current default is that there is a pass criteria of <= 0 as we dont allow problems
+func createProblemCountTileResult(indicatorName string, sliQuery string, sliResult *keptnv2.SLIResult) *TileResult {
 	// lets add the SLO definitin in case we need to generate an SLO.yaml
 	// we normally parse these values from the tile name. In this case we just build that tile name -> maybe in the future we will allow users to add additional SLO defs via the Tile Name, e.g: weight or KeySli
 	sloString := fmt.Sprintf("sli=%s;pass=<=0;key=true", indicatorName)
@@ -87,5 +102,5 @@ func (p *ProblemTileProcessing) processOpenProblemTile(problemSelector string, s
 		objective: sloDefinition,
 		sliName:   indicatorName,
 		sliQuery:  sliQuery,
-	}, nil
+	}
 }
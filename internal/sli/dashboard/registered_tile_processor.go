@@ -0,0 +1,127 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	pkgdashboard "github.com/keptn-contrib/dynatrace-service/pkg/dashboard"
+	pkgdynatrace "github.com/keptn-contrib/dynatrace-service/pkg/dynatrace"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// newRegisteredTileProcessors creates the tile processors of the factories, e.g. the ones registered by a custom build, for the event and timeframe
+func newRegisteredTileProcessors(factories []pkgdashboard.TileProcessorFactory, client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time) []TileProcessor {
+	if len(factories) == 0 {
+		return nil
+	}
+
+	exportedClient := &exportedClient{client: client}
+	eventContext := pkgdashboard.EventContext{
+		Project:       eventData.GetProject(),
+		Stage:         eventData.GetStage(),
+		Service:       eventData.GetService(),
+		Labels:        eventData.GetLabels(),
+		CustomFilters: customFilters,
+	}
+
+	var tileProcessors []TileProcessor
+	for _, createTileProcessor := range factories {
+		tileProcessors = append(tileProcessors, &registeredTileProcessor{processor: createTileProcessor(exportedClient, eventContext, startUnix, endUnix)})
+	}
+	return tileProcessors
+}
+
+// registeredTileProcessor adapts a TileProcessor of package pkg/dashboard to the TileProcessor of the processing
+type registeredTileProcessor struct {
+	processor pkgdashboard.TileProcessor
+}
+
+func (p *registeredTileProcessor) CanProcess(tile *dynatrace.Tile) bool {
+	exportedTile := &pkgdynatrace.Tile{}
+	if err := convert(tile, exportedTile); err != nil {
+		log.WithError(err).WithField("tileName", tile.Title()).Warn("Could not convert tile for registered tile processor")
+		return false
+	}
+
+	return p.processor.CanProcess(exportedTile)
+}
+
+func (p *registeredTileProcessor) Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	return p.process(p.processor.Process, tile, dashboardFilter)
+}
+
+func (p *registeredTileProcessor) ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	return p.process(p.processor.ProcessDefinitions, tile, dashboardFilter)
+}
+
+func (p *registeredTileProcessor) process(processTile func(*pkgdynatrace.Tile, *pkgdynatrace.DashboardFilter) []*pkgdashboard.TileResult, tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	exportedTile := &pkgdynatrace.Tile{}
+	if err := convert(tile, exportedTile); err != nil {
+		log.WithError(err).WithField("tileName", tile.Title()).Warn("Could not convert tile for registered tile processor")
+		return nil
+	}
+
+	var exportedDashboardFilter *pkgdynatrace.DashboardFilter
+	if dashboardFilter != nil {
+		exportedDashboardFilter = &pkgdynatrace.DashboardFilter{}
+		if err := convert(dashboardFilter, exportedDashboardFilter); err != nil {
+			log.WithError(err).WithField("tileName", tile.Title()).Warn("Could not convert dashboard filter for registered tile processor")
+			return nil
+		}
+	}
+
+	var results []*TileResult
+	for _, result := range processTile(exportedTile, exportedDashboardFilter) {
+		if result != nil {
+			results = append(results, NewTileResult(result.SLIName, result.SLIQuery, result.Objective, result.SLIResult))
+		}
+	}
+	return results
+}
+
+// convert copies the internal type from to the exported type to. Both types have the same JSON representation
+func convert(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// exportedClient adapts the Dynatrace client of the dynatrace-service to the ClientInterface of package pkg/dynatrace
+type exportedClient struct {
+	client dynatrace.ClientInterface
+}
+
+func (c *exportedClient) Get(apiPath string) ([]byte, error) {
+	return c.client.Get(apiPath)
+}
+
+func (c *exportedClient) Post(apiPath string, body []byte) ([]byte, error) {
+	return c.client.Post(apiPath, body)
+}
+
+func (c *exportedClient) PostPlainText(apiPath string, body []byte) ([]byte, error) {
+	return c.client.PostPlainText(apiPath, body)
+}
+
+func (c *exportedClient) Put(apiPath string, body []byte) ([]byte, error) {
+	return c.client.Put(apiPath, body)
+}
+
+func (c *exportedClient) Delete(apiPath string) ([]byte, error) {
+	return c.client.Delete(apiPath)
+}
+
+func (c *exportedClient) Credentials() *pkgdynatrace.Credentials {
+	dtCredentials := c.client.Credentials()
+	return &pkgdynatrace.Credentials{
+		Tenant:            dtCredentials.Tenant,
+		ApiToken:          dtCredentials.ApiToken,
+		SecondaryApiToken: dtCredentials.SecondaryApiToken,
+		ConfigAPIURL:      dtCredentials.ConfigAPIURL,
+	}
+}
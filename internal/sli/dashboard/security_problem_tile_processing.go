@@ -2,7 +2,6 @@ package dashboard
 
 import (
 	"fmt"
-	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	log "github.com/sirupsen/logrus"
@@ -45,10 +44,7 @@ func (p *SecurityProblemTileProcessing) Process(tile *dynatrace.Tile, dashboardF
 // If successful returns sliResult, sliIndicatorName, sliQuery & sloDefinition
 func (p *SecurityProblemTileProcessing) processProblemSelector(securityProblemSelector string, startUnix time.Time, endUnix time.Time) (*TileResult, error) {
 
-	problemQuery := ""
-	if securityProblemSelector != "" {
-		problemQuery = fmt.Sprintf("securityProblemSelector=%s", securityProblemSelector)
-	}
+	problemQuery := createSecurityProblemQuery(securityProblemSelector)
 
 	// Step 1: Query the Dynatrace API to get the number of actual problems matching that query and timeframe
 	problemQueryResult, err := dynatrace.NewSecurityProblemsClient(p.client).GetByQuery(problemQuery, startUnix, endUnix)
@@ -75,17 +71,20 @@ func (p *SecurityProblemTileProcessing) processProblemSelector(securityProblemSe
 
 	// add this to our SLI Indicator JSON in case we need to generate an SLI.yaml
 	// we prepend this with SECPV2;entitySelector=asdaf&problemSelector=asdf
-	sliQuery := fmt.Sprintf("SECPV2;%s", problemQuery)
-
-	// lets add the SLO definitin in case we need to generate an SLO.yaml
-	// we normally parse these values from the tile name. In this case we just build that tile name -> maybe in the future we will allow users to add additional SLO defs via the Tile Name, e.g: weight or KeySli
-	sloString := fmt.Sprintf("sli=%s;pass=<=0;key=true", indicatorName)
-	sloDefinition := common.ParsePassAndWarningWithoutDefaultsFrom(sloString)
-
-	return &TileResult{
-		sliResult: sliResult,
-		objective: sloDefinition,
-		sliName:   indicatorName,
-		sliQuery:  sliQuery,
-	}, nil
+	return createProblemCountTileResult(indicatorName, fmt.Sprintf("SECPV2;%s", problemQuery), sliResult), nil
+}
+
+// ProcessDefinition generates the SLI & SLO definition of the tile without querying the number of open security problems
+func (p *SecurityProblemTileProcessing) ProcessDefinition(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) *TileResult {
+	tileManagementZoneFilter := NewManagementZoneFilter(dashboardFilter, tile.TileFilter.ManagementZone)
+	problemQuery := createSecurityProblemQuery("status(OPEN)" + tileManagementZoneFilter.ForProblemSelector())
+
+	return createProblemCountTileResult("security_problems", fmt.Sprintf("SECPV2;%s", problemQuery), nil)
+}
+
+func createSecurityProblemQuery(securityProblemSelector string) string {
+	if securityProblemSelector == "" {
+		return ""
+	}
+	return fmt.Sprintf("securityProblemSelector=%s", securityProblemSelector)
 }
@@ -24,7 +24,13 @@ func NewSLOTileProcessing(client dynatrace.ClientInterface, startUnix time.Time,
 	}
 }
 
-func (p *SLOTileProcessing) Process(tile *dynatrace.Tile) []*TileResult {
+// CanProcess returns whether the tile is an SLO tile
+func (p *SLOTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == "SLO"
+}
+
+// Process queries the SLOs shown on the tile. SLO tiles are not filtered by the management zone of the dashboard
+func (p *SLOTileProcessing) Process(tile *dynatrace.Tile, _ *dynatrace.DashboardFilter) []*TileResult {
 	// we will take the SLO definition from Dynatrace
 	var results []*TileResult
 
@@ -60,6 +66,36 @@ func (p *SLOTileProcessing) processSLOTile(sloID string, startUnix time.Time, en
 		return nil, "", "", nil, err
 	}
 
+	sliResult, indicatorName, sliQuery, sloDefinition := createSLOTileResult(sloID, sloResult)
+	return sliResult, indicatorName, sliQuery, sloDefinition, nil
+}
+
+// ProcessDefinitions generates the SLI & SLO definitions of the SLOs shown on the tile using their current definitions in Dynatrace
+func (p *SLOTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, _ *dynatrace.DashboardFilter) []*TileResult {
+	var results []*TileResult
+	for _, sloEntity := range tile.AssignedEntities {
+		sloResult, err := dynatrace.NewSLOClient(p.client).GetDefinition(sloEntity)
+		if err != nil {
+			log.WithError(err).WithField("sloEntity", sloEntity).Error("Error retrieving SLO definition")
+			continue
+		}
+
+		_, sliIndicator, sliQuery, sloDefinition := createSLOTileResult(sloEntity, sloResult)
+		results = append(
+			results,
+			&TileResult{
+				objective: sloDefinition,
+				sliName:   sliIndicator,
+				sliQuery:  sliQuery,
+			})
+	}
+
+	return results
+}
+
+// createSLOTileResult returns sliResult, sliIndicatorName, sliQuery & sloDefinition for the SLO
+func createSLOTileResult(sloID string, sloResult *dynatrace.SLOResult) (*keptnv2.SLIResult, string, string, *keptncommon.SLO) {
+
 	// Step 2: As we have the SLO Result including SLO Definition we add it to the SLI & SLO objects
 	// IndicatorName is based on the slo Name
 	// the value defaults to the E
@@ -97,5 +133,5 @@ func (p *SLOTileProcessing) processSLOTile(sloID string, startUnix time.Time, en
 	sloString := fmt.Sprintf("sli=%s;pass=>=%f;warning=>=%f", indicatorName, warning, target)
 	sloDefinition := common.ParsePassAndWarningWithoutDefaultsFrom(sloString)
 
-	return sliResult, indicatorName, sliQuery, sloDefinition, nil
+	return sliResult, indicatorName, sliQuery, sloDefinition
 }
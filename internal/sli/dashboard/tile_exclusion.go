@@ -0,0 +1,80 @@
+package dashboard
+
+import (
+	"math"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// excludedTilePrefix marks a tile as excluded if its name starts with it, e.g. -Response time;sli=svc_rt
+const excludedTilePrefix = "-"
+
+// excludedSectionDirective marks a markdown tile as the start of a section whose tiles are excluded
+const excludedSectionDirective = "kqg.exclude=true"
+
+// getExcludedTiles returns the indexes of the tiles that are excluded from SLI processing. A tile is excluded if
+//   - its name starts with "-" or contains the name-value pair exclude=true
+//   - it is in a section started by a HEADER tile excluded by its name or a MARKDOWN tile containing KQG.Exclude=true.
+//     A section contains the tiles below the tile starting it that horizontally overlap with it, up to the next HEADER or MARKDOWN tile
+func getExcludedTiles(tiles []dynatrace.Tile) map[int]bool {
+	excludedTiles := make(map[int]bool)
+	for i, tile := range tiles {
+		if isExcludedTile(tile) {
+			excludedTiles[i] = true
+		}
+	}
+
+	for i, tile := range tiles {
+		if !excludedTiles[i] || !isSectionTile(tile) {
+			continue
+		}
+
+		sectionEnd := getSectionEnd(tiles, tile)
+		for j, other := range tiles {
+			if other.Bounds.Top >= tile.Bounds.Top && other.Bounds.Top < sectionEnd && overlapHorizontally(tile.Bounds, other.Bounds) {
+				excludedTiles[j] = true
+			}
+		}
+	}
+
+	return excludedTiles
+}
+
+// isExcludedTile returns whether the tile is excluded by its name or, for markdown tiles, by the exclusion directive
+func isExcludedTile(tile dynatrace.Tile) bool {
+	if tile.TileType == "MARKDOWN" && strings.Contains(strings.ToLower(strings.ReplaceAll(tile.Markdown, " ", "")), excludedSectionDirective) {
+		return true
+	}
+
+	title := strings.TrimSpace(tile.Title())
+	if strings.HasPrefix(title, excludedTilePrefix) {
+		return true
+	}
+
+	for _, nameValue := range strings.Split(title, ";") {
+		if strings.ToLower(strings.ReplaceAll(nameValue, " ", "")) == "exclude=true" {
+			return true
+		}
+	}
+	return false
+}
+
+func isSectionTile(tile dynatrace.Tile) bool {
+	return tile.TileType == "HEADER" || tile.TileType == "MARKDOWN"
+}
+
+// getSectionEnd returns the top of the next section tile below the section tile that horizontally overlaps with it, i.e. the end of its section
+func getSectionEnd(tiles []dynatrace.Tile, sectionTile dynatrace.Tile) int {
+	sectionEnd := math.MaxInt32
+	for _, tile := range tiles {
+		if isSectionTile(tile) && tile.Bounds.Top > sectionTile.Bounds.Top && tile.Bounds.Top < sectionEnd && overlapHorizontally(sectionTile.Bounds, tile.Bounds) {
+			sectionEnd = tile.Bounds.Top
+		}
+	}
+	return sectionEnd
+}
+
+func overlapHorizontally(a dynatrace.Bounds, b dynatrace.Bounds) bool {
+	return a.Left < b.Left+b.Width && b.Left < a.Left+a.Width
+}
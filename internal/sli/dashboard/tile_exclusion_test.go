@@ -0,0 +1,63 @@
+package dashboard
+
+import (
+	"testing"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExcludedTiles(t *testing.T) {
+	tests := []struct {
+		name                  string
+		tiles                 []dynatrace.Tile
+		expectedExcludedTiles map[int]bool
+	}{
+		{
+			name: "no exclusions",
+			tiles: []dynatrace.Tile{
+				{Name: "Response time;sli=svc_rt", TileType: "DATA_EXPLORER"},
+				{Name: "Markdown", TileType: "MARKDOWN", Markdown: "KQG.Total.Pass=90%;KQG.Total.Warning=75%"},
+			},
+			expectedExcludedTiles: map[int]bool{},
+		},
+		{
+			name: "tiles excluded by name",
+			tiles: []dynatrace.Tile{
+				{Name: "-Response time;sli=svc_rt", TileType: "DATA_EXPLORER"},
+				{Name: "Failure rate;sli=svc_fr;exclude=true", TileType: "DATA_EXPLORER"},
+				{Name: "Throughput;sli=svc_tp;exclude=false", TileType: "DATA_EXPLORER"},
+				{Name: "Custom chart", TileType: "CUSTOM_CHARTING", FilterConfig: &dynatrace.FilterConfig{CustomName: "- Process memory;sli=process_memory"}},
+			},
+			expectedExcludedTiles: map[int]bool{0: true, 1: true, 3: true},
+		},
+		{
+			name: "section excluded by markdown directive",
+			tiles: []dynatrace.Tile{
+				{Name: "Markdown", TileType: "MARKDOWN", Markdown: "Informational charts, KQG.Exclude=true", Bounds: dynatrace.Bounds{Top: 0, Left: 0, Width: 600, Height: 38}},
+				{Name: "Response time;sli=svc_rt", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 38, Left: 0, Width: 304, Height: 152}},
+				{Name: "Failure rate;sli=svc_fr", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 38, Left: 304, Width: 304, Height: 152}},
+				{Name: "Beside the section;sli=svc_tp", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 38, Left: 608, Width: 304, Height: 152}},
+				{Name: "Quality gates", TileType: "HEADER", Bounds: dynatrace.Bounds{Top: 190, Left: 0, Width: 600, Height: 38}},
+				{Name: "Process memory;sli=process_memory", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 228, Left: 0, Width: 304, Height: 152}},
+			},
+			expectedExcludedTiles: map[int]bool{0: true, 1: true, 2: true},
+		},
+		{
+			name: "section excluded by header name",
+			tiles: []dynatrace.Tile{
+				{Name: "Quality gates", TileType: "HEADER", Bounds: dynatrace.Bounds{Top: 0, Left: 0, Width: 600, Height: 38}},
+				{Name: "Response time;sli=svc_rt", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 38, Left: 0, Width: 304, Height: 152}},
+				{Name: "-Informational", TileType: "HEADER", Bounds: dynatrace.Bounds{Top: 190, Left: 0, Width: 600, Height: 38}},
+				{Name: "Problems", TileType: "OPEN_PROBLEMS", Bounds: dynatrace.Bounds{Top: 228, Left: 0, Width: 152, Height: 152}},
+				{Name: "Failure rate;sli=svc_fr", TileType: "DATA_EXPLORER", Bounds: dynatrace.Bounds{Top: 380, Left: 0, Width: 304, Height: 152}},
+			},
+			expectedExcludedTiles: map[int]bool{2: true, 3: true, 4: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expectedExcludedTiles, getExcludedTiles(tt.tiles))
+		})
+	}
+}
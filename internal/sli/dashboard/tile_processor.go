@@ -0,0 +1,73 @@
+package dashboard
+
+import (
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// TileProcessor generates SLIs and SLOs from the tiles of a dashboard. Custom builds support further tile types, e.g. proprietary ones,
+// by registering a TileProcessor of package pkg/dashboard, which is adapted to this interface
+type TileProcessor interface {
+	// CanProcess returns whether the processor generates the SLIs of the tile
+	CanProcess(tile *dynatrace.Tile) bool
+
+	// Process queries the SLI values of the tile and generates their definitions and objectives
+	Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult
+
+	// ProcessDefinitions generates the SLI definitions and objectives of the tile without querying any values
+	ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult
+}
+
+// NewDefaultTileProcessors creates the processors of all tile types supported by default. If tileThresholds is set, the SLO criteria of
+// Data Explorer tiles without criteria in their names are derived from the thresholds of their queries
+func NewDefaultTileProcessors(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, startUnix time.Time, endUnix time.Time, tileThresholds bool) []TileProcessor {
+	return []TileProcessor{
+		NewSLOTileProcessing(client, startUnix, endUnix),
+		newOpenProblemsTileProcessing(client, startUnix, endUnix),
+		NewDataExplorerTileProcessing(client, eventData, customFilters, startUnix, endUnix, tileThresholds),
+		NewCustomChartingTileProcessing(client, eventData, customFilters, startUnix, endUnix),
+		NewUSQLTileProcessing(client, eventData, customFilters, startUnix, endUnix),
+	}
+}
+
+// openProblemsTileProcessing generates the SLIs of the open problems and, as the current logic does, the security problems of OPEN_PROBLEMS tiles
+type openProblemsTileProcessing struct {
+	problems         *ProblemTileProcessing
+	securityProblems *SecurityProblemTileProcessing
+}
+
+func newOpenProblemsTileProcessing(client dynatrace.ClientInterface, startUnix time.Time, endUnix time.Time) *openProblemsTileProcessing {
+	return &openProblemsTileProcessing{
+		problems:         NewProblemTileProcessing(client, startUnix, endUnix),
+		securityProblems: NewSecurityProblemTileProcessing(client, startUnix, endUnix),
+	}
+}
+
+func (p *openProblemsTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == "OPEN_PROBLEMS"
+}
+
+func (p *openProblemsTileProcessing) Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	return nonNilTileResults(
+		p.problems.Process(tile, dashboardFilter),
+		p.securityProblems.Process(tile, dashboardFilter))
+}
+
+func (p *openProblemsTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	return nonNilTileResults(
+		p.problems.ProcessDefinition(tile, dashboardFilter),
+		p.securityProblems.ProcessDefinition(tile, dashboardFilter))
+}
+
+func nonNilTileResults(results ...*TileResult) []*TileResult {
+	var nonNilResults []*TileResult
+	for _, result := range results {
+		if result != nil {
+			nonNilResults = append(nonNilResults, result)
+		}
+	}
+	return nonNilResults
+}
@@ -0,0 +1,122 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	pkgdashboard "github.com/keptn-contrib/dynatrace-service/pkg/dashboard"
+	pkgdynatrace "github.com/keptn-contrib/dynatrace-service/pkg/dynatrace"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	"github.com/stretchr/testify/assert"
+)
+
+// proprietaryTileProcessing generates an SLI with a fixed query for tiles of its type
+type proprietaryTileProcessing struct {
+	tileType string
+}
+
+func (p *proprietaryTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == p.tileType
+}
+
+func (p *proprietaryTileProcessing) Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult {
+	return p.ProcessDefinitions(tile, dashboardFilter)
+}
+
+func (p *proprietaryTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, _ *dynatrace.DashboardFilter) []*TileResult {
+	return []*TileResult{NewTileResult(tile.Name, "metricSelector=proprietary:"+tile.Name, &keptncommon.SLO{SLI: tile.Name, Weight: 1}, nil)}
+}
+
+// Tests that added tile processors are used for tiles of further types and take precedence over the default tile processors
+func TestProcessingWithTileProcessors(t *testing.T) {
+	dashboard := &dynatrace.Dashboard{
+		Tiles: []dynatrace.Tile{
+			{Name: "proprietary_sli", TileType: "PROPRIETARY"},
+			{Name: "Header", TileType: "HEADER"},
+			{Name: "data_explorer_sli", TileType: "DATA_EXPLORER"},
+		},
+	}
+
+	// the default processor of the data explorer tile would need a Dynatrace client
+	now := time.Now()
+	result := NewProcessing(nil, nil, nil, now, now, false).
+		WithTileProcessors(&proprietaryTileProcessing{tileType: "PROPRIETARY"}, &proprietaryTileProcessing{tileType: "DATA_EXPLORER"}).
+		ProcessDefinitions(dashboard)
+
+	assert.Equal(t,
+		map[string]string{
+			"proprietary_sli":   "metricSelector=proprietary:proprietary_sli",
+			"data_explorer_sli": "metricSelector=proprietary:data_explorer_sli",
+		},
+		result.SLI().Indicators)
+	if assert.Len(t, result.SLO().Objectives, 2) {
+		assert.Equal(t, "proprietary_sli", result.SLO().Objectives[0].SLI)
+		assert.Equal(t, "data_explorer_sli", result.SLO().Objectives[1].SLI)
+	}
+	assert.Empty(t, result.SLIResults())
+}
+
+// exportedTileProcessing generates an SLI per tile of its type, using the tenant and event it was created for in the query
+type exportedTileProcessing struct {
+	tileType     string
+	tenant       string
+	eventContext pkgdashboard.EventContext
+}
+
+func (p *exportedTileProcessing) CanProcess(tile *pkgdynatrace.Tile) bool {
+	return tile.TileType == p.tileType
+}
+
+func (p *exportedTileProcessing) Process(tile *pkgdynatrace.Tile, dashboardFilter *pkgdynatrace.DashboardFilter) []*pkgdashboard.TileResult {
+	return p.ProcessDefinitions(tile, dashboardFilter)
+}
+
+func (p *exportedTileProcessing) ProcessDefinitions(tile *pkgdynatrace.Tile, dashboardFilter *pkgdynatrace.DashboardFilter) []*pkgdashboard.TileResult {
+	return []*pkgdashboard.TileResult{
+		{
+			SLIName:   tile.Name,
+			SLIQuery:  "metricSelector=proprietary:" + p.eventContext.Project + ":" + p.eventContext.Service + "&timeframe=" + dashboardFilter.Timeframe,
+			Objective: &keptncommon.SLO{SLI: tile.Name, Weight: 1},
+		},
+	}
+}
+
+// Tests that the tile processors of package pkg/dashboard are created using the Dynatrace client and event context of the query and are used for tiles of their type
+func TestQueryingWithExportedTileProcessors(t *testing.T) {
+	const dashboardID = "12345678-1111-4444-8888-123456789012"
+
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/config/v1/dashboards/"+dashboardID, []byte(`{
+		"id": "`+dashboardID+`",
+		"dashboardMetadata": {"name": "KQG", "dashboardFilter": {"timeframe": "-2h"}},
+		"tiles": [
+			{"name": "proprietary_sli", "tileType": "PROPRIETARY"},
+			{"name": "Header", "tileType": "HEADER"}
+		]
+	}`))
+
+	querying, url, teardown := createQueryingWithHandler(createKeptnEvent("sockshop", "dev", "carts"), handler)
+	defer teardown()
+
+	var tileProcessor *exportedTileProcessing
+	querying.WithTileProcessors(func(client pkgdynatrace.ClientInterface, eventContext pkgdashboard.EventContext, _ time.Time, _ time.Time) pkgdashboard.TileProcessor {
+		tileProcessor = &exportedTileProcessing{tileType: "PROPRIETARY", tenant: client.Credentials().Tenant, eventContext: eventContext}
+		return tileProcessor
+	})
+
+	result, err := querying.GetSLIDefinitions(dashboardID)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, tileProcessor) {
+		assert.Equal(t, url, tileProcessor.tenant)
+		assert.Equal(t, "dev", tileProcessor.eventContext.Stage)
+	}
+	if assert.NotNil(t, result) {
+		assert.Equal(t, map[string]string{"proprietary_sli": "metricSelector=proprietary:sockshop:carts&timeframe=-2h"}, result.SLI().Indicators)
+		if assert.Len(t, result.SLO().Objectives, 1) {
+			assert.Equal(t, "proprietary_sli", result.SLO().Objectives[0].SLI)
+		}
+	}
+}
@@ -30,7 +30,12 @@ func NewUSQLTileProcessing(client dynatrace.ClientInterface, eventData adapter.E
 	}
 }
 
-func (p *USQLTileProcessing) Process(tile *dynatrace.Tile) []*TileResult {
+// CanProcess returns whether the tile is a USQL tile
+func (p *USQLTileProcessing) CanProcess(tile *dynatrace.Tile) bool {
+	return tile.TileType == "DTAQL"
+}
+
+func (p *USQLTileProcessing) Process(tile *dynatrace.Tile, _ *dynatrace.DashboardFilter) []*TileResult {
 	// for Dynatrace Query Language we currently support the following
 	// SINGLE_VALUE: we just take the one value that comes back
 	// PIE_CHART, COLUMN_CHART: we assume the first column is the dimension and the second column is the value column
@@ -115,3 +120,38 @@ func (p *USQLTileProcessing) Process(tile *dynatrace.Tile) []*TileResult {
 
 	return tileResults
 }
+
+// ProcessDefinitions generates the SLI & SLO definition of the tile without running the USQL query.
+// Only SINGLE_VALUE tiles are supported, as the other types result in an indicator per dimension value returned by the query.
+func (p *USQLTileProcessing) ProcessDefinitions(tile *dynatrace.Tile, _ *dynatrace.DashboardFilter) []*TileResult {
+	tileTitle := tile.Title()
+
+	sloDefinition := common.ParsePassAndWarningWithoutDefaultsFrom(tileTitle)
+	if sloDefinition.SLI == "" {
+		log.WithField("tileTitle", tileTitle).Debug("Tile not included as name doesnt include sli=SLINAME")
+		return nil
+	}
+
+	if tile.Type != "SINGLE_VALUE" {
+		log.WithFields(
+			log.Fields{
+				"tileTitle": tileTitle,
+				"tileType":  tile.Type,
+			}).Warn("USQL tile is not a single value, SLI cannot be generated without querying the dimension values")
+		return nil
+	}
+
+	return []*TileResult{
+		{
+			objective: &keptncommon.SLO{
+				SLI:     sloDefinition.SLI,
+				Weight:  sloDefinition.Weight,
+				KeySLI:  sloDefinition.KeySLI,
+				Pass:    sloDefinition.Pass,
+				Warning: sloDefinition.Warning,
+			},
+			sliName:  sloDefinition.SLI,
+			sliQuery: fmt.Sprintf("USQL;%s;;%s", tile.Type, tile.Query),
+		},
+	}
+}
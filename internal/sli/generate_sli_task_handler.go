@@ -0,0 +1,74 @@
+package sli
+
+import (
+	"fmt"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/sli/dashboard"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// GenerateSLITaskHandler converts a dashboard into sli.yaml and slo.yaml for a dynatrace-generate-sli.triggered event without querying any SLI values
+type GenerateSLITaskHandler struct {
+	event          GenerateSLITriggeredAdapterInterface
+	dtClient       dynatrace.ClientInterface
+	resourceClient keptn.ResourceClientInterface
+	dashboard      string
+	cfg            *env.Config
+}
+
+// NewGenerateSLITaskHandler creates a new GenerateSLITaskHandler. dashboard is the dashboard property of the dynatrace.conf.yaml, which is used if the event does not specify one
+func NewGenerateSLITaskHandler(event GenerateSLITriggeredAdapterInterface, dtClient dynatrace.ClientInterface, resourceClient keptn.ResourceClientInterface, dashboard string, cfg *env.Config) *GenerateSLITaskHandler {
+	return &GenerateSLITaskHandler{
+		event:          event,
+		dtClient:       dtClient,
+		resourceClient: resourceClient,
+		dashboard:      dashboard,
+		cfg:            cfg,
+	}
+}
+
+// HandleTask uploads the SLI and SLO definitions of the dashboard, replacing existing ones, and returns the factory for the finished event
+func (h *GenerateSLITaskHandler) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	dashboardID := h.event.GetDashboard()
+	if dashboardID == "" {
+		dashboardID = h.dashboard
+	}
+
+	result, err := dashboard.NewQuerying(h.event, nil, h.dtClient, h.resourceClient).WithTileThresholds(h.cfg.DashboardTileThresholdsEnabled).GetSLIDefinitions(dashboardID)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert dashboard: %w", err)
+	}
+
+	if len(result.SLI().Indicators) == 0 {
+		return nil, common.NewUserConfigurationError(fmt.Errorf("dashboard %s does not contain any tiles that can be converted to SLIs", result.Dashboard().ID))
+	}
+
+	err = h.resourceClient.UploadDashboardSLIAndSLOs(h.event.GetProject(), h.event.GetStage(), h.event.GetService(), nil, result.SLI(), result.SLO())
+	if err != nil {
+		return nil, fmt.Errorf("could not upload sli.yaml and slo.yaml: %w", err)
+	}
+
+	log.WithFields(
+		log.Fields{
+			"dashboard": result.Dashboard().ID,
+			"slis":      len(result.SLI().Indicators),
+		}).Info("Generated SLIs and SLOs from dashboard")
+
+	finishedEvent := keptnv2.EventData{
+		Project: h.event.GetProject(),
+		Stage:   h.event.GetStage(),
+		Service: h.event.GetService(),
+		Labels:  h.event.GetLabels(),
+		Status:  keptnv2.StatusSucceeded,
+		Result:  keptnv2.ResultPass,
+		Message: fmt.Sprintf("Generated %d SLIs from dashboard %s and uploaded them to dynatrace/sli.yaml and slo.yaml", len(result.SLI().Indicators), result.Dashboard().ID),
+	}
+
+	return adapter.NewCloudEventFactory(h.event, keptnv2.GetFinishedEventType(GenerateSLITaskName), finishedEvent), nil
+}
@@ -0,0 +1,124 @@
+package sli
+
+import (
+	"errors"
+	"testing"
+
+	keptnapi "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+const testGenerateSLIDashboardID = "12345678-1111-4444-8888-123456789012"
+
+const testGenerateSLIDashboard = `{
+  "id": "12345678-1111-4444-8888-123456789012",
+  "dashboardMetadata": {"name": "KQG;project=sockshop;stage=staging;service=carts", "owner": "keptn"},
+  "tiles": [
+    {
+      "name": "User sessions",
+      "tileType": "DTAQL",
+      "customName": "User sessions;sli=user_sessions;pass=>100",
+      "query": "SELECT count(*) FROM usersession",
+      "type": "SINGLE_VALUE"
+    }
+  ]
+}`
+
+type generateSLIEventData struct {
+	test.EventData
+	dashboard string
+}
+
+func (e *generateSLIEventData) GetEventID() string {
+	return "some-event-id"
+}
+
+func (e *generateSLIEventData) GetDashboard() string {
+	return e.dashboard
+}
+
+// uploadingResourceClientMock records the SLIs and SLOs uploaded together with a dashboard
+type uploadingResourceClientMock struct {
+	resourceClientMock
+	uploadErr error
+
+	uploadedSLI  *dynatrace.SLI
+	uploadedSLOs *keptnapi.ServiceLevelObjectives
+}
+
+func (m *uploadingResourceClientMock) UploadDashboardSLIAndSLOs(project string, stage string, service string, dashboard *dynatrace.Dashboard, sli *dynatrace.SLI, slos *keptnapi.ServiceLevelObjectives) error {
+	m.uploadedSLI = sli
+	m.uploadedSLOs = slos
+	return m.uploadErr
+}
+
+func createGenerateSLITaskHandler(t *testing.T, dashboardPayload string, eventDashboard string, resourceClient *uploadingResourceClientMock) (*GenerateSLITaskHandler, func()) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/config/v1/dashboards/"+testGenerateSLIDashboardID, []byte(dashboardPayload))
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	dtClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: "http://my-tenant.dynatrace.com", ApiToken: "abc123"}, httpClient)
+
+	event := &generateSLIEventData{
+		EventData: test.EventData{Project: "sockshop", Stage: "staging", Service: "carts"},
+		dashboard: eventDashboard,
+	}
+	return NewGenerateSLITaskHandler(event, dtClient, resourceClient, testGenerateSLIDashboardID, env.ReadConfig()), teardown
+}
+
+func TestGenerateSLITaskHandler_HandleTask(t *testing.T) {
+	resourceClient := &uploadingResourceClientMock{}
+	handler, teardown := createGenerateSLITaskHandler(t, testGenerateSLIDashboard, "", resourceClient)
+	defer teardown()
+
+	factory, err := handler.HandleTask()
+	assert.NoError(t, err)
+	if !assert.NotNil(t, factory) {
+		return
+	}
+
+	if assert.NotNil(t, resourceClient.uploadedSLI) {
+		assert.Equal(t, map[string]string{"user_sessions": "USQL;SINGLE_VALUE;;SELECT count(*) FROM usersession"}, resourceClient.uploadedSLI.Indicators)
+	}
+	if assert.NotNil(t, resourceClient.uploadedSLOs) && assert.Len(t, resourceClient.uploadedSLOs.Objectives, 1) {
+		assert.Equal(t, "user_sessions", resourceClient.uploadedSLOs.Objectives[0].SLI)
+	}
+
+	event, err := factory.CreateCloudEvent()
+	assert.NoError(t, err)
+	assert.Equal(t, keptnv2.GetFinishedEventType(GenerateSLITaskName), event.Type())
+
+	data := keptnv2.EventData{}
+	assert.NoError(t, event.DataAs(&data))
+	assert.Equal(t, keptnv2.StatusSucceeded, data.Status)
+	assert.Equal(t, "Generated 1 SLIs from dashboard "+testGenerateSLIDashboardID+" and uploaded them to dynatrace/sli.yaml and slo.yaml", data.Message)
+}
+
+func TestGenerateSLITaskHandler_HandleTaskWithoutSLITiles(t *testing.T) {
+	resourceClient := &uploadingResourceClientMock{}
+	handler, teardown := createGenerateSLITaskHandler(t, `{"id": "`+testGenerateSLIDashboardID+`", "dashboardMetadata": {"name": "KQG"}, "tiles": []}`, "", resourceClient)
+	defer teardown()
+
+	factory, err := handler.HandleTask()
+	assert.Nil(t, factory)
+	assert.Error(t, err)
+	assert.Equal(t, common.UserConfigurationErrorType, common.GetErrorType(err))
+	assert.Nil(t, resourceClient.uploadedSLI)
+}
+
+func TestGenerateSLITaskHandler_HandleTaskUploadFails(t *testing.T) {
+	resourceClient := &uploadingResourceClientMock{uploadErr: errors.New("configuration-service unavailable")}
+	handler, teardown := createGenerateSLITaskHandler(t, testGenerateSLIDashboard, testGenerateSLIDashboardID, resourceClient)
+	defer teardown()
+
+	factory, err := handler.HandleTask()
+	assert.Nil(t, factory)
+	assert.EqualError(t, err, "could not upload sli.yaml and slo.yaml: configuration-service unavailable")
+}
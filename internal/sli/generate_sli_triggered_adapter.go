@@ -0,0 +1,113 @@
+package sli
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// GenerateSLITaskName is the name of the task that converts a dashboard into sli.yaml and slo.yaml, i.e. it is triggered by events of type sh.keptn.event.dynatrace-generate-sli.triggered
+const GenerateSLITaskName = "dynatrace-generate-sli"
+
+// GenerateSLITriggeredEventData is the data of a sh.keptn.event.dynatrace-generate-sli.triggered event
+type GenerateSLITriggeredEventData struct {
+	keptnv2.EventData
+
+	// Dashboard is the ID of the dashboard to convert or query to search for the dashboard matching project, stage and service.
+	// If it is empty, the dashboard property of the dynatrace.conf.yaml is used.
+	Dashboard string `json:"dashboard,omitempty"`
+}
+
+type GenerateSLITriggeredAdapterInterface interface {
+	adapter.EventContentAdapter
+	adapter.TriggeredCloudEventContentAdapter
+
+	GetDashboard() string
+}
+
+// GenerateSLITriggeredAdapter is a content adaptor for events of type sh.keptn.event.dynatrace-generate-sli.triggered
+type GenerateSLITriggeredAdapter struct {
+	event      GenerateSLITriggeredEventData
+	cloudEvent adapter.CloudEventAdapter
+}
+
+// IsGenerateSLITriggeredEventType returns whether the event type is the one of the generate SLI task
+func IsGenerateSLITriggeredEventType(eventType string) bool {
+	return eventType == keptnv2.GetTriggeredEventType(GenerateSLITaskName)
+}
+
+// NewGenerateSLITriggeredAdapterFromEvent creates a new GenerateSLITriggeredAdapter from a cloudevents Event
+func NewGenerateSLITriggeredAdapterFromEvent(e cloudevents.Event) (*GenerateSLITriggeredAdapter, error) {
+	ceAdapter := adapter.NewCloudEventAdapter(e)
+
+	eventData := &GenerateSLITriggeredEventData{}
+	err := ceAdapter.PayloadAs(eventData)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenerateSLITriggeredAdapter{
+		event:      *eventData,
+		cloudEvent: ceAdapter,
+	}, nil
+}
+
+// GetShKeptnContext returns the shkeptncontext
+func (a GenerateSLITriggeredAdapter) GetShKeptnContext() string {
+	return a.cloudEvent.ShKeptnContext()
+}
+
+// GetEventID returns the ID of the triggered event
+func (a GenerateSLITriggeredAdapter) GetEventID() string {
+	return a.cloudEvent.ID()
+}
+
+// GetSource returns the source specified in the CloudEvent context
+func (a GenerateSLITriggeredAdapter) GetSource() string {
+	return a.cloudEvent.Source()
+}
+
+// GetEvent returns the event type
+func (a GenerateSLITriggeredAdapter) GetEvent() string {
+	return a.cloudEvent.Type()
+}
+
+// GetProject returns the project
+func (a GenerateSLITriggeredAdapter) GetProject() string {
+	return a.event.Project
+}
+
+// GetStage returns the stage
+func (a GenerateSLITriggeredAdapter) GetStage() string {
+	return a.event.Stage
+}
+
+// GetService returns the service
+func (a GenerateSLITriggeredAdapter) GetService() string {
+	return a.event.Service
+}
+
+// GetDeployment returns the name of the deployment
+func (a GenerateSLITriggeredAdapter) GetDeployment() string {
+	return ""
+}
+
+// GetTestStrategy returns the used test strategy
+func (a GenerateSLITriggeredAdapter) GetTestStrategy() string {
+	return ""
+}
+
+// GetDeploymentStrategy returns the used deployment strategy
+func (a GenerateSLITriggeredAdapter) GetDeploymentStrategy() string {
+	return ""
+}
+
+// GetLabels returns a map of labels
+func (a GenerateSLITriggeredAdapter) GetLabels() map[string]string {
+	return a.event.Labels
+}
+
+// GetDashboard returns the dashboard to convert, which may be empty
+func (a GenerateSLITriggeredAdapter) GetDashboard() string {
+	return a.event.Dashboard
+}
@@ -3,42 +3,31 @@ package sli
 import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"strings"
 )
 
-type GetSliStartedEventFactory struct {
-	event GetSLITriggeredAdapterInterface
-}
-
-func NewGetSliStartedEventFactory(event GetSLITriggeredAdapterInterface) *GetSliStartedEventFactory {
-	return &GetSliStartedEventFactory{
-		event: event,
-	}
-}
-
-func (f *GetSliStartedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
-	getSLIStartedEvent := keptnv2.GetSLIStartedEventData{
-		EventData: keptnv2.EventData{
-			Project: f.event.GetProject(),
-			Stage:   f.event.GetStage(),
-			Service: f.event.GetService(),
-			Labels:  f.event.GetLabels(),
-			Status:  keptnv2.StatusSucceeded,
-			Result:  keptnv2.ResultPass,
-		},
-	}
-
-	return adapter.NewCloudEventFactory(f.event, keptnv2.GetStartedEventType(keptnv2.GetSLITaskName), getSLIStartedEvent).CreateCloudEvent()
-
-}
-
 type GetSliFinishedEventFactory struct {
 	event           GetSLITriggeredAdapterInterface
 	indicatorValues []*keptnv2.SLIResult
+	sliRequests     []*SLIRequests
+	warnings        []string
 	err             error
 }
 
+// SLIRequests are the Dynatrace API requests that were sent to retrieve the value of an SLI
+type SLIRequests struct {
+	Metric   string   `json:"metric"`
+	Requests []string `json:"requests"`
+}
+
+// getSLIFinishedEventData extends the get-sli.finished event data by the Dynatrace API requests sent for each SLI
+type getSLIFinishedEventData struct {
+	keptnv2.GetSLIFinishedEventData
+	SLIRequests []*SLIRequests `json:"sliRequests,omitempty"`
+}
+
 func NewGetSLIFinishedEventFactory(event GetSLITriggeredAdapterInterface, indicatorValues []*keptnv2.SLIResult, err error) *GetSliFinishedEventFactory {
 	return &GetSliFinishedEventFactory{
 		event:           event,
@@ -47,12 +36,25 @@ func NewGetSLIFinishedEventFactory(event GetSLITriggeredAdapterInterface, indica
 	}
 }
 
+// WithSLIRequests adds the Dynatrace API requests sent for each SLI to the event
+func (f *GetSliFinishedEventFactory) WithSLIRequests(sliRequests []*SLIRequests) *GetSliFinishedEventFactory {
+	f.sliRequests = sliRequests
+	return f
+}
+
+// WithWarnings adds warnings to the message of the event, e.g. that the dashboard changed since the previous evaluation
+func (f *GetSliFinishedEventFactory) WithWarnings(warnings []string) *GetSliFinishedEventFactory {
+	f.warnings = warnings
+	return f
+}
+
 func (f *GetSliFinishedEventFactory) CreateCloudEvent() (*cloudevents.Event, error) {
+	status := keptnv2.StatusSucceeded
 	result := keptnv2.ResultPass
 	message := ""
 	if f.err != nil {
-		result = keptnv2.ResultFailed
-		message = f.err.Error()
+		status, result = common.GetFinishedEventStatusAndResult(f.err)
+		message = common.GetFinishedEventMessage(f.err)
 	}
 
 	// get error messages if only some SLIs failed and there was no error
@@ -61,22 +63,26 @@ func (f *GetSliFinishedEventFactory) CreateCloudEvent() (*cloudevents.Event, err
 		result = keptnv2.ResultFailed
 		message = strings.Join(sliErrorMessages, "; ")
 	}
+	message = appendWarnings(message, f.warnings)
 
-	getSLIFinishedEvent := keptnv2.GetSLIFinishedEventData{
-		EventData: keptnv2.EventData{
-			Project: f.event.GetProject(),
-			Stage:   f.event.GetStage(),
-			Service: f.event.GetService(),
-			Labels:  f.event.GetLabels(),
-			Status:  keptnv2.StatusSucceeded,
-			Result:  result,
-			Message: message,
-		},
-		GetSLI: keptnv2.GetSLIFinished{
-			IndicatorValues: f.indicatorValues,
-			Start:           f.event.GetSLIStart(),
-			End:             f.event.GetSLIEnd(),
+	getSLIFinishedEvent := getSLIFinishedEventData{
+		GetSLIFinishedEventData: keptnv2.GetSLIFinishedEventData{
+			EventData: keptnv2.EventData{
+				Project: f.event.GetProject(),
+				Stage:   f.event.GetStage(),
+				Service: f.event.GetService(),
+				Labels:  f.event.GetLabels(),
+				Status:  status,
+				Result:  result,
+				Message: message,
+			},
+			GetSLI: keptnv2.GetSLIFinished{
+				IndicatorValues: f.indicatorValues,
+				Start:           f.event.GetSLIStart(),
+				End:             f.event.GetSLIEnd(),
+			},
 		},
+		SLIRequests: f.sliRequests,
 	}
 
 	return adapter.NewCloudEventFactory(f.event, keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), getSLIFinishedEvent).CreateCloudEvent()
@@ -3,6 +3,7 @@ package sli
 import (
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 )
 
@@ -15,17 +16,19 @@ type GetSLITriggeredAdapterInterface interface {
 	GetSLIEnd() string
 	GetIndicators() []string
 	GetCustomSLIFilters() []*keptnv2.SLIFilter
+	GetGitCommitID() string
 	AddLabel(name string, value string)
 }
 
 // GetSLITriggeredAdapter is a content adaptor for events of type sh.keptn.event.action.started
 type GetSLITriggeredAdapter struct {
-	event      keptnv2.GetSLITriggeredEventData
-	cloudEvent adapter.CloudEventAdapter
+	event           keptnv2.GetSLITriggeredEventData
+	cloudEvent      adapter.CloudEventAdapter
+	sliProviderName string
 }
 
 // NewGetSLITriggeredAdapterFromEvent creates a new GetSLITriggeredAdapter from a cloudevents Event
-func NewGetSLITriggeredAdapterFromEvent(e cloudevents.Event) (*GetSLITriggeredAdapter, error) {
+func NewGetSLITriggeredAdapterFromEvent(e cloudevents.Event, cfg *env.Config) (*GetSLITriggeredAdapter, error) {
 	ceAdapter := adapter.NewCloudEventAdapter(e)
 
 	stData := &keptnv2.GetSLITriggeredEventData{}
@@ -35,8 +38,9 @@ func NewGetSLITriggeredAdapterFromEvent(e cloudevents.Event) (*GetSLITriggeredAd
 	}
 
 	return &GetSLITriggeredAdapter{
-		event:      *stData,
-		cloudEvent: ceAdapter,
+		event:           *stData,
+		cloudEvent:      ceAdapter,
+		sliProviderName: cfg.SLIProviderName,
 	}, nil
 }
 
@@ -90,8 +94,14 @@ func (a GetSLITriggeredAdapter) GetLabels() map[string]string {
 	return a.event.Labels
 }
 
+// IsNotForDynatrace returns whether the SLIs are to be retrieved by another SLI provider than the one configured by env.Config.SLIProviderName
 func (a GetSLITriggeredAdapter) IsNotForDynatrace() bool {
-	return a.event.GetSLI.SLIProvider != "dynatrace"
+	return a.event.GetSLI.SLIProvider != a.sliProviderName
+}
+
+// GetSLIProvider returns the SLI provider the SLIs are to be retrieved by
+func (a GetSLITriggeredAdapter) GetSLIProvider() string {
+	return a.event.GetSLI.SLIProvider
 }
 
 func (a GetSLITriggeredAdapter) GetSLIStart() string {
@@ -114,6 +124,11 @@ func (a GetSLITriggeredAdapter) GetEventID() string {
 	return a.cloudEvent.ID()
 }
 
+// GetGitCommitID returns the ID of the git commit of the configuration repository at the start of the sequence, or an empty string if there is none
+func (a GetSLITriggeredAdapter) GetGitCommitID() string {
+	return a.cloudEvent.GitCommitID()
+}
+
 func (a *GetSLITriggeredAdapter) AddLabel(name string, value string) {
 	if a.event.Labels == nil {
 		a.event.Labels = make(map[string]string)
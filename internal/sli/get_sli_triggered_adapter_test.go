@@ -0,0 +1,78 @@
+package sli
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func createGetSLITriggeredCloudEvent(t *testing.T, sliProvider string) cloudevents.Event {
+	ce := cloudevents.NewEvent()
+	ce.SetID("a3e5f16d-8888-4720-82c7-6995062905c1")
+	ce.SetType(keptnv2.GetTriggeredEventType(keptnv2.GetSLITaskName))
+	ce.SetSource("lighthouse-service")
+	ce.SetExtension("shkeptncontext", "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9")
+	err := ce.SetData(cloudevents.ApplicationJSON, keptnv2.GetSLITriggeredEventData{
+		EventData: keptnv2.EventData{
+			Project: "sockshop",
+			Stage:   "staging",
+			Service: "carts",
+		},
+		GetSLI: keptnv2.GetSLI{
+			SLIProvider: sliProvider,
+			Indicators:  []string{"response_time_p95"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not set cloud event data: %v", err)
+	}
+
+	return ce
+}
+
+func TestGetSLITriggeredAdapter_IsNotForDynatrace(t *testing.T) {
+	tests := []struct {
+		name                string
+		sliProviderName     string
+		sliProvider         string
+		wantNotForDynatrace bool
+	}{
+		{
+			name:        "dynatrace",
+			sliProvider: "dynatrace",
+		},
+		{
+			name:                "other SLI provider",
+			sliProvider:         "prometheus",
+			wantNotForDynatrace: true,
+		},
+		{
+			name:            "configured SLI provider",
+			sliProviderName: "dynatrace-saas",
+			sliProvider:     "dynatrace-saas",
+		},
+		{
+			name:                "dynatrace if another SLI provider is configured",
+			sliProviderName:     "dynatrace-saas",
+			sliProvider:         "dynatrace",
+			wantNotForDynatrace: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := env.ReadConfig()
+			if tt.sliProviderName != "" {
+				cfg.SLIProviderName = tt.sliProviderName
+			}
+
+			adapter, err := NewGetSLITriggeredAdapterFromEvent(createGetSLITriggeredCloudEvent(t, tt.sliProvider), cfg)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantNotForDynatrace, adapter.IsNotForDynatrace())
+				assert.Equal(t, tt.sliProvider, adapter.GetSLIProvider())
+			}
+		})
+	}
+}
@@ -3,13 +3,18 @@ package sli
 import (
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"github.com/keptn-contrib/dynatrace-service/internal/sli/dashboard"
 	"github.com/keptn-contrib/dynatrace-service/internal/sli/query"
-	"strings"
-	"time"
 
 	keptncommon "github.com/keptn/go-utils/pkg/lib"
 	log "github.com/sirupsen/logrus"
@@ -24,6 +29,10 @@ import (
 
 const ProblemOpenSLI = "problem_open"
 
+const dashboardLinkLabel = "Dashboard Link"
+const dashboardVersionLabel = "Dashboard Version"
+const previousDashboardVersionLabel = "Previous Dashboard Version"
+
 type GetSLIEventHandler struct {
 	event          GetSLITriggeredAdapterInterface
 	dtClient       dynatrace.ClientInterface
@@ -32,9 +41,27 @@ type GetSLIEventHandler struct {
 
 	secretName string
 	dashboard  string
+
+	// strictKeySLIs makes the get-sli task error if a key SLI cannot be retrieved
+	strictKeySLIs bool
+
+	// additionalTenants are further Dynatrace environments SLIs defined in the sli.yaml are queried from and aggregated with sliAggregation
+	additionalTenants []Tenant
+	sliAggregation    string
+
+	// cache keeps SLI results for repeated evaluations of the same timeframe, no results are cached if it is nil
+	cache *SLIResultCache
+
+	// dataCoverage enables checking how much of the evaluation timeframe is covered by the data points of metrics SLIs defined in the sli.yaml
+	dataCoverage *config.SLIDataCoverage
+
+	// sliMerge enables merging the SLIs of the dashboard with those defined in the sli.yaml and defines which of them takes precedence, see config.SupportedSLIMerges
+	sliMerge string
+
+	cfg *env.Config
 }
 
-func NewGetSLITriggeredHandler(event GetSLITriggeredAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, secretName string, dashboard string) GetSLIEventHandler {
+func NewGetSLITriggeredHandler(event GetSLITriggeredAdapterInterface, dtClient dynatrace.ClientInterface, kClient keptn.ClientInterface, resourceClient keptn.ResourceClientInterface, secretName string, dashboard string, strictKeySLIs bool, cfg *env.Config) GetSLIEventHandler {
 	return GetSLIEventHandler{
 		event:          event,
 		dtClient:       dtClient,
@@ -42,18 +69,83 @@ func NewGetSLITriggeredHandler(event GetSLITriggeredAdapterInterface, dtClient d
 		resourceClient: resourceClient,
 		secretName:     secretName,
 		dashboard:      dashboard,
+		strictKeySLIs:  strictKeySLIs,
+		cfg:            cfg,
 	}
 }
 
-func (eh GetSLIEventHandler) HandleEvent() error {
-	// prepare event
+// WithAdditionalTenants returns a copy of the handler that also queries SLIs defined in the sli.yaml from the additional tenants and combines
+// the values of all tenants using the aggregation
+func (eh GetSLIEventHandler) WithAdditionalTenants(tenants []Tenant, aggregation string) GetSLIEventHandler {
+	eh.additionalTenants = tenants
+	eh.sliAggregation = aggregation
+	return eh
+}
 
-	// do not continue if SLIProvider is not dynatrace
-	if eh.event.IsNotForDynatrace() {
-		return nil
+// WithCache returns a copy of the handler that serves SLI results from the cache if the same SLIs of the same timeframe were retrieved before
+func (eh GetSLIEventHandler) WithCache(cache *SLIResultCache) GetSLIEventHandler {
+	eh.cache = cache
+	return eh
+}
+
+// WithDataCoverageCheck returns a copy of the handler that warns about metrics SLIs whose data points do not cover enough of the evaluation timeframe
+func (eh GetSLIEventHandler) WithDataCoverageCheck(dataCoverage *config.SLIDataCoverage) GetSLIEventHandler {
+	eh.dataCoverage = dataCoverage
+	return eh
+}
+
+// WithSLIMerge returns a copy of the handler that merges the SLIs of the dashboard with those defined in the sli.yaml, with the SLIs of the source
+// defined by precedence, i.e. dashboard or file, taking precedence if both define an SLI. No SLIs are merged if precedence is empty
+func (eh GetSLIEventHandler) WithSLIMerge(precedence string) GetSLIEventHandler {
+	eh.sliMerge = precedence
+	return eh
+}
+
+// HandleTask retrieves the SLIs and returns the factory for the get-sli.finished event
+func (eh GetSLIEventHandler) HandleTask() (adapter.CloudEventFactoryInterface, error) {
+	sliResults, sliRequests, err := eh.retrieveMetrics()
+
+	// if an error was set - the indicators will be set to failed and error message is set to each
+	sliResults = resetIndicatorsInCaseOfError(err, eh.event, sliResults)
+
+	// in strict mode, a key SLI that could not be retrieved errors the task rather than leaving the decision to the lighthouse-service
+	if err == nil && eh.strictKeySLIs {
+		err = eh.checkKeySLIsRetrieved(sliResults)
+	}
+
+	return NewGetSLIFinishedEventFactory(eh.event, sliResults, err).
+		WithSLIRequests(sliRequests).
+		WithWarnings(getDashboardChangedWarnings(eh.event.GetLabels())), nil
+}
+
+// checkKeySLIsRetrieved returns an error if one of the SLIs marked as key SLI in the slo.yaml could not be retrieved
+func (eh GetSLIEventHandler) checkKeySLIsRetrieved(sliResults []*keptnv2.SLIResult) error {
+	slos, err := eh.resourceClient.GetSLOs(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService())
+	if err != nil {
+		var rnfErr *keptn.ResourceNotFoundError
+		if errors.As(err, &rnfErr) {
+			return nil
+		}
+		return fmt.Errorf("could not retrieve SLOs to check key SLIs: %w", err)
+	}
+
+	keySLIs := make(map[string]bool)
+	for _, objective := range slos.Objectives {
+		if objective.KeySLI {
+			keySLIs[objective.SLI] = true
+		}
+	}
+
+	var failedKeySLIs []string
+	for _, sliResult := range sliResults {
+		if !sliResult.Success && keySLIs[sliResult.Metric] {
+			failedKeySLIs = append(failedKeySLIs, fmt.Sprintf("%s (%s)", sliResult.Metric, sliResult.Message))
+		}
 	}
 
-	go eh.retrieveMetrics()
+	if len(failedKeySLIs) > 0 {
+		return fmt.Errorf("key SLIs could not be retrieved: %s", strings.Join(failedKeySLIs, ", "))
+	}
 
 	return nil
 }
@@ -64,7 +156,7 @@ func (eh GetSLIEventHandler) HandleEvent() error {
  *              to circumvent this issue I am changing the check to also allow a time difference of up to 2 minutes (120 seconds). This shouldnt be a problem as our SLI Service retries the DYnatrace API anyway
  * Here is the issue: https://github.com/keptn-contrib/dynatrace-sli-service/issues/55
  */
-func ensureRightTimestamps(start string, end string) (time.Time, time.Time, error) {
+func ensureRightTimestamps(start string, end string, cfg *env.Config) (time.Time, time.Time, error) {
 
 	startUnix, err := common.ParseUnixTimestamp(start)
 	if err != nil {
@@ -88,17 +180,16 @@ func ensureRightTimestamps(start string, end string) (time.Time, time.Time, erro
 		return startUnix, endUnix, errors.New("error validating time range: start time needs to be before end time")
 	}
 
-	// AG-2020-07-16: Wait so Dynatrace has enough data but dont wait every time to shorten processing time
-	// if we have a very short evaluation window and the end timestampe is now then we need to give Dynatrace some time to make sure we have relevant data
-	// if the evalutaion timeframe is > 2 minutes we dont wait and just live with the fact that we may miss one minute or two at the end
-
-	waitForSeconds := 120.0        // by default lets make sure we are at least 120 seconds away from "now()"
-	if timeframeInSeconds >= 300 { // if our evaluated timeframe however is larger than 5 minutes its ok to continue right away. 5 minutes is the default timeframe for most evaluations
-		waitForSeconds = 0.0
-	} else if timeframeInSeconds >= 120 { // if the evaluation span is between 2 and 5 minutes make sure we at least have the last minute of data
-		waitForSeconds = 60.0
+	// shift the timeframe into the past to skip data points Dynatrace has not completely processed yet
+	timeframeShift := time.Duration(cfg.SLITimeframeShift) * time.Second
+	if timeframeShift > 0 {
+		log.WithField("shiftSeconds", timeframeShift.Seconds()).Debug("Shifting evaluation timeframe into the past")
+		startUnix = startUnix.Add(-timeframeShift)
+		endUnix = endUnix.Add(-timeframeShift)
 	}
 
+	waitForSeconds := getWaitForDataSeconds(timeframeInSeconds, cfg)
+
 	// log output while we are waiting
 	if time.Now().Sub(endUnix).Seconds() < waitForSeconds {
 		log.Debug("As the end date is too close to Now() we are going to wait to make sure we have all the data for the requested timeframe(start-end)")
@@ -113,6 +204,26 @@ func ensureRightTimestamps(start string, end string) (time.Time, time.Time, erro
 	return startUnix, endUnix, nil
 }
 
+// getWaitForDataSeconds returns the number of seconds the end of the timeframe must lie in the past before querying Dynatrace
+func getWaitForDataSeconds(timeframeInSeconds float64, cfg *env.Config) float64 {
+	if configuredSeconds := cfg.SLIWaitForData; configuredSeconds >= 0 {
+		return float64(configuredSeconds)
+	}
+
+	// AG-2020-07-16: Wait so Dynatrace has enough data but dont wait every time to shorten processing time
+	// if we have a very short evaluation window and the end timestampe is now then we need to give Dynatrace some time to make sure we have relevant data
+	// if the evalutaion timeframe is > 2 minutes we dont wait and just live with the fact that we may miss one minute or two at the end
+
+	waitForSeconds := 120.0        // by default lets make sure we are at least 120 seconds away from "now()"
+	if timeframeInSeconds >= 300 { // if our evaluated timeframe however is larger than 5 minutes its ok to continue right away. 5 minutes is the default timeframe for most evaluations
+		waitForSeconds = 0.0
+	} else if timeframeInSeconds >= 120 { // if the evaluation span is between 2 and 5 minutes make sure we at least have the last minute of data
+		waitForSeconds = 60.0
+	}
+
+	return waitForSeconds
+}
+
 /**
  * Adds an SLO Entry to the SLO.yaml
  */
@@ -160,10 +271,26 @@ func (eh GetSLIEventHandler) addSLO(newSLO *keptncommon.SLO) error {
 /**
  * Tries to find a dynatrace dashboard that matches our project. If so - returns the SLI, SLO and SLIResults
  */
-func (eh *GetSLIEventHandler) getDataFromDynatraceDashboard(startUnix time.Time, endUnix time.Time) (*dashboard.DashboardLink, []*keptnv2.SLIResult, error) {
+func (eh *GetSLIEventHandler) getDataFromDynatraceDashboard(startUnix time.Time, endUnix time.Time) (*dashboard.QueryResult, error) {
+	result, err := eh.queryDynatraceDashboard(startUnix, endUnix)
+	if result == nil || err != nil {
+		return result, err
+	}
+
+	// lets store the dashboard as well as the SLI and the SLO in the config repo with a single commit
+	err = eh.resourceClient.UploadDashboardSLIAndSLOs(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), result.Dashboard(), result.SLI(), result.SLO())
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// queryDynatraceDashboard queries the SLIs of the Dynatrace dashboard without storing it
+func (eh *GetSLIEventHandler) queryDynatraceDashboard(startUnix time.Time, endUnix time.Time) (*dashboard.QueryResult, error) {
 
 	// creating Dynatrace Retrieval which allows us to call the Dynatrace API
-	sliQuerying := dashboard.NewQuerying(eh.event, eh.event.GetCustomSLIFilters(), eh.dtClient, eh.resourceClient)
+	sliQuerying := dashboard.NewQuerying(eh.event, eh.event.GetCustomSLIFilters(), eh.dtClient, eh.resourceClient).WithTileThresholds(eh.cfg.DashboardTileThresholdsEnabled)
 
 	//
 	// Option 1: We query the data from a dashboard instead of the uploaded SLI.yaml
@@ -171,38 +298,44 @@ func (eh *GetSLIEventHandler) getDataFromDynatraceDashboard(startUnix time.Time,
 	// Lets see if we have a Dashboard in Dynatrace that we should parse
 	result, err := sliQuerying.GetSLIValues(eh.dashboard, startUnix, endUnix)
 	if result == nil && err == nil {
-		return nil, nil, nil
+		return nil, nil
 	}
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not query Dynatrace dashboard for SLIs: %v", err)
+		return nil, fmt.Errorf("could not query Dynatrace dashboard for SLIs: %v", err)
 	}
 
-	// lets store the dashboard as well
-	if result.Dashboard() != nil {
-		err = eh.resourceClient.UploadDashboard(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), result.Dashboard())
-		if err != nil {
-			return result.DashboardLink(), result.SLIResults(), err
-		}
+	return result, nil
+}
+
+// getDashboardLabels returns the labels describing the dashboard the SLIs were retrieved from, i.e. its link and version as well as the version
+// of the dashboard used by the previous evaluation if it was changed since then
+func getDashboardLabels(result *dashboard.QueryResult) map[string]string {
+	labels := make(map[string]string)
+	if result == nil {
+		return labels
 	}
 
-	// lets write the SLI to the config repo
-	if result.SLI() != nil {
-		err = eh.resourceClient.UploadSLI(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), result.SLI())
-		if err != nil {
-			return result.DashboardLink(), result.SLIResults(), err
-		}
+	if result.DashboardLink() != nil {
+		labels[dashboardLinkLabel] = result.DashboardLink().String()
+	}
+	if result.DashboardVersion() != "" {
+		labels[dashboardVersionLabel] = result.DashboardVersion()
+	}
+	if result.HasDashboardChanged() {
+		labels[previousDashboardVersionLabel] = result.PreviousDashboardVersion()
 	}
+	return labels
+}
 
-	// lets write the SLO to the config repo
-	if result.SLO() != nil {
-		err = eh.resourceClient.UploadSLOs(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), result.SLO())
-		if err != nil {
-			return result.DashboardLink(), result.SLIResults(), err
-		}
+// getDashboardChangedWarnings returns a warning if the labels show that the dashboard changed since the previous evaluation, as its objectives may have changed
+func getDashboardChangedWarnings(labels map[string]string) []string {
+	previousVersion, found := labels[previousDashboardVersionLabel]
+	if !found {
+		return nil
 	}
 
-	return result.DashboardLink(), result.SLIResults(), nil
+	return []string{fmt.Sprintf("Warning: dashboard changed since the previous evaluation (version %s, previously %s), SLIs and objectives may differ", labels[dashboardVersionLabel], previousVersion)}
 }
 
 /**
@@ -233,52 +366,146 @@ func getDynatraceProblemContext(eventData GetSLITriggeredAdapterInterface) strin
 }
 
 //
-func (eh *GetSLIEventHandler) getSLIResultsFromCustomQueries(startUnix time.Time, endUnix time.Time) ([]*keptnv2.SLIResult, error) {
+func (eh *GetSLIEventHandler) getSLIResultsFromCustomQueries(startUnix time.Time, endUnix time.Time) ([]*keptnv2.SLIResult, []*SLIRequests, error) {
+	projectCustomQueries, err := eh.getCustomQueries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sliResults, sliRequests := eh.getSLIResultsForIndicators(projectCustomQueries, eh.event.GetIndicators(), startUnix, endUnix)
+	return sliResults, sliRequests, nil
+}
+
+// getCustomQueries returns the SLI definitions of the sli.yaml
+func (eh *GetSLIEventHandler) getCustomQueries() (*keptn.CustomQueries, error) {
 	// get custom metrics for project if they exist
 	projectCustomQueries, err := eh.kClient.GetCustomQueries(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService())
 	if err != nil {
 		log.WithError(err).Errorf("could not retrieve custom queries: %v", err)
 		return nil, fmt.Errorf("could not retrieve custom SLI definitions: %w", err)
 	}
+	return projectCustomQueries, nil
+}
 
-	queryProcessing := query.NewProcessing(eh.dtClient, eh.event, eh.event.GetCustomSLIFilters(), projectCustomQueries, startUnix, endUnix)
+// getSLIResultsForIndicators queries the indicators using the SLI definitions of the sli.yaml from all tenants
+func (eh *GetSLIEventHandler) getSLIResultsForIndicators(projectCustomQueries *keptn.CustomQueries, allIndicators []string, startUnix time.Time, endUnix time.Time) ([]*keptnv2.SLIResult, []*SLIRequests) {
+	newTenantProcessing := func(name string, client dynatrace.ClientInterface) *tenantQueryProcessing {
+		return newTenantQueryProcessing(name, client.Credentials().Tenant, eh.cfg, func() *query.Processing {
+			return query.NewProcessing(client, eh.event, eh.event.GetCustomSLIFilters(), projectCustomQueries, startUnix, endUnix).
+				WithDataCoverageCheck(eh.dataCoverage).
+				WithTimeframeValidation(eh.cfg.SLITimeframeValidationEnabled)
+		})
+	}
 
-	var sliResults []*keptnv2.SLIResult
+	tenants := []*tenantQueryProcessing{newTenantProcessing(eh.secretName, eh.dtClient)}
+	for _, tenant := range eh.additionalTenants {
+		tenants = append(tenants, newTenantProcessing(tenant.Name, tenant.Client))
+	}
 
-	// query all indicators
-	for _, indicator := range eh.event.GetIndicators() {
+	var indicators []string
+	for _, indicator := range allIndicators {
 		if strings.Compare(indicator, ProblemOpenSLI) == 0 {
 			log.WithField("indicator", indicator).Info("Skipping indicator as it is handled later")
 			continue
 		}
+		indicators = append(indicators, indicator)
+	}
+
+	// query all indicators
+	sliValues := getSLIValues(indicators, tenants)
 
-		sliResults = append(sliResults, getSLIResultFromIndicator(indicator, queryProcessing))
+	var sliResults []*keptnv2.SLIResult
+	var sliRequests []*SLIRequests
+	for i, indicator := range indicators {
+		if len(tenants) == 1 {
+			sliResults = append(sliResults, getSLIResult(indicator, sliValues[i][0]))
+			sliRequests = append(sliRequests, &SLIRequests{Metric: indicator, Requests: sliValues[i][0].requests})
+			continue
+		}
+
+		sliResults = append(sliResults, getAggregatedSLIResult(indicator, sliValues[i], eh.sliAggregation))
+
+		var requests []string
+		for _, v := range sliValues[i] {
+			requests = append(requests, v.requests...)
+		}
+		sliRequests = append(sliRequests, &SLIRequests{Metric: indicator, Requests: requests})
 	}
 
-	return sliResults, nil
+	return sliResults, sliRequests
 }
 
-func getSLIResultFromIndicator(indicator string, queryProcessing *query.Processing) *keptnv2.SLIResult {
-	log.WithField("indicator", indicator).Info("Fetching indicator")
-
-	sliValue, err := queryProcessing.GetSLIValue(indicator)
-	if err != nil {
+// getSLIResult returns the result of an indicator queried from a single tenant
+func getSLIResult(indicator string, v tenantSLIValue) *keptnv2.SLIResult {
+	if v.err != nil {
 		// failed to fetch metric
-		log.WithError(err).Error("GetSLIValue failed")
+		log.WithError(v.err).Error("GetSLIValue failed")
 		return &keptnv2.SLIResult{
 			Metric:  indicator,
 			Value:   0,
 			Success: false, // mark as failure
-			Message: err.Error(),
+			Message: appendRetries(v.err.Error(), v.retries),
 		}
 	}
 
 	// successfully fetched metric
 	return &keptnv2.SLIResult{
 		Metric:  indicator,
-		Value:   sliValue,
+		Value:   v.value,
 		Success: true, // mark as success
+		Message: appendWarnings(appendRetries("", v.retries), v.warnings),
+	}
+}
+
+// getSLIValueWithNoDataRetries retries the SLI query with an exponential backoff as long as the Dynatrace Metrics API returns no
+// data points at most maxRetries times and returns the value as well as the number of retries
+func getSLIValueWithNoDataRetries(indicator string, querySLIValue func() (float64, error), maxRetries int, delay time.Duration) (float64, int, error) {
+	retries := 0
+	for {
+		sliValue, err := querySLIValue()
+
+		var noDataErr *query.NoDataError
+		if err == nil || !errors.As(err, &noDataErr) || retries >= maxRetries {
+			return sliValue, retries, err
+		}
+
+		log.WithFields(
+			log.Fields{
+				"indicator":    indicator,
+				"retry":        retries + 1,
+				"delaySeconds": delay.Seconds(),
+			}).Info("No data returned for indicator, retrying")
+		time.Sleep(delay)
+		delay *= 2
+		retries++
+	}
+}
+
+// appendWarnings appends the warnings about the data an SLI value was calculated from to the message
+func appendWarnings(message string, warnings []string) string {
+	if len(warnings) == 0 {
+		return message
+	}
+
+	warningsMessage := strings.Join(warnings, "; ")
+	if message == "" {
+		return warningsMessage
 	}
+
+	return fmt.Sprintf("%s; %s", warningsMessage, message)
+}
+
+func appendRetries(message string, retries int) string {
+	if retries == 0 {
+		return message
+	}
+
+	retriesMessage := fmt.Sprintf("retried %d times as no data was returned", retries)
+	if message == "" {
+		return retriesMessage
+	}
+
+	return fmt.Sprintf("%s (%s)", message, retriesMessage)
 }
 
 func (eh *GetSLIEventHandler) getSLIResultsFromProblemContext(problemID string) *keptnv2.SLIResult {
@@ -326,12 +553,7 @@ func (eh *GetSLIEventHandler) getSLIResultsFromProblemContext(problemID string)
 //
 // First tries to find a Dynatrace dashboard and then parses it for SLIs and SLOs
 // Second will go to parse the SLI.yaml and returns the SLI as passed in by the event
-func (eh *GetSLIEventHandler) retrieveMetrics() error {
-	// send get-sli.started event
-	if err := eh.sendGetSLIStartedEvent(); err != nil {
-		return eh.sendGetSLIFinishedEvent(nil, err)
-	}
-
+func (eh *GetSLIEventHandler) retrieveMetrics() ([]*keptnv2.SLIResult, []*SLIRequests, error) {
 	log.WithFields(
 		log.Fields{
 			"project": eh.event.GetProject(),
@@ -341,40 +563,26 @@ func (eh *GetSLIEventHandler) retrieveMetrics() error {
 
 	// Adding DtCreds as a label so users know which DtCreds was used
 	eh.event.AddLabel("DtCreds", eh.secretName)
+	if len(eh.additionalTenants) > 0 {
+		tenantNames := make([]string, len(eh.additionalTenants))
+		for i, tenant := range eh.additionalTenants {
+			tenantNames[i] = tenant.Name
+		}
+		eh.event.AddLabel("Additional DtCreds", strings.Join(tenantNames, ", "))
+		eh.event.AddLabel("SLI Aggregation", eh.sliAggregation)
+	}
 
 	//
 	// parse start and end (which are datetime strings) and convert them into unix timestamps
-	startUnix, endUnix, err := ensureRightTimestamps(eh.event.GetSLIStart(), eh.event.GetSLIEnd())
+	startUnix, endUnix, err := ensureRightTimestamps(eh.event.GetSLIStart(), eh.event.GetSLIEnd(), eh.cfg)
 	if err != nil {
 		log.WithError(err).Error("ensureRightTimestamps failed")
-		return eh.sendGetSLIFinishedEvent(nil, err)
+		return nil, nil, err
 	}
 
-	//
-	// THIS IS OUR RETURN OBJECT: sliResult
-	// Whether option 1 or option 2 - this will hold our SLIResults
-	var sliResults []*keptnv2.SLIResult
-
-	//
-	// Option 1 - see if we can get the data from a Dynatrace Dashboard
-	dashboardLinkAsLabel, sliResults, err := eh.getDataFromDynatraceDashboard(startUnix, endUnix)
+	sliResults, sliRequests, err := eh.retrieveSLIResultsWithCache(startUnix, endUnix)
 	if err != nil {
-		// log the error, but continue with loading sli.yaml
-		log.WithError(err).Error("getDataFromDynatraceDashboard failed")
-	}
-
-	// add link to dynatrace dashboard to labels
-	if dashboardLinkAsLabel != nil {
-		eh.event.AddLabel("Dashboard Link", dashboardLinkAsLabel.String())
-	}
-
-	//
-	// Option 2: If we have not received any data via a Dynatrace Dashboard lets query the SLIs based on the SLI.yaml definition
-	if sliResults == nil {
-		sliResults, err = eh.getSLIResultsFromCustomQueries(startUnix, endUnix)
-		if err != nil {
-			return eh.sendGetSLIFinishedEvent(nil, err)
-		}
+		return nil, nil, err
 	}
 
 	// ARE WE CALLED IN CONTEXT OF A PROBLEM REMEDIATION??
@@ -390,20 +598,105 @@ func (eh *GetSLIEventHandler) retrieveMetrics() error {
 		err = errors.New("Couldn't retrieve any SLI Results")
 	}
 
-	log.Info("Finished fetching metrics; Sending SLIDone event now ...")
+	log.Info("Finished fetching metrics")
 
-	return eh.sendGetSLIFinishedEvent(sliResults, err)
+	return sliResults, sliRequests, err
 }
 
-/**
- * Sends the SLI Done Event. If err != nil it will send an error message
- */
-func (eh *GetSLIEventHandler) sendGetSLIFinishedEvent(indicatorValues []*keptnv2.SLIResult, err error) error {
+// retrieveSLIResultsWithCache returns the SLI results cached for the same SLIs and timeframe or retrieves and caches them
+func (eh *GetSLIEventHandler) retrieveSLIResultsWithCache(startUnix time.Time, endUnix time.Time) ([]*keptnv2.SLIResult, []*SLIRequests, error) {
+	cacheKey := eh.getSLIResultCacheKey(startUnix, endUnix)
+	if labels, sliResults, sliRequests, found := eh.cache.get(cacheKey); found {
+		log.WithField("indicators", eh.event.GetIndicators()).Info("Using cached SLI results of the same timeframe")
+		eh.addLabels(labels)
+		return sliResults, sliRequests, nil
+	}
 
-	// if an error was set - the indicators will be set to failed and error message is set to each
-	indicatorValues = resetIndicatorsInCaseOfError(err, eh.event, indicatorValues)
+	labels, sliResults, sliRequests, err := eh.retrieveSLIResults(startUnix, endUnix)
+	eh.addLabels(labels)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	eh.cache.put(cacheKey, labels, sliResults, sliRequests)
+	return sliResults, sliRequests, nil
+}
 
-	return eh.sendEvent(NewGetSLIFinishedEventFactory(eh.event, indicatorValues, err))
+func (eh *GetSLIEventHandler) addLabels(labels map[string]string) {
+	for name, value := range labels {
+		eh.event.AddLabel(name, value)
+	}
+}
+
+// retrieveSLIResults retrieves the SLI results from a Dynatrace dashboard or, if there is none, using the SLI definitions in the sli.yaml.
+// It also returns the labels to add to the event, e.g. the link to the dashboard
+func (eh *GetSLIEventHandler) retrieveSLIResults(startUnix time.Time, endUnix time.Time) (map[string]string, []*keptnv2.SLIResult, []*SLIRequests, error) {
+	if eh.sliMerge != "" {
+		return eh.retrieveMergedSLIResults(startUnix, endUnix)
+	}
+
+	//
+	// Option 1 - see if we can get the data from a Dynatrace Dashboard
+	dashboardResult, err := eh.getDataFromDynatraceDashboard(startUnix, endUnix)
+	if err != nil {
+		// log the error, but continue with loading sli.yaml
+		log.WithError(err).Error("getDataFromDynatraceDashboard failed")
+	}
+
+	// add link to and version of dynatrace dashboard to labels
+	labels := getDashboardLabels(dashboardResult)
+
+	var sliResults []*keptnv2.SLIResult
+	if dashboardResult != nil {
+		sliResults = dashboardResult.SLIResults()
+	}
+
+	//
+	// Option 2: If we have not received any data via a Dynatrace Dashboard lets query the SLIs based on the SLI.yaml definition
+	var sliRequests []*SLIRequests
+	if sliResults == nil {
+		sliResults, sliRequests, err = eh.getSLIResultsFromCustomQueries(startUnix, endUnix)
+		if err != nil {
+			return labels, nil, nil, err
+		}
+	}
+
+	return labels, sliResults, sliRequests, nil
+}
+
+// getSLIResultCacheKey returns the key SLI results are cached with. It contains everything the results depend on besides the SLI definitions,
+// which are identified by the git commit of the configuration repository, if there is one. This includes all values of the event that
+// may be substituted for placeholders in the queries, e.g. $CONTEXT, $DEPLOYMENT or $LABEL.*, see common.ReplaceKeptnPlaceholders
+func (eh *GetSLIEventHandler) getSLIResultCacheKey(startUnix time.Time, endUnix time.Time) string {
+	parts := []string{eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), eh.event.GetGitCommitID(), eh.secretName, eh.dashboard, eh.sliAggregation}
+	parts = append(parts, eh.event.GetShKeptnContext(), eh.event.GetEvent(), eh.event.GetSource(), eh.event.GetDeployment(), eh.event.GetTestStrategy(), eh.event.GetDeploymentStrategy())
+
+	labels := eh.event.GetLabels()
+	labelNames := make([]string, 0, len(labels))
+	for name := range labels {
+		labelNames = append(labelNames, name)
+	}
+	sort.Strings(labelNames)
+	for _, name := range labelNames {
+		parts = append(parts, "label:"+name+"="+labels[name])
+	}
+	for _, tenant := range eh.additionalTenants {
+		parts = append(parts, tenant.Name)
+	}
+	parts = append(parts, strings.Join(eh.event.GetIndicators(), ","))
+	for _, filter := range eh.event.GetCustomSLIFilters() {
+		if filter != nil {
+			parts = append(parts, filter.Key+"="+filter.Value)
+		}
+	}
+	if eh.sliMerge != "" {
+		parts = append(parts, "merge="+eh.sliMerge)
+	}
+	if eh.dataCoverage != nil {
+		parts = append(parts, fmt.Sprintf("coverage=%g,%t", eh.dataCoverage.MinPercent, eh.dataCoverage.FailBelowMinPercent))
+	}
+	parts = append(parts, strconv.FormatInt(startUnix.Unix(), 10), strconv.FormatInt(endUnix.Unix(), 10))
+	return strings.Join(parts, "|")
 }
 
 func resetIndicatorsInCaseOfError(err error, eventData GetSLITriggeredAdapterInterface, indicatorValues []*keptnv2.SLIResult) []*keptnv2.SLIResult {
@@ -433,20 +726,3 @@ func resetIndicatorsInCaseOfError(err error, eventData GetSLITriggeredAdapterInt
 
 	return indicatorValues
 }
-
-func (eh *GetSLIEventHandler) sendGetSLIStartedEvent() error {
-	return eh.sendEvent(NewGetSliStartedEventFactory(eh.event))
-}
-
-/**
- * sends cloud event back to keptn
- */
-func (eh *GetSLIEventHandler) sendEvent(factory adapter.CloudEventFactoryInterface) error {
-	err := eh.kClient.SendCloudEvent(factory)
-	if err != nil {
-		log.WithError(err).Error("Could not send get sli cloud event")
-		return err
-	}
-
-	return nil
-}
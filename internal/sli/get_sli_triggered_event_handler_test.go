@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	keptnapi "github.com/keptn/go-utils/pkg/lib"
 	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"testing"
+	"time"
 )
 
 const indicator = "response_time_p95"
@@ -85,7 +89,7 @@ func TestNoDefaultSLIsAreUsedWhenCustomSLIsAreInvalidYAML(t *testing.T) {
 
 	const errorMessage = "invalid YAML file - some parsing issue"
 	kClient := &keptnClientMock{
-		customQueriesError: fmt.Errorf(errorMessage),
+		customQueriesError: common.NewUserConfigurationError(fmt.Errorf(errorMessage)),
 	}
 
 	assertionsFunc := func(t *testing.T, actual *keptnv2.SLIResult) {
@@ -326,9 +330,150 @@ func setupTestAndAssertNoError(t *testing.T, handler http.Handler, kClient *kept
 	eh, _, teardown := createGetSLIEventHandler(ev, handler, kClient)
 	defer teardown()
 
-	err := eh.retrieveMetrics()
+	factory, err := eh.HandleTask()
+	assert.NoError(t, err)
+
+	err = kClient.SendCloudEvent(factory)
+	assert.NoError(t, err)
+}
+
+// Tests that an SLI query returning no data points is retried and that the retries and the request are reported in the finished event
+func TestSLIQueryWithoutDataIsRetried(t *testing.T) {
+	noDataResponse := []byte(`{"result":[{"metricId":"builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)","data":[]}]}`)
+	dataResponse := []byte(`{"result":[{"metricId":"builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)","data":[{"dimensions":[],"timestamps":[1],"values":[12000]}]}]}`)
+
+	requests := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= 2 {
+			w.Write(noDataResponse)
+			return
+		}
+		w.Write(dataResponse)
+	})
+
+	kClient := &keptnClientMock{
+		customQueries: map[string]string{
+			indicator: "metricSelector=builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)&entitySelector=type(SERVICE),tag(keptn_project:sockshop),tag(keptn_stage:staging)",
+		},
+	}
+
+	assertionsFunc := func(t *testing.T, actual *keptnv2.SLIResult) {
+		assert.EqualValues(t, indicator, actual.Metric)
+		assert.EqualValues(t, 12, actual.Value)
+		assert.EqualValues(t, true, actual.Success)
+		assert.EqualValues(t, "retried 2 times as no data was returned", actual.Message)
+	}
+
+	ev := &getSLIEventData{
+		project:    "sockshop",
+		stage:      "staging",
+		service:    "carts",
+		indicators: []string{indicator},
+	}
+
+	eh, _, teardown := createGetSLIEventHandler(ev, handler, kClient)
+	defer teardown()
+	eh.cfg.SLINoDataRetries = 3
+	eh.cfg.SLINoDataRetryDelay = 0
+
+	factory, err := eh.HandleTask()
+	assert.NoError(t, err)
+	assert.NoError(t, kClient.SendCloudEvent(factory))
+
+	assertThatEventHasExpectedPayloadWithMatchingFunc(t, assertionsFunc, kClient.eventSink, false)
+	assert.Equal(t, 3, requests)
+
+	// only the request of the last retry is reported
+	var data getSLIFinishedEventData
+	err = json.Unmarshal(kClient.eventSink[0].Data(), &data)
+	if assert.NoError(t, err) && assert.Len(t, data.SLIRequests, 1) {
+		assert.Equal(t, indicator, data.SLIRequests[0].Metric)
+		if assert.Len(t, data.SLIRequests[0].Requests, 1) {
+			assert.Contains(t, data.SLIRequests[0].Requests[0], "/api/v2/metrics/query?entitySelector=type%28SERVICE%29%2Ctag%28keptn_project%3Asockshop%29%2Ctag%28keptn_stage%3Astaging%29&from=")
+		}
+	}
+}
+
+// Tests that in strict mode a key SLI that could not be retrieved errors the get-sli task, while other failed SLIs do not
+func TestStrictKeySLIsErrorTheTaskIfAKeySLIFails(t *testing.T) {
+	for _, keySLI := range []bool{true, false} {
+		t.Run(fmt.Sprintf("keySLI=%v", keySLI), func(t *testing.T) {
+			handler := test.NewFileBasedURLHandler(t)
+			handler.AddExact(
+				"/api/v2/metrics/query?entitySelector=type%28SERVICE%29%2Ctag%28keptn_project%3Asockshop%29%2Ctag%28keptn_stage%3Astagin%29&from=1632834999000&metricSelector=builtin%3Aservice.response.time%3Amerge%28%22dt.entity.service%22%29%3Apercentile%2895%29&resolution=Inf&to=1632835299000",
+				"./testdata/response_time_p95_200_0_result_wrong-tag.json")
+
+			kClient := &keptnClientMock{
+				customQueries: map[string]string{
+					indicator: "metricSelector=builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)&entitySelector=type(SERVICE),tag(keptn_project:sockshop),tag(keptn_stage:stagin)",
+				},
+			}
+
+			ev := &getSLIEventData{
+				project:    "sockshop",
+				stage:      "staging",
+				service:    "carts",
+				indicators: []string{indicator},
+			}
+
+			eh, _, teardown := createGetSLIEventHandler(ev, handler, kClient)
+			defer teardown()
+			eh.strictKeySLIs = true
+			eh.resourceClient = &resourceClientMock{
+				slos: &keptnapi.ServiceLevelObjectives{
+					Objectives: []*keptnapi.SLO{{SLI: indicator, KeySLI: keySLI}},
+				},
+			}
+
+			factory, err := eh.HandleTask()
+			assert.NoError(t, err)
+			assert.NoError(t, kClient.SendCloudEvent(factory))
+
+			assert.EqualValues(t, 1, len(kClient.eventSink))
+			var data keptnv2.GetSLIFinishedEventData
+			assert.NoError(t, json.Unmarshal(kClient.eventSink[0].Data(), &data))
+			assert.EqualValues(t, keptnv2.ResultFailed, data.Result)
+			if keySLI {
+				assert.EqualValues(t, keptnv2.StatusErrored, data.Status)
+				assert.Contains(t, data.Message, "key SLIs could not be retrieved: response_time_p95")
+			} else {
+				assert.EqualValues(t, keptnv2.StatusSucceeded, data.Status)
+			}
+		})
+	}
+}
+
+func TestEnsureRightTimestampsShiftsTimeframe(t *testing.T) {
+	cfg := env.ReadConfig()
+	cfg.SLITimeframeShift = 120
+	cfg.SLIWaitForData = 0
+
+	end := time.Now().UTC().Truncate(time.Second)
+	start := end.Add(-time.Minute)
+
+	startUnix, endUnix, err := ensureRightTimestamps(start.Format(time.RFC3339), end.Format(time.RFC3339), cfg)
 
 	assert.NoError(t, err)
+	assert.Equal(t, start.Add(-2*time.Minute), startUnix)
+	assert.Equal(t, end.Add(-2*time.Minute), endUnix)
+}
+
+func TestGetWaitForDataSeconds(t *testing.T) {
+	cfg := env.ReadConfig()
+	assert.EqualValues(t, 120, getWaitForDataSeconds(60, cfg))
+	assert.EqualValues(t, 60, getWaitForDataSeconds(180, cfg))
+	assert.EqualValues(t, 0, getWaitForDataSeconds(300, cfg))
+
+	cfg.SLIWaitForData = 90
+	assert.EqualValues(t, 90, getWaitForDataSeconds(300, cfg))
+}
+
+func TestGetDashboardChangedWarnings(t *testing.T) {
+	assert.Empty(t, getDashboardChangedWarnings(map[string]string{dashboardLinkLabel: "https://tenant/#dashboard;id=123", dashboardVersionLabel: "35157d8b9c45"}))
+	assert.Equal(t,
+		[]string{"Warning: dashboard changed since the previous evaluation (version 35157d8b9c45, previously 0a1b2c3d4e5f), SLIs and objectives may differ"},
+		getDashboardChangedWarnings(map[string]string{dashboardVersionLabel: "35157d8b9c45", previousDashboardVersionLabel: "0a1b2c3d4e5f"}))
 }
 
 func assertThatEventHasExpectedPayloadWithMatchingFunc(t *testing.T, assertionsFunc func(*testing.T, *keptnv2.SLIResult), events []*cloudevents.Event, shouldFail bool) {
@@ -339,13 +484,12 @@ func assertThatEventHasExpectedPayloadWithMatchingFunc(t *testing.T, assertionsF
 }
 
 func assertThatEventsAreThere(t *testing.T, events []*cloudevents.Event, shouldFail bool) *keptnv2.GetSLIFinishedEventData {
-	assert.EqualValues(t, 2, len(events))
+	assert.EqualValues(t, 1, len(events))
 
-	assert.EqualValues(t, keptnv2.GetStartedEventType(keptnv2.GetSLITaskName), events[0].Type())
-	assert.EqualValues(t, keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), events[1].Type())
+	assert.EqualValues(t, keptnv2.GetFinishedEventType(keptnv2.GetSLITaskName), events[0].Type())
 
 	var data keptnv2.GetSLIFinishedEventData
-	err := json.Unmarshal(events[1].Data(), &data)
+	err := json.Unmarshal(events[0].Data(), &data)
 	if err != nil {
 		t.Fatalf("could not parse event payload correctly: %s", err)
 	}
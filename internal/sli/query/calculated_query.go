@@ -0,0 +1,210 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+)
+
+// indicatorValueFunc returns the value of the indicator with the given name
+type indicatorValueFunc func(indicator string) (float64, error)
+
+// calculation evaluates an arithmetic expression over other indicators, e.g. failed_requests / total_requests * 100.
+// Supported are numbers, indicator names, the operators +, -, * and / as well as parentheses.
+// Indicator names may contain '.' and '-', so a '-' directly followed by another character of the name is not a subtraction, e.g. response-time - 5
+type calculation struct {
+	expression     string
+	tokens         []string
+	position       int
+	indicatorValue indicatorValueFunc
+}
+
+// evaluateCalculation evaluates the expression using indicatorValue to resolve the referenced indicators
+func evaluateCalculation(expression string, indicatorValue indicatorValueFunc) (float64, error) {
+	tokens, err := tokenizeCalculation(expression)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(tokens) == 0 {
+		return 0, fmt.Errorf("calculated SLI expression is empty")
+	}
+
+	c := &calculation{
+		expression:     expression,
+		tokens:         tokens,
+		indicatorValue: indicatorValue,
+	}
+
+	value, err := c.parseExpression()
+	if err != nil {
+		return 0, err
+	}
+
+	if c.position < len(c.tokens) {
+		return 0, fmt.Errorf("unexpected '%s' in calculated SLI expression: %s", c.tokens[c.position], expression)
+	}
+
+	return value, nil
+}
+
+func tokenizeCalculation(expression string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '+' || r == '-' || r == '*' || r == '/' || r == '(' || r == ')':
+			tokens = append(tokens, string(r))
+			i++
+
+		case isDigitRune(r) || r == '.':
+			start := i
+			for i < len(runes) && (isDigitRune(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+
+		case isIndicatorNameRune(r):
+			start := i
+			for i < len(runes) && isIndicatorNameContinuationRune(runes, i) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' in calculated SLI expression: %s", r, expression)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isIndicatorNameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || r == '_'
+}
+
+// isIndicatorNameContinuationRune returns whether the rune at position i continues an indicator name.
+// A '-' only does so if it is followed by another character of the name, otherwise it is the subtraction operator
+func isIndicatorNameContinuationRune(runes []rune, i int) bool {
+	r := runes[i]
+	if r == '-' {
+		return i+1 < len(runes) && (isIndicatorNameRune(runes[i+1]) || isDigitRune(runes[i+1]) || runes[i+1] == '.')
+	}
+	return isIndicatorNameRune(r) || isDigitRune(r) || r == '.'
+}
+
+func isDigitRune(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+// parseExpression parses a sum or difference of terms
+func (c *calculation) parseExpression() (float64, error) {
+	value, err := c.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+
+	for c.peek() == "+" || c.peek() == "-" {
+		operator := c.next()
+		operand, err := c.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+
+		if operator == "+" {
+			value += operand
+		} else {
+			value -= operand
+		}
+	}
+
+	return value, nil
+}
+
+// parseTerm parses a product or quotient of factors
+func (c *calculation) parseTerm() (float64, error) {
+	value, err := c.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+
+	for c.peek() == "*" || c.peek() == "/" {
+		operator := c.next()
+		operand, err := c.parseFactor()
+		if err != nil {
+			return 0, err
+		}
+
+		if operator == "*" {
+			value *= operand
+			continue
+		}
+
+		if operand == 0 {
+			return 0, fmt.Errorf("division by zero in calculated SLI expression: %s", c.expression)
+		}
+		value /= operand
+	}
+
+	return value, nil
+}
+
+// parseFactor parses a number, an indicator, a negated factor or an expression in parentheses
+func (c *calculation) parseFactor() (float64, error) {
+	token := c.next()
+	switch {
+	case token == "":
+		return 0, fmt.Errorf("unexpected end of calculated SLI expression: %s", c.expression)
+
+	case token == "-":
+		value, err := c.parseFactor()
+		return -value, err
+
+	case token == "(":
+		value, err := c.parseExpression()
+		if err != nil {
+			return 0, err
+		}
+
+		if c.next() != ")" {
+			return 0, fmt.Errorf("missing ')' in calculated SLI expression: %s", c.expression)
+		}
+		return value, nil
+
+	case isDigitRune(rune(token[0])) || token[0] == '.':
+		value, err := strconv.ParseFloat(token, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse number '%s' in calculated SLI expression: %s", token, c.expression)
+		}
+		return value, nil
+
+	case isIndicatorNameRune(rune(token[0])):
+		value, err := c.indicatorValue(token)
+		if err != nil {
+			return 0, fmt.Errorf("could not get value of indicator '%s': %w", token, err)
+		}
+		return value, nil
+
+	default:
+		return 0, fmt.Errorf("unexpected '%s' in calculated SLI expression: %s", token, c.expression)
+	}
+}
+
+func (c *calculation) peek() string {
+	if c.position >= len(c.tokens) {
+		return ""
+	}
+	return c.tokens[c.position]
+}
+
+func (c *calculation) next() string {
+	token := c.peek()
+	if token != "" {
+		c.position++
+	}
+	return token
+}
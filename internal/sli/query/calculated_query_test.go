@@ -0,0 +1,110 @@
+package query
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluateCalculation(t *testing.T) {
+	values := map[string]float64{
+		"failed_requests": 5,
+		"total_requests":  200,
+		"zero":            0,
+		"response-time":   300,
+		"rt.p90-ms":       250,
+	}
+	indicatorValue := func(indicator string) (float64, error) {
+		value, ok := values[indicator]
+		if !ok {
+			return 0, fmt.Errorf("SLI definition for '%s' was not found", indicator)
+		}
+		return value, nil
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       float64
+		wantErr    bool
+	}{
+		{
+			name:       "ratio in percent",
+			expression: "failed_requests / total_requests * 100",
+			want:       2.5,
+		},
+		{
+			name:       "operator precedence",
+			expression: "1 + 2 * 3",
+			want:       7,
+		},
+		{
+			name:       "parentheses and negation",
+			expression: "-(total_requests - failed_requests) / 2.5",
+			want:       -78,
+		},
+		{
+			name:       "indicator names containing '-' and '.'",
+			expression: "response-time - rt.p90-ms",
+			want:       50,
+		},
+		{
+			name:       "subtraction without whitespace after indicator name",
+			expression: "response-time-(rt.p90-ms)",
+			want:       50,
+		},
+		{
+			name:       "subtraction of number",
+			expression: "response-time - 100",
+			want:       200,
+		},
+		{
+			name:       "'-' without whitespace is part of indicator name",
+			expression: "total_requests-failed_requests",
+			wantErr:    true,
+		},
+		{
+			name:       "unknown indicator",
+			expression: "failed_requests / unknown",
+			wantErr:    true,
+		},
+		{
+			name:       "division by zero",
+			expression: "failed_requests / zero",
+			wantErr:    true,
+		},
+		{
+			name:       "missing parenthesis",
+			expression: "(failed_requests + 1",
+			wantErr:    true,
+		},
+		{
+			name:       "trailing operator",
+			expression: "failed_requests *",
+			wantErr:    true,
+		},
+		{
+			name:       "unsupported character",
+			expression: "failed_requests % 2",
+			wantErr:    true,
+		},
+		{
+			name:       "empty expression",
+			expression: " ",
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := evaluateCalculation(tt.expression, indicatorValue)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 0.0001)
+		})
+	}
+}
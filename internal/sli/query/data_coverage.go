@@ -0,0 +1,149 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// maxDataCoverageBuckets is the maximum number of data points requested to determine the data coverage of the evaluation timeframe
+const maxDataCoverageBuckets = 60
+
+// dataCoverage describes how much of the evaluation timeframe is covered by the data points of a metric
+type dataCoverage struct {
+	// percent is the percentage of time buckets of the timeframe that contain a data point
+	percent float64
+	// longestGap is the longest period without data points
+	longestGap time.Duration
+}
+
+// getDataCoverageResolution returns the resolution splitting the timeframe into at most maxDataCoverageBuckets buckets, but at least one minute
+func getDataCoverageResolution(startUnix time.Time, endUnix time.Time) time.Duration {
+	minutes := math.Ceil(endUnix.Sub(startUnix).Minutes() / maxDataCoverageBuckets)
+	if minutes < 1 {
+		minutes = 1
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// getDataCoverage queries the metric of the built metrics query at a finer resolution and determines the share of buckets containing data points.
+// Fold transformations are removed, as they would combine the buckets into a single one. The request is not recorded, as it does not contribute to the SLI value
+func (p *Processing) getDataCoverage(metricsQuery string, metricSelector string, startUnix time.Time, endUnix time.Time) (*dataCoverage, error) {
+	q, err := url.ParseQuery(metricsQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	coverageMetricSelector := removeFoldTransformations(metricSelector)
+	resolution := getDataCoverageResolution(startUnix, endUnix)
+	q.Set("metricSelector", removeFoldTransformations(q.Get("metricSelector")))
+	q.Set("resolution", strconv.Itoa(int(resolution.Minutes()))+"m")
+
+	result, err := dynatrace.NewMetricsClient(p.client.ClientInterface).GetSeriesByQuery(q.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range result.Result {
+		if !IsMatchingMetricID(r.MetricID, coverageMetricSelector) || len(r.Data) == 0 {
+			continue
+		}
+
+		return calculateDataCoverage(r.Data[0].Values, resolution), nil
+	}
+
+	return &dataCoverage{percent: 0, longestGap: endUnix.Sub(startUnix)}, nil
+}
+
+// removeFoldTransformations removes the fold transformations from a metric selector, e.g. builtin:service.response.time:fold(avg):merge("dt.entity.service")
+// becomes builtin:service.response.time:merge("dt.entity.service")
+func removeFoldTransformations(metricSelector string) string {
+	const fold = ":fold"
+
+	var result strings.Builder
+	for {
+		index := strings.Index(metricSelector, fold)
+		if index < 0 {
+			result.WriteString(metricSelector)
+			return result.String()
+		}
+
+		end := index + len(fold)
+		if end < len(metricSelector) && metricSelector[end] == '(' {
+			closing := strings.IndexByte(metricSelector[end:], ')')
+			if closing < 0 {
+				result.WriteString(metricSelector)
+				return result.String()
+			}
+			end += closing + 1
+		}
+
+		// metric keys starting with fold, e.g. custom:folder.size, are kept
+		if end < len(metricSelector) && metricSelector[end] != ':' && metricSelector[end] != ')' && metricSelector[end] != ',' {
+			result.WriteString(metricSelector[:end])
+			metricSelector = metricSelector[end:]
+			continue
+		}
+
+		result.WriteString(metricSelector[:index])
+		metricSelector = metricSelector[end:]
+	}
+}
+
+// calculateDataCoverage returns the data coverage of a series of values, where null values are buckets without data points
+func calculateDataCoverage(values []*float64, resolution time.Duration) *dataCoverage {
+	if len(values) == 0 {
+		return &dataCoverage{}
+	}
+
+	covered := 0
+	gap := 0
+	longestGap := 0
+	for _, value := range values {
+		if value != nil {
+			covered++
+			gap = 0
+			continue
+		}
+
+		gap++
+		if gap > longestGap {
+			longestGap = gap
+		}
+	}
+
+	return &dataCoverage{
+		percent:    float64(covered) * 100 / float64(len(values)),
+		longestGap: time.Duration(longestGap) * resolution,
+	}
+}
+
+// checkDataCoverage checks the data coverage of a metrics SLI. It returns a warning if the coverage is below the minimum, or an error if such SLIs should fail
+func (p *Processing) checkDataCoverage(metricsQuery string, metricSelector string, startUnix time.Time, endUnix time.Time) error {
+	coverage, err := p.getDataCoverage(metricsQuery, metricSelector, startUnix, endUnix)
+	if err != nil {
+		p.warnings = append(p.warnings, fmt.Sprintf("Warning: could not check the data coverage of the evaluation timeframe: %s", err.Error()))
+		return nil
+	}
+
+	if coverage.percent >= p.dataCoverage.MinPercent {
+		return nil
+	}
+
+	message := fmt.Sprintf("data points cover only %.0f%% of the evaluation timeframe, expected at least %.0f%%", coverage.percent, p.dataCoverage.MinPercent)
+	if coverage.longestGap > 0 {
+		message = fmt.Sprintf("%s (longest gap: %s)", message, coverage.longestGap)
+	}
+
+	if p.dataCoverage.FailBelowMinPercent {
+		return fmt.Errorf("Dynatrace Metrics API returned incomplete data: %s", message)
+	}
+
+	p.warnings = append(p.warnings, "Warning: "+message)
+	return nil
+}
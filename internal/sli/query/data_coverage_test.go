@@ -0,0 +1,153 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDataCoverageResolution(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	assert.Equal(t, time.Minute, getDataCoverageResolution(start, start.Add(5*time.Minute)))
+	assert.Equal(t, time.Minute, getDataCoverageResolution(start, start.Add(time.Hour)))
+	assert.Equal(t, 2*time.Minute, getDataCoverageResolution(start, start.Add(90*time.Minute)))
+	assert.Equal(t, 24*time.Minute, getDataCoverageResolution(start, start.Add(24*time.Hour)))
+}
+
+// Tests that metrics SLIs whose data points do not cover enough of the evaluation timeframe get a warning or fail
+func TestGetSLIValueWithDataCoverageCheck(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	end := start.Add(10 * time.Minute)
+
+	fullCoverage := `{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1,2,3,4,5,6,7,8,9,10],"values":[1,2,3,4,5,6,7,8,9,10]}]}]}`
+	partialCoverage := `{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1,2,3,4,5,6,7,8,9,10],"values":[1,null,null,null,null,null,null,7,null,10]}]}]}`
+
+	tests := []struct {
+		name             string
+		dataCoverage     *config.SLIDataCoverage
+		coverageResponse string
+		expectedWarnings []string
+		expectedErr      string
+	}{
+		{
+			name:         "check disabled",
+			dataCoverage: nil,
+		},
+		{
+			name:             "full coverage",
+			dataCoverage:     &config.SLIDataCoverage{MinPercent: 90},
+			coverageResponse: fullCoverage,
+		},
+		{
+			name:             "partial coverage is reported as a warning",
+			dataCoverage:     &config.SLIDataCoverage{MinPercent: 90},
+			coverageResponse: partialCoverage,
+			expectedWarnings: []string{"Warning: data points cover only 30% of the evaluation timeframe, expected at least 90% (longest gap: 6m0s)"},
+		},
+		{
+			name:             "partial coverage fails",
+			dataCoverage:     &config.SLIDataCoverage{MinPercent: 90, FailBelowMinPercent: true},
+			coverageResponse: partialCoverage,
+			expectedErr:      "Dynatrace Metrics API returned incomplete data: data points cover only 30% of the evaluation timeframe, expected at least 90% (longest gap: 6m0s)",
+		},
+		{
+			name:             "partial coverage above minimum",
+			dataCoverage:     &config.SLIDataCoverage{MinPercent: 25},
+			coverageResponse: partialCoverage,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			handler.AddExact(createMetricsQueryURL(start, end), []byte(`{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1],"values":[300000]}]}]}`))
+			if tt.coverageResponse != "" {
+				handler.AddExact(createMetricsCoverageQueryURL(start, end, "1m"), []byte(tt.coverageResponse))
+			}
+
+			httpClient, teardown := test.CreateHTTPClient(handler)
+			defer teardown()
+
+			customQueries := map[string]string{
+				"response_time": "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			}
+			p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), start, end).WithDataCoverageCheck(tt.dataCoverage)
+
+			value, err := p.GetSLIValue("response_time")
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, 300, value)
+			assert.Equal(t, tt.expectedWarnings, p.GetSLIWarnings("response_time"))
+			assert.Len(t, p.GetSLIRequests("response_time"), 1)
+		})
+	}
+}
+
+// Tests that the data coverage of metrics SLIs folding their data points is checked using the unfolded data points
+func TestGetSLIValueWithDataCoverageCheck_FoldedMetric(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	end := start.Add(10 * time.Minute)
+
+	q := url.Values{}
+	q.Add("metricSelector", "builtin:service.response.time:fold(avg)")
+	q.Add("entitySelector", "type(SERVICE)")
+	q.Add("resolution", "Inf")
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(metricAPIURL+"?"+q.Encode(), []byte(`{"result":[{"metricId":"builtin:service.response.time:fold(avg)","data":[{"dimensions":[],"timestamps":[1],"values":[300000]}]}]}`))
+	handler.AddExact(createMetricsCoverageQueryURL(start, end, "1m"), []byte(`{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1,2,3,4],"values":[1,null,null,4]}]}]}`))
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	customQueries := map[string]string{
+		"response_time": "metricSelector=builtin:service.response.time:fold(avg)&entitySelector=type(SERVICE)",
+	}
+	p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), start, end).
+		WithDataCoverageCheck(&config.SLIDataCoverage{MinPercent: 90})
+
+	value, err := p.GetSLIValue("response_time")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 300, value)
+	assert.Equal(t, []string{"Warning: data points cover only 50% of the evaluation timeframe, expected at least 90% (longest gap: 2m0s)"}, p.GetSLIWarnings("response_time"))
+}
+
+func TestRemoveFoldTransformations(t *testing.T) {
+	tests := []struct {
+		metricSelector string
+		want           string
+	}{
+		{metricSelector: "builtin:service.response.time", want: "builtin:service.response.time"},
+		{metricSelector: "builtin:service.response.time:fold", want: "builtin:service.response.time"},
+		{metricSelector: "builtin:service.response.time:fold(avg)", want: "builtin:service.response.time"},
+		{metricSelector: "builtin:service.response.time:fold(avg):merge(\"dt.entity.service\")", want: "builtin:service.response.time:merge(\"dt.entity.service\")"},
+		{metricSelector: "(builtin:service.errors.total.count:fold,builtin:service.requestCount.total:fold(sum))", want: "(builtin:service.errors.total.count,builtin:service.requestCount.total)"},
+		{metricSelector: "custom:folder.size", want: "custom:folder.size"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metricSelector, func(t *testing.T) {
+			assert.Equal(t, tt.want, removeFoldTransformations(tt.metricSelector))
+		})
+	}
+}
+
+func createMetricsCoverageQueryURL(start time.Time, end time.Time, resolution string) string {
+	q := url.Values{}
+	q.Add("metricSelector", "builtin:service.response.time")
+	q.Add("entitySelector", "type(SERVICE)")
+	q.Add("resolution", resolution)
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+	return metricAPIURL + "?" + q.Encode()
+}
@@ -0,0 +1,164 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// entityMeasure is a built-in metric queried by a measure of an entity query, e.g. HOST;cpu
+type entityMeasure struct {
+	metricKey  string
+	metricUnit string
+	// aggregation is used if the query does not define one
+	aggregation string
+	// mergedDimensions are the dimensions of the metric besides the entity that are merged to get a single value
+	mergedDimensions []string
+}
+
+// queriedEntity describes the entity type an entity query prefix, e.g. HOST;, refers to
+type queriedEntity struct {
+	prefix     string
+	name       string
+	entityType string
+	dimension  string
+	measures   map[string]entityMeasure
+	// count returns the number of entities of the type matching the entity selector in the timeframe
+	count func(client dynatrace.ClientInterface, entitySelector string, startUnix time.Time, endUnix time.Time) (int, error)
+}
+
+var hostEntity = queriedEntity{
+	prefix:     "HOST;",
+	name:       "hosts",
+	entityType: dynatrace.HostEntityType,
+	dimension:  "dt.entity.host",
+	measures: map[string]entityMeasure{
+		"cpu":    {metricKey: "builtin:host.cpu.usage", aggregation: "max"},
+		"load":   {metricKey: "builtin:host.cpu.load", aggregation: "max"},
+		"memory": {metricKey: "builtin:host.mem.usage", aggregation: "max"},
+		"disk":   {metricKey: "builtin:host.disk.usedPct", aggregation: "max"},
+	},
+	count: func(client dynatrace.ClientInterface, entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+		return dynatrace.NewHostsClient(client).CountByEntitySelector(entitySelector, startUnix, endUnix)
+	},
+}
+
+var processGroupInstanceEntity = queriedEntity{
+	prefix:     "PGI;",
+	name:       "process group instances",
+	entityType: dynatrace.ProcessGroupInstanceEntityType,
+	dimension:  "dt.entity.process_group_instance",
+	measures: map[string]entityMeasure{
+		"cpu":    {metricKey: "builtin:tech.generic.cpu.usage", aggregation: "max"},
+		"memory": {metricKey: "builtin:tech.generic.mem.workingSetSize", metricUnit: "Byte", aggregation: "max"},
+	},
+	count: func(client dynatrace.ClientInterface, entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+		return dynatrace.NewProcessGroupInstancesClient(client).CountByEntitySelector(entitySelector, startUnix, endUnix)
+	},
+}
+
+var applicationEntity = queriedEntity{
+	prefix:     "APP;",
+	name:       "applications",
+	entityType: dynatrace.ApplicationEntityType,
+	dimension:  "dt.entity.application",
+	measures: map[string]entityMeasure{
+		"apdex":       {metricKey: "builtin:apps.web.apdex.userType", aggregation: "avg", mergedDimensions: []string{"User type"}},
+		"duration":    {metricKey: "builtin:apps.web.actionDuration.load.browser", metricUnit: "MicroSecond", aggregation: "avg", mergedDimensions: []string{"dt.entity.browser"}},
+		"xhrDuration": {metricKey: "builtin:apps.web.actionDuration.xhr.browser", metricUnit: "MicroSecond", aggregation: "avg", mergedDimensions: []string{"dt.entity.browser"}},
+	},
+	count: func(client dynatrace.ClientInterface, entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+		return dynatrace.NewApplicationsClient(client).CountByEntitySelector(entitySelector, startUnix, endUnix)
+	},
+}
+
+var keyUserActionEntity = queriedEntity{
+	prefix:     "KUA;",
+	name:       "key user actions",
+	entityType: dynatrace.ApplicationMethodEntityType,
+	dimension:  "dt.entity.application_method",
+	measures: map[string]entityMeasure{
+		"apdex":       {metricKey: "builtin:apps.web.action.apdex", aggregation: "avg"},
+		"duration":    {metricKey: "builtin:apps.web.action.duration.load.browser", metricUnit: "MicroSecond", aggregation: "avg", mergedDimensions: []string{"dt.entity.browser"}},
+		"xhrDuration": {metricKey: "builtin:apps.web.action.duration.xhr.browser", metricUnit: "MicroSecond", aggregation: "avg", mergedDimensions: []string{"dt.entity.browser"}},
+	},
+	count: func(client dynatrace.ClientInterface, entitySelector string, startUnix time.Time, endUnix time.Time) (int, error) {
+		return dynatrace.NewApplicationsClient(client).CountKeyUserActionsByEntitySelector(entitySelector, startUnix, endUnix)
+	},
+}
+
+// executeEntityQuery evaluates <prefix><measure>[:<aggregation>];<entitySelector>, e.g. HOST;cpu;tag(keptn_stage:$STAGE).
+// It checks that entities match the entity selector in the timeframe and queries the built-in metric of the measure aggregated over all of them
+func (p *Processing) executeEntityQuery(entity queriedEntity, sliQuery string, startUnix time.Time, endUnix time.Time) (float64, error) {
+	metricsQuery, measure, entitySelector, err := p.buildEntityMetricsQuery(entity, sliQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := entity.count(p.client, entitySelector, startUnix, endUnix)
+	if err != nil {
+		return 0, fmt.Errorf("could not look up %s: %w", entity.name, err)
+	}
+	if count == 0 {
+		return 0, fmt.Errorf("no %s match the entity selector %s in the evaluation timeframe", entity.name, entitySelector)
+	}
+
+	return p.executeMetricsQuery(metricsQuery, measure.metricUnit, startUnix, endUnix)
+}
+
+// buildEntityMetricsQuery returns the metrics query of an entity query as well as its measure and entity selector with the placeholders replaced
+func (p *Processing) buildEntityMetricsQuery(entity queriedEntity, sliQuery string) (string, *entityMeasure, string, error) {
+	querySplits := strings.SplitN(strings.TrimPrefix(sliQuery, entity.prefix), ";", 2)
+	if len(querySplits) != 2 {
+		return "", nil, "", createEntityQueryFormatError(entity, sliQuery)
+	}
+
+	measureName, aggregation := querySplits[0], ""
+	if i := strings.Index(measureName, ":"); i >= 0 {
+		measureName, aggregation = measureName[:i], measureName[i+1:]
+	}
+
+	measure, ok := entity.measures[measureName]
+	if !ok {
+		return "", nil, "", fmt.Errorf("unsupported measure '%s' of %s query, expected one of: %s", measureName, strings.TrimSuffix(entity.prefix, ";"), strings.Join(getMeasureNames(entity), ", "))
+	}
+	if aggregation == "" {
+		aggregation = measure.aggregation
+	}
+	if aggregation != "avg" && aggregation != "max" && aggregation != "min" {
+		return "", nil, "", fmt.Errorf("unsupported aggregation '%s' of %s query, expected one of: avg, max, min", aggregation, strings.TrimSuffix(entity.prefix, ";"))
+	}
+
+	entitySelector := common.ReplaceQueryParameters(querySplits[1], p.customFilters, p.eventData)
+	if entitySelector == "" {
+		return "", nil, "", createEntityQueryFormatError(entity, sliQuery)
+	}
+
+	metricSelector := measure.metricKey
+	for _, dimension := range append(measure.mergedDimensions, entity.dimension) {
+		metricSelector += fmt.Sprintf(":merge(\"%s\")", dimension)
+	}
+
+	q := url.Values{}
+	q.Set("metricSelector", metricSelector+":"+aggregation)
+	q.Set("entitySelector", fmt.Sprintf("type(\"%s\"),%s", entity.entityType, entitySelector))
+	return q.Encode(), &measure, entitySelector, nil
+}
+
+func getMeasureNames(entity queriedEntity) []string {
+	names := make([]string, 0, len(entity.measures))
+	for name := range entity.measures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func createEntityQueryFormatError(entity queriedEntity, sliQuery string) error {
+	return fmt.Errorf("could not parse SLI definition format - should be '%s<measure>[:<aggregation>];<entitySelector>': %s", entity.prefix, sliQuery)
+}
@@ -0,0 +1,140 @@
+package query
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func createEntityCountURL(entitySelector string, start time.Time, end time.Time) string {
+	q := url.Values{}
+	q.Add("entitySelector", entitySelector)
+	q.Add("pageSize", "1")
+	query := q.Encode()
+
+	q = url.Values{}
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+	return "/api/v2/entities?" + query + "&" + q.Encode()
+}
+
+func createEntityMetricsQueryURL(metricSelector string, entitySelector string, start time.Time, end time.Time) string {
+	q := url.Values{}
+	q.Add("metricSelector", metricSelector)
+	q.Add("entitySelector", entitySelector)
+	q.Add("resolution", "Inf")
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+	return metricAPIURL + "?" + q.Encode()
+}
+
+func TestGetSLIValueWithEntityQuery(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	end := start.Add(10 * time.Minute)
+
+	tests := []struct {
+		name                string
+		query               string
+		entityCountURL      string
+		entityCountResponse string
+		metricsQueryURL     string
+		metricsResponse     string
+		expectedValue       float64
+		expectedErr         string
+	}{
+		{
+			name:                "peak CPU usage of hosts",
+			query:               "HOST;cpu;tag(keptn_stage:$STAGE)",
+			entityCountURL:      createEntityCountURL(`type("HOST"),tag(keptn_stage:dev)`, start, end),
+			entityCountResponse: `{"totalCount":2,"pageSize":1,"nextPageKey":"page2","entities":[{"entityId":"HOST-1","displayName":"node-1"}]}`,
+			metricsQueryURL:     createEntityMetricsQueryURL(`builtin:host.cpu.usage:merge("dt.entity.host"):max`, `type("HOST"),tag(keptn_stage:dev)`, start, end),
+			metricsResponse:     `{"result":[{"metricId":"builtin:host.cpu.usage:merge(\"dt.entity.host\"):max","data":[{"dimensions":[],"timestamps":[1],"values":[87.5]}]}]}`,
+			expectedValue:       87.5,
+		},
+		{
+			name:                "average memory of process group instances",
+			query:               "PGI;memory:avg;tag(keptn_service:$SERVICE)",
+			entityCountURL:      createEntityCountURL(`type("PROCESS_GROUP_INSTANCE"),tag(keptn_service:carts)`, start, end),
+			entityCountResponse: `{"totalCount":1,"pageSize":1,"entities":[{"entityId":"PROCESS_GROUP_INSTANCE-1","displayName":"carts"}]}`,
+			metricsQueryURL:     createEntityMetricsQueryURL(`builtin:tech.generic.mem.workingSetSize:merge("dt.entity.process_group_instance"):avg`, `type("PROCESS_GROUP_INSTANCE"),tag(keptn_service:carts)`, start, end),
+			metricsResponse:     `{"result":[{"metricId":"builtin:tech.generic.mem.workingSetSize:merge(\"dt.entity.process_group_instance\"):avg","data":[{"dimensions":[],"timestamps":[1],"values":[524288]}]}]}`,
+			expectedValue:       512,
+		},
+		{
+			name:                "Apdex of applications",
+			query:               "APP;apdex;entityName.equals(sockshop-$STAGE)",
+			entityCountURL:      createEntityCountURL(`type("APPLICATION"),entityName.equals(sockshop-dev)`, start, end),
+			entityCountResponse: `{"totalCount":1,"pageSize":1,"entities":[{"entityId":"APPLICATION-1","displayName":"sockshop-dev"}]}`,
+			metricsQueryURL:     createEntityMetricsQueryURL(`builtin:apps.web.apdex.userType:merge("User type"):merge("dt.entity.application"):avg`, `type("APPLICATION"),entityName.equals(sockshop-dev)`, start, end),
+			metricsResponse:     `{"result":[{"metricId":"builtin:apps.web.apdex.userType:merge(\"User type\"):merge(\"dt.entity.application\"):avg","data":[{"dimensions":[],"timestamps":[1],"values":[0.94]}]}]}`,
+			expectedValue:       0.94,
+		},
+		{
+			name:                "peak duration of key user actions",
+			query:               "KUA;duration:max;fromRelationships.isApplicationMethodOf(type(APPLICATION),entityName.equals(sockshop-$STAGE))",
+			entityCountURL:      createEntityCountURL(`type("APPLICATION_METHOD"),fromRelationships.isApplicationMethodOf(type(APPLICATION),entityName.equals(sockshop-dev))`, start, end),
+			entityCountResponse: `{"totalCount":1,"pageSize":1,"entities":[{"entityId":"APPLICATION_METHOD-1","displayName":"Loading of page /checkout"}]}`,
+			metricsQueryURL:     createEntityMetricsQueryURL(`builtin:apps.web.action.duration.load.browser:merge("dt.entity.browser"):merge("dt.entity.application_method"):max`, `type("APPLICATION_METHOD"),fromRelationships.isApplicationMethodOf(type(APPLICATION),entityName.equals(sockshop-dev))`, start, end),
+			metricsResponse:     `{"result":[{"metricId":"builtin:apps.web.action.duration.load.browser:merge(\"dt.entity.browser\"):merge(\"dt.entity.application_method\"):max","data":[{"dimensions":[],"timestamps":[1],"values":[1250000]}]}]}`,
+			expectedValue:       1250,
+		},
+		{
+			name:                "no matching hosts",
+			query:               "HOST;cpu;tag(keptn_stage:$STAGE)",
+			entityCountURL:      createEntityCountURL(`type("HOST"),tag(keptn_stage:dev)`, start, end),
+			entityCountResponse: `{"totalCount":0,"pageSize":1,"entities":[]}`,
+			expectedErr:         "no hosts match the entity selector tag(keptn_stage:dev) in the evaluation timeframe",
+		},
+		{
+			name:        "unsupported measure",
+			query:       "HOST;gc;tag(keptn_stage:$STAGE)",
+			expectedErr: "unsupported measure 'gc' of HOST query, expected one of: cpu, disk, load, memory",
+		},
+		{
+			name:        "unsupported measure of key user actions",
+			query:       "KUA;actions;entityName.equals(checkout)",
+			expectedErr: "unsupported measure 'actions' of KUA query, expected one of: apdex, duration, xhrDuration",
+		},
+		{
+			name:        "unsupported aggregation",
+			query:       "PGI;cpu:sum;tag(keptn_service:$SERVICE)",
+			expectedErr: "unsupported aggregation 'sum' of PGI query, expected one of: avg, max, min",
+		},
+		{
+			name:        "missing entity selector",
+			query:       "HOST;cpu",
+			expectedErr: "could not parse SLI definition format - should be 'HOST;<measure>[:<aggregation>];<entitySelector>': HOST;cpu",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			if tt.entityCountURL != "" {
+				handler.AddExact(tt.entityCountURL, []byte(tt.entityCountResponse))
+			}
+			if tt.metricsQueryURL != "" {
+				handler.AddExact(tt.metricsQueryURL, []byte(tt.metricsResponse))
+			}
+
+			httpClient, teardown := test.CreateHTTPClient(handler)
+			defer teardown()
+
+			p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(map[string]string{"entity_sli": tt.query}), start, end)
+
+			value, err := p.GetSLIValue("entity_sli")
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedValue, value)
+			assert.Len(t, p.GetSLIRequests("entity_sli"), 2)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	timeshiftModifier = "timeshift"
+	compareModifier   = "compare"
+
+	// compareRatio divides the value of the SLI's timeframe by the value of the shifted timeframe
+	compareRatio = "ratio"
+)
+
+var timeshiftPattern = regexp.MustCompile(`^-(\d+)([mhdw])$`)
+
+// queryModifiers are the SLI query modifiers that are handled by the dynatrace-service rather than passed on to the Dynatrace API.
+// timeshift=-1w evaluates the metric over the same timeframe one week earlier, adding compare=ratio evaluates the metric over
+// both timeframes and returns the value of the timeframe divided by the value of the shifted timeframe
+type queryModifiers struct {
+	timeshift time.Duration
+	compare   string
+}
+
+// extractQueryModifiers removes the query modifiers from a metrics query and returns the remaining query and the modifiers
+func extractQueryModifiers(metricsQuery string) (string, *queryModifiers, error) {
+	modifiers := &queryModifiers{}
+
+	var remainingParams []string
+	for _, param := range strings.Split(metricsQuery, "&") {
+		switch {
+		case strings.HasPrefix(param, timeshiftModifier+"="):
+			timeshift, err := parseTimeshift(strings.TrimPrefix(param, timeshiftModifier+"="))
+			if err != nil {
+				return "", nil, err
+			}
+			modifiers.timeshift = timeshift
+
+		case strings.HasPrefix(param, compareModifier+"="):
+			compare := strings.TrimPrefix(param, compareModifier+"=")
+			if compare != compareRatio {
+				return "", nil, fmt.Errorf("unsupported value of query modifier %s: %s - supported is '%s'", compareModifier, compare, compareRatio)
+			}
+			modifiers.compare = compare
+
+		default:
+			remainingParams = append(remainingParams, param)
+		}
+	}
+
+	if modifiers.compare != "" && modifiers.timeshift == 0 {
+		return "", nil, fmt.Errorf("query modifier %s requires a %s", compareModifier, timeshiftModifier)
+	}
+
+	return strings.Join(remainingParams, "&"), modifiers, nil
+}
+
+// parseTimeshift parses a negative shift such as -30m, -2h, -1d or -1w
+func parseTimeshift(value string) (time.Duration, error) {
+	chunks := timeshiftPattern.FindStringSubmatch(value)
+	if len(chunks) != 3 {
+		return 0, fmt.Errorf("could not parse query modifier %s: %s - should be a negative shift like -30m, -2h, -1d or -1w", timeshiftModifier, value)
+	}
+
+	amount, err := strconv.Atoi(chunks[1])
+	if err != nil {
+		return 0, fmt.Errorf("could not parse query modifier %s: %w", timeshiftModifier, err)
+	}
+
+	unit := time.Minute
+	switch chunks[2] {
+	case "h":
+		unit = time.Hour
+	case "d":
+		unit = 24 * time.Hour
+	case "w":
+		unit = 7 * 24 * time.Hour
+	}
+
+	return -time.Duration(amount) * unit, nil
+}
+
+// isComparison returns whether the SLI value compares the timeframe with the shifted timeframe
+func (m *queryModifiers) isComparison() bool {
+	return m.compare != ""
+}
+
+// compareValues combines the value of the SLI's timeframe with the value of the shifted timeframe
+func (m *queryModifiers) compareValues(value float64, shiftedValue float64) (float64, error) {
+	if shiftedValue == 0 {
+		return 0, fmt.Errorf("could not compute %s: value of timeframe shifted by %s is 0", m.compare, m.timeshift)
+	}
+
+	return value / shiftedValue, nil
+}
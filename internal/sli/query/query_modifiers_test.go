@@ -0,0 +1,65 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractQueryModifiers(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		wantQuery     string
+		wantModifiers *queryModifiers
+		wantErr       bool
+	}{
+		{
+			name:          "no modifiers",
+			query:         "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			wantQuery:     "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			wantModifiers: &queryModifiers{},
+		},
+		{
+			name:          "timeshift",
+			query:         "metricSelector=builtin:service.response.time&timeshift=-2h&entitySelector=type(SERVICE)",
+			wantQuery:     "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			wantModifiers: &queryModifiers{timeshift: -2 * time.Hour},
+		},
+		{
+			name:          "timeshift with ratio comparison",
+			query:         "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)&timeshift=-1w&compare=ratio",
+			wantQuery:     "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			wantModifiers: &queryModifiers{timeshift: -7 * 24 * time.Hour, compare: compareRatio},
+		},
+		{
+			name:    "positive timeshift",
+			query:   "metricSelector=builtin:service.response.time&timeshift=1d",
+			wantErr: true,
+		},
+		{
+			name:    "unsupported comparison",
+			query:   "metricSelector=builtin:service.response.time&timeshift=-1d&compare=difference",
+			wantErr: true,
+		},
+		{
+			name:    "comparison without timeshift",
+			query:   "metricSelector=builtin:service.response.time&compare=ratio",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, modifiers, err := extractQueryModifiers(tt.query)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+			assert.Equal(t, tt.wantModifiers, modifiers)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"github.com/keptn-contrib/dynatrace-service/internal/sli/metrics"
@@ -16,29 +17,134 @@ import (
 	"time"
 )
 
+// NoDataError is returned if the Dynatrace Metrics API returned no data points for an SLI query, e.g. because the data of a
+// recent deployment has not been processed yet
+type NoDataError struct {
+	message string
+}
+
+func (e *NoDataError) Error() string {
+	return e.message
+}
+
 type Processing struct {
-	client        dynatrace.ClientInterface
+	client        *requestRecordingClient
 	eventData     adapter.EventContentAdapter
 	customFilters []*keptnv2.SLIFilter
 	customQueries *keptn.CustomQueries
 	startUnix     time.Time
 	endUnix       time.Time
+
+	// values caches the values of indicators referenced by calculated SLIs, calculating detects circular references
+	values      map[string]float64
+	calculating map[string]bool
+
+	// requests holds the URLs of the Dynatrace API requests sent for each indicator
+	requests map[string][]string
+
+	// dataCoverage enables checking the data coverage of the evaluation timeframe for metrics SLIs if set
+	dataCoverage *config.SLIDataCoverage
+	// warnings holds the warnings of all queries, indicatorWarnings those of each indicator
+	warnings          []string
+	indicatorWarnings map[string][]string
+
+	// validateTimeframes enables validating the timeframe of metrics SLIs against the metadata of their metrics, which is cached in metricMetadata
+	validateTimeframes bool
+	metricMetadata     map[string]*dynatrace.MetricDefinition
+	now                func() time.Time
 }
 
 func NewProcessing(client dynatrace.ClientInterface, eventData adapter.EventContentAdapter, customFilters []*keptnv2.SLIFilter, customQueries *keptn.CustomQueries, startUnix time.Time, endUnix time.Time) *Processing {
 	return &Processing{
-		client:        client,
+		client:        &requestRecordingClient{ClientInterface: client},
 		eventData:     eventData,
 		customFilters: customFilters,
 		customQueries: customQueries,
 		startUnix:     startUnix,
 		endUnix:       endUnix,
+		values:        make(map[string]float64),
+		calculating:   make(map[string]bool),
+		requests:      make(map[string][]string),
+
+		indicatorWarnings: make(map[string][]string),
+		metricMetadata:    make(map[string]*dynatrace.MetricDefinition),
+		now:               time.Now,
 	}
 }
 
+// WithDataCoverageCheck makes the processing check how much of the evaluation timeframe is covered by the data points of metrics SLIs
+func (p *Processing) WithDataCoverageCheck(dataCoverage *config.SLIDataCoverage) *Processing {
+	p.dataCoverage = dataCoverage
+	return p
+}
+
+// WithTimeframeValidation makes the processing validate the timeframe of metrics SLIs against the metadata of their metrics if enabled,
+// returning a TimeframeError instead of no data if the timeframe exceeds the retention of the metric data
+func (p *Processing) WithTimeframeValidation(enabled bool) *Processing {
+	p.validateTimeframes = enabled
+	return p
+}
+
 // GetSLIValue queries a single metric value from Dynatrace API.
 // Can handle both Metric Queries as well as USQL
 func (p *Processing) GetSLIValue(name string) (float64, error) {
+	if value, ok := p.values[name]; ok {
+		// make the requests of cached indicators part of the requests of calculated SLIs referencing them
+		p.client.requests = append(p.client.requests, p.requests[name]...)
+		p.warnings = append(p.warnings, p.indicatorWarnings[name]...)
+		return value, nil
+	}
+
+	firstRequest := len(p.client.requests)
+	firstWarning := len(p.warnings)
+	value, err := p.querySLIValue(name)
+	p.requests[name] = uniqueStrings(p.client.requests[firstRequest:])
+	p.indicatorWarnings[name] = uniqueStrings(p.warnings[firstWarning:])
+	if err != nil {
+		return 0, err
+	}
+
+	p.values[name] = value
+	return value, nil
+}
+
+// IsCalculated returns whether the indicator is a calculated SLI, whose value depends on the values of the indicators it references
+func (p *Processing) IsCalculated(name string) bool {
+	sliQuery, err := p.customQueries.GetQueryByNameOrDefaultIfEmpty(name)
+	return err == nil && strings.HasPrefix(sliQuery, "CALC;")
+}
+
+// AddSLIValue makes the value of an indicator queried by another processing available to the calculated SLIs referencing it, so that it is not queried again
+func (p *Processing) AddSLIValue(name string, value float64, requests []string, warnings []string) {
+	p.values[name] = value
+	p.requests[name] = requests
+	p.indicatorWarnings[name] = warnings
+}
+
+// GetSLIRequests returns the URLs of the Dynatrace API requests sent by the last call of GetSLIValue for the indicator
+func (p *Processing) GetSLIRequests(name string) []string {
+	return p.requests[name]
+}
+
+// GetSLIWarnings returns the warnings about the data returned for the last call of GetSLIValue for the indicator, e.g. if it does not cover the whole evaluation timeframe
+func (p *Processing) GetSLIWarnings(name string) []string {
+	return p.indicatorWarnings[name]
+}
+
+// uniqueStrings returns a copy of the requests or warnings without duplicates, e.g. of indicators referenced several times by a calculated SLI
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
+
+func (p *Processing) querySLIValue(name string) (float64, error) {
 	// first we get the query from the SLI configuration based on its logical name
 	// no default values here anymore if indicator could not be matched (e.g. due to a misspelling) and custom SLIs were defined
 	sliQuery, err := p.customQueries.GetQueryByNameOrDefaultIfEmpty(name)
@@ -63,11 +169,33 @@ func (p *Processing) GetSLIValue(name string) (float64, error) {
 		return p.executeSecurityProblemQuery(sliQuery, p.startUnix, p.endUnix)
 	case strings.HasPrefix(sliQuery, "MV2;"):
 		return p.executeMetricsV2Query(sliQuery, p.startUnix, p.endUnix)
+	case strings.HasPrefix(sliQuery, hostEntity.prefix):
+		return p.executeEntityQuery(hostEntity, sliQuery, p.startUnix, p.endUnix)
+	case strings.HasPrefix(sliQuery, processGroupInstanceEntity.prefix):
+		return p.executeEntityQuery(processGroupInstanceEntity, sliQuery, p.startUnix, p.endUnix)
+	case strings.HasPrefix(sliQuery, applicationEntity.prefix):
+		return p.executeEntityQuery(applicationEntity, sliQuery, p.startUnix, p.endUnix)
+	case strings.HasPrefix(sliQuery, keyUserActionEntity.prefix):
+		return p.executeEntityQuery(keyUserActionEntity, sliQuery, p.startUnix, p.endUnix)
+	case strings.HasPrefix(sliQuery, "CALC;"):
+		return p.executeCalculatedQuery(name, sliQuery)
 	default:
 		return p.executeMetricsQuery(sliQuery, "", p.startUnix, p.endUnix)
 	}
 }
 
+// executeCalculatedQuery evaluates CALC;<expression>, where the expression references other indicators of the SLI configuration
+func (p *Processing) executeCalculatedQuery(name string, sliQuery string) (float64, error) {
+	if p.calculating[name] {
+		return 0, fmt.Errorf("calculated SLI '%s' has a circular reference to itself", name)
+	}
+
+	p.calculating[name] = true
+	defer delete(p.calculating, name)
+
+	return evaluateCalculation(strings.TrimPrefix(sliQuery, "CALC;"), p.GetSLIValue)
+}
+
 // USQL query
 func (p *Processing) executeUSQLQuery(metricsQuery string, startUnix time.Time, endUnix time.Time) (float64, error) {
 	// In this case we need to parse USQL;TILE_TYPE;DIMENSION;QUERY
@@ -201,13 +329,53 @@ func createMV2FormatError(query string) error {
 	return fmt.Errorf("could not parse SLI definition format - should either be 'MV2;Byte;<query>' or 'MV2;MicroSecond;<query>': %s", query)
 }
 
+// executeMetricsQuery applies the query modifiers and queries the metric either over the (shifted) timeframe or over both
+// the timeframe and the shifted timeframe to compare them
 func (p *Processing) executeMetricsQuery(metricsQuery string, metricUnit string, startUnix time.Time, endUnix time.Time) (float64, error) {
+	metricsQuery, modifiers, err := extractQueryModifiers(metricsQuery)
+	if err != nil {
+		return 0, err
+	}
+
+	shiftedStartUnix := startUnix.Add(modifiers.timeshift)
+	shiftedEndUnix := endUnix.Add(modifiers.timeshift)
+	if !modifiers.isComparison() {
+		return p.executeMetricsQueryForTimeframe(metricsQuery, metricUnit, shiftedStartUnix, shiftedEndUnix)
+	}
+
+	value, err := p.executeMetricsQueryForTimeframe(metricsQuery, metricUnit, startUnix, endUnix)
+	if err != nil {
+		return 0, err
+	}
+
+	shiftedValue, err := p.executeMetricsQueryForTimeframe(metricsQuery, metricUnit, shiftedStartUnix, shiftedEndUnix)
+	if err != nil {
+		return 0, fmt.Errorf("could not query timeframe shifted by %s: %w", modifiers.timeshift, err)
+	}
+
+	return modifiers.compareValues(value, shiftedValue)
+}
+
+func (p *Processing) executeMetricsQueryForTimeframe(metricsQuery string, metricUnit string, startUnix time.Time, endUnix time.Time) (float64, error) {
 
 	metricsQuery, metricSelector, err := metrics.NewQueryBuilder(p.eventData, p.customFilters).Build(metricsQuery, startUnix, endUnix)
 	if err != nil {
 		return 0, err
 	}
-	result, err := dynatrace.NewMetricsClient(p.client).GetByQuery(metricsQuery)
+
+	if p.validateTimeframes {
+		metricsQuery, err = p.validateTimeframe(metricsQuery, metricSelector, startUnix, endUnix)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	// a single series is expected, so decoding can stop once a second one has been found
+	result, err := dynatrace.NewMetricsClient(p.client).GetByQueryWithOptions(metricsQuery,
+		dynatrace.MetricsQueryOptions{
+			IsWantedMetricID: func(metricID string) bool { return IsMatchingMetricID(metricID, metricSelector) },
+			MaxSeries:        1,
+		})
 
 	if err != nil {
 		return 0, fmt.Errorf("Dynatrace Metrics API returned an error: %s. This was the query executed: %s", err.Error(), metricsQuery)
@@ -222,14 +390,27 @@ func (p *Processing) executeMetricsQuery(metricsQuery string, metricUnit string,
 			if len(i.Data) != 1 {
 				if len(i.Data) == 0 {
 					if len(i.Warnings) > 0 {
-						return 0, fmt.Errorf("Warning: %s. Dynatrace Metrics API returned no result values, expected 1 for query: %s", strings.Join(i.Warnings, ", "), metricsQuery)
+						return 0, &NoDataError{message: fmt.Sprintf("Warning: %s. Dynatrace Metrics API returned no result values, expected 1 for query: %s", strings.Join(i.Warnings, ", "), metricsQuery)}
 					}
 
-					return 0, fmt.Errorf("Dynatrace Metrics API returned no result values, expected 1 for query: %s. Please ensure the response contains exactly one value", metricsQuery)
+					return 0, &NoDataError{message: fmt.Sprintf("Dynatrace Metrics API returned no result values, expected 1 for query: %s. Please ensure the response contains exactly one value", metricsQuery)}
+				}
+
+				// decoding stopped after the second value, but the response states the total number of values
+				resultValueCount := len(i.Data)
+				if result.Truncated && result.TotalCount > resultValueCount {
+					resultValueCount = result.TotalCount
 				}
 
 				jsonString, _ := json.Marshal(i)
-				return 0, fmt.Errorf("Dynatrace Metrics API returned %d result values, expected 1 for query: %s. Please ensure the response contains exactly one value (e.g., by using :merge(dimension_key):avg for the metric). Here is the output for troubleshooting: %s", len(i.Data), metricsQuery, string(jsonString))
+				return 0, fmt.Errorf("Dynatrace Metrics API returned %d result values, expected 1 for query: %s. Please ensure the response contains exactly one value (e.g., by using :merge(dimension_key):avg for the metric). Here is the output for troubleshooting: %s", resultValueCount, metricsQuery, string(jsonString))
+			}
+
+			if p.dataCoverage != nil {
+				err = p.checkDataCoverage(metricsQuery, metricSelector, startUnix, endUnix)
+				if err != nil {
+					return 0, err
+				}
 			}
 
 			return unit.ScaleData(metricSelector, metricUnit, i.Data[0].Values[0]), nil
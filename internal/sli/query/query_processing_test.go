@@ -3,10 +3,12 @@ package query
 import (
 	"errors"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
@@ -392,6 +394,78 @@ func TestGetSLIValueForIndicator(t *testing.T) {
 	}
 }
 
+// Tests that a ratio comparison queries both the timeframe and the timeframe shifted by one week
+func TestGetSLIValueWithTimeshiftComparison(t *testing.T) {
+	start := time.Unix(1571649084, 0).UTC()
+	end := time.Unix(1571649085, 0).UTC()
+	weekAgo := -7 * 24 * time.Hour
+
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact(createMetricsQueryURL(start, end), []byte(`{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1],"values":[300000]}]}]}`))
+	handler.AddExact(createMetricsQueryURL(start.Add(weekAgo), end.Add(weekAgo)), []byte(`{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1],"values":[200000]}]}]}`))
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	customQueries := map[string]string{
+		"rt_vs_last_week": "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)&timeshift=-1w&compare=ratio",
+	}
+	p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), start, end)
+
+	value, err := p.GetSLIValue("rt_vs_last_week")
+
+	assert.NoError(t, err)
+	assert.InDelta(t, 1.5, value, 0.001)
+}
+
+// Tests that calculated SLIs resolve the referenced indicators and detect circular references
+func TestGetSLIValueWithCalculatedSLI(t *testing.T) {
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddExact("/api/v2/problems?from=1571649084000&to=1571649085000&problemSelector=status(open)", []byte(`{"totalCount":3}`))
+	handler.AddExact("/api/v2/problems?from=1571649084000&to=1571649085000&problemSelector=status(closed)", []byte(`{"totalCount":9}`))
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	customQueries := map[string]string{
+		"open_problems":     "PV2;problemSelector=status(open)",
+		"closed_problems":   "PV2;problemSelector=status(closed)",
+		"open_problem_rate": "CALC;open_problems / (open_problems + closed_problems) * 100",
+		"cycle_a":           "CALC;cycle_b + 1",
+		"cycle_b":           "CALC;cycle_a + 1",
+	}
+	p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), time.Unix(1571649084, 0).UTC(), time.Unix(1571649085, 0).UTC())
+
+	value, err := p.GetSLIValue("open_problem_rate")
+	assert.NoError(t, err)
+	assert.InDelta(t, 25, value, 0.001)
+	assert.Equal(t,
+		[]string{
+			"http://dynatrace/api/v2/problems?from=1571649084000&to=1571649085000&problemSelector=status(open)",
+			"http://dynatrace/api/v2/problems?from=1571649084000&to=1571649085000&problemSelector=status(closed)",
+		},
+		p.GetSLIRequests("open_problem_rate"))
+
+	value, err = p.GetSLIValue("open_problems")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, value)
+
+	_, err = p.GetSLIValue("cycle_a")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "circular reference")
+	}
+}
+
+func createMetricsQueryURL(start time.Time, end time.Time) string {
+	q := url.Values{}
+	q.Add("metricSelector", "builtin:service.response.time")
+	q.Add("entitySelector", "type(SERVICE)")
+	q.Add("resolution", "Inf")
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+	return metricAPIURL + "?" + q.Encode()
+}
+
 func createQueryProcessing(keptnEvent adapter.EventContentAdapter, httpClient *http.Client, start time.Time, end time.Time) *Processing {
 	return createCustomQueryProcessing(
 		keptnEvent,
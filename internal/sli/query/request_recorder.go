@@ -0,0 +1,18 @@
+package query
+
+import (
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// requestRecordingClient records the URLs of the Dynatrace API requests sent to retrieve SLI values,
+// so that users can reproduce the values in their tenant
+type requestRecordingClient struct {
+	dynatrace.ClientInterface
+	requests []string
+}
+
+// Get records the URL and sends the request
+func (c *requestRecordingClient) Get(apiPath string) ([]byte, error) {
+	c.requests = append(c.requests, c.Credentials().Tenant+apiPath)
+	return c.ClientInterface.Get(apiPath)
+}
@@ -0,0 +1,143 @@
+package query
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// metricRetentionTier is how long Dynatrace retains metric data at a resolution
+type metricRetentionTier struct {
+	resolution string
+	retention  time.Duration
+}
+
+// metricRetentionTiers are the resolutions at which Dynatrace retains metric data, from the finest to the coarsest one
+var metricRetentionTiers = []metricRetentionTier{
+	{resolution: "1m", retention: 14 * 24 * time.Hour},
+	{resolution: "5m", retention: 28 * 24 * time.Hour},
+	{resolution: "1h", retention: 400 * 24 * time.Hour},
+	{resolution: "1d", retention: 5 * 365 * 24 * time.Hour},
+}
+
+// TimeframeError is returned if a metric can have no data points for the timeframe of an SLI query, e.g. because the timeframe exceeds
+// the retention of the metric data or the metric did not exist yet
+type TimeframeError struct {
+	message string
+}
+
+func (e *TimeframeError) Error() string {
+	return e.message
+}
+
+// validateTimeframe checks the timeframe of a metrics query built by the metrics.QueryBuilder against the retention tiers and the metadata of the queried metric before it is executed.
+// It returns a TimeframeError if the metric can have no data points for the timeframe. If the metric does not support resolution=Inf, the query is adjusted
+// to fold the data points of the timeframe into a single one instead, using the finest resolution still retained for the start of the timeframe
+func (p *Processing) validateTimeframe(metricsQuery string, metricSelector string, startUnix time.Time, endUnix time.Time) (string, error) {
+	metricKey := getMetricKey(metricSelector)
+	if metricKey == "" {
+		return metricsQuery, nil
+	}
+
+	tier, ok := getMetricRetentionTier(startUnix, p.now())
+	if !ok {
+		retentionStart := p.now().Add(-metricRetentionTiers[len(metricRetentionTiers)-1].retention)
+		return "", &TimeframeError{message: fmt.Sprintf("timeframe exceeds retention for metric %s: the timeframe starts at %s, but metric data is only retained since %s", metricKey, formatTimestamp(startUnix), formatTimestamp(retentionStart))}
+	}
+
+	metadata, err := p.getMetricMetadata(metricKey)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve metadata of metric %s to validate the timeframe: %w", metricKey, err)
+	}
+
+	if metadata.Created != nil && endUnix.Before(millisecondsToTime(*metadata.Created)) {
+		return "", &TimeframeError{message: fmt.Sprintf("timeframe ends before metric %s was created at %s", metricKey, formatTimestamp(millisecondsToTime(*metadata.Created)))}
+	}
+
+	if metadata.ResolutionInfSupported == nil || *metadata.ResolutionInfSupported {
+		return metricsQuery, nil
+	}
+
+	foldedMetricsQuery, err := getFoldedMetricsQuery(metricsQuery, tier.resolution)
+	if err != nil {
+		return "", err
+	}
+
+	if foldedMetricsQuery != metricsQuery {
+		p.warnings = append(p.warnings, fmt.Sprintf("Warning: metric %s does not support resolution=Inf, its data points at resolution %s were folded instead", metricKey, tier.resolution))
+	}
+	return foldedMetricsQuery, nil
+}
+
+// getMetricRetentionTier returns the tier of the finest resolution at which metric data of a timeframe starting at startUnix is still retained, or false if it is not retained at all
+func getMetricRetentionTier(startUnix time.Time, now time.Time) (metricRetentionTier, bool) {
+	for _, tier := range metricRetentionTiers {
+		if !startUnix.Before(now.Add(-tier.retention)) {
+			return tier, true
+		}
+	}
+	return metricRetentionTier{}, false
+}
+
+// getMetricMetadata returns the metadata of the metric, which is cached for the processing, e.g. for metrics queried by several SLIs.
+// The request is not recorded, as it does not contribute to the SLI value
+func (p *Processing) getMetricMetadata(metricKey string) (*dynatrace.MetricDefinition, error) {
+	if metadata, ok := p.metricMetadata[metricKey]; ok {
+		return metadata, nil
+	}
+
+	metadata, err := dynatrace.NewMetricsClient(p.client.ClientInterface).GetMetadataByID(metricKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p.metricMetadata[metricKey] = metadata
+	return metadata, nil
+}
+
+// getFoldedMetricsQuery returns the metrics query with the resolution instead of resolution=Inf and the data points folded into a single one
+func getFoldedMetricsQuery(metricsQuery string, resolution string) (string, error) {
+	q, err := url.ParseQuery(metricsQuery)
+	if err != nil {
+		return "", err
+	}
+
+	if q.Get("resolution") != "Inf" {
+		return metricsQuery, nil
+	}
+
+	q.Set("resolution", resolution)
+	q.Set("metricSelector", q.Get("metricSelector")+":fold")
+	return q.Encode(), nil
+}
+
+// getMetricKey returns the key of the metric queried by a metric selector, e.g. builtin:service.response.time for
+// builtin:service.response.time:merge("dt.entity.service"):percentile(50), or an empty string if the selector combines several metrics.
+// Transformations are recognized as they, unlike the segments of a metric key, contain no dot
+func getMetricKey(metricSelector string) string {
+	segments := strings.Split(metricSelector, ":")
+	if segments[0] == "" || strings.ContainsAny(segments[0], "(),+*/ ") {
+		return ""
+	}
+
+	metricKey := segments[0]
+	for _, segment := range segments[1:] {
+		if !strings.Contains(segment, ".") || strings.ContainsAny(segment, "(),") {
+			break
+		}
+		metricKey += ":" + segment
+	}
+	return metricKey
+}
+
+// millisecondsToTime converts a timestamp of the Dynatrace API in milliseconds since the epoch
+func millisecondsToTime(timestamp int64) time.Time {
+	return time.Unix(0, timestamp*int64(time.Millisecond)).UTC()
+}
+
+func formatTimestamp(timestamp time.Time) string {
+	return timestamp.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,197 @@
+package query
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/common"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+	"github.com/stretchr/testify/assert"
+)
+
+const responseTimeMetadataURL = "/api/v2/metrics/builtin:service.response.time?fields="
+
+func TestGetMetricKey(t *testing.T) {
+	tests := []struct {
+		metricSelector string
+		want           string
+	}{
+		{metricSelector: "builtin:service.response.time", want: "builtin:service.response.time"},
+		{metricSelector: "builtin:service.response.time:merge(\"dt.entity.service\"):percentile(50)", want: "builtin:service.response.time"},
+		{metricSelector: "builtin:service.errors.total.rate:avg", want: "builtin:service.errors.total.rate"},
+		{metricSelector: "jmeter.usermetrics.transaction.meantime:max", want: "jmeter.usermetrics.transaction.meantime"},
+		{metricSelector: "calc:service.checkout-duration", want: "calc:service.checkout-duration"},
+		{metricSelector: "(builtin:service.errors.total.count:value)/(builtin:service.requestCount.total:value)", want: ""},
+		{metricSelector: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metricSelector, func(t *testing.T) {
+			assert.Equal(t, tt.want, getMetricKey(tt.metricSelector))
+		})
+	}
+}
+
+// Tests that the timeframe of metrics SLIs is validated against the metadata of their metrics if enabled
+func TestGetSLIValueWithTimeframeValidation(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	end := start.Add(10 * time.Minute)
+	value := []byte(`{"result":[{"metricId":"builtin:service.response.time","data":[{"dimensions":[],"timestamps":[1],"values":[300000]}]}]}`)
+
+	tests := []struct {
+		name             string
+		now              time.Time
+		metadataStatus   int
+		metadataResponse string
+		foldedResolution string
+		expectedWarnings []string
+		expectedErr      string
+	}{
+		{
+			name:             "timeframe within retention",
+			now:              end.Add(time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","resolutionInfSupported":true,"created":1500000000000}`,
+		},
+		{
+			name:             "timeframe exceeds retention",
+			now:              end.Add(6 * 365 * 24 * time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","resolutionInfSupported":true}`,
+			expectedErr:      "timeframe exceeds retention for metric builtin:service.response.time: the timeframe starts at 2019-10-21T09:10:00Z, but metric data is only retained since 2020-10-20T09:20:00Z",
+		},
+		{
+			name:             "timeframe ends before the metric was created",
+			now:              end.Add(time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","created":1600000000000}`,
+			expectedErr:      "timeframe ends before metric builtin:service.response.time was created at 2020-09-13T12:26:40Z",
+		},
+		{
+			name:             "data points are folded if resolution=Inf is not supported",
+			now:              end.Add(time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","resolutionInfSupported":false}`,
+			foldedResolution: "1m",
+			expectedWarnings: []string{"Warning: metric builtin:service.response.time does not support resolution=Inf, its data points at resolution 1m were folded instead"},
+		},
+		{
+			name:             "resolution of folded data points is adjusted to the retention tier of the timeframe",
+			now:              start.Add(20 * 24 * time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","resolutionInfSupported":false}`,
+			foldedResolution: "5m",
+			expectedWarnings: []string{"Warning: metric builtin:service.response.time does not support resolution=Inf, its data points at resolution 5m were folded instead"},
+		},
+		{
+			name:             "resolution of folded data points is adjusted to the coarsest retention tier",
+			now:              start.Add(2 * 365 * 24 * time.Hour),
+			metadataStatus:   http.StatusOK,
+			metadataResponse: `{"metricId":"builtin:service.response.time","resolutionInfSupported":false}`,
+			foldedResolution: "1d",
+			expectedWarnings: []string{"Warning: metric builtin:service.response.time does not support resolution=Inf, its data points at resolution 1d were folded instead"},
+		},
+		{
+			name:             "error if the metadata is not available",
+			now:              end.Add(time.Hour),
+			metadataStatus:   http.StatusNotFound,
+			metadataResponse: `{"error":{"code":404,"message":"Metric not found"}}`,
+			expectedErr:      "could not retrieve metadata of metric builtin:service.response.time to validate the timeframe",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := test.NewPayloadBasedURLHandler(t)
+			handler.AddStartsWithError(responseTimeMetadataURL, tt.metadataStatus, []byte(tt.metadataResponse))
+			if tt.foldedResolution != "" {
+				handler.AddExact(createFoldedMetricsQueryURL(start, end, tt.foldedResolution), value)
+			} else {
+				handler.AddExact(createMetricsQueryURL(start, end), value)
+			}
+
+			httpClient, teardown := test.CreateHTTPClient(handler)
+			defer teardown()
+
+			customQueries := map[string]string{
+				"response_time": "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+			}
+			p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), start, end).WithTimeframeValidation(true)
+			p.now = func() time.Time { return tt.now }
+
+			value, err := p.GetSLIValue("response_time")
+			if tt.expectedErr != "" {
+				if assert.Error(t, err) {
+					assert.Contains(t, err.Error(), tt.expectedErr)
+				}
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.EqualValues(t, 300, value)
+			assert.Equal(t, tt.expectedWarnings, p.GetSLIWarnings("response_time"))
+			assert.Len(t, p.GetSLIRequests("response_time"), 1)
+		})
+	}
+}
+
+func createFoldedMetricsQueryURL(start time.Time, end time.Time, resolution string) string {
+	q := url.Values{}
+	q.Add("metricSelector", "builtin:service.response.time:fold")
+	q.Add("entitySelector", "type(SERVICE)")
+	q.Add("resolution", resolution)
+	q.Add("from", common.TimestampToString(start))
+	q.Add("to", common.TimestampToString(end))
+	return "/api/v2/metrics/query?" + q.Encode()
+}
+
+// Tests that errors of the timeframe are returned as TimeframeError
+func TestGetSLIValueWithTimeframeValidation_ReturnsTimeframeError(t *testing.T) {
+	start := time.Unix(1571649000, 0).UTC()
+	end := start.Add(10 * time.Minute)
+
+	handler := test.NewPayloadBasedURLHandler(t)
+	handler.AddStartsWith(responseTimeMetadataURL, []byte(`{"metricId":"builtin:service.response.time","created":1600000000000}`))
+
+	httpClient, teardown := test.CreateHTTPClient(handler)
+	defer teardown()
+
+	customQueries := map[string]string{
+		"response_time": "metricSelector=builtin:service.response.time&entitySelector=type(SERVICE)",
+	}
+	p := createCustomQueryProcessing(createDefaultTestEventData(), httpClient, keptn.NewCustomQueries(customQueries), start, end).WithTimeframeValidation(true)
+	p.now = func() time.Time { return end.Add(time.Hour) }
+
+	_, err := p.GetSLIValue("response_time")
+
+	var timeframeErr *TimeframeError
+	assert.True(t, errors.As(err, &timeframeErr))
+}
+
+func TestGetMetricRetentionTier(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	const day = 24 * time.Hour
+
+	tests := []struct {
+		name               string
+		start              time.Time
+		expectedResolution string
+		expectedRetained   bool
+	}{
+		{name: "1 hour ago", start: now.Add(-time.Hour), expectedResolution: "1m", expectedRetained: true},
+		{name: "14 days ago", start: now.Add(-14 * day), expectedResolution: "1m", expectedRetained: true},
+		{name: "15 days ago", start: now.Add(-15 * day), expectedResolution: "5m", expectedRetained: true},
+		{name: "100 days ago", start: now.Add(-100 * day), expectedResolution: "1h", expectedRetained: true},
+		{name: "3 years ago", start: now.Add(-3 * 365 * day), expectedResolution: "1d", expectedRetained: true},
+		{name: "6 years ago", start: now.Add(-6 * 365 * day), expectedRetained: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier, retained := getMetricRetentionTier(tt.start, now)
+			assert.Equal(t, tt.expectedRetained, retained)
+			assert.Equal(t, tt.expectedResolution, tier.resolution)
+		})
+	}
+}
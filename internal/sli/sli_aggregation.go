@@ -0,0 +1,78 @@
+package sli
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// Tenant is a Dynatrace environment SLIs are queried from, identified by the name of its credentials
+type Tenant struct {
+	Name   string
+	Client dynatrace.ClientInterface
+}
+
+// getAggregatedSLIResult aggregates the values of the indicator queried from all tenants. The SLI fails if it could not be retrieved from one of the tenants,
+// as the aggregated value would be misleading otherwise
+func getAggregatedSLIResult(indicator string, tenantValues []tenantSLIValue, aggregation string) *keptnv2.SLIResult {
+	values := make([]float64, 0, len(tenantValues))
+	var messages []string
+	var errorMessages []string
+	for _, v := range tenantValues {
+		if v.err != nil {
+			log.WithError(v.err).WithField("tenant", v.tenant).Error("GetSLIValue failed")
+			errorMessages = append(errorMessages, fmt.Sprintf("%s: %s", v.tenant, appendRetries(v.err.Error(), v.retries)))
+			continue
+		}
+
+		values = append(values, v.value)
+		if v.retries > 0 || len(v.warnings) > 0 {
+			messages = append(messages, fmt.Sprintf("%s: %s", v.tenant, appendWarnings(appendRetries("", v.retries), v.warnings)))
+		}
+	}
+
+	if len(errorMessages) > 0 {
+		return &keptnv2.SLIResult{
+			Metric:  indicator,
+			Value:   0,
+			Success: false,
+			Message: strings.Join(errorMessages, "; "),
+		}
+	}
+
+	return &keptnv2.SLIResult{
+		Metric:  indicator,
+		Value:   aggregateSLIValues(aggregation, values),
+		Success: true,
+		Message: strings.Join(messages, "; "),
+	}
+}
+
+// aggregateSLIValues combines the values of an SLI queried from several tenants, using the sum if the aggregation is unknown
+func aggregateSLIValues(aggregation string, values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	result := values[0]
+	for _, value := range values[1:] {
+		switch aggregation {
+		case config.MaximumSLIAggregation:
+			result = math.Max(result, value)
+		case config.MinimumSLIAggregation:
+			result = math.Min(result, value)
+		default:
+			result += value
+		}
+	}
+
+	if aggregation == config.AverageSLIAggregation {
+		result /= float64(len(values))
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package sli
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/test"
+)
+
+func TestAggregateSLIValues(t *testing.T) {
+	values := []float64{12, 8, 4}
+	tests := []struct {
+		aggregation string
+		want        float64
+	}{
+		{aggregation: config.SumSLIAggregation, want: 24},
+		{aggregation: config.AverageSLIAggregation, want: 8},
+		{aggregation: config.MaximumSLIAggregation, want: 12},
+		{aggregation: config.MinimumSLIAggregation, want: 4},
+		{aggregation: "", want: 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.aggregation, func(t *testing.T) {
+			assert.EqualValues(t, tt.want, aggregateSLIValues(tt.aggregation, values))
+		})
+	}
+}
+
+// Tests that an SLI defined in the sli.yaml is queried from all tenants and that the values are aggregated, unless one of the tenants fails
+func TestSLIsAreAggregatedAcrossTenants(t *testing.T) {
+	metricsResponse := func(value string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"result":[{"metricId":"builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)","data":[{"dimensions":[],"timestamps":[1],"values":[` + value + `]}]}]}`))
+		})
+	}
+	errorResponse := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":{"code":403,"message":"Token is missing required scope"}}`))
+	})
+
+	tests := []struct {
+		name            string
+		aggregation     string
+		saasHandler     http.Handler
+		expectedValue   float64
+		expectedSuccess bool
+		expectedMessage string
+	}{
+		{
+			name:            "sum",
+			aggregation:     config.SumSLIAggregation,
+			saasHandler:     metricsResponse("8000"),
+			expectedValue:   20,
+			expectedSuccess: true,
+		},
+		{
+			name:            "avg",
+			aggregation:     config.AverageSLIAggregation,
+			saasHandler:     metricsResponse("8000"),
+			expectedValue:   10,
+			expectedSuccess: true,
+		},
+		{
+			name:            "max",
+			aggregation:     config.MaximumSLIAggregation,
+			saasHandler:     metricsResponse("8000"),
+			expectedValue:   12,
+			expectedSuccess: true,
+		},
+		{
+			name:            "one tenant fails",
+			aggregation:     config.SumSLIAggregation,
+			saasHandler:     errorResponse,
+			expectedSuccess: false,
+			expectedMessage: "dynatrace-saas: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kClient := &keptnClientMock{
+				customQueries: map[string]string{
+					indicator: "metricSelector=builtin:service.response.time:merge(\"dt.entity.service\"):percentile(95)&entitySelector=type(SERVICE),tag(keptn_project:sockshop),tag(keptn_stage:staging)",
+				},
+			}
+
+			ev := &getSLIEventData{
+				project:    "sockshop",
+				stage:      "staging",
+				service:    "carts",
+				indicators: []string{indicator},
+			}
+
+			eh, _, teardown := createGetSLIEventHandler(ev, metricsResponse("12000"), kClient)
+			defer teardown()
+
+			saasHTTPClient, saasURL, saasTeardown := test.CreateHTTPSClient(tt.saasHandler)
+			defer saasTeardown()
+			saasClient := dynatrace.NewClientWithHTTP(&credentials.DTCredentials{Tenant: saasURL, ApiToken: "test"}, saasHTTPClient)
+
+			handler := eh.WithAdditionalTenants([]Tenant{{Name: "dynatrace-saas", Client: saasClient}}, tt.aggregation)
+			factory, err := handler.HandleTask()
+			assert.NoError(t, err)
+			assert.NoError(t, kClient.SendCloudEvent(factory))
+
+			var data getSLIFinishedEventData
+			if !assert.NoError(t, json.Unmarshal(kClient.eventSink[0].Data(), &data)) || !assert.Len(t, data.GetSLI.IndicatorValues, 1) {
+				return
+			}
+
+			result := data.GetSLI.IndicatorValues[0]
+			assert.Equal(t, tt.expectedSuccess, result.Success)
+			assert.Contains(t, result.Message, tt.expectedMessage)
+			if tt.expectedSuccess {
+				assert.EqualValues(t, tt.expectedValue, result.Value)
+			}
+
+			if assert.Len(t, data.SLIRequests, 1) {
+				assert.Len(t, data.SLIRequests[0].Requests, 2)
+			}
+			assert.Equal(t, "dynatrace-saas", data.Labels["Additional DtCreds"])
+			assert.Equal(t, tt.aggregation, data.Labels["SLI Aggregation"])
+		})
+	}
+}
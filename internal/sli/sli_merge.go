@@ -0,0 +1,150 @@
+package sli
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	log "github.com/sirupsen/logrus"
+)
+
+// retrieveMergedSLIResults retrieves the SLI results from the Dynatrace dashboard and takes the SLIs it does not provide from the sli.yaml.
+// If both define an SLI, the source defined by sliMerge takes precedence. The dashboard and the objectives of both sources are stored,
+// but not the SLI definitions of the dashboard, so that the sli.yaml keeps its own
+func (eh *GetSLIEventHandler) retrieveMergedSLIResults(startUnix time.Time, endUnix time.Time) (map[string]string, []*keptnv2.SLIResult, []*SLIRequests, error) {
+	dashboardResult, err := eh.queryDynatraceDashboard(startUnix, endUnix)
+	if err != nil {
+		// log the error, but continue with the SLIs of the sli.yaml
+		log.WithError(err).Error("queryDynatraceDashboard failed")
+	}
+
+	labels := getDashboardLabels(dashboardResult)
+	if dashboardResult == nil || dashboardResult.SLIResults() == nil {
+		sliResults, sliRequests, err := eh.getSLIResultsFromCustomQueries(startUnix, endUnix)
+		if err != nil {
+			return labels, nil, nil, err
+		}
+		return labels, sliResults, sliRequests, nil
+	}
+
+	projectCustomQueries, err := eh.getCustomQueries()
+	if err != nil {
+		return labels, nil, nil, err
+	}
+
+	fileIndicators := getFileIndicators(eh.event.GetIndicators(), projectCustomQueries, dashboardResult.SLIResults(), eh.sliMerge)
+	log.WithField("indicators", fileIndicators).Info("Merging dashboard SLIs with SLIs defined in the sli.yaml")
+
+	var fileSLIResults []*keptnv2.SLIResult
+	var sliRequests []*SLIRequests
+	if len(fileIndicators) > 0 {
+		fileSLIResults, sliRequests = eh.getSLIResultsForIndicators(projectCustomQueries, fileIndicators, startUnix, endUnix)
+	}
+
+	slos := dashboardResult.SLO()
+	if slos != nil {
+		existingSLOs, err := eh.getExistingSLOs()
+		if err != nil {
+			// the objectives of the SLIs of the sli.yaml would be lost, so the objectives are not stored at all
+			log.WithError(err).Error("Could not retrieve SLOs to merge with the dashboard, not storing the SLOs")
+			slos = nil
+		} else {
+			slos = mergeSLOs(slos, existingSLOs, fileIndicators)
+		}
+	}
+
+	err = eh.resourceClient.UploadDashboardSLIAndSLOs(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService(), dashboardResult.Dashboard(), nil, slos)
+	if err != nil {
+		// log the error, but continue as the SLI results are available
+		log.WithError(err).Error("Could not store dashboard and merged SLOs")
+	}
+
+	return labels, mergeSLIResults(dashboardResult.SLIResults(), fileSLIResults), sliRequests, nil
+}
+
+// getExistingSLOs returns the SLOs stored before or nil if there are none
+func (eh *GetSLIEventHandler) getExistingSLOs() (*keptncommon.ServiceLevelObjectives, error) {
+	slos, err := eh.resourceClient.GetSLOs(eh.event.GetProject(), eh.event.GetStage(), eh.event.GetService())
+	if err != nil {
+		var rnfErr *keptn.ResourceNotFoundError
+		if errors.As(err, &rnfErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return slos, nil
+}
+
+// getFileIndicators returns the indicators to be queried using the sli.yaml, i.e. the ones defined in the sli.yaml unless the dashboard
+// takes precedence and also provides them
+func getFileIndicators(indicators []string, customQueries *keptn.CustomQueries, dashboardSLIResults []*keptnv2.SLIResult, precedence string) []string {
+	dashboardIndicators := make(map[string]bool, len(dashboardSLIResults))
+	for _, sliResult := range dashboardSLIResults {
+		dashboardIndicators[sliResult.Metric] = true
+	}
+
+	var fileIndicators []string
+	for _, indicator := range indicators {
+		if indicator == ProblemOpenSLI || !customQueries.IsDefined(indicator) {
+			continue
+		}
+		if precedence == config.DashboardSLIMerge && dashboardIndicators[indicator] {
+			continue
+		}
+		fileIndicators = append(fileIndicators, indicator)
+	}
+	return fileIndicators
+}
+
+// mergeSLIResults returns the SLI results of the dashboard with those also queried using the sli.yaml replaced, followed by the remaining ones of the sli.yaml
+func mergeSLIResults(dashboardSLIResults []*keptnv2.SLIResult, fileSLIResults []*keptnv2.SLIResult) []*keptnv2.SLIResult {
+	fileSLIResultsByMetric := make(map[string]*keptnv2.SLIResult, len(fileSLIResults))
+	for _, sliResult := range fileSLIResults {
+		fileSLIResultsByMetric[sliResult.Metric] = sliResult
+	}
+
+	sliResults := make([]*keptnv2.SLIResult, 0, len(dashboardSLIResults)+len(fileSLIResults))
+	for _, sliResult := range dashboardSLIResults {
+		if fileSLIResult, ok := fileSLIResultsByMetric[sliResult.Metric]; ok {
+			sliResult = fileSLIResult
+			delete(fileSLIResultsByMetric, sliResult.Metric)
+		}
+		sliResults = append(sliResults, sliResult)
+	}
+
+	for _, sliResult := range fileSLIResults {
+		if _, ok := fileSLIResultsByMetric[sliResult.Metric]; ok {
+			sliResults = append(sliResults, sliResult)
+		}
+	}
+	return sliResults
+}
+
+// mergeSLOs returns the SLOs of the dashboard with the objectives of the indicators queried using the sli.yaml taken from the existing SLOs
+func mergeSLOs(dashboardSLOs *keptncommon.ServiceLevelObjectives, existingSLOs *keptncommon.ServiceLevelObjectives, fileIndicators []string) *keptncommon.ServiceLevelObjectives {
+	isFileIndicator := make(map[string]bool, len(fileIndicators))
+	for _, indicator := range fileIndicators {
+		isFileIndicator[indicator] = true
+	}
+
+	mergedSLOs := *dashboardSLOs
+	mergedSLOs.Objectives = nil
+	for _, objective := range dashboardSLOs.Objectives {
+		// failed tiles have no objective
+		if objective == nil || !isFileIndicator[objective.SLI] {
+			mergedSLOs.Objectives = append(mergedSLOs.Objectives, objective)
+		}
+	}
+
+	if existingSLOs != nil {
+		for _, objective := range existingSLOs.Objectives {
+			if isFileIndicator[objective.SLI] {
+				mergedSLOs.Objectives = append(mergedSLOs.Objectives, objective)
+			}
+		}
+	}
+	return &mergedSLOs
+}
@@ -0,0 +1,258 @@
+package sli
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/config"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFileIndicators(t *testing.T) {
+	indicators := []string{"response_time_p95", "error_rate", "throughput", ProblemOpenSLI}
+	customQueries := keptn.NewCustomQueries(map[string]string{
+		"response_time_p95": "metricSelector=builtin:service.response.time:percentile(95)",
+		"error_rate":        "metricSelector=builtin:service.errors.total.rate",
+	})
+	dashboardSLIResults := []*keptnv2.SLIResult{{Metric: "response_time_p95"}, {Metric: "throughput"}}
+
+	tests := []struct {
+		precedence string
+		want       []string
+	}{
+		{
+			precedence: config.DashboardSLIMerge,
+			want:       []string{"error_rate"},
+		},
+		{
+			precedence: config.FileSLIMerge,
+			want:       []string{"response_time_p95", "error_rate"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.precedence, func(t *testing.T) {
+			assert.Equal(t, tt.want, getFileIndicators(indicators, customQueries, dashboardSLIResults, tt.precedence))
+		})
+	}
+}
+
+func TestMergeSLIResults(t *testing.T) {
+	dashboardSLIResults := []*keptnv2.SLIResult{
+		{Metric: "response_time_p95", Value: 300, Success: true},
+		{Metric: "throughput", Value: 1000, Success: true},
+	}
+	fileSLIResults := []*keptnv2.SLIResult{
+		{Metric: "response_time_p95", Value: 320, Success: true},
+		{Metric: "error_rate", Value: 0.5, Success: true},
+	}
+
+	assert.Equal(t, []*keptnv2.SLIResult{
+		{Metric: "response_time_p95", Value: 320, Success: true},
+		{Metric: "throughput", Value: 1000, Success: true},
+		{Metric: "error_rate", Value: 0.5, Success: true},
+	}, mergeSLIResults(dashboardSLIResults, fileSLIResults))
+}
+
+func TestMergeSLOs(t *testing.T) {
+	dashboardSLOs := &keptncommon.ServiceLevelObjectives{
+		Objectives: []*keptncommon.SLO{
+			{SLI: "response_time_p95", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<600"}}}},
+			{SLI: "throughput"},
+		},
+		TotalScore: &keptncommon.SLOScore{Pass: "90%", Warning: "75%"},
+	}
+	existingSLOs := &keptncommon.ServiceLevelObjectives{
+		Objectives: []*keptncommon.SLO{
+			{SLI: "response_time_p95", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}},
+			{SLI: "error_rate", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<1"}}}, KeySLI: true},
+			{SLI: "availability"},
+		},
+		TotalScore: &keptncommon.SLOScore{Pass: "80%", Warning: "60%"},
+	}
+
+	t.Run("dashboard objectives and those of the SLIs of the sli.yaml", func(t *testing.T) {
+		mergedSLOs := mergeSLOs(dashboardSLOs, existingSLOs, []string{"response_time_p95", "error_rate"})
+
+		assert.Equal(t, dashboardSLOs.TotalScore, mergedSLOs.TotalScore)
+		assert.Equal(t, []*keptncommon.SLO{
+			{SLI: "throughput"},
+			{SLI: "response_time_p95", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}},
+			{SLI: "error_rate", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<1"}}}, KeySLI: true},
+		}, mergedSLOs.Objectives)
+		assert.Len(t, dashboardSLOs.Objectives, 2)
+	})
+
+	t.Run("no existing SLOs", func(t *testing.T) {
+		mergedSLOs := mergeSLOs(dashboardSLOs, nil, []string{"error_rate"})
+
+		assert.Equal(t, dashboardSLOs.Objectives, mergedSLOs.Objectives)
+	})
+}
+
+const mergeTestDashboardID = "12345678-1111-4444-8888-123456789012"
+
+// mergeTestDashboard defines the SLIs response_time and throughput with objectives
+const mergeTestDashboard = `{
+	"id": "` + mergeTestDashboardID + `",
+	"dashboardMetadata": {"name": "KQG;project=sockshop;stage=staging;service=carts", "owner": "keptn", "dashboardFilter": {"timeframe": "-2h"}},
+	"tiles": [
+		{"name": "Response time;sli=response_time;pass=<600", "tileType": "DATA_EXPLORER", "configured": true,
+			"queries": [{"id": "A", "metric": "builtin:service.response.time", "spaceAggregation": "AVG", "timeAggregation": "DEFAULT", "enabled": true}]},
+		{"name": "Throughput;sli=throughput;pass=>100", "tileType": "DATA_EXPLORER", "configured": true,
+			"queries": [{"id": "A", "metric": "builtin:service.requestCount.total", "spaceAggregation": "SUM", "timeAggregation": "DEFAULT", "enabled": true}]}
+	]
+}`
+
+// getMergeTestMetricValue returns the value of a metric selector of the sli.yaml, i.e. the 95th percentile of the response time, or of the dashboard
+func getMergeTestMetricValue(metricSelector string) (float64, bool) {
+	switch {
+	case strings.HasPrefix(metricSelector, "builtin:service.response.time:percentile(95)"):
+		return 320000, true
+	case strings.HasPrefix(metricSelector, "builtin:service.response.time"):
+		return 300000, true
+	case strings.HasPrefix(metricSelector, "builtin:service.requestCount.total"):
+		return 1000, true
+	case strings.HasPrefix(metricSelector, "builtin:service.errors.total.rate"):
+		return 0.5, true
+	default:
+		return 0, false
+	}
+}
+
+// mergeTestHandler serves the dashboard, the metric definitions and the values of getMergeTestMetricValue, independent of the order of query parameters
+func mergeTestHandler(t *testing.T) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/api/config/v1/dashboards/"+mergeTestDashboardID:
+			w.Write([]byte(mergeTestDashboard))
+		case r.URL.Path == "/api/v2/metrics/query":
+			metricSelector := r.URL.Query().Get("metricSelector")
+			value, ok := getMergeTestMetricValue(metricSelector)
+			if !ok {
+				t.Errorf("unexpected metric selector %s", metricSelector)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			result := dynatrace.MetricsQueryResult{
+				TotalCount: 1,
+				Result: []dynatrace.MetricQueryResultValues{
+					{MetricID: metricSelector, Data: []dynatrace.MetricQueryResultNumbers{{Timestamps: []int64{1}, Values: []float64{value}}}},
+				},
+			}
+			json.NewEncoder(w).Encode(result)
+		case strings.HasPrefix(r.URL.Path, "/api/v2/metrics/"):
+			metricID := strings.TrimPrefix(r.URL.Path, "/api/v2/metrics/")
+			unit := "Count"
+			if metricID == "builtin:service.response.time" {
+				unit = "MicroSecond"
+			}
+			w.Write([]byte(`{"metricId":"` + metricID + `","unit":"` + unit + `","aggregationTypes":["auto","avg","count","max","min","sum"],"defaultAggregation":{"type":"avg"},"entityType":["SERVICE"]}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.String())
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+// mergingResourceClientMock returns the existing SLOs and records the uploaded dashboard, SLIs and SLOs
+type mergingResourceClientMock struct {
+	resourceClientMock
+	uploadedDashboard *dynatrace.Dashboard
+	uploadedSLI       *dynatrace.SLI
+	uploadedSLOs      *keptncommon.ServiceLevelObjectives
+}
+
+func (m *mergingResourceClientMock) UploadDashboardSLIAndSLOs(project string, stage string, service string, dashboard *dynatrace.Dashboard, sli *dynatrace.SLI, slos *keptncommon.ServiceLevelObjectives) error {
+	m.uploadedDashboard = dashboard
+	m.uploadedSLI = sli
+	m.uploadedSLOs = slos
+	return nil
+}
+
+// Tests merging the SLIs of a dashboard with those of the sli.yaml from querying the dashboard to storing the merged SLOs
+func TestRetrieveMergedSLIResults(t *testing.T) {
+	customQueries := map[string]string{
+		"response_time": "metricSelector=builtin:service.response.time:percentile(95)&entitySelector=type(SERVICE)",
+		"error_rate":    "metricSelector=builtin:service.errors.total.rate&entitySelector=type(SERVICE)",
+	}
+	existingSLOs := &keptncommon.ServiceLevelObjectives{
+		Objectives: []*keptncommon.SLO{
+			{SLI: "response_time", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<500"}}}},
+			{SLI: "error_rate", Pass: []*keptncommon.SLOCriteria{{Criteria: []string{"<1"}}}},
+		},
+	}
+
+	tests := []struct {
+		precedence          string
+		expectedValues      map[string]float64
+		expectedObjectives  map[string]string
+		expectedRequestSLIs []string
+	}{
+		{
+			precedence:          config.DashboardSLIMerge,
+			expectedValues:      map[string]float64{"response_time": 300, "throughput": 1000, "error_rate": 0.5},
+			expectedObjectives:  map[string]string{"response_time": "<600", "throughput": ">100", "error_rate": "<1"},
+			expectedRequestSLIs: []string{"error_rate"},
+		},
+		{
+			precedence:          config.FileSLIMerge,
+			expectedValues:      map[string]float64{"response_time": 320, "throughput": 1000, "error_rate": 0.5},
+			expectedObjectives:  map[string]string{"response_time": "<500", "throughput": ">100", "error_rate": "<1"},
+			expectedRequestSLIs: []string{"response_time", "error_rate"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.precedence, func(t *testing.T) {
+			ev := &getSLIEventData{
+				project:    "sockshop",
+				stage:      "staging",
+				service:    "carts",
+				indicators: []string{"response_time", "throughput", "error_rate"},
+			}
+
+			resourceClient := &mergingResourceClientMock{resourceClientMock: resourceClientMock{slos: existingSLOs}}
+			eh, _, teardown := createGetSLIEventHandler(ev, mergeTestHandler(t), &keptnClientMock{customQueries: customQueries})
+			defer teardown()
+			eh.resourceClient = resourceClient
+			eh.dashboard = mergeTestDashboardID
+			eh.sliMerge = tt.precedence
+
+			labels, sliResults, sliRequests, err := eh.retrieveMergedSLIResults(time.Unix(1632834999, 0), time.Unix(1632835299, 0))
+
+			assert.NoError(t, err)
+			assert.Contains(t, labels, dashboardLinkLabel)
+
+			values := make(map[string]float64, len(sliResults))
+			for _, sliResult := range sliResults {
+				assert.True(t, sliResult.Success, sliResult.Message)
+				values[sliResult.Metric] = sliResult.Value
+			}
+			assert.Equal(t, tt.expectedValues, values)
+
+			var requestSLIs []string
+			for _, sliRequest := range sliRequests {
+				requestSLIs = append(requestSLIs, sliRequest.Metric)
+			}
+			assert.Equal(t, tt.expectedRequestSLIs, requestSLIs)
+
+			if assert.NotNil(t, resourceClient.uploadedDashboard) {
+				assert.Equal(t, mergeTestDashboardID, resourceClient.uploadedDashboard.ID)
+			}
+			assert.Nil(t, resourceClient.uploadedSLI, "the SLIs of the dashboard must not replace the sli.yaml")
+			if assert.NotNil(t, resourceClient.uploadedSLOs) {
+				objectives := make(map[string]string, len(resourceClient.uploadedSLOs.Objectives))
+				for _, objective := range resourceClient.uploadedSLOs.Objectives {
+					objectives[objective.SLI] = strings.Join(objective.Pass[0].Criteria, " ")
+				}
+				assert.Equal(t, tt.expectedObjectives, objectives)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package sli
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	"github.com/keptn-contrib/dynatrace-service/internal/sli/query"
+	log "github.com/sirupsen/logrus"
+)
+
+// tenantLimiters bound the number of concurrent queries to each tenant across all events being handled, keyed by the URL of the tenant
+var tenantLimiters = struct {
+	sync.Mutex
+	limiters map[string]chan struct{}
+}{
+	limiters: make(map[string]chan struct{}),
+}
+
+// getTenantLimiter returns the limiter of the tenant allowing the maximum number of concurrent queries
+func getTenantLimiter(tenantURL string, maxConcurrentQueries int) chan struct{} {
+	if maxConcurrentQueries < 1 {
+		maxConcurrentQueries = 1
+	}
+
+	tenantLimiters.Lock()
+	defer tenantLimiters.Unlock()
+
+	limiter, ok := tenantLimiters.limiters[tenantURL]
+	if !ok || cap(limiter) != maxConcurrentQueries {
+		limiter = make(chan struct{}, maxConcurrentQueries)
+		tenantLimiters.limiters[tenantURL] = limiter
+	}
+	return limiter
+}
+
+// tenantQueryProcessing queries the SLIs defined in the sli.yaml from a single tenant
+type tenantQueryProcessing struct {
+	name string
+	// processing is used for sequential queries and calculated SLIs, which reuse the values of the indicators queried before
+	processing *query.Processing
+	// newProcessing creates a query processing used by a single concurrent query, as the shared one is not safe for concurrent use
+	newProcessing func() *query.Processing
+	// concurrent is set if the indicators not referencing others are queried concurrently
+	concurrent bool
+	// limiter bounds the number of concurrent queries to the tenant
+	limiter chan struct{}
+	// noDataRetries and noDataRetryDelay define how often and after which initial delay queries returning no data points are retried
+	noDataRetries    int
+	noDataRetryDelay time.Duration
+}
+
+// tenantSLIValue is the value of an indicator queried from a tenant
+type tenantSLIValue struct {
+	tenant   string
+	value    float64
+	retries  int
+	err      error
+	requests []string
+	warnings []string
+}
+
+// newTenantQueryProcessing creates the query processing of a tenant that runs at most the configured maximum number of queries to it concurrently.
+// With at most one concurrent query, the indicators are queried sequentially using a single query processing
+func newTenantQueryProcessing(name string, tenantURL string, cfg *env.Config, newProcessing func() *query.Processing) *tenantQueryProcessing {
+	return &tenantQueryProcessing{
+		name:             name,
+		processing:       newProcessing(),
+		newProcessing:    newProcessing,
+		concurrent:       cfg.SLIMaxConcurrentQueriesPerTenant > 1,
+		limiter:          getTenantLimiter(tenantURL, cfg.SLIMaxConcurrentQueriesPerTenant),
+		noDataRetries:    cfg.SLINoDataRetries,
+		noDataRetryDelay: time.Duration(cfg.SLINoDataRetryDelay) * time.Second,
+	}
+}
+
+// getSLIValue queries the indicator using the processing, waiting for a free slot if the maximum number of concurrent queries to the tenant is running.
+// The slot is only held while querying, so that other queries are not blocked while waiting for a retry
+func (t *tenantQueryProcessing) getSLIValue(indicator string, processing *query.Processing) tenantSLIValue {
+	querySLIValue := func() (float64, error) {
+		t.limiter <- struct{}{}
+		defer func() { <-t.limiter }()
+
+		return processing.GetSLIValue(indicator)
+	}
+
+	log.WithFields(log.Fields{"indicator": indicator, "tenant": t.name}).Info("Fetching indicator")
+	value, retries, err := getSLIValueWithNoDataRetries(indicator, querySLIValue, t.noDataRetries, t.noDataRetryDelay)
+	return tenantSLIValue{
+		tenant:   t.name,
+		value:    value,
+		retries:  retries,
+		err:      err,
+		requests: processing.GetSLIRequests(indicator),
+		warnings: processing.GetSLIWarnings(indicator),
+	}
+}
+
+// getSLIValues queries the indicators from the tenant and returns their values in the order of the indicators.
+// If queries are concurrent, calculated SLIs are only evaluated once all other indicators have been queried, reusing their values
+func (t *tenantQueryProcessing) getSLIValues(indicators []string) []tenantSLIValue {
+	values := make([]tenantSLIValue, len(indicators))
+	if !t.concurrent {
+		for i, indicator := range indicators {
+			values[i] = t.getSLIValue(indicator, t.processing)
+		}
+		return values
+	}
+
+	var wg sync.WaitGroup
+	for i, indicator := range indicators {
+		if t.processing.IsCalculated(indicator) {
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, indicator string) {
+			defer wg.Done()
+			values[i] = t.getSLIValue(indicator, t.newProcessing())
+		}(i, indicator)
+	}
+	wg.Wait()
+
+	for i, indicator := range indicators {
+		if !t.processing.IsCalculated(indicator) && values[i].err == nil {
+			t.processing.AddSLIValue(indicator, values[i].value, values[i].requests, values[i].warnings)
+		}
+	}
+
+	for i, indicator := range indicators {
+		if t.processing.IsCalculated(indicator) {
+			values[i] = t.getSLIValue(indicator, t.processing)
+		}
+	}
+	return values
+}
+
+// getSLIValues queries all indicators from all tenants concurrently and returns the values per indicator in the order of the tenants
+func getSLIValues(indicators []string, tenants []*tenantQueryProcessing) [][]tenantSLIValue {
+	tenantValues := make([][]tenantSLIValue, len(tenants))
+
+	var wg sync.WaitGroup
+	for j, tenant := range tenants {
+		wg.Add(1)
+		go func(j int, tenant *tenantQueryProcessing) {
+			defer wg.Done()
+			tenantValues[j] = tenant.getSLIValues(indicators)
+		}(j, tenant)
+	}
+	wg.Wait()
+
+	values := make([][]tenantSLIValue, len(indicators))
+	for i := range values {
+		values[i] = make([]tenantSLIValue, len(tenants))
+		for j := range tenants {
+			values[i][j] = tenantValues[j][i]
+		}
+	}
+	return values
+}
@@ -0,0 +1,239 @@
+package sli
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/sli/query"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingMetricsHandler answers metrics queries only once they are released and records the maximum number of queries running at the same time.
+// The value of each metric is the number in its ID, e.g. 3 for builtin:custom.metric3
+type blockingMetricsHandler struct {
+	started chan struct{}
+	release chan struct{}
+
+	mutex                  sync.Mutex
+	runningRequestCount    int
+	maxRunningRequestCount int
+}
+
+func newBlockingMetricsHandler(maxRequestCount int) *blockingMetricsHandler {
+	return &blockingMetricsHandler{
+		started: make(chan struct{}, maxRequestCount),
+		release: make(chan struct{}),
+	}
+}
+
+func (h *blockingMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mutex.Lock()
+	h.runningRequestCount++
+	if h.runningRequestCount > h.maxRunningRequestCount {
+		h.maxRunningRequestCount = h.runningRequestCount
+	}
+	h.mutex.Unlock()
+
+	h.started <- struct{}{}
+	<-h.release
+
+	h.mutex.Lock()
+	h.runningRequestCount--
+	h.mutex.Unlock()
+
+	metricSelector := r.URL.Query().Get("metricSelector")
+	value := strings.TrimPrefix(metricSelector, "builtin:custom.metric")
+	w.Write([]byte(`{"totalCount":1,"nextPageKey":null,"result":[{"metricId":"` + metricSelector + `","data":[{"dimensions":[],"timestamps":[1],"values":[` + value + `]}]}]}`))
+}
+
+// releaseAfter releases all queries once the number of queries has been started, so that at least this number of queries runs at the same time
+func (h *blockingMetricsHandler) releaseAfter(startedRequestCount int) {
+	for i := 0; i < startedRequestCount; i++ {
+		<-h.started
+	}
+	close(h.release)
+}
+
+func (h *blockingMetricsHandler) getMaxRunningRequestCount() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.maxRunningRequestCount
+}
+
+func createCustomMetricQueries(count int) (map[string]string, []string) {
+	customQueries := map[string]string{}
+	var indicators []string
+	for i := 1; i <= count; i++ {
+		indicator := fmt.Sprintf("indicator%d", i)
+		customQueries[indicator] = fmt.Sprintf("metricSelector=builtin:custom.metric%d", i)
+		indicators = append(indicators, indicator)
+	}
+	return customQueries, indicators
+}
+
+// Tests that SLIs defined in the sli.yaml are queried concurrently up to the maximum number of concurrent queries and that the results keep the order of the indicators
+func TestSLIsAreQueriedConcurrentlyUpToMaximum(t *testing.T) {
+	tests := []struct {
+		maxConcurrentQueries int
+	}{
+		{maxConcurrentQueries: 1},
+		{maxConcurrentQueries: 3},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.maxConcurrentQueries), func(t *testing.T) {
+			customQueries, indicators := createCustomMetricQueries(6)
+			ev := &getSLIEventData{
+				project:    "sockshop",
+				stage:      "staging",
+				service:    "carts",
+				indicators: indicators,
+			}
+
+			handler := newBlockingMetricsHandler(len(indicators))
+			eh, _, teardown := createGetSLIEventHandler(ev, handler, &keptnClientMock{customQueries: customQueries})
+			defer teardown()
+			eh.cfg.SLIMaxConcurrentQueriesPerTenant = tt.maxConcurrentQueries
+
+			go handler.releaseAfter(tt.maxConcurrentQueries)
+			sliResults, sliRequests, err := eh.getSLIResultsFromCustomQueries(time.Unix(1632834999, 0), time.Unix(1632835299, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.maxConcurrentQueries, handler.getMaxRunningRequestCount())
+
+			if assert.Len(t, sliResults, len(indicators)) && assert.Len(t, sliRequests, len(indicators)) {
+				for i, indicator := range indicators {
+					assert.Equal(t, indicator, sliResults[i].Metric)
+					assert.True(t, sliResults[i].Success, sliResults[i].Message)
+					assert.EqualValues(t, i+1, sliResults[i].Value)
+					assert.Equal(t, indicator, sliRequests[i].Metric)
+					assert.Len(t, sliRequests[i].Requests, 1)
+				}
+			}
+		})
+	}
+}
+
+// Tests that the maximum number of concurrent queries applies to a tenant, even if it is queried as several tenants
+func TestSLIsAreQueriedConcurrentlyUpToMaximumPerTenant(t *testing.T) {
+	customQueries, indicators := createCustomMetricQueries(4)
+	ev := &getSLIEventData{
+		project:    "sockshop",
+		stage:      "staging",
+		service:    "carts",
+		indicators: indicators,
+	}
+
+	handler := newBlockingMetricsHandler(2 * len(indicators))
+	eh, _, teardown := createGetSLIEventHandler(ev, handler, &keptnClientMock{customQueries: customQueries})
+	defer teardown()
+	eh.cfg.SLIMaxConcurrentQueriesPerTenant = 2
+	handlerWithSameTenant := eh.WithAdditionalTenants([]Tenant{{Name: "dynatrace-same-tenant", Client: eh.dtClient}}, "avg")
+
+	go handler.releaseAfter(2)
+	sliResults, _, err := handlerWithSameTenant.getSLIResultsFromCustomQueries(time.Unix(1632834999, 0), time.Unix(1632835299, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, handler.getMaxRunningRequestCount())
+
+	if assert.Len(t, sliResults, len(indicators)) {
+		for i := range indicators {
+			assert.True(t, sliResults[i].Success, sliResults[i].Message)
+			assert.EqualValues(t, i+1, sliResults[i].Value)
+		}
+	}
+}
+
+// Tests that calculated SLIs are evaluated after the indicators they reference and reuse their values when queried concurrently
+func TestCalculatedSLIsAreEvaluatedAfterQueriedIndicators(t *testing.T) {
+	var mutex sync.Mutex
+	queriedMetricSelectors := map[string]int{}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metricSelector := r.URL.Query().Get("metricSelector")
+		mutex.Lock()
+		queriedMetricSelectors[metricSelector]++
+		mutex.Unlock()
+
+		value := strings.TrimPrefix(metricSelector, "builtin:custom.metric")
+		w.Write([]byte(`{"totalCount":1,"nextPageKey":null,"result":[{"metricId":"` + metricSelector + `","data":[{"dimensions":[],"timestamps":[1],"values":[` + value + `]}]}]}`))
+	})
+
+	customQueries := map[string]string{
+		"ratio":       "CALC;indicator1 / indicator4 * 100",
+		"indicator1":  "metricSelector=builtin:custom.metric1",
+		"indicator4":  "metricSelector=builtin:custom.metric4",
+		"ratio_delta": "CALC;ratio - indicator1",
+	}
+	ev := &getSLIEventData{
+		project:    "sockshop",
+		stage:      "staging",
+		service:    "carts",
+		indicators: []string{"ratio_delta", "ratio", "indicator1", "indicator4"},
+	}
+
+	eh, _, teardown := createGetSLIEventHandler(ev, handler, &keptnClientMock{customQueries: customQueries})
+	defer teardown()
+	eh.cfg.SLIMaxConcurrentQueriesPerTenant = 4
+
+	sliResults, sliRequests, err := eh.getSLIResultsFromCustomQueries(time.Unix(1632834999, 0), time.Unix(1632835299, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"builtin:custom.metric1": 1, "builtin:custom.metric4": 1}, queriedMetricSelectors)
+
+	if assert.Len(t, sliResults, 4) && assert.Len(t, sliRequests, 4) {
+		for _, sliResult := range sliResults {
+			assert.True(t, sliResult.Success, sliResult.Message)
+		}
+		assert.EqualValues(t, 24, sliResults[0].Value)
+		assert.EqualValues(t, 25, sliResults[1].Value)
+		assert.Len(t, sliRequests[0].Requests, 2)
+		assert.Len(t, sliRequests[1].Requests, 2)
+	}
+}
+
+// Tests that the slot of a query is released while waiting for a retry, so that other queries to the tenant are not blocked
+func TestSLIQueryWithoutDataReleasesSlotWhileWaitingForRetry(t *testing.T) {
+	queried := make(chan struct{}, 2)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queried <- struct{}{}
+		w.Write([]byte(`{"result":[{"metricId":"builtin:custom.metric1","data":[]}]}`))
+	})
+
+	ev := &getSLIEventData{
+		project:    "sockshop",
+		stage:      "staging",
+		service:    "carts",
+		indicators: []string{"indicator1"},
+	}
+	kClient := &keptnClientMock{customQueries: map[string]string{"indicator1": "metricSelector=builtin:custom.metric1"}}
+	eh, url, teardown := createGetSLIEventHandler(ev, handler, kClient)
+	defer teardown()
+
+	customQueries, err := kClient.GetCustomQueries("sockshop", "staging", "carts")
+	assert.NoError(t, err)
+
+	eh.cfg.SLIMaxConcurrentQueriesPerTenant = 1
+	eh.cfg.SLINoDataRetries = 1
+	tenant := newTenantQueryProcessing("dynatrace", url, eh.cfg, func() *query.Processing {
+		return query.NewProcessing(eh.dtClient, ev, nil, customQueries, time.Unix(1632834999, 0), time.Unix(1632835299, 0))
+	})
+	tenant.noDataRetryDelay = time.Second
+
+	values := make(chan tenantSLIValue)
+	go func() {
+		values <- tenant.getSLIValue("indicator1", tenant.processing)
+	}()
+	<-queried
+
+	select {
+	case tenant.limiter <- struct{}{}:
+		<-tenant.limiter
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("slot was not released while waiting for the retry")
+	}
+
+	value := <-values
+	assert.Error(t, value.err)
+	assert.Equal(t, 1, value.retries)
+}
@@ -0,0 +1,127 @@
+package sli
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+var defaultSLIResultCache *SLIResultCache
+var defaultSLIResultCacheOnce sync.Once
+
+type sliResultCacheEntry struct {
+	labels      map[string]string
+	sliResults  []keptnv2.SLIResult
+	sliRequests []SLIRequests
+	storedAt    time.Time
+}
+
+// SLIResultCache keeps retrieved SLI results for a short time, so that repeated get-sli.triggered events for the same service and timeframe,
+// e.g. retries or recalculations of a comparison by the lighthouse-service, do not query Dynatrace again
+type SLIResultCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mutex   sync.Mutex
+	entries map[string]sliResultCacheEntry
+}
+
+// GetDefaultSLIResultCache returns the SLIResultCache shared by all get-sli.triggered event handlers.
+// It uses the TTL of the configuration of the first call
+func GetDefaultSLIResultCache(cfg *env.Config) *SLIResultCache {
+	defaultSLIResultCacheOnce.Do(func() {
+		defaultSLIResultCache = NewSLIResultCache(time.Duration(cfg.SLIResultCacheTTL) * time.Second)
+	})
+
+	return defaultSLIResultCache
+}
+
+// NewSLIResultCache creates a new SLIResultCache. If ttl is 0 or less, no results are cached
+func NewSLIResultCache(ttl time.Duration) *SLIResultCache {
+	return &SLIResultCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]sliResultCacheEntry),
+	}
+}
+
+// get returns a copy of the SLI results and requests cached for the key and the labels added to the event when they were retrieved, e.g. the dashboard link
+func (c *SLIResultCache) get(key string) (map[string]string, []*keptnv2.SLIResult, []*SLIRequests, bool) {
+	if c == nil || c.ttl <= 0 {
+		return nil, nil, nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.removeExpiredEntries()
+
+	entry, found := c.entries[key]
+	if !found {
+		return nil, nil, nil, false
+	}
+
+	sliResults := make([]*keptnv2.SLIResult, len(entry.sliResults))
+	for i := range entry.sliResults {
+		sliResult := entry.sliResults[i]
+		sliResults[i] = &sliResult
+	}
+
+	var sliRequests []*SLIRequests
+	for i := range entry.sliRequests {
+		sliRequest := entry.sliRequests[i]
+		sliRequests = append(sliRequests, &sliRequest)
+	}
+
+	return copyLabels(entry.labels), sliResults, sliRequests, true
+}
+
+// put caches a copy of the SLI results and requests for the key. Results are only cached if all SLIs were retrieved successfully,
+// so that failures, e.g. because data was not available yet, are retried
+func (c *SLIResultCache) put(key string, labels map[string]string, sliResults []*keptnv2.SLIResult, sliRequests []*SLIRequests) {
+	if c == nil || c.ttl <= 0 || len(sliResults) == 0 {
+		return
+	}
+
+	entry := sliResultCacheEntry{
+		labels:     copyLabels(labels),
+		sliResults: make([]keptnv2.SLIResult, len(sliResults)),
+	}
+	for i, sliResult := range sliResults {
+		if sliResult == nil || !sliResult.Success {
+			return
+		}
+		entry.sliResults[i] = *sliResult
+	}
+	for _, sliRequest := range sliRequests {
+		if sliRequest != nil {
+			entry.sliRequests = append(entry.sliRequests, *sliRequest)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.removeExpiredEntries()
+	entry.storedAt = c.now()
+	c.entries[key] = entry
+}
+
+func (c *SLIResultCache) removeExpiredEntries() {
+	now := c.now()
+	for key, entry := range c.entries {
+		if now.Sub(entry.storedAt) >= c.ttl {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func copyLabels(labels map[string]string) map[string]string {
+	copiedLabels := make(map[string]string, len(labels))
+	for name, value := range labels {
+		copiedLabels[name] = value
+	}
+	return copiedLabels
+}
@@ -0,0 +1,112 @@
+package sli
+
+import (
+	"testing"
+	"time"
+
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSLIResultCache(t *testing.T) {
+	now := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	cache := NewSLIResultCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	sliResults := []*keptnv2.SLIResult{{Metric: "response_time_p95", Value: 12.5, Success: true}}
+	sliRequests := []*SLIRequests{{Metric: "response_time_p95", Requests: []string{"https://tenant/api/v2/metrics/query"}}}
+	labels := map[string]string{"Dashboard Link": "https://tenant/#dashboard;id=123"}
+	cache.put("key", labels, sliResults, sliRequests)
+
+	// the cached results are not affected by changes to the stored or returned results
+	sliResults[0].Value = 0
+	labels["Dashboard Link"] = ""
+	cachedLabels, cachedResults, cachedRequests, found := cache.get("key")
+	assert.True(t, found)
+	assert.Equal(t, map[string]string{"Dashboard Link": "https://tenant/#dashboard;id=123"}, cachedLabels)
+	assert.Equal(t, []*keptnv2.SLIResult{{Metric: "response_time_p95", Value: 12.5, Success: true}}, cachedResults)
+	assert.Equal(t, sliRequests, cachedRequests)
+
+	cachedResults[0].Success = false
+	_, cachedResults, _, _ = cache.get("key")
+	assert.True(t, cachedResults[0].Success)
+
+	_, _, _, found = cache.get("other-key")
+	assert.False(t, found)
+
+	now = now.Add(time.Minute)
+	_, _, _, found = cache.get("key")
+	assert.False(t, found)
+}
+
+func TestSLIResultCache_DoesNotCacheFailedResults(t *testing.T) {
+	cache := NewSLIResultCache(time.Minute)
+
+	cache.put("key", nil, []*keptnv2.SLIResult{
+		{Metric: "response_time_p95", Value: 12.5, Success: true},
+		{Metric: "error_rate", Success: false, Message: "no data"},
+	}, nil)
+
+	_, _, _, found := cache.get("key")
+	assert.False(t, found)
+}
+
+func TestSLIResultCache_Disabled(t *testing.T) {
+	cache := NewSLIResultCache(0)
+	cache.put("key", nil, []*keptnv2.SLIResult{{Metric: "response_time_p95", Value: 12.5, Success: true}}, nil)
+
+	_, _, _, found := cache.get("key")
+	assert.False(t, found)
+
+	var nilCache *SLIResultCache
+	nilCache.put("key", nil, []*keptnv2.SLIResult{{Metric: "response_time_p95", Value: 12.5, Success: true}}, nil)
+	_, _, _, found = nilCache.get("key")
+	assert.False(t, found)
+}
+
+// Tests that SLI results retrieved with the SLI definitions of another git commit of the configuration repository are not reused
+func TestGetSLIEventHandler_getSLIResultCacheKeyContainsGitCommitID(t *testing.T) {
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	getKey := func(gitCommitID string) string {
+		eh := &GetSLIEventHandler{event: &getSLIEventData{project: "sockshop", stage: "staging", service: "carts", indicators: []string{"response_time_p95"}, gitCommitID: gitCommitID}}
+		return eh.getSLIResultCacheKey(start, end)
+	}
+
+	assert.Equal(t, getKey("a1b2c3"), getKey("a1b2c3"))
+	assert.NotEqual(t, getKey("a1b2c3"), getKey("d4e5f6"))
+	assert.NotEqual(t, getKey("a1b2c3"), getKey(""))
+}
+
+// Tests that SLI results are not reused for other sequences or deployments, as their values may be used as placeholders in the queries
+func TestGetSLIEventHandler_getSLIResultCacheKeyContainsPlaceholderValues(t *testing.T) {
+	start := time.Date(2021, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	getKey := func(modify func(e *getSLIEventData)) string {
+		e := &getSLIEventData{
+			context:            "7c2c890f-b3ac-4caa-8922-f44d2aa54ec9",
+			project:            "sockshop",
+			stage:              "staging",
+			service:            "carts",
+			deployment:         "canary",
+			testStrategy:       "performance",
+			deploymentStrategy: "blue_green_service",
+			labels:             map[string]string{"buildId": "1", "owner": "team-a"},
+			indicators:         []string{"response_time_p95"},
+		}
+		modify(e)
+		eh := &GetSLIEventHandler{event: e}
+		return eh.getSLIResultCacheKey(start, end)
+	}
+
+	key := getKey(func(e *getSLIEventData) {})
+	assert.Equal(t, key, getKey(func(e *getSLIEventData) {}))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.context = "a3e5f16d-8888-4720-82c7-6995062905c1" }))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.deployment = "primary" }))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.testStrategy = "functional" }))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.deploymentStrategy = "direct" }))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.labels = map[string]string{"buildId": "2", "owner": "team-a"} }))
+	assert.NotEqual(t, key, getKey(func(e *getSLIEventData) { e.labels = map[string]string{"buildId": "1"} }))
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/keptn-contrib/dynatrace-service/internal/adapter"
 	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
 	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
 	"github.com/keptn-contrib/dynatrace-service/internal/keptn"
 	"github.com/keptn-contrib/dynatrace-service/internal/test"
 	keptnapi "github.com/keptn/go-utils/pkg/lib"
@@ -29,6 +30,7 @@ func createGetSLIEventHandler(keptnEvent GetSLITriggeredAdapterInterface, handle
 		resourceClient: &resourceClientMock{},
 		dashboard:      "",          // we do not want to query a dashboard, so we leave it empty (and have no dashboard stored)
 		secretName:     "dynatrace", // we do not need this string
+		cfg:            env.ReadConfig(),
 	}
 
 	return eh, url, teardown
@@ -53,6 +55,7 @@ type getSLIEventData struct {
 	notForDynatrace bool
 	sliStart        string
 	sliEnd          string
+	gitCommitID     string
 }
 
 func (e *getSLIEventData) GetShKeptnContext() string {
@@ -127,6 +130,10 @@ func (e *getSLIEventData) GetCustomSLIFilters() []*keptnv2.SLIFilter {
 	return e.customFilters
 }
 
+func (e *getSLIEventData) GetGitCommitID() string {
+	return e.gitCommitID
+}
+
 func (e *getSLIEventData) AddLabel(name string, value string) {
 	if e.labels == nil {
 		e.labels = make(map[string]string)
@@ -135,10 +142,15 @@ func (e *getSLIEventData) AddLabel(name string, value string) {
 	e.labels[name] = value
 }
 
-type resourceClientMock struct{}
+type resourceClientMock struct {
+	slos *keptnapi.ServiceLevelObjectives
+}
 
 func (m *resourceClientMock) GetSLOs(project string, stage string, service string) (*keptnapi.ServiceLevelObjectives, error) {
-	panic("GetSLOs() should not be needed in this mock!")
+	if m.slos == nil {
+		panic("GetSLOs() should not be needed in this mock!")
+	}
+	return m.slos, nil
 }
 
 func (m *resourceClientMock) UploadSLI(project string, stage string, service string, sli *dynatrace.SLI) error {
@@ -158,6 +170,13 @@ func (m *resourceClientMock) UploadDashboard(project string, stage string, servi
 	panic("UploadDashboard() should not be needed in this mock!")
 }
 
+func (m *resourceClientMock) UploadDashboardSLIAndSLOs(project string, stage string, service string, dashboard *dynatrace.Dashboard, sli *dynatrace.SLI, slos *keptnapi.ServiceLevelObjectives) error {
+	if dashboard == nil && sli == nil && slos == nil {
+		return nil
+	}
+	panic("UploadDashboardSLIAndSLOs() should not be needed in this mock!")
+}
+
 type keptnClientMock struct {
 	eventSink          []*cloudevents.Event
 	customQueries      map[string]string
@@ -177,10 +196,6 @@ func (m *keptnClientMock) GetCustomQueries(project string, stage string, service
 	return keptn.NewCustomQueries(m.customQueries), nil
 }
 
-func (m *keptnClientMock) GetShipyard() (*keptnv2.Shipyard, error) {
-	panic("GetShipyard() should not be needed in this mock!")
-}
-
 func (m *keptnClientMock) SendCloudEvent(factory adapter.CloudEventFactoryInterface) error {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
@@ -0,0 +1,90 @@
+package startup
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// maxReadinessCheckDelay limits the exponential backoff between two attempts of a readiness check
+const maxReadinessCheckDelay = 30 * time.Second
+
+// initialReadinessCheckDelay is the delay before the second attempt of a readiness check, doubled for every further attempt
+const initialReadinessCheckDelay = 1 * time.Second
+
+// ReadinessCheck is a dependency that must be available before the subsystems of the dynatrace-service are enabled
+type ReadinessCheck struct {
+	// Name identifies the dependency in the log, e.g. Keptn API
+	Name string
+
+	// Check returns an error as long as the dependency is not available
+	Check func() error
+}
+
+// ReadinessGate waits for the dependencies of the dynatrace-service, e.g. the Keptn control plane, to become available.
+// Every check is retried with an exponential backoff until it succeeds or the timeout is exceeded, and each step is logged
+type ReadinessGate struct {
+	checks       []ReadinessCheck
+	timeout      time.Duration
+	initialDelay time.Duration
+	sleep        func(d time.Duration)
+	now          func() time.Time
+}
+
+// NewReadinessGate creates a new ReadinessGate for the checks. If the timeout is 0 or less, the checks are not run
+func NewReadinessGate(timeout time.Duration, checks ...ReadinessCheck) *ReadinessGate {
+	return &ReadinessGate{
+		checks:       checks,
+		timeout:      timeout,
+		initialDelay: initialReadinessCheckDelay,
+		sleep:        time.Sleep,
+		now:          time.Now,
+	}
+}
+
+// Wait runs the checks in order and returns whether all of them succeeded before the timeout. It returns as soon as a check has not succeeded
+// within the timeout, so that the dynatrace-service starts anyway and handles the unavailable dependency like a transient outage
+func (g *ReadinessGate) Wait() bool {
+	if g.timeout <= 0 {
+		log.Debug("Readiness gate disabled, not waiting for dependencies")
+		return true
+	}
+
+	start := g.now()
+	deadline := start.Add(g.timeout)
+	for _, check := range g.checks {
+		if !g.waitFor(check, start, deadline) {
+			log.WithFields(log.Fields{"dependency": check.Name, "elapsed": g.now().Sub(start)}).Warn("Dependency not ready within the startup timeout, starting anyway")
+			return false
+		}
+	}
+
+	log.WithField("elapsed", g.now().Sub(start)).Info("All dependencies ready, enabling subsystems")
+	return true
+}
+
+// waitFor retries the check until it succeeds or the next attempt would exceed the deadline
+func (g *ReadinessGate) waitFor(check ReadinessCheck, start time.Time, deadline time.Time) bool {
+	delay := g.initialDelay
+	for attempt := 1; ; attempt++ {
+		err := check.Check()
+		fields := log.Fields{"dependency": check.Name, "attempt": attempt, "elapsed": g.now().Sub(start)}
+		if err == nil {
+			log.WithFields(fields).Info("Dependency ready")
+			return true
+		}
+
+		if g.now().Add(delay).After(deadline) {
+			log.WithFields(fields).WithError(err).Warn("Dependency not ready")
+			return false
+		}
+
+		log.WithFields(fields).WithField("retryIn", delay).WithError(err).Info("Dependency not ready yet, waiting")
+		g.sleep(delay)
+
+		delay *= 2
+		if delay > maxReadinessCheckDelay {
+			delay = maxReadinessCheckDelay
+		}
+	}
+}
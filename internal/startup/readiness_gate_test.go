@@ -0,0 +1,73 @@
+package startup
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestReadinessGate creates a ReadinessGate whose clock is advanced by sleeping, and returns the delays slept
+func newTestReadinessGate(timeout time.Duration, checks ...ReadinessCheck) (*ReadinessGate, *[]time.Duration) {
+	var delays []time.Duration
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	gate := NewReadinessGate(timeout, checks...)
+	gate.now = func() time.Time { return now }
+	gate.sleep = func(d time.Duration) {
+		delays = append(delays, d)
+		now = now.Add(d)
+	}
+	return gate, &delays
+}
+
+// failingCheck returns a check that fails the given number of times before it succeeds
+func failingCheck(name string, failures int) ReadinessCheck {
+	attempts := 0
+	return ReadinessCheck{
+		Name: name,
+		Check: func() error {
+			attempts++
+			if attempts <= failures {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}
+}
+
+func TestReadinessGate_WaitRetriesWithBackoff(t *testing.T) {
+	gate, delays := newTestReadinessGate(5*time.Minute, failingCheck("Keptn API", 3), failingCheck("resource-service", 1))
+
+	assert.True(t, gate.Wait())
+	assert.Equal(t, []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 1 * time.Second}, *delays)
+}
+
+func TestReadinessGate_WaitLimitsDelay(t *testing.T) {
+	gate, delays := newTestReadinessGate(5*time.Minute, failingCheck("Keptn API", 7))
+
+	assert.True(t, gate.Wait())
+	assert.Equal(t, []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 30 * time.Second, 30 * time.Second}, *delays)
+}
+
+func TestReadinessGate_WaitGivesUpAfterTimeout(t *testing.T) {
+	resourceServiceChecked := false
+	gate, delays := newTestReadinessGate(10*time.Second,
+		failingCheck("Keptn API", 100),
+		ReadinessCheck{Name: "resource-service", Check: func() error {
+			resourceServiceChecked = true
+			return nil
+		}})
+
+	assert.False(t, gate.Wait())
+	assert.Equal(t, []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}, *delays)
+	assert.False(t, resourceServiceChecked)
+}
+
+func TestReadinessGate_WaitDisabled(t *testing.T) {
+	gate, delays := newTestReadinessGate(0, failingCheck("Keptn API", 100))
+
+	assert.True(t, gate.Wait())
+	assert.Empty(t, *delays)
+}
@@ -0,0 +1,224 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// RecordDynatraceAPIEnvName is the environment variable enabling the recording of fixtures by RecordingURLHandlers
+const RecordDynatraceAPIEnvName = "RECORD_DYNATRACE_API"
+
+const recordingTimeout = 2 * time.Minute
+
+// Interaction is a request to the Dynatrace API and its recorded response
+type Interaction struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	StatusCode int    `json:"statusCode"`
+
+	// Response is set if the response body is JSON, ResponseText otherwise
+	Response     json.RawMessage `json:"response,omitempty"`
+	ResponseText string          `json:"responseText,omitempty"`
+}
+
+type fixture struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// RecordingURLHandler serves the responses of the Dynatrace API recorded in a fixture file, matching requests by method, path and query parameters,
+// regardless of the order and encoding of the parameters.
+//
+// If the environment variable RECORD_DYNATRACE_API is set to true, requests are instead forwarded to the tenant set by DT_TENANT
+// using the API token set by DT_API_TOKEN, and all interactions are written to the fixture file once the test finished.
+// This allows adding regression tests from the responses of a real tenant, which can then be replayed without any credentials
+type RecordingURLHandler struct {
+	t           *testing.T
+	fixtureFile string
+
+	recording  bool
+	tenant     string
+	apiToken   string
+	httpClient *http.Client
+
+	mutex        sync.Mutex
+	interactions []Interaction
+}
+
+// NewRecordingURLHandler creates a new RecordingURLHandler using the given fixture file, which must be in the local 'testdata' folder
+func NewRecordingURLHandler(t *testing.T, fixtureFile string) *RecordingURLHandler {
+	h := &RecordingURLHandler{
+		t:           t,
+		fixtureFile: fixtureFile,
+	}
+
+	if !strings.HasPrefix(fixtureFile, "./testdata/") {
+		t.Fatalf("the fixture file you specified is not in the local 'testdata' folder: %s", fixtureFile)
+	}
+
+	if os.Getenv(RecordDynatraceAPIEnvName) == "true" {
+		h.startRecording()
+		return h
+	}
+
+	h.loadFixture()
+	return h
+}
+
+func (h *RecordingURLHandler) startRecording() {
+	h.tenant = strings.TrimSuffix(os.Getenv("DT_TENANT"), "/")
+	h.apiToken = os.Getenv("DT_API_TOKEN")
+	if h.tenant == "" || h.apiToken == "" {
+		h.t.Fatalf("DT_TENANT and DT_API_TOKEN must be set to record %s", h.fixtureFile)
+	}
+
+	h.recording = true
+	h.httpClient = &http.Client{Timeout: recordingTimeout}
+	h.t.Cleanup(h.saveFixture)
+	log.WithFields(log.Fields{"tenant": h.tenant, "fixtureFile": h.fixtureFile}).Info("Recording Dynatrace API responses")
+}
+
+func (h *RecordingURLHandler) loadFixture() {
+	content, err := ioutil.ReadFile(h.fixtureFile)
+	if err != nil {
+		h.t.Fatalf("could not load fixture file %s, record it by setting %s=true: %v", h.fixtureFile, RecordDynatraceAPIEnvName, err)
+	}
+
+	f := fixture{}
+	err = json.Unmarshal(content, &f)
+	if err != nil {
+		h.t.Fatalf("invalid fixture file %s: %v", h.fixtureFile, err)
+	}
+
+	h.interactions = f.Interactions
+}
+
+func (h *RecordingURLHandler) saveFixture() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	content, err := json.MarshalIndent(fixture{Interactions: h.interactions}, "", "  ")
+	if err != nil {
+		h.t.Errorf("could not marshal fixture %s: %v", h.fixtureFile, err)
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(h.fixtureFile), 0755)
+	if err == nil {
+		err = ioutil.WriteFile(h.fixtureFile, append(content, '\n'), 0644)
+	}
+	if err != nil {
+		h.t.Errorf("could not write fixture file %s: %v", h.fixtureFile, err)
+		return
+	}
+
+	log.WithFields(log.Fields{"fixtureFile": h.fixtureFile, "interactions": len(h.interactions)}).Info("Recorded Dynatrace API responses")
+}
+
+func (h *RecordingURLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.recording {
+		h.record(w, r)
+		return
+	}
+
+	for _, interaction := range h.interactions {
+		if interaction.matches(r.Method, r.URL) {
+			log.Println("Found recording: " + r.Method + " " + r.URL.String())
+			writeInteraction(w, interaction)
+			return
+		}
+	}
+
+	h.t.Fatalf("no recording in %s for: %s %s", h.fixtureFile, r.Method, r.URL.String())
+}
+
+// record forwards the request to the tenant and records its response
+func (h *RecordingURLHandler) record(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		h.t.Fatalf("could not read request body: %v", err)
+	}
+
+	req, err := http.NewRequest(r.Method, h.tenant+r.URL.RequestURI(), bytes.NewReader(body))
+	if err != nil {
+		h.t.Fatalf("could not create request: %v", err)
+	}
+	req.Header.Set("Content-Type", r.Header.Get("Content-Type"))
+	req.Header.Set("Authorization", "Api-Token "+h.apiToken)
+
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		h.t.Fatalf("could not send request to %s: %v", h.tenant, err)
+	}
+	defer resp.Body.Close()
+
+	responseBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		h.t.Fatalf("could not read response from %s: %v", h.tenant, err)
+	}
+
+	interaction := Interaction{
+		Method:     r.Method,
+		URL:        r.URL.String(),
+		StatusCode: resp.StatusCode,
+	}
+	if json.Valid(responseBody) {
+		interaction.Response = responseBody
+	} else {
+		interaction.ResponseText = string(responseBody)
+	}
+
+	h.addInteraction(interaction, r.URL)
+	writeInteraction(w, interaction)
+}
+
+// addInteraction adds the interaction unless the same request was already recorded, as only the first response is replayed anyway
+func (h *RecordingURLHandler) addInteraction(interaction Interaction, requestedURL *url.URL) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for _, recorded := range h.interactions {
+		if recorded.matches(interaction.Method, requestedURL) {
+			return
+		}
+	}
+	h.interactions = append(h.interactions, interaction)
+}
+
+// matches returns whether the interaction is a recording of a request with the method and URL
+func (i Interaction) matches(method string, requestedURL *url.URL) bool {
+	if i.Method != method {
+		return false
+	}
+
+	recordedURL, err := url.Parse(i.URL)
+	if err != nil {
+		return false
+	}
+	return recordedURL.Path == requestedURL.Path && reflect.DeepEqual(recordedURL.Query(), requestedURL.Query())
+}
+
+func writeInteraction(w http.ResponseWriter, interaction Interaction) {
+	body := []byte(interaction.ResponseText)
+	if len(interaction.Response) > 0 {
+		body = interaction.Response
+		w.Header().Set("Content-Type", "application/json")
+	}
+
+	w.WriteHeader(interaction.StatusCode)
+	_, err := w.Write(body)
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testFixtureFile = "./testdata/recordings/recorded.json"
+
+// Tests that responses recorded from a tenant are written to the fixture file and replayed from it regardless of the order of the query parameters
+func TestRecordingURLHandler_RecordsAndReplays(t *testing.T) {
+	changeToTempDir(t)
+
+	tenant := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Api-Token dt0c01.test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/api/v2/metrics/query":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"totalCount":1}`))
+		case "/api/config/v1/dashboards/12345678-1111-4444-8888-123456789012":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte("not found"))
+		default:
+			t.Errorf("unexpected request: %s", r.URL.String())
+		}
+	}))
+	defer tenant.Close()
+
+	t.Run("record", func(t *testing.T) {
+		setRecordingEnv(t, tenant.URL, "dt0c01.test")
+
+		handler := NewRecordingURLHandler(t, testFixtureFile)
+
+		assertResponse(t, handler, "/api/v2/metrics/query?metricSelector=builtin%3Aservice.response.time&resolution=Inf", http.StatusOK, `{"totalCount":1}`)
+		assertResponse(t, handler, "/api/config/v1/dashboards/12345678-1111-4444-8888-123456789012", http.StatusNotFound, "not found")
+	})
+
+	content, err := ioutil.ReadFile(testFixtureFile)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, string(content), "dt0c01.test", "the API token must not be recorded")
+	}
+
+	t.Run("replay", func(t *testing.T) {
+		handler := NewRecordingURLHandler(t, testFixtureFile)
+
+		assertResponse(t, handler, "/api/v2/metrics/query?resolution=Inf&metricSelector=builtin:service.response.time", http.StatusOK, `{"totalCount":1}`)
+		assertResponse(t, handler, "/api/config/v1/dashboards/12345678-1111-4444-8888-123456789012", http.StatusNotFound, "not found")
+	})
+}
+
+func assertResponse(t *testing.T, handler http.Handler, url string, expectedStatusCode int, expectedBody string) {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, url, nil))
+
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	if recorder.Header().Get("Content-Type") == "application/json" {
+		assert.JSONEq(t, expectedBody, recorder.Body.String())
+	} else {
+		assert.Equal(t, expectedBody, recorder.Body.String())
+	}
+}
+
+// changeToTempDir runs the test in a temporary directory, so that the fixture file is not written to the local 'testdata' folder
+func changeToTempDir(t *testing.T) {
+	workingDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(workingDir)
+	})
+}
+
+func setRecordingEnv(t *testing.T, tenant string, apiToken string) {
+	os.Setenv(RecordDynatraceAPIEnvName, "true")
+	os.Setenv("DT_TENANT", tenant)
+	os.Setenv("DT_API_TOKEN", apiToken)
+	t.Cleanup(func() {
+		os.Unsetenv(RecordDynatraceAPIEnvName)
+		os.Unsetenv("DT_TENANT")
+		os.Unsetenv("DT_API_TOKEN")
+	})
+}
@@ -0,0 +1,25 @@
+package tracing
+
+import (
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// StartEventSpan starts the span for handling the event as child of the span of the sender, if any. It returns a copy of the event
+// carrying the context of the new span, so that all spans started for clients created from it become its children
+func StartEventSpan(event cloudevents.Event) (trace.Span, cloudevents.Event) {
+	span := StartSpan("handle "+event.Type(), FromEvent(event), trace.SpanKindConsumer)
+	span.SetAttributes(
+		attribute.String("cloudevents.event_id", event.ID()),
+		attribute.String("cloudevents.event_type", event.Type()),
+		attribute.String("cloudevents.event_source", event.Source()),
+	)
+	if keptnContext, ok := event.Extensions()["shkeptncontext"].(string); ok {
+		span.SetAttributes(attribute.String("keptn.context", keptnContext))
+	}
+
+	tracedEvent := event.Clone()
+	SetEventSpanContext(&tracedEvent, span.SpanContext())
+	return span, tracedEvent
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+const exportBatchSize = 256
+const exportInterval = 5 * time.Second
+const exportQueueSize = 2048
+
+// Init sets up the tracer provider and the export of spans via OTLP/HTTP if an endpoint is configured. Without one, spans are still
+// propagated to Dynatrace, Keptn and outgoing events, but not exported
+func Init(cfg *env.Config) {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceNameKey.String(cfg.OTelServiceName))),
+	}
+
+	if cfg.OTLPTracesEndpoint == "" {
+		log.Debug("No OTLP endpoint configured, spans will not be exported")
+	} else {
+		exporter, err := newOTLPExporter(cfg.OTLPTracesEndpoint, parseHeaders(cfg.OTLPHeaders))
+		if err != nil {
+			log.WithError(err).Error("Could not set up the export of spans via OTLP, spans will not be exported")
+		} else {
+			log.WithField("endpoint", cfg.OTLPTracesEndpoint).Info("Exporting spans via OTLP")
+			options = append(options, sdktrace.WithBatcher(exporter,
+				sdktrace.WithBatchTimeout(exportInterval),
+				sdktrace.WithMaxExportBatchSize(exportBatchSize),
+				sdktrace.WithMaxQueueSize(exportQueueSize),
+			))
+		}
+	}
+
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(options...))
+	otel.SetTextMapPropagator(propagator)
+}
+
+// Shutdown sends all spans that have not been exported yet, e.g. before the process exits. It returns once they are sent or the context is done
+func Shutdown(ctx context.Context) error {
+	shutdowner, ok := otel.GetTracerProvider().(interface {
+		Shutdown(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+
+	return shutdowner.Shutdown(ctx)
+}
+
+// newOTLPExporter creates an exporter sending spans to the OTLP/HTTP endpoint, e.g. https://abc12345.live.dynatrace.com/api/v2/otlp/v1/traces
+func newOTLPExporter(endpoint string, headers map[string]string) (*otlptrace.Exporter, error) {
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTLP endpoint %s: %w", endpoint, err)
+	}
+
+	options := []otlptracehttp.Option{
+		otlptracehttp.WithEndpoint(endpointURL.Host),
+		otlptracehttp.WithURLPath(endpointURL.Path),
+		otlptracehttp.WithHeaders(headers),
+	}
+	if endpointURL.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+
+	return otlptracehttp.New(context.Background(), options...)
+}
+
+// parseHeaders parses comma-separated key=value pairs with URL encoded values as specified for OTEL_EXPORTER_OTLP_HEADERS
+func parseHeaders(headers string) map[string]string {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(headers, ",") {
+		keyAndValue := strings.SplitN(pair, "=", 2)
+		if len(keyAndValue) != 2 || strings.TrimSpace(keyAndValue[0]) == "" {
+			continue
+		}
+
+		value, err := url.PathUnescape(strings.TrimSpace(keyAndValue[1]))
+		if err != nil {
+			log.WithError(err).WithField("header", strings.TrimSpace(keyAndValue[0])).Warn("Could not decode OTLP header value")
+			continue
+		}
+		parsed[strings.TrimSpace(keyAndValue[0])] = value
+	}
+	return parsed
+}
@@ -0,0 +1,138 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+// setRecordingTracerProvider records all ended spans until the end of the test
+func setRecordingTracerProvider(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+	})
+	return exporter
+}
+
+func TestStartEventSpan_ContinuesTraceOfSender(t *testing.T) {
+	e := setRecordingTracerProvider(t)
+
+	event := cloudevents.NewEvent()
+	event.SetID("event-1")
+	event.SetType("sh.keptn.event.get-sli.triggered")
+	event.SetExtension(TraceParentExtension, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	eventSpan, tracedEvent := StartEventSpan(event)
+	apiSpan := StartSpan("GET /api/v2/metrics/query", FromEvent(tracedEvent), trace.SpanKindClient)
+	EndSpan(apiSpan, errors.New("404 Not Found"))
+	EndSpan(eventSpan, nil)
+	EndSpan(eventSpan, nil)
+
+	sender := FromEvent(event)
+	assert.Equal(t, sender.TraceID(), eventSpan.SpanContext().TraceID())
+	assert.Equal(t, sender.TraceID(), apiSpan.SpanContext().TraceID())
+
+	spans := e.GetSpans()
+	if assert.Len(t, spans, 2) {
+		assert.Equal(t, eventSpan.SpanContext().SpanID(), spans[0].Parent.SpanID())
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Equal(t, sender.SpanID(), spans[1].Parent.SpanID())
+		assert.Equal(t, "handle sh.keptn.event.get-sli.triggered", spans[1].Name)
+		assert.Equal(t, trace.SpanKindConsumer, spans[1].SpanKind)
+		assert.Contains(t, spans[1].Attributes, attribute.String("cloudevents.event_id", "event-1"))
+	}
+}
+
+func TestStartSpan_NotSampledIsNotExported(t *testing.T) {
+	e := setRecordingTracerProvider(t)
+
+	event := cloudevents.NewEvent()
+	event.SetExtension(TraceParentExtension, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	EndSpan(StartSpan("get shipyard", FromEvent(event), trace.SpanKindClient), nil)
+
+	assert.Empty(t, e.GetSpans())
+}
+
+func TestInit_ExportsSpansViaOTLP(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	var mutex sync.Mutex
+	var authorization string
+	var exported []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/otlp/v1/traces", r.URL.Path)
+
+		body, _ := ioutil.ReadAll(r.Body)
+		request := &collectortrace.ExportTraceServiceRequest{}
+		assert.NoError(t, proto.Unmarshal(body, request))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		authorization = r.Header.Get("Authorization")
+		for _, resourceSpans := range request.ResourceSpans {
+			assert.Equal(t, "service.name", resourceSpans.Resource.Attributes[0].Key)
+			assert.Equal(t, "dynatrace-service", resourceSpans.Resource.Attributes[0].Value.GetStringValue())
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					exported = append(exported, span.Name)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	Init(&env.Config{
+		OTLPTracesEndpoint: server.URL + "/api/v2/otlp/v1/traces",
+		OTLPHeaders:        "Authorization=Api-Token%20dt0c01.abc",
+		OTelServiceName:    "dynatrace-service",
+	})
+
+	for i := 0; i < exportBatchSize+1; i++ {
+		EndSpan(StartSpan("get shipyard", trace.SpanContext{}, trace.SpanKindClient), nil)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, Shutdown(ctx))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, "Api-Token dt0c01.abc", authorization)
+	assert.Len(t, exported, exportBatchSize+1)
+}
+
+func TestInit_PropagatesSpansWithoutEndpoint(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	Init(&env.Config{OTelServiceName: "dynatrace-service"})
+
+	span := StartSpan("handle sh.keptn.event.get-sli.triggered", trace.SpanContext{}, trace.SpanKindConsumer)
+	defer EndSpan(span, nil)
+
+	assert.True(t, span.SpanContext().IsValid())
+	assert.True(t, span.SpanContext().IsSampled())
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationScope = "github.com/keptn-contrib/dynatrace-service"
+
+// StartSpan starts a new span as child of parent or, if parent is not valid, as root of a new trace
+func StartSpan(name string, parent trace.SpanContext, kind trace.SpanKind) trace.Span {
+	_, span := otel.Tracer(instrumentationScope).Start(trace.ContextWithSpanContext(context.Background(), parent), name, trace.WithSpanKind(kind))
+	return span
+}
+
+// EndSpan ends the span, marking it as failed if err is not nil. Subsequent calls have no effect
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceParentExtension is the name of the cloud event extension and of the HTTP header carrying the W3C trace context
+const TraceParentExtension = "traceparent"
+
+// propagator propagates span contexts as W3C trace context, also if no global propagator is set up
+var propagator = propagation.TraceContext{}
+
+// eventCarrier carries the W3C trace context in the extensions of a cloud event
+type eventCarrier struct {
+	event *cloudevents.Event
+}
+
+// Get returns the value of the extension
+func (c eventCarrier) Get(key string) string {
+	value, _ := c.event.Extensions()[key].(string)
+	return value
+}
+
+// Set sets the extension. The names of cloud event extensions must be lower case, like the keys of the W3C trace context
+func (c eventCarrier) Set(key string, value string) {
+	c.event.SetExtension(key, value)
+}
+
+// Keys returns the names of all extensions of the event
+func (c eventCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.event.Extensions()))
+	for key := range c.event.Extensions() {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// FromEvent returns the span context carried by the traceparent extension of the event, which is invalid if there is none
+func FromEvent(event cloudevents.Event) trace.SpanContext {
+	ctx := propagator.Extract(context.Background(), eventCarrier{event: &event})
+	return trace.SpanContextFromContext(ctx)
+}
+
+// SetEventSpanContext sets the traceparent extension of the event, so that the receiver can continue the trace
+func SetEventSpanContext(event *cloudevents.Event, spanContext trace.SpanContext) {
+	propagator.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), eventCarrier{event: event})
+}
+
+// InjectHTTPHeaders sets the traceparent header of an HTTP request, so that the called service can continue the trace
+func InjectHTTPHeaders(header http.Header, spanContext trace.SpanContext) {
+	propagator.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), propagation.HeaderCarrier(header))
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"testing"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromEvent(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceParent string
+		wantValid   bool
+		wantSampled bool
+	}{
+		{
+			name:        "sampled",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			wantValid:   true,
+			wantSampled: true,
+		},
+		{
+			name:        "not sampled",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			wantValid:   true,
+		},
+		{
+			name:        "all zero trace ID",
+			traceParent: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		},
+		{
+			name:        "short span ID",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902-01",
+		},
+		{
+			name:        "invalid hex",
+			traceParent: "00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		},
+		{
+			name: "empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := cloudevents.NewEvent()
+			if tt.traceParent != "" {
+				event.SetExtension(TraceParentExtension, tt.traceParent)
+			}
+
+			got := FromEvent(event)
+			assert.Equal(t, tt.wantValid, got.IsValid())
+			if !tt.wantValid {
+				return
+			}
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.TraceID().String())
+			assert.Equal(t, "00f067aa0ba902b7", got.SpanID().String())
+			assert.Equal(t, tt.wantSampled, got.IsSampled())
+
+			tracedEvent := cloudevents.NewEvent()
+			SetEventSpanContext(&tracedEvent, got)
+			assert.Equal(t, tt.traceParent, tracedEvent.Extensions()[TraceParentExtension])
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package dashboard lets custom builds of the dynatrace-service generate SLIs from further tile types of SLI dashboards, e.g. proprietary ones.
+// Tile processors are registered using RegisterTileProcessor, typically in an init function of a package imported by the main package of the custom build
+package dashboard
+
+import (
+	"sync"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/pkg/dynatrace"
+	keptncommon "github.com/keptn/go-utils/pkg/lib"
+	keptnv2 "github.com/keptn/go-utils/pkg/lib/v0_2_0"
+)
+
+// TileResult is an SLI generated from a tile
+type TileResult struct {
+	// SLIName is the name of the SLI
+	SLIName string
+	// SLIQuery is the query of the SLI as stored in the SLI file, e.g. "metricSelector=..."
+	SLIQuery string
+	// Objective is the objective of the SLI or nil if the SLI is only informational
+	Objective *keptncommon.SLO
+	// SLIResult is the value of the SLI. It is nil if only the definitions are processed
+	SLIResult *keptnv2.SLIResult
+}
+
+// EventContext is the context of the event a dashboard is processed for
+type EventContext struct {
+	Project string
+	Stage   string
+	Service string
+	Labels  map[string]string
+	// CustomFilters are the SLI filters of the event, which should be applied to the queries of the tiles
+	CustomFilters []*keptnv2.SLIFilter
+}
+
+// TileProcessor generates SLIs and SLOs from the tiles of a dashboard
+type TileProcessor interface {
+	// CanProcess returns whether the processor generates the SLIs of the tile
+	CanProcess(tile *dynatrace.Tile) bool
+
+	// Process queries the SLI values of the tile and generates their definitions and objectives
+	Process(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult
+
+	// ProcessDefinitions generates the SLI definitions and objectives of the tile without querying any values
+	ProcessDefinitions(tile *dynatrace.Tile, dashboardFilter *dynatrace.DashboardFilter) []*TileResult
+}
+
+// TileProcessorFactory creates a TileProcessor for processing a dashboard for an event and the timeframe of its evaluation.
+// client sends requests to the Dynatrace tenant configured for the event
+type TileProcessorFactory func(client dynatrace.ClientInterface, eventContext EventContext, startUnix time.Time, endUnix time.Time) TileProcessor
+
+var (
+	tileProcessorFactoriesMutex sync.RWMutex
+	tileProcessorFactories      []TileProcessorFactory
+)
+
+// RegisterTileProcessor registers a factory of a tile processor used for all dashboards processed afterwards.
+// Registered processors take precedence over the default ones in the order of their registration, so they may also replace the processing of a supported tile type
+func RegisterTileProcessor(factory TileProcessorFactory) {
+	tileProcessorFactoriesMutex.Lock()
+	defer tileProcessorFactoriesMutex.Unlock()
+
+	tileProcessorFactories = append(tileProcessorFactories, factory)
+}
+
+// RegisteredTileProcessors returns the factories of the registered tile processors in the order of their registration
+func RegisteredTileProcessors() []TileProcessorFactory {
+	tileProcessorFactoriesMutex.RLock()
+	defer tileProcessorFactoriesMutex.RUnlock()
+
+	return append([]TileProcessorFactory{}, tileProcessorFactories...)
+}
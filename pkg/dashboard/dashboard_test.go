@@ -0,0 +1,33 @@
+package dashboard
+
+import (
+	"testing"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/pkg/dynatrace"
+	"github.com/stretchr/testify/assert"
+)
+
+// Tests that tile processors are returned in the order of their registration and that the returned factories cannot change the registered ones
+func TestRegisterTileProcessor(t *testing.T) {
+	var created []string
+	newFactory := func(name string) TileProcessorFactory {
+		return func(_ dynatrace.ClientInterface, _ EventContext, _ time.Time, _ time.Time) TileProcessor {
+			created = append(created, name)
+			return nil
+		}
+	}
+
+	registeredCount := len(RegisteredTileProcessors())
+	RegisterTileProcessor(newFactory("first"))
+	RegisterTileProcessor(newFactory("second"))
+
+	factories := RegisteredTileProcessors()
+	if assert.Len(t, factories, registeredCount+2) {
+		factories[registeredCount] = newFactory("replaced")
+		for _, factory := range RegisteredTileProcessors()[registeredCount:] {
+			factory(nil, EventContext{}, time.Time{}, time.Time{})
+		}
+		assert.Equal(t, []string{"first", "second"}, created)
+	}
+}
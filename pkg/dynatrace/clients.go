@@ -0,0 +1,221 @@
+//go:generate moq --skip-ensure -pkg dynatrace_mock -out ./mock/client_mock.go . ClientInterface MetricsClientInterface EntitiesClientInterface ProblemsClientInterface DashboardsClientInterface
+package dynatrace
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+)
+
+// MetricsClientInterface retrieves metric definitions and queries metrics using the Metrics API v2
+type MetricsClientInterface interface {
+	GetByID(metricID string) (*MetricDefinition, error)
+	GetByQuery(metricsQuery string) (*MetricsQueryResult, error)
+}
+
+// EntitiesClientInterface retrieves entities using the Monitored entities API v2
+type EntitiesClientInterface interface {
+	GetKeptnManagedServices() ([]Entity, error)
+}
+
+// ProblemsClientInterface retrieves, comments and closes problems using the Problems API v2
+type ProblemsClientInterface interface {
+	GetByQuery(problemQuery string, startUnix time.Time, endUnix time.Time) (*ProblemQueryResult, error)
+	GetById(problemID string) (*Problem, error)
+	AddProblemComment(problemID string, comment string) error
+	CloseProblem(problemID string, message string) error
+}
+
+// DashboardsClientInterface retrieves, creates and deletes dashboards using the Dashboards API
+type DashboardsClientInterface interface {
+	GetAll() (*Dashboards, error)
+	GetByID(dashboardID string) (*Dashboard, error)
+	Create(dashboard *Dashboard) error
+	Delete(dashboardID string) error
+}
+
+var _ MetricsClientInterface = (*MetricsClient)(nil)
+var _ EntitiesClientInterface = (*EntitiesClient)(nil)
+var _ ProblemsClientInterface = (*ProblemsClient)(nil)
+var _ DashboardsClientInterface = (*DashboardsClient)(nil)
+
+// convert copies the internal type from to the exported type to and vice versa. Both types have the same JSON representation
+func convert(from interface{}, to interface{}) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+// MetricsClient is the default implementation of MetricsClientInterface
+type MetricsClient struct {
+	client *dynatrace.MetricsClient
+}
+
+// NewMetricsClient creates a new MetricsClient
+func NewMetricsClient(client ClientInterface) *MetricsClient {
+	return &MetricsClient{client: dynatrace.NewMetricsClient(toInternalClient(client))}
+}
+
+// GetByID retrieves the definition of a metric
+func (mc *MetricsClient) GetByID(metricID string) (*MetricDefinition, error) {
+	metricDefinition, err := mc.client.GetByID(metricID)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &MetricDefinition{}
+	err = convert(metricDefinition, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByQuery queries metrics, e.g. metricSelector=builtin:service.response.time&from=now-2h
+func (mc *MetricsClient) GetByQuery(metricsQuery string) (*MetricsQueryResult, error) {
+	queryResult, err := mc.client.GetByQuery(metricsQuery)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &MetricsQueryResult{}
+	err = convert(queryResult, result)
+	if err != nil {
+		return nil, err
+	}
+	result.Truncated = queryResult.Truncated
+	return result, nil
+}
+
+// EntitiesClient is the default implementation of EntitiesClientInterface
+type EntitiesClient struct {
+	client *dynatrace.EntitiesClient
+}
+
+// NewEntitiesClient creates a new EntitiesClient
+func NewEntitiesClient(client ClientInterface) *EntitiesClient {
+	return &EntitiesClient{client: dynatrace.NewEntitiesClient(toInternalClient(client))}
+}
+
+// GetKeptnManagedServices retrieves the services tagged with keptn_managed and keptn_service
+func (ec *EntitiesClient) GetKeptnManagedServices() ([]Entity, error) {
+	entities, err := ec.client.GetKeptnManagedServices()
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	var result []Entity
+	err = convert(entities, &result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ProblemsClient is the default implementation of ProblemsClientInterface
+type ProblemsClient struct {
+	client *dynatrace.ProblemsV2Client
+}
+
+// NewProblemsClient creates a new ProblemsClient
+func NewProblemsClient(client ClientInterface) *ProblemsClient {
+	return &ProblemsClient{client: dynatrace.NewProblemsV2Client(toInternalClient(client))}
+}
+
+// GetByQuery retrieves the problems matching the problem selector in the timeframe
+func (pc *ProblemsClient) GetByQuery(problemQuery string, startUnix time.Time, endUnix time.Time) (*ProblemQueryResult, error) {
+	queryResult, err := pc.client.GetByQuery(problemQuery, startUnix, endUnix)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &ProblemQueryResult{}
+	err = convert(queryResult, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetById retrieves a problem
+func (pc *ProblemsClient) GetById(problemID string) (*Problem, error) {
+	problem, err := pc.client.GetById(problemID)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &Problem{}
+	err = convert(problem, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// AddProblemComment adds a comment to a problem
+func (pc *ProblemsClient) AddProblemComment(problemID string, comment string) error {
+	return toAPIError(pc.client.AddProblemComment(problemID, comment))
+}
+
+// CloseProblem closes a problem with a message
+func (pc *ProblemsClient) CloseProblem(problemID string, message string) error {
+	return toAPIError(pc.client.CloseProblem(problemID, message))
+}
+
+// DashboardsClient is the default implementation of DashboardsClientInterface
+type DashboardsClient struct {
+	client *dynatrace.DashboardsClient
+}
+
+// NewDashboardsClient creates a new DashboardsClient
+func NewDashboardsClient(client ClientInterface) *DashboardsClient {
+	return &DashboardsClient{client: dynatrace.NewDashboardsClient(toInternalClient(client))}
+}
+
+// GetAll retrieves the ID, name and owner of all dashboards
+func (dc *DashboardsClient) GetAll() (*Dashboards, error) {
+	dashboards, err := dc.client.GetAll()
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &Dashboards{}
+	err = convert(dashboards, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetByID retrieves a dashboard
+func (dc *DashboardsClient) GetByID(dashboardID string) (*Dashboard, error) {
+	dashboard, err := dc.client.GetByID(dashboardID)
+	if err != nil {
+		return nil, toAPIError(err)
+	}
+
+	result := &Dashboard{}
+	err = convert(dashboard, result)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Create creates a dashboard
+func (dc *DashboardsClient) Create(dashboard *Dashboard) error {
+	internalDashboard := &dynatrace.Dashboard{}
+	err := convert(dashboard, internalDashboard)
+	if err != nil {
+		return err
+	}
+	return toAPIError(dc.client.Create(internalDashboard))
+}
+
+// Delete deletes a dashboard
+func (dc *DashboardsClient) Delete(dashboardID string) error {
+	return toAPIError(dc.client.Delete(dashboardID))
+}
@@ -0,0 +1,230 @@
+// Package dynatrace exports the Dynatrace API clients of the dynatrace-service, so that other Keptn integrations can reuse them.
+// The types of this package are independent of the internal packages of the dynatrace-service, the clients delegate to the clients used by the dynatrace-service itself, so both always behave the same.
+// The interfaces of the clients are mocked in package dynatrace_mock for testing
+package dynatrace
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/keptn-contrib/dynatrace-service/internal/credentials"
+	"github.com/keptn-contrib/dynatrace-service/internal/dynatrace"
+	"github.com/keptn-contrib/dynatrace-service/internal/env"
+)
+
+// Credentials are the URL of the Dynatrace tenant, always prefixed with https:// or http://, and the API token
+type Credentials struct {
+	// Tenant is the base URL of the Dynatrace tenant. This is always prefixed with "https://" or "http://"
+	Tenant   string `json:"DT_TENANT" yaml:"DT_TENANT"`
+	ApiToken string `json:"DT_API_TOKEN" yaml:"DT_API_TOKEN"`
+	// SecondaryApiToken is optional and used if the Dynatrace API rejects ApiToken, e.g. while the token is being rotated
+	SecondaryApiToken string `json:"DT_API_TOKEN_SECONDARY,omitempty" yaml:"DT_API_TOKEN_SECONDARY,omitempty"`
+	// ConfigAPIURL is optional and used instead of Tenant as the base URL of the configuration API, e.g. if only Tenant points at an Environment ActiveGate
+	ConfigAPIURL string `json:"DT_CONFIG_API_URL,omitempty" yaml:"DT_CONFIG_API_URL,omitempty"`
+}
+
+// TLSOptions defines how the connection to the Dynatrace API is secured. Unset options fall back to the environment variables of the dynatrace-service
+type TLSOptions struct {
+	// SSLVerify defines whether the certificate of the Dynatrace API has to be valid
+	SSLVerify *bool `json:"sslVerify,omitempty" yaml:"sslVerify,omitempty"`
+	// CABundle is the path of a PEM encoded CA bundle, e.g. a mounted secret or config map, used in addition to the system certificates
+	CABundle string `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+	// MinVersion is the minimum TLS version, one of 1.0, 1.1, 1.2 or 1.3
+	MinVersion string `json:"minVersion,omitempty" yaml:"minVersion,omitempty"`
+}
+
+// APITimeouts are the timeouts of requests per family of Dynatrace APIs
+type APITimeouts struct {
+	// Metrics is the timeout of the metrics, SLO and USQL APIs, which may take long for large timeframes
+	Metrics time.Duration
+	// Entities is the timeout of the entities API
+	Entities time.Duration
+	// Events is the timeout of the events API
+	Events time.Duration
+	// Problems is the timeout of the problems and security problems APIs
+	Problems time.Duration
+	// Dashboards is the timeout of the dashboards API
+	Dashboards time.Duration
+}
+
+// APIError is returned if the Dynatrace API responded with an error
+type APIError struct {
+	code    int
+	message string
+	err     error
+}
+
+// Code returns the HTTP status code of the response
+func (e *APIError) Code() int {
+	return e.code
+}
+
+// Message returns the error message of the response
+func (e *APIError) Message() string {
+	return e.message
+}
+
+func (e *APIError) Error() string {
+	return e.err.Error()
+}
+
+// toAPIError returns an APIError if err was caused by an error response of the Dynatrace API, otherwise err
+func toAPIError(err error) error {
+	var apiErr *dynatrace.APIError
+	if err != nil && errors.As(err, &apiErr) {
+		return &APIError{code: apiErr.Code(), message: apiErr.Message(), err: err}
+	}
+	return err
+}
+
+// ClientInterface sends requests to the Dynatrace API
+type ClientInterface interface {
+	Get(apiPath string) ([]byte, error)
+	Post(apiPath string, body []byte) ([]byte, error)
+	PostPlainText(apiPath string, body []byte) ([]byte, error)
+	Put(apiPath string, body []byte) ([]byte, error)
+	Delete(apiPath string) ([]byte, error)
+
+	Credentials() *Credentials
+}
+
+// Client is the default implementation of ClientInterface
+type Client struct {
+	client *dynatrace.Client
+}
+
+var _ ClientInterface = (*Client)(nil)
+
+// Options defines how the Client connects to the Dynatrace API
+type Options struct {
+	// HTTPClient is used to send requests to the Dynatrace API. If it is set, TLS is ignored
+	HTTPClient *http.Client
+	// TLS defines how the connection is secured if no HTTPClient is set
+	TLS *TLSOptions
+	// Timeouts overrides the timeouts per family of Dynatrace APIs, which are otherwise defined by the environment variables of the dynatrace-service
+	Timeouts *APITimeouts
+}
+
+// NewClient creates a new Client for the Dynatrace tenant of the credentials. The URL of the tenant is validated and normalized,
+// so both SaaS and Managed URLs, e.g. https://managed.example.com/e/<environment-id>/, are supported
+func NewClient(dtCredentials *Credentials, options Options) (*Client, error) {
+	tenant, err := credentials.NormalizeTenantURL(dtCredentials.Tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizedCredentials := toInternalCredentials(dtCredentials)
+	normalizedCredentials.Tenant = tenant
+
+	var client *dynatrace.Client
+	if options.HTTPClient != nil {
+		client = dynatrace.NewClientWithHTTP(normalizedCredentials, options.HTTPClient)
+	} else {
+		var tlsOptions *dynatrace.TLSOptions
+		if options.TLS != nil {
+			tlsOptions = &dynatrace.TLSOptions{
+				SSLVerify:  options.TLS.SSLVerify,
+				CABundle:   options.TLS.CABundle,
+				MinVersion: options.TLS.MinVersion,
+			}
+		}
+
+		client, err = dynatrace.NewClientWithTLSOptions(normalizedCredentials, tlsOptions, env.ReadConfig())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if options.Timeouts != nil {
+		client.SetTimeouts(dynatrace.APITimeouts(*options.Timeouts))
+	}
+	return &Client{client: client}, nil
+}
+
+// Get sends a GET request to the Dynatrace API
+func (c *Client) Get(apiPath string) ([]byte, error) {
+	body, err := c.client.Get(apiPath)
+	return body, toAPIError(err)
+}
+
+// Post sends a POST request with a JSON body to the Dynatrace API
+func (c *Client) Post(apiPath string, body []byte) ([]byte, error) {
+	responseBody, err := c.client.Post(apiPath, body)
+	return responseBody, toAPIError(err)
+}
+
+// PostPlainText sends a POST request with a plain text body to the Dynatrace API
+func (c *Client) PostPlainText(apiPath string, body []byte) ([]byte, error) {
+	responseBody, err := c.client.PostPlainText(apiPath, body)
+	return responseBody, toAPIError(err)
+}
+
+// Put sends a PUT request with a JSON body to the Dynatrace API
+func (c *Client) Put(apiPath string, body []byte) ([]byte, error) {
+	responseBody, err := c.client.Put(apiPath, body)
+	return responseBody, toAPIError(err)
+}
+
+// Delete sends a DELETE request to the Dynatrace API
+func (c *Client) Delete(apiPath string) ([]byte, error) {
+	body, err := c.client.Delete(apiPath)
+	return body, toAPIError(err)
+}
+
+// Credentials returns the credentials of the Dynatrace tenant
+func (c *Client) Credentials() *Credentials {
+	dtCredentials := c.client.Credentials()
+	return &Credentials{
+		Tenant:            dtCredentials.Tenant,
+		ApiToken:          dtCredentials.ApiToken,
+		SecondaryApiToken: dtCredentials.SecondaryApiToken,
+		ConfigAPIURL:      dtCredentials.ConfigAPIURL,
+	}
+}
+
+func toInternalCredentials(dtCredentials *Credentials) *credentials.DTCredentials {
+	return &credentials.DTCredentials{
+		Tenant:            dtCredentials.Tenant,
+		ApiToken:          dtCredentials.ApiToken,
+		SecondaryApiToken: dtCredentials.SecondaryApiToken,
+		ConfigAPIURL:      dtCredentials.ConfigAPIURL,
+	}
+}
+
+// internalClient adapts a ClientInterface, e.g. a mock, to the client interface of the internal clients
+type internalClient struct {
+	client ClientInterface
+}
+
+func (c *internalClient) Get(apiPath string) ([]byte, error) {
+	return c.client.Get(apiPath)
+}
+
+func (c *internalClient) Post(apiPath string, body []byte) ([]byte, error) {
+	return c.client.Post(apiPath, body)
+}
+
+func (c *internalClient) PostPlainText(apiPath string, body []byte) ([]byte, error) {
+	return c.client.PostPlainText(apiPath, body)
+}
+
+func (c *internalClient) Put(apiPath string, body []byte) ([]byte, error) {
+	return c.client.Put(apiPath, body)
+}
+
+func (c *internalClient) Delete(apiPath string) ([]byte, error) {
+	return c.client.Delete(apiPath)
+}
+
+func (c *internalClient) Credentials() *credentials.DTCredentials {
+	return toInternalCredentials(c.client.Credentials())
+}
+
+// toInternalClient returns the internal client of a Client, so that errors of the Dynatrace API are handled the same way as by the dynatrace-service, or adapts any other ClientInterface
+func toInternalClient(client ClientInterface) dynatrace.ClientInterface {
+	if c, ok := client.(*Client); ok {
+		return c.client
+	}
+	return &internalClient{client: client}
+}
@@ -0,0 +1,85 @@
+package dynatrace_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/keptn-contrib/dynatrace-service/pkg/dynatrace"
+	dynatrace_mock "github.com/keptn-contrib/dynatrace-service/pkg/dynatrace/mock"
+)
+
+func TestNewClient_WithHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/metrics/builtin:service.response.time", r.URL.Path)
+		assert.Equal(t, "Api-Token my-token", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"metricId": "builtin:service.response.time", "unit": "MicroSecond"}`))
+	}))
+	defer server.Close()
+
+	client, err := dynatrace.NewClient(&dynatrace.Credentials{Tenant: server.URL, ApiToken: "my-token"}, dynatrace.Options{HTTPClient: server.Client()})
+	assert.NoError(t, err)
+
+	metricDefinition, err := dynatrace.NewMetricsClient(client).GetByID("builtin:service.response.time")
+	assert.NoError(t, err)
+	assert.Equal(t, "MicroSecond", metricDefinition.Unit)
+}
+
+func TestMetricsClientMock(t *testing.T) {
+	var metricsClient dynatrace.MetricsClientInterface = &dynatrace_mock.MetricsClientInterfaceMock{
+		GetByIDFunc: func(metricID string) (*dynatrace.MetricDefinition, error) {
+			return &dynatrace.MetricDefinition{MetricID: metricID}, nil
+		},
+	}
+
+	metricDefinition, err := metricsClient.GetByID("builtin:service.response.time")
+	assert.NoError(t, err)
+	assert.Equal(t, "builtin:service.response.time", metricDefinition.MetricID)
+}
+
+func TestNewClient_ReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": {"code": 404, "message": "Metric not found"}}`))
+	}))
+	defer server.Close()
+
+	client, err := dynatrace.NewClient(&dynatrace.Credentials{Tenant: server.URL, ApiToken: "my-token"}, dynatrace.Options{HTTPClient: server.Client()})
+	assert.NoError(t, err)
+
+	_, err = dynatrace.NewMetricsClient(client).GetByID("builtin:unknown")
+	var apiErr *dynatrace.APIError
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusNotFound, apiErr.Code())
+		assert.Equal(t, "Metric not found", apiErr.Message())
+	}
+}
+
+func TestDashboardsClient_WithClientMock(t *testing.T) {
+	var postedBody []byte
+	client := &dynatrace_mock.ClientInterfaceMock{
+		GetFunc: func(apiPath string) ([]byte, error) {
+			assert.Equal(t, "/api/config/v1/dashboards/12345", apiPath)
+			return []byte(`{"id": "12345", "dashboardMetadata": {"name": "KQG;project=sockshop;service=carts;stage=staging"}, "tiles": [{"name": "Response time", "tileType": "DATA_EXPLORER", "queries": [{"id": "A", "metric": "builtin:service.response.time", "filterBy": {"criteria": [{"value": "1", "evaluator": "GT"}]}}]}]}`), nil
+		},
+		PostFunc: func(apiPath string, body []byte) ([]byte, error) {
+			assert.Equal(t, "/api/config/v1/dashboards", apiPath)
+			postedBody = body
+			return []byte(`{}`), nil
+		},
+	}
+
+	dashboardsClient := dynatrace.NewDashboardsClient(client)
+	dashboard, err := dashboardsClient.GetByID("12345")
+	assert.NoError(t, err)
+	if assert.Len(t, dashboard.Tiles, 1) && assert.Len(t, dashboard.Tiles[0].Queries, 1) {
+		assert.Equal(t, "builtin:service.response.time", dashboard.Tiles[0].Queries[0].Metric)
+		assert.Equal(t, []dynatrace.FilterCriterion{{Value: "1", Evaluator: "GT"}}, dashboard.Tiles[0].Queries[0].FilterBy.Criteria)
+	}
+
+	err = dashboardsClient.Create(dashboard)
+	assert.NoError(t, err)
+	assert.Contains(t, string(postedBody), `"metric":"builtin:service.response.time"`)
+}
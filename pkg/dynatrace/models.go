@@ -0,0 +1,310 @@
+package dynatrace
+
+// MetricDefinition is the definition of a metric returned by /api/v2/metrics/<metricId>
+type MetricDefinition struct {
+	MetricID             string                `json:"metricId"`
+	DisplayName          string                `json:"displayName"`
+	Description          string                `json:"description"`
+	Unit                 string                `json:"unit"`
+	AggregationTypes     []string              `json:"aggregationTypes"`
+	Transformations      []string              `json:"transformations"`
+	DefaultAggregation   DefaultAggregation    `json:"defaultAggregation"`
+	DimensionDefinitions []DimensionDefinition `json:"dimensionDefinitions"`
+	EntityType           []string              `json:"entityType"`
+}
+
+// DefaultAggregation is the aggregation of a metric used if a query does not define one
+type DefaultAggregation struct {
+	Type      string  `json:"type"`
+	Parameter float64 `json:"parameter,omitempty"`
+}
+
+// DimensionDefinition is the definition of a dimension of a metric
+type DimensionDefinition struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Key         string `json:"key"`
+	DisplayName string `json:"displayName"`
+}
+
+// MetricsQueryResult is the result of /api/v2/metrics/query
+type MetricsQueryResult struct {
+	TotalCount  int                       `json:"totalCount"`
+	NextPageKey string                    `json:"nextPageKey"`
+	Result      []MetricQueryResultValues `json:"result"`
+
+	// Truncated is set if decoding stopped after the maximum number of series, i.e. the result does not contain all series
+	Truncated bool `json:"-"`
+}
+
+// MetricQueryResultValues are the series of a metric
+type MetricQueryResultValues struct {
+	MetricID string                     `json:"metricId"`
+	Data     []MetricQueryResultNumbers `json:"data"`
+	Warnings []string                   `json:"warnings,omitempty"`
+}
+
+// MetricQueryResultNumbers is a single series, i.e. the values of a combination of dimensions
+type MetricQueryResultNumbers struct {
+	Dimensions   []string          `json:"dimensions"`
+	DimensionMap map[string]string `json:"dimensionMap,omitempty"`
+	Timestamps   []int64           `json:"timestamps"`
+	Values       []float64         `json:"values"`
+}
+
+// Entity is a monitored entity returned by /api/v2/entities
+type Entity struct {
+	EntityID        string                 `json:"entityId"`
+	Type            string                 `json:"type,omitempty"`
+	DisplayName     string                 `json:"displayName"`
+	Tags            []Tag                  `json:"tags"`
+	FirstSeenTms    int64                  `json:"firstSeenTms,omitempty"`
+	LastSeenTms     int64                  `json:"lastSeenTms,omitempty"`
+	ManagementZones []EntityManagementZone `json:"managementZones,omitempty"`
+}
+
+// Tag is a tag of an entity
+type Tag struct {
+	Context              string `json:"context"`
+	Key                  string `json:"key"`
+	StringRepresentation string `json:"stringRepresentation"`
+	Value                string `json:"value,omitempty"`
+}
+
+// EntityManagementZone is a management zone an entity belongs to
+type EntityManagementZone struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProblemQueryResult is the result of /api/v2/problems
+type ProblemQueryResult struct {
+	TotalCount int       `json:"totalCount"`
+	PageSize   int       `json:"pageSize"`
+	Problems   []Problem `json:"problems"`
+}
+
+// Problem is a problem returned by /api/v2/problems
+type Problem struct {
+	ProblemID        string          `json:"problemId"`
+	DisplayID        string          `json:"displayId"`
+	Title            string          `json:"title"`
+	ImpactLevel      string          `json:"impactLevel"`
+	SeverityLevel    string          `json:"severityLevel"`
+	Status           string          `json:"status"`
+	AffectedEntities []ProblemEntity `json:"affectedEntities"`
+	ImpactedEntities []ProblemEntity `json:"impactedEntities"`
+	RootCauseEntity  ProblemEntity   `json:"rootCauseEntity"`
+	ManagementZones  []interface{}   `json:"managementZones"`
+	EntityTags       []Tag           `json:"entityTags"`
+	ProblemFilters   []ProblemFilter `json:"problemFilters"`
+	StartTime        int64           `json:"startTime"`
+	EndTime          int64           `json:"endTime"`
+}
+
+// ProblemEntity is an entity affected or impacted by a problem or its root cause
+type ProblemEntity struct {
+	EntityID EntityID `json:"entityId"`
+	Name     string   `json:"name"`
+}
+
+// EntityID identifies an entity and its type
+type EntityID struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// ProblemFilter is an alerting profile matching a problem
+type ProblemFilter struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Dashboards is the result of /api/config/v1/dashboards
+type Dashboards struct {
+	Dashboards []DashboardEntry `json:"dashboards"`
+}
+
+// DashboardEntry is the ID, name and owner of a dashboard
+type DashboardEntry struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Owner string `json:"owner"`
+}
+
+// Dashboard is a dashboard returned by /api/config/v1/dashboards/<id>
+type Dashboard struct {
+	Metadata          *Metadata         `json:"metadata,omitempty"`
+	ID                string            `json:"id,omitempty"`
+	DashboardMetadata DashboardMetadata `json:"dashboardMetadata"`
+	Tiles             []Tile            `json:"tiles"`
+}
+
+// Metadata is the configuration metadata of a dashboard
+type Metadata struct {
+	ConfigurationVersions []int  `json:"configurationVersions,omitempty"`
+	ClusterVersion        string `json:"clusterVersion,omitempty"`
+}
+
+// DashboardMetadata contains the name, owner, sharing and filter of a dashboard
+type DashboardMetadata struct {
+	Name            string           `json:"name"`
+	Shared          bool             `json:"shared"`
+	Owner           string           `json:"owner"`
+	SharingDetails  SharingDetails   `json:"sharingDetails"`
+	DashboardFilter *DashboardFilter `json:"dashboardFilter,omitempty"`
+	Tags            []string         `json:"tags,omitempty"`
+}
+
+// SharingDetails defines how a dashboard is shared
+type SharingDetails struct {
+	LinkShared bool `json:"linkShared"`
+	Published  bool `json:"published"`
+}
+
+// DashboardFilter is the timeframe and management zone of a dashboard
+type DashboardFilter struct {
+	Timeframe      string               `json:"timeframe"`
+	ManagementZone *ManagementZoneEntry `json:"managementZone,omitempty"`
+}
+
+// ManagementZoneEntry is a management zone used to filter a dashboard or tile
+type ManagementZoneEntry struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Tile is a tile of a dashboard
+type Tile struct {
+	Name                      string              `json:"name"`
+	TileType                  string              `json:"tileType"`
+	Configured                bool                `json:"configured"`
+	Query                     string              `json:"query,omitempty"`
+	Type                      string              `json:"type,omitempty"`
+	CustomName                string              `json:"customName,omitempty"`
+	Markdown                  string              `json:"markdown,omitempty"`
+	ChartVisible              bool                `json:"chartVisible,omitempty"`
+	Bounds                    Bounds              `json:"bounds"`
+	TileFilter                TileFilter          `json:"tileFilter"`
+	Queries                   []DataExplorerQuery `json:"queries,omitempty"`
+	AssignedEntities          []string            `json:"assignedEntities,omitempty"`
+	ExcludeMaintenanceWindows bool                `json:"excludeMaintenanceWindows,omitempty"`
+	FilterConfig              *FilterConfig       `json:"filterConfig,omitempty"`
+	VisualConfig              *VisualConfig       `json:"visualConfig,omitempty"`
+}
+
+// Bounds is the position and size of a tile
+type Bounds struct {
+	Top    int `json:"top"`
+	Left   int `json:"left"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// TileFilter is the timeframe and management zone of a tile
+type TileFilter struct {
+	Timeframe      string               `json:"timeframe"`
+	ManagementZone *ManagementZoneEntry `json:"managementZone,omitempty"`
+}
+
+// DataExplorerQuery is a query of a DATA_EXPLORER tile
+type DataExplorerQuery struct {
+	ID               string              `json:"id"`
+	Metric           string              `json:"metric"`
+	SpaceAggregation string              `json:"spaceAggregation"`
+	TimeAggregation  string              `json:"timeAggregation"`
+	SplitBy          []string            `json:"splitBy"`
+	FilterBy         *DataExplorerFilter `json:"filterBy,omitempty"`
+}
+
+// DataExplorerFilter is the filter of a query of a DATA_EXPLORER tile
+type DataExplorerFilter struct {
+	FilterOperator string                     `json:"filterOperator"`
+	NestedFilters  []NestedFilterDataExplorer `json:"nestedFilters"`
+	Criteria       []FilterCriterion          `json:"criteria"`
+}
+
+// NestedFilterDataExplorer is a filter nested in the filter of a query of a DATA_EXPLORER tile
+type NestedFilterDataExplorer struct {
+	Filter         string                     `json:"filter"`
+	FilterType     string                     `json:"filterType"`
+	FilterOperator string                     `json:"filterOperator"`
+	NestedFilters  []NestedFilterDataExplorer `json:"nestedFilters"`
+	Criteria       []FilterCriterion          `json:"criteria"`
+}
+
+// FilterCriterion is a value and how it is compared
+type FilterCriterion struct {
+	Value     string `json:"value"`
+	Evaluator string `json:"evaluator"`
+}
+
+// VisualConfig is the visualization configuration of a DATA_EXPLORER tile
+type VisualConfig struct {
+	Type       string              `json:"type,omitempty"`
+	Global     *VisualConfigGlobal `json:"global,omitempty"`
+	Thresholds []Threshold         `json:"thresholds,omitempty"`
+}
+
+// VisualConfigGlobal contains the global visualization settings of a DATA_EXPLORER tile. Older dashboards define the threshold here
+type VisualConfigGlobal struct {
+	Theme      string     `json:"theme,omitempty"`
+	Threshold  *Threshold `json:"threshold,omitempty"`
+	SeriesType string     `json:"seriesType,omitempty"`
+}
+
+// Threshold defines the colors of the values of a DATA_EXPLORER tile
+type Threshold struct {
+	AxisTarget string          `json:"axisTarget,omitempty"`
+	QueryID    string          `json:"queryId,omitempty"`
+	Visible    *bool           `json:"visible,omitempty"`
+	Rules      []ThresholdRule `json:"rules"`
+}
+
+// ThresholdRule is a single threshold value and its color. The value is null if it is not set
+type ThresholdRule struct {
+	Value *float64 `json:"value"`
+	Color string   `json:"color"`
+}
+
+// FilterConfig is the configuration of a CUSTOM_CHARTING tile
+type FilterConfig struct {
+	Type                 string                         `json:"type"`
+	CustomName           string                         `json:"customName"`
+	DefaultName          string                         `json:"defaultName"`
+	ChartConfig          ChartConfig                    `json:"chartConfig"`
+	FiltersPerEntityType map[string]map[string][]string `json:"filtersPerEntityType"`
+}
+
+// ChartConfig is the chart of a CUSTOM_CHARTING tile
+type ChartConfig struct {
+	LegendShown    bool           `json:"legendShown"`
+	Type           string         `json:"type"`
+	Series         []Series       `json:"series"`
+	ResultMetadata ResultMetadata `json:"resultMetadata"`
+}
+
+// Series is a metric shown in the chart of a CUSTOM_CHARTING tile
+type Series struct {
+	Metric          string       `json:"metric"`
+	Aggregation     string       `json:"aggregation"`
+	Percentile      interface{}  `json:"percentile"`
+	Type            string       `json:"type"`
+	EntityType      string       `json:"entityType"`
+	Dimensions      []Dimensions `json:"dimensions"`
+	SortAscending   bool         `json:"sortAscending"`
+	SortColumn      bool         `json:"sortColumn"`
+	AggregationRate string       `json:"aggregationRate"`
+}
+
+// ResultMetadata is the result metadata of the chart of a CUSTOM_CHARTING tile
+type ResultMetadata struct {
+}
+
+// Dimensions is a dimension a series is split by
+type Dimensions struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	Values          []string `json:"values"`
+	EntityDimension bool     `json:"entityDimension"`
+}